@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/application"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/config"
+	"github.com/joho/godotenv"
+)
+
+// defaultBackfillDelay spaces out history requests to stay within the free
+// plan of each provider: 8 requests a minute on Twelve Data and 60 on
+// Finnhub. The yfinance service is not metered but Yahoo throttles bursts.
+func defaultBackfillDelay(provider string) time.Duration {
+	switch provider {
+	case config.MarketDataProviderFinnhub:
+		return time.Second
+	case config.MarketDataProviderYFinance:
+		return 500 * time.Millisecond
+	default:
+		return 8 * time.Second
+	}
+}
+
+// parseBackfillFlags reads the arguments of the backfill command. The
+// delay defaults to the one of the configured provider.
+func parseBackfillFlags(args []string, provider string, output io.Writer) (application.BackfillRequest, error) {
+	req := application.BackfillRequest{}
+	flags := flag.NewFlagSet("backfill", flag.ContinueOnError)
+	flags.SetOutput(output)
+	isins := flags.String("isins", "", "comma-separated ISINs to backfill (default: positions and benchmark)")
+	from := flags.String("from", "", "first date to backfill, YYYY-MM-DD (default: first trade)")
+	to := flags.String("to", "", "last date to backfill, YYYY-MM-DD (default: today)")
+	flags.DurationVar(&req.Delay, "delay", defaultBackfillDelay(provider), "wait between provider requests")
+	flags.IntVar(&req.Retries, "retries", 5, "retries of a rate-limited request")
+	flags.DurationVar(&req.Backoff, "backoff", time.Minute, "wait before the first retry, doubled for each retry after it")
+	if err := flags.Parse(args); err != nil {
+		return req, err
+	}
+	if flags.NArg() > 0 {
+		return req, fmt.Errorf("unexpected arguments: %s", strings.Join(flags.Args(), " "))
+	}
+
+	for _, isin := range strings.Split(*isins, ",") {
+		if isin = strings.TrimSpace(isin); isin != "" {
+			req.ISINs = append(req.ISINs, isin)
+		}
+	}
+	var err error
+	if *from != "" {
+		if req.From, err = time.Parse(time.DateOnly, *from); err != nil {
+			return req, fmt.Errorf("invalid from date: %w", err)
+		}
+	}
+	if *to != "" {
+		if req.To, err = time.Parse(time.DateOnly, *to); err != nil {
+			return req, fmt.Errorf("invalid to date: %w", err)
+		}
+	}
+	if req.Delay < 0 || req.Retries < 0 || req.Backoff < 0 {
+		return req, errors.New("delay, retries and backoff must not be negative")
+	}
+	return req, nil
+}
+
+// runBackfill stores the past daily bars of the portfolio's instruments.
+// It can be interrupted at any time; running it again resumes where it
+// stopped.
+func runBackfill(args []string) error {
+	setupLogger()
+
+	if err := godotenv.Load(); err != nil {
+		slog.Warn("No .env file found, using environment variables")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	req, err := parseBackfillFlags(args, cfg.MarketDataProvider, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	repo, err := initializeDatabase(cfg)
+	if err != nil {
+		return fmt.Errorf("database initialization failed: %w", err)
+	}
+
+	portfolioService, err := application.NewPortfolioService(repo, createMarketDataClient(cfg))
+	if err != nil {
+		return fmt.Errorf("failed to create portfolio service: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	slog.Info("Backfilling prices", "provider", cfg.MarketDataProvider, "delay", req.Delay)
+	results, err := portfolioService.BackfillPrices(ctx, req)
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+			slog.Error("Backfill failed", "isin", result.ISIN, "symbol", result.Symbol, "bars", result.Bars, "error", result.Error)
+			continue
+		}
+		slog.Info("Backfilled", "isin", result.ISIN, "symbol", result.Symbol, "from", result.From.Format(time.DateOnly),
+			"to", result.To.Format(time.DateOnly), "bars", result.Bars)
+	}
+	if errors.Is(err, context.Canceled) {
+		slog.Info("Backfill interrupted, run it again to resume")
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("backfill failed: %w", err)
+	}
+	if failed > 0 {
+		return fmt.Errorf("backfill failed for %d of %d instruments", failed, len(results))
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/config"
+)
+
+func TestParseBackfillFlags(t *testing.T) {
+	req, err := parseBackfillFlags(nil, config.MarketDataProviderTwelveData, io.Discard)
+	if err != nil {
+		t.Fatalf("parseBackfillFlags failed: %v", err)
+	}
+	if req.Delay != 8*time.Second || req.Retries != 5 || req.Backoff != time.Minute || len(req.ISINs) != 0 || !req.From.IsZero() || !req.To.IsZero() {
+		t.Errorf("unexpected defaults %+v", req)
+	}
+
+	req, err = parseBackfillFlags([]string{"-isins", "US0378331005, IE00B4L5Y983,", "-from", "2020-01-01", "-to", "2024-12-31", "-delay", "0s"},
+		config.MarketDataProviderFinnhub, io.Discard)
+	if err != nil {
+		t.Fatalf("parseBackfillFlags failed: %v", err)
+	}
+	if len(req.ISINs) != 2 || req.ISINs[1] != "IE00B4L5Y983" || req.Delay != 0 ||
+		!req.From.Equal(time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)) || !req.To.Equal(time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected request %+v", req)
+	}
+
+	if req, _ = parseBackfillFlags(nil, config.MarketDataProviderFinnhub, io.Discard); req.Delay != time.Second {
+		t.Errorf("expected the Finnhub delay, got %s", req.Delay)
+	}
+
+	for _, args := range [][]string{{"-from", "01/01/2020"}, {"-retries", "-1"}, {"-unknown"}, {"extra"}} {
+		if _, err := parseBackfillFlags(args, config.MarketDataProviderYFinance, io.Discard); err == nil {
+			t.Errorf("expected an error for %v", args)
+		}
+	}
+}
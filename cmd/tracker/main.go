@@ -18,10 +18,13 @@ import (
 	"github.com/jmanzanog/stock-tracker/internal/domain"
 	"github.com/jmanzanog/stock-tracker/internal/infrastructure/config"
 	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata/ecb"
 	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata/finnhub"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata/staticfx"
 	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata/twelvedata"
 	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata/yfinance"
 	"github.com/jmanzanog/stock-tracker/internal/infrastructure/persistence/sqldb"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/webhook"
 	httpHandler "github.com/jmanzanog/stock-tracker/internal/interfaces/http"
 	"github.com/joho/godotenv"
 	_ "github.com/sijms/go-ora/v2"
@@ -105,6 +108,34 @@ func createMarketDataClient(cfg *config.Config) marketdata.MDataProvider {
 	}
 }
 
+// createFXRateProvider creates the exchange rate source, or nil when conversion is disabled
+func createFXRateProvider(cfg *config.Config) (marketdata.FXRateProvider, error) {
+	switch cfg.FXProvider {
+	case config.FXProviderNone:
+		return nil, nil
+	case config.FXProviderStatic:
+		rates, err := staticfx.ParseRates(cfg.FXStaticRates)
+		if err != nil {
+			return nil, fmt.Errorf("invalid FX_STATIC_RATES: %w", err)
+		}
+		return staticfx.NewProvider("EUR", rates), nil
+	default:
+		return ecb.NewClientWithBaseURL(cfg.ECBBaseURL), nil
+	}
+}
+
+// createAlertNotifier creates the webhook notifier for triggered alerts, or nil when no URL is configured
+func createAlertNotifier(cfg *config.Config) (application.AlertNotifier, error) {
+	urls, err := webhook.ParseURLs(cfg.AlertWebhookURLs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ALERT_WEBHOOK_URLS: %w", err)
+	}
+	if len(urls) == 0 {
+		return nil, nil
+	}
+	return webhook.NewNotifier(urls, cfg.AlertWebhookRetries, cfg.AlertWebhookBackoff), nil
+}
+
 // App wraps the application components for easier testing
 type App struct {
 	Server        *http.Server
@@ -152,6 +183,31 @@ func run() error {
 	if err != nil {
 		return fmt.Errorf("failed to create portfolio service: %w", err)
 	}
+	portfolioService.SetRiskFreeRate(cfg.RiskFreeRate)
+
+	fxProvider, err := createFXRateProvider(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create fx rate provider: %w", err)
+	}
+	if fxProvider != nil {
+		// Keep every rate served so historical valuations can be reproduced
+		if fxRepo, ok := repo.(domain.FXRateRepository); ok {
+			fxProvider = application.NewStoredFXRates(fxProvider, fxRepo)
+		}
+		portfolioService.SetFXRateProvider(fxProvider)
+		slog.Info("Using fx rate provider", "provider", cfg.FXProvider)
+	} else {
+		slog.Warn("No FX rate provider configured, multi-currency portfolios cannot be valued")
+	}
+
+	notifier, err := createAlertNotifier(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create alert notifier: %w", err)
+	}
+	if notifier != nil {
+		portfolioService.SetAlertNotifier(notifier)
+		slog.Info("Delivering alerts to webhooks", "retries", cfg.AlertWebhookRetries)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -201,6 +257,14 @@ func run() error {
 }
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "backfill" {
+		if err := runBackfill(os.Args[2:]); err != nil {
+			slog.Error("Backfill error", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	if err := run(); err != nil {
 		slog.Error("Application error", "error", err)
 		os.Exit(1)
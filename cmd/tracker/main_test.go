@@ -14,7 +14,10 @@ import (
 	"github.com/jmanzanog/stock-tracker/internal/application"
 	"github.com/jmanzanog/stock-tracker/internal/domain"
 	"github.com/jmanzanog/stock-tracker/internal/infrastructure/config"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata/ecb"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata/staticfx"
 	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata/twelvedata"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/webhook"
 	"github.com/testcontainers/testcontainers-go"
 	"github.com/testcontainers/testcontainers-go/modules/postgres"
 	"github.com/testcontainers/testcontainers-go/wait"
@@ -181,6 +184,43 @@ func TestBuildServer(t *testing.T) {
 	}
 }
 
+func TestCreateFXRateProvider(t *testing.T) {
+	provider, err := createFXRateProvider(&config.Config{FXProvider: config.FXProviderNone})
+	if err != nil || provider != nil {
+		t.Errorf("expected no provider when disabled, got %v, %v", provider, err)
+	}
+
+	provider, err = createFXRateProvider(&config.Config{FXProvider: config.FXProviderECB, ECBBaseURL: "http://localhost"})
+	if _, ok := provider.(*ecb.Client); err != nil || !ok {
+		t.Errorf("expected ECB client, got %T, %v", provider, err)
+	}
+
+	provider, err = createFXRateProvider(&config.Config{FXProvider: config.FXProviderStatic, FXStaticRates: "USD=1.08,GBP=0.85"})
+	if _, ok := provider.(*staticfx.Provider); err != nil || !ok {
+		t.Errorf("expected static provider, got %T, %v", provider, err)
+	}
+
+	if _, err := createFXRateProvider(&config.Config{FXProvider: config.FXProviderStatic, FXStaticRates: "USD"}); err == nil {
+		t.Error("expected error for malformed FX_STATIC_RATES")
+	}
+}
+
+func TestCreateAlertNotifier(t *testing.T) {
+	notifier, err := createAlertNotifier(&config.Config{})
+	if err != nil || notifier != nil {
+		t.Errorf("expected no notifier without urls, got %v, %v", notifier, err)
+	}
+
+	notifier, err = createAlertNotifier(&config.Config{AlertWebhookURLs: "https://hooks.example.com/alerts", AlertWebhookRetries: 3})
+	if _, ok := notifier.(*webhook.Notifier); err != nil || !ok {
+		t.Errorf("expected webhook notifier, got %T, %v", notifier, err)
+	}
+
+	if _, err := createAlertNotifier(&config.Config{AlertWebhookURLs: "hooks.example.com"}); err == nil {
+		t.Error("expected error for a relative ALERT_WEBHOOK_URLS entry")
+	}
+}
+
 // --- App Tests ---
 
 func TestApp_Shutdown(t *testing.T) {
@@ -0,0 +1,245 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// ErrAlertsUnsupported is returned when the repository does not store
+// alert rules.
+var ErrAlertsUnsupported = errors.New("repository does not store alert rules")
+
+// defaultAlertLimit is the number of alerts listed when a request does not
+// set one.
+const defaultAlertLimit = 50
+
+// AlertNotifier delivers triggered alerts, such as to webhooks. Retries
+// are up to the notifier; an error means the alert was not delivered.
+type AlertNotifier interface {
+	Notify(ctx context.Context, alert domain.Alert) error
+}
+
+// CreateAlertRuleRequest defines an alert rule. ISIN is required for
+// every kind except portfolio_value_below. CooldownMinutes defaults to
+// domain.DefaultAlertCooldown.
+type CreateAlertRuleRequest struct {
+	Kind            domain.AlertKind `json:"kind" binding:"required"`
+	ISIN            string           `json:"isin"`
+	Threshold       domain.Decimal   `json:"threshold"`
+	CooldownMinutes *int             `json:"cooldown_minutes"`
+}
+
+// SetAlertNotifier configures where triggered alerts are delivered.
+// Without one, alerts are only stored.
+func (s *PortfolioService) SetAlertNotifier(notifier AlertNotifier) {
+	s.notifier = notifier
+}
+
+// CreateAlertRule stores a rule, checked from the next price refresh on.
+func (s *PortfolioService) CreateAlertRule(ctx context.Context, req CreateAlertRuleRequest) (*domain.AlertRule, error) {
+	repo, ok := s.repo.(domain.AlertRepository)
+	if !ok {
+		return nil, ErrAlertsUnsupported
+	}
+
+	cooldown := domain.DefaultAlertCooldown
+	if req.CooldownMinutes != nil {
+		cooldown = *req.CooldownMinutes
+	}
+	rule, err := domain.NewAlertRule(req.Kind, req.ISIN, req.Threshold, cooldown)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.SaveAlertRule(ctx, rule); err != nil {
+		return nil, fmt.Errorf("failed to save alert rule: %w", err)
+	}
+
+	slog.InfoContext(ctx, "alert rule created", "rule_id", rule.ID, "kind", rule.Kind, "isin", rule.ISIN, "threshold", rule.Threshold)
+	return rule, nil
+}
+
+// ListAlertRules returns every rule with the state of its last check.
+func (s *PortfolioService) ListAlertRules(ctx context.Context) ([]*domain.AlertRule, error) {
+	repo, ok := s.repo.(domain.AlertRepository)
+	if !ok {
+		return nil, ErrAlertsUnsupported
+	}
+
+	rules, err := repo.FindAlertRules(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alert rules: %w", err)
+	}
+	return rules, nil
+}
+
+// DeleteAlertRule removes a rule and the alerts it raised.
+func (s *PortfolioService) DeleteAlertRule(ctx context.Context, id string) error {
+	repo, ok := s.repo.(domain.AlertRepository)
+	if !ok {
+		return ErrAlertsUnsupported
+	}
+
+	if err := repo.DeleteAlertRule(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrAlertRuleNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete alert rule: %w", err)
+	}
+	slog.InfoContext(ctx, "alert rule deleted", "rule_id", id)
+	return nil
+}
+
+// ListAlerts returns the latest triggered alerts, newest first. A limit of
+// zero or less lists the last 50.
+func (s *PortfolioService) ListAlerts(ctx context.Context, limit int) ([]domain.Alert, error) {
+	repo, ok := s.repo.(domain.AlertRepository)
+	if !ok {
+		return nil, ErrAlertsUnsupported
+	}
+
+	if limit <= 0 {
+		limit = defaultAlertLimit
+	}
+	alerts, err := repo.FindAlerts(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load alerts: %w", err)
+	}
+	return alerts, nil
+}
+
+// evaluateAlerts checks every rule against the prices just quoted and
+// delivers the alerts that fire. Rules whose quantity cannot be observed,
+// such as a price that was not quoted, keep their state until the next
+// refresh. Failures are logged rather than returned, as the refreshed
+// prices are already saved.
+func (s *PortfolioService) evaluateAlerts(ctx context.Context, now time.Time, quoted []domain.PricePoint) {
+	repo, ok := s.repo.(domain.AlertRepository)
+	if !ok {
+		return
+	}
+	rules, err := repo.FindAlertRules(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load alert rules", "error", err)
+		return
+	}
+
+	prices := make(map[string]domain.Decimal, len(quoted))
+	for _, point := range quoted {
+		prices[point.ISIN] = point.Close
+	}
+	var valuation *domain.Valuation
+	for _, rule := range rules {
+		if rule.Kind == domain.AlertPortfolioValueBelow && valuation == nil {
+			if valuation, err = s.GetPortfolioValuation(ctx); err != nil {
+				slog.WarnContext(ctx, "failed to value portfolio for alerts", "error", err)
+				continue
+			}
+		}
+		value, ok, err := s.observeAlert(ctx, rule, now, prices, valuation)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to evaluate alert rule", "rule_id", rule.ID, "kind", rule.Kind, "error", err)
+			continue
+		}
+		if !ok {
+			continue
+		}
+
+		alert, changed := rule.Evaluate(value, now)
+		switch {
+		case alert != nil:
+			if err := repo.RecordAlert(ctx, rule, alert); err != nil {
+				slog.WarnContext(ctx, "failed to record alert", "rule_id", rule.ID, "error", err)
+				continue
+			}
+			slog.InfoContext(ctx, "alert triggered", "rule_id", rule.ID, "kind", rule.Kind, "message", alert.Message)
+			s.deliverAlert(ctx, repo, alert)
+		case changed:
+			if err := repo.SaveAlertRule(ctx, rule); err != nil {
+				slog.WarnContext(ctx, "failed to save alert rule", "rule_id", rule.ID, "error", err)
+			}
+		}
+	}
+}
+
+// observeAlert returns the quantity the rule watches, or false when it is
+// not known: the instrument was not quoted, it has no earlier close, or the
+// position is not held.
+func (s *PortfolioService) observeAlert(ctx context.Context, rule *domain.AlertRule, now time.Time, prices map[string]domain.Decimal, valuation *domain.Valuation) (domain.Decimal, bool, error) {
+	switch rule.Kind {
+	case domain.AlertPriceAbove, domain.AlertPriceBelow:
+		price, ok := prices[rule.ISIN]
+		return price, ok, nil
+	case domain.AlertDailyMove:
+		price, ok := prices[rule.ISIN]
+		if !ok {
+			return domain.Zero, false, nil
+		}
+		previous, ok, err := s.previousClose(ctx, rule.ISIN, now)
+		if err != nil || !ok {
+			return domain.Zero, false, err
+		}
+		move, err := domain.DailyMove(previous, price)
+		return move, err == nil, err
+	case domain.AlertPositionLoss:
+		position, err := s.defaultPortfolio.FindPositionByISIN(rule.ISIN)
+		if err != nil || position.IsClosed() || position.CurrentPrice.IsZero() {
+			return domain.Zero, false, nil
+		}
+		percent, err := position.ProfitLossPercent()
+		if err != nil {
+			return domain.Zero, false, fmt.Errorf("failed to calculate profit/loss of %s: %w", rule.ISIN, err)
+		}
+		rounded, err := percent.Round(2)
+		return rounded, err == nil, err
+	case domain.AlertPortfolioValueBelow:
+		if valuation == nil {
+			return domain.Zero, false, nil
+		}
+		return valuation.TotalValue, true, nil
+	default:
+		return domain.Zero, false, nil
+	}
+}
+
+// previousClose returns the last stored close of isin before the day of
+// now, looking back two weeks to cover weekends and holidays.
+func (s *PortfolioService) previousClose(ctx context.Context, isin string, now time.Time) (domain.Decimal, bool, error) {
+	history, ok := s.repo.(domain.PriceHistoryRepository)
+	if !ok {
+		return domain.Zero, false, nil
+	}
+	// Closes are stored on the calendar day of the quote, as UTC midnight
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+	points, err := history.FindPrices(ctx, isin, today.AddDate(0, 0, -14), today.AddDate(0, 0, -1))
+	if err != nil {
+		return domain.Zero, false, fmt.Errorf("failed to load closes of %s: %w", isin, err)
+	}
+	if len(points) == 0 {
+		return domain.Zero, false, nil
+	}
+	return points[len(points)-1].Close, true, nil
+}
+
+// deliverAlert hands an alert to the notifier and stores the outcome.
+func (s *PortfolioService) deliverAlert(ctx context.Context, repo domain.AlertRepository, alert *domain.Alert) {
+	if s.notifier == nil {
+		return
+	}
+	if err := s.notifier.Notify(ctx, *alert); err != nil {
+		slog.WarnContext(ctx, "failed to deliver alert", "alert_id", alert.ID, "error", err)
+		alert.DeliveryError = err.Error()
+	} else {
+		delivered := time.Now()
+		alert.DeliveredAt = &delivered
+		alert.DeliveryError = ""
+	}
+	if err := repo.SaveAlertDelivery(ctx, alert); err != nil {
+		slog.WarnContext(ctx, "failed to save alert delivery", "alert_id", alert.ID, "error", err)
+	}
+}
@@ -0,0 +1,204 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// mockAlertRepository keeps rules and alerts in memory, handing out copies
+// so that only saved state persists between refreshes
+type mockAlertRepository struct {
+	mockPriceHistoryRepository
+	rules  []domain.AlertRule
+	alerts []domain.Alert
+}
+
+func (m *mockAlertRepository) SaveAlertRule(_ context.Context, rule *domain.AlertRule) error {
+	for i := range m.rules {
+		if m.rules[i].ID == rule.ID {
+			m.rules[i] = *rule
+			return nil
+		}
+	}
+	m.rules = append(m.rules, *rule)
+	return nil
+}
+
+func (m *mockAlertRepository) FindAlertRules(_ context.Context) ([]*domain.AlertRule, error) {
+	rules := make([]*domain.AlertRule, len(m.rules))
+	for i := range m.rules {
+		rule := m.rules[i]
+		rules[i] = &rule
+	}
+	return rules, nil
+}
+
+func (m *mockAlertRepository) DeleteAlertRule(_ context.Context, id string) error {
+	for i := range m.rules {
+		if m.rules[i].ID == id {
+			m.rules = append(m.rules[:i], m.rules[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrAlertRuleNotFound
+}
+
+func (m *mockAlertRepository) RecordAlert(ctx context.Context, rule *domain.AlertRule, alert *domain.Alert) error {
+	m.alerts = append(m.alerts, *alert)
+	return m.SaveAlertRule(ctx, rule)
+}
+
+func (m *mockAlertRepository) SaveAlertDelivery(_ context.Context, alert *domain.Alert) error {
+	for i := range m.alerts {
+		if m.alerts[i].ID == alert.ID {
+			m.alerts[i] = *alert
+		}
+	}
+	return nil
+}
+
+func (m *mockAlertRepository) FindAlerts(_ context.Context, limit int) ([]domain.Alert, error) {
+	alerts := make([]domain.Alert, 0, len(m.alerts))
+	for i := len(m.alerts) - 1; i >= 0 && len(alerts) < limit; i-- {
+		alerts = append(alerts, m.alerts[i])
+	}
+	return alerts, nil
+}
+
+type mockAlertNotifier struct {
+	err      error
+	notified []domain.Alert
+}
+
+func (m *mockAlertNotifier) Notify(_ context.Context, alert domain.Alert) error {
+	m.notified = append(m.notified, alert)
+	return m.err
+}
+
+func TestRefreshPrices_Alerts(t *testing.T) {
+	// 10 AAPL bought at 150 USD and quoted at 150, worth 750 EUR
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&MockFXRates{})
+	repo := &mockAlertRepository{}
+	service.repo = repo
+	notifier := &mockAlertNotifier{}
+	service.SetAlertNotifier(notifier)
+	ctx := context.Background()
+
+	yesterday := time.Now().AddDate(0, 0, -1)
+	repo.prices = []domain.PricePoint{domain.NewPricePoint("US0378331005", yesterday, domain.NewDecimalFromInt(200), "USD", "quote")}
+
+	zero := 0
+	requests := []CreateAlertRuleRequest{
+		{Kind: domain.AlertPriceAbove, ISIN: "US0378331005", Threshold: domain.NewDecimalFromInt(150), CooldownMinutes: &zero},
+		{Kind: domain.AlertPriceBelow, ISIN: "US0378331005", Threshold: domain.NewDecimalFromInt(100)},
+		{Kind: domain.AlertDailyMove, ISIN: "US0378331005", Threshold: domain.NewDecimalFromInt(5)},
+		{Kind: domain.AlertPositionLoss, ISIN: "US0378331005", Threshold: domain.NewDecimalFromInt(10)},
+		{Kind: domain.AlertPortfolioValueBelow, Threshold: domain.NewDecimalFromInt(1000)},
+		{Kind: domain.AlertPriceAbove, ISIN: "IE00B4L5Y983", Threshold: domain.NewDecimalFromInt(1)},
+	}
+	for _, req := range requests {
+		if _, err := service.CreateAlertRule(ctx, req); err != nil {
+			t.Fatalf("CreateAlertRule(%s) failed: %v", req.Kind, err)
+		}
+	}
+
+	if err := service.RefreshPrices(ctx); err != nil {
+		t.Fatalf("RefreshPrices failed: %v", err)
+	}
+	alerts, err := service.ListAlerts(ctx, 0)
+	if err != nil {
+		t.Fatalf("ListAlerts failed: %v", err)
+	}
+	// The unquoted IE00B4L5Y983 and the rules that do not hold stay silent
+	fired := make(map[domain.AlertKind]domain.Alert)
+	for _, alert := range alerts {
+		fired[alert.Kind] = alert
+		if alert.DeliveredAt == nil {
+			t.Errorf("expected %s delivered, got %+v", alert.Kind, alert)
+		}
+	}
+	if len(alerts) != 3 || len(notifier.notified) != 3 {
+		t.Fatalf("expected 3 alerts delivered, got %+v", alerts)
+	}
+	if move := fired[domain.AlertDailyMove]; !move.Value.Equal(domain.NewDecimalFromInt(-25)) {
+		t.Errorf("expected a daily move of -25%%, got %+v", move)
+	}
+	if value := fired[domain.AlertPortfolioValueBelow]; !value.Value.Equal(domain.NewDecimalFromInt(750)) {
+		t.Errorf("expected a portfolio value of 750, got %+v", value)
+	}
+	if _, ok := fired[domain.AlertPriceAbove]; !ok {
+		t.Errorf("expected the price alert, got %+v", alerts)
+	}
+
+	// Conditions that still hold do not fire again, even without a cooldown
+	if err := service.RefreshPrices(ctx); err != nil {
+		t.Fatalf("RefreshPrices failed: %v", err)
+	}
+	if len(repo.alerts) != 3 {
+		t.Errorf("expected no new alerts, got %+v", repo.alerts)
+	}
+	rules, err := service.ListAlertRules(ctx)
+	if err != nil {
+		t.Fatalf("ListAlertRules failed: %v", err)
+	}
+	for _, rule := range rules {
+		_, hasFired := fired[rule.Kind]
+		if rule.ISIN == "IE00B4L5Y983" {
+			hasFired = false
+		}
+		if rule.Active != hasFired {
+			t.Errorf("expected %s %s active=%v, got %+v", rule.Kind, rule.ISIN, hasFired, rule)
+		}
+	}
+}
+
+func TestRefreshPrices_AlertDeliveryFailure(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	repo := &mockAlertRepository{}
+	service.repo = repo
+	service.SetAlertNotifier(&mockAlertNotifier{err: errors.New("webhook returned 503")})
+	ctx := context.Background()
+
+	if _, err := service.CreateAlertRule(ctx, CreateAlertRuleRequest{Kind: domain.AlertPriceBelow, ISIN: "US0378331005", Threshold: domain.NewDecimalFromInt(200)}); err != nil {
+		t.Fatalf("CreateAlertRule failed: %v", err)
+	}
+	if err := service.RefreshPrices(ctx); err != nil {
+		t.Fatalf("RefreshPrices failed: %v", err)
+	}
+	if len(repo.alerts) != 1 || repo.alerts[0].DeliveredAt != nil || repo.alerts[0].DeliveryError != "webhook returned 503" {
+		t.Errorf("expected an undelivered alert with its error, got %+v", repo.alerts)
+	}
+}
+
+func TestAlertRules(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	ctx := context.Background()
+
+	req := CreateAlertRuleRequest{Kind: domain.AlertPortfolioValueBelow, Threshold: domain.NewDecimalFromInt(1000)}
+	if _, err := service.CreateAlertRule(ctx, req); !errors.Is(err, ErrAlertsUnsupported) {
+		t.Errorf("expected ErrAlertsUnsupported, got %v", err)
+	}
+
+	service.repo = &mockAlertRepository{}
+	rule, err := service.CreateAlertRule(ctx, req)
+	if err != nil {
+		t.Fatalf("CreateAlertRule failed: %v", err)
+	}
+	if rule.CooldownMinutes != domain.DefaultAlertCooldown {
+		t.Errorf("expected the default cooldown, got %d", rule.CooldownMinutes)
+	}
+	if _, err := service.CreateAlertRule(ctx, CreateAlertRuleRequest{Kind: domain.AlertDailyMove, Threshold: domain.NewDecimalFromInt(5)}); !errors.Is(err, domain.ErrInvalidAlertRule) {
+		t.Errorf("expected ErrInvalidAlertRule, got %v", err)
+	}
+	if err := service.DeleteAlertRule(ctx, rule.ID); err != nil {
+		t.Fatalf("DeleteAlertRule failed: %v", err)
+	}
+	if err := service.DeleteAlertRule(ctx, rule.ID); !errors.Is(err, domain.ErrAlertRuleNotFound) {
+		t.Errorf("expected ErrAlertRuleNotFound, got %v", err)
+	}
+}
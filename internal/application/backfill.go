@@ -0,0 +1,326 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata"
+)
+
+// ErrHistoryUnsupported is returned when the market data provider cannot
+// fetch past prices.
+var ErrHistoryUnsupported = errors.New("market data provider does not serve price history")
+
+// priceSourceBackfill marks closes fetched from a provider's price history.
+const priceSourceBackfill = "backfill"
+
+// backfillChunkYears is the span of history fetched per request, so that each
+// request stays within the providers' output limits and an interrupted
+// backfill loses at most one chunk.
+const backfillChunkYears = 1
+
+// backfillMaxGapDays is the longest run of days without a bar between two
+// backfilled days that is taken as a market closure, such as a weekend or
+// a holiday; longer runs are fetched again.
+const backfillMaxGapDays = 4
+
+// BackfillRequest selects the instruments and dates to backfill. Without
+// ISINs, the positions and the benchmark are backfilled. A zero From
+// starts at the first trade of the portfolio and a zero To means today.
+// Delay is waited between requests; a rate-limited request is retried up
+// to Retries times, waiting Backoff before the first retry and doubling it
+// for each one after.
+type BackfillRequest struct {
+	ISINs   []string
+	From    time.Time
+	To      time.Time
+	Delay   time.Duration
+	Retries int
+	Backoff time.Duration
+}
+
+// BackfillResult reports the daily bars stored for one instrument between
+// From and To, not counting the bars an earlier run already stored. Error
+// is set when the instrument could not be completed.
+type BackfillResult struct {
+	ISIN   string    `json:"isin"`
+	Symbol string    `json:"symbol"`
+	From   time.Time `json:"from"`
+	To     time.Time `json:"to"`
+	Bars   int       `json:"bars"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// BackfillPrices stores the daily bars of past dates from the provider's
+// price history. It skips the days a previous backfill stored for each
+// instrument, fetching only those before, between and after them, and
+// saves every chunk as it is fetched, so it can be stopped and run again.
+// Failures of single instruments are reported in their result; only
+// cancellation stops the whole backfill.
+func (s *PortfolioService) BackfillPrices(ctx context.Context, req BackfillRequest) ([]BackfillResult, error) {
+	provider, ok := s.marketData.(marketdata.HistoryProvider)
+	if !ok {
+		return nil, ErrHistoryUnsupported
+	}
+	repo, ok := s.repo.(domain.PriceHistoryRepository)
+	if !ok {
+		return nil, ErrPriceHistoryUnsupported
+	}
+
+	to := dateOf(req.To)
+	if req.To.IsZero() {
+		to = dateOf(time.Now())
+	}
+	from := dateOf(req.From)
+	if req.From.IsZero() {
+		from = to.AddDate(-1, 0, 0)
+		for _, pos := range s.defaultPortfolio.Positions {
+			if since, ok := s.holdingSince(pos); ok && since.Before(from) {
+				from = dateOf(since)
+			}
+		}
+	}
+	if from.After(to) {
+		return nil, fmt.Errorf("backfill start %s is after its end %s", from.Format(time.DateOnly), to.Format(time.DateOnly))
+	}
+
+	isins, err := s.backfillISINs(req.ISINs)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &backfill{provider: provider, repo: repo, req: req}
+	results := make([]BackfillResult, 0, len(isins))
+	for _, isin := range isins {
+		result := BackfillResult{ISIN: isin, From: from, To: to}
+		err := b.run(ctx, s, &result)
+		if ctx.Err() != nil {
+			results = append(results, result)
+			return results, ctx.Err()
+		}
+		if err != nil {
+			slog.WarnContext(ctx, "failed to backfill prices", "isin", isin, "error", err)
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	slog.InfoContext(ctx, "prices backfilled", "instruments", len(results))
+	return results, nil
+}
+
+// backfillISINs validates the requested ISINs, defaulting to the positions
+// and the benchmark.
+func (s *PortfolioService) backfillISINs(requested []string) ([]string, error) {
+	var isins []string
+	seen := make(map[string]bool)
+	add := func(isin string) {
+		if isin != "" && !seen[isin] {
+			seen[isin] = true
+			isins = append(isins, isin)
+		}
+	}
+
+	if len(requested) > 0 {
+		for _, raw := range requested {
+			isin := strings.ToUpper(strings.TrimSpace(raw))
+			if err := domain.ValidateISIN(isin); err != nil {
+				return nil, err
+			}
+			add(isin)
+		}
+		return isins, nil
+	}
+
+	for i := range s.defaultPortfolio.Positions {
+		add(s.defaultPortfolio.Positions[i].Instrument.ISIN)
+	}
+	add(s.defaultPortfolio.BenchmarkISIN)
+	return isins, nil
+}
+
+// backfillInstrument finds the symbol and currency of isin, without asking
+// the provider for held instruments and the benchmark.
+func (s *PortfolioService) backfillInstrument(ctx context.Context, isin string) (*domain.Instrument, error) {
+	if pos, err := s.defaultPortfolio.FindPositionByISIN(isin); err == nil {
+		return &pos.Instrument, nil
+	}
+	if s.benchmark != nil && s.benchmark.ISIN == isin {
+		return s.benchmark, nil
+	}
+	instrument, err := s.marketData.SearchByISIN(ctx, isin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find instrument: %w", err)
+	}
+	return instrument, nil
+}
+
+// backfill fetches history for one request, spacing out its calls to the
+// provider.
+type backfill struct {
+	provider marketdata.HistoryProvider
+	repo     domain.PriceHistoryRepository
+	req      BackfillRequest
+	requests int
+}
+
+// run backfills the instrument of result between its From and To, around
+// the days an earlier backfill already stored. A failed range does not
+// stop the ranges after it unless the provider is still rate limiting.
+func (b *backfill) run(ctx context.Context, s *PortfolioService, result *BackfillResult) error {
+	stored, err := b.repo.FindPrices(ctx, result.ISIN, result.From, result.To)
+	if err != nil {
+		return fmt.Errorf("failed to load stored prices: %w", err)
+	}
+	var days []time.Time
+	for _, point := range stored {
+		if point.Source == priceSourceBackfill {
+			days = append(days, point.Date)
+		}
+	}
+
+	missing := missingRanges(result.From, result.To, days)
+	if len(missing) == 0 {
+		slog.DebugContext(ctx, "prices already backfilled", "isin", result.ISIN)
+		return nil
+	}
+
+	instrument, err := s.backfillInstrument(ctx, result.ISIN)
+	if err != nil {
+		return err
+	}
+	result.Symbol = instrument.Symbol
+
+	var errs []error
+	for _, r := range missing {
+		if err := b.fill(ctx, instrument, result, r[0], r[1]); err != nil {
+			if ctx.Err() != nil || errors.Is(err, marketdata.ErrRateLimited) {
+				return errors.Join(append(errs, err)...)
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// missingRanges returns the ranges between from and to without a stored
+// day, given the stored days in date order. Runs of up to
+// backfillMaxGapDays days between two stored days are not missing.
+func missingRanges(from, to time.Time, stored []time.Time) [][2]time.Time {
+	var missing [][2]time.Time
+	add := func(start, end time.Time) {
+		if !start.After(end) {
+			missing = append(missing, [2]time.Time{start, end})
+		}
+	}
+
+	next := from
+	for i, day := range stored {
+		day = dateOf(day)
+		if i == 0 || day.Sub(next) > backfillMaxGapDays*24*time.Hour {
+			add(next, day.AddDate(0, 0, -1))
+		}
+		if after := day.AddDate(0, 0, 1); after.After(next) {
+			next = after
+		}
+	}
+	add(next, to)
+	return missing
+}
+
+// fill stores the bars of instrument between from and to, a chunk at a
+// time. A failed chunk is left for the next run to fill in and the chunks
+// after it are still fetched, unless the provider is still rate limiting.
+func (b *backfill) fill(ctx context.Context, instrument *domain.Instrument, result *BackfillResult, from, to time.Time) error {
+	var errs []error
+	for start := from; !start.After(to); {
+		end := start.AddDate(backfillChunkYears, 0, -1)
+		if end.After(to) {
+			end = to
+		}
+
+		bars, err := b.fetch(ctx, instrument.Symbol, start, end)
+		if err != nil {
+			errs = append(errs, err)
+			if ctx.Err() != nil || errors.Is(err, marketdata.ErrRateLimited) {
+				break
+			}
+			slog.WarnContext(ctx, "failed to backfill chunk", "isin", result.ISIN, "from", start, "to", end, "error", err)
+			start = end.AddDate(0, 0, 1)
+			continue
+		}
+		points := make([]domain.PricePoint, 0, len(bars))
+		for _, bar := range bars {
+			currency := bar.Currency
+			if currency == "" {
+				currency = instrument.Currency
+			}
+			point := domain.NewPricePoint(result.ISIN, bar.Date, bar.Close, currency, priceSourceBackfill)
+			point.Open, point.High, point.Low, point.Volume = bar.Open, bar.High, bar.Low, bar.Volume
+			points = append(points, point)
+		}
+		if len(points) > 0 {
+			if err := b.repo.SavePrices(ctx, points); err != nil {
+				return fmt.Errorf("failed to store prices: %w", err)
+			}
+		}
+		result.Bars += len(points)
+		slog.DebugContext(ctx, "backfilled prices", "isin", result.ISIN, "from", start, "to", end, "bars", len(points))
+
+		start = end.AddDate(0, 0, 1)
+	}
+	return errors.Join(errs...)
+}
+
+// fetch requests the daily bars of symbol, waiting Delay after the
+// previous request and retrying while the provider is rate limiting.
+func (b *backfill) fetch(ctx context.Context, symbol string, from, to time.Time) ([]marketdata.Bar, error) {
+	wait := b.req.Backoff
+	for attempt := 0; ; attempt++ {
+		if b.requests > 0 {
+			if err := sleep(ctx, b.req.Delay); err != nil {
+				return nil, err
+			}
+		}
+		b.requests++
+
+		bars, err := b.provider.GetHistory(ctx, symbol, from, to, marketdata.HistoryDaily)
+		if err == nil {
+			return bars, nil
+		}
+		if !errors.Is(err, marketdata.ErrRateLimited) || attempt >= b.req.Retries {
+			return nil, fmt.Errorf("failed to get history for %s: %w", symbol, err)
+		}
+
+		slog.WarnContext(ctx, "rate limited, retrying", "symbol", symbol, "attempt", attempt+1, "wait", wait)
+		if err := sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		wait *= 2
+	}
+}
+
+// sleep waits for d unless ctx is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// dateOf returns the UTC midnight of t's calendar day.
+func dateOf(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,190 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata"
+)
+
+// historyMarketData serves a bar for every day of the requested range,
+// failing with the queued errors first; a nil error lets its request pass
+type historyMarketData struct {
+	MockMarketData
+	errs     []error
+	requests [][2]time.Time
+}
+
+func (m *historyMarketData) GetHistory(_ context.Context, _ string, from, to time.Time, _ marketdata.HistoryInterval) ([]marketdata.Bar, error) {
+	m.requests = append(m.requests, [2]time.Time{from, to})
+	if len(m.errs) > 0 {
+		err := m.errs[0]
+		m.errs = m.errs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	var bars []marketdata.Bar
+	for date := from; !date.After(to); date = date.AddDate(0, 0, 1) {
+		bars = append(bars, marketdata.Bar{Date: date, Close: domain.NewDecimalFromInt(int64(date.Day()))})
+	}
+	return bars, nil
+}
+
+func TestBackfillPrices(t *testing.T) {
+	md := &historyMarketData{}
+	service := newDividendService(t, md)
+	repo := &mockPriceHistoryRepository{}
+	service.repo = repo
+	ctx := context.Background()
+
+	// A quoted close does not count as backfilled
+	from := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 1, 31, 0, 0, 0, 0, time.UTC)
+	repo.prices = []domain.PricePoint{domain.NewPricePoint("US0378331005", to, domain.NewDecimalFromInt(1), "USD", priceSourceQuote)}
+
+	req := BackfillRequest{From: from, To: to}
+	results, err := service.BackfillPrices(ctx, req)
+	if err != nil {
+		t.Fatalf("BackfillPrices failed: %v", err)
+	}
+	if len(results) != 1 || results[0].ISIN != "US0378331005" || results[0].Error != "" || results[0].Bars != 611 {
+		t.Fatalf("expected 611 bars for the position, got %+v", results)
+	}
+	// Fetched a year at a time
+	if len(md.requests) != 2 || !md.requests[0][1].Equal(time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)) || !md.requests[1][1].Equal(to) {
+		t.Errorf("expected two yearly requests, got %v", md.requests)
+	}
+	if stored := repo.prices[1]; stored.Source != priceSourceBackfill || stored.Currency != "USD" || !stored.Date.Equal(from) {
+		t.Errorf("expected a backfilled bar in the instrument currency, got %+v", stored)
+	}
+
+	// A later end resumes after the last backfilled day
+	md.requests = nil
+	req.To = to.AddDate(0, 0, 3)
+	if results, err = service.BackfillPrices(ctx, req); err != nil {
+		t.Fatalf("BackfillPrices failed: %v", err)
+	}
+	if len(md.requests) != 1 || !md.requests[0][0].Equal(to.AddDate(0, 0, 1)) || results[0].Bars != 3 {
+		t.Errorf("expected to resume on %s, got %v and %+v", to.AddDate(0, 0, 1), md.requests, results)
+	}
+	if results, _ = service.BackfillPrices(ctx, req); results[0].Bars != 0 || len(md.requests) != 1 {
+		t.Errorf("expected nothing left to backfill, got %v and %+v", md.requests, results)
+	}
+
+	// An earlier start fills the days before the first backfilled one
+	md.requests = nil
+	req.From = from.AddDate(0, 0, -5)
+	if results, err = service.BackfillPrices(ctx, req); err != nil {
+		t.Fatalf("BackfillPrices failed: %v", err)
+	}
+	if len(md.requests) != 1 || !md.requests[0][0].Equal(req.From) || !md.requests[0][1].Equal(from.AddDate(0, 0, -1)) || results[0].Bars != 5 {
+		t.Errorf("expected to fill %s to %s, got %v and %+v", req.From, from.AddDate(0, 0, -1), md.requests, results)
+	}
+}
+
+func TestBackfillPrices_FillsHoles(t *testing.T) {
+	// The second of three yearly requests fails
+	md := &historyMarketData{errs: []error{nil, errors.New("server error")}}
+	service := newDividendService(t, md)
+	service.repo = &mockPriceHistoryRepository{}
+	ctx := context.Background()
+	req := BackfillRequest{From: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC), To: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)}
+
+	results, err := service.BackfillPrices(ctx, req)
+	if err != nil {
+		t.Fatalf("BackfillPrices failed: %v", err)
+	}
+	if len(md.requests) != 3 || results[0].Error == "" || results[0].Bars != 365+366 {
+		t.Fatalf("expected 2022 and 2024 to be stored around the failure, got %v and %+v", md.requests, results)
+	}
+
+	// Only the hole is fetched again
+	md.requests = nil
+	hole := [2]time.Time{time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2023, 12, 31, 0, 0, 0, 0, time.UTC)}
+	if results, err = service.BackfillPrices(ctx, req); err != nil {
+		t.Fatalf("BackfillPrices failed: %v", err)
+	}
+	if len(md.requests) != 1 || md.requests[0] != hole || results[0].Error != "" || results[0].Bars != 365 {
+		t.Errorf("expected to fill %v, got %v and %+v", hole, md.requests, results)
+	}
+	if results, _ = service.BackfillPrices(ctx, req); results[0].Bars != 0 || len(md.requests) != 1 {
+		t.Errorf("expected nothing left to backfill, got %v and %+v", md.requests, results)
+	}
+}
+
+func TestMissingRanges(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	// Friday 1 to Monday 4 is a weekend; 5 to 14 is a hole
+	stored := []time.Time{day(1), day(4), day(5), day(15)}
+
+	missing := missingRanges(day(1), day(20), stored)
+	expected := [][2]time.Time{{day(6), day(14)}, {day(16), day(20)}}
+	if len(missing) != len(expected) || missing[0] != expected[0] || missing[1] != expected[1] {
+		t.Errorf("expected %v, got %v", expected, missing)
+	}
+}
+
+func TestBackfillPrices_RateLimited(t *testing.T) {
+	limited := errors.Join(marketdata.ErrRateLimited, errors.New("credits exhausted"))
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		errs     []error
+		bars     int
+		requests int
+		failed   bool
+	}{
+		{"retried until it passes", []error{limited, limited}, 10, 3, false},
+		{"gives up after the retries", []error{limited, limited, limited}, 0, 3, true},
+		{"does not retry other errors", []error{errors.New("symbol not found")}, 0, 1, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			md := &historyMarketData{errs: tt.errs}
+			service := newDividendService(t, md)
+			service.repo = &mockPriceHistoryRepository{}
+
+			results, err := service.BackfillPrices(context.Background(), BackfillRequest{
+				From: from, To: to, Delay: time.Millisecond, Retries: 2, Backoff: time.Millisecond,
+			})
+			if err != nil {
+				t.Fatalf("BackfillPrices failed: %v", err)
+			}
+			if results[0].Bars != tt.bars || (results[0].Error != "") != tt.failed || len(md.requests) != tt.requests {
+				t.Errorf("expected %d bars after %d requests, got %+v after %d", tt.bars, tt.requests, results[0], len(md.requests))
+			}
+		})
+	}
+}
+
+func TestBackfillPrices_Errors(t *testing.T) {
+	ctx := context.Background()
+
+	service := newDividendService(t, &MockMarketData{})
+	service.repo = &mockPriceHistoryRepository{}
+	if _, err := service.BackfillPrices(ctx, BackfillRequest{}); !errors.Is(err, ErrHistoryUnsupported) {
+		t.Errorf("expected ErrHistoryUnsupported, got %v", err)
+	}
+
+	service = newDividendService(t, &historyMarketData{})
+	if _, err := service.BackfillPrices(ctx, BackfillRequest{}); !errors.Is(err, ErrPriceHistoryUnsupported) {
+		t.Errorf("expected ErrPriceHistoryUnsupported, got %v", err)
+	}
+
+	service.repo = &mockPriceHistoryRepository{}
+	if _, err := service.BackfillPrices(ctx, BackfillRequest{ISINs: []string{"US0378331006"}}); !errors.Is(err, domain.ErrInvalidIdentifier) {
+		t.Errorf("expected ErrInvalidIdentifier, got %v", err)
+	}
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := service.BackfillPrices(canceled, BackfillRequest{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// ErrPriceHistoryUnsupported is returned when the repository does not
+// store daily closes.
+var ErrPriceHistoryUnsupported = errors.New("repository does not store price history")
+
+// priceSourceQuote marks closes recorded from the quotes of a price refresh.
+const priceSourceQuote = "quote"
+
+// SetBenchmark resolves the identifier of an index fund or ETF and compares
+// the portfolio against it from now on. An empty identifier removes the
+// benchmark and returns nil.
+func (s *PortfolioService) SetBenchmark(ctx context.Context, identifier string) (*domain.Instrument, error) {
+	var instrument *domain.Instrument
+	isin := ""
+	if identifier != "" {
+		var err error
+		if instrument, err = s.ResolveInstrument(ctx, identifier); err != nil {
+			return nil, err
+		}
+		isin = instrument.ISIN
+	}
+
+	if err := s.defaultPortfolio.SetBenchmark(isin); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Save(ctx, s.defaultPortfolio); err != nil {
+		return nil, fmt.Errorf("failed to save portfolio: %w", err)
+	}
+	s.benchmark = instrument
+
+	slog.InfoContext(ctx, "benchmark set", "isin", isin)
+	return instrument, nil
+}
+
+// CompareBenchmark measures the portfolio against its benchmark over the
+// named period (1M, 3M, YTD, 1Y or ALL), ending now, from stored closes.
+func (s *PortfolioService) CompareBenchmark(ctx context.Context, periodName string) (*domain.BenchmarkComparison, error) {
+	period, err := domain.ParsePerformancePeriod(periodName)
+	if err != nil {
+		return nil, err
+	}
+	if s.defaultPortfolio.BenchmarkISIN == "" {
+		return nil, domain.ErrNoBenchmark
+	}
+
+	isins := []string{s.defaultPortfolio.BenchmarkISIN}
+	for i := range s.defaultPortfolio.Positions {
+		isins = append(isins, s.defaultPortfolio.Positions[i].Instrument.ISIN)
+	}
+	now := time.Now()
+	history, err := s.priceHistory(ctx, isins, time.Time{}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := s.historicalExchangeRates(ctx, history.Dates(s.defaultPortfolio.BenchmarkISIN))
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := s.portfolioSnapshots(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	comparison, err := s.defaultPortfolio.CompareBenchmark(period, now, rates, history, snapshots)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare with benchmark: %w", err)
+	}
+	slog.DebugContext(ctx, "benchmark compared", "period", period, "benchmark", comparison.BenchmarkISIN, "excess_return", comparison.ExcessReturn)
+	return comparison, nil
+}
+
+// priceHistory loads the stored closes of the given instruments between
+// from and to.
+func (s *PortfolioService) priceHistory(ctx context.Context, isins []string, from, to time.Time) (domain.PriceHistory, error) {
+	repo, ok := s.repo.(domain.PriceHistoryRepository)
+	if !ok {
+		return nil, ErrPriceHistoryUnsupported
+	}
+
+	var points []domain.PricePoint
+	seen := make(map[string]bool)
+	for _, isin := range isins {
+		if seen[isin] {
+			continue
+		}
+		seen[isin] = true
+		prices, err := repo.FindPrices(ctx, isin, from, to)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load prices of %s: %w", isin, err)
+		}
+		points = append(points, prices...)
+	}
+	return domain.NewPriceHistory(points), nil
+}
+
+// benchmarkClose quotes the benchmark unless an open position already
+// did, so that its close is stored with those of the positions.
+func (s *PortfolioService) benchmarkClose(ctx context.Context, now time.Time) (*domain.PricePoint, error) {
+	isin := s.defaultPortfolio.BenchmarkISIN
+	if isin == "" {
+		return nil, nil
+	}
+	if pos, err := s.defaultPortfolio.FindPositionByISIN(isin); err == nil && !pos.IsClosed() {
+		return nil, nil
+	}
+
+	if s.benchmark == nil || s.benchmark.ISIN != isin {
+		instrument, err := s.marketData.SearchByISIN(ctx, isin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find benchmark: %w", err)
+		}
+		s.benchmark = instrument
+	}
+	quote, err := s.marketData.GetQuote(ctx, s.benchmark.Symbol)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quote for benchmark %s: %w", s.benchmark.Symbol, err)
+	}
+	price, err := domain.NewDecimalFromString(quote.Price.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse quote price for benchmark %s: %w", s.benchmark.Symbol, err)
+	}
+
+	point := domain.NewPricePoint(isin, now, price, s.benchmark.Currency, priceSourceQuote)
+	return &point, nil
+}
+
+// recordCloses stores the latest prices as the closes of the day. Failures
+// are logged rather than returned, as the refreshed prices are already
+// saved with the portfolio.
+func (s *PortfolioService) recordCloses(ctx context.Context, now time.Time, points []domain.PricePoint) {
+	repo, ok := s.repo.(domain.PriceHistoryRepository)
+	if !ok {
+		return
+	}
+
+	benchmark, err := s.benchmarkClose(ctx, now)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to quote benchmark", "isin", s.defaultPortfolio.BenchmarkISIN, "error", err)
+	} else if benchmark != nil {
+		points = append(points, *benchmark)
+	}
+	if len(points) == 0 {
+		return
+	}
+
+	if err := repo.SavePrices(ctx, points); err != nil {
+		slog.WarnContext(ctx, "failed to store closing prices", "count", len(points), "error", err)
+	}
+}
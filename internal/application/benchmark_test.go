@@ -0,0 +1,100 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// mockPriceHistoryRepository keeps stored closes in memory and finds them
+// in date order
+type mockPriceHistoryRepository struct {
+	MockRepository
+	prices []domain.PricePoint
+}
+
+func (m *mockPriceHistoryRepository) SavePrices(_ context.Context, prices []domain.PricePoint) error {
+	m.prices = append(m.prices, prices...)
+	return nil
+}
+
+func (m *mockPriceHistoryRepository) FindPrices(_ context.Context, isin string, from, to time.Time) ([]domain.PricePoint, error) {
+	var found []domain.PricePoint
+	for _, p := range m.prices {
+		if p.ISIN == isin && !p.Date.Before(from) && !p.Date.After(to) {
+			found = append(found, p)
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool {
+		return found[i].Date.Before(found[j].Date)
+	})
+	return found, nil
+}
+
+func TestRefreshPrices_RecordsCloses(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	repo := &mockPriceHistoryRepository{}
+	service.repo = repo
+
+	if _, err := service.SetBenchmark(context.Background(), "IE00B4L5Y983"); err != nil {
+		t.Fatalf("SetBenchmark failed: %v", err)
+	}
+	if err := service.RefreshPrices(context.Background()); err != nil {
+		t.Fatalf("RefreshPrices failed: %v", err)
+	}
+
+	// The position and the benchmark, which is not held, are both stored
+	if len(repo.prices) != 2 || repo.prices[0].ISIN != "US0378331005" || repo.prices[1].ISIN != "IE00B4L5Y983" {
+		t.Fatalf("expected closes for the position and the benchmark, got %+v", repo.prices)
+	}
+	if repo.prices[1].Source != priceSourceQuote || !repo.prices[1].Close.Equal(domain.NewDecimalFromInt(150)) {
+		t.Errorf("expected a quoted close of 150, got %+v", repo.prices[1])
+	}
+}
+
+func TestCompareBenchmark(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&MockFXRates{})
+	ctx := context.Background()
+
+	if _, err := service.CompareBenchmark(ctx, "ALL"); !errors.Is(err, domain.ErrNoBenchmark) {
+		t.Errorf("expected ErrNoBenchmark, got %v", err)
+	}
+	if _, err := service.SetBenchmark(ctx, "IE00B4L5Y983"); err != nil {
+		t.Fatalf("SetBenchmark failed: %v", err)
+	}
+	if _, err := service.CompareBenchmark(ctx, "ALL"); !errors.Is(err, ErrPriceHistoryUnsupported) {
+		t.Errorf("expected ErrPriceHistoryUnsupported, got %v", err)
+	}
+
+	jan10 := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+	jun10 := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	service.repo = &mockPriceHistoryRepository{prices: []domain.PricePoint{
+		domain.NewPricePoint("IE00B4L5Y983", jan10, domain.NewDecimalFromInt(100), "EUR", priceSourceQuote),
+		domain.NewPricePoint("IE00B4L5Y983", jun10, domain.NewDecimalFromInt(110), "EUR", priceSourceQuote),
+		domain.NewPricePoint("US0378331005", jun10, domain.NewDecimalFromInt(165), "USD", priceSourceQuote),
+	}}
+
+	comparison, err := service.CompareBenchmark(ctx, "ALL")
+	if err != nil {
+		t.Fatalf("CompareBenchmark failed: %v", err)
+	}
+	if comparison.Currency != "EUR" || comparison.BenchmarkCurrency != "EUR" || len(comparison.Points) != 3 {
+		t.Fatalf("expected 3 points in EUR, got %+v", comparison)
+	}
+	// Both gained 10% by June; the position is now marked back at 150
+	june := comparison.Points[1]
+	if !june.PortfolioReturn.Equal(domain.NewDecimalFromInt(10)) || !june.Difference.IsZero() {
+		t.Errorf("expected both up 10%% in June, got %+v", june)
+	}
+	if !comparison.ExcessReturn.Equal(domain.NewDecimalFromInt(-10)) {
+		t.Errorf("expected -10 points of excess return, got %s", comparison.ExcessReturn)
+	}
+	if comparison.TrackingDifference.Cmp(domain.Zero) >= 0 {
+		t.Errorf("expected a negative tracking difference, got %s", comparison.TrackingDifference)
+	}
+}
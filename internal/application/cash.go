@@ -0,0 +1,67 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// RecordCashMovementRequest describes a deposit into or a withdrawal from
+// the cash account of a currency. Date defaults to now.
+type RecordCashMovementRequest struct {
+	Type     domain.TransactionType `json:"type" binding:"required"`
+	Amount   domain.Decimal         `json:"amount" binding:"required"`
+	Currency string                 `json:"currency" binding:"required"`
+	Date     time.Time              `json:"date"`
+}
+
+// RecordCashMovement books a deposit or withdrawal in the ledger. The first
+// movement in a currency opens its cash account.
+func (s *PortfolioService) RecordCashMovement(ctx context.Context, req RecordCashMovementRequest) (*domain.Transaction, error) {
+	date := req.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+
+	tx := domain.NewTransaction(req.Type, "", date, domain.Zero, domain.Zero, req.Amount, req.Currency)
+	if err := s.defaultPortfolio.RecordCashMovement(tx); err != nil {
+		return nil, fmt.Errorf("failed to record cash movement: %w", err)
+	}
+
+	if err := s.repo.Save(ctx, s.defaultPortfolio); err != nil {
+		return nil, fmt.Errorf("failed to save portfolio: %w", err)
+	}
+
+	slog.InfoContext(ctx, "cash movement recorded", "type", tx.Type, "amount", tx.Amount, "currency", tx.Currency)
+	recorded := s.defaultPortfolio.Transactions[len(s.defaultPortfolio.Transactions)-1]
+	return &recorded, nil
+}
+
+// GetCashBalances returns the balance of every cash account and their
+// total in the base currency.
+func (s *PortfolioService) GetCashBalances(ctx context.Context) (*domain.CashSummary, error) {
+	rates, err := s.exchangeRates(ctx, s.defaultPortfolio.Currency(), s.defaultPortfolio.CashAccountCurrencies())
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := s.defaultPortfolio.Cash(rates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate cash balances: %w", err)
+	}
+	return summary, nil
+}
+
+// ListCashMovements returns the entries that moved cash in currency, or in
+// every cash account when currency is empty, with running balances.
+func (s *PortfolioService) ListCashMovements(ctx context.Context, currency string) ([]domain.CashMovement, error) {
+	movements, err := s.defaultPortfolio.CashMovements(currency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cash movements: %w", err)
+	}
+	slog.DebugContext(ctx, "listing cash movements", "currency", currency, "count", len(movements))
+	return movements, nil
+}
@@ -0,0 +1,58 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+func TestRecordCashMovement(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&MockFXRates{})
+
+	_, err := service.RecordCashMovement(context.Background(), RecordCashMovementRequest{
+		Type:     domain.TransactionTypeDeposit,
+		Amount:   domain.NewDecimalFromInt(2000),
+		Currency: "USD",
+		Date:     time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("RecordCashMovement failed: %v", err)
+	}
+
+	// The 1500 USD buy of the setup is now paid from the USD account
+	summary, err := service.GetCashBalances(context.Background())
+	if err != nil {
+		t.Fatalf("GetCashBalances failed: %v", err)
+	}
+	if len(summary.Balances) != 1 || !summary.Balances[0].Equal(domain.NewMoney(domain.NewDecimalFromInt(500), "USD")) {
+		t.Errorf("expected 500 USD, got %v", summary.Balances)
+	}
+	if summary.Currency != "EUR" || !summary.Total.Equal(domain.NewDecimalFromInt(250)) {
+		t.Errorf("expected 250 EUR in total, got %s %s", summary.Total, summary.Currency)
+	}
+
+	movements, err := service.ListCashMovements(context.Background(), "")
+	if err != nil {
+		t.Fatalf("ListCashMovements failed: %v", err)
+	}
+	if len(movements) != 2 || movements[0].Type != domain.TransactionTypeDeposit {
+		t.Errorf("expected the deposit followed by the buy, got %+v", movements)
+	}
+}
+
+func TestRecordCashMovement_Invalid(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+
+	_, err := service.RecordCashMovement(context.Background(), RecordCashMovementRequest{
+		Type:     domain.TransactionTypeBuy,
+		Amount:   domain.NewDecimalFromInt(100),
+		Currency: "USD",
+	})
+	if !errors.Is(err, domain.ErrInvalidTransaction) {
+		t.Errorf("expected ErrInvalidTransaction, got %v", err)
+	}
+}
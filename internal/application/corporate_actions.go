@@ -0,0 +1,84 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// ApplyCorporateActionRequest describes a split, reverse split, symbol
+// change or ISIN change of an instrument held in the portfolio.
+// Splits need RatioFrom and RatioTo: a 4-for-1 split is 1 to 4.
+// An ISIN change without NewSymbol keeps the symbol the provider reports
+// for the new ISIN, or the current one when it cannot be found.
+type ApplyCorporateActionRequest struct {
+	Type          domain.CorporateActionType `json:"type" binding:"required"`
+	ISIN          string                     `json:"isin" binding:"required"`
+	EffectiveDate time.Time                  `json:"effective_date" binding:"required"`
+	RatioFrom     domain.Decimal             `json:"ratio_from"`
+	RatioTo       domain.Decimal             `json:"ratio_to"`
+	NewSymbol     string                     `json:"new_symbol"`
+	NewISIN       string                     `json:"new_isin"`
+	Note          string                     `json:"note"`
+}
+
+// ApplyCorporateAction adjusts the position holding the instrument and
+// stores the action in the portfolio history.
+func (s *PortfolioService) ApplyCorporateAction(ctx context.Context, req ApplyCorporateActionRequest) (*domain.CorporateAction, error) {
+	action := domain.NewCorporateAction(req.Type, req.ISIN, req.EffectiveDate)
+	action.RatioFrom = req.RatioFrom
+	action.RatioTo = req.RatioTo
+	action.NewSymbol = req.NewSymbol
+	action.NewISIN = req.NewISIN
+	action.Note = req.Note
+
+	if action.NewISIN != "" {
+		action.NewISIN = strings.ToUpper(strings.TrimSpace(action.NewISIN))
+		if err := domain.ValidateISIN(action.NewISIN); err != nil {
+			return nil, err
+		}
+	}
+	if action.Type == domain.CorporateActionISINChange && action.NewSymbol == "" && action.NewISIN != "" {
+		instrument, err := s.marketData.SearchByISIN(ctx, action.NewISIN)
+		if err != nil {
+			slog.WarnContext(ctx, "keeping current symbol for new ISIN", "isin", action.NewISIN, "error", err)
+		} else {
+			action.NewSymbol = instrument.Symbol
+		}
+	}
+
+	applied, err := s.defaultPortfolio.ApplyCorporateAction(action)
+	if err != nil {
+		return nil, fmt.Errorf("failed to apply corporate action: %w", err)
+	}
+
+	if store, ok := s.repo.(domain.CorporateActionRepository); ok {
+		err = store.ApplyCorporateAction(ctx, s.defaultPortfolio, applied)
+	} else {
+		err = s.repo.Save(ctx, s.defaultPortfolio)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to save corporate action: %w", err)
+	}
+
+	slog.InfoContext(ctx, "corporate action applied", "type", applied.Type, "isin", applied.ISIN, "position_id", applied.PositionID)
+	result := *applied
+	return &result, nil
+}
+
+// ListCorporateActions returns the applied corporate actions ordered by
+// effective date.
+func (s *PortfolioService) ListCorporateActions(ctx context.Context) ([]domain.CorporateAction, error) {
+	slog.DebugContext(ctx, "listing corporate actions", "count", len(s.defaultPortfolio.CorporateActions))
+	actions := make([]domain.CorporateAction, len(s.defaultPortfolio.CorporateActions))
+	copy(actions, s.defaultPortfolio.CorporateActions)
+	sort.SliceStable(actions, func(i, j int) bool {
+		return actions[i].EffectiveDate.Before(actions[j].EffectiveDate)
+	})
+	return actions, nil
+}
@@ -0,0 +1,90 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// mockCorporateActionRepository records the actions stored atomically
+type mockCorporateActionRepository struct {
+	MockRepository
+	applied []domain.CorporateAction
+}
+
+func (m *mockCorporateActionRepository) ApplyCorporateAction(_ context.Context, _ *domain.Portfolio, action *domain.CorporateAction) error {
+	m.applied = append(m.applied, *action)
+	return nil
+}
+
+func TestApplyCorporateAction_Split(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	repo := &mockCorporateActionRepository{}
+	service.repo = repo
+
+	action, err := service.ApplyCorporateAction(context.Background(), ApplyCorporateActionRequest{
+		Type:          domain.CorporateActionSplit,
+		ISIN:          "US0378331005",
+		EffectiveDate: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC),
+		RatioFrom:     domain.NewDecimalFromInt(1),
+		RatioTo:       domain.NewDecimalFromInt(4),
+	})
+	if err != nil {
+		t.Fatalf("ApplyCorporateAction failed: %v", err)
+	}
+	if len(repo.applied) != 1 || repo.applied[0].ID != action.ID {
+		t.Fatalf("expected the action to be stored atomically, got %d", len(repo.applied))
+	}
+
+	positions, _ := service.ListPositions(context.Background())
+	if !positions[0].Quantity.Equal(domain.NewDecimalFromInt(40)) {
+		t.Errorf("expected 40 units after split, got %s", positions[0].Quantity)
+	}
+
+	actions, _ := service.ListCorporateActions(context.Background())
+	if len(actions) != 1 || actions[0].OldSymbol != "TESTSYM" {
+		t.Errorf("expected 1 action with the old symbol, got %+v", actions)
+	}
+}
+
+func TestApplyCorporateAction_ISINChange(t *testing.T) {
+	marketData := &MockMarketData{}
+	service := newDividendService(t, marketData)
+	marketData.searchError = errors.New("not found")
+
+	_, err := service.ApplyCorporateAction(context.Background(), ApplyCorporateActionRequest{
+		Type:          domain.CorporateActionISINChange,
+		ISIN:          "US0378331005",
+		EffectiveDate: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC),
+		NewISIN:       "US30303M1027",
+	})
+	if err != nil {
+		t.Fatalf("ApplyCorporateAction failed: %v", err)
+	}
+
+	pos, err := service.defaultPortfolio.FindPositionByISIN("US30303M1027")
+	if err != nil {
+		t.Fatalf("expected position under the new ISIN: %v", err)
+	}
+	if pos.Instrument.Symbol != "TESTSYM" {
+		t.Errorf("expected the current symbol to be kept, got %s", pos.Instrument.Symbol)
+	}
+}
+
+func TestApplyCorporateAction_Invalid(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+
+	_, err := service.ApplyCorporateAction(context.Background(), ApplyCorporateActionRequest{
+		Type:          domain.CorporateActionReverseSplit,
+		ISIN:          "US0378331005",
+		EffectiveDate: time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC),
+		RatioFrom:     domain.NewDecimalFromInt(1),
+		RatioTo:       domain.NewDecimalFromInt(4),
+	})
+	if !errors.Is(err, domain.ErrInvalidCorporateAction) {
+		t.Errorf("expected ErrInvalidCorporateAction, got %v", err)
+	}
+}
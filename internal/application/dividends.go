@@ -0,0 +1,170 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata"
+)
+
+// ErrDividendsUnsupported is returned when the market data provider has no
+// dividend history.
+var ErrDividendsUnsupported = errors.New("market data provider does not support dividends")
+
+// RecordDividendRequest describes a manually entered distribution.
+// GrossAmount is the total paid on the holding before WithholdingTax.
+// PayDate defaults to the ex-date and Currency to the instrument currency.
+type RecordDividendRequest struct {
+	ISIN           string         `json:"isin" binding:"required"`
+	ExDate         time.Time      `json:"ex_date" binding:"required"`
+	PayDate        time.Time      `json:"pay_date"`
+	GrossAmount    domain.Decimal `json:"gross_amount" binding:"required"`
+	WithholdingTax domain.Decimal `json:"withholding_tax"`
+	Currency       string         `json:"currency"`
+}
+
+// RecordDividend books a distribution on a position held in the portfolio.
+func (s *PortfolioService) RecordDividend(ctx context.Context, req RecordDividendRequest) (*domain.Dividend, error) {
+	currency := req.Currency
+	if currency == "" {
+		pos, err := s.defaultPortfolio.FindPositionByISIN(req.ISIN)
+		if err != nil {
+			return nil, err
+		}
+		currency, _ = domain.NormalizeCurrency(pos.ValueCurrency())
+	}
+
+	dividend := domain.NewDividend(req.ISIN, req.ExDate, req.PayDate, req.GrossAmount, req.WithholdingTax, currency, domain.DividendSourceManual)
+	recorded, err := s.defaultPortfolio.RecordDividend(dividend)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record dividend: %w", err)
+	}
+
+	if err := s.repo.Save(ctx, s.defaultPortfolio); err != nil {
+		return nil, fmt.Errorf("failed to save portfolio: %w", err)
+	}
+
+	result := *recorded
+	return &result, nil
+}
+
+// ListDividends returns the recorded distributions ordered by pay date.
+func (s *PortfolioService) ListDividends(ctx context.Context) ([]domain.Dividend, error) {
+	slog.DebugContext(ctx, "listing dividends", "count", len(s.defaultPortfolio.Dividends))
+	dividends := make([]domain.Dividend, len(s.defaultPortfolio.Dividends))
+	copy(dividends, s.defaultPortfolio.Dividends)
+	sort.SliceStable(dividends, func(i, j int) bool {
+		return dividends[i].PayDate.Before(dividends[j].PayDate)
+	})
+	return dividends, nil
+}
+
+// SyncDividends fetches the dividend history of every position from the
+// market data provider and records the distributions not seen yet.
+// The gross amount is the per-unit amount times the units held before the
+// ex-date; withholding tax is unknown to the provider and left at zero.
+// It returns the number of dividends added.
+func (s *PortfolioService) SyncDividends(ctx context.Context) (int, error) {
+	provider, ok := s.marketData.(marketdata.DividendProvider)
+	if !ok {
+		return 0, ErrDividendsUnsupported
+	}
+
+	now := time.Now()
+	added := 0
+	for _, pos := range s.defaultPortfolio.Positions {
+		from, ok := s.holdingSince(pos)
+		if !ok {
+			slog.DebugContext(ctx, "skipping dividend sync for position without history", "isin", pos.Instrument.ISIN)
+			continue
+		}
+
+		events, err := provider.GetDividends(ctx, pos.Instrument.Symbol, from, now)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to fetch dividends", "symbol", pos.Instrument.Symbol, "error", err)
+			continue
+		}
+
+		for _, event := range events {
+			if s.defaultPortfolio.HasDividend(pos.Instrument.ISIN, event.ExDate) {
+				continue
+			}
+			held, err := s.defaultPortfolio.QuantityHeldAt(pos.Instrument.ISIN, event.ExDate)
+			if err != nil {
+				return added, err
+			}
+			if held.Cmp(domain.Zero) <= 0 {
+				continue
+			}
+			gross, err := event.Amount.Mul(held)
+			if err != nil {
+				return added, fmt.Errorf("failed to calculate dividend amount: %w", err)
+			}
+
+			currency := event.Currency
+			if currency == "" {
+				currency = pos.ValueCurrency()
+			}
+			currency, _ = domain.NormalizeCurrency(currency)
+			dividend := domain.NewDividend(pos.Instrument.ISIN, event.ExDate, event.PayDate, gross, domain.Zero, currency, domain.DividendSourceProvider)
+			if _, err := s.defaultPortfolio.RecordDividend(dividend); err != nil {
+				slog.WarnContext(ctx, "skipping dividend", "isin", pos.Instrument.ISIN, "ex_date", event.ExDate, "error", err)
+				continue
+			}
+			added++
+		}
+	}
+
+	if added > 0 {
+		if err := s.repo.Save(ctx, s.defaultPortfolio); err != nil {
+			return 0, fmt.Errorf("failed to save portfolio: %w", err)
+		}
+	}
+	slog.InfoContext(ctx, "dividends synced", "added", added)
+	return added, nil
+}
+
+// holdingSince returns the earliest date the position is known to have
+// been held, from its ledger entries or lots.
+func (s *PortfolioService) holdingSince(pos domain.Position) (time.Time, bool) {
+	var since time.Time
+	for _, tx := range s.defaultPortfolio.Transactions {
+		if tx.PositionID == pos.ID && tx.IsTrade() && (since.IsZero() || tx.TradeDate.Before(since)) {
+			since = tx.TradeDate
+		}
+	}
+	for _, lot := range pos.Lots {
+		if since.IsZero() || lot.AcquiredAt.Before(since) {
+			since = lot.AcquiredAt
+		}
+	}
+	return since, !since.IsZero()
+}
+
+// GetIncomeReport aggregates dividend income by month and year in the
+// base currency.
+func (s *PortfolioService) GetIncomeReport(ctx context.Context) (*domain.IncomeReport, error) {
+	seen := make(map[string]bool)
+	currencies := make([]string, 0)
+	for _, d := range s.defaultPortfolio.Dividends {
+		if !seen[d.Currency()] {
+			seen[d.Currency()] = true
+			currencies = append(currencies, d.Currency())
+		}
+	}
+	rates, err := s.exchangeRates(ctx, s.defaultPortfolio.Currency(), currencies)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.defaultPortfolio.Income(rates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate income: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,142 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata"
+)
+
+// mockDividendMarketData implements both MDataProvider and DividendProvider
+type mockDividendMarketData struct {
+	MockMarketData
+	events []marketdata.DividendEvent
+}
+
+func (m *mockDividendMarketData) GetDividends(_ context.Context, _ string, from, to time.Time) ([]marketdata.DividendEvent, error) {
+	result := make([]marketdata.DividendEvent, 0)
+	for _, e := range m.events {
+		if !e.ExDate.Before(from) && !e.ExDate.After(to) {
+			result = append(result, e)
+		}
+	}
+	return result, nil
+}
+
+func newDividendService(t *testing.T, marketData marketdata.MDataProvider) *PortfolioService {
+	t.Helper()
+	service, err := NewPortfolioService(&MockRepository{}, marketData)
+	if err != nil {
+		t.Fatalf("NewPortfolioService failed: %v", err)
+	}
+	_, err = service.RecordTransaction(context.Background(), RecordTransactionRequest{
+		ISIN:      "US0378331005",
+		Type:      domain.TransactionTypeBuy,
+		TradeDate: time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		Quantity:  domain.NewDecimalFromInt(10),
+		Price:     domain.NewDecimalFromInt(150),
+		Currency:  "USD",
+	})
+	if err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	return service
+}
+
+func TestRecordDividend_DefaultsToInstrumentCurrency(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+
+	dividend, err := service.RecordDividend(context.Background(), RecordDividendRequest{
+		ISIN:           "US0378331005",
+		ExDate:         time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC),
+		GrossAmount:    domain.NewDecimalFromInt(10),
+		WithholdingTax: domain.NewDecimalFromInt(2),
+	})
+	if err != nil {
+		t.Fatalf("RecordDividend failed: %v", err)
+	}
+	if dividend.Currency() != "USD" || dividend.Source != domain.DividendSourceManual {
+		t.Errorf("expected manual USD dividend, got %s %s", dividend.Source, dividend.Currency())
+	}
+
+	dividends, _ := service.ListDividends(context.Background())
+	if len(dividends) != 1 {
+		t.Errorf("expected 1 dividend, got %d", len(dividends))
+	}
+}
+
+func TestRecordDividend_Invalid(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+
+	_, err := service.RecordDividend(context.Background(), RecordDividendRequest{
+		ISIN:           "US0378331005",
+		ExDate:         time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC),
+		GrossAmount:    domain.NewDecimalFromInt(10),
+		WithholdingTax: domain.NewDecimalFromInt(20),
+		Currency:       "USD",
+	})
+	if !errors.Is(err, domain.ErrInvalidDividend) {
+		t.Errorf("expected ErrInvalidDividend, got %v", err)
+	}
+}
+
+func TestSyncDividends(t *testing.T) {
+	marketData := &mockDividendMarketData{events: []marketdata.DividendEvent{
+		// Before the position was opened
+		{ExDate: time.Date(2023, 11, 10, 0, 0, 0, 0, time.UTC), PayDate: time.Date(2023, 11, 16, 0, 0, 0, 0, time.UTC), Amount: domain.NewDecimalFromInt(1), Currency: "USD"},
+		{ExDate: time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC), PayDate: time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), Amount: domain.NewDecimalFromInt(2), Currency: "USD"},
+		{ExDate: time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC), PayDate: time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC), Amount: domain.NewDecimalFromInt(2), Currency: ""},
+	}}
+	service := newDividendService(t, marketData)
+
+	added, err := service.SyncDividends(context.Background())
+	if err != nil {
+		t.Fatalf("SyncDividends failed: %v", err)
+	}
+	if added != 2 {
+		t.Fatalf("expected 2 dividends added, got %d", added)
+	}
+	first := service.defaultPortfolio.Dividends[0]
+	if !first.GrossAmount.Equal(domain.NewMoney(domain.NewDecimalFromInt(20), "USD")) {
+		t.Errorf("expected 20 USD gross for 10 units, got %s", first.GrossAmount)
+	}
+
+	// A second sync must not record the same distributions again
+	if added, err = service.SyncDividends(context.Background()); err != nil || added != 0 {
+		t.Errorf("expected no new dividends, got %d, %v", added, err)
+	}
+}
+
+func TestSyncDividends_Unsupported(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+
+	if _, err := service.SyncDividends(context.Background()); !errors.Is(err, ErrDividendsUnsupported) {
+		t.Errorf("expected ErrDividendsUnsupported, got %v", err)
+	}
+}
+
+func TestGetIncomeReport_ConvertsToBaseCurrency(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&MockFXRates{})
+
+	_, err := service.RecordDividend(context.Background(), RecordDividendRequest{
+		ISIN:        "US0378331005",
+		ExDate:      time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC),
+		GrossAmount: domain.NewDecimalFromInt(30),
+		Currency:    "USD",
+	})
+	if err != nil {
+		t.Fatalf("RecordDividend failed: %v", err)
+	}
+
+	report, err := service.GetIncomeReport(context.Background())
+	if err != nil {
+		t.Fatalf("GetIncomeReport failed: %v", err)
+	}
+	if report.Currency != "EUR" || !report.Total.NetAmount.Equal(domain.NewDecimalFromInt(15)) {
+		t.Errorf("expected 15 EUR income, got %s %s", report.Total.NetAmount, report.Currency)
+	}
+}
@@ -0,0 +1,108 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/holdings"
+)
+
+// ErrHoldingsUnsupported is returned when the repository does not store
+// ETF holdings.
+var ErrHoldingsUnsupported = errors.New("repository does not store etf holdings")
+
+// ImportETFHoldings reads the constituents of an ETF from an issuer file
+// in the named format, or in the format its headers match when format is
+// empty, and replaces those stored for the ETF. Holdings are dated today
+// when the file does not state their date.
+func (s *PortfolioService) ImportETFHoldings(ctx context.Context, etfISIN, format string, r io.Reader) (*domain.ETFComposition, error) {
+	repo, ok := s.repo.(domain.ETFHoldingsRepository)
+	if !ok {
+		return nil, ErrHoldingsUnsupported
+	}
+
+	parsedFormat, err := holdings.ParseFormat(format)
+	if err != nil {
+		return nil, err
+	}
+	file, err := holdings.Parse(r, parsedFormat)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse holdings: %w", err)
+	}
+	asOf := file.AsOf
+	if asOf.IsZero() {
+		asOf = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+
+	composition, err := domain.NewETFComposition(etfISIN, asOf, file.Holdings)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.SaveETFHoldings(ctx, composition); err != nil {
+		return nil, fmt.Errorf("failed to save etf holdings: %w", err)
+	}
+
+	slog.InfoContext(ctx, "etf holdings imported", "etf_isin", composition.ETFISIN, "format", file.Format, "holdings", len(composition.Holdings), "as_of", composition.AsOf)
+	return composition, nil
+}
+
+// GetETFHoldings returns the stored constituents of an ETF.
+func (s *PortfolioService) GetETFHoldings(ctx context.Context, etfISIN string) (*domain.ETFComposition, error) {
+	repo, ok := s.repo.(domain.ETFHoldingsRepository)
+	if !ok {
+		return nil, ErrHoldingsUnsupported
+	}
+
+	composition, err := repo.FindETFHoldings(ctx, strings.ToUpper(strings.TrimSpace(etfISIN)))
+	if err != nil {
+		if errors.Is(err, domain.ErrHoldingsNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to load etf holdings: %w", err)
+	}
+	return composition, nil
+}
+
+// etfCompositions loads the stored compositions of every ETF the given
+// instruments hold, directly or through other ETFs. It returns nil when
+// the repository does not store holdings.
+func (s *PortfolioService) etfCompositions(ctx context.Context, isins []string) (domain.ETFCompositions, error) {
+	repo, ok := s.repo.(domain.ETFHoldingsRepository)
+	if !ok {
+		return nil, nil
+	}
+	stored, err := repo.FindETFISINs(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list etf holdings: %w", err)
+	}
+	available := make(map[string]bool, len(stored))
+	for _, isin := range stored {
+		available[isin] = true
+	}
+
+	compositions := make(domain.ETFCompositions)
+	for len(isins) > 0 {
+		isin := isins[0]
+		isins = isins[1:]
+		if !available[isin] || compositions[isin] != nil {
+			continue
+		}
+		composition, err := repo.FindETFHoldings(ctx, isin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load etf holdings of %s: %w", isin, err)
+		}
+		compositions[isin] = composition
+		for _, h := range composition.Holdings {
+			if h.ISIN != "" {
+				isins = append(isins, h.ISIN)
+			}
+		}
+	}
+	return compositions, nil
+}
@@ -0,0 +1,124 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// mockETFHoldingsRepository keeps stored compositions in memory
+type mockETFHoldingsRepository struct {
+	MockRepository
+	compositions domain.ETFCompositions
+}
+
+func (m *mockETFHoldingsRepository) SaveETFHoldings(_ context.Context, composition *domain.ETFComposition) error {
+	if m.compositions == nil {
+		m.compositions = make(domain.ETFCompositions)
+	}
+	m.compositions[composition.ETFISIN] = composition
+	return nil
+}
+
+func (m *mockETFHoldingsRepository) FindETFHoldings(_ context.Context, etfISIN string) (*domain.ETFComposition, error) {
+	composition, ok := m.compositions[etfISIN]
+	if !ok {
+		return nil, domain.ErrHoldingsNotFound
+	}
+	return composition, nil
+}
+
+func (m *mockETFHoldingsRepository) FindETFISINs(_ context.Context) ([]string, error) {
+	isins := make([]string, 0, len(m.compositions))
+	for isin := range m.compositions {
+		isins = append(isins, isin)
+	}
+	sort.Strings(isins)
+	return isins, nil
+}
+
+const appleHoldingsFile = `Fund Holdings as of,"Mar 01, 2024"
+
+Ticker,Name,ISIN,Sector,Asset Class,Weight (%),Location,Market Currency
+"AAPL","APPLE INC","US0378331005","Information Technology","Equity","75.00","United States","USD"
+"EUR","EUR CASH","-","Cash and/or Derivatives","Cash","25.00","European Union","EUR"
+`
+
+func TestImportETFHoldings(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	repo := &mockETFHoldingsRepository{}
+	service.repo = repo
+	ctx := context.Background()
+
+	composition, err := service.ImportETFHoldings(ctx, "ie00b4l5y983", "", strings.NewReader(appleHoldingsFile))
+	if err != nil {
+		t.Fatalf("ImportETFHoldings failed: %v", err)
+	}
+	if composition.ETFISIN != "IE00B4L5Y983" || len(composition.Holdings) != 2 {
+		t.Fatalf("expected 2 holdings of IE00B4L5Y983, got %+v", composition)
+	}
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC); !composition.AsOf.Equal(want) {
+		t.Errorf("expected holdings as of %s, got %s", want, composition.AsOf)
+	}
+
+	found, err := service.GetETFHoldings(ctx, "IE00B4L5Y983")
+	if err != nil {
+		t.Fatalf("GetETFHoldings failed: %v", err)
+	}
+	if found != composition {
+		t.Errorf("expected the imported composition, got %+v", found)
+	}
+	if _, err := service.GetETFHoldings(ctx, "IE00B5BMR087"); !errors.Is(err, domain.ErrHoldingsNotFound) {
+		t.Errorf("expected ErrHoldingsNotFound, got %v", err)
+	}
+}
+
+func TestImportETFHoldings_Errors(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	ctx := context.Background()
+
+	if _, err := service.ImportETFHoldings(ctx, "IE00B4L5Y983", "", strings.NewReader(appleHoldingsFile)); !errors.Is(err, ErrHoldingsUnsupported) {
+		t.Errorf("expected ErrHoldingsUnsupported, got %v", err)
+	}
+
+	service.repo = &mockETFHoldingsRepository{}
+	if _, err := service.ImportETFHoldings(ctx, "IE00B4L5Y983", "spdr", strings.NewReader(appleHoldingsFile)); !errors.Is(err, domain.ErrInvalidHoldings) {
+		t.Errorf("expected ErrInvalidHoldings for an unknown format, got %v", err)
+	}
+	if _, err := service.ImportETFHoldings(ctx, "IE00B4L5Y984", "ishares", strings.NewReader(appleHoldingsFile)); !errors.Is(err, domain.ErrInvalidHoldings) {
+		t.Errorf("expected ErrInvalidHoldings for an invalid ISIN, got %v", err)
+	}
+}
+
+func TestGetExposure_LookThrough(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&MockFXRates{})
+	service.repo = &mockETFHoldingsRepository{}
+	ctx := context.Background()
+
+	// The held instrument is split across the constituents of its file
+	if _, err := service.ImportETFHoldings(ctx, "US0378331005", "ishares", strings.NewReader(strings.Replace(appleHoldingsFile, "US0378331005", "US5949181045", 1))); err != nil {
+		t.Fatalf("ImportETFHoldings failed: %v", err)
+	}
+
+	exposure, err := service.GetExposure(ctx, true)
+	if err != nil {
+		t.Fatalf("GetExposure failed: %v", err)
+	}
+	if len(exposure.Currencies) != 2 || exposure.Currencies[0].Key != "USD" || !exposure.Currencies[0].Weight.Equal(domain.NewDecimalFromInt(75)) {
+		t.Errorf("expected 75%% in USD, got %+v", exposure.Currencies)
+	}
+
+	exposure, err = service.GetExposure(ctx, false)
+	if err != nil {
+		t.Fatalf("GetExposure failed: %v", err)
+	}
+	if len(exposure.Currencies) != 1 || exposure.Currencies[0].Key != "USD" {
+		t.Errorf("expected all of it in USD without look-through, got %+v", exposure.Currencies)
+	}
+}
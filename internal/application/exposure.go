@@ -0,0 +1,40 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// GetExposure breaks the portfolio value down by sector, industry,
+// country, currency and asset type, in the base currency. With lookThrough,
+// positions in ETFs with imported holdings are split across their
+// constituents.
+func (s *PortfolioService) GetExposure(ctx context.Context, lookThrough bool) (*domain.Exposure, error) {
+	rates, err := s.exchangeRates(ctx, s.defaultPortfolio.Currency(), s.defaultPortfolio.Currencies())
+	if err != nil {
+		return nil, err
+	}
+
+	var compositions domain.ETFCompositions
+	if lookThrough {
+		isins := make([]string, 0, len(s.defaultPortfolio.Positions))
+		for i := range s.defaultPortfolio.Positions {
+			if pos := &s.defaultPortfolio.Positions[i]; !pos.IsClosed() {
+				isins = append(isins, pos.Instrument.ISIN)
+			}
+		}
+		if compositions, err = s.etfCompositions(ctx, isins); err != nil {
+			return nil, err
+		}
+	}
+
+	exposure, err := s.defaultPortfolio.Exposure(rates, compositions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate exposure: %w", err)
+	}
+	slog.DebugContext(ctx, "exposure calculated", "total_value", exposure.TotalValue, "sectors", len(exposure.Sectors), "look_through", len(compositions))
+	return exposure, nil
+}
@@ -0,0 +1,37 @@
+package application
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+func TestGetExposure(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&MockFXRates{})
+	ctx := context.Background()
+
+	sector, country := "Technology", "US"
+	if _, err := service.UpdateInstrument(ctx, "US0378331005", domain.InstrumentDetails{Sector: &sector, Country: &country}); err != nil {
+		t.Fatalf("UpdateInstrument failed: %v", err)
+	}
+
+	exposure, err := service.GetExposure(ctx, true)
+	if err != nil {
+		t.Fatalf("GetExposure failed: %v", err)
+	}
+	// 10 units at 150 USD are worth 750 EUR
+	if exposure.Currency != "EUR" || !exposure.TotalValue.Equal(domain.NewDecimalFromInt(750)) {
+		t.Fatalf("expected 750 EUR, got %s %s", exposure.TotalValue, exposure.Currency)
+	}
+	if len(exposure.Sectors) != 1 || exposure.Sectors[0].Key != "Technology" || !exposure.Sectors[0].Weight.Equal(domain.NewDecimalFromInt(100)) {
+		t.Errorf("expected all of it in Technology, got %+v", exposure.Sectors)
+	}
+	if len(exposure.Industries) != 1 || exposure.Industries[0].Key != domain.ExposureUnclassified {
+		t.Errorf("expected the industry to be unclassified, got %+v", exposure.Industries)
+	}
+	if len(exposure.Currencies) != 1 || exposure.Currencies[0].Key != "USD" {
+		t.Errorf("expected all of it in USD, got %+v", exposure.Currencies)
+	}
+}
@@ -0,0 +1,177 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata"
+)
+
+// StoredFXRates wraps an exchange rate source and keeps every rate it
+// serves in the repository, so historical valuations can be reproduced
+// and the last known rates remain available when the source is down.
+type StoredFXRates struct {
+	source marketdata.FXRateProvider
+	repo   domain.FXRateRepository
+}
+
+var _ marketdata.HistoricalFXRateBatchProvider = (*StoredFXRates)(nil)
+
+// fxPublicationGapDays is the longest stretch between two published rates
+// that is taken as weekends and holidays rather than missing history.
+const fxPublicationGapDays = 5
+
+func NewStoredFXRates(source marketdata.FXRateProvider, repo domain.FXRateRepository) *StoredFXRates {
+	return &StoredFXRates{
+		source: source,
+		repo:   repo,
+	}
+}
+
+// GetRate returns the latest rate from the source and stores it.
+// When the source fails, the most recent stored rate is used instead.
+func (s *StoredFXRates) GetRate(ctx context.Context, from, to string) (*domain.FXRate, error) {
+	rate, err := s.source.GetRate(ctx, from, to)
+	if err != nil {
+		stored, findErr := s.repo.FindFXRate(ctx, from, to, time.Now())
+		if findErr != nil {
+			return nil, fmt.Errorf("failed to get fx rate %s/%s: %w", from, to, err)
+		}
+		slog.WarnContext(ctx, "fx rate source unavailable, using stored rate", "from", from, "to", to, "as_of", stored.AsOf, "error", err)
+		return stored, nil
+	}
+
+	s.store(ctx, *rate)
+	return rate, nil
+}
+
+// GetHistoricalRate returns the rate in effect on date, preferring the
+// stored history and asking the source only for days not stored yet.
+func (s *StoredFXRates) GetHistoricalRate(ctx context.Context, from, to string, date time.Time) (*domain.FXRate, error) {
+	stored, err := s.repo.FindFXRate(ctx, from, to, date)
+	if err != nil && !errors.Is(err, domain.ErrFXRateNotFound) {
+		return nil, fmt.Errorf("failed to find stored fx rate: %w", err)
+	}
+	if stored != nil && sameDay(stored.AsOf, date) {
+		return stored, nil
+	}
+
+	historical, ok := s.source.(marketdata.HistoricalFXRateProvider)
+	if !ok {
+		if stored != nil {
+			return stored, nil
+		}
+		return nil, fmt.Errorf("%w: %s/%s on %s (source has no history)", domain.ErrFXRateNotFound, from, to, date.Format(time.DateOnly))
+	}
+
+	rate, err := historical.GetHistoricalRate(ctx, from, to, date)
+	if err != nil {
+		if stored != nil {
+			return stored, nil
+		}
+		return nil, fmt.Errorf("failed to get historical fx rate %s/%s: %w", from, to, err)
+	}
+
+	s.store(ctx, *rate)
+	return rate, nil
+}
+
+// GetHistoricalRates returns the rates in effect on dates, oldest first. The
+// stored history of the whole range is loaded at once and the source is
+// only asked about the days it does not cover; days the source has no rate
+// for are skipped.
+func (s *StoredFXRates) GetHistoricalRates(ctx context.Context, from, to string, dates []time.Time) ([]domain.FXRate, error) {
+	if len(dates) == 0 {
+		return nil, nil
+	}
+	days := make([]time.Time, 0, len(dates))
+	for _, date := range dates {
+		days = append(days, dateOf(date))
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Before(days[j])
+	})
+
+	stored, err := s.repo.FindFXRates(ctx, from, to, days[0].AddDate(0, 0, -fxPublicationGapDays), days[len(days)-1].AddDate(0, 0, 1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stored fx rates: %w", err)
+	}
+
+	historical, ok := s.source.(marketdata.HistoricalFXRateProvider)
+	if !ok {
+		return stored, nil
+	}
+	var fetched []domain.FXRate
+	for i, day := range days {
+		if (i > 0 && day.Equal(days[i-1])) || coversDay(stored, day) {
+			continue
+		}
+		rate, err := historical.GetHistoricalRate(ctx, from, to, day)
+		if err != nil {
+			slog.DebugContext(ctx, "no historical fx rate", "from", from, "to", to, "date", day, "error", err)
+			continue
+		}
+		if rate.AsOf.IsZero() {
+			rate.AsOf = day
+		}
+		if !coversDay(stored, rate.AsOf) {
+			fetched = append(fetched, *rate)
+		}
+		stored = insertRate(stored, *rate)
+	}
+
+	if len(fetched) > 0 {
+		if err := s.repo.SaveFXRates(ctx, fetched); err != nil {
+			slog.WarnContext(ctx, "failed to store fx rates", "from", from, "to", to, "rates", len(fetched), "error", err)
+		}
+	}
+	return stored, nil
+}
+
+// coversDay reports whether rates, sorted by date, hold the rate of day:
+// one published on it, or one before and one after it within the gap of
+// a weekend or holiday.
+func coversDay(rates []domain.FXRate, day time.Time) bool {
+	i := sort.Search(len(rates), func(i int) bool {
+		return !dateOf(rates[i].AsOf).Before(day)
+	})
+	if i < len(rates) && sameDay(rates[i].AsOf, day) {
+		return true
+	}
+	return i > 0 && i < len(rates) && !dateOf(rates[i].AsOf).After(dateOf(rates[i-1].AsOf).AddDate(0, 0, fxPublicationGapDays))
+}
+
+// insertRate adds rate to rates in date order, replacing the one of the
+// same day.
+func insertRate(rates []domain.FXRate, rate domain.FXRate) []domain.FXRate {
+	i := sort.Search(len(rates), func(i int) bool {
+		return !dateOf(rates[i].AsOf).Before(dateOf(rate.AsOf))
+	})
+	if i < len(rates) && sameDay(rates[i].AsOf, rate.AsOf) {
+		rates[i] = rate
+		return rates
+	}
+	rates = append(rates, domain.FXRate{})
+	copy(rates[i+1:], rates[i:])
+	rates[i] = rate
+	return rates
+}
+
+// store saves a served rate; failures only cost reproducibility, so they
+// are logged rather than returned.
+func (s *StoredFXRates) store(ctx context.Context, rate domain.FXRate) {
+	if err := s.repo.SaveFXRates(ctx, []domain.FXRate{rate}); err != nil {
+		slog.WarnContext(ctx, "failed to store fx rate", "from", rate.From, "to", rate.To, "error", err)
+	}
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,165 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// MockFXRateRepository keeps rates in memory, keyed by pair.
+type MockFXRateRepository struct {
+	rates []domain.FXRate
+	finds int
+}
+
+func (m *MockFXRateRepository) SaveFXRates(_ context.Context, rates []domain.FXRate) error {
+	m.rates = append(m.rates, rates...)
+	return nil
+}
+
+func (m *MockFXRateRepository) FindFXRate(_ context.Context, from, to string, date time.Time) (*domain.FXRate, error) {
+	var found *domain.FXRate
+	for i := range m.rates {
+		r := &m.rates[i]
+		if r.From == from && r.To == to && !r.AsOf.After(date) && (found == nil || r.AsOf.After(found.AsOf)) {
+			found = r
+		}
+	}
+	if found == nil {
+		return nil, domain.ErrFXRateNotFound
+	}
+	return found, nil
+}
+
+func (m *MockFXRateRepository) FindFXRates(_ context.Context, from, to string, start, end time.Time) ([]domain.FXRate, error) {
+	m.finds++
+	var found []domain.FXRate
+	for _, r := range m.rates {
+		if r.From == from && r.To == to && !r.AsOf.Before(start) && !r.AsOf.After(end) {
+			found = append(found, r)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool {
+		return found[i].AsOf.Before(found[j].AsOf)
+	})
+	return found, nil
+}
+
+// MockHistoricalFXRates quotes a fixed rate dated on the requested day.
+type MockHistoricalFXRates struct {
+	err   error
+	calls int
+}
+
+func (m *MockHistoricalFXRates) GetRate(ctx context.Context, from, to string) (*domain.FXRate, error) {
+	return m.GetHistoricalRate(ctx, from, to, time.Now())
+}
+
+func (m *MockHistoricalFXRates) GetHistoricalRate(_ context.Context, from, to string, date time.Time) (*domain.FXRate, error) {
+	m.calls++
+	if m.err != nil {
+		return nil, m.err
+	}
+	return &domain.FXRate{From: from, To: to, Rate: domain.NewDecimalFromInt(2), AsOf: date, Source: "mock"}, nil
+}
+
+func TestStoredFXRates_GetRateStoresRate(t *testing.T) {
+	repo := &MockFXRateRepository{}
+	rates := NewStoredFXRates(&MockHistoricalFXRates{}, repo)
+
+	rate, err := rates.GetRate(context.Background(), "EUR", "USD")
+	if err != nil {
+		t.Fatalf("GetRate failed: %v", err)
+	}
+	if !rate.Rate.Equal(domain.NewDecimalFromInt(2)) {
+		t.Errorf("expected rate 2, got %s", rate.Rate)
+	}
+	if len(repo.rates) != 1 {
+		t.Errorf("expected rate to be stored, got %d stored", len(repo.rates))
+	}
+}
+
+func TestStoredFXRates_GetRateFallsBackToStored(t *testing.T) {
+	yesterday := time.Now().AddDate(0, 0, -1)
+	repo := &MockFXRateRepository{rates: []domain.FXRate{
+		{From: "EUR", To: "USD", Rate: domain.NewDecimalFromInt(3), AsOf: yesterday},
+	}}
+	rates := NewStoredFXRates(&MockHistoricalFXRates{err: fmt.Errorf("feed down")}, repo)
+
+	rate, err := rates.GetRate(context.Background(), "EUR", "USD")
+	if err != nil {
+		t.Fatalf("GetRate failed: %v", err)
+	}
+	if !rate.Rate.Equal(domain.NewDecimalFromInt(3)) {
+		t.Errorf("expected stored rate 3, got %s", rate.Rate)
+	}
+
+	if _, err := rates.GetRate(context.Background(), "EUR", "GBP"); err == nil {
+		t.Error("expected error when neither source nor store has the rate")
+	}
+}
+
+func TestStoredFXRates_GetHistoricalRatePrefersStore(t *testing.T) {
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	repo := &MockFXRateRepository{rates: []domain.FXRate{
+		{From: "EUR", To: "USD", Rate: domain.NewDecimalFromInt(3), AsOf: day},
+	}}
+	source := &MockHistoricalFXRates{}
+	rates := NewStoredFXRates(source, repo)
+	ctx := context.Background()
+
+	rate, err := rates.GetHistoricalRate(ctx, "EUR", "USD", day.Add(15*time.Hour))
+	if err != nil {
+		t.Fatalf("GetHistoricalRate failed: %v", err)
+	}
+	if !rate.Rate.Equal(domain.NewDecimalFromInt(3)) || source.calls != 0 {
+		t.Errorf("expected stored rate without calling the source, got %s after %d calls", rate.Rate, source.calls)
+	}
+
+	// A later day is not stored yet and comes from the source
+	rate, err = rates.GetHistoricalRate(ctx, "EUR", "USD", day.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatalf("GetHistoricalRate failed: %v", err)
+	}
+	if !rate.Rate.Equal(domain.NewDecimalFromInt(2)) || len(repo.rates) != 2 {
+		t.Errorf("expected source rate to be stored, got %s with %d stored", rate.Rate, len(repo.rates))
+	}
+}
+
+func TestStoredFXRates_GetHistoricalRateWithoutHistory(t *testing.T) {
+	rates := NewStoredFXRates(&MockFXRates{}, &MockFXRateRepository{})
+
+	_, err := rates.GetHistoricalRate(context.Background(), "EUR", "USD", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	if !errors.Is(err, domain.ErrFXRateNotFound) {
+		t.Errorf("expected ErrFXRateNotFound, got %v", err)
+	}
+}
+
+func TestStoredFXRates_GetHistoricalRates(t *testing.T) {
+	friday := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	monday := friday.AddDate(0, 0, 3)
+	repo := &MockFXRateRepository{rates: []domain.FXRate{
+		{From: "EUR", To: "USD", Rate: domain.NewDecimalFromInt(3), AsOf: friday},
+		{From: "EUR", To: "USD", Rate: domain.NewDecimalFromInt(3), AsOf: monday},
+	}}
+	source := &MockHistoricalFXRates{}
+	rates := NewStoredFXRates(source, repo)
+
+	// The weekend lies between two stored days; only Tuesday is missing
+	dates := []time.Time{monday.AddDate(0, 0, 1), friday.AddDate(0, 0, 1), monday, friday.AddDate(0, 0, 2), monday}
+	past, err := rates.GetHistoricalRates(context.Background(), "EUR", "USD", dates)
+	if err != nil {
+		t.Fatalf("GetHistoricalRates failed: %v", err)
+	}
+	if repo.finds != 1 || source.calls != 1 || len(repo.rates) != 3 {
+		t.Errorf("expected one lookup and one source call for Tuesday, got %d lookups, %d calls and %d stored", repo.finds, source.calls, len(repo.rates))
+	}
+	if len(past) != 3 || !past[0].AsOf.Equal(friday) || !past[2].AsOf.Equal(monday.AddDate(0, 0, 1)) {
+		t.Errorf("expected Friday to Tuesday in order, got %+v", past)
+	}
+}
@@ -0,0 +1,79 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata"
+)
+
+// ErrUnresolvedIdentifier is returned when an identifier is valid but no
+// ISIN can be found for it.
+var ErrUnresolvedIdentifier = errors.New("identifier cannot be resolved to an ISIN")
+
+// ResolveInstrument validates an ISIN, CUSIP, SEDOL, FIGI or
+// exchange:ticker and returns the instrument it identifies, keyed by ISIN.
+// Instruments already held are returned without a provider call.
+func (s *PortfolioService) ResolveInstrument(ctx context.Context, identifier string) (*domain.Instrument, error) {
+	id, err := domain.ParseIdentifier(identifier)
+	if err != nil {
+		return nil, err
+	}
+	if instrument, ok := s.heldInstrument(id); ok {
+		return instrument, nil
+	}
+	return s.lookupInstrument(ctx, id)
+}
+
+// heldInstrument finds the instrument of a position matching id. Tickers
+// match the symbol and, when given, the exchange.
+func (s *PortfolioService) heldInstrument(id domain.Identifier) (*domain.Instrument, bool) {
+	isin, hasISIN := id.ISIN()
+	for i := range s.defaultPortfolio.Positions {
+		instrument := s.defaultPortfolio.Positions[i].Instrument
+		switch {
+		case hasISIN && instrument.ISIN == isin:
+		case id.Type == domain.IdentifierTicker && strings.EqualFold(instrument.Symbol, id.Value) &&
+			strings.EqualFold(instrument.Exchange, id.Exchange):
+		default:
+			continue
+		}
+		return &instrument, true
+	}
+	return nil, false
+}
+
+// lookupInstrument asks the market data provider for the instrument of id.
+// CUSIPs and SEDOLs are converted to ISINs first; FIGIs and tickers need a
+// provider that can search by them.
+func (s *PortfolioService) lookupInstrument(ctx context.Context, id domain.Identifier) (*domain.Instrument, error) {
+	if isin, ok := id.ISIN(); ok {
+		instrument, err := s.marketData.SearchByISIN(ctx, isin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find instrument: %w", err)
+		}
+		return instrument, nil
+	}
+
+	if instrument, ok := s.heldInstrument(id); ok {
+		return instrument, nil
+	}
+	provider, ok := s.marketData.(marketdata.IdentifierProvider)
+	if !ok {
+		return nil, fmt.Errorf("%w: the market data provider cannot search by %s", ErrUnresolvedIdentifier, id.Type)
+	}
+	instrument, err := provider.SearchByIdentifier(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find instrument: %w", err)
+	}
+	if err := domain.ValidateISIN(instrument.ISIN); err != nil {
+		return nil, fmt.Errorf("%w: %s resolved to %q: %v", ErrUnresolvedIdentifier, id, instrument.ISIN, err)
+	}
+
+	slog.DebugContext(ctx, "identifier resolved", "identifier", id.String(), "type", id.Type, "isin", instrument.ISIN)
+	return instrument, nil
+}
@@ -0,0 +1,75 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// MockIdentifierMarketData resolves tickers to a fixed ISIN.
+type MockIdentifierMarketData struct {
+	MockMarketData
+}
+
+func (m *MockIdentifierMarketData) SearchByIdentifier(ctx context.Context, id domain.Identifier) (*domain.Instrument, error) {
+	if id.Type != domain.IdentifierTicker {
+		return nil, errors.New("unsupported")
+	}
+	return m.SearchByISIN(ctx, "US5949181045")
+}
+
+func TestResolveInstrument(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	ctx := context.Background()
+
+	// CUSIPs and SEDOLs are converted to ISINs offline
+	instrument, err := service.ResolveInstrument(ctx, "B0YBKJ7")
+	if err != nil {
+		t.Fatalf("ResolveInstrument failed: %v", err)
+	}
+	if instrument.ISIN != "GB00B0YBKJ77" {
+		t.Errorf("expected GB00B0YBKJ77, got %s", instrument.ISIN)
+	}
+
+	// Held instruments resolve by ticker without a provider search
+	instrument, err = service.ResolveInstrument(ctx, "nasdaq:testsym")
+	if err != nil {
+		t.Fatalf("ResolveInstrument failed: %v", err)
+	}
+	if instrument.ISIN != "US0378331005" {
+		t.Errorf("expected the held US0378331005, got %s", instrument.ISIN)
+	}
+
+	if _, err := service.ResolveInstrument(ctx, "XETRA:SAP"); !errors.Is(err, ErrUnresolvedIdentifier) {
+		t.Errorf("expected ErrUnresolvedIdentifier without provider support, got %v", err)
+	}
+	if _, err := service.ResolveInstrument(ctx, "US0378331006"); !errors.Is(err, domain.ErrInvalidIdentifier) {
+		t.Errorf("expected ErrInvalidIdentifier, got %v", err)
+	}
+}
+
+func TestAddPosition_ByTicker(t *testing.T) {
+	service := newDividendService(t, &MockIdentifierMarketData{})
+
+	pos, err := service.AddPosition(context.Background(), "XNAS:MSFT", domain.NewDecimalFromInt(300), "USD", domain.TradeCharges{}, TradeDetails{})
+	if err != nil {
+		t.Fatalf("AddPosition failed: %v", err)
+	}
+	if pos.Instrument.ISIN != "US5949181045" {
+		t.Errorf("expected the position to be keyed by ISIN, got %s", pos.Instrument.ISIN)
+	}
+}
+
+func TestAddPositionsBatch_InvalidIdentifiers(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+
+	result := service.AddPositionsBatch(context.Background(), []AddPositionBatchRequest{
+		{ISIN: "US0378331006", InvestedAmount: domain.NewDecimalFromInt(100), Currency: "USD"},
+		{ISIN: "BBG000B9XRY4", InvestedAmount: domain.NewDecimalFromInt(100), Currency: "USD"},
+	})
+	if len(result.Successful) != 0 || len(result.Failed) != 2 {
+		t.Errorf("expected both entries to fail validation, got %+v", result)
+	}
+}
@@ -0,0 +1,68 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// UpdateInstrument corrects the type or the type-specific attributes of a
+// held instrument, such as the coupon and maturity of a bond or the unit
+// precision of a crypto asset, and overrides its sector, industry and
+// country.
+func (s *PortfolioService) UpdateInstrument(ctx context.Context, isin string, details domain.InstrumentDetails) (*domain.Instrument, error) {
+	instrument, err := s.defaultPortfolio.UpdateInstrument(isin, details)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update instrument: %w", err)
+	}
+
+	if store, ok := s.repo.(domain.InstrumentRepository); ok {
+		if err := store.UpdateInstrument(ctx, instrument); err != nil {
+			return nil, fmt.Errorf("failed to save instrument: %w", err)
+		}
+	}
+	if err := s.repo.Save(ctx, s.defaultPortfolio); err != nil {
+		return nil, fmt.Errorf("failed to save portfolio: %w", err)
+	}
+
+	slog.InfoContext(ctx, "instrument updated", "isin", isin, "type", instrument.Type)
+	return instrument, nil
+}
+
+// GetInstrumentPrices returns the stored daily bars of an instrument
+// between from and to, inclusive, in date order. A zero from starts at the
+// first stored bar and a zero to ends today. When source is set, only bars
+// from that source are returned.
+func (s *PortfolioService) GetInstrumentPrices(ctx context.Context, isin string, from, to time.Time, source string) ([]domain.PricePoint, error) {
+	isin = strings.ToUpper(strings.TrimSpace(isin))
+	if err := domain.ValidateISIN(isin); err != nil {
+		return nil, err
+	}
+	repo, ok := s.repo.(domain.PriceHistoryRepository)
+	if !ok {
+		return nil, ErrPriceHistoryUnsupported
+	}
+
+	if to.IsZero() {
+		to = time.Now()
+	}
+	prices, err := repo.FindPrices(ctx, isin, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load prices of %s: %w", isin, err)
+	}
+	if source == "" {
+		return prices, nil
+	}
+
+	filtered := make([]domain.PricePoint, 0, len(prices))
+	for _, price := range prices {
+		if price.Source == source {
+			filtered = append(filtered, price)
+		}
+	}
+	return filtered, nil
+}
@@ -0,0 +1,106 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata"
+)
+
+func TestUpdateInstrument(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	bond := domain.InstrumentTypeBond
+	coupon, _ := domain.NewDecimalFromString("2.5")
+	maturity := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	instrument, err := service.UpdateInstrument(context.Background(), "US0378331005", domain.InstrumentDetails{
+		Type:         &bond,
+		CouponRate:   &coupon,
+		MaturityDate: &maturity,
+	})
+	if err != nil {
+		t.Fatalf("UpdateInstrument failed: %v", err)
+	}
+	if instrument.Type != domain.InstrumentTypeBond || !instrument.CouponRate.Equal(coupon) {
+		t.Errorf("expected a 2.5%% bond, got %+v", instrument)
+	}
+
+	// The bond has matured, so refreshing prices no longer quotes it
+	service.marketData = &MockMarketData{quoteError: errors.New("not quoted")}
+	if err := service.RefreshPrices(context.Background()); err != nil {
+		t.Errorf("expected the matured bond to be skipped, got %v", err)
+	}
+}
+
+func TestUpdateInstrument_Invalid(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	precision := int32(40)
+
+	_, err := service.UpdateInstrument(context.Background(), "US0378331005", domain.InstrumentDetails{UnitPrecision: &precision})
+	if !errors.Is(err, domain.ErrInvalidInstrument) {
+		t.Errorf("expected ErrInvalidInstrument, got %v", err)
+	}
+	if _, err := service.UpdateInstrument(context.Background(), "IE00B4L5Y983", domain.InstrumentDetails{}); !errors.Is(err, domain.ErrPositionNotFound) {
+		t.Errorf("expected ErrPositionNotFound, got %v", err)
+	}
+}
+
+// barMarketData quotes the day's open, high, low and volume with the price
+type barMarketData struct {
+	MockMarketData
+}
+
+func (m *barMarketData) GetQuote(ctx context.Context, symbol string) (*marketdata.QuoteResult, error) {
+	quote, err := m.MockMarketData.GetQuote(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	open, high, low, volume := domain.NewDecimalFromInt(148), domain.NewDecimalFromInt(152), domain.NewDecimalFromInt(147), domain.NewDecimalFromInt(1000000)
+	quote.Open, quote.High, quote.Low, quote.Volume = &open, &high, &low, &volume
+	return quote, nil
+}
+
+func TestGetInstrumentPrices(t *testing.T) {
+	service := newDividendService(t, &barMarketData{})
+	ctx := context.Background()
+
+	if _, err := service.GetInstrumentPrices(ctx, "US0378331005", time.Time{}, time.Time{}, ""); !errors.Is(err, ErrPriceHistoryUnsupported) {
+		t.Errorf("expected ErrPriceHistoryUnsupported, got %v", err)
+	}
+
+	repo := &mockPriceHistoryRepository{}
+	service.repo = repo
+	if err := service.RefreshPrices(ctx); err != nil {
+		t.Fatalf("RefreshPrices failed: %v", err)
+	}
+	repo.prices = append(repo.prices, domain.NewPricePoint("US0378331005", time.Now().AddDate(0, 0, -1), domain.NewDecimalFromInt(149), "USD", "backfill"))
+
+	// The refresh stores the quoted bar
+	prices, err := service.GetInstrumentPrices(ctx, " us0378331005 ", time.Time{}, time.Time{}, priceSourceQuote)
+	if err != nil {
+		t.Fatalf("GetInstrumentPrices failed: %v", err)
+	}
+	if len(prices) != 1 || !prices[0].Close.Equal(domain.NewDecimalFromInt(150)) {
+		t.Fatalf("expected today's quote, got %+v", prices)
+	}
+	bar := prices[0]
+	if bar.Open == nil || !bar.Open.Equal(domain.NewDecimalFromInt(148)) || bar.High == nil || !bar.High.Equal(domain.NewDecimalFromInt(152)) ||
+		bar.Low == nil || !bar.Low.Equal(domain.NewDecimalFromInt(147)) || bar.Volume == nil || !bar.Volume.Equal(domain.NewDecimalFromInt(1000000)) {
+		t.Errorf("expected the quoted bar, got %+v", bar)
+	}
+
+	prices, err = service.GetInstrumentPrices(ctx, "US0378331005", time.Time{}, time.Time{}, "")
+	if err != nil {
+		t.Fatalf("GetInstrumentPrices failed: %v", err)
+	}
+	if len(prices) != 2 {
+		t.Errorf("expected the bars of every source, got %+v", prices)
+	}
+
+	if _, err := service.GetInstrumentPrices(ctx, "US0378331006", time.Time{}, time.Time{}, ""); !errors.Is(err, domain.ErrInvalidIdentifier) {
+		t.Errorf("expected ErrInvalidIdentifier, got %v", err)
+	}
+}
@@ -0,0 +1,66 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// GetPerformance returns the time-weighted return of the portfolio and its
+// positions over the named period (1M, 3M, YTD, 1Y or ALL), ending now and
+// expressed in the base currency at the rates of each day. Holdings are
+// valued at their stored closes, like the benchmark comparison and the risk
+// report; without a price history they are marked at their last trade.
+func (s *PortfolioService) GetPerformance(ctx context.Context, periodName string) (*domain.PerformanceReport, error) {
+	period, err := domain.ParsePerformancePeriod(periodName)
+	if err != nil {
+		return nil, err
+	}
+
+	isins := make([]string, 0, len(s.defaultPortfolio.Positions))
+	for i := range s.defaultPortfolio.Positions {
+		isins = append(isins, s.defaultPortfolio.Positions[i].Instrument.ISIN)
+	}
+	now := time.Now()
+	history, err := s.priceHistory(ctx, isins, time.Time{}, now)
+	if err != nil && !errors.Is(err, ErrPriceHistoryUnsupported) {
+		return nil, err
+	}
+
+	rates, err := s.historicalExchangeRates(ctx, history.TradingDates(isins...))
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := s.portfolioSnapshots(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.defaultPortfolio.Performance(period, now, rates, history, snapshots)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure performance: %w", err)
+	}
+	slog.DebugContext(ctx, "performance measured", "period", period, "from", report.From, "twr", report.TimeWeightedReturn)
+	return report, nil
+}
+
+// GetMoneyWeightedReturn returns the XIRR of the portfolio and its
+// positions since inception, valuing the holdings now in the base currency.
+func (s *PortfolioService) GetMoneyWeightedReturn(ctx context.Context) (*domain.MoneyWeightedReport, error) {
+	rates, err := s.historicalExchangeRates(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.defaultPortfolio.MoneyWeightedReturn(time.Now(), rates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate money-weighted return: %w", err)
+	}
+	slog.DebugContext(ctx, "money-weighted return calculated", "xirr", report.XIRR, "cash_flows", len(report.CashFlows))
+	return report, nil
+}
@@ -0,0 +1,195 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+func TestGetPerformance(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&MockFXRates{})
+	if err := service.defaultPortfolio.UpdatePositionPrice(service.defaultPortfolio.Positions[0].ID, domain.NewDecimalFromInt(165)); err != nil {
+		t.Fatalf("UpdatePositionPrice failed: %v", err)
+	}
+
+	report, err := service.GetPerformance(context.Background(), "all")
+	if err != nil {
+		t.Fatalf("GetPerformance failed: %v", err)
+	}
+	if report.Period != domain.PeriodSinceInception || report.Currency != "EUR" {
+		t.Errorf("expected since inception in EUR, got %s in %s", report.Period, report.Currency)
+	}
+	if !report.TimeWeightedReturn.Equal(domain.NewDecimalFromInt(10)) {
+		t.Errorf("expected 10%%, got %s", report.TimeWeightedReturn)
+	}
+	if len(report.Positions) != 1 {
+		t.Errorf("expected 1 position, got %d", len(report.Positions))
+	}
+}
+
+func TestGetPerformance_Snapshots(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&MockFXRates{})
+	if err := service.defaultPortfolio.UpdatePositionPrice(service.defaultPortfolio.Positions[0].ID, domain.NewDecimalFromInt(165)); err != nil {
+		t.Fatalf("UpdatePositionPrice failed: %v", err)
+	}
+	// The snapshot of the buy date marks the 750 EUR bought at 600 EUR
+	service.repo = &mockSnapshotRepository{snapshots: []domain.PortfolioSnapshot{{
+		PortfolioID: service.defaultPortfolio.ID,
+		Date:        time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		Currency:    "EUR",
+		TotalValue:  domain.NewDecimalFromInt(600),
+		NetFlow:     domain.NewDecimalFromInt(750),
+	}}}
+
+	report, err := service.GetPerformance(context.Background(), "all")
+	if err != nil {
+		t.Fatalf("GetPerformance failed: %v", err)
+	}
+	// 600 -> 825 EUR rather than 750 -> 825 EUR from the ledger
+	expected, _ := domain.NewDecimalFromString("37.5")
+	if !report.TimeWeightedReturn.Equal(expected) {
+		t.Errorf("expected %s%%, got %s", expected, report.TimeWeightedReturn)
+	}
+}
+
+// datedFXRates quotes 1 USD = 0.5 EUR until the end of 2024 and 0.4 EUR
+// after it, and the inverse rates from EUR to USD.
+type datedFXRates struct{}
+
+func (m *datedFXRates) GetRate(ctx context.Context, from, to string) (*domain.FXRate, error) {
+	return m.GetHistoricalRate(ctx, from, to, time.Now())
+}
+
+func (m *datedFXRates) GetHistoricalRate(_ context.Context, from, to string, date time.Time) (*domain.FXRate, error) {
+	var rate domain.Decimal
+	switch {
+	case from == "USD" && to == "EUR" && date.Year() <= 2024:
+		rate, _ = domain.NewDecimalFromString("0.5")
+	case from == "USD" && to == "EUR":
+		rate, _ = domain.NewDecimalFromString("0.4")
+	case from == "EUR" && to == "USD" && date.Year() <= 2024:
+		rate = domain.NewDecimalFromInt(2)
+	case from == "EUR" && to == "USD":
+		rate, _ = domain.NewDecimalFromString("2.5")
+	default:
+		return nil, fmt.Errorf("%w: %s/%s", domain.ErrFXRateNotFound, from, to)
+	}
+	return &domain.FXRate{From: from, To: to, Rate: rate, AsOf: date}, nil
+}
+
+// recentFXRates is datedFXRates without rates before 2024.
+type recentFXRates struct {
+	datedFXRates
+}
+
+func (m *recentFXRates) GetHistoricalRate(ctx context.Context, from, to string, date time.Time) (*domain.FXRate, error) {
+	if date.Year() < 2024 {
+		return nil, fmt.Errorf("%w: %s/%s before 2024", domain.ErrFXRateNotFound, from, to)
+	}
+	return m.datedFXRates.GetHistoricalRate(ctx, from, to, date)
+}
+
+func TestHistoricalExchangeRates_SkipsDaysWithoutRate(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&recentFXRates{})
+	buyDate := time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	// The day without a rate neither stops nor changes the others
+	for range 10 {
+		rates, err := service.historicalExchangeRates(context.Background(), []time.Time{time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)})
+		if err != nil {
+			t.Fatalf("historicalExchangeRates failed: %v", err)
+		}
+		converted, err := rates.On(buyDate).Convert(domain.NewDecimalFromInt(100), "USD")
+		if err != nil {
+			t.Fatalf("Convert failed: %v", err)
+		}
+		if !converted.Equal(domain.NewDecimalFromInt(50)) {
+			t.Fatalf("expected 100 USD to be 50 EUR on the buy date, got %s", converted)
+		}
+	}
+}
+
+func TestGetPerformance_HistoricalRates(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&datedFXRates{})
+	if err := service.defaultPortfolio.UpdatePositionPrice(service.defaultPortfolio.Positions[0].ID, domain.NewDecimalFromInt(165)); err != nil {
+		t.Fatalf("UpdatePositionPrice failed: %v", err)
+	}
+
+	report, err := service.GetPerformance(context.Background(), "all")
+	if err != nil {
+		t.Fatalf("GetPerformance failed: %v", err)
+	}
+	// Bought for 1500 USD = 750 EUR, now worth 1650 USD = 660 EUR
+	if !report.TimeWeightedReturn.Equal(domain.NewDecimalFromInt(-12)) {
+		t.Errorf("expected -12%% at the rates of each day, got %s", report.TimeWeightedReturn)
+	}
+}
+
+func TestGetPerformance_StoredCloses(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&MockFXRates{})
+	pos := service.defaultPortfolio.Positions[0]
+	if err := service.defaultPortfolio.UpdatePositionPrice(pos.ID, domain.NewDecimalFromInt(150)); err != nil {
+		t.Fatalf("UpdatePositionPrice failed: %v", err)
+	}
+	repo := &mockPriceHistoryRepository{}
+	service.repo = repo
+
+	// Without closes the month starts at the buy price
+	report, err := service.GetPerformance(context.Background(), "1M")
+	if err != nil {
+		t.Fatalf("GetPerformance failed: %v", err)
+	}
+	if !report.TimeWeightedReturn.IsZero() {
+		t.Errorf("expected 0%% at the last trade price, got %s", report.TimeWeightedReturn)
+	}
+
+	// A close before the month starts it at 200
+	before := time.Now().AddDate(0, -1, -10)
+	repo.prices = []domain.PricePoint{domain.NewPricePoint(pos.Instrument.ISIN, before, domain.NewDecimalFromInt(200), "USD", priceSourceQuote)}
+	if report, err = service.GetPerformance(context.Background(), "1M"); err != nil {
+		t.Fatalf("GetPerformance failed: %v", err)
+	}
+	if !report.TimeWeightedReturn.Equal(domain.NewDecimalFromInt(-25)) {
+		t.Errorf("expected -25%% from the stored close, got %s", report.TimeWeightedReturn)
+	}
+	if len(report.Positions) != 1 || !report.Positions[0].TimeWeightedReturn.Equal(domain.NewDecimalFromInt(-25)) {
+		t.Errorf("expected the position valued the same way, got %+v", report.Positions)
+	}
+}
+
+func TestGetPerformance_InvalidPeriod(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+
+	if _, err := service.GetPerformance(context.Background(), "10Y"); !errors.Is(err, domain.ErrInvalidPeriod) {
+		t.Errorf("expected ErrInvalidPeriod, got %v", err)
+	}
+}
+
+func TestGetMoneyWeightedReturn(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&MockFXRates{})
+	if err := service.defaultPortfolio.UpdatePositionPrice(service.defaultPortfolio.Positions[0].ID, domain.NewDecimalFromInt(165)); err != nil {
+		t.Fatalf("UpdatePositionPrice failed: %v", err)
+	}
+
+	report, err := service.GetMoneyWeightedReturn(context.Background())
+	if err != nil {
+		t.Fatalf("GetMoneyWeightedReturn failed: %v", err)
+	}
+	if report.Currency != "EUR" || len(report.CashFlows) != 2 {
+		t.Errorf("expected the buy and the current value in EUR, got %d flows in %s", len(report.CashFlows), report.Currency)
+	}
+	// A 10% gain since January 2024 is less than 10% a year
+	if report.XIRR.Cmp(domain.Zero) <= 0 || report.XIRR.Cmp(domain.NewDecimalFromInt(10)) >= 0 {
+		t.Errorf("expected a yearly rate between 0%% and 10%%, got %s", report.XIRR)
+	}
+}
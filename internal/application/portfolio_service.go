@@ -2,17 +2,32 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sort"
+	"time"
 
 	"github.com/jmanzanog/stock-tracker/internal/domain"
 	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata"
 )
 
+// ErrHistoricalPriceNotFound is returned when neither the stored history
+// nor the provider has a close for a past trade date.
+var ErrHistoricalPriceNotFound = errors.New("no historical close found")
+
+// closeLookbackDays is how far before a date its close is looked for, so
+// that trades entered for weekends and holidays get the last close before.
+const closeLookbackDays = 7
+
 type PortfolioService struct {
 	repo             domain.PortfolioRepository
 	marketData       marketdata.MDataProvider
+	fxRates          marketdata.FXRateProvider
 	defaultPortfolio *domain.Portfolio
+	benchmark        *domain.Instrument
+	riskFreeRate     domain.Decimal
+	notifier         AlertNotifier
 }
 
 func NewPortfolioService(repo domain.PortfolioRepository, marketData marketdata.MDataProvider) (*PortfolioService, error) {
@@ -45,41 +60,174 @@ func NewPortfolioService(repo domain.PortfolioRepository, marketData marketdata.
 		repo:             repo,
 		marketData:       marketData,
 		defaultPortfolio: defaultPortfolio,
+		riskFreeRate:     domain.Zero,
 	}, nil
 }
 
-func (s *PortfolioService) AddPosition(ctx context.Context, isin string, investedAmount domain.Decimal, currency string) (*domain.Position, error) {
-	instrument, err := s.marketData.SearchByISIN(ctx, isin)
+// SetFXRateProvider configures the exchange rate source used to convert
+// amounts between currencies. Without one, only single-currency portfolios
+// can be valued.
+func (s *PortfolioService) SetFXRateProvider(provider marketdata.FXRateProvider) {
+	s.fxRates = provider
+}
+
+// TradeDetails dates a buy in the past. A zero TradeDate means now. Price
+// is optional: without it a backdated buy is priced at the close of its
+// trade date, and a buy made today at the latest quote.
+type TradeDetails struct {
+	TradeDate time.Time
+	Price     domain.Decimal
+}
+
+// AddPosition buys investedAmount worth of the instrument at the latest
+// quote, or on the date and at the price given in trade. The instrument
+// may be given by ISIN, CUSIP, SEDOL, FIGI or exchange:ticker, and is
+// validated before any provider call. Fees and taxes are paid on top of
+// the invested amount.
+func (s *PortfolioService) AddPosition(ctx context.Context, identifier string, investedAmount domain.Decimal, currency string, charges domain.TradeCharges, trade TradeDetails) (*domain.Position, error) {
+	id, err := domain.ParseIdentifier(identifier)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if trade.TradeDate.After(now) {
+		return nil, fmt.Errorf("%w: trade date %s is in the future", domain.ErrInvalidTransaction, trade.TradeDate.Format(time.DateOnly))
+	}
+	if trade.Price.Cmp(domain.Zero) < 0 {
+		return nil, fmt.Errorf("%w: price must not be negative", domain.ErrInvalidTransaction)
+	}
+	backdated := !trade.TradeDate.IsZero() && dateOf(trade.TradeDate).Before(dateOf(now))
+
+	instrument, err := s.lookupInstrument(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	price, quoted := trade.Price, false
+	switch {
+	case !price.IsZero():
+	case backdated:
+		if price, err = s.closeOn(ctx, *instrument, trade.TradeDate); err != nil {
+			return nil, err
+		}
+	default:
+		if price, err = s.latestPrice(ctx, instrument.Symbol); err != nil {
+			return nil, err
+		}
+		quoted = true
+	}
+
+	position, err := s.recordBuy(ctx, *instrument, investedAmount, currency, price, trade.TradeDate, charges)
 	if err != nil {
-		return nil, fmt.Errorf("failed to find instrument: %w", err)
+		return nil, err
+	}
+	if !quoted {
+		// The position is worth today's price, not the one it was bought at
+		if latest, err := s.latestPrice(ctx, instrument.Symbol); err != nil {
+			slog.WarnContext(ctx, "failed to quote position, keeping its buy price", "isin", instrument.ISIN, "error", err)
+		} else if err := s.defaultPortfolio.UpdatePositionPrice(position.ID, latest); err != nil {
+			return nil, err
+		} else {
+			position.CurrentPrice = latest
+		}
+	}
+
+	if err := s.repo.Save(ctx, s.defaultPortfolio); err != nil {
+		return nil, fmt.Errorf("failed to save portfolio: %w", err)
 	}
 
-	position := domain.NewPosition(*instrument, investedAmount, currency)
+	return position, nil
+}
 
-	quote, err := s.marketData.GetQuote(ctx, instrument.Symbol)
+// latestPrice quotes the instrument with the given symbol.
+func (s *PortfolioService) latestPrice(ctx context.Context, symbol string) (domain.Decimal, error) {
+	quote, err := s.marketData.GetQuote(ctx, symbol)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get quote: %w", err)
+		return domain.Zero, fmt.Errorf("failed to get quote: %w", err)
 	}
 
 	// Convert shopspring decimal (from marketdata) to domain decimal
 	price, err := domain.NewDecimalFromString(quote.Price.String())
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse quote price: %w", err)
+		return domain.Zero, fmt.Errorf("failed to parse quote price: %w", err)
+	}
+	return price, nil
+}
+
+// closeOn finds the close of the instrument on date, or the last one
+// before it, in the stored history first and then from the provider.
+func (s *PortfolioService) closeOn(ctx context.Context, instrument domain.Instrument, date time.Time) (domain.Decimal, error) {
+	day := dateOf(date)
+	from := day.AddDate(0, 0, -closeLookbackDays)
+
+	if repo, ok := s.repo.(domain.PriceHistoryRepository); ok {
+		points, err := repo.FindPrices(ctx, instrument.ISIN, from, day)
+		if err != nil {
+			return domain.Zero, fmt.Errorf("failed to load prices of %s: %w", instrument.ISIN, err)
+		}
+		if point, ok := domain.NewPriceHistory(points).CloseOn(instrument.ISIN, day); ok {
+			return point.Close, nil
+		}
+	}
+
+	if provider, ok := s.marketData.(marketdata.HistoryProvider); ok {
+		bars, err := provider.GetHistory(ctx, instrument.Symbol, from, day, marketdata.HistoryDaily)
+		if err != nil {
+			return domain.Zero, fmt.Errorf("failed to get history for %s: %w", instrument.Symbol, err)
+		}
+		for i := len(bars) - 1; i >= 0; i-- {
+			if !bars[i].Date.After(day) {
+				return bars[i].Close, nil
+			}
+		}
+	}
+
+	return domain.Zero, fmt.Errorf("%w for %s on %s, give the price", ErrHistoricalPriceNotFound, instrument.ISIN, day.Format(time.DateOnly))
+}
+
+// recordBuy books a buy of investedAmount at price into the ledger and
+// returns a copy of the resulting position. The invested amount is
+// converted into the quote currency at the rate of the trade date before
+// deriving the quantity. A zero trade date means now; only then is price
+// taken as the current price.
+func (s *PortfolioService) recordBuy(ctx context.Context, instrument domain.Instrument, investedAmount domain.Decimal, currency string, price domain.Decimal, tradeDate time.Time, charges domain.TradeCharges) (*domain.Position, error) {
+	if investedAmount.Cmp(domain.Zero) <= 0 {
+		return nil, fmt.Errorf("failed to add position: %w", domain.ErrInvalidPosition)
 	}
 
-	if err := position.UpdatePrice(price); err != nil {
-		return nil, fmt.Errorf("failed to update position price: %w", err)
+	quoted, err := s.convertOn(ctx, investedAmount, currency, instrument.Currency, tradeDate)
+	if err != nil {
+		return nil, err
+	}
+	quantity, err := quoted.Div(price)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate quantity: %w", err)
+	}
+	if quantity, err = instrument.RoundQuantity(quantity); err != nil {
+		return nil, err
 	}
 
-	if err := s.defaultPortfolio.AddPosition(position); err != nil {
+	current := tradeDate.IsZero()
+	if current {
+		tradeDate = time.Now()
+	}
+	tx := domain.NewTransaction(domain.TransactionTypeBuy, instrument.ISIN, tradeDate, quantity, price, investedAmount, currency)
+	if err := s.setCharges(ctx, &tx, charges); err != nil {
+		return nil, err
+	}
+	position, err := s.defaultPortfolio.RecordTransaction(instrument, tx)
+	if err != nil {
 		return nil, fmt.Errorf("failed to add position: %w", err)
 	}
 
-	if err := s.repo.Save(ctx, s.defaultPortfolio); err != nil {
-		return nil, fmt.Errorf("failed to save portfolio: %w", err)
+	if current {
+		if err := position.UpdatePrice(price); err != nil {
+			return nil, fmt.Errorf("failed to update position price: %w", err)
+		}
 	}
 
-	return &position, nil
+	result := *position
+	return &result, nil
 }
 
 func (s *PortfolioService) RemovePosition(ctx context.Context, positionID string) error {
@@ -114,9 +262,18 @@ func (s *PortfolioService) GetPortfolioSummary(ctx context.Context) (*domain.Por
 	return s.defaultPortfolio, nil
 }
 
+// RefreshPrices quotes the open positions, then the watchlist items,
+// stores the quotes as the bars of the day, snapshots the portfolio
+// valuation and checks the alert rules.
 func (s *PortfolioService) RefreshPrices(ctx context.Context) error {
+	now := time.Now()
+	closes := make([]domain.PricePoint, 0, len(s.defaultPortfolio.Positions))
 	for i := range s.defaultPortfolio.Positions {
 		pos := &s.defaultPortfolio.Positions[i]
+		// Matured bonds are no longer quoted
+		if pos.IsClosed() || pos.Instrument.IsMatured(now) {
+			continue
+		}
 
 		quote, err := s.marketData.GetQuote(ctx, pos.Instrument.Symbol)
 		if err != nil {
@@ -131,11 +288,361 @@ func (s *PortfolioService) RefreshPrices(ctx context.Context) error {
 		if err := pos.UpdatePrice(price); err != nil {
 			return fmt.Errorf("failed to update price for %s: %w", pos.Instrument.Symbol, err)
 		}
+		point := domain.NewPricePoint(pos.Instrument.ISIN, now, price, pos.ValueCurrency(), priceSourceQuote)
+		point.Open, point.High, point.Low, point.Volume = quote.Open, quote.High, quote.Low, quote.Volume
+		closes = append(closes, point)
 	}
 
 	if err := s.repo.Save(ctx, s.defaultPortfolio); err != nil {
 		return fmt.Errorf("failed to save portfolio: %w", err)
 	}
+	closes = append(closes, s.refreshWatchlists(ctx, now, closes)...)
+	s.recordCloses(ctx, now, closes)
+	s.recordSnapshot(ctx, now)
+	s.evaluateAlerts(ctx, now, closes)
 
 	return nil
 }
+
+// RecordTransactionRequest describes a manual ledger entry.
+// Amount is optional for trades and defaults to quantity * price.
+// Fee and Tax are charged on top of the amount, each in its own currency.
+type RecordTransactionRequest struct {
+	ISIN      string                 `json:"isin" binding:"required"`
+	Type      domain.TransactionType `json:"type" binding:"required"`
+	TradeDate time.Time              `json:"trade_date"`
+	Quantity  domain.Decimal         `json:"quantity"`
+	Price     domain.Decimal         `json:"price"`
+	Amount    domain.Decimal         `json:"amount"`
+	Currency  string                 `json:"currency" binding:"required"`
+	LotID     string                 `json:"lot_id"`
+	Fee       domain.Money           `json:"fee"`
+	Tax       domain.Money           `json:"tax"`
+}
+
+// RecordTransaction books a ledger entry and updates the affected position.
+func (s *PortfolioService) RecordTransaction(ctx context.Context, req RecordTransactionRequest) (*domain.Transaction, error) {
+	instrument, err := s.ResolveInstrument(ctx, req.ISIN)
+	if err != nil {
+		return nil, err
+	}
+
+	tradeDate := req.TradeDate
+	if tradeDate.IsZero() {
+		tradeDate = time.Now()
+	}
+
+	amount := req.Amount
+	if amount.IsZero() && req.Type.IsTrade() {
+		amount, err = req.Quantity.Mul(req.Price)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate amount: %w", err)
+		}
+	}
+
+	tx := domain.NewTransaction(req.Type, instrument.ISIN, tradeDate, req.Quantity, req.Price, amount, req.Currency)
+	tx.LotID = req.LotID
+	if err := s.setCharges(ctx, &tx, domain.TradeCharges{Fee: req.Fee, Tax: req.Tax}); err != nil {
+		return nil, err
+	}
+	if _, err := s.defaultPortfolio.RecordTransaction(*instrument, tx); err != nil {
+		return nil, fmt.Errorf("failed to record transaction: %w", err)
+	}
+
+	if err := s.repo.Save(ctx, s.defaultPortfolio); err != nil {
+		return nil, fmt.Errorf("failed to save portfolio: %w", err)
+	}
+
+	recorded := s.defaultPortfolio.Transactions[len(s.defaultPortfolio.Transactions)-1]
+	return &recorded, nil
+}
+
+// ListTransactions returns the ledger of the default portfolio in trade date order.
+func (s *PortfolioService) ListTransactions(ctx context.Context) ([]domain.Transaction, error) {
+	slog.DebugContext(ctx, "listing transactions", "count", len(s.defaultPortfolio.Transactions))
+	ledger := make([]domain.Transaction, len(s.defaultPortfolio.Transactions))
+	copy(ledger, s.defaultPortfolio.Transactions)
+	sort.SliceStable(ledger, func(i, j int) bool {
+		return ledger[i].TradeDate.Before(ledger[j].TradeDate)
+	})
+	return ledger, nil
+}
+
+// SetCostBasisMethod changes the lot selection policy applied to future sales.
+func (s *PortfolioService) SetCostBasisMethod(ctx context.Context, method domain.CostBasisMethod) error {
+	if err := s.defaultPortfolio.SetCostBasisMethod(method); err != nil {
+		return fmt.Errorf("failed to set cost basis method: %w", err)
+	}
+
+	if err := s.repo.Save(ctx, s.defaultPortfolio); err != nil {
+		return fmt.Errorf("failed to save portfolio: %w", err)
+	}
+
+	slog.InfoContext(ctx, "cost basis method updated", "portfolio_id", s.defaultPortfolio.ID, "method", method)
+	return nil
+}
+
+// SellPositionRequest describes a partial or full sale of a position.
+// Exactly one of Quantity or Amount must be set; Amount is converted to
+// units at Price. When Price is omitted the latest quote is used.
+// Fee and Tax are deducted from the proceeds, each in its own currency.
+type SellPositionRequest struct {
+	Quantity  domain.Decimal `json:"quantity"`
+	Amount    domain.Decimal `json:"amount"`
+	Price     domain.Decimal `json:"price"`
+	TradeDate time.Time      `json:"trade_date"`
+	LotID     string         `json:"lot_id"`
+	Fee       domain.Money   `json:"fee"`
+	Tax       domain.Money   `json:"tax"`
+}
+
+// SellPosition reduces a position and books the realized profit/loss.
+// Selling the whole quantity closes the position but keeps it in the portfolio.
+func (s *PortfolioService) SellPosition(ctx context.Context, positionID string, req SellPositionRequest) (*domain.Position, error) {
+	position, err := s.defaultPortfolio.GetPosition(positionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get position: %w", err)
+	}
+	if req.Quantity.IsZero() == req.Amount.IsZero() {
+		return nil, fmt.Errorf("%w: exactly one of quantity or amount is required", domain.ErrInvalidTransaction)
+	}
+
+	price := req.Price
+	if price.IsZero() {
+		quote, err := s.marketData.GetQuote(ctx, position.Instrument.Symbol)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get quote: %w", err)
+		}
+		if price, err = domain.NewDecimalFromString(quote.Price.String()); err != nil {
+			return nil, fmt.Errorf("failed to parse quote price: %w", err)
+		}
+	}
+
+	// Prices are quoted in the instrument currency, while proceeds are
+	// booked in the currency the position was invested in.
+	quoteCurrency, bookCurrency := position.ValueCurrency(), position.InvestedAmount.Currency
+	quantity, amount := req.Quantity, req.Amount
+	if quantity.IsZero() {
+		quoted, err := s.convertOn(ctx, amount, bookCurrency, quoteCurrency, req.TradeDate)
+		if err != nil {
+			return nil, err
+		}
+		if quantity, err = quoted.Div(price); err != nil {
+			return nil, fmt.Errorf("failed to calculate quantity: %w", err)
+		}
+	} else {
+		proceeds, err := quantity.Mul(price)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate proceeds: %w", err)
+		}
+		if amount, err = s.convertOn(ctx, proceeds, quoteCurrency, bookCurrency, req.TradeDate); err != nil {
+			return nil, err
+		}
+	}
+
+	tradeDate := req.TradeDate
+	if tradeDate.IsZero() {
+		tradeDate = time.Now()
+	}
+
+	tx := domain.NewTransaction(domain.TransactionTypeSell, position.Instrument.ISIN, tradeDate, quantity, price, amount, position.InvestedAmount.Currency)
+	tx.LotID = req.LotID
+	if err := s.setCharges(ctx, &tx, domain.TradeCharges{Fee: req.Fee, Tax: req.Tax}); err != nil {
+		return nil, err
+	}
+	sold, err := s.defaultPortfolio.RecordTransaction(position.Instrument, tx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sell position: %w", err)
+	}
+
+	if err := s.repo.Save(ctx, s.defaultPortfolio); err != nil {
+		return nil, fmt.Errorf("failed to save portfolio: %w", err)
+	}
+
+	slog.InfoContext(ctx, "position sold", "position_id", positionID, "quantity", quantity, "realized_profit_loss", sold.RealizedProfitLoss)
+	result := *sold
+	return &result, nil
+}
+
+// SetBaseCurrency changes the currency the portfolio totals are reported in.
+func (s *PortfolioService) SetBaseCurrency(ctx context.Context, currency string) error {
+	if err := s.defaultPortfolio.SetBaseCurrency(currency); err != nil {
+		return fmt.Errorf("failed to set base currency: %w", err)
+	}
+
+	if err := s.repo.Save(ctx, s.defaultPortfolio); err != nil {
+		return fmt.Errorf("failed to save portfolio: %w", err)
+	}
+
+	slog.InfoContext(ctx, "base currency updated", "portfolio_id", s.defaultPortfolio.ID, "currency", currency)
+	return nil
+}
+
+// GetPortfolioValuation returns the portfolio totals converted into the
+// base currency, together with the exchange rates that were applied.
+func (s *PortfolioService) GetPortfolioValuation(ctx context.Context) (*domain.Valuation, error) {
+	rates, err := s.exchangeRates(ctx, s.defaultPortfolio.Currency(), s.defaultPortfolio.Currencies())
+	if err != nil {
+		return nil, err
+	}
+
+	valuation, err := s.defaultPortfolio.Valuate(rates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to value portfolio: %w", err)
+	}
+	return valuation, nil
+}
+
+// setCharges attaches trade charges to a ledger entry, converting them
+// into the currency of the entry at the rates of its trade date.
+func (s *PortfolioService) setCharges(ctx context.Context, tx *domain.Transaction, charges domain.TradeCharges) error {
+	rates, err := s.exchangeRatesOn(ctx, tx.Currency, charges.Currencies(), tx.TradeDate)
+	if err != nil {
+		return err
+	}
+	if err := tx.SetCharges(charges, rates); err != nil {
+		return fmt.Errorf("failed to set trade charges: %w", err)
+	}
+	return nil
+}
+
+// exchangeRates fetches the rates needed to convert currencies into target.
+func (s *PortfolioService) exchangeRates(ctx context.Context, target string, currencies []string) (*domain.ExchangeRates, error) {
+	return s.exchangeRatesOn(ctx, target, currencies, time.Time{})
+}
+
+// exchangeRatesOn fetches the rates needed to convert currencies into
+// target on date. Days before today are converted at the rates of that day
+// when the provider has past rates, and at the latest rates otherwise.
+func (s *PortfolioService) exchangeRatesOn(ctx context.Context, target string, currencies []string, date time.Time) (*domain.ExchangeRates, error) {
+	historical, _ := s.fxRates.(marketdata.HistoricalFXRateProvider)
+	if date.IsZero() || !dateOf(date).Before(dateOf(time.Now())) {
+		historical = nil
+	}
+
+	rates := domain.NewExchangeRates(target)
+	for _, currency := range currencies {
+		from, _ := domain.NormalizeCurrency(currency)
+		if from == "" || from == rates.Target {
+			continue
+		}
+		if s.fxRates == nil {
+			return nil, fmt.Errorf("%w: %s/%s (no FX rate provider configured)", domain.ErrFXRateNotFound, from, rates.Target)
+		}
+
+		var rate *domain.FXRate
+		var err error
+		if historical != nil {
+			rate, err = historical.GetHistoricalRate(ctx, from, rates.Target, dateOf(date))
+		} else {
+			rate, err = s.fxRates.GetRate(ctx, from, rates.Target)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to get fx rate %s/%s: %w", from, rates.Target, err)
+		}
+		if err := rates.Add(*rate); err != nil {
+			return nil, err
+		}
+	}
+	return rates, nil
+}
+
+// historicalExchangeRates fetches the rates into the base currency, adding
+// those in effect on each of dates and on the trade dates of the ledger so
+// that past valuations convert at the rates of their day. Without a source
+// of past rates, or for currencies it has no history of, every day
+// converts at the latest rates.
+func (s *PortfolioService) historicalExchangeRates(ctx context.Context, dates []time.Time) (*domain.ExchangeRates, error) {
+	rates, err := s.exchangeRates(ctx, s.defaultPortfolio.Currency(), s.defaultPortfolio.Currencies())
+	if err != nil {
+		return nil, err
+	}
+	provider, ok := s.fxRates.(marketdata.HistoricalFXRateProvider)
+	if !ok {
+		return rates, nil
+	}
+
+	seen := make(map[time.Time]bool)
+	var days []time.Time
+	for _, date := range dates {
+		if day := dateOf(date); !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	for i := range s.defaultPortfolio.Transactions {
+		if day := dateOf(s.defaultPortfolio.Transactions[i].TradeDate); !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Before(days[j])
+	})
+
+	for _, latest := range rates.Rates() {
+		past, err := pastRates(ctx, provider, latest.From, rates.Target, days)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to get historical fx rates, using the latest", "from", latest.From, "to", rates.Target, "error", err)
+			continue
+		}
+		for _, rate := range past {
+			if err := rates.AddHistorical(rate); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return rates, nil
+}
+
+// pastRates returns the rates from provider in effect on days, in one
+// request when the provider supports it. Days without a rate are skipped
+// and convert at the rate of the day before.
+func pastRates(ctx context.Context, provider marketdata.HistoricalFXRateProvider, from, to string, days []time.Time) ([]domain.FXRate, error) {
+	if batch, ok := provider.(marketdata.HistoricalFXRateBatchProvider); ok {
+		return batch.GetHistoricalRates(ctx, from, to, days)
+	}
+
+	past := make([]domain.FXRate, 0, len(days))
+	for _, day := range days {
+		rate, err := provider.GetHistoricalRate(ctx, from, to, day)
+		if err != nil {
+			slog.DebugContext(ctx, "no historical fx rate", "from", from, "to", to, "date", day, "error", err)
+			continue
+		}
+		if rate.AsOf.IsZero() {
+			rate.AsOf = day
+		}
+		past = append(past, *rate)
+	}
+	return past, nil
+}
+
+// convert expresses an amount in currency from as currency to, keeping
+// minor-unit quote currencies such as GBX in their minor unit.
+func (s *PortfolioService) convert(ctx context.Context, amount domain.Decimal, from, to string) (domain.Decimal, error) {
+	return s.convertOn(ctx, amount, from, to, time.Time{})
+}
+
+// convertOn is convert at the rates in effect on date.
+func (s *PortfolioService) convertOn(ctx context.Context, amount domain.Decimal, from, to string, date time.Time) (domain.Decimal, error) {
+	if from == to || from == "" || to == "" {
+		return amount, nil
+	}
+
+	rates, err := s.exchangeRatesOn(ctx, to, []string{from}, date)
+	if err != nil {
+		return domain.Zero, err
+	}
+	converted, err := rates.Convert(amount, from)
+	if err != nil {
+		return domain.Zero, fmt.Errorf("failed to convert amount: %w", err)
+	}
+
+	_, minorUnits := domain.NormalizeCurrency(to)
+	result, err := converted.Mul(minorUnits)
+	if err != nil {
+		return domain.Zero, fmt.Errorf("failed to convert to minor units: %w", err)
+	}
+	return result, nil
+}
@@ -2,8 +2,10 @@ package application
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/jmanzanog/stock-tracker/internal/domain"
 	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata"
@@ -151,11 +153,11 @@ func TestAddPosition_Success(t *testing.T) {
 	service, _ := NewPortfolioService(repo, marketData)
 	ctx := context.Background()
 
-	isin := "US0000000001"
+	isin := "US5949181045"
 	amount := domain.NewDecimalFromInt(1000)
 	currency := "USD"
 
-	pos, err := service.AddPosition(ctx, isin, amount, currency)
+	pos, err := service.AddPosition(ctx, isin, amount, currency, domain.TradeCharges{}, TradeDetails{})
 
 	if err != nil {
 		t.Fatalf("AddPosition failed: %v", err)
@@ -170,6 +172,117 @@ func TestAddPosition_Success(t *testing.T) {
 	}
 }
 
+func TestAddPosition_TradeDate(t *testing.T) {
+	ctx := context.Background()
+	isin := "US5949181045"
+	friday := time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC)
+	sunday := time.Date(2021, 3, 14, 15, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		history       bool
+		stored        []domain.PricePoint
+		trade         TradeDetails
+		expectedPrice int64
+	}{
+		// The stored close of the Friday before prices a Sunday trade
+		{"stored close", false, []domain.PricePoint{domain.NewPricePoint(isin, friday, domain.NewDecimalFromInt(100), "USD", priceSourceBackfill)},
+			TradeDetails{TradeDate: sunday}, 100},
+		// The provider's bars close at the day of the month
+		{"provider close", true, nil, TradeDetails{TradeDate: sunday}, 14},
+		{"explicit price", true, nil, TradeDetails{TradeDate: sunday, Price: domain.NewDecimalFromInt(125)}, 125},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var md marketdata.MDataProvider = &MockMarketData{}
+			if tt.history {
+				md = &historyMarketData{}
+			}
+			service, _ := NewPortfolioService(&mockPriceHistoryRepository{prices: tt.stored}, md)
+
+			pos, err := service.AddPosition(ctx, isin, domain.NewDecimalFromInt(1000), "USD", domain.TradeCharges{}, tt.trade)
+			if err != nil {
+				t.Fatalf("AddPosition failed: %v", err)
+			}
+			// Bought at the close, valued at the latest quote
+			quantity, _ := domain.NewDecimalFromInt(1000).Div(domain.NewDecimalFromInt(tt.expectedPrice))
+			if !pos.Quantity.Equal(quantity) || !pos.CurrentPrice.Equal(domain.NewDecimalFromInt(150)) {
+				t.Errorf("expected %s units valued at 150, got %s at %s", quantity, pos.Quantity, pos.CurrentPrice)
+			}
+			if pos.OpenedAt == nil || !pos.OpenedAt.Equal(sunday) {
+				t.Errorf("expected opened at %s, got %v", sunday, pos.OpenedAt)
+			}
+			ledger := service.defaultPortfolio.Transactions
+			if len(ledger) != 1 || !ledger[0].TradeDate.Equal(sunday) || !ledger[0].Price.Equal(domain.NewDecimalFromInt(tt.expectedPrice)) {
+				t.Errorf("expected a buy at %d on %s, got %+v", tt.expectedPrice, sunday, ledger)
+			}
+		})
+	}
+}
+
+func TestAddPosition_TradeDateRate(t *testing.T) {
+	service, _ := NewPortfolioService(&MockRepository{}, &MockMarketData{})
+	service.SetFXRateProvider(&datedFXRates{})
+
+	// 500 EUR were 1000 USD in 2021, not the 1250 USD they are today
+	pos, err := service.AddPosition(context.Background(), "US5949181045", domain.NewDecimalFromInt(500), "EUR", domain.TradeCharges{},
+		TradeDetails{TradeDate: time.Date(2021, 3, 12, 0, 0, 0, 0, time.UTC), Price: domain.NewDecimalFromInt(100)})
+	if err != nil {
+		t.Fatalf("AddPosition failed: %v", err)
+	}
+	if !pos.Quantity.Equal(domain.NewDecimalFromInt(10)) {
+		t.Errorf("expected 10 units at the rate of the trade date, got %s", pos.Quantity)
+	}
+}
+
+func TestAddPosition_BeforeLaterSell(t *testing.T) {
+	ctx := context.Background()
+	service := newDividendService(t, &MockMarketData{})
+	_, err := service.SellPosition(ctx, service.defaultPortfolio.Positions[0].ID, SellPositionRequest{
+		Quantity: domain.NewDecimalFromInt(5), Price: domain.NewDecimalFromInt(200), TradeDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("SellPosition failed: %v", err)
+	}
+
+	// The sale is replayed against the average cost of both buys
+	pos, err := service.AddPosition(ctx, "US0378331005", domain.NewDecimalFromInt(1000), "USD", domain.TradeCharges{},
+		TradeDetails{TradeDate: time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC), Price: domain.NewDecimalFromInt(100)})
+	if err != nil {
+		t.Fatalf("AddPosition failed: %v", err)
+	}
+	if !pos.Quantity.Equal(domain.NewDecimalFromInt(15)) || !pos.RealizedProfitLoss.Amount.Equal(domain.NewDecimalFromInt(375)) {
+		t.Errorf("expected 15 units and 375 realized, got %s and %s", pos.Quantity, pos.RealizedProfitLoss)
+	}
+
+	if err := service.defaultPortfolio.RebuildPositions(); err != nil {
+		t.Fatalf("RebuildPositions failed: %v", err)
+	}
+	replayed := service.defaultPortfolio.Positions[0]
+	if !replayed.Quantity.Equal(pos.Quantity) || !replayed.RealizedProfitLoss.Equal(pos.RealizedProfitLoss) || !replayed.InvestedAmount.Equal(pos.InvestedAmount) {
+		t.Errorf("expected replay to match %s units, %s realized, %s invested, got %s, %s, %s",
+			pos.Quantity, pos.RealizedProfitLoss, pos.InvestedAmount, replayed.Quantity, replayed.RealizedProfitLoss, replayed.InvestedAmount)
+	}
+}
+
+func TestAddPosition_TradeDateErrors(t *testing.T) {
+	ctx := context.Background()
+	service, _ := NewPortfolioService(&mockPriceHistoryRepository{}, &MockMarketData{})
+	amount := domain.NewDecimalFromInt(1000)
+
+	_, err := service.AddPosition(ctx, "US5949181045", amount, "USD", domain.TradeCharges{}, TradeDetails{TradeDate: time.Now().AddDate(0, 0, -30)})
+	if !errors.Is(err, ErrHistoricalPriceNotFound) {
+		t.Errorf("expected ErrHistoricalPriceNotFound, got %v", err)
+	}
+	_, err = service.AddPosition(ctx, "US5949181045", amount, "USD", domain.TradeCharges{}, TradeDetails{TradeDate: time.Now().AddDate(0, 0, 2)})
+	if !errors.Is(err, domain.ErrInvalidTransaction) {
+		t.Errorf("expected ErrInvalidTransaction for a future date, got %v", err)
+	}
+	if len(service.defaultPortfolio.Positions) != 0 {
+		t.Errorf("expected no position, got %+v", service.defaultPortfolio.Positions)
+	}
+}
+
 func TestAddPosition_InstrumentNotFound(t *testing.T) {
 	repo := &MockRepository{}
 	marketData := &MockMarketData{
@@ -178,7 +291,7 @@ func TestAddPosition_InstrumentNotFound(t *testing.T) {
 	service, _ := NewPortfolioService(repo, marketData)
 	ctx := context.Background()
 
-	_, err := service.AddPosition(ctx, "INVALID", domain.NewDecimalFromInt(1000), "USD")
+	_, err := service.AddPosition(ctx, "INVALID", domain.NewDecimalFromInt(1000), "USD", domain.TradeCharges{}, TradeDetails{})
 
 	if err == nil {
 		t.Fatal("expected error when instrument not found")
@@ -193,7 +306,7 @@ func TestAddPosition_QuoteFetchError(t *testing.T) {
 	service, _ := NewPortfolioService(repo, marketData)
 	ctx := context.Background()
 
-	_, err := service.AddPosition(ctx, "US0000000001", domain.NewDecimalFromInt(1000), "USD")
+	_, err := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1000), "USD", domain.TradeCharges{}, TradeDetails{})
 
 	if err == nil {
 		t.Fatal("expected error when quote fetch fails")
@@ -216,7 +329,7 @@ func TestAddPosition_RepositorySaveError(t *testing.T) {
 	// Reset the error to only affect AddPosition call
 	repo.saveError = fmt.Errorf("database write failed")
 
-	_, err := service.AddPosition(ctx, "US0000000001", domain.NewDecimalFromInt(1000), "USD")
+	_, err := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1000), "USD", domain.TradeCharges{}, TradeDetails{})
 
 	if err == nil {
 		t.Fatal("expected error when repository save fails")
@@ -230,7 +343,7 @@ func TestRemovePosition_Success(t *testing.T) {
 	ctx := context.Background()
 
 	// First add a position
-	pos, _ := service.AddPosition(ctx, "US0000000001", domain.NewDecimalFromInt(1000), "USD")
+	pos, _ := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1000), "USD", domain.TradeCharges{}, TradeDetails{})
 
 	// Then remove it
 	err := service.RemovePosition(ctx, pos.ID)
@@ -260,7 +373,7 @@ func TestRemovePosition_RepositoryError(t *testing.T) {
 	ctx := context.Background()
 
 	// Add a position first
-	pos, _ := service.AddPosition(ctx, "US0000000001", domain.NewDecimalFromInt(1000), "USD")
+	pos, _ := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1000), "USD", domain.TradeCharges{}, TradeDetails{})
 
 	// Set repository error
 	repo.saveError = fmt.Errorf("database error")
@@ -279,7 +392,7 @@ func TestGetPosition_Success(t *testing.T) {
 	ctx := context.Background()
 
 	// Add a position first
-	addedPos, _ := service.AddPosition(ctx, "US0000000001", domain.NewDecimalFromInt(1000), "USD")
+	addedPos, _ := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1000), "USD", domain.TradeCharges{}, TradeDetails{})
 
 	// Retrieve it
 	pos, err := service.GetPosition(ctx, addedPos.ID)
@@ -322,11 +435,11 @@ func TestListPositions_Success(t *testing.T) {
 	}
 
 	// Add some positions
-	_, err = service.AddPosition(ctx, "US0000000001", domain.NewDecimalFromInt(1000), "USD")
+	_, err = service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1000), "USD", domain.TradeCharges{}, TradeDetails{})
 	if err != nil {
 		t.Fatalf("AddPosition failed: %v", err)
 	}
-	_, err = service.AddPosition(ctx, "US0000000002", domain.NewDecimalFromInt(2000), "USD")
+	_, err = service.AddPosition(ctx, "US0000000002", domain.NewDecimalFromInt(2000), "USD", domain.TradeCharges{}, TradeDetails{})
 	if err != nil {
 		t.Fatalf("AddPosition failed: %v", err)
 	}
@@ -368,7 +481,7 @@ func TestRefreshPrices_Success(t *testing.T) {
 	ctx := context.Background()
 
 	// Add a position
-	_, err := service.AddPosition(ctx, "US0000000001", domain.NewDecimalFromInt(1000), "USD")
+	_, err := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1000), "USD", domain.TradeCharges{}, TradeDetails{})
 	if err != nil {
 		t.Fatalf("AddPosition failed: %v", err)
 	}
@@ -404,7 +517,7 @@ func TestRefreshPrices_MarketDataError(t *testing.T) {
 
 	// Add a position first (before setting quote error)
 	marketData.quoteError = nil
-	_, err := service.AddPosition(ctx, "US0000000001", domain.NewDecimalFromInt(1000), "USD")
+	_, err := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1000), "USD", domain.TradeCharges{}, TradeDetails{})
 	if err != nil {
 		t.Fatalf("AddPosition failed: %v", err)
 	}
@@ -426,7 +539,7 @@ func TestRefreshPrices_RepositoryError(t *testing.T) {
 	ctx := context.Background()
 
 	// Add a position
-	_, err := service.AddPosition(ctx, "US0000000001", domain.NewDecimalFromInt(1000), "USD")
+	_, err := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1000), "USD", domain.TradeCharges{}, TradeDetails{})
 	if err != nil {
 		t.Fatalf("AddPosition failed: %v", err)
 	}
@@ -440,3 +553,355 @@ func TestRefreshPrices_RepositoryError(t *testing.T) {
 		t.Fatal("expected error when repository save fails")
 	}
 }
+
+func TestAddPosition_RecordsBuyTransaction(t *testing.T) {
+	repo := &MockRepository{}
+	marketData := &MockMarketData{}
+	service, _ := NewPortfolioService(repo, marketData)
+	ctx := context.Background()
+
+	pos, err := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1500), "USD", domain.TradeCharges{}, TradeDetails{})
+	if err != nil {
+		t.Fatalf("AddPosition failed: %v", err)
+	}
+
+	transactions, err := service.ListTransactions(ctx)
+	if err != nil {
+		t.Fatalf("ListTransactions failed: %v", err)
+	}
+	if len(transactions) != 1 {
+		t.Fatalf("expected 1 transaction, got %d", len(transactions))
+	}
+	if transactions[0].Type != domain.TransactionTypeBuy || transactions[0].PositionID != pos.ID {
+		t.Errorf("expected buy transaction for position %s, got %s for %s", pos.ID, transactions[0].Type, transactions[0].PositionID)
+	}
+
+	// 1500 / 150 = 10 units, which must survive a refresh
+	if err := service.RefreshPrices(ctx); err != nil {
+		t.Fatalf("RefreshPrices failed: %v", err)
+	}
+	refreshed, _ := service.GetPosition(ctx, pos.ID)
+	if !refreshed.Quantity.Equal(domain.NewDecimalFromInt(10)) {
+		t.Errorf("expected quantity 10, got %s", refreshed.Quantity)
+	}
+}
+
+func TestRecordTransaction_Sell(t *testing.T) {
+	repo := &MockRepository{}
+	marketData := &MockMarketData{}
+	service, _ := NewPortfolioService(repo, marketData)
+	ctx := context.Background()
+
+	pos, _ := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1500), "USD", domain.TradeCharges{}, TradeDetails{})
+
+	tx, err := service.RecordTransaction(ctx, RecordTransactionRequest{
+		ISIN:     "US5949181045",
+		Type:     domain.TransactionTypeSell,
+		Quantity: domain.NewDecimalFromInt(4),
+		Price:    domain.NewDecimalFromInt(160),
+		Currency: "USD",
+	})
+	if err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+
+	if !tx.Amount.Equal(domain.NewDecimalFromInt(640)) {
+		t.Errorf("expected amount 640, got %s", tx.Amount)
+	}
+	if tx.TradeDate.IsZero() {
+		t.Error("expected trade date to default to now")
+	}
+
+	updated, _ := service.GetPosition(ctx, pos.ID)
+	if !updated.Quantity.Equal(domain.NewDecimalFromInt(6)) {
+		t.Errorf("expected quantity 6, got %s", updated.Quantity)
+	}
+}
+
+func TestRecordTransaction_InstrumentNotFound(t *testing.T) {
+	repo := &MockRepository{}
+	marketData := &MockMarketData{}
+	service, _ := NewPortfolioService(repo, marketData)
+	marketData.searchError = fmt.Errorf("instrument not found")
+
+	_, err := service.RecordTransaction(context.Background(), RecordTransactionRequest{
+		ISIN:     "US5949181045",
+		Type:     domain.TransactionTypeBuy,
+		Quantity: domain.NewDecimalFromInt(1),
+		Price:    domain.NewDecimalFromInt(100),
+		Currency: "USD",
+	})
+	if err == nil {
+		t.Fatal("expected error when instrument not found")
+	}
+}
+
+func TestSetCostBasisMethod_AppliesToSales(t *testing.T) {
+	repo := &MockRepository{}
+	marketData := &MockMarketData{}
+	service, _ := NewPortfolioService(repo, marketData)
+	ctx := context.Background()
+
+	if err := service.SetCostBasisMethod(ctx, domain.CostBasisLIFO); err != nil {
+		t.Fatalf("SetCostBasisMethod failed: %v", err)
+	}
+	if repo.portfolio.CostBasisMethod != domain.CostBasisLIFO {
+		t.Errorf("expected saved method lifo, got %s", repo.portfolio.CostBasisMethod)
+	}
+
+	if err := service.SetCostBasisMethod(ctx, "hifo"); err == nil {
+		t.Fatal("expected error for unsupported method")
+	}
+}
+
+func TestSellPosition_Partial(t *testing.T) {
+	repo := &MockRepository{}
+	marketData := &MockMarketData{}
+	service, _ := NewPortfolioService(repo, marketData)
+	ctx := context.Background()
+
+	// 1500 / 150 = 10 units
+	pos, _ := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1500), "USD", domain.TradeCharges{}, TradeDetails{})
+
+	sold, err := service.SellPosition(ctx, pos.ID, SellPositionRequest{
+		Quantity: domain.NewDecimalFromInt(4),
+		Price:    domain.NewDecimalFromInt(200),
+	})
+	if err != nil {
+		t.Fatalf("SellPosition failed: %v", err)
+	}
+
+	if !sold.Quantity.Equal(domain.NewDecimalFromInt(6)) {
+		t.Errorf("expected quantity 6, got %s", sold.Quantity)
+	}
+	if !sold.RealizedProfitLoss.Equal(domain.NewMoney(domain.NewDecimalFromInt(200), "USD")) {
+		t.Errorf("expected realized 200, got %s", sold.RealizedProfitLoss)
+	}
+	if sold.IsClosed() {
+		t.Error("expected position to stay open")
+	}
+}
+
+func TestSellPosition_TargetAmountAtQuote(t *testing.T) {
+	repo := &MockRepository{}
+	marketData := &MockMarketData{}
+	service, _ := NewPortfolioService(repo, marketData)
+	ctx := context.Background()
+
+	pos, _ := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1500), "USD", domain.TradeCharges{}, TradeDetails{})
+
+	// No price given: the quote (150) converts 300 into 2 units
+	sold, err := service.SellPosition(ctx, pos.ID, SellPositionRequest{Amount: domain.NewDecimalFromInt(300)})
+	if err != nil {
+		t.Fatalf("SellPosition failed: %v", err)
+	}
+
+	if !sold.Quantity.Equal(domain.NewDecimalFromInt(8)) {
+		t.Errorf("expected quantity 8, got %s", sold.Quantity)
+	}
+}
+
+func TestSellPosition_FullClosesWithoutDeleting(t *testing.T) {
+	repo := &MockRepository{}
+	marketData := &MockMarketData{}
+	service, _ := NewPortfolioService(repo, marketData)
+	ctx := context.Background()
+
+	pos, _ := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1500), "USD", domain.TradeCharges{}, TradeDetails{})
+
+	sold, err := service.SellPosition(ctx, pos.ID, SellPositionRequest{
+		Quantity: domain.NewDecimalFromInt(10),
+		Price:    domain.NewDecimalFromInt(100),
+	})
+	if err != nil {
+		t.Fatalf("SellPosition failed: %v", err)
+	}
+
+	if !sold.IsClosed() {
+		t.Error("expected position to be closed")
+	}
+	if !sold.RealizedProfitLoss.Equal(domain.NewMoney(domain.NewDecimalFromInt(-500), "USD")) {
+		t.Errorf("expected realized -500, got %s", sold.RealizedProfitLoss)
+	}
+
+	positions, _ := service.ListPositions(ctx)
+	if len(positions) != 1 {
+		t.Errorf("expected closed position to be kept, got %d positions", len(positions))
+	}
+
+	// Closed positions are not refreshed
+	marketData.quoteError = fmt.Errorf("should not be called")
+	if err := service.RefreshPrices(ctx); err != nil {
+		t.Errorf("RefreshPrices failed: %v", err)
+	}
+}
+
+func TestSellPosition_InvalidRequest(t *testing.T) {
+	repo := &MockRepository{}
+	marketData := &MockMarketData{}
+	service, _ := NewPortfolioService(repo, marketData)
+	ctx := context.Background()
+
+	pos, _ := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1500), "USD", domain.TradeCharges{}, TradeDetails{})
+
+	_, err := service.SellPosition(ctx, pos.ID, SellPositionRequest{Price: domain.NewDecimalFromInt(100)})
+	if !errors.Is(err, domain.ErrInvalidTransaction) {
+		t.Errorf("expected ErrInvalidTransaction, got %v", err)
+	}
+
+	_, err = service.SellPosition(ctx, pos.ID, SellPositionRequest{
+		Quantity: domain.NewDecimalFromInt(11),
+		Price:    domain.NewDecimalFromInt(100),
+	})
+	if !errors.Is(err, domain.ErrInsufficientQuantity) {
+		t.Errorf("expected ErrInsufficientQuantity, got %v", err)
+	}
+
+	_, err = service.SellPosition(ctx, "missing", SellPositionRequest{Quantity: domain.NewDecimalFromInt(1)})
+	if !errors.Is(err, domain.ErrPositionNotFound) {
+		t.Errorf("expected ErrPositionNotFound, got %v", err)
+	}
+}
+
+// MockFXRates quotes 1 EUR = 2 USD.
+type MockFXRates struct{}
+
+func (m *MockFXRates) GetRate(_ context.Context, from, to string) (*domain.FXRate, error) {
+	rate := domain.NewDecimalFromInt(2)
+	switch {
+	case from == "USD" && to == "EUR":
+		rate, _ = domain.NewDecimalFromString("0.5")
+	case from == "EUR" && to == "USD":
+	default:
+		return nil, fmt.Errorf("%w: %s/%s", domain.ErrFXRateNotFound, from, to)
+	}
+	return &domain.FXRate{From: from, To: to, Rate: rate, AsOf: time.Now()}, nil
+}
+
+func TestAddPosition_ConvertsInvestedCurrency(t *testing.T) {
+	repo := &MockRepository{}
+	marketData := &MockMarketData{}
+	service, _ := NewPortfolioService(repo, marketData)
+	service.SetFXRateProvider(&MockFXRates{})
+	ctx := context.Background()
+
+	// 750 EUR = 1500 USD, which buys 10 units quoted at 150 USD
+	pos, err := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(750), "EUR", domain.TradeCharges{}, TradeDetails{})
+	if err != nil {
+		t.Fatalf("AddPosition failed: %v", err)
+	}
+
+	if !pos.Quantity.Equal(domain.NewDecimalFromInt(10)) {
+		t.Errorf("expected quantity 10, got %s", pos.Quantity)
+	}
+	if !pos.InvestedAmount.Equal(domain.NewMoney(domain.NewDecimalFromInt(750), "EUR")) {
+		t.Errorf("expected 750 EUR invested, got %s", pos.InvestedAmount)
+	}
+}
+
+func TestAddPosition_MissingFXProvider(t *testing.T) {
+	repo := &MockRepository{}
+	marketData := &MockMarketData{}
+	service, _ := NewPortfolioService(repo, marketData)
+
+	_, err := service.AddPosition(context.Background(), "US5949181045", domain.NewDecimalFromInt(750), "EUR", domain.TradeCharges{}, TradeDetails{})
+	if !errors.Is(err, domain.ErrFXRateNotFound) {
+		t.Errorf("expected ErrFXRateNotFound, got %v", err)
+	}
+}
+
+func TestGetPortfolioValuation_BaseCurrency(t *testing.T) {
+	repo := &MockRepository{}
+	marketData := &MockMarketData{}
+	service, _ := NewPortfolioService(repo, marketData)
+	service.SetFXRateProvider(&MockFXRates{})
+	ctx := context.Background()
+
+	_, _ = service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1500), "USD", domain.TradeCharges{}, TradeDetails{})
+	_, _ = service.AddPosition(ctx, "US0000000002", domain.NewDecimalFromInt(500), "EUR", domain.TradeCharges{}, TradeDetails{})
+
+	valuation, err := service.GetPortfolioValuation(ctx)
+	if err != nil {
+		t.Fatalf("GetPortfolioValuation failed: %v", err)
+	}
+
+	// 1500 USD -> 750 EUR, plus 500 EUR
+	if valuation.Currency != domain.DefaultBaseCurrency {
+		t.Errorf("expected %s, got %s", domain.DefaultBaseCurrency, valuation.Currency)
+	}
+	if !valuation.TotalValue.Equal(domain.NewDecimalFromInt(1250)) {
+		t.Errorf("expected total value 1250, got %s", valuation.TotalValue)
+	}
+	if len(valuation.FXRates) != 1 || valuation.FXRates[0].From != "USD" {
+		t.Errorf("expected the USD/EUR rate to be reported, got %v", valuation.FXRates)
+	}
+
+	if err := service.SetBaseCurrency(ctx, "USD"); err != nil {
+		t.Fatalf("SetBaseCurrency failed: %v", err)
+	}
+	valuation, err = service.GetPortfolioValuation(ctx)
+	if err != nil {
+		t.Fatalf("GetPortfolioValuation failed: %v", err)
+	}
+	if !valuation.TotalValue.Equal(domain.NewDecimalFromInt(2500)) {
+		t.Errorf("expected total value 2500 USD, got %s", valuation.TotalValue)
+	}
+	if repo.portfolio.BaseCurrency != "USD" {
+		t.Errorf("expected base currency to be saved, got %s", repo.portfolio.BaseCurrency)
+	}
+}
+
+func TestSetBaseCurrency_Invalid(t *testing.T) {
+	repo := &MockRepository{}
+	marketData := &MockMarketData{}
+	service, _ := NewPortfolioService(repo, marketData)
+
+	err := service.SetBaseCurrency(context.Background(), "euro")
+	if !errors.Is(err, domain.ErrInvalidCurrency) {
+		t.Errorf("expected ErrInvalidCurrency, got %v", err)
+	}
+}
+
+func TestAddPosition_WithCharges(t *testing.T) {
+	repo := &MockRepository{}
+	marketData := &MockMarketData{}
+	service, _ := NewPortfolioService(repo, marketData)
+	service.SetFXRateProvider(&MockFXRates{})
+	ctx := context.Background()
+
+	// 1500 USD buys 10 units; the 5 EUR stamp duty costs 10 USD
+	pos, err := service.AddPosition(ctx, "US5949181045", domain.NewDecimalFromInt(1500), "USD", domain.TradeCharges{
+		Fee: domain.NewMoney(domain.NewDecimalFromInt(5), ""),
+		Tax: domain.NewMoney(domain.NewDecimalFromInt(5), "EUR"),
+	}, TradeDetails{})
+	if err != nil {
+		t.Fatalf("AddPosition failed: %v", err)
+	}
+	if !pos.Quantity.Equal(domain.NewDecimalFromInt(10)) {
+		t.Errorf("expected charges not to buy units, got quantity %s", pos.Quantity)
+	}
+	if !pos.InvestedAmount.Amount.Equal(domain.NewDecimalFromInt(1515)) {
+		t.Errorf("expected cost basis 1515 USD, got %s", pos.InvestedAmount)
+	}
+
+	sold, err := service.SellPosition(ctx, pos.ID, SellPositionRequest{
+		Quantity: domain.NewDecimalFromInt(10),
+		Fee:      domain.NewMoney(domain.NewDecimalFromInt(5), "USD"),
+	})
+	if err != nil {
+		t.Fatalf("SellPosition failed: %v", err)
+	}
+	// Proceeds 1500 - 5 against a cost of 1515
+	if !sold.RealizedProfitLoss.Amount.Equal(domain.NewDecimalFromInt(-20)) {
+		t.Errorf("expected realized loss of 20, got %s", sold.RealizedProfitLoss)
+	}
+
+	valuation, err := service.GetPortfolioValuation(ctx)
+	if err != nil {
+		t.Fatalf("GetPortfolioValuation failed: %v", err)
+	}
+	// 10 USD fees and 5 EUR tax, in EUR
+	if !valuation.Fees.TotalFees.Equal(domain.NewDecimalFromInt(5)) || !valuation.Fees.TotalTaxes.Equal(domain.NewDecimalFromInt(5)) {
+		t.Errorf("expected 5 EUR fees and 5 EUR taxes, got %s and %s", valuation.Fees.TotalFees, valuation.Fees.TotalTaxes)
+	}
+}
@@ -5,16 +5,20 @@ import (
 	"fmt"
 	"log/slog"
 	"sync"
+	"time"
 
 	"github.com/jmanzanog/stock-tracker/internal/domain"
 	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata"
 )
 
 // AddPositionBatchRequest represents a single position request in a batch.
+// Fee and Tax are optional and paid on top of the invested amount.
 type AddPositionBatchRequest struct {
 	ISIN           string         `json:"isin"`
 	InvestedAmount domain.Decimal `json:"invested_amount"`
 	Currency       string         `json:"currency"`
+	Fee            domain.Money   `json:"fee"`
+	Tax            domain.Money   `json:"tax"`
 }
 
 // AddPositionResult represents the result of adding a single position.
@@ -43,12 +47,31 @@ func (s *PortfolioService) AddPositionsBatch(ctx context.Context, requests []Add
 		return result
 	}
 
-	// Extract ISINs for batch search
-	isins := make([]string, len(requests))
+	// Extract ISINs for batch search, rejecting invalid identifiers before
+	// any provider call. CUSIPs and SEDOLs are converted to ISINs.
+	isins := make([]string, 0, len(requests))
 	requestMap := make(map[string]AddPositionBatchRequest)
-	for i, req := range requests {
-		isins[i] = req.ISIN
-		requestMap[req.ISIN] = req
+	for _, req := range requests {
+		id, err := domain.ParseIdentifier(req.ISIN)
+		if err != nil {
+			result.Failed = append(result.Failed, AddPositionResult{ISIN: req.ISIN, Error: err.Error()})
+			continue
+		}
+		isin, ok := id.ISIN()
+		if !ok {
+			result.Failed = append(result.Failed, AddPositionResult{
+				ISIN:  req.ISIN,
+				Error: fmt.Sprintf("%v: batch requests need an ISIN, CUSIP or SEDOL, got a %s", ErrUnresolvedIdentifier, id.Type),
+			})
+			continue
+		}
+		if _, seen := requestMap[isin]; !seen {
+			isins = append(isins, isin)
+		}
+		requestMap[isin] = req
+	}
+	if len(isins) == 0 {
+		return result
 	}
 
 	// Try batch provider first, fall back to concurrent calls
@@ -114,8 +137,6 @@ func (s *PortfolioService) AddPositionsBatch(ctx context.Context, requests []Add
 			continue
 		}
 
-		position := domain.NewPosition(*instrument, req.InvestedAmount, req.Currency)
-
 		price, err := domain.NewDecimalFromString(quote.Price.String())
 		if err != nil {
 			result.Failed = append(result.Failed, AddPositionResult{
@@ -125,25 +146,18 @@ func (s *PortfolioService) AddPositionsBatch(ctx context.Context, requests []Add
 			continue
 		}
 
-		if err := position.UpdatePrice(price); err != nil {
-			result.Failed = append(result.Failed, AddPositionResult{
-				ISIN:  isin,
-				Error: fmt.Sprintf("failed to update price: %v", err),
-			})
-			continue
-		}
-
-		if err := s.defaultPortfolio.AddPosition(position); err != nil {
+		position, err := s.recordBuy(ctx, *instrument, req.InvestedAmount, req.Currency, price, time.Time{}, domain.TradeCharges{Fee: req.Fee, Tax: req.Tax})
+		if err != nil {
 			result.Failed = append(result.Failed, AddPositionResult{
 				ISIN:  isin,
-				Error: fmt.Sprintf("failed to add to portfolio: %v", err),
+				Error: err.Error(),
 			})
 			continue
 		}
 
 		result.Successful = append(result.Successful, AddPositionResult{
 			ISIN:     isin,
-			Position: &position,
+			Position: position,
 		})
 	}
 
@@ -300,11 +300,11 @@ func TestAddPositionsBatch_QuoteError(t *testing.T) {
 
 func TestAddPositionsBatch_AddPositionError(t *testing.T) {
 	repo := &MockRepository{}
-	instrument := domain.NewInstrument("US123", "SYMBOL", "Name", domain.InstrumentTypeStock, "USD", "EXCH")
+	instrument := domain.NewInstrument("US0378331005", "SYMBOL", "Name", domain.InstrumentTypeStock, "USD", "EXCH")
 
 	provider := &mockBatchMarketData{
 		searchByISINBatchFunc: func(ctx context.Context, isins []string) []marketdata.SearchResult {
-			return []marketdata.SearchResult{{ISIN: "US123", Instrument: &instrument}}
+			return []marketdata.SearchResult{{ISIN: "US0378331005", Instrument: &instrument}}
 		},
 		getQuoteBatchFunc: func(ctx context.Context, symbols []string) []marketdata.QuoteBatchResult {
 			return []marketdata.QuoteBatchResult{{
@@ -324,7 +324,7 @@ func TestAddPositionsBatch_AddPositionError(t *testing.T) {
 
 	// Request with empty currency will make the position invalid
 	requests := []AddPositionBatchRequest{
-		{ISIN: "US123", InvestedAmount: domain.NewDecimalFromInt(1000), Currency: ""},
+		{ISIN: "US0378331005", InvestedAmount: domain.NewDecimalFromInt(1000), Currency: ""},
 	}
 
 	result := service.AddPositionsBatch(context.Background(), requests)
@@ -339,11 +339,11 @@ func TestAddPositionsBatch_AddPositionError(t *testing.T) {
 
 func TestAddPositionsBatch_InvalidPosition(t *testing.T) {
 	repo := &MockRepository{}
-	instrument := domain.NewInstrument("US123", "SYMBOL", "Name", domain.InstrumentTypeStock, "USD", "EXCH")
+	instrument := domain.NewInstrument("US0378331005", "SYMBOL", "Name", domain.InstrumentTypeStock, "USD", "EXCH")
 
 	provider := &mockBatchMarketData{
 		searchByISINBatchFunc: func(ctx context.Context, isins []string) []marketdata.SearchResult {
-			return []marketdata.SearchResult{{ISIN: "US123", Instrument: &instrument}}
+			return []marketdata.SearchResult{{ISIN: "US0378331005", Instrument: &instrument}}
 		},
 		getQuoteBatchFunc: func(ctx context.Context, symbols []string) []marketdata.QuoteBatchResult {
 			return []marketdata.QuoteBatchResult{{
@@ -363,7 +363,7 @@ func TestAddPositionsBatch_InvalidPosition(t *testing.T) {
 
 	// Invalid request: zero invested amount
 	requests := []AddPositionBatchRequest{
-		{ISIN: "US123", InvestedAmount: domain.Zero, Currency: "USD"},
+		{ISIN: "US0378331005", InvestedAmount: domain.Zero, Currency: "USD"},
 	}
 
 	result := service.AddPositionsBatch(context.Background(), requests)
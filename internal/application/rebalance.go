@@ -0,0 +1,496 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// RebalanceAction tells whether a suggested trade buys or sells.
+type RebalanceAction string
+
+const (
+	RebalanceActionBuy  RebalanceAction = "buy"
+	RebalanceActionSell RebalanceAction = "sell"
+	RebalanceActionHold RebalanceAction = "hold"
+)
+
+// unclassifiedAssetClass groups holdings and targets without a label.
+const unclassifiedAssetClass = "unclassified"
+
+// RebalanceRequest describes new money to invest alongside the rebalance,
+// in the base currency. With BuyOnly set nothing is sold: the contribution
+// is spent on the most underweight instruments first.
+type RebalanceRequest struct {
+	Contribution domain.Decimal
+	BuyOnly      bool
+}
+
+// RebalanceTrade is the suggested trade for one instrument. Amount is in the
+// base currency and Quantity in units at the current price, both negative
+// for sales. Weights are percentages of the portfolio after the
+// contribution. Quantity is omitted when no price is available.
+type RebalanceTrade struct {
+	ISIN            string          `json:"isin"`
+	Symbol          string          `json:"symbol,omitempty"`
+	AssetClass      string          `json:"asset_class"`
+	Action          RebalanceAction `json:"action"`
+	Amount          domain.Decimal  `json:"amount"`
+	Quantity        *domain.Decimal `json:"quantity,omitempty"`
+	CurrentValue    domain.Decimal  `json:"current_value"`
+	CurrentWeight   domain.Decimal  `json:"current_weight"`
+	TargetWeight    domain.Decimal  `json:"target_weight"`
+	ResultingWeight domain.Decimal  `json:"resulting_weight"`
+}
+
+// AssetClassAllocation compares the weight of an asset class before and
+// after the suggested trades with its target.
+type AssetClassAllocation struct {
+	AssetClass      string         `json:"asset_class"`
+	CurrentWeight   domain.Decimal `json:"current_weight"`
+	TargetWeight    domain.Decimal `json:"target_weight"`
+	ResultingWeight domain.Decimal `json:"resulting_weight"`
+}
+
+// RebalancePlan holds the trades that bring the portfolio closest to its
+// target allocations. MaxDrift is the largest gap, in percentage points,
+// left between a resulting and a target weight. Cash balances are not
+// invested; only the contribution is.
+type RebalancePlan struct {
+	Currency     string                 `json:"currency"`
+	Contribution domain.Decimal         `json:"contribution"`
+	BuyOnly      bool                   `json:"buy_only"`
+	CurrentValue domain.Decimal         `json:"current_value"`
+	TargetValue  domain.Decimal         `json:"target_value"`
+	MaxDrift     domain.Decimal         `json:"max_drift"`
+	Trades       []RebalanceTrade       `json:"trades"`
+	AssetClasses []AssetClassAllocation `json:"asset_classes"`
+}
+
+// SetTargetAllocations replaces the target weights of the portfolio.
+func (s *PortfolioService) SetTargetAllocations(ctx context.Context, targets []domain.TargetAllocation) ([]domain.TargetAllocation, error) {
+	if err := s.defaultPortfolio.SetTargetAllocations(targets); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.Save(ctx, s.defaultPortfolio); err != nil {
+		return nil, fmt.Errorf("failed to save portfolio: %w", err)
+	}
+
+	slog.InfoContext(ctx, "target allocations updated", "portfolio_id", s.defaultPortfolio.ID, "count", len(targets))
+	return s.defaultPortfolio.TargetAllocations, nil
+}
+
+// ListTargetAllocations returns the target weights of the portfolio.
+func (s *PortfolioService) ListTargetAllocations(ctx context.Context) ([]domain.TargetAllocation, error) {
+	slog.DebugContext(ctx, "listing target allocations", "count", len(s.defaultPortfolio.TargetAllocations))
+	return s.defaultPortfolio.TargetAllocations, nil
+}
+
+// rebalanceHolding is the open value of an instrument in the base currency.
+type rebalanceHolding struct {
+	instrument domain.Instrument
+	price      domain.Decimal
+	value      domain.Decimal
+}
+
+// Rebalance proposes the buys and sells per ISIN that move the portfolio
+// to its target allocations, valuing holdings at the prices last stored by
+// RefreshPrices. Without BuyOnly every instrument ends at its target weight
+// and holdings without a target are sold; with it the contribution is
+// spread so as to leave the smallest possible drift.
+func (s *PortfolioService) Rebalance(ctx context.Context, req RebalanceRequest) (*RebalancePlan, error) {
+	p := s.defaultPortfolio
+	if len(p.TargetAllocations) == 0 {
+		return nil, fmt.Errorf("%w: no target allocation set", domain.ErrInvalidAllocation)
+	}
+	if req.Contribution.Cmp(domain.Zero) < 0 {
+		return nil, fmt.Errorf("%w: contribution must not be negative", domain.ErrInvalidAllocation)
+	}
+
+	base := p.Currency()
+	rates, err := s.exchangeRates(ctx, base, p.Currencies())
+	if err != nil {
+		return nil, err
+	}
+
+	holdings, order, err := rebalanceHoldings(p, rates)
+	if err != nil {
+		return nil, err
+	}
+	current := domain.Zero
+	for _, isin := range order {
+		if current, err = current.Add(holdings[isin].value); err != nil {
+			return nil, fmt.Errorf("failed to add holding value: %w", err)
+		}
+	}
+	total, err := current.Add(req.Contribution)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add contribution: %w", err)
+	}
+	if total.IsZero() {
+		return nil, fmt.Errorf("%w: the portfolio is empty and there is no contribution", domain.ErrInvalidAllocation)
+	}
+
+	var amounts map[string]domain.Decimal
+	if req.BuyOnly {
+		amounts, err = buyOnlyAmounts(p.TargetAllocations, holdings, req.Contribution)
+	} else {
+		amounts, err = fullRebalanceAmounts(p.TargetAllocations, holdings, order, total)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	currentValue, err := domain.NewMoney(current, base).Round()
+	if err != nil {
+		return nil, fmt.Errorf("failed to round portfolio value: %w", err)
+	}
+	targetValue, err := domain.NewMoney(total, base).Round()
+	if err != nil {
+		return nil, fmt.Errorf("failed to round portfolio value: %w", err)
+	}
+
+	plan := &RebalancePlan{
+		Currency:     base,
+		Contribution: req.Contribution,
+		BuyOnly:      req.BuyOnly,
+		CurrentValue: currentValue.Amount,
+		TargetValue:  targetValue.Amount,
+		MaxDrift:     domain.Zero,
+		Trades:       make([]RebalanceTrade, 0, len(p.TargetAllocations)+len(order)),
+	}
+
+	targeted := make(map[string]bool, len(p.TargetAllocations))
+	for _, t := range p.TargetAllocations {
+		targeted[t.ISIN] = true
+		trade, err := s.rebalanceTrade(ctx, t, holdings[t.ISIN], amounts[t.ISIN], base, total)
+		if err != nil {
+			return nil, err
+		}
+		plan.Trades = append(plan.Trades, *trade)
+	}
+	for _, isin := range order {
+		if targeted[isin] {
+			continue
+		}
+		t := domain.TargetAllocation{ISIN: isin, Weight: domain.Zero}
+		trade, err := s.rebalanceTrade(ctx, t, holdings[isin], amounts[isin], base, total)
+		if err != nil {
+			return nil, err
+		}
+		plan.Trades = append(plan.Trades, *trade)
+	}
+
+	for _, trade := range plan.Trades {
+		drift, err := trade.ResultingWeight.Sub(trade.TargetWeight)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate drift: %w", err)
+		}
+		if drift.Abs().Cmp(plan.MaxDrift) > 0 {
+			plan.MaxDrift = drift.Abs()
+		}
+	}
+	if plan.AssetClasses, err = assetClassAllocations(plan.Trades); err != nil {
+		return nil, err
+	}
+
+	slog.DebugContext(ctx, "rebalance planned", "contribution", req.Contribution, "buy_only", req.BuyOnly, "trades", len(plan.Trades), "max_drift", plan.MaxDrift)
+	return plan, nil
+}
+
+// rebalanceHoldings values the open positions in the target currency of
+// rates, merged by ISIN, and returns the ISINs in position order.
+func rebalanceHoldings(p *domain.Portfolio, rates *domain.ExchangeRates) (map[string]*rebalanceHolding, []string, error) {
+	holdings := make(map[string]*rebalanceHolding)
+	order := make([]string, 0, len(p.Positions))
+	for i := range p.Positions {
+		pos := &p.Positions[i]
+		if pos.IsClosed() {
+			continue
+		}
+		value, err := pos.CurrentValue()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to calculate value of %s: %w", pos.Instrument.ISIN, err)
+		}
+		if value, err = rates.ConvertMoney(value); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert value of %s: %w", pos.Instrument.ISIN, err)
+		}
+
+		h, ok := holdings[pos.Instrument.ISIN]
+		if !ok {
+			h = &rebalanceHolding{instrument: pos.Instrument, price: pos.CurrentPrice, value: domain.Zero}
+			holdings[pos.Instrument.ISIN] = h
+			order = append(order, pos.Instrument.ISIN)
+		}
+		if h.value, err = h.value.Add(value.Amount); err != nil {
+			return nil, nil, fmt.Errorf("failed to add value of %s: %w", pos.Instrument.ISIN, err)
+		}
+	}
+	return holdings, order, nil
+}
+
+// fullRebalanceAmounts moves every instrument to its target share of total
+// and sells everything without a target.
+func fullRebalanceAmounts(targets []domain.TargetAllocation, holdings map[string]*rebalanceHolding, order []string, total domain.Decimal) (map[string]domain.Decimal, error) {
+	hundred := domain.NewDecimalFromInt(100)
+	amounts := make(map[string]domain.Decimal, len(targets)+len(order))
+	for _, isin := range order {
+		amount, err := domain.Zero.Sub(holdings[isin].value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate sale of %s: %w", isin, err)
+		}
+		amounts[isin] = amount
+	}
+	for _, t := range targets {
+		share, err := t.Weight.Mul(total)
+		if err == nil {
+			share, err = share.Div(hundred)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate target value of %s: %w", t.ISIN, err)
+		}
+		if h, ok := holdings[t.ISIN]; ok {
+			share, err = share.Sub(h.value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to calculate trade of %s: %w", t.ISIN, err)
+			}
+		}
+		amounts[t.ISIN] = share
+	}
+	return amounts, nil
+}
+
+// buyOnlyAmounts spreads the contribution over the targets so that the most
+// underweight instruments are topped up first. Instruments are filled in
+// order of value per weight point until they all reach a common level,
+// which minimises the largest remaining drift without selling.
+func buyOnlyAmounts(targets []domain.TargetAllocation, holdings map[string]*rebalanceHolding, contribution domain.Decimal) (map[string]domain.Decimal, error) {
+	type candidate struct {
+		isin   string
+		weight domain.Decimal
+		value  domain.Decimal
+		ratio  domain.Decimal
+	}
+
+	candidates := make([]candidate, 0, len(targets))
+	for _, t := range targets {
+		if t.Weight.IsZero() {
+			continue
+		}
+		c := candidate{isin: t.ISIN, weight: t.Weight, value: domain.Zero}
+		if h, ok := holdings[t.ISIN]; ok {
+			c.value = h.value
+		}
+		ratio, err := c.value.Div(c.weight)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate value per weight of %s: %w", t.ISIN, err)
+		}
+		c.ratio = ratio
+		candidates = append(candidates, c)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].ratio.Cmp(candidates[j].ratio) < 0
+	})
+
+	amounts := make(map[string]domain.Decimal, len(candidates))
+	if contribution.IsZero() || len(candidates) == 0 {
+		return amounts, nil
+	}
+
+	// Find how many of the cheapest candidates share the contribution and
+	// the level, in value per weight point, they are all raised to.
+	values, weights := contribution, domain.Zero
+	level := domain.Zero
+	filled := 0
+	for filled < len(candidates) {
+		var err error
+		if values, err = values.Add(candidates[filled].value); err != nil {
+			return nil, fmt.Errorf("failed to add holding value: %w", err)
+		}
+		if weights, err = weights.Add(candidates[filled].weight); err != nil {
+			return nil, fmt.Errorf("failed to add target weight: %w", err)
+		}
+		if level, err = values.Div(weights); err != nil {
+			return nil, fmt.Errorf("failed to calculate fill level: %w", err)
+		}
+		filled++
+		if filled < len(candidates) && level.Cmp(candidates[filled].ratio) <= 0 {
+			break
+		}
+	}
+
+	for _, c := range candidates[:filled] {
+		amount, err := c.weight.Mul(level)
+		if err == nil {
+			amount, err = amount.Sub(c.value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate purchase of %s: %w", c.isin, err)
+		}
+		amounts[c.isin] = amount
+	}
+	return amounts, nil
+}
+
+// rebalanceTrade builds the suggestion for one instrument, pricing
+// instruments not yet held with a fresh quote.
+func (s *PortfolioService) rebalanceTrade(ctx context.Context, target domain.TargetAllocation, holding *rebalanceHolding, amount domain.Decimal, base string, total domain.Decimal) (*RebalanceTrade, error) {
+	rounded, err := domain.NewMoney(amount, base).Round()
+	if err != nil {
+		return nil, fmt.Errorf("failed to round trade of %s: %w", target.ISIN, err)
+	}
+
+	trade := &RebalanceTrade{
+		ISIN:         target.ISIN,
+		AssetClass:   target.AssetClass,
+		Action:       RebalanceActionHold,
+		Amount:       rounded.Amount,
+		CurrentValue: domain.Zero,
+		TargetWeight: target.Weight,
+	}
+	if trade.AssetClass == "" {
+		trade.AssetClass = unclassifiedAssetClass
+	}
+	switch rounded.Amount.Cmp(domain.Zero) {
+	case 1:
+		trade.Action = RebalanceActionBuy
+	case -1:
+		trade.Action = RebalanceActionSell
+	}
+
+	value := domain.Zero
+	if holding != nil {
+		value = holding.value
+		trade.Symbol = holding.instrument.Symbol
+		current, err := domain.NewMoney(value, base).Round()
+		if err != nil {
+			return nil, fmt.Errorf("failed to round value of %s: %w", target.ISIN, err)
+		}
+		trade.CurrentValue = current.Amount
+	}
+	resulting, err := value.Add(amount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate resulting value of %s: %w", target.ISIN, err)
+	}
+	if trade.CurrentWeight, err = weightOf(value, total); err != nil {
+		return nil, err
+	}
+	if trade.ResultingWeight, err = weightOf(resulting, total); err != nil {
+		return nil, err
+	}
+
+	if trade.Action == RebalanceActionHold {
+		return trade, nil
+	}
+	var instrument domain.Instrument
+	var price domain.Decimal
+	if holding != nil {
+		instrument, price = holding.instrument, holding.price
+	} else {
+		quoted, quote, err := s.quoteByISIN(ctx, target.ISIN)
+		if err != nil {
+			slog.WarnContext(ctx, "failed to price rebalance trade", "isin", target.ISIN, "error", err)
+			return trade, nil
+		}
+		instrument, price = *quoted, quote
+		trade.Symbol = instrument.Symbol
+	}
+	trade.Quantity = s.rebalanceQuantity(ctx, instrument, price, rounded.Amount, base)
+	return trade, nil
+}
+
+// rebalanceQuantity converts a trade amount in the base currency into units
+// of the instrument. It returns nil when the instrument cannot be priced.
+func (s *PortfolioService) rebalanceQuantity(ctx context.Context, instrument domain.Instrument, price, amount domain.Decimal, base string) *domain.Decimal {
+	if price.IsZero() {
+		return nil
+	}
+	converted, err := s.convert(ctx, amount, base, instrument.Currency)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to convert rebalance trade", "isin", instrument.ISIN, "error", err)
+		return nil
+	}
+	units, err := converted.Div(price)
+	if err == nil {
+		units, err = units.Round(6)
+	}
+	if err != nil {
+		slog.WarnContext(ctx, "failed to calculate rebalance quantity", "isin", instrument.ISIN, "error", err)
+		return nil
+	}
+	return &units
+}
+
+// quoteByISIN resolves an ISIN and returns its instrument and latest price.
+func (s *PortfolioService) quoteByISIN(ctx context.Context, isin string) (*domain.Instrument, domain.Decimal, error) {
+	instrument, err := s.marketData.SearchByISIN(ctx, isin)
+	if err != nil {
+		return nil, domain.Zero, fmt.Errorf("failed to find instrument: %w", err)
+	}
+	quote, err := s.marketData.GetQuote(ctx, instrument.Symbol)
+	if err != nil {
+		return nil, domain.Zero, fmt.Errorf("failed to get quote: %w", err)
+	}
+	price, err := domain.NewDecimalFromString(quote.Price.String())
+	if err != nil {
+		return nil, domain.Zero, fmt.Errorf("failed to parse quote price: %w", err)
+	}
+	return instrument, price, nil
+}
+
+// weightOf returns value as a percentage of total, to two decimals.
+func weightOf(value, total domain.Decimal) (domain.Decimal, error) {
+	share, err := value.Mul(domain.NewDecimalFromInt(100))
+	if err == nil {
+		share, err = share.Div(total)
+	}
+	if err == nil {
+		share, err = share.Round(2)
+	}
+	if err != nil {
+		return domain.Zero, fmt.Errorf("failed to calculate weight: %w", err)
+	}
+	return share, nil
+}
+
+// assetClassAllocations adds up the weights of the trades per asset class,
+// ordered by name.
+func assetClassAllocations(trades []RebalanceTrade) ([]AssetClassAllocation, error) {
+	byClass := make(map[string]*AssetClassAllocation)
+	names := make([]string, 0)
+	for _, trade := range trades {
+		a, ok := byClass[trade.AssetClass]
+		if !ok {
+			a = &AssetClassAllocation{
+				AssetClass:      trade.AssetClass,
+				CurrentWeight:   domain.Zero,
+				TargetWeight:    domain.Zero,
+				ResultingWeight: domain.Zero,
+			}
+			byClass[trade.AssetClass] = a
+			names = append(names, trade.AssetClass)
+		}
+
+		var err error
+		if a.CurrentWeight, err = a.CurrentWeight.Add(trade.CurrentWeight); err != nil {
+			return nil, fmt.Errorf("failed to add asset class weight: %w", err)
+		}
+		if a.TargetWeight, err = a.TargetWeight.Add(trade.TargetWeight); err != nil {
+			return nil, fmt.Errorf("failed to add asset class weight: %w", err)
+		}
+		if a.ResultingWeight, err = a.ResultingWeight.Add(trade.ResultingWeight); err != nil {
+			return nil, fmt.Errorf("failed to add asset class weight: %w", err)
+		}
+	}
+	sort.Strings(names)
+
+	result := make([]AssetClassAllocation, 0, len(names))
+	for _, name := range names {
+		result = append(result, *byClass[name])
+	}
+	return result, nil
+}
@@ -0,0 +1,139 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// newRebalanceService holds 1500 USD (750 EUR) of US0378331005 and targets
+// 60% of it and 40% of a bond ETF not yet held.
+func newRebalanceService(t *testing.T) *PortfolioService {
+	t.Helper()
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&MockFXRates{})
+
+	_, err := service.SetTargetAllocations(context.Background(), []domain.TargetAllocation{
+		{ISIN: "US0378331005", AssetClass: "Equity", Weight: domain.NewDecimalFromInt(60)},
+		{ISIN: "IE00B4L5Y983", AssetClass: "bonds", Weight: domain.NewDecimalFromInt(40)},
+	})
+	if err != nil {
+		t.Fatalf("SetTargetAllocations failed: %v", err)
+	}
+	return service
+}
+
+func TestRebalance_Full(t *testing.T) {
+	service := newRebalanceService(t)
+
+	plan, err := service.Rebalance(context.Background(), RebalanceRequest{Contribution: domain.NewDecimalFromInt(250)})
+	if err != nil {
+		t.Fatalf("Rebalance failed: %v", err)
+	}
+	if plan.Currency != "EUR" || !plan.TargetValue.Equal(domain.NewDecimalFromInt(1000)) {
+		t.Errorf("expected 1000 EUR after the contribution, got %s %s", plan.TargetValue, plan.Currency)
+	}
+	if len(plan.Trades) != 2 {
+		t.Fatalf("expected 2 trades, got %+v", plan.Trades)
+	}
+
+	// 750 EUR held against a 600 EUR target: sell 150 EUR, 300 USD, 2 units at 150 USD
+	sell := plan.Trades[0]
+	if sell.Action != RebalanceActionSell || !sell.Amount.Equal(domain.NewDecimalFromInt(-150)) {
+		t.Errorf("expected to sell 150 EUR, got %s %s", sell.Action, sell.Amount)
+	}
+	if sell.Quantity == nil || !sell.Quantity.Equal(domain.NewDecimalFromInt(-2)) {
+		t.Errorf("expected to sell 2 units, got %v", sell.Quantity)
+	}
+	if !sell.CurrentWeight.Equal(domain.NewDecimalFromInt(75)) {
+		t.Errorf("expected a current weight of 75%%, got %s", sell.CurrentWeight)
+	}
+
+	buy := plan.Trades[1]
+	if buy.Action != RebalanceActionBuy || !buy.Amount.Equal(domain.NewDecimalFromInt(400)) || buy.Symbol != "TESTSYM" {
+		t.Errorf("expected to buy 400 EUR of TESTSYM, got %+v", buy)
+	}
+	if !plan.MaxDrift.IsZero() {
+		t.Errorf("expected no drift left, got %s", plan.MaxDrift)
+	}
+	if len(plan.AssetClasses) != 2 || plan.AssetClasses[1].AssetClass != "equity" || !plan.AssetClasses[1].ResultingWeight.Equal(domain.NewDecimalFromInt(60)) {
+		t.Errorf("expected equity at 60%% after the trades, got %+v", plan.AssetClasses)
+	}
+}
+
+func TestRebalance_BuyOnly(t *testing.T) {
+	service := newRebalanceService(t)
+
+	plan, err := service.Rebalance(context.Background(), RebalanceRequest{
+		Contribution: domain.NewDecimalFromInt(250),
+		BuyOnly:      true,
+	})
+	if err != nil {
+		t.Fatalf("Rebalance failed: %v", err)
+	}
+
+	// The whole contribution goes to the underweight bonds
+	if plan.Trades[0].Action != RebalanceActionHold || !plan.Trades[0].Amount.IsZero() {
+		t.Errorf("expected to hold the overweight equity, got %+v", plan.Trades[0])
+	}
+	if !plan.Trades[1].Amount.Equal(domain.NewDecimalFromInt(250)) {
+		t.Errorf("expected to buy 250 EUR of bonds, got %s", plan.Trades[1].Amount)
+	}
+	if !plan.MaxDrift.Equal(domain.NewDecimalFromInt(15)) {
+		t.Errorf("expected 15 points of drift left, got %s", plan.MaxDrift)
+	}
+
+	// A large enough contribution reaches the targets without selling
+	plan, err = service.Rebalance(context.Background(), RebalanceRequest{
+		Contribution: domain.NewDecimalFromInt(1000),
+		BuyOnly:      true,
+	})
+	if err != nil {
+		t.Fatalf("Rebalance failed: %v", err)
+	}
+	if !plan.Trades[0].Amount.Equal(domain.NewDecimalFromInt(300)) || !plan.Trades[1].Amount.Equal(domain.NewDecimalFromInt(700)) {
+		t.Errorf("expected 300 and 700 EUR of buys, got %s and %s", plan.Trades[0].Amount, plan.Trades[1].Amount)
+	}
+	if !plan.MaxDrift.IsZero() {
+		t.Errorf("expected no drift left, got %s", plan.MaxDrift)
+	}
+}
+
+func TestRebalance_SellsHoldingsWithoutTarget(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&MockFXRates{})
+	_, err := service.SetTargetAllocations(context.Background(), []domain.TargetAllocation{
+		{ISIN: "IE00B4L5Y983", Weight: domain.NewDecimalFromInt(100)},
+	})
+	if err != nil {
+		t.Fatalf("SetTargetAllocations failed: %v", err)
+	}
+
+	plan, err := service.Rebalance(context.Background(), RebalanceRequest{})
+	if err != nil {
+		t.Fatalf("Rebalance failed: %v", err)
+	}
+	if len(plan.Trades) != 2 || plan.Trades[1].ISIN != "US0378331005" || !plan.Trades[1].Amount.Equal(domain.NewDecimalFromInt(-750)) {
+		t.Errorf("expected the untargeted holding to be sold, got %+v", plan.Trades)
+	}
+	if plan.Trades[1].AssetClass != unclassifiedAssetClass {
+		t.Errorf("expected the holding to be unclassified, got %q", plan.Trades[1].AssetClass)
+	}
+}
+
+func TestRebalance_Invalid(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+
+	if _, err := service.Rebalance(context.Background(), RebalanceRequest{}); !errors.Is(err, domain.ErrInvalidAllocation) {
+		t.Errorf("expected ErrInvalidAllocation without targets, got %v", err)
+	}
+
+	_, err := service.SetTargetAllocations(context.Background(), []domain.TargetAllocation{
+		{ISIN: "US0378331005", Weight: domain.NewDecimalFromInt(60)},
+	})
+	if !errors.Is(err, domain.ErrInvalidAllocation) {
+		t.Errorf("expected ErrInvalidAllocation for weights under 100%%, got %v", err)
+	}
+}
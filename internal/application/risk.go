@@ -0,0 +1,61 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// SetRiskFreeRate sets the yearly rate, in percent, that Sharpe and Sortino
+// ratios are measured against when a request does not give one.
+func (s *PortfolioService) SetRiskFreeRate(rate domain.Decimal) {
+	s.riskFreeRate = rate
+}
+
+// GetRisk returns the volatility, maximum drawdown, Sharpe and Sortino
+// ratios and beta against the benchmark of the portfolio and its positions
+// over the named period (1M, 3M, YTD, 1Y or ALL), ending now, from stored
+// daily closes. A nil riskFreeRate uses the configured one.
+func (s *PortfolioService) GetRisk(ctx context.Context, periodName string, riskFreeRate *domain.Decimal) (*domain.RiskReport, error) {
+	period, err := domain.ParsePerformancePeriod(periodName)
+	if err != nil {
+		return nil, err
+	}
+	rate := s.riskFreeRate
+	if riskFreeRate != nil {
+		rate = *riskFreeRate
+	}
+
+	isins := make([]string, 0, len(s.defaultPortfolio.Positions)+1)
+	if s.defaultPortfolio.BenchmarkISIN != "" {
+		isins = append(isins, s.defaultPortfolio.BenchmarkISIN)
+	}
+	for i := range s.defaultPortfolio.Positions {
+		isins = append(isins, s.defaultPortfolio.Positions[i].Instrument.ISIN)
+	}
+	now := time.Now()
+	history, err := s.priceHistory(ctx, isins, time.Time{}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	rates, err := s.historicalExchangeRates(ctx, history.TradingDates(isins...))
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := s.portfolioSnapshots(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := s.defaultPortfolio.Risk(period, now, rates, history, snapshots, rate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to measure risk: %w", err)
+	}
+	slog.DebugContext(ctx, "risk measured", "period", period, "observations", report.Observations, "volatility", report.Volatility)
+	return report, nil
+}
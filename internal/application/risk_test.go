@@ -0,0 +1,56 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+func TestGetRisk(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&MockFXRates{})
+	ctx := context.Background()
+
+	if _, err := service.GetRisk(ctx, "ALL", nil); !errors.Is(err, ErrPriceHistoryUnsupported) {
+		t.Errorf("expected ErrPriceHistoryUnsupported, got %v", err)
+	}
+	if _, err := service.GetRisk(ctx, "2W", nil); !errors.Is(err, domain.ErrInvalidPeriod) {
+		t.Errorf("expected ErrInvalidPeriod, got %v", err)
+	}
+
+	jun10 := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	jul10 := time.Date(2024, 7, 10, 0, 0, 0, 0, time.UTC)
+	service.repo = &mockPriceHistoryRepository{prices: []domain.PricePoint{
+		domain.NewPricePoint("US0378331005", jun10, domain.NewDecimalFromInt(165), "USD", priceSourceQuote),
+		domain.NewPricePoint("US0378331005", jul10, domain.NewDecimalFromInt(135), "USD", priceSourceQuote),
+	}}
+	service.SetRiskFreeRate(domain.NewDecimalFromInt(3))
+
+	report, err := service.GetRisk(ctx, "ALL", nil)
+	if err != nil {
+		t.Fatalf("GetRisk failed: %v", err)
+	}
+	if report.Currency != "EUR" || !report.RiskFreeRate.Equal(domain.NewDecimalFromInt(3)) || report.Observations != 3 {
+		t.Fatalf("expected 3 returns in EUR at 3%%, got %+v", report)
+	}
+	// From 165 on Jun 10 down to 135 on Jul 10
+	drawdown := report.MaxDrawdown
+	if drawdown.Peak == nil || !drawdown.Peak.Equal(jun10) || drawdown.Trough == nil || !drawdown.Trough.Equal(jul10) {
+		t.Errorf("expected the drawdown from Jun 10 to Jul 10, got %+v", drawdown)
+	}
+	if len(report.Positions) != 1 || report.Positions[0].RiskMetrics == nil {
+		t.Errorf("expected metrics for the position, got %+v", report.Positions)
+	}
+
+	override := domain.Zero
+	report, err = service.GetRisk(ctx, "ALL", &override)
+	if err != nil {
+		t.Fatalf("GetRisk failed: %v", err)
+	}
+	if !report.RiskFreeRate.IsZero() {
+		t.Errorf("expected the requested rate of 0%%, got %s", report.RiskFreeRate)
+	}
+}
@@ -0,0 +1,86 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// ErrSnapshotsUnsupported is returned when the repository does not store
+// portfolio snapshots.
+var ErrSnapshotsUnsupported = errors.New("repository does not store portfolio snapshots")
+
+// GetPortfolioHistory returns the stored end-of-day valuations of the
+// portfolio between from and to, inclusive, sampled at the named interval
+// (day, week or month). A zero from starts at the first snapshot and a
+// zero to ends today.
+func (s *PortfolioService) GetPortfolioHistory(ctx context.Context, from, to time.Time, intervalName string) (*domain.PortfolioHistory, error) {
+	interval, err := domain.ParseSnapshotInterval(intervalName)
+	if err != nil {
+		return nil, err
+	}
+	repo, ok := s.repo.(domain.SnapshotRepository)
+	if !ok {
+		return nil, ErrSnapshotsUnsupported
+	}
+
+	if to.IsZero() {
+		to = time.Now()
+	}
+	snapshots, err := repo.FindSnapshots(ctx, s.defaultPortfolio.ID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load portfolio snapshots: %w", err)
+	}
+
+	history, err := domain.NewPortfolioHistory(s.defaultPortfolio.Currency(), interval, from, to, snapshots)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build portfolio history: %w", err)
+	}
+	return history, nil
+}
+
+// recordSnapshot stores the valuation of the portfolio as the snapshot of
+// the day of now. Each refresh replaces the snapshot of the same day, so
+// the last refresh of the day leaves its end-of-day valuation. Failures
+// are logged rather than returned, as the refreshed prices are already
+// saved.
+func (s *PortfolioService) recordSnapshot(ctx context.Context, now time.Time) {
+	repo, ok := s.repo.(domain.SnapshotRepository)
+	if !ok {
+		return
+	}
+	rates, err := s.exchangeRates(ctx, s.defaultPortfolio.Currency(), s.defaultPortfolio.Currencies())
+	if err != nil {
+		slog.WarnContext(ctx, "failed to get exchange rates for snapshot", "error", err)
+		return
+	}
+	snapshot, err := s.defaultPortfolio.Snapshot(now, rates)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to take portfolio snapshot", "error", err)
+		return
+	}
+	if err := repo.SaveSnapshot(ctx, snapshot); err != nil {
+		slog.WarnContext(ctx, "failed to save portfolio snapshot", "error", err)
+		return
+	}
+	slog.DebugContext(ctx, "portfolio snapshot saved", "date", snapshot.Date, "total_value", snapshot.TotalValue)
+}
+
+// portfolioSnapshots loads the stored snapshots of the portfolio up to to,
+// which returns are chained from where they exist. It returns none when
+// the repository does not store snapshots.
+func (s *PortfolioService) portfolioSnapshots(ctx context.Context, to time.Time) ([]domain.PortfolioSnapshot, error) {
+	repo, ok := s.repo.(domain.SnapshotRepository)
+	if !ok {
+		return nil, nil
+	}
+	snapshots, err := repo.FindSnapshots(ctx, s.defaultPortfolio.ID, time.Time{}, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load portfolio snapshots: %w", err)
+	}
+	return snapshots, nil
+}
@@ -0,0 +1,103 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// mockSnapshotRepository keeps one snapshot per day in memory
+type mockSnapshotRepository struct {
+	MockRepository
+	snapshots []domain.PortfolioSnapshot
+}
+
+func (m *mockSnapshotRepository) SaveSnapshot(_ context.Context, snapshot *domain.PortfolioSnapshot) error {
+	for i := range m.snapshots {
+		if m.snapshots[i].Date.Equal(snapshot.Date) {
+			m.snapshots[i] = *snapshot
+			return nil
+		}
+	}
+	m.snapshots = append(m.snapshots, *snapshot)
+	return nil
+}
+
+func (m *mockSnapshotRepository) FindSnapshots(_ context.Context, portfolioID string, from, to time.Time) ([]domain.PortfolioSnapshot, error) {
+	var found []domain.PortfolioSnapshot
+	for _, s := range m.snapshots {
+		if s.PortfolioID == portfolioID && !s.Date.Before(from) && !s.Date.After(to) {
+			found = append(found, s)
+		}
+	}
+	return found, nil
+}
+
+func TestRefreshPrices_RecordsSnapshot(t *testing.T) {
+	// 10 AAPL quoted at 150 USD, worth 750 EUR
+	service := newDividendService(t, &MockMarketData{})
+	service.SetFXRateProvider(&MockFXRates{})
+	repo := &mockSnapshotRepository{}
+	service.repo = repo
+	ctx := context.Background()
+
+	for range 2 {
+		if err := service.RefreshPrices(ctx); err != nil {
+			t.Fatalf("RefreshPrices failed: %v", err)
+		}
+	}
+	// The second refresh of the day replaces the first snapshot
+	if len(repo.snapshots) != 1 {
+		t.Fatalf("expected 1 snapshot, got %+v", repo.snapshots)
+	}
+	snapshot := repo.snapshots[0]
+	// Bought in 2024, so nothing flowed in today
+	if snapshot.Currency != "EUR" || !snapshot.TotalValue.Equal(domain.NewDecimalFromInt(750)) || !snapshot.NetFlow.IsZero() {
+		t.Errorf("expected a value of 750 EUR without cash flows, got %+v", snapshot)
+	}
+	if len(snapshot.Positions) != 1 || snapshot.Positions[0].ISIN != "US0378331005" ||
+		!snapshot.Positions[0].Price.Equal(domain.NewDecimalFromInt(150)) || snapshot.Positions[0].PriceCurrency != "USD" {
+		t.Errorf("expected the position quoted at 150 USD, got %+v", snapshot.Positions)
+	}
+
+	// Yesterday's snapshot makes a daily history of two points
+	yesterday := snapshot
+	yesterday.Date = snapshot.Date.AddDate(0, 0, -1)
+	yesterday.TotalValue = domain.NewDecimalFromInt(600)
+	repo.snapshots = append([]domain.PortfolioSnapshot{yesterday}, repo.snapshots...)
+
+	history, err := service.GetPortfolioHistory(ctx, time.Time{}, time.Time{}, "")
+	if err != nil {
+		t.Fatalf("GetPortfolioHistory failed: %v", err)
+	}
+	if history.Interval != domain.IntervalDay || history.Currency != "EUR" || len(history.Snapshots) != 2 {
+		t.Fatalf("unexpected history %+v", history)
+	}
+	if !history.Return.Equal(domain.NewDecimalFromInt(25)) {
+		t.Errorf("expected a return of 25%%, got %s", history.Return)
+	}
+
+	history, err = service.GetPortfolioHistory(ctx, snapshot.Date, time.Time{}, "month")
+	if err != nil {
+		t.Fatalf("GetPortfolioHistory failed: %v", err)
+	}
+	if len(history.Snapshots) != 1 || !history.Return.IsZero() {
+		t.Errorf("expected today's snapshot alone, got %+v", history)
+	}
+}
+
+func TestGetPortfolioHistory_Errors(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	ctx := context.Background()
+
+	if _, err := service.GetPortfolioHistory(ctx, time.Time{}, time.Time{}, ""); !errors.Is(err, ErrSnapshotsUnsupported) {
+		t.Errorf("expected ErrSnapshotsUnsupported, got %v", err)
+	}
+	service.repo = &mockSnapshotRepository{}
+	if _, err := service.GetPortfolioHistory(ctx, time.Time{}, time.Time{}, "hourly"); !errors.Is(err, domain.ErrInvalidSnapshotInterval) {
+		t.Errorf("expected ErrInvalidSnapshotInterval, got %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// ErrWatchlistsUnsupported is returned when the repository does not store
+// watchlists.
+var ErrWatchlistsUnsupported = errors.New("repository does not store watchlists")
+
+// AddWatchlistItemRequest puts an instrument on a watchlist. Identifier
+// may replace ISIN with a CUSIP, SEDOL, FIGI or exchange:ticker.
+type AddWatchlistItemRequest struct {
+	ISIN        string          `json:"isin" binding:"required_without=Identifier"`
+	Identifier  string          `json:"identifier"`
+	Notes       string          `json:"notes"`
+	TargetPrice *domain.Decimal `json:"target_price"`
+}
+
+// CreateWatchlist stores a new, empty watchlist.
+func (s *PortfolioService) CreateWatchlist(ctx context.Context, name string) (*domain.WatchlistReport, error) {
+	repo, ok := s.repo.(domain.WatchlistRepository)
+	if !ok {
+		return nil, ErrWatchlistsUnsupported
+	}
+
+	watchlist, err := domain.NewWatchlist(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := repo.SaveWatchlist(ctx, watchlist); err != nil {
+		return nil, fmt.Errorf("failed to save watchlist: %w", err)
+	}
+
+	slog.InfoContext(ctx, "watchlist created", "watchlist_id", watchlist.ID, "name", watchlist.Name)
+	return watchlist.Report()
+}
+
+// ListWatchlists returns every watchlist with the price movements of its
+// items.
+func (s *PortfolioService) ListWatchlists(ctx context.Context) ([]domain.WatchlistReport, error) {
+	repo, ok := s.repo.(domain.WatchlistRepository)
+	if !ok {
+		return nil, ErrWatchlistsUnsupported
+	}
+
+	watchlists, err := repo.FindWatchlists(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load watchlists: %w", err)
+	}
+	reports := make([]domain.WatchlistReport, 0, len(watchlists))
+	for _, watchlist := range watchlists {
+		report, err := watchlist.Report()
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+	return reports, nil
+}
+
+// GetWatchlist returns a watchlist with the price movements of its items.
+func (s *PortfolioService) GetWatchlist(ctx context.Context, id string) (*domain.WatchlistReport, error) {
+	_, watchlist, err := s.findWatchlist(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return watchlist.Report()
+}
+
+// RenameWatchlist changes the name of a watchlist.
+func (s *PortfolioService) RenameWatchlist(ctx context.Context, id, name string) (*domain.WatchlistReport, error) {
+	return s.updateWatchlist(ctx, id, func(watchlist *domain.Watchlist) error {
+		return watchlist.Rename(name)
+	})
+}
+
+// DeleteWatchlist removes a watchlist and its items.
+func (s *PortfolioService) DeleteWatchlist(ctx context.Context, id string) error {
+	repo, ok := s.repo.(domain.WatchlistRepository)
+	if !ok {
+		return ErrWatchlistsUnsupported
+	}
+
+	if err := repo.DeleteWatchlist(ctx, id); err != nil {
+		if errors.Is(err, domain.ErrWatchlistNotFound) {
+			return err
+		}
+		return fmt.Errorf("failed to delete watchlist: %w", err)
+	}
+	slog.InfoContext(ctx, "watchlist deleted", "watchlist_id", id)
+	return nil
+}
+
+// AddWatchlistItem resolves an instrument and puts it on a watchlist. The
+// instrument is quoted right away when possible, and otherwise on the next
+// price refresh.
+func (s *PortfolioService) AddWatchlistItem(ctx context.Context, id string, req AddWatchlistItemRequest) (*domain.WatchlistReport, error) {
+	identifier := req.ISIN
+	if req.Identifier != "" {
+		identifier = req.Identifier
+	}
+	instrument, err := s.ResolveInstrument(ctx, identifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.updateWatchlist(ctx, id, func(watchlist *domain.Watchlist) error {
+		item, err := watchlist.AddItem(*instrument, domain.WatchlistItemDetails{Notes: &req.Notes, TargetPrice: req.TargetPrice})
+		if err != nil {
+			return err
+		}
+		if err := s.quoteWatchlistItem(ctx, item, time.Now()); err != nil {
+			slog.WarnContext(ctx, "failed to quote watchlist item", "isin", instrument.ISIN, "error", err)
+		}
+		return nil
+	})
+}
+
+// UpdateWatchlistItem changes the notes and target price of an item.
+func (s *PortfolioService) UpdateWatchlistItem(ctx context.Context, id, isin string, details domain.WatchlistItemDetails) (*domain.WatchlistReport, error) {
+	return s.updateWatchlist(ctx, id, func(watchlist *domain.Watchlist) error {
+		_, err := watchlist.UpdateItem(isin, details)
+		return err
+	})
+}
+
+// RemoveWatchlistItem takes an instrument off a watchlist.
+func (s *PortfolioService) RemoveWatchlistItem(ctx context.Context, id, isin string) (*domain.WatchlistReport, error) {
+	return s.updateWatchlist(ctx, id, func(watchlist *domain.Watchlist) error {
+		return watchlist.RemoveItem(isin)
+	})
+}
+
+// findWatchlist loads a watchlist from a repository that stores them.
+func (s *PortfolioService) findWatchlist(ctx context.Context, id string) (domain.WatchlistRepository, *domain.Watchlist, error) {
+	repo, ok := s.repo.(domain.WatchlistRepository)
+	if !ok {
+		return nil, nil, ErrWatchlistsUnsupported
+	}
+
+	watchlist, err := repo.FindWatchlist(ctx, id)
+	if err != nil {
+		if errors.Is(err, domain.ErrWatchlistNotFound) {
+			return nil, nil, err
+		}
+		return nil, nil, fmt.Errorf("failed to load watchlist: %w", err)
+	}
+	return repo, watchlist, nil
+}
+
+// updateWatchlist loads a watchlist, applies change and saves it.
+func (s *PortfolioService) updateWatchlist(ctx context.Context, id string, change func(*domain.Watchlist) error) (*domain.WatchlistReport, error) {
+	repo, watchlist, err := s.findWatchlist(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := change(watchlist); err != nil {
+		return nil, fmt.Errorf("failed to update watchlist: %w", err)
+	}
+	if err := repo.SaveWatchlist(ctx, watchlist); err != nil {
+		return nil, fmt.Errorf("failed to save watchlist: %w", err)
+	}
+
+	slog.InfoContext(ctx, "watchlist updated", "watchlist_id", watchlist.ID, "items", len(watchlist.Items))
+	return watchlist.Report()
+}
+
+// quoteWatchlistItem updates an item with the latest quote of its
+// instrument.
+func (s *PortfolioService) quoteWatchlistItem(ctx context.Context, item *domain.WatchlistItem, now time.Time) error {
+	quote, err := s.marketData.GetQuote(ctx, item.Instrument.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get quote for %s: %w", item.Instrument.Symbol, err)
+	}
+	price, err := domain.NewDecimalFromString(quote.Price.String())
+	if err != nil {
+		return fmt.Errorf("failed to parse quote price for %s: %w", item.Instrument.Symbol, err)
+	}
+	return item.UpdatePrice(price, now)
+}
+
+// refreshWatchlists quotes the items of every watchlist, reusing the
+// prices just quoted for positions, and returns the closes of the
+// instruments that were quoted for the watchlists only. Failures are
+// logged rather than returned, so that they do not hold up the positions.
+func (s *PortfolioService) refreshWatchlists(ctx context.Context, now time.Time, quoted []domain.PricePoint) []domain.PricePoint {
+	repo, ok := s.repo.(domain.WatchlistRepository)
+	if !ok {
+		return nil
+	}
+	watchlists, err := repo.FindWatchlists(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to load watchlists", "error", err)
+		return nil
+	}
+
+	prices := make(map[string]domain.Decimal, len(quoted))
+	for _, point := range quoted {
+		prices[point.ISIN] = point.Close
+	}
+	var closes []domain.PricePoint
+	for _, watchlist := range watchlists {
+		for i := range watchlist.Items {
+			item := &watchlist.Items[i]
+			isin := item.Instrument.ISIN
+			if item.Instrument.IsMatured(now) {
+				continue
+			}
+			if price, ok := prices[isin]; ok {
+				if err := item.UpdatePrice(price, now); err != nil {
+					slog.WarnContext(ctx, "failed to update watchlist item", "isin", isin, "error", err)
+				}
+				continue
+			}
+			if err := s.quoteWatchlistItem(ctx, item, now); err != nil {
+				slog.WarnContext(ctx, "failed to quote watchlist item", "isin", isin, "error", err)
+				continue
+			}
+			prices[isin] = item.LastPrice
+			closes = append(closes, domain.NewPricePoint(isin, now, item.LastPrice, item.Instrument.Currency, priceSourceQuote))
+		}
+		if err := repo.SaveWatchlist(ctx, watchlist); err != nil {
+			slog.WarnContext(ctx, "failed to save watchlist", "watchlist_id", watchlist.ID, "error", err)
+		}
+	}
+	return closes
+}
@@ -0,0 +1,141 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// mockWatchlistRepository keeps watchlists in memory
+type mockWatchlistRepository struct {
+	MockRepository
+	watchlists map[string]*domain.Watchlist
+}
+
+func (m *mockWatchlistRepository) SaveWatchlist(_ context.Context, watchlist *domain.Watchlist) error {
+	if m.watchlists == nil {
+		m.watchlists = make(map[string]*domain.Watchlist)
+	}
+	m.watchlists[watchlist.ID] = watchlist
+	return nil
+}
+
+func (m *mockWatchlistRepository) FindWatchlist(_ context.Context, id string) (*domain.Watchlist, error) {
+	watchlist, ok := m.watchlists[id]
+	if !ok {
+		return nil, domain.ErrWatchlistNotFound
+	}
+	return watchlist, nil
+}
+
+func (m *mockWatchlistRepository) FindWatchlists(_ context.Context) ([]*domain.Watchlist, error) {
+	watchlists := make([]*domain.Watchlist, 0, len(m.watchlists))
+	for _, watchlist := range m.watchlists {
+		watchlists = append(watchlists, watchlist)
+	}
+	sort.Slice(watchlists, func(i, j int) bool { return watchlists[i].Name < watchlists[j].Name })
+	return watchlists, nil
+}
+
+func (m *mockWatchlistRepository) DeleteWatchlist(_ context.Context, id string) error {
+	if _, ok := m.watchlists[id]; !ok {
+		return domain.ErrWatchlistNotFound
+	}
+	delete(m.watchlists, id)
+	return nil
+}
+
+func TestWatchlists(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	service.repo = &mockWatchlistRepository{}
+	ctx := context.Background()
+
+	created, err := service.CreateWatchlist(ctx, "Candidates")
+	if err != nil {
+		t.Fatalf("CreateWatchlist failed: %v", err)
+	}
+
+	target := domain.NewDecimalFromInt(120)
+	report, err := service.AddWatchlistItem(ctx, created.ID, AddWatchlistItemRequest{ISIN: "IE00B4L5Y983", Notes: "core holding", TargetPrice: &target})
+	if err != nil {
+		t.Fatalf("AddWatchlistItem failed: %v", err)
+	}
+	if len(report.Items) != 1 {
+		t.Fatalf("expected 1 item, got %+v", report.Items)
+	}
+	// The item is quoted at 150 right away, 20% above the target
+	item := report.Items[0]
+	if !item.LastPrice.Equal(domain.NewDecimalFromInt(150)) || !item.DistanceToTarget.Equal(domain.NewDecimalFromInt(-20)) {
+		t.Errorf("expected a price of 150 and the target 20%% below, got %s and %v", item.LastPrice, item.DistanceToTarget)
+	}
+	if _, err := service.AddWatchlistItem(ctx, created.ID, AddWatchlistItemRequest{Identifier: "IE00B4L5Y983"}); !errors.Is(err, domain.ErrDuplicateWatchlistItem) {
+		t.Errorf("expected ErrDuplicateWatchlistItem, got %v", err)
+	}
+
+	notes := "satellite"
+	if report, err = service.UpdateWatchlistItem(ctx, created.ID, "IE00B4L5Y983", domain.WatchlistItemDetails{Notes: &notes}); err != nil {
+		t.Fatalf("UpdateWatchlistItem failed: %v", err)
+	}
+	if report.Items[0].Notes != "satellite" {
+		t.Errorf("expected updated notes, got %q", report.Items[0].Notes)
+	}
+	if report, err = service.RenameWatchlist(ctx, created.ID, "ETFs"); err != nil || report.Name != "ETFs" {
+		t.Fatalf("expected the watchlist renamed, got %+v, %v", report, err)
+	}
+
+	reports, err := service.ListWatchlists(ctx)
+	if err != nil || len(reports) != 1 {
+		t.Fatalf("expected 1 watchlist, got %+v, %v", reports, err)
+	}
+
+	if report, err = service.RemoveWatchlistItem(ctx, created.ID, "IE00B4L5Y983"); err != nil || len(report.Items) != 0 {
+		t.Fatalf("expected the item removed, got %+v, %v", report, err)
+	}
+	if err := service.DeleteWatchlist(ctx, created.ID); err != nil {
+		t.Fatalf("DeleteWatchlist failed: %v", err)
+	}
+	if _, err := service.GetWatchlist(ctx, created.ID); !errors.Is(err, domain.ErrWatchlistNotFound) {
+		t.Errorf("expected ErrWatchlistNotFound, got %v", err)
+	}
+}
+
+func TestWatchlists_Unsupported(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	if _, err := service.CreateWatchlist(context.Background(), "Candidates"); !errors.Is(err, ErrWatchlistsUnsupported) {
+		t.Errorf("expected ErrWatchlistsUnsupported, got %v", err)
+	}
+}
+
+func TestRefreshPrices_Watchlists(t *testing.T) {
+	service := newDividendService(t, &MockMarketData{})
+	repo := &mockWatchlistRepository{}
+	service.repo = repo
+	ctx := context.Background()
+
+	watchlist, err := domain.NewWatchlist("Candidates")
+	if err != nil {
+		t.Fatalf("NewWatchlist failed: %v", err)
+	}
+	held := domain.NewInstrument("US0378331005", "AAPL", "Apple Inc.", domain.InstrumentTypeStock, "USD", "NASDAQ")
+	other := domain.NewInstrument("IE00B4L5Y983", "IWDA", "iShares Core MSCI World", domain.InstrumentTypeETF, "EUR", "XAMS")
+	for _, instrument := range []domain.Instrument{held, other} {
+		if _, err := watchlist.AddItem(instrument, domain.WatchlistItemDetails{}); err != nil {
+			t.Fatalf("AddItem failed: %v", err)
+		}
+	}
+	if err := repo.SaveWatchlist(ctx, watchlist); err != nil {
+		t.Fatalf("SaveWatchlist failed: %v", err)
+	}
+
+	if err := service.RefreshPrices(ctx); err != nil {
+		t.Fatalf("RefreshPrices failed: %v", err)
+	}
+	for _, item := range repo.watchlists[watchlist.ID].Items {
+		if !item.LastPrice.Equal(domain.NewDecimalFromInt(150)) || item.LastUpdated == nil {
+			t.Errorf("expected %s quoted at 150, got %+v", item.Instrument.ISIN, item)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidAlertRule  = errors.New("invalid alert rule")
+	ErrAlertRuleNotFound = errors.New("alert rule not found")
+)
+
+// AlertKind identifies the quantity an alert rule watches.
+type AlertKind string
+
+const (
+	// AlertPriceAbove fires when the price of an instrument rises to the
+	// threshold or above.
+	AlertPriceAbove AlertKind = "price_above"
+	// AlertPriceBelow fires when the price of an instrument falls to the
+	// threshold or below.
+	AlertPriceBelow AlertKind = "price_below"
+	// AlertDailyMove fires when the price of an instrument has moved by the
+	// threshold, in percent and in either direction, since the previous
+	// close.
+	AlertDailyMove AlertKind = "daily_move"
+	// AlertPositionLoss fires when the profit/loss of a position falls to
+	// minus the threshold, in percent of the invested amount, or below.
+	AlertPositionLoss AlertKind = "position_loss"
+	// AlertPortfolioValueBelow fires when the value of the portfolio, in its
+	// base currency, falls to the threshold or below.
+	AlertPortfolioValueBelow AlertKind = "portfolio_value_below"
+)
+
+// DefaultAlertCooldown is the time, in minutes, a rule stays silent after
+// firing unless set otherwise.
+const DefaultAlertCooldown = 60
+
+// AlertRule is a condition checked after every price refresh. A rule fires
+// when its condition becomes true and is re-armed once the condition is
+// false again, so a price staying beyond its threshold raises a single
+// alert. Active records whether the condition held at the last check. The
+// cooldown additionally keeps a rule that flaps around its threshold from
+// firing more than once per CooldownMinutes.
+type AlertRule struct {
+	ID              string     `json:"id"`
+	Kind            AlertKind  `json:"kind"`
+	ISIN            string     `json:"isin,omitempty"`
+	Threshold       Decimal    `json:"threshold"`
+	CooldownMinutes int        `json:"cooldown_minutes"`
+	Active          bool       `json:"active"`
+	LastTriggeredAt *time.Time `json:"last_triggered_at,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// Alert is a firing of a rule. Value is the quantity the rule watches at
+// the time it fired. DeliveredAt is nil until a webhook accepted the alert;
+// DeliveryError holds the reason of the last failed delivery.
+type Alert struct {
+	ID            string     `json:"id"`
+	RuleID        string     `json:"rule_id"`
+	Kind          AlertKind  `json:"kind"`
+	ISIN          string     `json:"isin,omitempty"`
+	Value         Decimal    `json:"value"`
+	Threshold     Decimal    `json:"threshold"`
+	Message       string     `json:"message"`
+	TriggeredAt   time.Time  `json:"triggered_at"`
+	DeliveredAt   *time.Time `json:"delivered_at,omitempty"`
+	DeliveryError string     `json:"delivery_error,omitempty"`
+}
+
+// NewAlertRule validates a rule. Rules on an instrument or position need
+// its ISIN, while portfolio rules must not have one. Thresholds must be
+// positive; for position losses the threshold is the size of the loss.
+func NewAlertRule(kind AlertKind, isin string, threshold Decimal, cooldownMinutes int) (*AlertRule, error) {
+	isin = strings.ToUpper(strings.TrimSpace(isin))
+	switch kind {
+	case AlertPriceAbove, AlertPriceBelow, AlertDailyMove, AlertPositionLoss:
+		if err := ValidateISIN(isin); err != nil {
+			return nil, fmt.Errorf("%w: %s rules need a valid isin: %w", ErrInvalidAlertRule, kind, err)
+		}
+	case AlertPortfolioValueBelow:
+		if isin != "" {
+			return nil, fmt.Errorf("%w: %s rules apply to the whole portfolio, not %s", ErrInvalidAlertRule, kind, isin)
+		}
+	default:
+		return nil, fmt.Errorf("%w: unknown kind %q", ErrInvalidAlertRule, kind)
+	}
+	if threshold.Cmp(Zero) <= 0 {
+		return nil, fmt.Errorf("%w: threshold must be positive, got %s", ErrInvalidAlertRule, threshold)
+	}
+	if cooldownMinutes < 0 {
+		return nil, fmt.Errorf("%w: cooldown must not be negative, got %d", ErrInvalidAlertRule, cooldownMinutes)
+	}
+
+	return &AlertRule{
+		ID:              uuid.New().String(),
+		Kind:            kind,
+		ISIN:            isin,
+		Threshold:       threshold,
+		CooldownMinutes: cooldownMinutes,
+		CreatedAt:       time.Now(),
+	}, nil
+}
+
+// Evaluate checks the rule against value, the quantity the rule watches,
+// observed at now. It returns the alert when the rule fires and reports
+// whether the state of the rule changed, in which case it must be saved.
+// A condition that becomes true during the cooldown leaves the rule armed,
+// so that it fires once the cooldown is over if the condition still holds.
+func (r *AlertRule) Evaluate(value Decimal, now time.Time) (*Alert, bool) {
+	if !r.holds(value) {
+		changed := r.Active
+		r.Active = false
+		return nil, changed
+	}
+	if r.Active || r.coolingDown(now) {
+		return nil, false
+	}
+
+	r.Active = true
+	triggered := now
+	r.LastTriggeredAt = &triggered
+	return &Alert{
+		ID:          uuid.New().String(),
+		RuleID:      r.ID,
+		Kind:        r.Kind,
+		ISIN:        r.ISIN,
+		Value:       value,
+		Threshold:   r.Threshold,
+		Message:     r.describe(value),
+		TriggeredAt: now,
+	}, true
+}
+
+// holds reports whether the condition of the rule is met by value.
+func (r *AlertRule) holds(value Decimal) bool {
+	switch r.Kind {
+	case AlertPriceAbove:
+		return value.Cmp(r.Threshold) >= 0
+	case AlertPriceBelow, AlertPortfolioValueBelow:
+		return value.Cmp(r.Threshold) <= 0
+	case AlertDailyMove:
+		return value.Abs().Cmp(r.Threshold) >= 0
+	case AlertPositionLoss:
+		return value.Cmp(Zero) < 0 && value.Abs().Cmp(r.Threshold) >= 0
+	default:
+		return false
+	}
+}
+
+func (r *AlertRule) coolingDown(now time.Time) bool {
+	return r.LastTriggeredAt != nil && now.Sub(*r.LastTriggeredAt) < time.Duration(r.CooldownMinutes)*time.Minute
+}
+
+func (r *AlertRule) describe(value Decimal) string {
+	switch r.Kind {
+	case AlertPriceAbove:
+		return fmt.Sprintf("%s price %s is at or above %s", r.ISIN, value, r.Threshold)
+	case AlertPriceBelow:
+		return fmt.Sprintf("%s price %s is at or below %s", r.ISIN, value, r.Threshold)
+	case AlertDailyMove:
+		return fmt.Sprintf("%s moved %s%% since the previous close, beyond %s%%", r.ISIN, value, r.Threshold)
+	case AlertPositionLoss:
+		return fmt.Sprintf("%s position profit/loss %s%% is at or below -%s%%", r.ISIN, value, r.Threshold)
+	default:
+		return fmt.Sprintf("portfolio value %s is at or below %s", value, r.Threshold)
+	}
+}
+
+// DailyMove returns the change from previousClose to price in percent,
+// rounded to two decimals, as watched by AlertDailyMove rules.
+func DailyMove(previousClose, price Decimal) (Decimal, error) {
+	_, percent, err := relativeChange(previousClose, price)
+	if err != nil {
+		return Zero, fmt.Errorf("failed to calculate daily move: %w", err)
+	}
+	return percent.Round(2)
+}
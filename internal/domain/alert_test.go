@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewAlertRule_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		kind      AlertKind
+		isin      string
+		threshold Decimal
+		cooldown  int
+	}{
+		{"unknown kind", "price_crossing", "US0378331005", NewDecimalFromInt(150), 0},
+		{"price rule without isin", AlertPriceAbove, "", NewDecimalFromInt(150), 0},
+		{"invalid isin", AlertPositionLoss, "US0378331006", NewDecimalFromInt(10), 0},
+		{"portfolio rule with isin", AlertPortfolioValueBelow, "US0378331005", NewDecimalFromInt(1000), 0},
+		{"zero threshold", AlertDailyMove, "US0378331005", Zero, 0},
+		{"negative cooldown", AlertPriceBelow, "US0378331005", NewDecimalFromInt(150), -1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewAlertRule(tt.kind, tt.isin, tt.threshold, tt.cooldown); !errors.Is(err, ErrInvalidAlertRule) {
+				t.Errorf("expected ErrInvalidAlertRule, got %v", err)
+			}
+		})
+	}
+}
+
+func TestAlertRule_Evaluate(t *testing.T) {
+	rule, err := NewAlertRule(AlertPriceAbove, " us0378331005 ", NewDecimalFromInt(150), 60)
+	if err != nil {
+		t.Fatalf("NewAlertRule failed: %v", err)
+	}
+	if rule.ISIN != "US0378331005" {
+		t.Errorf("expected a normalized ISIN, got %q", rule.ISIN)
+	}
+	start := time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+
+	steps := []struct {
+		name    string
+		price   int64
+		at      time.Duration
+		fires   bool
+		changed bool
+	}{
+		{"below threshold", 140, 0, false, false},
+		{"crosses the threshold", 151, time.Minute, true, true},
+		{"stays above", 155, 2 * time.Minute, false, false},
+		{"falls back and re-arms", 149, 3 * time.Minute, false, true},
+		{"crosses again during the cooldown", 152, 4 * time.Minute, false, false},
+		{"still above after the cooldown", 153, 62 * time.Minute, true, true},
+	}
+	for _, step := range steps {
+		alert, changed := rule.Evaluate(NewDecimalFromInt(step.price), start.Add(step.at))
+		if (alert != nil) != step.fires || changed != step.changed {
+			t.Fatalf("%s: expected fires=%v changed=%v, got alert %+v changed=%v", step.name, step.fires, step.changed, alert, changed)
+		}
+		if alert != nil && (alert.RuleID != rule.ID || !alert.Value.Equal(NewDecimalFromInt(step.price)) || !alert.TriggeredAt.Equal(start.Add(step.at))) {
+			t.Errorf("%s: unexpected alert %+v", step.name, alert)
+		}
+	}
+	if rule.LastTriggeredAt == nil || !rule.LastTriggeredAt.Equal(start.Add(62*time.Minute)) {
+		t.Errorf("expected the last trigger at the second firing, got %v", rule.LastTriggeredAt)
+	}
+}
+
+func TestAlertRule_Conditions(t *testing.T) {
+	tests := []struct {
+		kind  AlertKind
+		isin  string
+		value string
+		fires bool
+	}{
+		{AlertPriceBelow, "US0378331005", "10", true},
+		{AlertPriceBelow, "US0378331005", "10.01", false},
+		{AlertDailyMove, "US0378331005", "-10.5", true},
+		{AlertDailyMove, "US0378331005", "9.99", false},
+		{AlertPositionLoss, "US0378331005", "-10", true},
+		{AlertPositionLoss, "US0378331005", "-9.5", false},
+		{AlertPositionLoss, "US0378331005", "12", false},
+		{AlertPortfolioValueBelow, "", "9.5", true},
+		{AlertPortfolioValueBelow, "", "11", false},
+	}
+	for _, tt := range tests {
+		rule, err := NewAlertRule(tt.kind, tt.isin, NewDecimalFromInt(10), 0)
+		if err != nil {
+			t.Fatalf("NewAlertRule(%s) failed: %v", tt.kind, err)
+		}
+		alert, _ := rule.Evaluate(mustDecimalFromString(tt.value), time.Now())
+		if (alert != nil) != tt.fires {
+			t.Errorf("%s at %s: expected fires=%v, got %+v", tt.kind, tt.value, tt.fires, alert)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidAllocation = errors.New("invalid target allocation")
+
+// TargetAllocation is the share of the portfolio value, in percent, an
+// instrument should make up. AssetClass is a free label such as "equity"
+// or "bonds" used to aggregate targets.
+type TargetAllocation struct {
+	ISIN       string  `json:"isin"`
+	AssetClass string  `json:"asset_class,omitempty"`
+	Weight     Decimal `json:"weight"`
+}
+
+// SetTargetAllocations replaces the target allocations of the portfolio.
+// Weights must be between 0 and 100 and add up to exactly 100; an empty
+// list clears the targets.
+func (p *Portfolio) SetTargetAllocations(targets []TargetAllocation) error {
+	hundred := NewDecimalFromInt(100)
+	seen := make(map[string]bool, len(targets))
+	total := Zero
+	result := make([]TargetAllocation, 0, len(targets))
+
+	for _, t := range targets {
+		t.ISIN = strings.ToUpper(strings.TrimSpace(t.ISIN))
+		t.AssetClass = strings.ToLower(strings.TrimSpace(t.AssetClass))
+		if t.ISIN == "" {
+			return fmt.Errorf("%w: isin is required", ErrInvalidAllocation)
+		}
+		if err := ValidateISIN(t.ISIN); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidAllocation, err)
+		}
+		if seen[t.ISIN] {
+			return fmt.Errorf("%w: %s is listed twice", ErrInvalidAllocation, t.ISIN)
+		}
+		if t.Weight.Cmp(Zero) < 0 || t.Weight.Cmp(hundred) > 0 {
+			return fmt.Errorf("%w: weight of %s must be between 0 and 100", ErrInvalidAllocation, t.ISIN)
+		}
+		seen[t.ISIN] = true
+
+		var err error
+		if total, err = total.Add(t.Weight); err != nil {
+			return fmt.Errorf("failed to add target weights: %w", err)
+		}
+		result = append(result, t)
+	}
+	if len(result) > 0 && !total.Equal(hundred) {
+		return fmt.Errorf("%w: weights add up to %s%%, not 100%%", ErrInvalidAllocation, total)
+	}
+
+	p.TargetAllocations = result
+	return nil
+}
+
+// TargetAllocation returns the target of an instrument, if any.
+func (p *Portfolio) TargetAllocation(isin string) (TargetAllocation, bool) {
+	for _, t := range p.TargetAllocations {
+		if t.ISIN == isin {
+			return t, true
+		}
+	}
+	return TargetAllocation{}, false
+}
@@ -0,0 +1,47 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestPortfolio_SetTargetAllocations(t *testing.T) {
+	weight := func(v string) Decimal {
+		d, _ := NewDecimalFromString(v)
+		return d
+	}
+
+	testCases := []struct {
+		name    string
+		targets []TargetAllocation
+		err     error
+	}{
+		{"valid", []TargetAllocation{{ISIN: " us0378331005 ", Weight: weight("62.5")}, {ISIN: "IE00B4L5Y983", Weight: weight("37.5")}}, nil},
+		{"empty clears", nil, nil},
+		{"under 100", []TargetAllocation{{ISIN: "US0378331005", Weight: weight("99.9")}}, ErrInvalidAllocation},
+		{"negative", []TargetAllocation{{ISIN: "US0378331005", Weight: weight("110")}, {ISIN: "IE00B4L5Y983", Weight: weight("-10")}}, ErrInvalidAllocation},
+		{"duplicate", []TargetAllocation{{ISIN: "US0378331005", Weight: weight("50")}, {ISIN: "us0378331005", Weight: weight("50")}}, ErrInvalidAllocation},
+		{"missing isin", []TargetAllocation{{Weight: weight("100")}}, ErrInvalidAllocation},
+		{"bad check digit", []TargetAllocation{{ISIN: "US0378331006", Weight: weight("100")}}, ErrInvalidIdentifier},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewPortfolio("Targets")
+			err := p.SetTargetAllocations(tc.targets)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected %v, got %v", tc.err, err)
+			}
+			if err == nil && len(p.TargetAllocations) != len(tc.targets) {
+				t.Errorf("expected %d targets, got %d", len(tc.targets), len(p.TargetAllocations))
+			}
+		})
+	}
+
+	p := NewPortfolio("Targets")
+	if err := p.SetTargetAllocations(testCases[0].targets); err != nil {
+		t.Fatalf("SetTargetAllocations failed: %v", err)
+	}
+	if target, ok := p.TargetAllocation("US0378331005"); !ok || !target.Weight.Equal(weight("62.5")) {
+		t.Errorf("expected the ISIN to be normalized, got %+v", p.TargetAllocations)
+	}
+}
@@ -0,0 +1,167 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrNoBenchmark = errors.New("no benchmark set")
+
+// SetBenchmark sets the ISIN of the index fund or ETF the portfolio is
+// compared against. An empty ISIN removes the benchmark.
+func (p *Portfolio) SetBenchmark(isin string) error {
+	isin = strings.ToUpper(strings.TrimSpace(isin))
+	if isin != "" {
+		if err := ValidateISIN(isin); err != nil {
+			return err
+		}
+	}
+	p.BenchmarkISIN = isin
+	return nil
+}
+
+// BenchmarkPoint holds the returns since the start of the comparison up
+// to Date, as percentages. Difference is the portfolio return minus the
+// benchmark return, in percentage points.
+type BenchmarkPoint struct {
+	Date            time.Time `json:"date"`
+	PortfolioReturn Decimal   `json:"portfolio_return"`
+	BenchmarkReturn Decimal   `json:"benchmark_return"`
+	Difference      Decimal   `json:"difference"`
+}
+
+// BenchmarkComparison sets the time-weighted return of the portfolio, in
+// its base currency, against the price return of the benchmark, in the
+// currency the benchmark is quoted in. ExcessReturn is the gap between the
+// two over the window, in percentage points. TrackingDifference is the
+// same gap between the annualized returns for windows longer than a year,
+// the way it is quoted for funds, and equals ExcessReturn otherwise.
+type BenchmarkComparison struct {
+	BenchmarkISIN      string            `json:"benchmark_isin"`
+	Period             PerformancePeriod `json:"period"`
+	From               time.Time         `json:"from"`
+	To                 time.Time         `json:"to"`
+	Currency           string            `json:"currency"`
+	BenchmarkCurrency  string            `json:"benchmark_currency"`
+	PortfolioReturn    Decimal           `json:"portfolio_return"`
+	BenchmarkReturn    Decimal           `json:"benchmark_return"`
+	ExcessReturn       Decimal           `json:"excess_return"`
+	TrackingDifference Decimal           `json:"tracking_difference"`
+	Points             []BenchmarkPoint  `json:"points"`
+}
+
+// CompareBenchmark measures the portfolio against its benchmark over
+// period, ending at asOf, from the stored closes in history. The portfolio
+// is valued on every day the benchmark has a close, marking holdings at
+// their own stored closes, or at the stored snapshot of the day. The
+// comparison starts at the later of the period start, the first ledger
+// entry and the first benchmark close.
+func (p *Portfolio) CompareBenchmark(period PerformancePeriod, asOf time.Time, rates *ExchangeRates, history PriceHistory, snapshots []PortfolioSnapshot) (*BenchmarkComparison, error) {
+	if p.BenchmarkISIN == "" {
+		return nil, ErrNoBenchmark
+	}
+	benchmark := history[p.BenchmarkISIN]
+	if len(benchmark) < 2 {
+		return nil, fmt.Errorf("%w: %d stored prices for benchmark %s", ErrInsufficientHistory, len(benchmark), p.BenchmarkISIN)
+	}
+
+	series, err := p.ValuationSeriesAt("", history.Dates(p.BenchmarkISIN), asOf, rates, history)
+	if err != nil {
+		return nil, err
+	}
+	series = withSnapshots(series, snapshots, rates.Target, false)
+	if len(series) == 0 {
+		return nil, fmt.Errorf("%w: the ledger is empty", ErrInsufficientHistory)
+	}
+
+	start := period.Start(asOf)
+	if first := benchmark[0].Date; first.After(start) {
+		start = first
+	}
+	window, from := pointsSince(series, start)
+	if len(window) < 2 {
+		return nil, fmt.Errorf("%w: %d valuation points", ErrInsufficientHistory, len(window))
+	}
+	base, ok := history.CloseOn(p.BenchmarkISIN, from)
+	if !ok || base.Close.IsZero() {
+		return nil, fmt.Errorf("%w: no benchmark price on %s", ErrInsufficientHistory, from.Format(time.DateOnly))
+	}
+
+	returns, err := cumulativeReturns(window)
+	if err != nil {
+		return nil, err
+	}
+	comparison := &BenchmarkComparison{
+		BenchmarkISIN:     p.BenchmarkISIN,
+		Period:            period,
+		From:              from,
+		To:                asOf,
+		Currency:          rates.Target,
+		BenchmarkCurrency: base.Currency,
+		Points:            make([]BenchmarkPoint, 0, len(window)),
+	}
+	one := NewDecimalFromInt(1)
+	var portfolioReturn, benchmarkReturn Decimal
+	for i, point := range window {
+		portfolioReturn = returns[i]
+		price, _ := history.CloseOn(p.BenchmarkISIN, point.Date)
+		ratio, err := price.Close.Div(base.Close)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate benchmark return: %w", err)
+		}
+		if benchmarkReturn, err = ratio.Sub(one); err != nil {
+			return nil, fmt.Errorf("failed to calculate benchmark return: %w", err)
+		}
+		benchmarkPoint, err := newBenchmarkPoint(point.Date, portfolioReturn, benchmarkReturn)
+		if err != nil {
+			return nil, err
+		}
+		comparison.Points = append(comparison.Points, benchmarkPoint)
+	}
+
+	last := comparison.Points[len(comparison.Points)-1]
+	comparison.PortfolioReturn = last.PortfolioReturn
+	comparison.BenchmarkReturn = last.BenchmarkReturn
+	comparison.ExcessReturn = last.Difference
+	comparison.TrackingDifference = last.Difference
+	if days := int(asOf.Sub(from).Hours() / 24); days > 365 {
+		annualizedPortfolio, err := AnnualizeReturn(portfolioReturn, days)
+		if err != nil {
+			return nil, err
+		}
+		annualizedBenchmark, err := AnnualizeReturn(benchmarkReturn, days)
+		if err != nil {
+			return nil, err
+		}
+		gap, err := annualizedPortfolio.Sub(annualizedBenchmark)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate tracking difference: %w", err)
+		}
+		if comparison.TrackingDifference, err = asPercent(gap); err != nil {
+			return nil, err
+		}
+	}
+	return comparison, nil
+}
+
+// newBenchmarkPoint expresses the two returns, given as fractions, and
+// their difference as percentages.
+func newBenchmarkPoint(date time.Time, portfolioReturn, benchmarkReturn Decimal) (BenchmarkPoint, error) {
+	difference, err := portfolioReturn.Sub(benchmarkReturn)
+	if err != nil {
+		return BenchmarkPoint{}, fmt.Errorf("failed to calculate return difference: %w", err)
+	}
+	point := BenchmarkPoint{Date: date}
+	if point.PortfolioReturn, err = asPercent(portfolioReturn); err != nil {
+		return BenchmarkPoint{}, err
+	}
+	if point.BenchmarkReturn, err = asPercent(benchmarkReturn); err != nil {
+		return BenchmarkPoint{}, err
+	}
+	if point.Difference, err = asPercent(difference); err != nil {
+		return BenchmarkPoint{}, err
+	}
+	return point, nil
+}
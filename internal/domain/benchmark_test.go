@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPortfolio_SetBenchmark(t *testing.T) {
+	p := NewPortfolio("Benchmark")
+	if err := p.SetBenchmark(" ie00b4l5y983 "); err != nil || p.BenchmarkISIN != "IE00B4L5Y983" {
+		t.Fatalf("expected the ISIN to be normalized, got %q, %v", p.BenchmarkISIN, err)
+	}
+	if err := p.SetBenchmark("IE00B4L5Y984"); !errors.Is(err, ErrInvalidIdentifier) {
+		t.Errorf("expected ErrInvalidIdentifier, got %v", err)
+	}
+	if err := p.SetBenchmark(""); err != nil || p.BenchmarkISIN != "" {
+		t.Errorf("expected the benchmark to be removed, got %q, %v", p.BenchmarkISIN, err)
+	}
+}
+
+func TestPortfolio_CompareBenchmark(t *testing.T) {
+	p := newPerformancePortfolio(t)
+	asOf := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+
+	if _, err := p.CompareBenchmark(PeriodSinceInception, asOf, NewExchangeRates("USD"), nil, nil); !errors.Is(err, ErrNoBenchmark) {
+		t.Fatalf("expected ErrNoBenchmark, got %v", err)
+	}
+	if err := p.SetBenchmark("IE00B4L5Y983"); err != nil {
+		t.Fatalf("SetBenchmark failed: %v", err)
+	}
+	if _, err := p.CompareBenchmark(PeriodSinceInception, asOf, NewExchangeRates("USD"), nil, nil); !errors.Is(err, ErrInsufficientHistory) {
+		t.Fatalf("expected ErrInsufficientHistory without prices, got %v", err)
+	}
+
+	history := NewPriceHistory([]PricePoint{
+		NewPricePoint("IE00B4L5Y983", day(time.January, 10), NewDecimalFromInt(50), "USD", "quote"),
+		NewPricePoint("IE00B4L5Y983", day(time.January, 31), NewDecimalFromInt(55), "USD", "quote"),
+		NewPricePoint("IE00B4L5Y983", day(time.February, 10), NewDecimalFromInt(55), "USD", "quote"),
+		NewPricePoint("IE00B4L5Y983", day(time.February, 29), NewDecimalFromInt(60), "USD", "quote"),
+		NewPricePoint("IE00B4L5Y983", day(time.March, 14), NewDecimalFromInt(50), "USD", "quote"),
+		// The holding is marked at its stored close until the next trade
+		NewPricePoint("US001", day(time.January, 31), NewDecimalFromInt(120), "USD", "quote"),
+	})
+
+	comparison, err := p.CompareBenchmark(PeriodSinceInception, asOf, NewExchangeRates("USD"), history, nil)
+	if err != nil {
+		t.Fatalf("CompareBenchmark failed: %v", err)
+	}
+	if !comparison.From.Equal(day(time.January, 10)) || len(comparison.Points) != 6 {
+		t.Fatalf("expected 6 points from the first buy, got %s %+v", comparison.From, comparison.Points)
+	}
+	jan31 := comparison.Points[1]
+	if !jan31.PortfolioReturn.Equal(NewDecimalFromInt(20)) || !jan31.BenchmarkReturn.Equal(NewDecimalFromInt(10)) || !jan31.Difference.Equal(NewDecimalFromInt(10)) {
+		t.Errorf("expected +20%% against +10%% on Jan 31, got %+v", jan31)
+	}
+	if !comparison.PortfolioReturn.Equal(NewDecimalFromInt(-1)) || !comparison.BenchmarkReturn.IsZero() {
+		t.Errorf("expected -1%% against 0%%, got %s and %s", comparison.PortfolioReturn, comparison.BenchmarkReturn)
+	}
+	if !comparison.ExcessReturn.Equal(NewDecimalFromInt(-1)) || !comparison.TrackingDifference.Equal(NewDecimalFromInt(-1)) {
+		t.Errorf("expected -1 point of excess return, got %s and %s", comparison.ExcessReturn, comparison.TrackingDifference)
+	}
+
+	// The one month window starts from the last values before Feb 15
+	comparison, err = p.CompareBenchmark(PeriodOneMonth, asOf, NewExchangeRates("USD"), history, nil)
+	if err != nil {
+		t.Fatalf("CompareBenchmark failed: %v", err)
+	}
+	benchmarkReturn, _ := comparison.BenchmarkReturn.Round(2)
+	if expected, _ := NewDecimalFromString("-9.09"); !comparison.PortfolioReturn.Equal(NewDecimalFromInt(-10)) || !benchmarkReturn.Equal(expected) {
+		t.Errorf("expected -10%% against -9.09%%, got %s and %s", comparison.PortfolioReturn, comparison.BenchmarkReturn)
+	}
+}
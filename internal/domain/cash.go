@@ -0,0 +1,189 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// CashEffect is the signed amount an entry adds to or takes from the cash
+// account of its currency, in ISO-4217 major units. Buys pay their charges
+// on top of the amount and sells receive the amount net of them; transfers
+// of units leave cash untouched.
+func (t *Transaction) CashEffect() (Money, error) {
+	currency, divisor := NormalizeCurrency(t.Currency)
+
+	var amount Decimal
+	var err error
+	switch t.Type {
+	case TransactionTypeDeposit, TransactionTypeDividend:
+		amount = t.Amount
+	case TransactionTypeWithdrawal, TransactionTypeFee:
+		amount, err = Zero.Sub(t.Amount)
+	case TransactionTypeBuy:
+		if amount, err = t.Amount.Add(t.Costs); err == nil {
+			amount, err = Zero.Sub(amount)
+		}
+	case TransactionTypeSell:
+		amount, err = t.Amount.Sub(t.Costs)
+	default:
+		return ZeroMoney(currency), nil
+	}
+	if err != nil {
+		return Money{}, fmt.Errorf("failed to calculate cash effect of transaction %s: %w", t.ID, err)
+	}
+	if amount, err = amount.Div(divisor); err != nil {
+		return Money{}, fmt.Errorf("failed to convert minor units: %w", err)
+	}
+	return NewMoney(amount, currency), nil
+}
+
+// RecordCashMovement appends a deposit or withdrawal to the ledger.
+func (p *Portfolio) RecordCashMovement(tx Transaction) error {
+	if !tx.Type.IsCashMovement() || !tx.IsValid() || !IsValidCurrency(tx.Currency) {
+		return ErrInvalidTransaction
+	}
+	tx.PortfolioID = p.ID
+	p.Transactions = append(p.Transactions, tx)
+	return nil
+}
+
+// CashMovement is the effect of a ledger entry on a cash account, with the
+// balance of the account after it.
+type CashMovement struct {
+	TransactionID string          `json:"transaction_id"`
+	Type          TransactionType `json:"type"`
+	Date          time.Time       `json:"date"`
+	ISIN          string          `json:"isin,omitempty"`
+	Amount        Money           `json:"amount"`
+	Balance       Money           `json:"balance"`
+}
+
+// CashAccountCurrencies lists the currencies the portfolio holds cash in.
+// A currency has a cash account once money has been deposited or
+// withdrawn in it; entries in other currencies are treated as paid from
+// outside the portfolio.
+func (p *Portfolio) CashAccountCurrencies() []string {
+	seen := make(map[string]bool)
+	result := make([]string, 0)
+	for i := range p.Transactions {
+		if !p.Transactions[i].Type.IsCashMovement() {
+			continue
+		}
+		currency, _ := NormalizeCurrency(p.Transactions[i].Currency)
+		if !seen[currency] {
+			seen[currency] = true
+			result = append(result, currency)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// CashMovements replays the ledger in trade date order and returns every
+// entry that moved cash in currency, or in any cash account when currency
+// is empty, with the running balance of its account.
+func (p *Portfolio) CashMovements(currency string) ([]CashMovement, error) {
+	accounts := make(map[string]Money)
+	for _, code := range p.CashAccountCurrencies() {
+		accounts[code] = ZeroMoney(code)
+	}
+	if currency != "" {
+		currency, _ = NormalizeCurrency(currency)
+	}
+
+	movements := make([]CashMovement, 0)
+	for _, step := range p.replaySteps() {
+		if step.tx == nil {
+			continue
+		}
+		effect, err := step.tx.CashEffect()
+		if err != nil {
+			return nil, err
+		}
+		balance, ok := accounts[effect.Currency]
+		if !ok || effect.IsZero() {
+			continue
+		}
+		if balance, err = balance.Add(effect); err != nil {
+			return nil, fmt.Errorf("failed to update cash balance: %w", err)
+		}
+		accounts[effect.Currency] = balance
+
+		if currency != "" && effect.Currency != currency {
+			continue
+		}
+		movements = append(movements, CashMovement{
+			TransactionID: step.tx.ID,
+			Type:          step.tx.Type,
+			Date:          step.tx.TradeDate,
+			ISIN:          step.tx.InstrumentISIN,
+			Amount:        effect,
+			Balance:       balance,
+		})
+	}
+	return movements, nil
+}
+
+// CashBalances returns the balance of every cash account, ordered by
+// currency. Balances may be negative when trades were paid before the
+// matching deposit was recorded.
+func (p *Portfolio) CashBalances() ([]Money, error) {
+	balances := make(map[string]Money)
+	for _, code := range p.CashAccountCurrencies() {
+		balances[code] = ZeroMoney(code)
+	}
+	for i := range p.Transactions {
+		effect, err := p.Transactions[i].CashEffect()
+		if err != nil {
+			return nil, err
+		}
+		balance, ok := balances[effect.Currency]
+		if !ok {
+			continue
+		}
+		if balances[effect.Currency], err = balance.Add(effect); err != nil {
+			return nil, fmt.Errorf("failed to update cash balance: %w", err)
+		}
+	}
+
+	result := make([]Money, 0, len(balances))
+	for _, code := range p.CashAccountCurrencies() {
+		result = append(result, balances[code])
+	}
+	return result, nil
+}
+
+// CashSummary holds the cash account balances and their total converted
+// into a single currency.
+type CashSummary struct {
+	Currency string  `json:"currency"`
+	Total    Decimal `json:"total"`
+	Balances []Money `json:"balances"`
+}
+
+// Cash converts every cash balance into the target currency of rates and
+// totals them.
+func (p *Portfolio) Cash(rates *ExchangeRates) (*CashSummary, error) {
+	balances, err := p.CashBalances()
+	if err != nil {
+		return nil, err
+	}
+	converted := make([]Money, 0, len(balances))
+	for _, balance := range balances {
+		amount, err := rates.ConvertMoney(balance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s cash: %w", balance.Currency, err)
+		}
+		converted = append(converted, amount)
+	}
+	total, err := sumMoney(rates.Target, converted)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add cash balances: %w", err)
+	}
+	return &CashSummary{
+		Currency: rates.Target,
+		Total:    total.Amount,
+		Balances: balances,
+	}, nil
+}
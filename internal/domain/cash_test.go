@@ -0,0 +1,115 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newCashPortfolio(t *testing.T) (*Portfolio, Instrument) {
+	t.Helper()
+	p := NewPortfolio("Cash")
+	inst := NewInstrument("IE00B4L5Y983", "IWDA", "iShares Core MSCI World", InstrumentTypeETF, "EUR", "XETRA")
+
+	deposit := NewTransaction(TransactionTypeDeposit, "", time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC), Zero, Zero, NewDecimalFromInt(2000), "EUR")
+	if err := p.RecordCashMovement(deposit); err != nil {
+		t.Fatalf("RecordCashMovement failed: %v", err)
+	}
+	buy := NewTransaction(TransactionTypeBuy, inst.ISIN, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		NewDecimalFromInt(10), NewDecimalFromInt(100), NewDecimalFromInt(1000), "EUR")
+	if err := buy.SetCharges(TradeCharges{Fee: NewMoney(NewDecimalFromInt(5), "EUR")}, NewExchangeRates("EUR")); err != nil {
+		t.Fatalf("SetCharges failed: %v", err)
+	}
+	if _, err := p.RecordTransaction(inst, buy); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	return &p, inst
+}
+
+func TestPortfolio_CashBalances(t *testing.T) {
+	p, inst := newCashPortfolio(t)
+	dividend := NewTransaction(TransactionTypeDividend, inst.ISIN, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Zero, Zero, NewDecimalFromInt(15), "EUR")
+	if _, err := p.RecordTransaction(inst, dividend); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	// Paid from outside the portfolio: there is no USD account
+	usd := NewInstrument("US0378331005", "AAPL", "Apple Inc.", InstrumentTypeStock, "USD", "NASDAQ")
+	if _, err := p.RecordTransaction(usd, newBuy(usd.ISIN, 1, 150, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC))); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+
+	balances, err := p.CashBalances()
+	if err != nil {
+		t.Fatalf("CashBalances failed: %v", err)
+	}
+	// 2000 - 1005 + 15
+	if len(balances) != 1 || !balances[0].Equal(NewMoney(NewDecimalFromInt(1010), "EUR")) {
+		t.Errorf("expected 1010 EUR, got %v", balances)
+	}
+
+	movements, err := p.CashMovements("eur")
+	if err != nil {
+		t.Fatalf("CashMovements failed: %v", err)
+	}
+	if len(movements) != 3 {
+		t.Fatalf("expected deposit, buy and dividend, got %d movements", len(movements))
+	}
+	if movements[1].Type != TransactionTypeBuy || !movements[1].Amount.Amount.Equal(NewDecimalFromInt(-1005)) || !movements[1].Balance.Amount.Equal(NewDecimalFromInt(995)) {
+		t.Errorf("expected buy of -1005 leaving 995, got %+v", movements[1])
+	}
+}
+
+func TestPortfolio_RecordCashMovement_Invalid(t *testing.T) {
+	p := NewPortfolio("Cash")
+
+	testCases := []struct {
+		name string
+		tx   Transaction
+	}{
+		{"buy", newBuy("US001", 1, 100, time.Now())},
+		{"zero amount", NewTransaction(TransactionTypeDeposit, "", time.Now(), Zero, Zero, Zero, "EUR")},
+		{"invalid currency", NewTransaction(TransactionTypeWithdrawal, "", time.Now(), Zero, Zero, NewDecimalFromInt(5), "euro")},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := p.RecordCashMovement(tc.tx); !errors.Is(err, ErrInvalidTransaction) {
+				t.Errorf("expected ErrInvalidTransaction, got %v", err)
+			}
+		})
+	}
+}
+
+func TestPortfolio_CashCountsTowardValue(t *testing.T) {
+	p, _ := newCashPortfolio(t)
+	if err := p.UpdatePositionPrice(p.Positions[0].ID, NewDecimalFromInt(110)); err != nil {
+		t.Fatalf("UpdatePositionPrice failed: %v", err)
+	}
+
+	total, err := p.TotalValue()
+	if err != nil {
+		t.Fatalf("TotalValue failed: %v", err)
+	}
+	if !total.Equal(NewMoney(NewDecimalFromInt(2095), "EUR")) {
+		t.Errorf("expected 1100 in units and 995 in cash, got %s", total)
+	}
+
+	valuation, err := p.Valuate(NewExchangeRates("EUR"))
+	if err != nil {
+		t.Fatalf("Valuate failed: %v", err)
+	}
+	if !valuation.TotalCash.Equal(NewDecimalFromInt(995)) || !valuation.TotalValue.Equal(NewDecimalFromInt(2095)) {
+		t.Errorf("expected 995 cash in 2095 total, got %s in %s", valuation.TotalCash, valuation.TotalValue)
+	}
+	if !valuation.TotalUnrealizedProfitLoss.Equal(NewDecimalFromInt(95)) {
+		t.Errorf("expected unrealized gain of 95 on the units only, got %s", valuation.TotalUnrealizedProfitLoss)
+	}
+
+	// Deposits are the cash flows: 2000 became 2095
+	report, err := p.Performance(PeriodSinceInception, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), NewExchangeRates("EUR"), nil, nil)
+	if err != nil {
+		t.Fatalf("Performance failed: %v", err)
+	}
+	if expected, _ := NewDecimalFromString("4.75"); !report.TimeWeightedReturn.Equal(expected) {
+		t.Errorf("expected 4.75%%, got %s", report.TimeWeightedReturn)
+	}
+}
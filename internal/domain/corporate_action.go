@@ -0,0 +1,231 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidCorporateAction   = errors.New("invalid corporate action")
+	ErrDuplicateCorporateAction = errors.New("corporate action already applied")
+)
+
+type CorporateActionType string
+
+const (
+	CorporateActionSplit        CorporateActionType = "split"
+	CorporateActionReverseSplit CorporateActionType = "reverse_split"
+	CorporateActionSymbolChange CorporateActionType = "symbol_change"
+	CorporateActionISINChange   CorporateActionType = "isin_change"
+)
+
+// IsValid reports whether the type is one of the supported corporate actions.
+func (t CorporateActionType) IsValid() bool {
+	switch t {
+	case CorporateActionSplit, CorporateActionReverseSplit,
+		CorporateActionSymbolChange, CorporateActionISINChange:
+		return true
+	}
+	return false
+}
+
+// IsSplit reports whether actions of this type change the units held.
+func (t CorporateActionType) IsSplit() bool {
+	return t == CorporateActionSplit || t == CorporateActionReverseSplit
+}
+
+// CorporateAction is an issuer event that changes how a holding is recorded
+// without any cash changing hands.
+// Splits turn every RatioFrom units held into RatioTo units: a 4-for-1 split
+// has RatioFrom 1 and RatioTo 4, a 1-for-10 reverse split RatioFrom 10 and
+// RatioTo 1. The cost basis is unchanged; unit costs and prices scale down.
+// Symbol and ISIN changes rename the instrument; ISIN and OldSymbol keep the
+// identifiers in use before the action for audit.
+type CorporateAction struct {
+	ID            string              `json:"id"`
+	PortfolioID   string              `json:"-"`
+	PositionID    string              `json:"position_id,omitempty"`
+	Type          CorporateActionType `json:"type"`
+	ISIN          string              `json:"isin"`
+	EffectiveDate time.Time           `json:"effective_date"`
+	RatioFrom     Decimal             `json:"ratio_from"`
+	RatioTo       Decimal             `json:"ratio_to"`
+	OldSymbol     string              `json:"old_symbol,omitempty"`
+	NewSymbol     string              `json:"new_symbol,omitempty"`
+	NewISIN       string              `json:"new_isin,omitempty"`
+	Note          string              `json:"note,omitempty"`
+	CreatedAt     time.Time           `json:"created_at"`
+}
+
+func NewCorporateAction(actionType CorporateActionType, isin string, effectiveDate time.Time) CorporateAction {
+	return CorporateAction{
+		ID:            uuid.New().String(),
+		Type:          actionType,
+		ISIN:          isin,
+		EffectiveDate: effectiveDate,
+		RatioFrom:     Zero,
+		RatioTo:       Zero,
+		CreatedAt:     time.Now(),
+	}
+}
+
+func (a *CorporateAction) IsValid() bool {
+	if a.ID == "" || a.ISIN == "" || !a.Type.IsValid() || a.EffectiveDate.IsZero() {
+		return false
+	}
+	switch a.Type {
+	case CorporateActionSplit:
+		return a.RatioFrom.Cmp(Zero) > 0 && a.RatioTo.Cmp(a.RatioFrom) > 0
+	case CorporateActionReverseSplit:
+		return a.RatioTo.Cmp(Zero) > 0 && a.RatioTo.Cmp(a.RatioFrom) < 0
+	case CorporateActionSymbolChange:
+		return a.NewSymbol != ""
+	case CorporateActionISINChange:
+		return a.NewISIN != "" && a.NewISIN != a.ISIN
+	}
+	return false
+}
+
+// Factor is the number of units held after a split for every unit held
+// before it.
+func (a *CorporateAction) Factor() (Decimal, error) {
+	factor, err := a.RatioTo.Div(a.RatioFrom)
+	if err != nil {
+		return Zero, fmt.Errorf("failed to calculate split factor: %w", err)
+	}
+	return factor, nil
+}
+
+// ApplyCorporateAction adjusts the position holding the instrument and
+// keeps the action in the portfolio history.
+// Splits scale the quantity, the lots and the current price; a split dated
+// before trades of the position replays it from the ledger instead. Symbol
+// and ISIN changes rename the instrument of the position. Ledger entries keep
+// the identifiers they were recorded with.
+func (p *Portfolio) ApplyCorporateAction(a CorporateAction) (*CorporateAction, error) {
+	if !a.IsValid() {
+		return nil, ErrInvalidCorporateAction
+	}
+	pos, err := p.FindPositionByISIN(a.ISIN)
+	if err != nil {
+		return nil, err
+	}
+	for _, existing := range p.CorporateActions {
+		if existing.PositionID == pos.ID && existing.Type == a.Type && sameDate(existing.EffectiveDate, a.EffectiveDate) {
+			return nil, fmt.Errorf("%w: %s %s on %s", ErrDuplicateCorporateAction, a.Type, a.ISIN, a.EffectiveDate.Format(time.DateOnly))
+		}
+	}
+
+	a.PortfolioID = p.ID
+	a.PositionID = pos.ID
+	a.OldSymbol = pos.Instrument.Symbol
+
+	replay := false
+	saved := *pos
+	saved.Lots = append([]Lot(nil), pos.Lots...)
+
+	switch {
+	case a.Type.IsSplit():
+		factor, err := a.Factor()
+		if err != nil {
+			return nil, err
+		}
+		if replay = p.tradedSince(pos, a.EffectiveDate); replay && !p.replayable(pos) {
+			return nil, fmt.Errorf("%w: %s is dated before trades of a position that cannot be replayed", ErrInvalidCorporateAction, a.EffectiveDate.Format(time.DateOnly))
+		}
+		if err := pos.applySplit(factor); err != nil {
+			return nil, err
+		}
+	case a.Type == CorporateActionSymbolChange:
+		pos.Instrument.Symbol = a.NewSymbol
+	case a.Type == CorporateActionISINChange:
+		if _, err := p.FindPositionByISIN(a.NewISIN); err == nil {
+			return nil, fmt.Errorf("%w: a position in %s already exists", ErrInvalidCorporateAction, a.NewISIN)
+		}
+		pos.Instrument.ISIN = a.NewISIN
+		pos.InstrumentISIN = a.NewISIN
+		if a.NewSymbol != "" {
+			pos.Instrument.Symbol = a.NewSymbol
+		}
+	}
+	pos.LastUpdated = time.Now()
+
+	p.CorporateActions = append(p.CorporateActions, a)
+	// Trades on or after the split are already in post-split units, so only
+	// a replay scales the units held before it
+	if replay {
+		if err := p.rebuildPosition(pos); err != nil {
+			*pos = saved
+			p.CorporateActions = p.CorporateActions[:len(p.CorporateActions)-1]
+			return nil, err
+		}
+	}
+	return &p.CorporateActions[len(p.CorporateActions)-1], nil
+}
+
+// tradedSince reports whether the ledger holds a trade of pos dated on or
+// after date.
+func (p *Portfolio) tradedSince(pos *Position, date time.Time) bool {
+	for i := range p.Transactions {
+		tx := &p.Transactions[i]
+		if tx.PositionID == pos.ID && tx.IsTrade() && !tx.TradeDate.Before(date) {
+			return true
+		}
+	}
+	return false
+}
+
+// applySplit multiplies the units held by factor and divides the unit
+// costs and the current price by it, leaving the cost basis unchanged.
+func (p *Position) applySplit(factor Decimal) error {
+	var err error
+	if p.Quantity, err = p.Quantity.Mul(factor); err != nil {
+		return fmt.Errorf("failed to split quantity: %w", err)
+	}
+	if p.CurrentPrice, err = p.CurrentPrice.Div(factor); err != nil {
+		return fmt.Errorf("failed to split price: %w", err)
+	}
+	for i := range p.Lots {
+		lot := &p.Lots[i]
+		if lot.Quantity, err = lot.Quantity.Mul(factor); err != nil {
+			return fmt.Errorf("failed to split lot quantity: %w", err)
+		}
+		if lot.RemainingQuantity, err = lot.RemainingQuantity.Mul(factor); err != nil {
+			return fmt.Errorf("failed to split lot quantity: %w", err)
+		}
+		if lot.UnitCost, err = lot.UnitCost.Div(factor); err != nil {
+			return fmt.Errorf("failed to split lot unit cost: %w", err)
+		}
+	}
+	return nil
+}
+
+// replayStep is a ledger entry or a split in the order they are replayed.
+type replayStep struct {
+	date  time.Time
+	tx    *Transaction
+	split *CorporateAction
+}
+
+// replaySteps returns the ledger entries in trade date order interleaved
+// with the splits. A split takes effect at the start of its effective date,
+// so trades on that date are already recorded in post-split units.
+func (p *Portfolio) replaySteps() []replayStep {
+	steps := make([]replayStep, 0, len(p.CorporateActions)+len(p.Transactions))
+	for i := range p.CorporateActions {
+		if p.CorporateActions[i].Type.IsSplit() {
+			steps = append(steps, replayStep{date: p.CorporateActions[i].EffectiveDate, split: &p.CorporateActions[i]})
+		}
+	}
+	for i := range p.Transactions {
+		steps = append(steps, replayStep{date: p.Transactions[i].TradeDate, tx: &p.Transactions[i]})
+	}
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].date.Before(steps[j].date)
+	})
+	return steps
+}
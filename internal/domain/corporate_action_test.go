@@ -0,0 +1,201 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newSplit(isin string, effectiveDate time.Time, ratioFrom, ratioTo int64) CorporateAction {
+	a := NewCorporateAction(CorporateActionSplit, isin, effectiveDate)
+	if ratioTo < ratioFrom {
+		a.Type = CorporateActionReverseSplit
+	}
+	a.RatioFrom = NewDecimalFromInt(ratioFrom)
+	a.RatioTo = NewDecimalFromInt(ratioTo)
+	return a
+}
+
+func TestCorporateAction_IsValid(t *testing.T) {
+	date := time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC)
+	reverseAsSplit := newSplit("US001", date, 10, 1)
+	reverseAsSplit.Type = CorporateActionSplit
+	symbolChange := NewCorporateAction(CorporateActionSymbolChange, "US001", date)
+	symbolChange.NewSymbol = "META"
+	isinChange := NewCorporateAction(CorporateActionISINChange, "US001", date)
+	isinChange.NewISIN = "US002"
+	sameISIN := NewCorporateAction(CorporateActionISINChange, "US001", date)
+	sameISIN.NewISIN = "US001"
+
+	testCases := []struct {
+		name     string
+		action   CorporateAction
+		expected bool
+	}{
+		{"split", newSplit("US001", date, 1, 4), true},
+		{"reverse split", newSplit("US001", date, 10, 1), true},
+		{"reverse ratio on split", reverseAsSplit, false},
+		{"zero ratio", newSplit("US001", date, 0, 4), false},
+		{"symbol change", symbolChange, true},
+		{"symbol change without symbol", NewCorporateAction(CorporateActionSymbolChange, "US001", date), false},
+		{"isin change", isinChange, true},
+		{"isin change to same isin", sameISIN, false},
+		{"unknown type", NewCorporateAction("merger", "US001", date), false},
+		{"missing date", newSplit("US001", time.Time{}, 1, 4), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := tc.action.IsValid(); result != tc.expected {
+				t.Errorf("expected IsValid() = %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestApplyCorporateAction_Split(t *testing.T) {
+	p, inst := newDividendPortfolio(t)
+	if err := p.UpdatePositionPrice(p.Positions[0].ID, NewDecimalFromInt(120)); err != nil {
+		t.Fatalf("UpdatePositionPrice failed: %v", err)
+	}
+
+	applied, err := p.ApplyCorporateAction(newSplit(inst.ISIN, time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), 1, 4))
+	if err != nil {
+		t.Fatalf("ApplyCorporateAction failed: %v", err)
+	}
+	if applied.PositionID != p.Positions[0].ID || applied.OldSymbol != "AAPL" {
+		t.Errorf("expected action linked to position with old symbol, got %+v", applied)
+	}
+
+	pos := p.Positions[0]
+	if !pos.Quantity.Equal(NewDecimalFromInt(40)) || !pos.CurrentPrice.Equal(NewDecimalFromInt(30)) {
+		t.Errorf("expected 40 units at 30, got %s at %s", pos.Quantity, pos.CurrentPrice)
+	}
+	if !pos.InvestedAmount.Amount.Equal(NewDecimalFromInt(1000)) {
+		t.Errorf("expected cost basis unchanged at 1000, got %s", pos.InvestedAmount)
+	}
+	lot := pos.Lots[0]
+	if !lot.RemainingQuantity.Equal(NewDecimalFromInt(40)) || !lot.UnitCost.Equal(NewDecimalFromInt(25)) {
+		t.Errorf("expected lot of 40 at 25, got %s at %s", lot.RemainingQuantity, lot.UnitCost)
+	}
+
+	if _, err := p.ApplyCorporateAction(newSplit(inst.ISIN, time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), 1, 4)); !errors.Is(err, ErrDuplicateCorporateAction) {
+		t.Errorf("expected ErrDuplicateCorporateAction, got %v", err)
+	}
+}
+
+func TestApplyCorporateAction_ReverseSplit(t *testing.T) {
+	p, inst := newDividendPortfolio(t)
+
+	if _, err := p.ApplyCorporateAction(newSplit(inst.ISIN, time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), 5, 1)); err != nil {
+		t.Fatalf("ApplyCorporateAction failed: %v", err)
+	}
+	pos := p.Positions[0]
+	if !pos.Quantity.Equal(NewDecimalFromInt(2)) || !pos.Lots[0].UnitCost.Equal(NewDecimalFromInt(500)) {
+		t.Errorf("expected 2 units at 500, got %s at %s", pos.Quantity, pos.Lots[0].UnitCost)
+	}
+}
+
+func TestApplyCorporateAction_SplitBeforeLaterTrades(t *testing.T) {
+	p, inst := newDividendPortfolio(t)
+	buy := NewTransaction(TransactionTypeBuy, inst.ISIN, time.Date(2024, 9, 1, 0, 0, 0, 0, time.UTC),
+		NewDecimalFromInt(10), NewDecimalFromInt(50), NewDecimalFromInt(500), "USD")
+	if _, err := p.RecordTransaction(inst, buy); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	if err := p.UpdatePositionPrice(p.Positions[0].ID, NewDecimalFromInt(120)); err != nil {
+		t.Fatalf("UpdatePositionPrice failed: %v", err)
+	}
+
+	// Only the units bought before the split are doubled
+	if _, err := p.ApplyCorporateAction(newSplit(inst.ISIN, time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), 1, 2)); err != nil {
+		t.Fatalf("ApplyCorporateAction failed: %v", err)
+	}
+	live := p.Positions[0]
+	if !live.Quantity.Equal(NewDecimalFromInt(30)) || !live.CurrentPrice.Equal(NewDecimalFromInt(60)) {
+		t.Errorf("expected 30 units at 60, got %s at %s", live.Quantity, live.CurrentPrice)
+	}
+	if !live.InvestedAmount.Amount.Equal(NewDecimalFromInt(1500)) || len(live.Lots) != 2 {
+		t.Errorf("expected cost basis of 1500 in 2 lots, got %s in %d", live.InvestedAmount, len(live.Lots))
+	}
+
+	if err := p.RebuildPositions(); err != nil {
+		t.Fatalf("RebuildPositions failed: %v", err)
+	}
+	if !p.Positions[0].Quantity.Equal(live.Quantity) {
+		t.Errorf("expected replay to match the live %s units, got %s", live.Quantity, p.Positions[0].Quantity)
+	}
+}
+
+func TestApplyCorporateAction_ISINChange(t *testing.T) {
+	p, inst := newDividendPortfolio(t)
+	action := NewCorporateAction(CorporateActionISINChange, inst.ISIN, time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC))
+	action.NewISIN = "US30303M1027"
+	action.NewSymbol = "META"
+
+	if _, err := p.ApplyCorporateAction(action); err != nil {
+		t.Fatalf("ApplyCorporateAction failed: %v", err)
+	}
+	pos, err := p.FindPositionByISIN("US30303M1027")
+	if err != nil {
+		t.Fatalf("expected position under the new ISIN: %v", err)
+	}
+	if pos.InstrumentISIN != "US30303M1027" || pos.Instrument.Symbol != "META" {
+		t.Errorf("expected instrument renamed, got %s %s", pos.InstrumentISIN, pos.Instrument.Symbol)
+	}
+	if _, err := p.FindPositionByISIN(inst.ISIN); !errors.Is(err, ErrPositionNotFound) {
+		t.Errorf("expected old ISIN to be gone, got %v", err)
+	}
+
+	// The ledger follows the position, not the identifier it was recorded under
+	held, err := p.QuantityHeldAt("US30303M1027", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil || !held.Equal(NewDecimalFromInt(10)) {
+		t.Errorf("expected 10 units held, got %s, %v", held, err)
+	}
+}
+
+func TestApplyCorporateAction_UnknownPosition(t *testing.T) {
+	p, _ := newDividendPortfolio(t)
+
+	if _, err := p.ApplyCorporateAction(newSplit("US5949181045", time.Now(), 1, 2)); !errors.Is(err, ErrPositionNotFound) {
+		t.Errorf("expected ErrPositionNotFound, got %v", err)
+	}
+}
+
+func TestRebuildPositions_ReplaysSplits(t *testing.T) {
+	p, inst := newDividendPortfolio(t)
+	if _, err := p.ApplyCorporateAction(newSplit(inst.ISIN, time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), 1, 2)); err != nil {
+		t.Fatalf("ApplyCorporateAction failed: %v", err)
+	}
+	// Sold after the split, in post-split units
+	sell := NewTransaction(TransactionTypeSell, inst.ISIN, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+		NewDecimalFromInt(15), NewDecimalFromInt(60), NewDecimalFromInt(900), "USD")
+	if _, err := p.RecordTransaction(inst, sell); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+
+	if err := p.RebuildPositions(); err != nil {
+		t.Fatalf("RebuildPositions failed: %v", err)
+	}
+	if !p.Positions[0].Quantity.Equal(NewDecimalFromInt(5)) {
+		t.Errorf("expected 5 units after replay, got %s", p.Positions[0].Quantity)
+	}
+
+	testCases := []struct {
+		date     time.Time
+		expected int64
+	}{
+		{time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC), 10},
+		{time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC), 20},
+		{time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC), 5},
+	}
+	for _, tc := range testCases {
+		held, err := p.QuantityHeldAt(inst.ISIN, tc.date)
+		if err != nil {
+			t.Fatalf("QuantityHeldAt failed: %v", err)
+		}
+		if !held.Equal(NewDecimalFromInt(tc.expected)) {
+			t.Errorf("at %s: expected %d, got %s", tc.date.Format(time.DateOnly), tc.expected, held)
+		}
+	}
+}
@@ -121,6 +121,34 @@ func (d Decimal) Div(other Decimal) (Decimal, error) {
 	return res, nil
 }
 
+// Pow raises d to the power of exp, which may be fractional.
+func (d Decimal) Pow(exp Decimal) (Decimal, error) {
+	res := Decimal{}
+	if _, err := DefaultContext.Pow(&res.Decimal, &d.Decimal, &exp.Decimal); err != nil {
+		return res, fmt.Errorf("pow operation failed: %w", err)
+	}
+	return res, nil
+}
+
+// Sqrt returns the square root of d, which must not be negative.
+func (d Decimal) Sqrt() (Decimal, error) {
+	if d.Negative && !d.IsZero() {
+		return Zero, fmt.Errorf("square root of negative number %s", d.String())
+	}
+	res := Decimal{}
+	if _, err := DefaultContext.Sqrt(&res.Decimal, &d.Decimal); err != nil {
+		return res, fmt.Errorf("sqrt operation failed: %w", err)
+	}
+	return res, nil
+}
+
+// Abs returns the absolute value of d.
+func (d Decimal) Abs() Decimal {
+	res := Decimal{}
+	res.Decimal.Abs(&d.Decimal)
+	return res
+}
+
 func (d Decimal) IsZero() bool {
 	return d.Decimal.IsZero()
 }
@@ -447,6 +447,22 @@ func TestDecimal_Round(t *testing.T) {
 	}
 }
 
+func TestDecimal_Sqrt(t *testing.T) {
+	root, err := NewDecimalFromInt(144).Sqrt()
+	if err != nil {
+		t.Fatalf("Sqrt failed: %v", err)
+	}
+	if !root.Equal(NewDecimalFromInt(12)) {
+		t.Errorf("expected 12, got %s", root)
+	}
+	if root, err := Zero.Sqrt(); err != nil || !root.IsZero() {
+		t.Errorf("expected 0, got %s, %v", root, err)
+	}
+	if _, err := NewDecimalFromInt(-4).Sqrt(); err == nil {
+		t.Error("expected error for a negative number")
+	}
+}
+
 // --- Helper Functions ---
 
 func mustDecimalFromString(s string) Decimal {
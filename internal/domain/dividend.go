@@ -0,0 +1,269 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidDividend   = errors.New("invalid dividend")
+	ErrDuplicateDividend = errors.New("dividend already recorded")
+)
+
+// Dividend sources.
+const (
+	DividendSourceManual   = "manual"
+	DividendSourceProvider = "provider"
+)
+
+// Dividend is a cash distribution paid on a holding.
+// Units held before ExDate are entitled to it; the cash arrives on PayDate.
+// GrossAmount is the total distribution before WithholdingTax, both in the
+// currency the distribution was paid in.
+type Dividend struct {
+	ID             string    `json:"id"`
+	PortfolioID    string    `json:"-"`
+	InstrumentISIN string    `json:"isin"`
+	ExDate         time.Time `json:"ex_date"`
+	PayDate        time.Time `json:"pay_date"`
+	GrossAmount    Money     `json:"gross_amount"`
+	WithholdingTax Money     `json:"withholding_tax"`
+	Source         string    `json:"source"`
+	TransactionID  string    `json:"transaction_id,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+func NewDividend(isin string, exDate, payDate time.Time, gross, withholding Decimal, currency, source string) Dividend {
+	if payDate.IsZero() {
+		payDate = exDate
+	}
+	return Dividend{
+		ID:             uuid.New().String(),
+		InstrumentISIN: isin,
+		ExDate:         exDate,
+		PayDate:        payDate,
+		GrossAmount:    NewMoney(gross, currency),
+		WithholdingTax: NewMoney(withholding, currency),
+		Source:         source,
+		CreatedAt:      time.Now(),
+	}
+}
+
+// Currency is the currency the distribution was paid in.
+func (d *Dividend) Currency() string {
+	return d.GrossAmount.Currency
+}
+
+// NetAmount is the cash received after withholding tax.
+func (d *Dividend) NetAmount() (Money, error) {
+	net, err := d.GrossAmount.Sub(d.WithholdingTax)
+	if err != nil {
+		return Money{}, fmt.Errorf("failed to deduct withholding tax: %w", err)
+	}
+	return net, nil
+}
+
+func (d *Dividend) IsValid() bool {
+	if d.ID == "" || d.InstrumentISIN == "" || d.ExDate.IsZero() || d.PayDate.Before(d.ExDate) {
+		return false
+	}
+	if !IsValidCurrency(d.GrossAmount.Currency) || d.WithholdingTax.Currency != d.GrossAmount.Currency {
+		return false
+	}
+	return d.GrossAmount.Amount.Cmp(Zero) > 0 &&
+		d.WithholdingTax.Amount.Cmp(Zero) >= 0 &&
+		d.WithholdingTax.Amount.Cmp(d.GrossAmount.Amount) <= 0
+}
+
+// HasDividend reports whether a distribution with the same ex-date is
+// already recorded for the instrument.
+func (p *Portfolio) HasDividend(isin string, exDate time.Time) bool {
+	for _, d := range p.Dividends {
+		if d.InstrumentISIN == isin && sameDate(d.ExDate, exDate) {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordDividend books a distribution on a position held in the portfolio
+// and adds the net cash received to the ledger as a dividend entry.
+func (p *Portfolio) RecordDividend(d Dividend) (*Dividend, error) {
+	if !d.IsValid() {
+		return nil, ErrInvalidDividend
+	}
+	pos, err := p.FindPositionByISIN(d.InstrumentISIN)
+	if err != nil {
+		return nil, err
+	}
+	if p.HasDividend(d.InstrumentISIN, d.ExDate) {
+		return nil, fmt.Errorf("%w: %s ex-date %s", ErrDuplicateDividend, d.InstrumentISIN, d.ExDate.Format(time.DateOnly))
+	}
+
+	net, err := d.NetAmount()
+	if err != nil {
+		return nil, err
+	}
+	tx := NewTransaction(TransactionTypeDividend, d.InstrumentISIN, d.PayDate, Zero, Zero, net.Amount, net.Currency)
+	if !net.IsZero() {
+		if _, err := p.RecordTransaction(pos.Instrument, tx); err != nil {
+			return nil, err
+		}
+		d.TransactionID = tx.ID
+	}
+
+	d.PortfolioID = p.ID
+	p.Dividends = append(p.Dividends, d)
+	return &p.Dividends[len(p.Dividends)-1], nil
+}
+
+// QuantityHeldAt returns the units of an instrument held at the start of
+// date, derived from the ledger and the splits effective by then.
+// Positions without ledger entries report their current quantity.
+func (p *Portfolio) QuantityHeldAt(isin string, date time.Time) (Decimal, error) {
+	pos, err := p.FindPositionByISIN(isin)
+	if err != nil {
+		return Zero, err
+	}
+
+	held, traded := Zero, false
+	for _, step := range p.replaySteps() {
+		if step.split != nil {
+			if step.split.PositionID != pos.ID || step.date.After(date) {
+				continue
+			}
+			factor, err := step.split.Factor()
+			if err != nil {
+				return Zero, err
+			}
+			if held, err = held.Mul(factor); err != nil {
+				return Zero, fmt.Errorf("failed to replay split: %w", err)
+			}
+			continue
+		}
+
+		tx := step.tx
+		if tx.PositionID != pos.ID || !tx.IsTrade() {
+			continue
+		}
+		traded = true
+		if !tx.TradeDate.Before(date) {
+			continue
+		}
+		if tx.Type.IncreasesQuantity() {
+			held, err = held.Add(tx.Quantity)
+		} else {
+			held, err = held.Sub(tx.Quantity)
+		}
+		if err != nil {
+			return Zero, fmt.Errorf("failed to replay quantity: %w", err)
+		}
+	}
+	if !traded {
+		return pos.Quantity, nil
+	}
+	return held, nil
+}
+
+// IncomePeriod totals the distributions paid in a month ("2024-03") or a
+// year ("2024").
+type IncomePeriod struct {
+	Period         string  `json:"period"`
+	GrossAmount    Decimal `json:"gross_amount"`
+	WithholdingTax Decimal `json:"withholding_tax"`
+	NetAmount      Decimal `json:"net_amount"`
+	Count          int     `json:"count"`
+}
+
+// IncomeReport aggregates dividend income by pay date in a single currency.
+type IncomeReport struct {
+	Currency string         `json:"currency"`
+	Total    IncomePeriod   `json:"total"`
+	Monthly  []IncomePeriod `json:"monthly"`
+	Yearly   []IncomePeriod `json:"yearly"`
+	FXRates  []FXRate       `json:"fx_rates"`
+}
+
+// Income converts every distribution into the target currency of rates and
+// groups it by the month and year it was paid in.
+func (p *Portfolio) Income(rates *ExchangeRates) (*IncomeReport, error) {
+	report := &IncomeReport{
+		Currency: rates.Target,
+		Total:    newIncomePeriod("total"),
+		FXRates:  rates.Rates(),
+	}
+	monthly := make(map[string]*IncomePeriod)
+	yearly := make(map[string]*IncomePeriod)
+
+	for i := range p.Dividends {
+		d := &p.Dividends[i]
+		gross, err := rates.ConvertMoney(d.GrossAmount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert dividend of %s: %w", d.InstrumentISIN, err)
+		}
+		withholding, err := rates.ConvertMoney(d.WithholdingTax)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert withholding tax of %s: %w", d.InstrumentISIN, err)
+		}
+
+		month, year := d.PayDate.Format("2006-01"), d.PayDate.Format("2006")
+		if monthly[month] == nil {
+			period := newIncomePeriod(month)
+			monthly[month] = &period
+		}
+		if yearly[year] == nil {
+			period := newIncomePeriod(year)
+			yearly[year] = &period
+		}
+		for _, period := range []*IncomePeriod{monthly[month], yearly[year], &report.Total} {
+			if err := period.add(gross.Amount, withholding.Amount); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	report.Monthly = sortedIncomePeriods(monthly)
+	report.Yearly = sortedIncomePeriods(yearly)
+	return report, nil
+}
+
+func newIncomePeriod(period string) IncomePeriod {
+	return IncomePeriod{Period: period, GrossAmount: Zero, WithholdingTax: Zero, NetAmount: Zero}
+}
+
+func (ip *IncomePeriod) add(gross, withholding Decimal) error {
+	var err error
+	if ip.GrossAmount, err = ip.GrossAmount.Add(gross); err != nil {
+		return fmt.Errorf("failed to add gross income: %w", err)
+	}
+	if ip.WithholdingTax, err = ip.WithholdingTax.Add(withholding); err != nil {
+		return fmt.Errorf("failed to add withholding tax: %w", err)
+	}
+	if ip.NetAmount, err = ip.GrossAmount.Sub(ip.WithholdingTax); err != nil {
+		return fmt.Errorf("failed to calculate net income: %w", err)
+	}
+	ip.Count++
+	return nil
+}
+
+func sortedIncomePeriods(periods map[string]*IncomePeriod) []IncomePeriod {
+	result := make([]IncomePeriod, 0, len(periods))
+	for _, period := range periods {
+		result = append(result, *period)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Period < result[j].Period
+	})
+	return result
+}
+
+// sameDate reports whether two timestamps fall on the same calendar day.
+func sameDate(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
@@ -0,0 +1,168 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newDividendPortfolio(t *testing.T) (*Portfolio, Instrument) {
+	t.Helper()
+	p := NewPortfolio("Income")
+	inst := NewInstrument("US0378331005", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+	buy := NewTransaction(TransactionTypeBuy, inst.ISIN, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		NewDecimalFromInt(10), NewDecimalFromInt(100), NewDecimalFromInt(1000), "USD")
+	if _, err := p.RecordTransaction(inst, buy); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	return &p, inst
+}
+
+func TestDividend_IsValid(t *testing.T) {
+	exDate := time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC)
+	payDate := exDate.AddDate(0, 0, 7)
+
+	testCases := []struct {
+		name     string
+		dividend Dividend
+		expected bool
+	}{
+		{"valid", NewDividend("US001", exDate, payDate, NewDecimalFromInt(10), NewDecimalFromInt(1), "USD", DividendSourceManual), true},
+		{"pay date defaults to ex-date", NewDividend("US001", exDate, time.Time{}, NewDecimalFromInt(10), Zero, "USD", DividendSourceManual), true},
+		{"pay date before ex-date", NewDividend("US001", payDate, exDate, NewDecimalFromInt(10), Zero, "USD", DividendSourceManual), false},
+		{"zero gross", NewDividend("US001", exDate, payDate, Zero, Zero, "USD", DividendSourceManual), false},
+		{"withholding above gross", NewDividend("US001", exDate, payDate, NewDecimalFromInt(10), NewDecimalFromInt(11), "USD", DividendSourceManual), false},
+		{"invalid currency", NewDividend("US001", exDate, payDate, NewDecimalFromInt(10), Zero, "usd", DividendSourceManual), false},
+		{"missing ISIN", NewDividend("", exDate, payDate, NewDecimalFromInt(10), Zero, "USD", DividendSourceManual), false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if result := tc.dividend.IsValid(); result != tc.expected {
+				t.Errorf("expected IsValid() = %v, got %v", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestRecordDividend_AddsLedgerEntry(t *testing.T) {
+	p, inst := newDividendPortfolio(t)
+	exDate := time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC)
+	d := NewDividend(inst.ISIN, exDate, exDate.AddDate(0, 0, 7), NewDecimalFromInt(24), NewDecimalFromInt(4), "USD", DividendSourceManual)
+
+	recorded, err := p.RecordDividend(d)
+	if err != nil {
+		t.Fatalf("RecordDividend failed: %v", err)
+	}
+	if recorded.TransactionID == "" {
+		t.Fatal("expected a linked ledger entry")
+	}
+
+	last := p.Transactions[len(p.Transactions)-1]
+	if last.Type != TransactionTypeDividend || !last.Amount.Equal(NewDecimalFromInt(20)) {
+		t.Errorf("expected a 20 USD dividend entry, got %s %s", last.Type, last.Amount)
+	}
+	if !last.TradeDate.Equal(recorded.PayDate) {
+		t.Errorf("expected ledger entry on pay date %s, got %s", recorded.PayDate, last.TradeDate)
+	}
+
+	if _, err := p.RecordDividend(NewDividend(inst.ISIN, exDate, exDate, NewDecimalFromInt(24), Zero, "USD", DividendSourceManual)); !errors.Is(err, ErrDuplicateDividend) {
+		t.Errorf("expected ErrDuplicateDividend, got %v", err)
+	}
+}
+
+func TestRecordDividend_UnknownPosition(t *testing.T) {
+	p, _ := newDividendPortfolio(t)
+	d := NewDividend("US5949181045", time.Now(), time.Now(), NewDecimalFromInt(10), Zero, "USD", DividendSourceManual)
+
+	if _, err := p.RecordDividend(d); !errors.Is(err, ErrPositionNotFound) {
+		t.Errorf("expected ErrPositionNotFound, got %v", err)
+	}
+}
+
+func TestQuantityHeldAt(t *testing.T) {
+	p, inst := newDividendPortfolio(t)
+	sell := NewTransaction(TransactionTypeSell, inst.ISIN, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		NewDecimalFromInt(4), NewDecimalFromInt(110), NewDecimalFromInt(440), "USD")
+	if _, err := p.RecordTransaction(inst, sell); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+
+	testCases := []struct {
+		date     time.Time
+		expected int64
+	}{
+		{time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), 0},
+		{time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC), 10},
+		{time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC), 6},
+	}
+	for _, tc := range testCases {
+		held, err := p.QuantityHeldAt(inst.ISIN, tc.date)
+		if err != nil {
+			t.Fatalf("QuantityHeldAt failed: %v", err)
+		}
+		if !held.Equal(NewDecimalFromInt(tc.expected)) {
+			t.Errorf("at %s: expected %d, got %s", tc.date.Format(time.DateOnly), tc.expected, held)
+		}
+	}
+}
+
+func TestPortfolio_Income(t *testing.T) {
+	p, inst := newDividendPortfolio(t)
+	for _, d := range []Dividend{
+		NewDividend(inst.ISIN, time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC), time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), NewDecimalFromInt(20), NewDecimalFromInt(3), "USD", DividendSourceManual),
+		NewDividend(inst.ISIN, time.Date(2024, 5, 10, 0, 0, 0, 0, time.UTC), time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC), NewDecimalFromInt(20), NewDecimalFromInt(3), "USD", DividendSourceManual),
+		NewDividend(inst.ISIN, time.Date(2025, 2, 7, 0, 0, 0, 0, time.UTC), time.Date(2025, 2, 13, 0, 0, 0, 0, time.UTC), NewDecimalFromInt(10), Zero, "EUR", DividendSourceManual),
+	} {
+		if _, err := p.RecordDividend(d); err != nil {
+			t.Fatalf("RecordDividend failed: %v", err)
+		}
+	}
+
+	rates := NewExchangeRates("USD")
+	if err := rates.Add(FXRate{From: "EUR", To: "USD", Rate: NewDecimalFromInt(2)}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	report, err := p.Income(rates)
+	if err != nil {
+		t.Fatalf("Income failed: %v", err)
+	}
+	if len(report.Monthly) != 3 || len(report.Yearly) != 2 {
+		t.Fatalf("expected 3 months and 2 years, got %d and %d", len(report.Monthly), len(report.Yearly))
+	}
+	if report.Yearly[0].Period != "2024" || !report.Yearly[0].NetAmount.Equal(NewDecimalFromInt(34)) {
+		t.Errorf("expected 34 net in 2024, got %s in %s", report.Yearly[0].NetAmount, report.Yearly[0].Period)
+	}
+	if report.Monthly[2].Period != "2025-02" || !report.Monthly[2].GrossAmount.Equal(NewDecimalFromInt(20)) {
+		t.Errorf("expected 20 gross in 2025-02, got %s in %s", report.Monthly[2].GrossAmount, report.Monthly[2].Period)
+	}
+	if !report.Total.NetAmount.Equal(NewDecimalFromInt(54)) || report.Total.Count != 3 {
+		t.Errorf("expected 54 net over 3 dividends, got %s over %d", report.Total.NetAmount, report.Total.Count)
+	}
+}
+
+func TestValuate_IncludesIncomeInTotalReturn(t *testing.T) {
+	p, inst := newDividendPortfolio(t)
+	exDate := time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC)
+	if _, err := p.RecordDividend(NewDividend(inst.ISIN, exDate, exDate, NewDecimalFromInt(60), NewDecimalFromInt(10), "USD", DividendSourceManual)); err != nil {
+		t.Fatalf("RecordDividend failed: %v", err)
+	}
+	if err := p.UpdatePositionPrice(p.Positions[0].ID, NewDecimalFromInt(110)); err != nil {
+		t.Fatalf("UpdatePositionPrice failed: %v", err)
+	}
+
+	v, err := p.Valuate(NewExchangeRates("USD"))
+	if err != nil {
+		t.Fatalf("Valuate failed: %v", err)
+	}
+	if !v.TotalIncome.Equal(NewDecimalFromInt(50)) {
+		t.Errorf("expected income 50, got %s", v.TotalIncome)
+	}
+	if !v.TotalReturn.Equal(NewDecimalFromInt(150)) {
+		t.Errorf("expected total return 150, got %s", v.TotalReturn)
+	}
+	if !v.TotalReturnPercent.Equal(NewDecimalFromInt(15)) {
+		t.Errorf("expected total return 15%%, got %s", v.TotalReturnPercent)
+	}
+}
@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidHoldings  = errors.New("invalid etf holdings")
+	ErrHoldingsNotFound = errors.New("etf holdings not found")
+)
+
+// maxHoldingsWeight bounds the total weight of an ETF composition. Issuer
+// files round each weight and may list derivatives and cash lines, so the
+// total may slightly exceed 100.
+var maxHoldingsWeight = NewDecimalFromInt(101)
+
+// ETFHolding is a constituent of an ETF as listed by its issuer, with its
+// share of the fund in percent. ISIN is empty when the issuer only lists a
+// ticker; Country and Currency are those of the constituent's market.
+type ETFHolding struct {
+	ISIN       string  `json:"isin,omitempty"`
+	Ticker     string  `json:"ticker,omitempty"`
+	Name       string  `json:"name"`
+	Sector     string  `json:"sector,omitempty"`
+	Country    string  `json:"country,omitempty"`
+	Currency   string  `json:"currency,omitempty"`
+	AssetClass string  `json:"asset_class,omitempty"`
+	Weight     Decimal `json:"weight"`
+}
+
+// ETFComposition holds the constituents of an ETF on AsOf. TotalWeight is
+// the sum of their weights; the remainder up to 100 is left classified as
+// the ETF itself.
+type ETFComposition struct {
+	ETFISIN     string       `json:"etf_isin"`
+	AsOf        time.Time    `json:"as_of"`
+	TotalWeight Decimal      `json:"total_weight"`
+	Holdings    []ETFHolding `json:"holdings"`
+}
+
+// ETFCompositions holds compositions by ETF ISIN.
+type ETFCompositions map[string]*ETFComposition
+
+// NewETFComposition validates the constituents of an ETF. Weights must be
+// between -100 and 100, as short derivative and cash lines can be
+// negative, and add up to more than 0 and at most 101.
+func NewETFComposition(etfISIN string, asOf time.Time, holdings []ETFHolding) (*ETFComposition, error) {
+	etfISIN = strings.ToUpper(strings.TrimSpace(etfISIN))
+	if err := ValidateISIN(etfISIN); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHoldings, err)
+	}
+	if len(holdings) == 0 {
+		return nil, fmt.Errorf("%w: no holdings for %s", ErrInvalidHoldings, etfISIN)
+	}
+
+	hundred := NewDecimalFromInt(100)
+	minusHundred := NewDecimalFromInt(-100)
+	composition := &ETFComposition{
+		ETFISIN:     etfISIN,
+		AsOf:        asOf,
+		TotalWeight: Zero,
+		Holdings:    make([]ETFHolding, 0, len(holdings)),
+	}
+	for _, h := range holdings {
+		h.ISIN = strings.ToUpper(strings.TrimSpace(h.ISIN))
+		if h.ISIN != "" {
+			if err := ValidateISIN(h.ISIN); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidHoldings, err)
+			}
+			if h.ISIN == etfISIN {
+				return nil, fmt.Errorf("%w: %s holds itself", ErrInvalidHoldings, etfISIN)
+			}
+		}
+		if h.Currency != "" {
+			h.Currency, _ = NormalizeCurrency(strings.TrimSpace(h.Currency))
+			if !IsValidCurrency(h.Currency) {
+				return nil, fmt.Errorf("%w: %w: %s", ErrInvalidHoldings, ErrInvalidCurrency, h.Currency)
+			}
+		}
+		if h.Weight.Cmp(minusHundred) < 0 || h.Weight.Cmp(hundred) > 0 {
+			return nil, fmt.Errorf("%w: weight of %s must be between -100 and 100, got %s", ErrInvalidHoldings, h.Name, h.Weight)
+		}
+		total, err := composition.TotalWeight.Add(h.Weight)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add weights: %w", err)
+		}
+		composition.TotalWeight = total
+		composition.Holdings = append(composition.Holdings, h)
+	}
+	if composition.TotalWeight.Cmp(Zero) <= 0 || composition.TotalWeight.Cmp(maxHoldingsWeight) > 0 {
+		return nil, fmt.Errorf("%w: weights of %s add up to %s", ErrInvalidHoldings, etfISIN, composition.TotalWeight)
+	}
+	return composition, nil
+}
+
+// holdingAssetType maps the asset class of an issuer file to the asset
+// types used for exposure.
+func holdingAssetType(assetClass string) string {
+	switch strings.ToLower(strings.TrimSpace(assetClass)) {
+	case "":
+		return ExposureUnclassified
+	case "equity", "equities", "stock", "stocks":
+		return string(InstrumentTypeStock)
+	case "fixed income", "bond", "bonds":
+		return string(InstrumentTypeBond)
+	case "cash", "money market", "cash collateral and margins":
+		return ExposureCash
+	default:
+		return strings.ToLower(strings.TrimSpace(assetClass))
+	}
+}
@@ -0,0 +1,57 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewETFComposition(t *testing.T) {
+	asOf := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	composition, err := NewETFComposition(" ie00b4l5y983 ", asOf, []ETFHolding{
+		{ISIN: "us0378331005", Ticker: "AAPL", Name: "APPLE INC", Currency: "usd", Weight: mustDecimalFromString("4.5")},
+		{Name: "EUR CASH", Currency: "EUR", AssetClass: "Cash", Weight: mustDecimalFromString("0.25")},
+		{Name: "S&P500 EMINI MAR 24", AssetClass: "Futures", Weight: mustDecimalFromString("-0.05")},
+	})
+	if err != nil {
+		t.Fatalf("NewETFComposition failed: %v", err)
+	}
+	if composition.ETFISIN != "IE00B4L5Y983" || !composition.AsOf.Equal(asOf) {
+		t.Errorf("expected IE00B4L5Y983 as of %s, got %s as of %s", asOf, composition.ETFISIN, composition.AsOf)
+	}
+	if !composition.TotalWeight.Equal(mustDecimalFromString("4.7")) {
+		t.Errorf("expected total weight 4.7, got %s", composition.TotalWeight)
+	}
+	if h := composition.Holdings[0]; h.ISIN != "US0378331005" || h.Currency != "USD" {
+		t.Errorf("expected normalized ISIN and currency, got %+v", h)
+	}
+}
+
+func TestNewETFComposition_Invalid(t *testing.T) {
+	asOf := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	holding := func(isin, weight string) ETFHolding {
+		return ETFHolding{ISIN: isin, Name: "HOLDING", Weight: mustDecimalFromString(weight)}
+	}
+	tests := []struct {
+		name     string
+		etfISIN  string
+		holdings []ETFHolding
+	}{
+		{"invalid etf isin", "IE00B4L5Y984", []ETFHolding{holding("US0378331005", "100")}},
+		{"no holdings", "IE00B4L5Y983", nil},
+		{"invalid constituent isin", "IE00B4L5Y983", []ETFHolding{holding("US0378331006", "100")}},
+		{"holds itself", "IE00B4L5Y983", []ETFHolding{holding("IE00B4L5Y983", "100")}},
+		{"invalid currency", "IE00B4L5Y983", []ETFHolding{{Name: "CASH", Currency: "EURO", Weight: mustDecimalFromString("100")}}},
+		{"weight above 100", "IE00B4L5Y983", []ETFHolding{holding("US0378331005", "100.5")}},
+		{"total above 101", "IE00B4L5Y983", []ETFHolding{holding("US0378331005", "60"), holding("CH0038863350", "42")}},
+		{"total not positive", "IE00B4L5Y983", []ETFHolding{holding("", "0")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewETFComposition(tt.etfISIN, asOf, tt.holdings)
+			if !errors.Is(err, ErrInvalidHoldings) {
+				t.Errorf("expected ErrInvalidHoldings, got %v", err)
+			}
+		})
+	}
+}
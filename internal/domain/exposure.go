@@ -0,0 +1,245 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+)
+
+const (
+	// ExposureUnclassified groups holdings whose instrument lacks the
+	// classification.
+	ExposureUnclassified = "unclassified"
+	// ExposureCash groups cash balances, which have no sector, industry or
+	// country, and is their asset type.
+	ExposureCash = "cash"
+)
+
+// ExposureWeight is the value of the holdings sharing a classification, in
+// the report currency, and its share of the portfolio value in percent.
+type ExposureWeight struct {
+	Key    string  `json:"key"`
+	Value  Decimal `json:"value"`
+	Weight Decimal `json:"weight"`
+}
+
+// Exposure breaks the portfolio value, including cash, down by the sector,
+// industry and country of the issuers, the currency of the holdings and
+// their asset type. Each breakdown is ordered by value, largest first, and
+// its weights add up to 100.
+type Exposure struct {
+	Currency   string           `json:"currency"`
+	TotalValue Decimal          `json:"total_value"`
+	Sectors    []ExposureWeight `json:"sectors"`
+	Industries []ExposureWeight `json:"industries"`
+	Countries  []ExposureWeight `json:"countries"`
+	Currencies []ExposureWeight `json:"currencies"`
+	AssetTypes []ExposureWeight `json:"asset_types"`
+}
+
+// exposureSlice is a value classified along every dimension.
+type exposureSlice struct {
+	sector    string
+	industry  string
+	country   string
+	currency  string
+	assetType string
+	value     Decimal
+}
+
+// Exposure values the open positions and cash balances in the target
+// currency of rates and weights them along each dimension. Positions in an
+// ETF with a composition are split across its constituents, and so on for
+// constituents that are ETFs themselves; nil compositions count every
+// position as a whole.
+func (p *Portfolio) Exposure(rates *ExchangeRates, compositions ETFCompositions) (*Exposure, error) {
+	var slices []exposureSlice
+	for i := range p.Positions {
+		pos := &p.Positions[i]
+		if pos.IsClosed() {
+			continue
+		}
+		value, err := pos.CurrentValue()
+		if err != nil {
+			return nil, err
+		}
+		converted, err := rates.ConvertMoney(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert value of %s: %w", pos.Instrument.ISIN, err)
+		}
+		inst := pos.Instrument
+		slice := exposureSlice{
+			sector:    classification(inst.Sector),
+			industry:  classification(inst.Industry),
+			country:   classification(inst.Country),
+			currency:  value.Currency,
+			assetType: classification(string(inst.Type)),
+			value:     converted.Amount,
+		}
+		if slices, err = compositions.lookThrough(slices, inst.ISIN, slice, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	balances, err := p.CashBalances()
+	if err != nil {
+		return nil, err
+	}
+	for _, balance := range balances {
+		converted, err := rates.ConvertMoney(balance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s cash: %w", balance.Currency, err)
+		}
+		slices = append(slices, exposureSlice{
+			sector:    ExposureCash,
+			industry:  ExposureCash,
+			country:   ExposureCash,
+			currency:  balance.Currency,
+			assetType: ExposureCash,
+			value:     converted.Amount,
+		})
+	}
+	return newExposure(rates.Target, slices)
+}
+
+// lookThrough appends slice, split across the constituents of isin when it
+// has a composition. The share the composition leaves unlisted keeps the
+// classification of slice. visited holds the ETFs being split, so that
+// compositions listing each other do not recurse forever.
+func (c ETFCompositions) lookThrough(slices []exposureSlice, isin string, slice exposureSlice, visited map[string]bool) ([]exposureSlice, error) {
+	composition, ok := c[isin]
+	if !ok || visited[isin] {
+		return append(slices, slice), nil
+	}
+	if visited == nil {
+		visited = make(map[string]bool)
+	}
+	visited[isin] = true
+	defer delete(visited, isin)
+
+	hundred := NewDecimalFromInt(100)
+	share := func(weight Decimal) (Decimal, error) {
+		portion, err := slice.value.Mul(weight)
+		if err != nil {
+			return Zero, fmt.Errorf("failed to split value of %s: %w", isin, err)
+		}
+		if portion, err = portion.Div(hundred); err != nil {
+			return Zero, fmt.Errorf("failed to split value of %s: %w", isin, err)
+		}
+		return portion, nil
+	}
+
+	var err error
+	for _, h := range composition.Holdings {
+		constituent := exposureSlice{
+			sector:    classification(h.Sector),
+			industry:  ExposureUnclassified,
+			country:   classification(h.Country),
+			currency:  h.Currency,
+			assetType: holdingAssetType(h.AssetClass),
+		}
+		if constituent.currency == "" {
+			constituent.currency = slice.currency
+		}
+		if constituent.value, err = share(h.Weight); err != nil {
+			return nil, err
+		}
+		if h.ISIN == "" {
+			slices = append(slices, constituent)
+			continue
+		}
+		if slices, err = c.lookThrough(slices, h.ISIN, constituent, visited); err != nil {
+			return nil, err
+		}
+	}
+
+	unlisted, err := hundred.Sub(composition.TotalWeight)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate unlisted weight of %s: %w", isin, err)
+	}
+	if unlisted.IsZero() {
+		return slices, nil
+	}
+	residual := slice
+	if residual.value, err = share(unlisted); err != nil {
+		return nil, err
+	}
+	return append(slices, residual), nil
+}
+
+// newExposure totals the slices and groups them along each dimension.
+func newExposure(currency string, slices []exposureSlice) (*Exposure, error) {
+	total := Zero
+	var err error
+	for _, s := range slices {
+		if total, err = total.Add(s.value); err != nil {
+			return nil, fmt.Errorf("failed to add to total: %w", err)
+		}
+	}
+
+	exposure := &Exposure{Currency: currency, TotalValue: total}
+	dimensions := []struct {
+		target *[]ExposureWeight
+		key    func(exposureSlice) string
+	}{
+		{&exposure.Sectors, func(s exposureSlice) string { return s.sector }},
+		{&exposure.Industries, func(s exposureSlice) string { return s.industry }},
+		{&exposure.Countries, func(s exposureSlice) string { return s.country }},
+		{&exposure.Currencies, func(s exposureSlice) string { return s.currency }},
+		{&exposure.AssetTypes, func(s exposureSlice) string { return s.assetType }},
+	}
+	for _, d := range dimensions {
+		if *d.target, err = exposureWeights(slices, total, d.key); err != nil {
+			return nil, err
+		}
+	}
+	return exposure, nil
+}
+
+// exposureWeights sums the slices by key and weights each sum against
+// total. Weights are zero when the total is.
+func exposureWeights(slices []exposureSlice, total Decimal, key func(exposureSlice) string) ([]ExposureWeight, error) {
+	index := make(map[string]int)
+	weights := make([]ExposureWeight, 0)
+	for _, s := range slices {
+		k := key(s)
+		i, ok := index[k]
+		if !ok {
+			i = len(weights)
+			index[k] = i
+			weights = append(weights, ExposureWeight{Key: k, Value: Zero, Weight: Zero})
+		}
+		sum, err := weights[i].Value.Add(s.value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add %s exposure: %w", k, err)
+		}
+		weights[i].Value = sum
+	}
+
+	for i := range weights {
+		if total.IsZero() {
+			continue
+		}
+		share, err := weights[i].Value.Div(total)
+		if err != nil {
+			return nil, fmt.Errorf("failed to weight %s exposure: %w", weights[i].Key, err)
+		}
+		if weights[i].Weight, err = asPercent(share); err != nil {
+			return nil, err
+		}
+	}
+	sort.SliceStable(weights, func(i, j int) bool {
+		if c := weights[i].Value.Cmp(weights[j].Value); c != 0 {
+			return c > 0
+		}
+		return weights[i].Key < weights[j].Key
+	})
+	return weights, nil
+}
+
+// classification returns the label a holding is grouped under.
+func classification(value string) string {
+	if value == "" {
+		return ExposureUnclassified
+	}
+	return value
+}
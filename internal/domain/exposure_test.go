@@ -0,0 +1,133 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPortfolio_Exposure(t *testing.T) {
+	p, _ := newCashPortfolio(t)
+	apple := NewInstrument("US0378331005", "AAPL", "Apple Inc.", InstrumentTypeStock, "USD", "NASDAQ")
+	apple.Sector = "Technology"
+	apple.Industry = "Consumer Electronics"
+	apple.Country = "US"
+	if _, err := p.RecordTransaction(apple, newBuy(apple.ISIN, 1, 10, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC))); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	rates := NewExchangeRates("EUR")
+	if err := rates.Add(FXRate{From: "USD", To: "EUR", Rate: mustDecimalFromString("0.5")}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	exposure, err := p.Exposure(rates, nil)
+	if err != nil {
+		t.Fatalf("Exposure failed: %v", err)
+	}
+	// 1000 EUR of the ETF, 995 EUR of cash after the fee and 5 EUR of Apple
+	if exposure.Currency != "EUR" || !exposure.TotalValue.Equal(NewDecimalFromInt(2000)) {
+		t.Fatalf("expected 2000 EUR in total, got %s %s", exposure.TotalValue, exposure.Currency)
+	}
+
+	expectWeights(t, "sectors", exposure.Sectors, []string{ExposureUnclassified, ExposureCash, "Technology"}, []string{"50", "49.75", "0.25"})
+	expectWeights(t, "industries", exposure.Industries, []string{ExposureUnclassified, ExposureCash, "Consumer Electronics"}, []string{"50", "49.75", "0.25"})
+	expectWeights(t, "countries", exposure.Countries, []string{ExposureUnclassified, ExposureCash, "US"}, []string{"50", "49.75", "0.25"})
+	expectWeights(t, "currencies", exposure.Currencies, []string{"EUR", "USD"}, []string{"99.75", "0.25"})
+	expectWeights(t, "asset types", exposure.AssetTypes, []string{"etf", ExposureCash, "stock"}, []string{"50", "49.75", "0.25"})
+}
+
+func TestPortfolio_Exposure_LookThrough(t *testing.T) {
+	p, _ := newCashPortfolio(t)
+	apple := NewInstrument("US0378331005", "AAPL", "Apple Inc.", InstrumentTypeStock, "USD", "NASDAQ")
+	apple.Sector = "Technology"
+	apple.Industry = "Consumer Electronics"
+	apple.Country = "US"
+	if _, err := p.RecordTransaction(apple, newBuy(apple.ISIN, 1, 10, time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC))); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	rates := NewExchangeRates("EUR")
+	if err := rates.Add(FXRate{From: "USD", To: "EUR", Rate: mustDecimalFromString("0.5")}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	asOf := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	world, err := NewETFComposition("IE00B4L5Y983", asOf, []ETFHolding{
+		{ISIN: "US0378331005", Name: "APPLE INC", Sector: "Technology", Country: "US", Currency: "USD", AssetClass: "Equity", Weight: mustDecimalFromString("60")},
+		{ISIN: "CH0038863350", Name: "NESTLE SA", Sector: "Consumer Staples", Country: "Switzerland", Currency: "CHF", AssetClass: "Equity", Weight: mustDecimalFromString("30")},
+		{ISIN: "IE00B5BMR087", Name: "ISHARES CORE S&P 500", AssetClass: "Equity", Weight: mustDecimalFromString("5")},
+		{Name: "EUR CASH", Currency: "EUR", AssetClass: "Cash", Weight: mustDecimalFromString("3")},
+	})
+	if err != nil {
+		t.Fatalf("NewETFComposition failed: %v", err)
+	}
+	sp500, err := NewETFComposition("IE00B5BMR087", asOf, []ETFHolding{
+		{ISIN: "US5949181045", Name: "MICROSOFT CORP", Sector: "Technology", Country: "US", Currency: "USD", AssetClass: "Equity", Weight: mustDecimalFromString("100")},
+	})
+	if err != nil {
+		t.Fatalf("NewETFComposition failed: %v", err)
+	}
+
+	exposure, err := p.Exposure(rates, ETFCompositions{world.ETFISIN: world, sp500.ETFISIN: sp500})
+	if err != nil {
+		t.Fatalf("Exposure failed: %v", err)
+	}
+	if !exposure.TotalValue.Equal(NewDecimalFromInt(2000)) {
+		t.Fatalf("expected 2000 EUR in total, got %s", exposure.TotalValue)
+	}
+
+	// The ETF splits into 600 EUR of Apple, 300 of Nestle, 50 of Microsoft
+	// through the S&P 500 fund, 30 of cash and 20 left unlisted
+	expectWeights(t, "sectors", exposure.Sectors, []string{ExposureCash, "Technology", "Consumer Staples", ExposureUnclassified}, []string{"49.75", "32.75", "15", "2.5"})
+	expectWeights(t, "industries", exposure.Industries, []string{ExposureUnclassified, ExposureCash, "Consumer Electronics"}, []string{"50", "49.75", "0.25"})
+	expectWeights(t, "countries", exposure.Countries, []string{ExposureCash, "US", "Switzerland", ExposureUnclassified}, []string{"49.75", "32.75", "15", "2.5"})
+	expectWeights(t, "currencies", exposure.Currencies, []string{"EUR", "USD", "CHF"}, []string{"52.25", "32.75", "15"})
+	expectWeights(t, "asset types", exposure.AssetTypes, []string{ExposureCash, "stock", "etf"}, []string{"51.25", "47.75", "1"})
+}
+
+func TestPortfolio_Exposure_LookThroughCycle(t *testing.T) {
+	p, _ := newCashPortfolio(t)
+	asOf := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	world, err := NewETFComposition("IE00B4L5Y983", asOf, []ETFHolding{
+		{ISIN: "IE00B5BMR087", Name: "ISHARES CORE S&P 500", Weight: mustDecimalFromString("100")},
+	})
+	if err != nil {
+		t.Fatalf("NewETFComposition failed: %v", err)
+	}
+	sp500, err := NewETFComposition("IE00B5BMR087", asOf, []ETFHolding{
+		{ISIN: "IE00B4L5Y983", Name: "ISHARES CORE MSCI WORLD", Sector: "Funds", Weight: mustDecimalFromString("100")},
+	})
+	if err != nil {
+		t.Fatalf("NewETFComposition failed: %v", err)
+	}
+
+	exposure, err := p.Exposure(NewExchangeRates("EUR"), ETFCompositions{world.ETFISIN: world, sp500.ETFISIN: sp500})
+	if err != nil {
+		t.Fatalf("Exposure failed: %v", err)
+	}
+	// The S&P 500 fund lists the world fund, which is not split again
+	if s := exposure.Sectors[0]; s.Key != "Funds" || !s.Value.Equal(NewDecimalFromInt(1000)) {
+		t.Errorf("expected 1000 EUR of Funds, got %+v", s)
+	}
+}
+
+func TestPortfolio_Exposure_Empty(t *testing.T) {
+	p := NewPortfolio("Empty")
+	exposure, err := p.Exposure(NewExchangeRates("EUR"), nil)
+	if err != nil {
+		t.Fatalf("Exposure failed: %v", err)
+	}
+	if !exposure.TotalValue.IsZero() || len(exposure.Sectors) != 0 || exposure.AssetTypes == nil {
+		t.Errorf("expected empty breakdowns, got %+v", exposure)
+	}
+}
+
+func expectWeights(t *testing.T, name string, weights []ExposureWeight, keys, percents []string) {
+	t.Helper()
+	if len(weights) != len(keys) {
+		t.Fatalf("expected %d %s, got %+v", len(keys), name, weights)
+	}
+	for i, w := range weights {
+		if w.Key != keys[i] || !w.Weight.Equal(mustDecimalFromString(percents[i])) {
+			t.Errorf("expected %s %d to be %s at %s%%, got %s at %s%%", name, i, keys[i], percents[i], w.Key, w.Weight)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package domain
+
+import "fmt"
+
+// TradeCharges are the costs of a trade on top of its price: broker fees
+// and commissions, and taxes such as stamp duty. Each is held in the
+// currency it was charged in; an empty currency means the trade currency.
+type TradeCharges struct {
+	Fee Money `json:"fee"`
+	Tax Money `json:"tax"`
+}
+
+// Currencies lists the currencies of the charges that were actually paid.
+func (c TradeCharges) Currencies() []string {
+	currencies := make([]string, 0, 2)
+	for _, charge := range []Money{c.Fee, c.Tax} {
+		if !charge.IsZero() && charge.Currency != "" {
+			currencies = append(currencies, charge.Currency)
+		}
+	}
+	return currencies
+}
+
+// SetCharges attaches the fee and tax of a trade and converts them into
+// the trade currency with rates, whose target must be that currency.
+// The converted total is kept in Costs: it is added to the cost basis of
+// buys and deducted from the proceeds of sells.
+func (t *Transaction) SetCharges(charges TradeCharges, rates *ExchangeRates) error {
+	costs := Zero
+	for _, charge := range []*Money{&charges.Fee, &charges.Tax} {
+		if charge.Currency == "" {
+			charge.Currency = t.Currency
+		}
+		if charge.Amount.Cmp(Zero) < 0 || !IsValidCurrency(charge.Currency) {
+			return fmt.Errorf("%w: charge of %s", ErrInvalidTransaction, charge)
+		}
+		if charge.IsZero() {
+			continue
+		}
+
+		converted, err := rates.ConvertMoney(*charge)
+		if err != nil {
+			return fmt.Errorf("failed to convert charge of %s: %w", charge, err)
+		}
+		// Keep minor-unit trade currencies such as GBX in their minor unit
+		_, divisor := NormalizeCurrency(t.Currency)
+		amount, err := converted.Amount.Mul(divisor)
+		if err != nil {
+			return fmt.Errorf("failed to convert charge of %s: %w", charge, err)
+		}
+		if costs, err = costs.Add(amount); err != nil {
+			return fmt.Errorf("failed to add charges: %w", err)
+		}
+	}
+
+	rounded, err := NewMoney(costs, t.Currency).Round()
+	if err != nil {
+		return fmt.Errorf("failed to round charges: %w", err)
+	}
+	t.Fee = charges.Fee
+	t.Tax = charges.Tax
+	t.Costs = rounded.Amount
+	return nil
+}
+
+// FeeSummary totals the charges paid on trades, converted into a single
+// currency.
+type FeeSummary struct {
+	Currency   string  `json:"currency"`
+	TotalFees  Decimal `json:"total_fees"`
+	TotalTaxes Decimal `json:"total_taxes"`
+	Total      Decimal `json:"total"`
+	Trades     int     `json:"trades"`
+}
+
+// Fees converts the fees and taxes of every ledger entry into the target
+// currency of rates and totals them.
+func (p *Portfolio) Fees(rates *ExchangeRates) (*FeeSummary, error) {
+	var fees, taxes []Money
+	trades := 0
+	for i := range p.Transactions {
+		tx := &p.Transactions[i]
+		if tx.Fee.IsZero() && tx.Tax.IsZero() {
+			continue
+		}
+		trades++
+		if !tx.Fee.IsZero() {
+			fee, err := rates.ConvertMoney(tx.Fee)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert fee of transaction %s: %w", tx.ID, err)
+			}
+			fees = append(fees, fee)
+		}
+		if !tx.Tax.IsZero() {
+			tax, err := rates.ConvertMoney(tx.Tax)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert tax of transaction %s: %w", tx.ID, err)
+			}
+			taxes = append(taxes, tax)
+		}
+	}
+
+	totalFees, err := sumMoney(rates.Target, fees)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add fees: %w", err)
+	}
+	totalTaxes, err := sumMoney(rates.Target, taxes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add taxes: %w", err)
+	}
+	total, err := totalFees.Add(totalTaxes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add charges: %w", err)
+	}
+
+	return &FeeSummary{
+		Currency:   rates.Target,
+		TotalFees:  totalFees.Amount,
+		TotalTaxes: totalTaxes.Amount,
+		Total:      total.Amount,
+		Trades:     trades,
+	}, nil
+}
@@ -0,0 +1,98 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func eurUSDRates(t *testing.T) *ExchangeRates {
+	t.Helper()
+	rates := NewExchangeRates("USD")
+	if err := rates.Add(FXRate{From: "EUR", To: "USD", Rate: NewDecimalFromInt(2)}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	return rates
+}
+
+func TestTransaction_SetCharges(t *testing.T) {
+	tx := NewTransaction(TransactionTypeBuy, "US001", time.Now(), NewDecimalFromInt(10), NewDecimalFromInt(100), NewDecimalFromInt(1000), "USD")
+
+	err := tx.SetCharges(TradeCharges{
+		Fee: NewMoney(NewDecimalFromInt(5), ""),
+		Tax: NewMoney(NewDecimalFromInt(3), "EUR"),
+	}, eurUSDRates(t))
+	if err != nil {
+		t.Fatalf("SetCharges failed: %v", err)
+	}
+	if tx.Fee.Currency != "USD" || tx.Tax.Currency != "EUR" {
+		t.Errorf("expected charges kept in their own currency, got %s and %s", tx.Fee, tx.Tax)
+	}
+	if !tx.Costs.Equal(NewDecimalFromInt(11)) {
+		t.Errorf("expected costs of 11 USD, got %s", tx.Costs)
+	}
+}
+
+func TestTransaction_SetCharges_Invalid(t *testing.T) {
+	tx := NewTransaction(TransactionTypeBuy, "US001", time.Now(), NewDecimalFromInt(10), NewDecimalFromInt(100), NewDecimalFromInt(1000), "USD")
+
+	testCases := []struct {
+		name    string
+		charges TradeCharges
+		err     error
+	}{
+		{"negative fee", TradeCharges{Fee: NewMoney(NewDecimalFromInt(-1), "USD")}, ErrInvalidTransaction},
+		{"invalid currency", TradeCharges{Tax: NewMoney(NewDecimalFromInt(1), "usd")}, ErrInvalidTransaction},
+		{"missing rate", TradeCharges{Fee: NewMoney(NewDecimalFromInt(1), "GBP")}, ErrFXRateNotFound},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tx.SetCharges(tc.charges, eurUSDRates(t)); !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestApplyTransaction_IncludesCharges(t *testing.T) {
+	p := NewPortfolio("Fees")
+	inst := NewInstrument("US001", "TEST", "Test", InstrumentTypeStock, "USD", "NYSE")
+	rates := eurUSDRates(t)
+
+	buy := NewTransaction(TransactionTypeBuy, inst.ISIN, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+		NewDecimalFromInt(10), NewDecimalFromInt(100), NewDecimalFromInt(1000), "USD")
+	if err := buy.SetCharges(TradeCharges{Fee: NewMoney(NewDecimalFromInt(10), "USD")}, rates); err != nil {
+		t.Fatalf("SetCharges failed: %v", err)
+	}
+	pos, err := p.RecordTransaction(inst, buy)
+	if err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	if !pos.InvestedAmount.Amount.Equal(NewDecimalFromInt(1010)) || !pos.Lots[0].UnitCost.Equal(NewDecimalFromInt(101)) {
+		t.Errorf("expected cost basis 1010 at 101 per unit, got %s at %s", pos.InvestedAmount, pos.Lots[0].UnitCost)
+	}
+
+	sell := NewTransaction(TransactionTypeSell, inst.ISIN, time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC),
+		NewDecimalFromInt(5), NewDecimalFromInt(120), NewDecimalFromInt(600), "USD")
+	if err := sell.SetCharges(TradeCharges{Fee: NewMoney(NewDecimalFromInt(5), "USD"), Tax: NewMoney(NewDecimalFromInt(2), "EUR")}, rates); err != nil {
+		t.Fatalf("SetCharges failed: %v", err)
+	}
+	if pos, err = p.RecordTransaction(inst, sell); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	// Proceeds 600 - 9 charges, cost 5 * 101
+	if !pos.RealizedProfitLoss.Amount.Equal(NewDecimalFromInt(86)) {
+		t.Errorf("expected realized profit 86, got %s", pos.RealizedProfitLoss)
+	}
+
+	summary, err := p.Fees(rates)
+	if err != nil {
+		t.Fatalf("Fees failed: %v", err)
+	}
+	if !summary.TotalFees.Equal(NewDecimalFromInt(15)) || !summary.TotalTaxes.Equal(NewDecimalFromInt(4)) || summary.Trades != 2 {
+		t.Errorf("expected 15 fees and 4 taxes over 2 trades, got %s, %s over %d", summary.TotalFees, summary.TotalTaxes, summary.Trades)
+	}
+	if !summary.Total.Equal(NewDecimalFromInt(19)) {
+		t.Errorf("expected 19 total charges, got %s", summary.Total)
+	}
+}
@@ -0,0 +1,175 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	ErrFXRateNotFound  = errors.New("fx rate not found")
+	ErrInvalidCurrency = errors.New("invalid currency")
+)
+
+// DefaultBaseCurrency is used by portfolios that never chose a base currency.
+const DefaultBaseCurrency = "EUR"
+
+// minorUnitQuotes lists quote currencies that are expressed in the minor
+// unit of another currency, such as London listings quoted in pence.
+var minorUnitQuotes = map[string]string{
+	"GBX": "GBP",
+	"GBp": "GBP",
+	"ZAC": "ZAR",
+	"ZAc": "ZAR",
+	"ILA": "ILS",
+}
+
+// NormalizeCurrency maps a quote currency to its ISO-4217 major currency
+// and returns the number of quote units in one major unit.
+// "GBX" and "GBp" (pence) become "GBP" with a divisor of 100.
+func NormalizeCurrency(code string) (string, Decimal) {
+	if major, ok := minorUnitQuotes[code]; ok {
+		return major, NewDecimalFromInt(100)
+	}
+	return strings.ToUpper(code), NewDecimalFromInt(1)
+}
+
+// IsValidCurrency reports whether code looks like an ISO-4217 code.
+func IsValidCurrency(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, c := range code {
+		if c < 'A' || c > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// FXRate is the number of units of To that one unit of From buys.
+// Source names the feed that published the rate.
+type FXRate struct {
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Rate   Decimal   `json:"rate"`
+	AsOf   time.Time `json:"as_of"`
+	Source string    `json:"source,omitempty"`
+}
+
+// ExchangeRates converts amounts in any known currency into Target.
+// Besides the latest rates it may hold the rates in effect on past days,
+// which On selects for valuing amounts of those days.
+type ExchangeRates struct {
+	Target  string
+	rates   map[string]FXRate
+	history map[string][]FXRate
+}
+
+func NewExchangeRates(target string) *ExchangeRates {
+	major, _ := NormalizeCurrency(target)
+	return &ExchangeRates{
+		Target:  major,
+		rates:   make(map[string]FXRate),
+		history: make(map[string][]FXRate),
+	}
+}
+
+// Add registers the rate from a currency into the target currency.
+func (r *ExchangeRates) Add(rate FXRate) error {
+	if !strings.EqualFold(rate.To, r.Target) || rate.Rate.Cmp(Zero) <= 0 {
+		return fmt.Errorf("%w: %s/%s for target %s", ErrFXRateNotFound, rate.From, rate.To, r.Target)
+	}
+	from, _ := NormalizeCurrency(rate.From)
+	r.rates[from] = rate
+	return nil
+}
+
+// AddHistorical registers a rate into the target currency that was in
+// effect from its AsOf day until the next one registered.
+func (r *ExchangeRates) AddHistorical(rate FXRate) error {
+	if !strings.EqualFold(rate.To, r.Target) || rate.Rate.Cmp(Zero) <= 0 || rate.AsOf.IsZero() {
+		return fmt.Errorf("%w: %s/%s for target %s", ErrFXRateNotFound, rate.From, rate.To, r.Target)
+	}
+	from, _ := NormalizeCurrency(rate.From)
+	past := r.history[from]
+	i := sort.Search(len(past), func(i int) bool {
+		return !past[i].AsOf.Before(rate.AsOf)
+	})
+	if i < len(past) && sameDate(past[i].AsOf, rate.AsOf) {
+		past[i] = rate
+		return nil
+	}
+	past = append(past, FXRate{})
+	copy(past[i+1:], past[i:])
+	past[i] = rate
+	r.history[from] = past
+	return nil
+}
+
+// On returns the rates in effect on date: for each currency the last
+// historical rate of that day or before, and the latest rate when none
+// was registered.
+func (r *ExchangeRates) On(date time.Time) *ExchangeRates {
+	if len(r.history) == 0 {
+		return r
+	}
+	on := NewExchangeRates(r.Target)
+	for from, rate := range r.rates {
+		on.rates[from] = rate
+	}
+	for from, past := range r.history {
+		i := sort.Search(len(past), func(i int) bool {
+			return past[i].AsOf.After(date) && !sameDate(past[i].AsOf, date)
+		})
+		if i > 0 {
+			on.rates[from] = past[i-1]
+		}
+	}
+	return on
+}
+
+// Convert expresses an amount quoted in currency in the target currency.
+func (r *ExchangeRates) Convert(amount Decimal, currency string) (Decimal, error) {
+	from, divisor := NormalizeCurrency(currency)
+	major, err := amount.Div(divisor)
+	if err != nil {
+		return Zero, fmt.Errorf("failed to convert minor units: %w", err)
+	}
+	if from == r.Target {
+		return major, nil
+	}
+
+	rate, ok := r.rates[from]
+	if !ok {
+		return Zero, fmt.Errorf("%w: %s/%s", ErrFXRateNotFound, from, r.Target)
+	}
+	converted, err := major.Mul(rate.Rate)
+	if err != nil {
+		return Zero, fmt.Errorf("failed to convert %s to %s: %w", from, r.Target, err)
+	}
+	return converted, nil
+}
+
+// ConvertMoney expresses amount in the target currency.
+func (r *ExchangeRates) ConvertMoney(amount Money) (Money, error) {
+	converted, err := r.Convert(amount.Amount, amount.Currency)
+	if err != nil {
+		return Money{}, err
+	}
+	return NewMoney(converted, r.Target), nil
+}
+
+// Rates returns the registered rates ordered by source currency.
+func (r *ExchangeRates) Rates() []FXRate {
+	result := make([]FXRate, 0, len(r.rates))
+	for _, rate := range r.rates {
+		result = append(result, rate)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].From < result[j].From
+	})
+	return result
+}
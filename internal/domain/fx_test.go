@@ -0,0 +1,186 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newEURRates(t *testing.T) *ExchangeRates {
+	t.Helper()
+	rates := NewExchangeRates("EUR")
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	half, _ := NewDecimalFromString("0.5")
+	for _, r := range []FXRate{
+		{From: "USD", To: "EUR", Rate: half, AsOf: day},
+		{From: "GBP", To: "EUR", Rate: NewDecimalFromInt(2), AsOf: day},
+	} {
+		if err := rates.Add(r); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	return rates
+}
+
+func TestNormalizeCurrency(t *testing.T) {
+	tests := []struct {
+		code        string
+		wantMajor   string
+		wantDivisor int64
+	}{
+		{"USD", "USD", 1},
+		{"eur", "EUR", 1},
+		{"GBP", "GBP", 1},
+		{"GBX", "GBP", 100},
+		{"GBp", "GBP", 100},
+		{"ZAc", "ZAR", 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			major, divisor := NormalizeCurrency(tt.code)
+			if major != tt.wantMajor || !divisor.Equal(NewDecimalFromInt(tt.wantDivisor)) {
+				t.Errorf("NormalizeCurrency(%q) = %s, %s; want %s, %d", tt.code, major, divisor, tt.wantMajor, tt.wantDivisor)
+			}
+		})
+	}
+}
+
+func TestExchangeRates_Convert(t *testing.T) {
+	rates := newEURRates(t)
+
+	tests := []struct {
+		name     string
+		amount   int64
+		currency string
+		want     int64
+	}{
+		{"same currency", 100, "EUR", 100},
+		{"usd", 100, "USD", 50},
+		{"pence", 5000, "GBX", 100},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rates.Convert(NewDecimalFromInt(tt.amount), tt.currency)
+			if err != nil {
+				t.Fatalf("Convert failed: %v", err)
+			}
+			if !got.Equal(NewDecimalFromInt(tt.want)) {
+				t.Errorf("expected %d, got %s", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := rates.Convert(NewDecimalFromInt(1), "JPY"); !errors.Is(err, ErrFXRateNotFound) {
+		t.Errorf("expected ErrFXRateNotFound, got %v", err)
+	}
+}
+
+func TestExchangeRates_AddRejectsOtherTarget(t *testing.T) {
+	rates := NewExchangeRates("EUR")
+
+	err := rates.Add(FXRate{From: "EUR", To: "USD", Rate: NewDecimalFromInt(2)})
+	if !errors.Is(err, ErrFXRateNotFound) {
+		t.Errorf("expected ErrFXRateNotFound, got %v", err)
+	}
+}
+
+func TestExchangeRates_On(t *testing.T) {
+	rates := newEURRates(t)
+	monday := time.Date(2024, 1, 8, 0, 0, 0, 0, time.UTC)
+	for _, r := range []FXRate{
+		{From: "USD", To: "EUR", Rate: NewDecimalFromInt(3), AsOf: monday.AddDate(0, 0, 7)},
+		{From: "USD", To: "EUR", Rate: NewDecimalFromInt(1), AsOf: monday},
+	} {
+		if err := rates.AddHistorical(r); err != nil {
+			t.Fatalf("AddHistorical failed: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		date time.Time
+		want int64
+	}{
+		{"before any historical rate", monday.AddDate(0, 0, -1), 50},
+		{"on the day published", monday, 100},
+		{"until the next one", monday.AddDate(0, 0, 6), 100},
+		{"later day", monday.AddDate(0, 1, 0), 300},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := rates.On(tt.date).Convert(NewDecimalFromInt(100), "USD")
+			if err != nil {
+				t.Fatalf("Convert failed: %v", err)
+			}
+			if !got.Equal(NewDecimalFromInt(tt.want)) {
+				t.Errorf("expected %d, got %s", tt.want, got)
+			}
+		})
+	}
+
+	// Currencies without history keep the latest rate
+	if got, _ := rates.On(monday).Convert(NewDecimalFromInt(1), "GBP"); !got.Equal(NewDecimalFromInt(2)) {
+		t.Errorf("expected the latest GBP rate, got %s", got)
+	}
+}
+
+func TestPortfolio_SetBaseCurrency(t *testing.T) {
+	p := NewPortfolio("FX")
+	if p.Currency() != DefaultBaseCurrency {
+		t.Errorf("expected default base currency %s, got %s", DefaultBaseCurrency, p.Currency())
+	}
+
+	if err := p.SetBaseCurrency("usd"); !errors.Is(err, ErrInvalidCurrency) {
+		t.Errorf("expected ErrInvalidCurrency, got %v", err)
+	}
+	if err := p.SetBaseCurrency("USD"); err != nil {
+		t.Fatalf("SetBaseCurrency failed: %v", err)
+	}
+	if p.Currency() != "USD" {
+		t.Errorf("expected USD, got %s", p.Currency())
+	}
+}
+
+func TestPortfolio_Valuate(t *testing.T) {
+	p := NewPortfolio("FX")
+
+	usd := NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+	_, _ = p.RecordTransaction(usd, newBuy("US001", 10, 100, time.Now()))
+	p.Positions[0].CurrentPrice = NewDecimalFromInt(120)
+
+	// Bought in GBP, quoted in pence
+	lse := NewInstrument("GB001", "VOD", "Vodafone", InstrumentTypeStock, "GBX", "LSE")
+	buy := NewTransaction(TransactionTypeBuy, "GB001", time.Now(), NewDecimalFromInt(100), NewDecimalFromInt(1), NewDecimalFromInt(100), "GBP")
+	_, _ = p.RecordTransaction(lse, buy)
+	p.Positions[1].CurrentPrice = NewDecimalFromInt(150)
+
+	if got := p.Currencies(); len(got) != 2 || got[0] != "GBP" || got[1] != "USD" {
+		t.Errorf("expected currencies [GBP USD], got %v", got)
+	}
+
+	v, err := p.Valuate(newEURRates(t))
+	if err != nil {
+		t.Fatalf("Valuate failed: %v", err)
+	}
+
+	// USD: value 1200 -> 600 EUR, invested 1000 -> 500 EUR
+	// GBP: value 100 * 150p = 150 GBP -> 300 EUR, invested 100 GBP -> 200 EUR
+	if !v.TotalValue.Equal(NewDecimalFromInt(900)) {
+		t.Errorf("expected total value 900, got %s", v.TotalValue)
+	}
+	if !v.TotalInvested.Equal(NewDecimalFromInt(700)) {
+		t.Errorf("expected total invested 700, got %s", v.TotalInvested)
+	}
+	if !v.TotalProfitLoss.Equal(NewDecimalFromInt(200)) {
+		t.Errorf("expected total P/L 200, got %s", v.TotalProfitLoss)
+	}
+	if v.Currency != "EUR" || len(v.FXRates) != 2 {
+		t.Errorf("expected EUR valuation with 2 rates, got %s with %d", v.Currency, len(v.FXRates))
+	}
+
+	if _, err := p.Valuate(NewExchangeRates("EUR")); !errors.Is(err, ErrFXRateNotFound) {
+		t.Errorf("expected ErrFXRateNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,264 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidIdentifier = errors.New("invalid identifier")
+
+// IdentifierType names a scheme for identifying securities.
+type IdentifierType string
+
+const (
+	IdentifierISIN   IdentifierType = "isin"
+	IdentifierCUSIP  IdentifierType = "cusip"
+	IdentifierSEDOL  IdentifierType = "sedol"
+	IdentifierFIGI   IdentifierType = "figi"
+	IdentifierTicker IdentifierType = "ticker"
+)
+
+// Identifier is a parsed and validated security identifier. Exchange is
+// only set for tickers given as exchange:ticker.
+type Identifier struct {
+	Type     IdentifierType `json:"type"`
+	Value    string         `json:"value"`
+	Exchange string         `json:"exchange,omitempty"`
+}
+
+func (id Identifier) String() string {
+	if id.Exchange != "" {
+		return id.Exchange + ":" + id.Value
+	}
+	return id.Value
+}
+
+// isinCountryCodes holds the ISO 3166-1 alpha-2 codes an ISIN may start
+// with, plus the prefixes assigned to international and supranational
+// issuers (XS, EU) and to instruments without a country (XA-XD).
+var isinCountryCodes = func() map[string]bool {
+	codes := "AD AE AF AG AI AL AM AO AQ AR AS AT AU AW AX AZ BA BB BD BE BF BG BH BI BJ BL BM BN BO BQ BR BS BT BV BW " +
+		"BY BZ CA CC CD CF CG CH CI CK CL CM CN CO CR CU CV CW CX CY CZ DE DJ DK DM DO DZ EC EE EG EH ER ES ET FI FJ " +
+		"FK FM FO FR GA GB GD GE GF GG GH GI GL GM GN GP GQ GR GS GT GU GW GY HK HM HN HR HT HU ID IE IL IM IN IO IQ " +
+		"IR IS IT JE JM JO JP KE KG KH KI KM KN KP KR KW KY KZ LA LB LC LI LK LR LS LT LU LV LY MA MC MD ME MF MG MH " +
+		"MK ML MM MN MO MP MQ MR MS MT MU MV MW MX MY MZ NA NC NE NF NG NI NL NO NP NR NU NZ OM PA PE PF PG PH PK PL " +
+		"PM PN PR PS PT PW PY QA RE RO RS RU RW SA SB SC SD SE SG SH SI SJ SK SL SM SN SO SR SS ST SV SX SY SZ TC TD " +
+		"TF TG TH TJ TK TL TM TN TO TR TT TV TW TZ UA UG UM US UY UZ VA VC VE VG VI VN VU WF WS YE YT ZA ZM ZW " +
+		"XS EU XA XB XC XD"
+	result := make(map[string]bool)
+	for _, code := range strings.Fields(codes) {
+		result[code] = true
+	}
+	return result
+}()
+
+// ParseIdentifier recognises an ISIN, CUSIP, SEDOL, FIGI or exchange:ticker
+// and validates its check digit. ISINs are recognised by their country
+// prefix, so a 12-character code with a bad check digit is rejected rather
+// than treated as a ticker.
+func ParseIdentifier(input string) (Identifier, error) {
+	value := strings.ToUpper(strings.TrimSpace(input))
+	if value == "" {
+		return Identifier{}, fmt.Errorf("%w: identifier is empty", ErrInvalidIdentifier)
+	}
+
+	if exchange, ticker, ok := strings.Cut(value, ":"); ok {
+		exchange, ticker = strings.TrimSpace(exchange), strings.TrimSpace(ticker)
+		if exchange == "" || ticker == "" || strings.ContainsAny(ticker, " :") {
+			return Identifier{}, fmt.Errorf("%w: %q is not an exchange:ticker pair", ErrInvalidIdentifier, input)
+		}
+		return Identifier{Type: IdentifierTicker, Value: ticker, Exchange: exchange}, nil
+	}
+
+	var err error
+	var idType IdentifierType
+	switch {
+	case len(value) == 12 && strings.HasPrefix(value, "BBG"):
+		idType, err = IdentifierFIGI, ValidateFIGI(value)
+	case len(value) == 12 && isLetter(value[0]) && isLetter(value[1]):
+		idType, err = IdentifierISIN, ValidateISIN(value)
+	case len(value) == 9:
+		idType, err = IdentifierCUSIP, ValidateCUSIP(value)
+	case len(value) == 7:
+		idType, err = IdentifierSEDOL, ValidateSEDOL(value)
+	default:
+		return Identifier{}, fmt.Errorf("%w: %q is not an ISIN, CUSIP, SEDOL, FIGI or exchange:ticker", ErrInvalidIdentifier, input)
+	}
+	if err != nil {
+		return Identifier{}, err
+	}
+	return Identifier{Type: idType, Value: value}, nil
+}
+
+// ISIN returns the ISIN the identifier maps to without a lookup. CUSIPs
+// map to US ISINs and SEDOLs to GB ISINs; FIGIs and tickers need a market
+// data provider and report false.
+func (id Identifier) ISIN() (string, bool) {
+	var base string
+	switch id.Type {
+	case IdentifierISIN:
+		return id.Value, true
+	case IdentifierCUSIP:
+		base = "US" + id.Value
+	case IdentifierSEDOL:
+		base = "GB00" + id.Value
+	default:
+		return "", false
+	}
+	return base + string(rune('0'+isinCheckDigit(base))), true
+}
+
+// ValidateISIN checks the format, country prefix and check digit of an
+// ISIN.
+func ValidateISIN(isin string) error {
+	if len(isin) != 12 {
+		return fmt.Errorf("%w: ISIN %q must have 12 characters", ErrInvalidIdentifier, isin)
+	}
+	for i := 0; i < 11; i++ {
+		if !isAlphanumeric(isin[i]) || (i < 2 && !isLetter(isin[i])) {
+			return fmt.Errorf("%w: ISIN %q must be two letters followed by nine letters or digits and a check digit", ErrInvalidIdentifier, isin)
+		}
+	}
+	if !isDigit(isin[11]) {
+		return fmt.Errorf("%w: ISIN %q must end in a check digit", ErrInvalidIdentifier, isin)
+	}
+	if !isinCountryCodes[isin[:2]] {
+		return fmt.Errorf("%w: ISIN %q has unknown country prefix %s", ErrInvalidIdentifier, isin, isin[:2])
+	}
+	if expected := isinCheckDigit(isin[:11]); int(isin[11]-'0') != expected {
+		return fmt.Errorf("%w: ISIN %q has check digit %c, expected %d", ErrInvalidIdentifier, isin, isin[11], expected)
+	}
+	return nil
+}
+
+// ValidateCUSIP checks the format and check digit of a CUSIP.
+func ValidateCUSIP(cusip string) error {
+	if len(cusip) != 9 {
+		return fmt.Errorf("%w: CUSIP %q must have 9 characters", ErrInvalidIdentifier, cusip)
+	}
+	sum := 0
+	for i := 0; i < 8; i++ {
+		v, ok := cusipValue(cusip[i])
+		if !ok {
+			return fmt.Errorf("%w: CUSIP %q contains %q", ErrInvalidIdentifier, cusip, cusip[i])
+		}
+		if i%2 == 1 {
+			v *= 2
+		}
+		sum += v/10 + v%10
+	}
+	expected := (10 - sum%10) % 10
+	if !isDigit(cusip[8]) || int(cusip[8]-'0') != expected {
+		return fmt.Errorf("%w: CUSIP %q has check digit %c, expected %d", ErrInvalidIdentifier, cusip, cusip[8], expected)
+	}
+	return nil
+}
+
+// ValidateSEDOL checks the format and check digit of a SEDOL. Vowels are
+// never used.
+func ValidateSEDOL(sedol string) error {
+	if len(sedol) != 7 {
+		return fmt.Errorf("%w: SEDOL %q must have 7 characters", ErrInvalidIdentifier, sedol)
+	}
+	weights := [6]int{1, 3, 1, 7, 3, 9}
+	sum := 0
+	for i := 0; i < 6; i++ {
+		c := sedol[i]
+		if !isAlphanumeric(c) || strings.IndexByte("AEIOU", c) >= 0 {
+			return fmt.Errorf("%w: SEDOL %q contains %q", ErrInvalidIdentifier, sedol, c)
+		}
+		sum += alphanumericValue(c) * weights[i]
+	}
+	expected := (10 - sum%10) % 10
+	if !isDigit(sedol[6]) || int(sedol[6]-'0') != expected {
+		return fmt.Errorf("%w: SEDOL %q has check digit %c, expected %d", ErrInvalidIdentifier, sedol, sedol[6], expected)
+	}
+	return nil
+}
+
+// ValidateFIGI checks the format and check digit of a Financial Instrument
+// Global Identifier: BBG, eight consonants or digits and a check digit
+// calculated like a CUSIP's over the first eleven characters.
+func ValidateFIGI(figi string) error {
+	if len(figi) != 12 || !strings.HasPrefix(figi, "BBG") {
+		return fmt.Errorf("%w: FIGI %q must be BBG followed by nine characters", ErrInvalidIdentifier, figi)
+	}
+	sum := 0
+	for i := 0; i < 11; i++ {
+		c := figi[i]
+		if !isAlphanumeric(c) || (i >= 3 && strings.IndexByte("AEIOU", c) >= 0) {
+			return fmt.Errorf("%w: FIGI %q contains %q", ErrInvalidIdentifier, figi, c)
+		}
+		v := alphanumericValue(c)
+		if i%2 == 1 {
+			v *= 2
+		}
+		sum += v/10 + v%10
+	}
+	expected := (10 - sum%10) % 10
+	if !isDigit(figi[11]) || int(figi[11]-'0') != expected {
+		return fmt.Errorf("%w: FIGI %q has check digit %c, expected %d", ErrInvalidIdentifier, figi, figi[11], expected)
+	}
+	return nil
+}
+
+// isinCheckDigit applies the Luhn algorithm to the digits obtained by
+// replacing each letter with its value (A=10 ... Z=35).
+func isinCheckDigit(base string) int {
+	var digits []int
+	for i := 0; i < len(base); i++ {
+		v := alphanumericValue(base[i])
+		if v >= 10 {
+			digits = append(digits, v/10, v%10)
+		} else {
+			digits = append(digits, v)
+		}
+	}
+
+	sum := 0
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := digits[i]
+		// Double every other digit, starting with the rightmost
+		if (len(digits)-1-i)%2 == 0 {
+			d *= 2
+		}
+		sum += d/10 + d%10
+	}
+	return (10 - sum%10) % 10
+}
+
+// cusipValue returns the value of a CUSIP character: digits as themselves,
+// letters from 10 and the symbols *, @ and # as 36 to 38.
+func cusipValue(c byte) (int, bool) {
+	switch {
+	case isAlphanumeric(c):
+		return alphanumericValue(c), true
+	case c == '*':
+		return 36, true
+	case c == '@':
+		return 37, true
+	case c == '#':
+		return 38, true
+	}
+	return 0, false
+}
+
+func alphanumericValue(c byte) int {
+	if isDigit(c) {
+		return int(c - '0')
+	}
+	return int(c-'A') + 10
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isLetter(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
+
+func isAlphanumeric(c byte) bool {
+	return isDigit(c) || isLetter(c)
+}
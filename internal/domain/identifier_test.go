@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseIdentifier(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected Identifier
+		isin     string
+	}{
+		{" us0378331005 ", Identifier{Type: IdentifierISIN, Value: "US0378331005"}, "US0378331005"},
+		{"XC000A2YY636", Identifier{Type: IdentifierISIN, Value: "XC000A2YY636"}, "XC000A2YY636"},
+		{"037833100", Identifier{Type: IdentifierCUSIP, Value: "037833100"}, "US0378331005"},
+		{"B0YBKJ7", Identifier{Type: IdentifierSEDOL, Value: "B0YBKJ7"}, "GB00B0YBKJ77"},
+		{"BBG000B9XRY4", Identifier{Type: IdentifierFIGI, Value: "BBG000B9XRY4"}, ""},
+		{"nasdaq:aapl", Identifier{Type: IdentifierTicker, Value: "AAPL", Exchange: "NASDAQ"}, ""},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			id, err := ParseIdentifier(tc.input)
+			if err != nil {
+				t.Fatalf("ParseIdentifier failed: %v", err)
+			}
+			if id != tc.expected {
+				t.Errorf("expected %+v, got %+v", tc.expected, id)
+			}
+			isin, ok := id.ISIN()
+			if isin != tc.isin || ok != (tc.isin != "") {
+				t.Errorf("expected ISIN %q, got %q (%v)", tc.isin, isin, ok)
+			}
+		})
+	}
+}
+
+func TestParseIdentifier_Invalid(t *testing.T) {
+	for _, input := range []string{
+		"",
+		"US0378331006", // wrong check digit
+		"QQ0378331005", // unknown country
+		"US03783310X5", // letter in place of the check digit
+		"037833101",    // CUSIP with wrong check digit
+		"B0YBKJ8",      // SEDOL with wrong check digit
+		"B0YAKJ7",      // SEDOL with a vowel
+		"BBG000B9XRY5", // FIGI with wrong check digit
+		"NASDAQ:",
+		"AAPL",
+	} {
+		t.Run(input, func(t *testing.T) {
+			if _, err := ParseIdentifier(input); !errors.Is(err, ErrInvalidIdentifier) {
+				t.Errorf("expected ErrInvalidIdentifier, got %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateISIN(t *testing.T) {
+	for _, isin := range []string{"US0378331005", "US30303M1027", "IE00B4L5Y983", "DE0007164600", "DE0001102580", "GB00B63H8491"} {
+		if err := ValidateISIN(isin); err != nil {
+			t.Errorf("expected %s to be valid, got %v", isin, err)
+		}
+	}
+}
@@ -1,23 +1,64 @@
 package domain
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidInstrument = errors.New("invalid instrument")
+
 type InstrumentType string
 
 const (
-	InstrumentTypeStock InstrumentType = "stock"
-	InstrumentTypeETF   InstrumentType = "etf"
+	InstrumentTypeStock          InstrumentType = "stock"
+	InstrumentTypeETF            InstrumentType = "etf"
+	InstrumentTypeBond           InstrumentType = "bond"
+	InstrumentTypeMutualFund     InstrumentType = "mutual_fund"
+	InstrumentTypeCrypto         InstrumentType = "crypto"
+	InstrumentTypeCashEquivalent InstrumentType = "cash_equivalent"
 )
 
+// CryptoUnitPrecision is the number of decimals crypto quantities are kept
+// to unless the instrument says otherwise, the satoshi of Bitcoin.
+const CryptoUnitPrecision int32 = 8
+
+// maxUnitPrecision bounds the decimals of a quantity to what the decimal
+// context can represent next to the integer part.
+const maxUnitPrecision int32 = 18
+
+func (t InstrumentType) IsValid() bool {
+	switch t {
+	case InstrumentTypeStock, InstrumentTypeETF, InstrumentTypeBond,
+		InstrumentTypeMutualFund, InstrumentTypeCrypto, InstrumentTypeCashEquivalent:
+		return true
+	}
+	return false
+}
+
+// Instrument is a tradable security. CouponRate (yearly, in percent) and
+// MaturityDate only apply to bonds. UnitPrecision is the number of
+// decimals quantities are rounded to, zero meaning unrounded. Sector,
+// Industry and Country classify the issuer as reported by the market data
+// provider or set by hand, and are empty when unknown.
 type Instrument struct {
-	ISIN     string         `json:"isin" gorm:"primaryKey"`
-	Symbol   string         `json:"symbol"`
-	Name     string         `json:"name"`
-	Type     InstrumentType `json:"type"`
-	Currency string         `json:"currency"`
-	Exchange string         `json:"exchange"`
+	ISIN          string         `json:"isin" gorm:"primaryKey"`
+	Symbol        string         `json:"symbol"`
+	Name          string         `json:"name"`
+	Type          InstrumentType `json:"type"`
+	Currency      string         `json:"currency"`
+	Exchange      string         `json:"exchange"`
+	CouponRate    *Decimal       `json:"coupon_rate,omitempty"`
+	MaturityDate  *time.Time     `json:"maturity_date,omitempty"`
+	UnitPrecision int32          `json:"unit_precision,omitempty"`
+	Sector        string         `json:"sector,omitempty"`
+	Industry      string         `json:"industry,omitempty"`
+	Country       string         `json:"country,omitempty"`
 }
 
 func NewInstrument(isin, symbol, name string, instrumentType InstrumentType, currency, exchange string) Instrument {
-	return Instrument{
+	inst := Instrument{
 		ISIN:     isin,
 		Symbol:   symbol,
 		Name:     name,
@@ -25,8 +66,118 @@ func NewInstrument(isin, symbol, name string, instrumentType InstrumentType, cur
 		Currency: currency,
 		Exchange: exchange,
 	}
+	if instrumentType == InstrumentTypeCrypto {
+		inst.UnitPrecision = CryptoUnitPrecision
+	}
+	return inst
 }
 
 func (i Instrument) IsValid() bool {
 	return i.ISIN != "" && i.Symbol != ""
 }
+
+// IsMatured reports whether a bond has reached its maturity date on asOf.
+func (i Instrument) IsMatured(asOf time.Time) bool {
+	return i.Type == InstrumentTypeBond && i.MaturityDate != nil && !asOf.Before(*i.MaturityDate)
+}
+
+// RoundQuantity rounds a quantity to the unit precision of the instrument.
+func (i Instrument) RoundQuantity(quantity Decimal) (Decimal, error) {
+	if i.UnitPrecision <= 0 {
+		return quantity, nil
+	}
+	rounded, err := quantity.Round(i.UnitPrecision)
+	if err != nil {
+		return Zero, fmt.Errorf("failed to round quantity to %d decimals: %w", i.UnitPrecision, err)
+	}
+	return rounded, nil
+}
+
+// InstrumentDetails holds the attributes of an instrument that can be
+// corrected by hand when a provider reports them wrongly or not at all.
+// Nil fields are left unchanged; an empty sector, industry or country
+// clears it.
+type InstrumentDetails struct {
+	Type          *InstrumentType `json:"type"`
+	CouponRate    *Decimal        `json:"coupon_rate"`
+	MaturityDate  *time.Time      `json:"maturity_date"`
+	UnitPrecision *int32          `json:"unit_precision"`
+	Sector        *string         `json:"sector"`
+	Industry      *string         `json:"industry"`
+	Country       *string         `json:"country"`
+}
+
+// Apply validates the details against the instrument and sets them.
+// Coupon and maturity are only kept for bonds and are cleared when the
+// type changes to anything else.
+func (d InstrumentDetails) Apply(i *Instrument) error {
+	updated := *i
+	if d.Type != nil {
+		if !d.Type.IsValid() {
+			return fmt.Errorf("%w: unknown type %q", ErrInvalidInstrument, *d.Type)
+		}
+		if *d.Type == InstrumentTypeCrypto && updated.Type != InstrumentTypeCrypto && d.UnitPrecision == nil {
+			updated.UnitPrecision = CryptoUnitPrecision
+		}
+		updated.Type = *d.Type
+	}
+	if d.CouponRate != nil {
+		if d.CouponRate.Cmp(Zero) < 0 {
+			return fmt.Errorf("%w: coupon rate must not be negative", ErrInvalidInstrument)
+		}
+		coupon := *d.CouponRate
+		updated.CouponRate = &coupon
+	}
+	if d.MaturityDate != nil {
+		maturity := *d.MaturityDate
+		updated.MaturityDate = &maturity
+	}
+	if d.UnitPrecision != nil {
+		if *d.UnitPrecision < 0 || *d.UnitPrecision > maxUnitPrecision {
+			return fmt.Errorf("%w: unit precision must be between 0 and %d", ErrInvalidInstrument, maxUnitPrecision)
+		}
+		updated.UnitPrecision = *d.UnitPrecision
+	}
+	if d.Sector != nil {
+		updated.Sector = strings.TrimSpace(*d.Sector)
+	}
+	if d.Industry != nil {
+		updated.Industry = strings.TrimSpace(*d.Industry)
+	}
+	if d.Country != nil {
+		updated.Country = strings.TrimSpace(*d.Country)
+	}
+
+	if updated.Type != InstrumentTypeBond {
+		if d.CouponRate != nil || d.MaturityDate != nil {
+			return fmt.Errorf("%w: coupon and maturity only apply to bonds", ErrInvalidInstrument)
+		}
+		updated.CouponRate = nil
+		updated.MaturityDate = nil
+	}
+
+	*i = updated
+	return nil
+}
+
+// UpdateInstrument applies details to the instrument of every position
+// holding isin and returns the updated instrument.
+func (p *Portfolio) UpdateInstrument(isin string, details InstrumentDetails) (*Instrument, error) {
+	var updated *Instrument
+	for i := range p.Positions {
+		pos := &p.Positions[i]
+		if pos.Instrument.ISIN != isin {
+			continue
+		}
+		if err := details.Apply(&pos.Instrument); err != nil {
+			return nil, err
+		}
+		updated = &pos.Instrument
+	}
+	if updated == nil {
+		return nil, fmt.Errorf("%w: no position holds %s", ErrPositionNotFound, isin)
+	}
+
+	result := *updated
+	return &result, nil
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestNewInstrument_CryptoPrecision(t *testing.T) {
+	btc := NewInstrument("XC000A2YY636", "BTC-EUR", "Bitcoin", InstrumentTypeCrypto, "EUR", "CCC")
+	if btc.UnitPrecision != CryptoUnitPrecision {
+		t.Fatalf("expected %d decimals for crypto, got %d", CryptoUnitPrecision, btc.UnitPrecision)
+	}
+
+	quantity, _ := NewDecimalFromString("0.123456789")
+	rounded, err := btc.RoundQuantity(quantity)
+	if err != nil {
+		t.Fatalf("RoundQuantity failed: %v", err)
+	}
+	if expected, _ := NewDecimalFromString("0.12345679"); !rounded.Equal(expected) {
+		t.Errorf("expected 0.12345679, got %s", rounded)
+	}
+
+	stock := NewInstrument("US0378331005", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+	if unrounded, _ := stock.RoundQuantity(quantity); !unrounded.Equal(quantity) {
+		t.Errorf("expected stock quantities to stay unrounded, got %s", unrounded)
+	}
+}
+
+func TestInstrumentDetails_Apply(t *testing.T) {
+	bond := InstrumentTypeBond
+	etf := InstrumentTypeETF
+	unknown := InstrumentType("warrant")
+	coupon, _ := NewDecimalFromString("4.25")
+	negative := NewDecimalFromInt(-1)
+	maturity := time.Date(2030, 6, 15, 0, 0, 0, 0, time.UTC)
+
+	inst := NewInstrument("DE0001102580", "DBR", "Bund 2030", InstrumentTypeStock, "EUR", "XETRA")
+	if err := (InstrumentDetails{Type: &bond, CouponRate: &coupon, MaturityDate: &maturity}).Apply(&inst); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if inst.Type != InstrumentTypeBond || !inst.CouponRate.Equal(coupon) || !inst.MaturityDate.Equal(maturity) {
+		t.Errorf("expected a 4.25%% bond maturing %s, got %+v", maturity, inst)
+	}
+	if !inst.IsMatured(maturity) || inst.IsMatured(maturity.AddDate(0, 0, -1)) {
+		t.Error("expected the bond to mature on its maturity date")
+	}
+
+	testCases := []struct {
+		name    string
+		details InstrumentDetails
+	}{
+		{"unknown type", InstrumentDetails{Type: &unknown}},
+		{"negative coupon", InstrumentDetails{CouponRate: &negative}},
+		{"coupon on an etf", InstrumentDetails{Type: &etf, CouponRate: &coupon}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			before := inst
+			if err := tc.details.Apply(&inst); !errors.Is(err, ErrInvalidInstrument) {
+				t.Errorf("expected ErrInvalidInstrument, got %v", err)
+			}
+			if inst.Type != before.Type || inst.CouponRate != before.CouponRate {
+				t.Error("expected the instrument to be left unchanged")
+			}
+		})
+	}
+
+	// Changing the type away from bond clears the bond attributes
+	if err := (InstrumentDetails{Type: &etf}).Apply(&inst); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if inst.CouponRate != nil || inst.MaturityDate != nil {
+		t.Errorf("expected coupon and maturity to be cleared, got %+v", inst)
+	}
+}
+
+func TestInstrumentDetails_Apply_Classification(t *testing.T) {
+	inst := NewInstrument("US0378331005", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+	inst.Sector = "Technology"
+	inst.Industry = "Technology"
+	sector, industry, country := " Information Technology ", "Consumer Electronics", "US"
+
+	if err := (InstrumentDetails{Sector: &sector, Industry: &industry, Country: &country}).Apply(&inst); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if inst.Sector != "Information Technology" || inst.Industry != industry || inst.Country != country {
+		t.Errorf("expected the overrides to be set, got %+v", inst)
+	}
+
+	empty := ""
+	if err := (InstrumentDetails{Industry: &empty}).Apply(&inst); err != nil {
+		t.Fatalf("Apply failed: %v", err)
+	}
+	if inst.Industry != "" || inst.Sector != "Information Technology" {
+		t.Errorf("expected only the industry to be cleared, got %+v", inst)
+	}
+}
+
+func TestPortfolio_UpdateInstrument_NotHeld(t *testing.T) {
+	p := NewPortfolio("Instruments")
+	crypto := InstrumentTypeCrypto
+
+	if _, err := p.UpdateInstrument("XC000A2YY636", InstrumentDetails{Type: &crypto}); !errors.Is(err, ErrPositionNotFound) {
+		t.Errorf("expected ErrPositionNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrLotNotFound             = errors.New("lot not found")
+	ErrInvalidCostBasisMethod  = errors.New("invalid cost basis method")
+	ErrLotSelectionUnsupported = errors.New("lot selection requires specific identification")
+)
+
+// CostBasisMethod decides which lots are consumed when units are sold.
+type CostBasisMethod string
+
+const (
+	CostBasisFIFO     CostBasisMethod = "fifo"
+	CostBasisLIFO     CostBasisMethod = "lifo"
+	CostBasisAverage  CostBasisMethod = "average"
+	CostBasisSpecific CostBasisMethod = "specific"
+)
+
+// DefaultCostBasisMethod is used by portfolios that never chose a policy.
+const DefaultCostBasisMethod = CostBasisAverage
+
+// lotNamespace derives the ID of a lot from the transaction that opened it,
+// so that replaying the ledger recreates the lots under the same IDs.
+var lotNamespace = uuid.MustParse("5b0e1f4c-8d2a-4c57-9a3e-1f6b2d7c8e90")
+
+func (m CostBasisMethod) IsValid() bool {
+	switch m {
+	case CostBasisFIFO, CostBasisLIFO, CostBasisAverage, CostBasisSpecific:
+		return true
+	}
+	return false
+}
+
+// Lot is a tranche of units acquired in a single buy or inbound transfer.
+// RemainingQuantity shrinks as sales consume the lot; UnitCost never changes.
+type Lot struct {
+	ID                string    `json:"id"`
+	PositionID        string    `json:"-"`
+	TransactionID     string    `json:"transaction_id,omitempty"`
+	AcquiredAt        time.Time `json:"acquired_at"`
+	Quantity          Decimal   `json:"quantity"`
+	RemainingQuantity Decimal   `json:"remaining_quantity"`
+	UnitCost          Decimal   `json:"unit_cost"`
+}
+
+func NewLot(transactionID string, acquiredAt time.Time, quantity, cost Decimal) (Lot, error) {
+	unitCost, err := cost.Div(quantity)
+	if err != nil {
+		return Lot{}, fmt.Errorf("failed to calculate unit cost: %w", err)
+	}
+	id := uuid.New().String()
+	if transactionID != "" {
+		id = uuid.NewSHA1(lotNamespace, []byte(transactionID)).String()
+	}
+	return Lot{
+		ID:                id,
+		TransactionID:     transactionID,
+		AcquiredAt:        acquiredAt,
+		Quantity:          quantity,
+		RemainingQuantity: quantity,
+		UnitCost:          unitCost,
+	}, nil
+}
+
+// RemainingCost is the cost basis of the units still held from this lot.
+func (l *Lot) RemainingCost() (Decimal, error) {
+	cost, err := l.RemainingQuantity.Mul(l.UnitCost)
+	if err != nil {
+		return Zero, fmt.Errorf("failed to calculate lot cost: %w", err)
+	}
+	return cost, nil
+}
+
+// consume removes up to quantity units from the lot and returns the units
+// taken and their cost.
+func (l *Lot) consume(quantity Decimal) (Decimal, Decimal, error) {
+	taken := quantity
+	if taken.Cmp(l.RemainingQuantity) > 0 {
+		taken = l.RemainingQuantity
+	}
+	remaining, err := l.RemainingQuantity.Sub(taken)
+	if err != nil {
+		return Zero, Zero, fmt.Errorf("failed to reduce lot: %w", err)
+	}
+	cost, err := taken.Mul(l.UnitCost)
+	if err != nil {
+		return Zero, Zero, fmt.Errorf("failed to calculate cost of lot units: %w", err)
+	}
+	l.RemainingQuantity = remaining
+	return taken, cost, nil
+}
+
+// openLotIndexes returns the indexes of lots with units left, ordered
+// by acquisition date according to the method.
+func openLotIndexes(lots []Lot, method CostBasisMethod) []int {
+	idx := make([]int, 0, len(lots))
+	for i := range lots {
+		if lots[i].RemainingQuantity.Cmp(Zero) > 0 {
+			idx = append(idx, i)
+		}
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		if method == CostBasisLIFO {
+			return lots[idx[a]].AcquiredAt.After(lots[idx[b]].AcquiredAt)
+		}
+		return lots[idx[a]].AcquiredAt.Before(lots[idx[b]].AcquiredAt)
+	})
+	return idx
+}
@@ -0,0 +1,347 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// newTwoLotPortfolio buys 10 @ 100 and then 10 @ 200 of the same instrument.
+func newTwoLotPortfolio(t *testing.T, method CostBasisMethod) (*Portfolio, Instrument) {
+	t.Helper()
+	p := NewPortfolio("Lots")
+	if err := p.SetCostBasisMethod(method); err != nil {
+		t.Fatalf("SetCostBasisMethod failed: %v", err)
+	}
+	inst := NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	if _, err := p.RecordTransaction(inst, newBuy("US001", 10, 100, day)); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	if _, err := p.RecordTransaction(inst, newBuy("US001", 10, 200, day.AddDate(0, 1, 0))); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	return &p, inst
+}
+
+func newSell(quantity, price int64) Transaction {
+	return NewTransaction(TransactionTypeSell, "US001", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		NewDecimalFromInt(quantity), NewDecimalFromInt(price), NewDecimalFromInt(quantity*price), "USD")
+}
+
+func TestSetCostBasisMethod_Invalid(t *testing.T) {
+	p := NewPortfolio("Lots")
+
+	err := p.SetCostBasisMethod("hifo")
+	if !errors.Is(err, ErrInvalidCostBasisMethod) {
+		t.Errorf("expected ErrInvalidCostBasisMethod, got %v", err)
+	}
+	if p.CostBasisMethod != DefaultCostBasisMethod {
+		t.Errorf("expected method to stay %s, got %s", DefaultCostBasisMethod, p.CostBasisMethod)
+	}
+}
+
+func TestRecordTransaction_BuyOpensLot(t *testing.T) {
+	p, _ := newTwoLotPortfolio(t, CostBasisFIFO)
+
+	lots := p.Positions[0].Lots
+	if len(lots) != 2 {
+		t.Fatalf("expected 2 lots, got %d", len(lots))
+	}
+	if !lots[1].UnitCost.Equal(NewDecimalFromInt(200)) {
+		t.Errorf("expected unit cost 200, got %s", lots[1].UnitCost)
+	}
+	if lots[0].TransactionID != p.Transactions[0].ID {
+		t.Error("expected lot to reference its buy transaction")
+	}
+}
+
+func TestSell_CostBasisMethods(t *testing.T) {
+	tests := []struct {
+		method       CostBasisMethod
+		wantRealized int64
+		wantInvested int64
+	}{
+		// Sell 15 @ 250 = 3750 proceeds
+		{CostBasisFIFO, 3750 - (10*100 + 5*200), 5 * 200},
+		{CostBasisLIFO, 3750 - (10*200 + 5*100), 5 * 100},
+		{CostBasisAverage, 3750 - 15*150, 5 * 150},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.method), func(t *testing.T) {
+			p, inst := newTwoLotPortfolio(t, tt.method)
+
+			pos, err := p.RecordTransaction(inst, newSell(15, 250))
+			if err != nil {
+				t.Fatalf("RecordTransaction failed: %v", err)
+			}
+
+			if !pos.RealizedProfitLoss.Amount.Equal(NewDecimalFromInt(tt.wantRealized)) {
+				t.Errorf("expected realized %d, got %s", tt.wantRealized, pos.RealizedProfitLoss)
+			}
+			if !pos.InvestedAmount.Amount.Equal(NewDecimalFromInt(tt.wantInvested)) {
+				t.Errorf("expected invested %d, got %s", tt.wantInvested, pos.InvestedAmount)
+			}
+
+			remaining := Zero
+			for _, lot := range pos.Lots {
+				remaining, _ = remaining.Add(lot.RemainingQuantity)
+			}
+			if !remaining.Equal(pos.Quantity) {
+				t.Errorf("expected lots to hold %s units, got %s", pos.Quantity, remaining)
+			}
+		})
+	}
+}
+
+func TestSell_SpecificLot(t *testing.T) {
+	p, inst := newTwoLotPortfolio(t, CostBasisSpecific)
+	lotID := p.Positions[0].Lots[1].ID
+
+	sell := newSell(4, 250)
+	sell.LotID = lotID
+	pos, err := p.RecordTransaction(inst, sell)
+	if err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+
+	if !pos.RealizedProfitLoss.Amount.Equal(NewDecimalFromInt(4 * 50)) {
+		t.Errorf("expected realized 200, got %s", pos.RealizedProfitLoss)
+	}
+	if !pos.Lots[1].RemainingQuantity.Equal(NewDecimalFromInt(6)) {
+		t.Errorf("expected 6 units left in lot, got %s", pos.Lots[1].RemainingQuantity)
+	}
+	if !pos.Lots[0].RemainingQuantity.Equal(NewDecimalFromInt(10)) {
+		t.Errorf("expected first lot untouched, got %s", pos.Lots[0].RemainingQuantity)
+	}
+}
+
+func TestSell_SpecificLotErrors(t *testing.T) {
+	p, inst := newTwoLotPortfolio(t, CostBasisSpecific)
+
+	missing := newSell(1, 250)
+	missing.LotID = "unknown"
+	if _, err := p.RecordTransaction(inst, missing); !errors.Is(err, ErrLotNotFound) {
+		t.Errorf("expected ErrLotNotFound, got %v", err)
+	}
+
+	unselected := newSell(1, 250)
+	if _, err := p.RecordTransaction(inst, unselected); !errors.Is(err, ErrInvalidTransaction) {
+		t.Errorf("expected ErrInvalidTransaction without a lot, got %v", err)
+	}
+
+	tooMany := newSell(11, 250)
+	tooMany.LotID = p.Positions[0].Lots[0].ID
+	if _, err := p.RecordTransaction(inst, tooMany); !errors.Is(err, ErrInsufficientQuantity) {
+		t.Errorf("expected ErrInsufficientQuantity, got %v", err)
+	}
+}
+
+func TestSell_LotSelectionRequiresSpecificMethod(t *testing.T) {
+	p, inst := newTwoLotPortfolio(t, CostBasisFIFO)
+
+	sell := newSell(1, 250)
+	sell.LotID = p.Positions[0].Lots[1].ID
+	if _, err := p.RecordTransaction(inst, sell); !errors.Is(err, ErrLotSelectionUnsupported) {
+		t.Errorf("expected ErrLotSelectionUnsupported, got %v", err)
+	}
+}
+
+func TestSell_UntrackedUnitsGetOpeningLot(t *testing.T) {
+	p := NewPortfolio("Legacy")
+	_ = p.SetCostBasisMethod(CostBasisFIFO)
+	inst := NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+
+	// Position opened from an invested amount before lots existed: 10 units at 100
+	legacy := NewPosition(inst, NewDecimalFromInt(1000), "USD")
+	_ = legacy.UpdatePrice(NewDecimalFromInt(100))
+	_ = p.AddPosition(legacy)
+
+	pos, err := p.RecordTransaction(inst, newSell(10, 120))
+	if err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+
+	if len(pos.Lots) != 1 {
+		t.Fatalf("expected 1 opening lot, got %d", len(pos.Lots))
+	}
+	if !pos.RealizedProfitLoss.Amount.Equal(NewDecimalFromInt(200)) {
+		t.Errorf("expected realized 200, got %s", pos.RealizedProfitLoss)
+	}
+	if !pos.Quantity.IsZero() || !pos.InvestedAmount.IsZero() {
+		t.Errorf("expected closed position, got quantity %s invested %s", pos.Quantity, pos.InvestedAmount)
+	}
+}
+
+func TestProfitLoss_RealizedAndUnrealized(t *testing.T) {
+	p, inst := newTwoLotPortfolio(t, CostBasisFIFO)
+
+	pos, _ := p.RecordTransaction(inst, newSell(10, 150))
+	pos.CurrentPrice = NewDecimalFromInt(250)
+
+	unrealized, err := pos.UnrealizedProfitLoss()
+	if err != nil {
+		t.Fatalf("UnrealizedProfitLoss failed: %v", err)
+	}
+	// Remaining 10 units cost 200 each, now worth 250
+	if !unrealized.Amount.Equal(NewDecimalFromInt(500)) {
+		t.Errorf("expected unrealized 500, got %s", unrealized)
+	}
+
+	total, err := pos.ProfitLoss()
+	if err != nil {
+		t.Fatalf("ProfitLoss failed: %v", err)
+	}
+	if !total.Amount.Equal(NewDecimalFromInt(1000)) {
+		t.Errorf("expected total P/L 1000, got %s", total)
+	}
+
+	realized, err := p.TotalRealizedProfitLoss()
+	if err != nil {
+		t.Fatalf("TotalRealizedProfitLoss failed: %v", err)
+	}
+	if !realized.Amount.Equal(NewDecimalFromInt(500)) {
+		t.Errorf("expected portfolio realized 500, got %s", realized)
+	}
+
+	portfolioUnrealized, err := p.TotalUnrealizedProfitLoss()
+	if err != nil {
+		t.Fatalf("TotalUnrealizedProfitLoss failed: %v", err)
+	}
+	if !portfolioUnrealized.Amount.Equal(NewDecimalFromInt(500)) {
+		t.Errorf("expected portfolio unrealized 500, got %s", portfolioUnrealized)
+	}
+}
+
+func TestRebuildPositions_RestoresLots(t *testing.T) {
+	p, inst := newTwoLotPortfolio(t, CostBasisFIFO)
+	_, _ = p.RecordTransaction(inst, newSell(15, 250))
+
+	p.Positions[0].Lots = nil
+	p.Positions[0].RealizedProfitLoss = ZeroMoney("USD")
+
+	if err := p.RebuildPositions(); err != nil {
+		t.Fatalf("RebuildPositions failed: %v", err)
+	}
+
+	if len(p.Positions[0].Lots) != 2 {
+		t.Errorf("expected 2 lots, got %d", len(p.Positions[0].Lots))
+	}
+	if !p.Positions[0].RealizedProfitLoss.Amount.Equal(NewDecimalFromInt(1750)) {
+		t.Errorf("expected realized 1750, got %s", p.Positions[0].RealizedProfitLoss)
+	}
+}
+
+func TestRebuildPositions_KeepsSaleMethodAndLotIDs(t *testing.T) {
+	p, inst := newTwoLotPortfolio(t, CostBasisFIFO)
+	lotIDs := []string{p.Positions[0].Lots[0].ID, p.Positions[0].Lots[1].ID}
+	// FIFO sells from the lot bought at 100
+	if _, err := p.RecordTransaction(inst, newSell(5, 250)); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	if p.Transactions[2].CostBasisMethod != CostBasisFIFO {
+		t.Errorf("expected the sale to keep its method, got %q", p.Transactions[2].CostBasisMethod)
+	}
+
+	if err := p.SetCostBasisMethod(CostBasisSpecific); err != nil {
+		t.Fatalf("SetCostBasisMethod failed: %v", err)
+	}
+	if err := p.RebuildPositions(); err != nil {
+		t.Fatalf("RebuildPositions failed: %v", err)
+	}
+	pos := &p.Positions[0]
+	if !pos.RealizedProfitLoss.Amount.Equal(NewDecimalFromInt(750)) {
+		t.Errorf("expected the replayed sale to stay FIFO with realized 750, got %s", pos.RealizedProfitLoss)
+	}
+	if pos.Lots[0].ID != lotIDs[0] || pos.Lots[1].ID != lotIDs[1] {
+		t.Fatalf("expected the replay to keep lot IDs %v, got %s and %s", lotIDs, pos.Lots[0].ID, pos.Lots[1].ID)
+	}
+
+	// Later sales can still select the lots by their recorded IDs
+	sell := newSell(5, 250)
+	sell.TradeDate = sell.TradeDate.AddDate(0, 1, 0)
+	sell.LotID = lotIDs[1]
+	if _, err := p.RecordTransaction(inst, sell); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	if !pos.Lots[1].RemainingQuantity.Equal(NewDecimalFromInt(5)) {
+		t.Errorf("expected 5 units left in the selected lot, got %s", pos.Lots[1].RemainingQuantity)
+	}
+}
+
+func TestSell_FullSellClosesPosition(t *testing.T) {
+	p, inst := newTwoLotPortfolio(t, CostBasisFIFO)
+
+	pos, err := p.RecordTransaction(inst, newSell(20, 250))
+	if err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+
+	if !pos.IsClosed() || pos.ClosedAt == nil {
+		t.Fatal("expected position to be closed")
+	}
+	if !pos.ClosedAt.Equal(time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected closed at sell date, got %v", pos.ClosedAt)
+	}
+	if len(p.Positions) != 1 {
+		t.Errorf("expected closed position to be kept, got %d positions", len(p.Positions))
+	}
+
+	// 20 units bought for 3000 and sold for 5000
+	percent, err := pos.ProfitLossPercent()
+	if err != nil {
+		t.Fatalf("ProfitLossPercent failed: %v", err)
+	}
+	if rounded, _ := percent.Round(2); rounded.String() != "66.67" {
+		t.Errorf("expected the realized return 66.67%% of the closed position, got %s", percent)
+	}
+
+	pos, err = p.RecordTransaction(inst, newBuy("US001", 1, 300, time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	if pos.IsClosed() || pos.ClosedAt != nil {
+		t.Error("expected buy to reopen the position")
+	}
+}
+
+func TestRecordTransaction_OpenedAt(t *testing.T) {
+	p, inst := newTwoLotPortfolio(t, CostBasisFIFO)
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	pos := &p.Positions[0]
+	if pos.OpenedAt == nil || !pos.OpenedAt.Equal(first) {
+		t.Fatalf("expected opened at the first buy, got %v", pos.OpenedAt)
+	}
+
+	// An older purchase entered later moves the opening back
+	older := time.Date(2023, 3, 15, 0, 0, 0, 0, time.UTC)
+	if _, err := p.RecordTransaction(inst, newBuy("US001", 1, 90, older)); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	if !pos.OpenedAt.Equal(older) {
+		t.Errorf("expected opened at %s, got %v", older, pos.OpenedAt)
+	}
+
+	// Buying after selling out reopens the position
+	if _, err := p.RecordTransaction(inst, newSell(21, 250)); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	reopened := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	if _, err := p.RecordTransaction(inst, newBuy("US001", 1, 300, reopened)); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	if !pos.OpenedAt.Equal(reopened) {
+		t.Errorf("expected reopened at %s, got %v", reopened, pos.OpenedAt)
+	}
+
+	pos.OpenedAt = nil
+	if err := p.RebuildPositions(); err != nil {
+		t.Fatalf("RebuildPositions failed: %v", err)
+	}
+	if pos.OpenedAt == nil || !pos.OpenedAt.Equal(reopened) {
+		t.Errorf("expected the replay to restore %s, got %v", reopened, pos.OpenedAt)
+	}
+}
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var ErrCurrencyMismatch = errors.New("currency mismatch")
+
+// minorUnits lists the ISO-4217 currencies whose minor unit is not a
+// hundredth. Every other currency rounds to two decimal places.
+var minorUnits = map[string]int32{
+	"BHD": 3,
+	"CLP": 0,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+	"VND": 0,
+}
+
+// MinorUnits returns the number of decimal places of a currency.
+func MinorUnits(currency string) int32 {
+	if places, ok := minorUnits[currency]; ok {
+		return places
+	}
+	return 2
+}
+
+// Money is an amount in a single currency.
+// Arithmetic between two amounts is only allowed when they share a currency,
+// so that mixing currencies always requires an explicit conversion.
+//
+// Money implements driver.Valuer and sql.Scanner for its amount only; the
+// currency lives in a sibling column and is set by the repository.
+type Money struct {
+	Amount   Decimal `json:"amount"`
+	Currency string  `json:"currency"`
+}
+
+// NewMoney returns amount in currency.
+func NewMoney(amount Decimal, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// ZeroMoney returns a zero amount in currency.
+func ZeroMoney(currency string) Money {
+	return Money{Amount: Zero, Currency: currency}
+}
+
+func (m Money) String() string {
+	return m.Amount.String() + " " + m.Currency
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency != other.Currency {
+		return fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, other.Currency)
+	}
+	return nil
+}
+
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	sum, err := m.Amount.Add(other.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: sum, Currency: m.Currency}, nil
+}
+
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	diff, err := m.Amount.Sub(other.Amount)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: diff, Currency: m.Currency}, nil
+}
+
+// Mul scales the amount by factor and rounds to the minor unit.
+func (m Money) Mul(factor Decimal) (Money, error) {
+	product, err := m.Amount.Mul(factor)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: product, Currency: m.Currency}.Round()
+}
+
+// Div divides the amount by divisor and rounds to the minor unit.
+func (m Money) Div(divisor Decimal) (Money, error) {
+	quotient, err := m.Amount.Div(divisor)
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: quotient, Currency: m.Currency}.Round()
+}
+
+// Ratio returns m divided by other, which must share its currency.
+func (m Money) Ratio(other Money) (Decimal, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Zero, err
+	}
+	return m.Amount.Div(other.Amount)
+}
+
+// Round rounds the amount to the minor unit of its currency.
+func (m Money) Round() (Money, error) {
+	rounded, err := m.Amount.Round(MinorUnits(m.Currency))
+	if err != nil {
+		return Money{}, err
+	}
+	return Money{Amount: rounded, Currency: m.Currency}, nil
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount.IsZero()
+}
+
+// Equal reports whether both amount and currency match.
+func (m Money) Equal(other Money) bool {
+	return m.Currency == other.Currency && m.Amount.Equal(other.Amount)
+}
+
+// Value implements the driver.Valuer interface for database serialization.
+func (m Money) Value() (driver.Value, error) {
+	return m.Amount.Value()
+}
+
+// Scan implements the sql.Scanner interface for database deserialization.
+func (m *Money) Scan(value interface{}) error {
+	return m.Amount.Scan(value)
+}
+
+// UnmarshalJSON accepts the {"amount","currency"} object as well as a bare
+// number, which leaves the currency to be filled in by the caller.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '{' {
+		type plain Money
+		var p plain
+		if err := json.Unmarshal(data, &p); err != nil {
+			return err
+		}
+		*m = Money(p)
+		return nil
+	}
+	return m.Amount.UnmarshalJSON(data)
+}
+
+// sumMoney adds amounts that must share a currency. An empty list sums to
+// zero in fallback.
+func sumMoney(fallback string, amounts []Money) (Money, error) {
+	if len(amounts) == 0 {
+		return ZeroMoney(fallback), nil
+	}
+	total := amounts[0]
+	for _, amount := range amounts[1:] {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return Money{}, err
+		}
+	}
+	return total, nil
+}
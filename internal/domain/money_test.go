@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func mustMoney(t *testing.T, amount, currency string) Money {
+	t.Helper()
+	d, err := NewDecimalFromString(amount)
+	if err != nil {
+		t.Fatalf("invalid amount %s: %v", amount, err)
+	}
+	return NewMoney(d, currency)
+}
+
+func TestMoney_AddSub(t *testing.T) {
+	a := mustMoney(t, "100.25", "USD")
+	b := mustMoney(t, "50.10", "USD")
+
+	sum, err := a.Add(b)
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if !sum.Equal(mustMoney(t, "150.35", "USD")) {
+		t.Errorf("expected 150.35 USD, got %s", sum)
+	}
+
+	diff, err := a.Sub(b)
+	if err != nil {
+		t.Fatalf("Sub failed: %v", err)
+	}
+	if !diff.Equal(mustMoney(t, "50.15", "USD")) {
+		t.Errorf("expected 50.15 USD, got %s", diff)
+	}
+}
+
+func TestMoney_CurrencyMismatch(t *testing.T) {
+	usd := mustMoney(t, "100", "USD")
+	eur := mustMoney(t, "100", "EUR")
+
+	if _, err := usd.Add(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Add: expected ErrCurrencyMismatch, got %v", err)
+	}
+	if _, err := usd.Sub(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Sub: expected ErrCurrencyMismatch, got %v", err)
+	}
+	if _, err := usd.Ratio(eur); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("Ratio: expected ErrCurrencyMismatch, got %v", err)
+	}
+}
+
+func TestMoney_MulDivRoundToMinorUnits(t *testing.T) {
+	testCases := []struct {
+		name     string
+		money    Money
+		divisor  int64
+		expected string
+	}{
+		{"two decimals", mustMoney(t, "100", "USD"), 3, "33.33"},
+		{"zero decimals", mustMoney(t, "1000", "JPY"), 3, "333"},
+		{"three decimals", mustMoney(t, "10", "KWD"), 3, "3.333"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := tc.money.Div(NewDecimalFromInt(tc.divisor))
+			if err != nil {
+				t.Fatalf("Div failed: %v", err)
+			}
+			if !result.Equal(mustMoney(t, tc.expected, tc.money.Currency)) {
+				t.Errorf("expected %s, got %s", tc.expected, result)
+			}
+		})
+	}
+
+	product, err := mustMoney(t, "10.005", "EUR").Mul(NewDecimalFromInt(1))
+	if err != nil {
+		t.Fatalf("Mul failed: %v", err)
+	}
+	if !product.Equal(mustMoney(t, "10.01", "EUR")) {
+		t.Errorf("expected 10.01 EUR, got %s", product)
+	}
+
+	if _, err := mustMoney(t, "10", "EUR").Div(Zero); err == nil {
+		t.Error("expected error dividing by zero")
+	}
+}
+
+func TestMoney_JSON(t *testing.T) {
+	data, err := json.Marshal(mustMoney(t, "12.50", "GBP"))
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(data) != `{"amount":12.50,"currency":"GBP"}` {
+		t.Errorf("unexpected JSON %s", data)
+	}
+
+	var decoded Money
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !decoded.Equal(mustMoney(t, "12.50", "GBP")) {
+		t.Errorf("expected 12.50 GBP, got %s", decoded)
+	}
+}
+
+func TestMoney_SQL(t *testing.T) {
+	value, err := mustMoney(t, "99.95", "USD").Value()
+	if err != nil || value != "99.95" {
+		t.Errorf("expected 99.95, got %v, %v", value, err)
+	}
+
+	m := ZeroMoney("USD")
+	if err := m.Scan([]byte("12.34")); err != nil {
+		t.Fatalf("Scan failed: %v", err)
+	}
+	if !m.Equal(mustMoney(t, "12.34", "USD")) {
+		t.Errorf("expected 12.34 USD, got %s", m)
+	}
+}
+
+func TestPortfolio_TotalValue_MixedCurrencies(t *testing.T) {
+	p := NewPortfolio("Mixed")
+	usd := NewPosition(NewInstrument("US0378331005", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ"), NewDecimalFromInt(1000), "USD")
+	eur := NewPosition(NewInstrument("DE0007164600", "SAP", "SAP", InstrumentTypeStock, "EUR", "XETRA"), NewDecimalFromInt(1000), "EUR")
+	_ = usd.UpdatePrice(NewDecimalFromInt(100))
+	_ = eur.UpdatePrice(NewDecimalFromInt(100))
+	_ = p.AddPosition(usd)
+	_ = p.AddPosition(eur)
+
+	if _, err := p.TotalValue(); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+	if _, err := p.TotalInvested(); !errors.Is(err, ErrCurrencyMismatch) {
+		t.Errorf("expected ErrCurrencyMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,525 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidPeriod       = errors.New("invalid performance period")
+	ErrInsufficientHistory = errors.New("insufficient history")
+)
+
+// PerformancePeriod is the window a return is measured over, ending at the
+// valuation date.
+type PerformancePeriod string
+
+const (
+	PeriodOneMonth       PerformancePeriod = "1M"
+	PeriodThreeMonths    PerformancePeriod = "3M"
+	PeriodYearToDate     PerformancePeriod = "YTD"
+	PeriodOneYear        PerformancePeriod = "1Y"
+	PeriodSinceInception PerformancePeriod = "ALL"
+)
+
+// ParsePerformancePeriod accepts a period name in any case. An empty name
+// means since inception.
+func ParsePerformancePeriod(name string) (PerformancePeriod, error) {
+	if name == "" {
+		return PeriodSinceInception, nil
+	}
+	period := PerformancePeriod(strings.ToUpper(name))
+	switch period {
+	case PeriodOneMonth, PeriodThreeMonths, PeriodYearToDate, PeriodOneYear, PeriodSinceInception:
+		return period, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrInvalidPeriod, name)
+}
+
+// Start returns the beginning of the period ending at asOf, or the zero
+// time for since inception.
+func (p PerformancePeriod) Start(asOf time.Time) time.Time {
+	switch p {
+	case PeriodOneMonth:
+		return asOf.AddDate(0, -1, 0)
+	case PeriodThreeMonths:
+		return asOf.AddDate(0, -3, 0)
+	case PeriodYearToDate:
+		return time.Date(asOf.Year(), time.January, 1, 0, 0, 0, 0, asOf.Location())
+	case PeriodOneYear:
+		return asOf.AddDate(-1, 0, 0)
+	}
+	return time.Time{}
+}
+
+// ValuationPoint is the value of a portfolio or position at the end of a
+// day, after the external cash flows of that day. Flow is positive when
+// money comes in (deposits, or buys and fees paid from outside) and
+// negative when it goes out (withdrawals, or sales and dividends paid out).
+type ValuationPoint struct {
+	Date  time.Time `json:"date"`
+	Value Decimal   `json:"value"`
+	Flow  Decimal   `json:"flow"`
+}
+
+// TimeWeightedReturn chains the returns between consecutive points, so the
+// result does not depend on the size or timing of the cash flows. Each
+// sub-period returns (value - flow) / previous value - 1; sub-periods that
+// start with nothing held are skipped. The result is a fraction.
+func TimeWeightedReturn(points []ValuationPoint) (Decimal, error) {
+	if len(points) < 2 {
+		return Zero, fmt.Errorf("%w: %d valuation points", ErrInsufficientHistory, len(points))
+	}
+	returns, err := cumulativeReturns(points)
+	if err != nil {
+		return Zero, err
+	}
+	return returns[len(returns)-1], nil
+}
+
+// cumulativeReturns returns the time-weighted return from the first point
+// up to each point, as fractions.
+func cumulativeReturns(points []ValuationPoint) ([]Decimal, error) {
+	one := NewDecimalFromInt(1)
+	growth := one
+	returns := make([]Decimal, len(points))
+	returns[0] = Zero
+	for i := 1; i < len(points); i++ {
+		returns[i] = returns[i-1]
+		previous := points[i-1].Value
+		if previous.Cmp(Zero) <= 0 {
+			continue
+		}
+		before, err := points[i].Value.Sub(points[i].Flow)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove cash flow: %w", err)
+		}
+		factor, err := before.Div(previous)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate sub-period return: %w", err)
+		}
+		if growth, err = growth.Mul(factor); err != nil {
+			return nil, fmt.Errorf("failed to chain sub-period return: %w", err)
+		}
+		if returns[i], err = growth.Sub(one); err != nil {
+			return nil, fmt.Errorf("failed to calculate return: %w", err)
+		}
+	}
+	return returns, nil
+}
+
+// AnnualizeReturn converts a return over the given number of days into a
+// yearly rate. Both are fractions.
+func AnnualizeReturn(r Decimal, days int) (Decimal, error) {
+	if days <= 0 {
+		return Zero, fmt.Errorf("%w: period of %d days", ErrInsufficientHistory, days)
+	}
+	one := NewDecimalFromInt(1)
+	growth, err := r.Add(one)
+	if err != nil {
+		return Zero, fmt.Errorf("failed to calculate growth: %w", err)
+	}
+	exponent, err := NewDecimalFromInt(365).Div(NewDecimalFromInt(int64(days)))
+	if err != nil {
+		return Zero, fmt.Errorf("failed to calculate exponent: %w", err)
+	}
+	if growth, err = growth.Pow(exponent); err != nil {
+		return Zero, fmt.Errorf("failed to annualize return: %w", err)
+	}
+	result, err := growth.Sub(one)
+	if err != nil {
+		return Zero, fmt.Errorf("failed to annualize return: %w", err)
+	}
+	return result, nil
+}
+
+// ValuationSeries replays the ledger and values the portfolio, or only the
+// position with positionID when it is not empty, at the end of every trade
+// date in the target currency of rates. Units are marked at the price of
+// the last trade in the instrument; a final point at asOf marks them at the
+// current price. Each past day is converted at the rates in effect on it,
+// and the final point at the latest rates. The portfolio value includes
+// its cash accounts: deposits and withdrawals are then the cash flows,
+// while trades, dividends and fees settled in a cash account only move
+// value inside the portfolio.
+func (p *Portfolio) ValuationSeries(positionID string, asOf time.Time, rates *ExchangeRates) ([]ValuationPoint, error) {
+	return p.ValuationSeriesAt(positionID, nil, asOf, rates, nil)
+}
+
+// ValuationSeriesAt is ValuationSeries with an extra point on each of the
+// given dates between the first ledger entry and asOf, in order. Units are
+// marked at the stored close of their instrument when history has one at
+// least as recent as the last trade.
+func (p *Portfolio) ValuationSeriesAt(positionID string, dates []time.Time, asOf time.Time, rates *ExchangeRates, history PriceHistory) ([]ValuationPoint, error) {
+	type holding struct {
+		position *Position
+		isin     string
+		currency string
+		quantity Decimal
+		mark     Decimal
+		markDate time.Time
+	}
+	holdings := make(map[string]*holding)
+	var order []*holding
+
+	accounts := make(map[string]Decimal)
+	var currencies []string
+	if positionID == "" {
+		currencies = p.CashAccountCurrencies()
+		for _, currency := range currencies {
+			accounts[currency] = Zero
+		}
+	}
+
+	value := func(date time.Time, current bool) (Decimal, error) {
+		dayRates := rates
+		if !current {
+			dayRates = rates.On(date)
+		}
+		total := Zero
+		for _, currency := range currencies {
+			converted, err := dayRates.Convert(accounts[currency], currency)
+			if err != nil {
+				return Zero, fmt.Errorf("failed to convert cash balance: %w", err)
+			}
+			if total, err = total.Add(converted); err != nil {
+				return Zero, fmt.Errorf("failed to add cash balance: %w", err)
+			}
+		}
+		for _, h := range order {
+			if h.quantity.IsZero() {
+				continue
+			}
+			mark := h.mark
+			if stored, ok := history.CloseOn(h.isin, date); ok && stored.Currency == h.currency && !stored.Date.Before(h.markDate) {
+				mark = stored.Close
+			}
+			if current && h.position != nil && !h.position.CurrentPrice.IsZero() {
+				mark = h.position.CurrentPrice
+			}
+			amount, err := h.quantity.Mul(mark)
+			if err != nil {
+				return Zero, fmt.Errorf("failed to value holding: %w", err)
+			}
+			converted, err := dayRates.Convert(amount, h.currency)
+			if err != nil {
+				return Zero, fmt.Errorf("failed to convert holding value: %w", err)
+			}
+			if total, err = total.Add(converted); err != nil {
+				return Zero, fmt.Errorf("failed to add holding value: %w", err)
+			}
+		}
+		return total, nil
+	}
+
+	var points []ValuationPoint
+	var day time.Time
+	flow := Zero
+	closeDay := func() error {
+		v, err := value(day, false)
+		if err != nil {
+			return err
+		}
+		points = append(points, ValuationPoint{Date: day, Value: v, Flow: flow})
+		flow = Zero
+		return nil
+	}
+	// valueDatesBefore adds a point for each date after the last closed day
+	// and before limit, when nothing is traded
+	next := 0
+	valueDatesBefore := func(limit time.Time) error {
+		for ; next < len(dates) && dates[next].Before(limit) && !sameDate(dates[next], limit); next++ {
+			if day.IsZero() || !dates[next].After(day) || sameDate(dates[next], day) {
+				continue
+			}
+			v, err := value(dates[next], false)
+			if err != nil {
+				return err
+			}
+			points = append(points, ValuationPoint{Date: dates[next], Value: v, Flow: Zero})
+		}
+		return nil
+	}
+	addFlow := func(amount Decimal, currency string, inflow bool) error {
+		converted, err := rates.On(day).Convert(amount, currency)
+		if err != nil {
+			return fmt.Errorf("failed to convert cash flow: %w", err)
+		}
+		if inflow {
+			flow, err = flow.Add(converted)
+		} else {
+			flow, err = flow.Sub(converted)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to add cash flow: %w", err)
+		}
+		return nil
+	}
+
+	for _, step := range p.replaySteps() {
+		if step.split != nil {
+			// A split changes units and price together, not the value
+			h, ok := holdings[step.split.PositionID]
+			if !ok {
+				continue
+			}
+			factor, err := step.split.Factor()
+			if err != nil {
+				return nil, err
+			}
+			if h.quantity, err = h.quantity.Mul(factor); err != nil {
+				return nil, fmt.Errorf("failed to replay split: %w", err)
+			}
+			if h.mark, err = h.mark.Div(factor); err != nil {
+				return nil, fmt.Errorf("failed to replay split: %w", err)
+			}
+			continue
+		}
+
+		tx := step.tx
+		if positionID != "" && tx.PositionID != positionID {
+			continue
+		}
+		if !day.IsZero() && !sameDate(day, tx.TradeDate) {
+			if err := closeDay(); err != nil {
+				return nil, err
+			}
+		}
+		if err := valueDatesBefore(tx.TradeDate); err != nil {
+			return nil, err
+		}
+		day = tx.TradeDate
+
+		settled := false
+		if positionID == "" {
+			effect, err := tx.CashEffect()
+			if err != nil {
+				return nil, err
+			}
+			if balance, ok := accounts[effect.Currency]; ok && !effect.IsZero() {
+				if accounts[effect.Currency], err = balance.Add(effect.Amount); err != nil {
+					return nil, fmt.Errorf("failed to replay cash balance: %w", err)
+				}
+				settled = true
+			}
+		}
+
+		if tx.Type.IsCashMovement() {
+			if err := addFlow(tx.Amount, tx.Currency, tx.Type == TransactionTypeDeposit); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if !tx.IsTrade() {
+			if settled {
+				continue
+			}
+			// Dividends are paid out of the portfolio, fees are paid into it
+			if err := addFlow(tx.Amount, tx.Currency, tx.Type == TransactionTypeFee); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		h, ok := holdings[tx.PositionID]
+		if !ok {
+			h = &holding{isin: tx.InstrumentISIN, currency: tx.Currency, quantity: Zero, mark: Zero}
+			if pos, err := p.GetPosition(tx.PositionID); err == nil {
+				h.position = pos
+				h.isin = pos.Instrument.ISIN
+				h.currency = pos.ValueCurrency()
+			}
+			holdings[tx.PositionID] = h
+			order = append(order, h)
+		}
+		if !tx.Price.IsZero() {
+			h.mark = tx.Price
+			h.markDate = tx.TradeDate
+		}
+
+		var err error
+		if tx.Type.IncreasesQuantity() {
+			h.quantity, err = h.quantity.Add(tx.Quantity)
+		} else {
+			h.quantity, err = h.quantity.Sub(tx.Quantity)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to replay quantity: %w", err)
+		}
+
+		switch tx.Type {
+		case TransactionTypeBuy, TransactionTypeSell:
+			if settled {
+				break
+			}
+			// Cash paid includes the charges, cash received is net of them
+			var cash Decimal
+			if tx.Type == TransactionTypeBuy {
+				cash, err = tx.Amount.Add(tx.Costs)
+			} else {
+				cash, err = tx.Amount.Sub(tx.Costs)
+			}
+			if err != nil {
+				return nil, fmt.Errorf("failed to apply charges: %w", err)
+			}
+			err = addFlow(cash, tx.Currency, tx.Type == TransactionTypeBuy)
+		default:
+			// Transfers move units in or out at their market value
+			var transferred Decimal
+			if transferred, err = tx.Quantity.Mul(h.mark); err == nil {
+				err = addFlow(transferred, h.currency, tx.Type.IncreasesQuantity())
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if day.IsZero() {
+		return nil, nil
+	}
+	if err := closeDay(); err != nil {
+		return nil, err
+	}
+	if err := valueDatesBefore(asOf); err != nil {
+		return nil, err
+	}
+	current, err := value(asOf, true)
+	if err != nil {
+		return nil, err
+	}
+	points = append(points, ValuationPoint{Date: asOf, Value: current, Flow: Zero})
+	return points, nil
+}
+
+// pointsSince keeps the points after from, preceded by the last point on
+// or before it as the starting value. It returns the date measurement
+// starts at, which is the first point when the history begins after from.
+func pointsSince(points []ValuationPoint, from time.Time) ([]ValuationPoint, time.Time) {
+	base := -1
+	for i := range points {
+		if points[i].Date.After(from) {
+			break
+		}
+		base = i
+	}
+	if base < 0 {
+		return points, points[0].Date
+	}
+
+	result := make([]ValuationPoint, 0, len(points)-base)
+	result = append(result, ValuationPoint{Date: from, Value: points[base].Value, Flow: Zero})
+	return append(result, points[base+1:]...), from
+}
+
+// PositionPerformance is the time-weighted return of a single position.
+type PositionPerformance struct {
+	PositionID         string    `json:"position_id"`
+	ISIN               string    `json:"isin"`
+	Symbol             string    `json:"symbol"`
+	From               time.Time `json:"from"`
+	TimeWeightedReturn Decimal   `json:"time_weighted_return"`
+}
+
+// PerformanceReport holds time-weighted returns as percentages. The
+// annualized return is only reported for windows longer than a year.
+type PerformanceReport struct {
+	Period             PerformancePeriod     `json:"period"`
+	From               time.Time             `json:"from"`
+	To                 time.Time             `json:"to"`
+	Currency           string                `json:"currency"`
+	TimeWeightedReturn Decimal               `json:"time_weighted_return"`
+	AnnualizedReturn   *Decimal              `json:"annualized_return,omitempty"`
+	Positions          []PositionPerformance `json:"positions"`
+}
+
+// Performance measures the time-weighted return of the portfolio and of
+// every position held during period, ending at asOf, in the target
+// currency of rates. When the history begins inside the period, the
+// measurement starts at the first ledger entry. The portfolio return is
+// chained from the stored snapshots on the days they cover; on other days
+// holdings are valued at their stored closes on every trading day of
+// history, and at the price of their last trade where it has none.
+// Positions are always valued from the ledger.
+func (p *Portfolio) Performance(period PerformancePeriod, asOf time.Time, rates *ExchangeRates, history PriceHistory, snapshots []PortfolioSnapshot) (*PerformanceReport, error) {
+	isins := make([]string, 0, len(p.Positions))
+	for i := range p.Positions {
+		isins = append(isins, p.Positions[i].Instrument.ISIN)
+	}
+	series, err := p.ValuationSeriesAt("", history.TradingDates(isins...), asOf, rates, history)
+	if err != nil {
+		return nil, err
+	}
+	series = withSnapshots(series, snapshots, rates.Target, true)
+	if len(series) == 0 {
+		return nil, fmt.Errorf("%w: the ledger is empty", ErrInsufficientHistory)
+	}
+
+	start := period.Start(asOf)
+	window, from := pointsSince(series, start)
+	twr, err := TimeWeightedReturn(window)
+	if err != nil {
+		return nil, err
+	}
+	report := &PerformanceReport{
+		Period:    period,
+		From:      from,
+		To:        asOf,
+		Currency:  rates.Target,
+		Positions: make([]PositionPerformance, 0, len(p.Positions)),
+	}
+	if report.TimeWeightedReturn, err = asPercent(twr); err != nil {
+		return nil, err
+	}
+	if days := int(asOf.Sub(from).Hours() / 24); days > 365 {
+		annualized, err := AnnualizeReturn(twr, days)
+		if err != nil {
+			return nil, err
+		}
+		if annualized, err = asPercent(annualized); err != nil {
+			return nil, err
+		}
+		report.AnnualizedReturn = &annualized
+	}
+
+	for i := range p.Positions {
+		pos := &p.Positions[i]
+		if pos.ClosedAt != nil && pos.ClosedAt.Before(start) {
+			continue
+		}
+		series, err := p.ValuationSeriesAt(pos.ID, history.Dates(pos.Instrument.ISIN), asOf, rates, history)
+		if err != nil {
+			return nil, fmt.Errorf("failed to value position %s: %w", pos.ID, err)
+		}
+		if len(series) == 0 {
+			continue
+		}
+		window, from := pointsSince(series, start)
+		twr, err := TimeWeightedReturn(window)
+		if err != nil {
+			return nil, err
+		}
+		if twr, err = asPercent(twr); err != nil {
+			return nil, err
+		}
+		report.Positions = append(report.Positions, PositionPerformance{
+			PositionID:         pos.ID,
+			ISIN:               pos.Instrument.ISIN,
+			Symbol:             pos.Instrument.Symbol,
+			From:               from,
+			TimeWeightedReturn: twr,
+		})
+	}
+	return report, nil
+}
+
+// asPercent expresses a fraction as a percentage.
+func asPercent(fraction Decimal) (Decimal, error) {
+	result, err := fraction.Mul(NewDecimalFromInt(100))
+	if err != nil {
+		return Zero, fmt.Errorf("failed to multiply by 100: %w", err)
+	}
+	return result, nil
+}
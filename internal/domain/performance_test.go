@@ -0,0 +1,189 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParsePerformancePeriod(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected PerformancePeriod
+		err      error
+	}{
+		{"", PeriodSinceInception, nil},
+		{"1m", PeriodOneMonth, nil},
+		{"YTD", PeriodYearToDate, nil},
+		{"all", PeriodSinceInception, nil},
+		{"5Y", "", ErrInvalidPeriod},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			period, err := ParsePerformancePeriod(tc.input)
+			if !errors.Is(err, tc.err) || period != tc.expected {
+				t.Errorf("expected %q, %v; got %q, %v", tc.expected, tc.err, period, err)
+			}
+		})
+	}
+}
+
+func TestTimeWeightedReturn(t *testing.T) {
+	points := []ValuationPoint{
+		{Value: NewDecimalFromInt(1000), Flow: NewDecimalFromInt(1000)},
+		// Grew to 1100, then 1100 more was invested
+		{Value: NewDecimalFromInt(2200), Flow: NewDecimalFromInt(1100)},
+		{Value: NewDecimalFromInt(1980), Flow: Zero},
+	}
+
+	twr, err := TimeWeightedReturn(points)
+	if err != nil {
+		t.Fatalf("TimeWeightedReturn failed: %v", err)
+	}
+	// 1.1 * 0.9 - 1, regardless of the amount added halfway
+	if expected, _ := NewDecimalFromString("-0.01"); !twr.Equal(expected) {
+		t.Errorf("expected -0.01, got %s", twr)
+	}
+
+	if _, err := TimeWeightedReturn(points[:1]); !errors.Is(err, ErrInsufficientHistory) {
+		t.Errorf("expected ErrInsufficientHistory, got %v", err)
+	}
+}
+
+func newPerformancePortfolio(t *testing.T) *Portfolio {
+	t.Helper()
+	p := NewPortfolio("Performance")
+	p.BaseCurrency = "USD"
+	inst := NewInstrument("US001", "TEST", "Test", InstrumentTypeStock, "USD", "NYSE")
+
+	trades := []Transaction{
+		NewTransaction(TransactionTypeBuy, inst.ISIN, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC),
+			NewDecimalFromInt(10), NewDecimalFromInt(100), NewDecimalFromInt(1000), "USD"),
+		NewTransaction(TransactionTypeBuy, inst.ISIN, time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC),
+			NewDecimalFromInt(10), NewDecimalFromInt(110), NewDecimalFromInt(1100), "USD"),
+	}
+	for _, tx := range trades {
+		if _, err := p.RecordTransaction(inst, tx); err != nil {
+			t.Fatalf("RecordTransaction failed: %v", err)
+		}
+	}
+	if err := p.UpdatePositionPrice(p.Positions[0].ID, NewDecimalFromInt(99)); err != nil {
+		t.Fatalf("UpdatePositionPrice failed: %v", err)
+	}
+	return &p
+}
+
+func TestPortfolio_Performance(t *testing.T) {
+	p := newPerformancePortfolio(t)
+	asOf := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		period   PerformancePeriod
+		from     time.Time
+		expected int64
+	}{
+		// Marked at 110 on the second buy, 99 now
+		{PeriodOneMonth, time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), -10},
+		{PeriodSinceInception, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), -1},
+		{PeriodOneYear, time.Date(2024, 1, 10, 0, 0, 0, 0, time.UTC), -1},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.period), func(t *testing.T) {
+			report, err := p.Performance(tc.period, asOf, NewExchangeRates("USD"), nil, nil)
+			if err != nil {
+				t.Fatalf("Performance failed: %v", err)
+			}
+			if !report.From.Equal(tc.from) {
+				t.Errorf("expected measurement from %s, got %s", tc.from, report.From)
+			}
+			if !report.TimeWeightedReturn.Equal(NewDecimalFromInt(tc.expected)) {
+				t.Errorf("expected %d%%, got %s", tc.expected, report.TimeWeightedReturn)
+			}
+			if report.AnnualizedReturn != nil {
+				t.Errorf("expected no annualized return under a year, got %s", report.AnnualizedReturn)
+			}
+			if len(report.Positions) != 1 || !report.Positions[0].TimeWeightedReturn.Equal(NewDecimalFromInt(tc.expected)) {
+				t.Errorf("expected the single position to match the portfolio, got %+v", report.Positions)
+			}
+		})
+	}
+}
+
+func TestPortfolio_Performance_SaleAndDividend(t *testing.T) {
+	p := newPerformancePortfolio(t)
+	inst := p.Positions[0].Instrument
+
+	// Sold half at 121 after the second buy: 110 -> 121 is +10%
+	sell := NewTransaction(TransactionTypeSell, inst.ISIN, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+		NewDecimalFromInt(10), NewDecimalFromInt(121), NewDecimalFromInt(1210), "USD")
+	if _, err := p.RecordTransaction(inst, sell); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	// A dividend paid out is part of the return: 1210 -> 1331 is +10%
+	dividend := NewTransaction(TransactionTypeDividend, inst.ISIN, time.Date(2024, 3, 5, 0, 0, 0, 0, time.UTC),
+		Zero, Zero, NewDecimalFromInt(121), "USD")
+	if _, err := p.RecordTransaction(inst, dividend); err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	if err := p.UpdatePositionPrice(p.Positions[0].ID, NewDecimalFromInt(121)); err != nil {
+		t.Fatalf("UpdatePositionPrice failed: %v", err)
+	}
+
+	report, err := p.Performance(PeriodOneMonth, time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), NewExchangeRates("USD"), nil, nil)
+	if err != nil {
+		t.Fatalf("Performance failed: %v", err)
+	}
+	if !report.TimeWeightedReturn.Equal(NewDecimalFromInt(21)) {
+		t.Errorf("expected 21%%, got %s", report.TimeWeightedReturn)
+	}
+}
+
+func TestPortfolio_Performance_Annualized(t *testing.T) {
+	p := newPerformancePortfolio(t)
+
+	report, err := p.Performance(PeriodSinceInception, time.Date(2026, 1, 10, 0, 0, 0, 0, time.UTC), NewExchangeRates("USD"), nil, nil)
+	if err != nil {
+		t.Fatalf("Performance failed: %v", err)
+	}
+	if report.AnnualizedReturn == nil {
+		t.Fatal("expected an annualized return over two years")
+	}
+	if report.AnnualizedReturn.Cmp(report.TimeWeightedReturn) <= 0 || report.AnnualizedReturn.Cmp(Zero) >= 0 {
+		t.Errorf("expected a smaller yearly loss than %s, got %s", report.TimeWeightedReturn, report.AnnualizedReturn)
+	}
+}
+
+func TestPortfolio_Performance_Snapshots(t *testing.T) {
+	p := newPerformancePortfolio(t)
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+	snapshots := []PortfolioSnapshot{
+		// Marked at 80 on the first buy: 800 -> 1100 before the second
+		// buy is +37.5%, then 2200 -> 1980 is -10%
+		{Date: day(1, 10), Currency: "USD", TotalValue: NewDecimalFromInt(800), NetFlow: NewDecimalFromInt(1000)},
+		// A snapshot between trades adds a point without changing the chain
+		{Date: day(3, 1), Currency: "USD", TotalValue: NewDecimalFromInt(2200), NetFlow: Zero},
+		// Snapshots in another currency are ignored
+		{Date: day(2, 10), Currency: "EUR", TotalValue: NewDecimalFromInt(1), NetFlow: Zero},
+	}
+
+	report, err := p.Performance(PeriodSinceInception, day(3, 15), NewExchangeRates("USD"), nil, snapshots)
+	if err != nil {
+		t.Fatalf("Performance failed: %v", err)
+	}
+	expected, _ := NewDecimalFromString("23.75")
+	if !report.TimeWeightedReturn.Equal(expected) {
+		t.Errorf("expected %s%%, got %s", expected, report.TimeWeightedReturn)
+	}
+	// Positions are still valued from the ledger
+	if len(report.Positions) != 1 || !report.Positions[0].TimeWeightedReturn.Equal(NewDecimalFromInt(-1)) {
+		t.Errorf("expected the position at -1%%, got %+v", report.Positions)
+	}
+}
+
+func TestPortfolio_Performance_EmptyLedger(t *testing.T) {
+	p := NewPortfolio("Empty")
+
+	if _, err := p.Performance(PeriodOneYear, time.Now(), NewExchangeRates("EUR"), nil, nil); !errors.Is(err, ErrInsufficientHistory) {
+		t.Errorf("expected ErrInsufficientHistory, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package domain
 import (
 	"errors"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,22 +15,110 @@ var (
 )
 
 type Portfolio struct {
-	ID          string     `json:"id" gorm:"primaryKey"`
-	Name        string     `json:"name"`
-	Positions   []Position `json:"positions" gorm:"foreignKey:PortfolioID"`
-	LastUpdated time.Time  `json:"last_updated"`
-	CreatedAt   time.Time  `json:"created_at"`
+	ID                string             `json:"id" gorm:"primaryKey"`
+	Name              string             `json:"name"`
+	CostBasisMethod   CostBasisMethod    `json:"cost_basis_method"`
+	BaseCurrency      string             `json:"base_currency"`
+	Positions         []Position         `json:"positions" gorm:"foreignKey:PortfolioID"`
+	Transactions      []Transaction      `json:"transactions"`
+	Dividends         []Dividend         `json:"dividends"`
+	CorporateActions  []CorporateAction  `json:"corporate_actions"`
+	TargetAllocations []TargetAllocation `json:"target_allocations"`
+	BenchmarkISIN     string             `json:"benchmark_isin,omitempty"`
+	LastUpdated       time.Time          `json:"last_updated"`
+	CreatedAt         time.Time          `json:"created_at"`
 }
 
 func NewPortfolio(name string) Portfolio {
 	return Portfolio{
-		ID:        uuid.New().String(),
-		Name:      name,
-		Positions: make([]Position, 0),
-		CreatedAt: time.Now(),
+		ID:                uuid.New().String(),
+		Name:              name,
+		CostBasisMethod:   DefaultCostBasisMethod,
+		BaseCurrency:      DefaultBaseCurrency,
+		Positions:         make([]Position, 0),
+		Transactions:      make([]Transaction, 0),
+		Dividends:         make([]Dividend, 0),
+		CorporateActions:  make([]CorporateAction, 0),
+		TargetAllocations: make([]TargetAllocation, 0),
+		CreatedAt:         time.Now(),
 	}
 }
 
+// SetCostBasisMethod changes the policy applied to sales recorded from now on.
+func (p *Portfolio) SetCostBasisMethod(method CostBasisMethod) error {
+	if !method.IsValid() {
+		return fmt.Errorf("%w: %s", ErrInvalidCostBasisMethod, method)
+	}
+	p.CostBasisMethod = method
+	return nil
+}
+
+// costBasisMethod returns the configured policy, falling back to the default.
+func (p *Portfolio) costBasisMethod() CostBasisMethod {
+	if p.CostBasisMethod == "" {
+		return DefaultCostBasisMethod
+	}
+	return p.CostBasisMethod
+}
+
+// methodFor returns the policy a ledger entry was booked under. Entries
+// recorded before the policy was kept with them use the configured one.
+func (p *Portfolio) methodFor(tx *Transaction) CostBasisMethod {
+	if tx.CostBasisMethod == "" {
+		return p.costBasisMethod()
+	}
+	return tx.CostBasisMethod
+}
+
+// SetBaseCurrency changes the currency that portfolio totals are reported in.
+func (p *Portfolio) SetBaseCurrency(currency string) error {
+	if !IsValidCurrency(currency) {
+		return fmt.Errorf("%w: %s", ErrInvalidCurrency, currency)
+	}
+	p.BaseCurrency = currency
+	return nil
+}
+
+// Currency returns the base currency, falling back to the default.
+func (p *Portfolio) Currency() string {
+	if p.BaseCurrency == "" {
+		return DefaultBaseCurrency
+	}
+	return p.BaseCurrency
+}
+
+// Currencies lists the distinct currencies that positions are priced or
+// invested in, dividends are paid in and ledger entries and their trade
+// charges are recorded in, normalized to ISO-4217 major units.
+func (p *Portfolio) Currencies() []string {
+	codes := make([]string, 0, 2*len(p.Positions)+len(p.Dividends)+len(p.Transactions))
+	for _, pos := range p.Positions {
+		codes = append(codes, pos.ValueCurrency(), pos.InvestedAmount.Currency)
+	}
+	for _, d := range p.Dividends {
+		codes = append(codes, d.Currency())
+	}
+	for _, tx := range p.Transactions {
+		codes = append(codes, tx.Currency)
+		codes = append(codes, TradeCharges{Fee: tx.Fee, Tax: tx.Tax}.Currencies()...)
+	}
+
+	seen := make(map[string]bool)
+	result := make([]string, 0)
+	for _, code := range codes {
+		if code == "" {
+			continue
+		}
+		major, _ := NormalizeCurrency(code)
+		if !seen[major] {
+			seen[major] = true
+			result = append(result, major)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
 func (p *Portfolio) AddPosition(pos Position) error {
 	if !pos.IsValid() {
 		return ErrInvalidPosition
@@ -80,6 +169,174 @@ func (p *Portfolio) GetPosition(id string) (*Position, error) {
 	return nil, ErrPositionNotFound
 }
 
+// FindPositionByISIN returns the position holding the given instrument.
+func (p *Portfolio) FindPositionByISIN(isin string) (*Position, error) {
+	for i := range p.Positions {
+		if p.Positions[i].Instrument.ISIN == isin {
+			return &p.Positions[i], nil
+		}
+	}
+	return nil, ErrPositionNotFound
+}
+
+// RecordTransaction appends an entry to the ledger and applies it to the
+// position holding the instrument, opening a new position for a first buy.
+// A trade dated before the latest trade or split of its position is not
+// applied on top of them: the position is replayed from its ledger instead,
+// so that it does not depend on the order entries are recorded in.
+// It returns the affected position, or nil for entries without one.
+func (p *Portfolio) RecordTransaction(instrument Instrument, tx Transaction) (*Position, error) {
+	if !tx.IsValid() || tx.InstrumentISIN != instrument.ISIN {
+		return nil, ErrInvalidTransaction
+	}
+	if tx.Type.DecreasesQuantity() && tx.CostBasisMethod == "" {
+		tx.CostBasisMethod = p.costBasisMethod()
+	}
+
+	pos, err := p.FindPositionByISIN(instrument.ISIN)
+	if err != nil {
+		if !tx.Type.IncreasesQuantity() {
+			if tx.IsTrade() {
+				return nil, err
+			}
+			tx.PortfolioID = p.ID
+			p.Transactions = append(p.Transactions, tx)
+			return nil, nil
+		}
+		p.Positions = append(p.Positions, Position{
+			ID:                 uuid.New().String(),
+			PortfolioID:        p.ID,
+			InstrumentISIN:     instrument.ISIN,
+			Instrument:         instrument,
+			InvestedAmount:     ZeroMoney(tx.Currency),
+			Quantity:           Zero,
+			CurrentPrice:       Zero,
+			RealizedProfitLoss: ZeroMoney(tx.Currency),
+			LastUpdated:        time.Now(),
+		})
+		pos = &p.Positions[len(p.Positions)-1]
+	} else if tx.IsTrade() && p.isBackdated(pos, tx.TradeDate) {
+		if err := p.recordBackdated(pos, tx); err != nil {
+			return nil, err
+		}
+		return pos, nil
+	}
+
+	if err := pos.ApplyTransaction(tx, p.methodFor(&tx)); err != nil {
+		return nil, fmt.Errorf("failed to apply transaction: %w", err)
+	}
+
+	tx.PortfolioID = p.ID
+	tx.PositionID = pos.ID
+	p.Transactions = append(p.Transactions, tx)
+	return pos, nil
+}
+
+// isBackdated reports whether a trade on date precedes the latest trade or
+// split of pos. Positions holding units from before the ledger cannot be
+// replayed, so their trades are always applied in the order recorded.
+func (p *Portfolio) isBackdated(pos *Position, date time.Time) bool {
+	if !p.replayable(pos) {
+		return false
+	}
+	for _, step := range p.replaySteps() {
+		switch {
+		case step.split != nil && step.split.PositionID == pos.ID,
+			step.tx != nil && step.tx.PositionID == pos.ID && step.tx.IsTrade():
+			if date.Before(step.date) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// replayable reports whether every unit of pos comes from the ledger, so
+// that replaying it does not drop units held before the ledger existed.
+func (p *Portfolio) replayable(pos *Position) bool {
+	for i := range pos.Lots {
+		if pos.Lots[i].TransactionID == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// recordBackdated appends a trade to the ledger and replays its position,
+// leaving both unchanged when the replay fails.
+func (p *Portfolio) recordBackdated(pos *Position, tx Transaction) error {
+	saved := *pos
+	saved.Lots = append([]Lot(nil), pos.Lots...)
+
+	tx.PortfolioID = p.ID
+	tx.PositionID = pos.ID
+	p.Transactions = append(p.Transactions, tx)
+	if err := p.rebuildPosition(pos); err != nil {
+		*pos = saved
+		p.Transactions = p.Transactions[:len(p.Transactions)-1]
+		return err
+	}
+	return nil
+}
+
+// RebuildPositions recomputes quantity, lots, cost basis and realized
+// profit/loss of every position that has ledger entries by replaying them
+// in trade date order, each sale under the cost-basis method it was booked
+// with, together with the splits that took effect in between.
+// Positions without ledger entries are left untouched.
+func (p *Portfolio) RebuildPositions() error {
+	for i := range p.Positions {
+		if err := p.rebuildPosition(&p.Positions[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rebuildPosition replays the ledger entries and splits of pos. The
+// current price is kept, as it was quoted after every split already.
+func (p *Portfolio) rebuildPosition(pos *Position) error {
+	price := pos.CurrentPrice
+	reset := false
+	for _, step := range p.replaySteps() {
+		if step.split != nil {
+			// Splits before the first ledger entry are already reflected in it
+			if step.split.PositionID != pos.ID || !reset {
+				continue
+			}
+			factor, err := step.split.Factor()
+			if err != nil {
+				return err
+			}
+			if err := pos.applySplit(factor); err != nil {
+				return fmt.Errorf("failed to replay corporate action %s: %w", step.split.ID, err)
+			}
+			continue
+		}
+
+		tx := step.tx
+		if tx.PositionID != pos.ID {
+			continue
+		}
+		if !reset {
+			pos.Quantity = Zero
+			pos.InvestedAmount = ZeroMoney(pos.InvestedAmount.Currency)
+			pos.RealizedProfitLoss = ZeroMoney(pos.InvestedAmount.Currency)
+			pos.Lots = nil
+			pos.OpenedAt = nil
+			pos.ClosedAt = nil
+			reset = true
+		}
+		if err := pos.ApplyTransaction(*tx, p.methodFor(tx)); err != nil {
+			return fmt.Errorf("failed to replay transaction %s: %w", tx.ID, err)
+		}
+	}
+	if reset && !price.IsZero() {
+		pos.CurrentPrice = price
+	}
+	return nil
+}
+
 func (p *Portfolio) UpdatePositionPrice(id string, price Decimal) error {
 	pos, err := p.GetPosition(id)
 	if err != nil {
@@ -91,63 +348,126 @@ func (p *Portfolio) UpdatePositionPrice(id string, price Decimal) error {
 	return nil
 }
 
-func (p *Portfolio) TotalValue() (Decimal, error) {
-	total := Zero
+// TotalValue sums the position values and the cash balances.
+// It fails with ErrCurrencyMismatch when positions are quoted, or cash is
+// held, in different currencies; use Valuate for portfolios holding more
+// than one currency.
+func (p *Portfolio) TotalValue() (Money, error) {
+	holdings, err := p.holdingsValue()
+	if err != nil {
+		return Money{}, err
+	}
+	balances, err := p.CashBalances()
+	if err != nil {
+		return Money{}, fmt.Errorf("failed to calculate cash balances: %w", err)
+	}
+	total, err := sumMoney(p.Currency(), append(balances, holdings))
+	if err != nil {
+		return Money{}, fmt.Errorf("failed to add cash to total: %w", err)
+	}
+	return total, nil
+}
+
+// holdingsValue sums the position values.
+func (p *Portfolio) holdingsValue() (Money, error) {
+	values := make([]Money, 0, len(p.Positions))
 	for _, pos := range p.Positions {
 		currentValue, err := pos.CurrentValue()
 		if err != nil {
-			return Zero, fmt.Errorf("failed to calculate current value: %w", err)
-		}
-		newTotal, err := total.Add(currentValue)
-		if err != nil {
-			return Zero, fmt.Errorf("failed to add to total: %w", err)
+			return Money{}, fmt.Errorf("failed to calculate current value: %w", err)
 		}
-		total = newTotal
+		values = append(values, currentValue)
+	}
+	total, err := sumMoney(p.Currency(), values)
+	if err != nil {
+		return Money{}, fmt.Errorf("failed to add to total: %w", err)
 	}
 	return total, nil
 }
 
-func (p *Portfolio) TotalInvested() (Decimal, error) {
-	total := Zero
+// TotalInvested sums the invested amounts, which must share a currency.
+func (p *Portfolio) TotalInvested() (Money, error) {
+	amounts := make([]Money, 0, len(p.Positions))
 	for _, pos := range p.Positions {
-		newTotal, err := total.Add(pos.InvestedAmount)
-		if err != nil {
-			return Zero, fmt.Errorf("failed to add invested amount: %w", err)
-		}
-		total = newTotal
+		amounts = append(amounts, pos.InvestedAmount)
+	}
+	total, err := sumMoney(p.Currency(), amounts)
+	if err != nil {
+		return Money{}, fmt.Errorf("failed to add invested amount: %w", err)
+	}
+	return total, nil
+}
+
+// TotalRealizedProfitLoss sums the profit/loss booked by sales.
+func (p *Portfolio) TotalRealizedProfitLoss() (Money, error) {
+	amounts := make([]Money, 0, len(p.Positions))
+	for _, pos := range p.Positions {
+		amounts = append(amounts, pos.RealizedProfitLoss)
+	}
+	total, err := sumMoney(p.Currency(), amounts)
+	if err != nil {
+		return Money{}, fmt.Errorf("failed to add realized profit/loss: %w", err)
 	}
 	return total, nil
 }
 
-func (p *Portfolio) TotalProfitLoss() (Decimal, error) {
-	totalValue, err := p.TotalValue()
+// TotalUnrealizedProfitLoss is the gain or loss on the units still held.
+func (p *Portfolio) TotalUnrealizedProfitLoss() (Money, error) {
+	totalValue, err := p.holdingsValue()
 	if err != nil {
-		return Zero, fmt.Errorf("failed to calculate total value: %w", err)
+		return Money{}, fmt.Errorf("failed to calculate holdings value: %w", err)
 	}
 	totalInvested, err := p.TotalInvested()
 	if err != nil {
-		return Zero, fmt.Errorf("failed to calculate total invested: %w", err)
+		return Money{}, fmt.Errorf("failed to calculate total invested: %w", err)
 	}
 	result, err := totalValue.Sub(totalInvested)
 	if err != nil {
-		return Zero, fmt.Errorf("failed to subtract: %w", err)
+		return Money{}, fmt.Errorf("failed to subtract: %w", err)
 	}
 	return result, nil
 }
 
+// TotalProfitLoss is the total of realized and unrealized profit/loss.
+func (p *Portfolio) TotalProfitLoss() (Money, error) {
+	unrealized, err := p.TotalUnrealizedProfitLoss()
+	if err != nil {
+		return Money{}, err
+	}
+	realized, err := p.TotalRealizedProfitLoss()
+	if err != nil {
+		return Money{}, err
+	}
+	result, err := unrealized.Add(realized)
+	if err != nil {
+		return Money{}, fmt.Errorf("failed to add realized profit/loss: %w", err)
+	}
+	return result, nil
+}
+
+// TotalProfitLossPercent measures the total profit/loss against the cost of
+// the units held and of those already sold.
 func (p *Portfolio) TotalProfitLossPercent() (Decimal, error) {
-	invested, err := p.TotalInvested()
+	amounts := make([]Money, 0, len(p.Positions))
+	for i := range p.Positions {
+		basis, err := p.Positions[i].costBasis()
+		if err != nil {
+			return Zero, err
+		}
+		amounts = append(amounts, basis)
+	}
+	basis, err := sumMoney(p.Currency(), amounts)
 	if err != nil {
-		return Zero, fmt.Errorf("failed to calculate total invested: %w", err)
+		return Zero, fmt.Errorf("failed to add cost basis: %w", err)
 	}
-	if invested.IsZero() {
+	if basis.IsZero() {
 		return Zero, nil
 	}
 	profitLoss, err := p.TotalProfitLoss()
 	if err != nil {
 		return Zero, fmt.Errorf("failed to calculate profit/loss: %w", err)
 	}
-	percentage, err := profitLoss.Div(invested)
+	percentage, err := profitLoss.Ratio(basis)
 	if err != nil {
 		return Zero, fmt.Errorf("failed to divide: %w", err)
 	}
@@ -3,6 +3,7 @@ package domain
 import (
 	"errors"
 	"testing"
+	"time"
 )
 
 // --- NewPortfolio Tests ---
@@ -88,7 +89,7 @@ func TestAddPosition_Merge(t *testing.T) {
 
 	// Total Invested: 1000 + 500 = 1500
 	expectedInvested := NewDecimalFromInt(1500)
-	if !merged.InvestedAmount.Equal(expectedInvested) {
+	if !merged.InvestedAmount.Amount.Equal(expectedInvested) {
 		t.Errorf("Expected invested %s, got %s", expectedInvested, merged.InvestedAmount)
 	}
 
@@ -295,7 +296,7 @@ func TestTotalValue_MultiplePositions(t *testing.T) {
 	}
 
 	expected := NewDecimalFromInt(3000)
-	if !total.Equal(expected) {
+	if !total.Amount.Equal(expected) {
 		t.Errorf("expected total value %s, got %s", expected, total)
 	}
 }
@@ -337,7 +338,7 @@ func TestTotalInvested_MultiplePositions(t *testing.T) {
 	}
 
 	expected := NewDecimalFromInt(3500)
-	if !total.Equal(expected) {
+	if !total.Amount.Equal(expected) {
 		t.Errorf("expected total invested %s, got %s", expected, total)
 	}
 }
@@ -378,7 +379,7 @@ func TestTotalProfitLoss_Profit(t *testing.T) {
 	}
 
 	// Should have profit (> 0)
-	if profitLoss.Cmp(Zero) <= 0 {
+	if profitLoss.Amount.Cmp(Zero) <= 0 {
 		t.Errorf("expected positive profit, got %s", profitLoss)
 	}
 }
@@ -402,7 +403,7 @@ func TestTotalProfitLoss_Loss(t *testing.T) {
 	}
 
 	expected := NewDecimalFromInt(-250)
-	if !profitLoss.Equal(expected) {
+	if !profitLoss.Amount.Equal(expected) {
 		t.Errorf("expected loss %s, got %s", expected, profitLoss)
 	}
 }
@@ -470,3 +471,37 @@ func TestTotalProfitLossPercent_Loss(t *testing.T) {
 		t.Errorf("expected %s%%, got %s%%", expected, percent)
 	}
 }
+
+func TestTotalProfitLossPercent_AfterSells(t *testing.T) {
+	p, inst := newDividendPortfolio(t)
+	sell := func(date time.Time) {
+		t.Helper()
+		tx := NewTransaction(TransactionTypeSell, inst.ISIN, date, NewDecimalFromInt(5), NewDecimalFromInt(150), NewDecimalFromInt(750), "USD")
+		if _, err := p.RecordTransaction(inst, tx); err != nil {
+			t.Fatalf("RecordTransaction failed: %v", err)
+		}
+	}
+	// Bought 10 at 100, half sold at 150 and the rest worth 150: 500 on 1000
+	sell(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC))
+	if err := p.UpdatePositionPrice(p.Positions[0].ID, NewDecimalFromInt(150)); err != nil {
+		t.Fatalf("UpdatePositionPrice failed: %v", err)
+	}
+
+	for _, name := range []string{"partial sell", "full sell"} {
+		if name == "full sell" {
+			sell(time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC))
+		}
+		percent, err := p.TotalProfitLossPercent()
+		if err != nil {
+			t.Fatalf("%s: TotalProfitLossPercent failed: %v", name, err)
+		}
+		valuation, err := p.Valuate(NewExchangeRates("USD"))
+		if err != nil {
+			t.Fatalf("%s: Valuate failed: %v", name, err)
+		}
+		if !percent.Equal(NewDecimalFromInt(50)) || !valuation.TotalProfitLossPercent.Equal(NewDecimalFromInt(50)) ||
+			!valuation.TotalReturnPercent.Equal(NewDecimalFromInt(50)) {
+			t.Errorf("%s: expected 50%%, got %s, %s and %s", name, percent, valuation.TotalProfitLossPercent, valuation.TotalReturnPercent)
+		}
+	}
+}
@@ -7,36 +7,49 @@ import (
 	"github.com/google/uuid"
 )
 
+// Position holds an instrument in a portfolio.
+// InvestedAmount is the cost basis of the units still held, including the
+// fees and taxes paid to buy them; the cost of units already sold moves into
+// RealizedProfitLoss together with the proceeds net of selling charges.
+// Both are kept in the invested currency, while CurrentPrice is quoted in the
+// instrument currency.
 type Position struct {
-	ID               string     `json:"id" gorm:"primaryKey"`
-	PortfolioID      string     `json:"-"` // Foreign Key for GORM
-	InstrumentISIN   string     `json:"-"` // Foreign Key to Instrument table
-	Instrument       Instrument `json:"instrument" gorm:"foreignKey:InstrumentISIN;references:ISIN"`
-	InvestedAmount   Decimal    `json:"invested_amount" gorm:"type:numeric"`
-	InvestedCurrency string     `json:"invested_currency"`
-	Quantity         Decimal    `json:"quantity" gorm:"type:numeric"`
-	CurrentPrice     Decimal    `json:"current_price" gorm:"type:numeric"`
-	LastUpdated      time.Time  `json:"last_updated"`
+	ID                 string     `json:"id" gorm:"primaryKey"`
+	PortfolioID        string     `json:"-"` // Foreign Key for GORM
+	InstrumentISIN     string     `json:"-"` // Foreign Key to Instrument table
+	Instrument         Instrument `json:"instrument" gorm:"foreignKey:InstrumentISIN;references:ISIN"`
+	InvestedAmount     Money      `json:"invested_amount" gorm:"type:numeric"`
+	Quantity           Decimal    `json:"quantity" gorm:"type:numeric"`
+	CurrentPrice       Decimal    `json:"current_price" gorm:"type:numeric"`
+	RealizedProfitLoss Money      `json:"realized_profit_loss" gorm:"type:numeric"`
+	Lots               []Lot      `json:"lots,omitempty"`
+	OpenedAt           *time.Time `json:"opened_at,omitempty"`
+	ClosedAt           *time.Time `json:"closed_at,omitempty"`
+	LastUpdated        time.Time  `json:"last_updated"`
 }
 
 func NewPosition(instrument Instrument, investedAmount Decimal, investedCurrency string) Position {
 	return Position{
-		ID:               uuid.New().String(),
-		Instrument:       instrument,
-		InvestedAmount:   investedAmount,
-		InvestedCurrency: investedCurrency,
-		Quantity:         Zero,
-		CurrentPrice:     Zero,
-		LastUpdated:      time.Now(),
+		ID:                 uuid.New().String(),
+		Instrument:         instrument,
+		InvestedAmount:     NewMoney(investedAmount, investedCurrency),
+		Quantity:           Zero,
+		CurrentPrice:       Zero,
+		RealizedProfitLoss: ZeroMoney(investedCurrency),
+		LastUpdated:        time.Now(),
 	}
 }
 
+// UpdatePrice sets the latest market price.
+// The quantity is only derived from the invested amount when the position
+// has not been converted to units yet; afterwards it is owned by the ledger
+// and never drifts with the market.
 func (p *Position) UpdatePrice(price Decimal) error {
 	p.CurrentPrice = price
 	p.LastUpdated = time.Now()
 
-	if !price.IsZero() && !p.InvestedAmount.IsZero() {
-		quantity, err := p.InvestedAmount.Div(price)
+	if p.Quantity.IsZero() && !price.IsZero() && !p.InvestedAmount.IsZero() {
+		quantity, err := p.InvestedAmount.Amount.Div(price)
 		if err != nil {
 			return fmt.Errorf("failed to calculate quantity: %w", err)
 		}
@@ -45,38 +58,274 @@ func (p *Position) UpdatePrice(price Decimal) error {
 	return nil
 }
 
-func (p *Position) CurrentValue() (Decimal, error) {
+// ApplyTransaction updates quantity, lots and cost basis from a ledger entry.
+// Buys and inbound transfers open a new lot; sells and outbound transfers
+// consume lots according to the cost-basis method and book the difference
+// between proceeds and cost as realized profit/loss. Trade charges are
+// added to the cost of buys and deducted from the proceeds of sells.
+// Cash-only entries (dividends, fees) leave the position unchanged.
+func (p *Position) ApplyTransaction(tx Transaction, method CostBasisMethod) error {
+	if err := p.coverUntrackedUnits(); err != nil {
+		return err
+	}
+	amount := NewMoney(tx.Amount, tx.Currency)
+	costs := NewMoney(tx.Costs, tx.Currency)
+
+	switch {
+	case tx.Type.IncreasesQuantity():
+		paid, err := amount.Add(costs)
+		if err != nil {
+			return fmt.Errorf("failed to add trade charges: %w", err)
+		}
+		lot, err := NewLot(tx.ID, tx.TradeDate, tx.Quantity, paid.Amount)
+		if err != nil {
+			return err
+		}
+		lot.PositionID = p.ID
+
+		quantity, err := p.Quantity.Add(tx.Quantity)
+		if err != nil {
+			return fmt.Errorf("failed to add quantity: %w", err)
+		}
+		invested, err := p.InvestedAmount.Add(paid)
+		if err != nil {
+			return fmt.Errorf("failed to add invested amount: %w", err)
+		}
+		// A buy opens the position when none is held, or moves its
+		// opening back when it is dated before it
+		if p.Quantity.IsZero() || (p.OpenedAt != nil && tx.TradeDate.Before(*p.OpenedAt)) {
+			openedAt := tx.TradeDate
+			p.OpenedAt = &openedAt
+		}
+		p.Quantity = quantity
+		p.InvestedAmount = invested
+		p.Lots = append(p.Lots, lot)
+		p.ClosedAt = nil
+		if p.CurrentPrice.IsZero() {
+			p.CurrentPrice = tx.Price
+		}
+	case tx.Type.DecreasesQuantity():
+		if tx.Quantity.Cmp(p.Quantity) > 0 {
+			return fmt.Errorf("%w: selling %s of %s", ErrInsufficientQuantity, tx.Quantity, p.Quantity)
+		}
+		lotCost, err := p.consumeLots(tx, method)
+		if err != nil {
+			return err
+		}
+		cost := NewMoney(lotCost, p.InvestedAmount.Currency)
+		if tx.Quantity.Equal(p.Quantity) {
+			// Selling out releases the whole cost basis, including rounding residue
+			cost = p.InvestedAmount
+		}
+		quantity, err := p.Quantity.Sub(tx.Quantity)
+		if err != nil {
+			return fmt.Errorf("failed to subtract quantity: %w", err)
+		}
+		invested, err := p.InvestedAmount.Sub(cost)
+		if err != nil {
+			return fmt.Errorf("failed to subtract invested amount: %w", err)
+		}
+		p.Quantity = quantity
+		p.InvestedAmount = invested
+		if quantity.IsZero() {
+			// Closed positions are kept so their realized profit/loss stays visible
+			closedAt := tx.TradeDate
+			p.ClosedAt = &closedAt
+		}
+
+		if tx.Type == TransactionTypeSell {
+			proceeds, err := amount.Sub(costs)
+			if err != nil {
+				return fmt.Errorf("failed to deduct trade charges: %w", err)
+			}
+			gain, err := proceeds.Sub(cost)
+			if err != nil {
+				return fmt.Errorf("failed to calculate realized profit/loss: %w", err)
+			}
+			realized, err := p.RealizedProfitLoss.Add(gain)
+			if err != nil {
+				return fmt.Errorf("failed to add realized profit/loss: %w", err)
+			}
+			p.RealizedProfitLoss = realized
+		}
+	}
+	p.LastUpdated = time.Now()
+	return nil
+}
+
+// coverUntrackedUnits opens a lot for units that predate lot tracking,
+// such as positions opened from an invested amount only, so that every
+// unit held belongs to exactly one lot.
+func (p *Position) coverUntrackedUnits() error {
+	tracked, trackedCost := Zero, Zero
+	for i := range p.Lots {
+		cost, err := p.Lots[i].RemainingCost()
+		if err != nil {
+			return err
+		}
+		if tracked, err = tracked.Add(p.Lots[i].RemainingQuantity); err != nil {
+			return fmt.Errorf("failed to sum lot quantities: %w", err)
+		}
+		if trackedCost, err = trackedCost.Add(cost); err != nil {
+			return fmt.Errorf("failed to sum lot costs: %w", err)
+		}
+	}
+
+	untracked, err := p.Quantity.Sub(tracked)
+	if err != nil {
+		return fmt.Errorf("failed to calculate untracked quantity: %w", err)
+	}
+	if untracked.Cmp(Zero) <= 0 {
+		return nil
+	}
+	untrackedCost, err := p.InvestedAmount.Amount.Sub(trackedCost)
+	if err != nil {
+		return fmt.Errorf("failed to calculate untracked cost: %w", err)
+	}
+
+	lot, err := NewLot("", p.LastUpdated, untracked, untrackedCost)
+	if err != nil {
+		return err
+	}
+	lot.PositionID = p.ID
+	p.Lots = append([]Lot{lot}, p.Lots...)
+	return nil
+}
+
+// consumeLots removes the units of a sale from the lots and returns their cost.
+func (p *Position) consumeLots(tx Transaction, method CostBasisMethod) (Decimal, error) {
+	if tx.LotID != "" && method != CostBasisSpecific {
+		return Zero, ErrLotSelectionUnsupported
+	}
+
+	switch method {
+	case CostBasisAverage:
+		ratio, err := tx.Quantity.Div(p.Quantity)
+		if err != nil {
+			return Zero, fmt.Errorf("failed to calculate sold ratio: %w", err)
+		}
+		cost, err := p.InvestedAmount.Mul(ratio)
+		if err != nil {
+			return Zero, fmt.Errorf("failed to calculate cost of units sold: %w", err)
+		}
+		for i := range p.Lots {
+			sold, err := p.Lots[i].RemainingQuantity.Mul(ratio)
+			if err != nil {
+				return Zero, fmt.Errorf("failed to reduce lot: %w", err)
+			}
+			if _, _, err := p.Lots[i].consume(sold); err != nil {
+				return Zero, err
+			}
+		}
+		return cost.Amount, nil
+	case CostBasisSpecific:
+		if tx.LotID == "" {
+			return Zero, fmt.Errorf("%w: lot_id is required under specific identification", ErrInvalidTransaction)
+		}
+		for i := range p.Lots {
+			if p.Lots[i].ID != tx.LotID {
+				continue
+			}
+			if tx.Quantity.Cmp(p.Lots[i].RemainingQuantity) > 0 {
+				return Zero, fmt.Errorf("%w: selling %s of %s in lot %s", ErrInsufficientQuantity, tx.Quantity, p.Lots[i].RemainingQuantity, tx.LotID)
+			}
+			_, cost, err := p.Lots[i].consume(tx.Quantity)
+			return cost, err
+		}
+		return Zero, fmt.Errorf("%w: %s", ErrLotNotFound, tx.LotID)
+	case CostBasisFIFO, CostBasisLIFO:
+		left, total := tx.Quantity, Zero
+		for _, i := range openLotIndexes(p.Lots, method) {
+			if left.IsZero() {
+				break
+			}
+			taken, cost, err := p.Lots[i].consume(left)
+			if err != nil {
+				return Zero, err
+			}
+			if left, err = left.Sub(taken); err != nil {
+				return Zero, fmt.Errorf("failed to reduce quantity left to sell: %w", err)
+			}
+			if total, err = total.Add(cost); err != nil {
+				return Zero, fmt.Errorf("failed to add lot cost: %w", err)
+			}
+		}
+		return total, nil
+	default:
+		return Zero, fmt.Errorf("%w: %s", ErrInvalidCostBasisMethod, method)
+	}
+}
+
+// ValueCurrency is the currency the market value is expressed in: the
+// quote currency of the instrument, or the invested currency when unknown.
+func (p *Position) ValueCurrency() string {
+	if p.Instrument.Currency != "" {
+		return p.Instrument.Currency
+	}
+	return p.InvestedAmount.Currency
+}
+
+// IsClosed reports whether every unit of the position has been sold.
+func (p *Position) IsClosed() bool {
+	return p.ClosedAt != nil
+}
+
+// CurrentValue is the market value in the instrument currency.
+func (p *Position) CurrentValue() (Money, error) {
 	if p.CurrentPrice.IsZero() {
-		return Zero, nil
+		return ZeroMoney(p.ValueCurrency()), nil
 	}
 	value, err := p.Quantity.Mul(p.CurrentPrice)
 	if err != nil {
-		return Zero, fmt.Errorf("failed to calculate current value: %w", err)
+		return Money{}, fmt.Errorf("failed to calculate current value: %w", err)
 	}
-	return value, nil
+	return NewMoney(value, p.ValueCurrency()), nil
 }
 
-func (p *Position) ProfitLoss() (Decimal, error) {
+// UnrealizedProfitLoss is the gain or loss on the units still held.
+// It fails with ErrCurrencyMismatch when the instrument is quoted in another
+// currency than it was bought in; use Portfolio.Valuate in that case.
+func (p *Position) UnrealizedProfitLoss() (Money, error) {
 	currentValue, err := p.CurrentValue()
 	if err != nil {
-		return Zero, fmt.Errorf("failed to get current value: %w", err)
+		return Money{}, fmt.Errorf("failed to get current value: %w", err)
 	}
 	result, err := currentValue.Sub(p.InvestedAmount)
 	if err != nil {
-		return Zero, fmt.Errorf("failed to calculate profit/loss: %w", err)
+		return Money{}, fmt.Errorf("failed to calculate unrealized profit/loss: %w", err)
 	}
 	return result, nil
 }
 
+// ProfitLoss is the total of realized and unrealized profit/loss.
+func (p *Position) ProfitLoss() (Money, error) {
+	unrealized, err := p.UnrealizedProfitLoss()
+	if err != nil {
+		return Money{}, err
+	}
+	result, err := unrealized.Add(p.RealizedProfitLoss)
+	if err != nil {
+		return Money{}, fmt.Errorf("failed to calculate profit/loss: %w", err)
+	}
+	return result, nil
+}
+
+// ProfitLossPercent is the total profit/loss as a percentage of the cost
+// of the units still held and of the units already sold, so that closed
+// positions report their realized return.
 func (p *Position) ProfitLossPercent() (Decimal, error) {
-	if p.InvestedAmount.IsZero() {
+	basis, err := p.costBasis()
+	if err != nil {
+		return Zero, err
+	}
+	if basis.IsZero() {
 		return Zero, nil
 	}
 	profitLoss, err := p.ProfitLoss()
 	if err != nil {
 		return Zero, fmt.Errorf("failed to calculate profit/loss: %w", err)
 	}
-	percentage, err := profitLoss.Div(p.InvestedAmount)
+	percentage, err := profitLoss.Ratio(basis)
 	if err != nil {
 		return Zero, fmt.Errorf("failed to divide profit/loss: %w", err)
 	}
@@ -88,9 +337,43 @@ func (p *Position) ProfitLossPercent() (Decimal, error) {
 	return result, nil
 }
 
+// costBasis is the cost of the units held and of those already sold, which
+// profit/loss percentages are measured against.
+func (p *Position) costBasis() (Money, error) {
+	sold, err := p.soldCost()
+	if err != nil {
+		return Money{}, err
+	}
+	basis, err := p.InvestedAmount.Add(sold)
+	if err != nil {
+		return Money{}, fmt.Errorf("failed to add cost of units sold: %w", err)
+	}
+	return basis, nil
+}
+
+// soldCost is the cost basis of the units the lots have given up, in the
+// invested currency.
+func (p *Position) soldCost() (Money, error) {
+	total := Zero
+	for i := range p.Lots {
+		sold, err := p.Lots[i].Quantity.Sub(p.Lots[i].RemainingQuantity)
+		if err != nil {
+			return Money{}, fmt.Errorf("failed to calculate units sold: %w", err)
+		}
+		cost, err := sold.Mul(p.Lots[i].UnitCost)
+		if err != nil {
+			return Money{}, fmt.Errorf("failed to calculate cost of units sold: %w", err)
+		}
+		if total, err = total.Add(cost); err != nil {
+			return Money{}, fmt.Errorf("failed to add cost of units sold: %w", err)
+		}
+	}
+	return NewMoney(total, p.InvestedAmount.Currency), nil
+}
+
 func (p *Position) IsValid() bool {
 	return p.ID != "" &&
 		p.Instrument.IsValid() &&
 		!p.InvestedAmount.IsZero() &&
-		p.InvestedCurrency != ""
+		p.InvestedAmount.Currency != ""
 }
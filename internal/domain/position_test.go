@@ -21,12 +21,12 @@ func TestNewPosition(t *testing.T) {
 		t.Errorf("expected ISIN US0378331005, got %s", position.Instrument.ISIN)
 	}
 
-	if !position.InvestedAmount.Equal(investedAmount) {
+	if !position.InvestedAmount.Amount.Equal(investedAmount) {
 		t.Errorf("expected invested amount %s, got %s", investedAmount, position.InvestedAmount)
 	}
 
-	if position.InvestedCurrency != currency {
-		t.Errorf("expected currency %s, got %s", currency, position.InvestedCurrency)
+	if position.InvestedAmount.Currency != currency {
+		t.Errorf("expected currency %s, got %s", currency, position.InvestedAmount.Currency)
 	}
 
 	if !position.CurrentPrice.IsZero() {
@@ -116,7 +116,7 @@ func TestPosition_CurrentValue(t *testing.T) {
 	}
 	expected := NewDecimalFromInt(10000)
 
-	if !currentValue.Equal(expected) {
+	if !currentValue.Amount.Equal(expected) {
 		t.Errorf("Expected current value %s, got %s", expected, currentValue)
 	}
 }
@@ -137,7 +137,7 @@ func TestPosition_CurrentValue_WithPriceChange(t *testing.T) {
 	}
 
 	expected := NewDecimalFromInt(1500)
-	if !currentValue.Equal(expected) {
+	if !currentValue.Amount.Equal(expected) {
 		t.Errorf("Expected current value %s, got %s", expected, currentValue)
 	}
 }
@@ -164,13 +164,13 @@ func TestPosition_ProfitLoss(t *testing.T) {
 	if err != nil {
 		t.Fatalf("ProfitLoss failed: %v", err)
 	}
-	roundedProfitLoss, err := profitLoss.Round(2)
+	roundedProfitLoss, err := profitLoss.Round()
 	if err != nil {
 		t.Fatalf("Round failed: %v", err)
 	}
 	expected := NewDecimalFromInt(2000)
 
-	if !roundedProfitLoss.Equal(expected) {
+	if !roundedProfitLoss.Amount.Equal(expected) {
 		t.Errorf("Expected P/L %s, got %s", expected, roundedProfitLoss)
 	}
 }
@@ -191,7 +191,7 @@ func TestPosition_ProfitLoss_Loss(t *testing.T) {
 	}
 
 	expected := NewDecimalFromInt(-500)
-	if !profitLoss.Equal(expected) {
+	if !profitLoss.Amount.Equal(expected) {
 		t.Errorf("Expected P/L %s, got %s", expected, profitLoss)
 	}
 }
@@ -300,50 +300,45 @@ func TestPosition_IsValid(t *testing.T) {
 		{
 			name: "valid position",
 			position: Position{
-				ID:               "test-id",
-				Instrument:       NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ"),
-				InvestedAmount:   NewDecimalFromInt(1000),
-				InvestedCurrency: "USD",
+				ID:             "test-id",
+				Instrument:     NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ"),
+				InvestedAmount: NewMoney(NewDecimalFromInt(1000), "USD"),
 			},
 			expected: true,
 		},
 		{
 			name: "empty ID",
 			position: Position{
-				ID:               "",
-				Instrument:       NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ"),
-				InvestedAmount:   NewDecimalFromInt(1000),
-				InvestedCurrency: "USD",
+				ID:             "",
+				Instrument:     NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ"),
+				InvestedAmount: NewMoney(NewDecimalFromInt(1000), "USD"),
 			},
 			expected: false,
 		},
 		{
 			name: "empty ISIN",
 			position: Position{
-				ID:               "test-id",
-				Instrument:       NewInstrument("", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ"),
-				InvestedAmount:   NewDecimalFromInt(1000),
-				InvestedCurrency: "USD",
+				ID:             "test-id",
+				Instrument:     NewInstrument("", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ"),
+				InvestedAmount: NewMoney(NewDecimalFromInt(1000), "USD"),
 			},
 			expected: false,
 		},
 		{
 			name: "zero invested amount",
 			position: Position{
-				ID:               "test-id",
-				Instrument:       NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ"),
-				InvestedAmount:   Zero,
-				InvestedCurrency: "USD",
+				ID:             "test-id",
+				Instrument:     NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ"),
+				InvestedAmount: NewMoney(Zero, "USD"),
 			},
 			expected: false,
 		},
 		{
 			name: "empty InvestedCurrency",
 			position: Position{
-				ID:               "test-id",
-				Instrument:       NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ"),
-				InvestedAmount:   NewDecimalFromInt(1000),
-				InvestedCurrency: "",
+				ID:             "test-id",
+				Instrument:     NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ"),
+				InvestedAmount: NewMoney(NewDecimalFromInt(1000), ""),
 			},
 			expected: false,
 		},
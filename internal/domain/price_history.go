@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// PricePoint is the daily bar of an instrument, in the currency it is
+// quoted in. Only the close is required; open, high, low and volume are
+// kept when the source reports them. Source names where the price came
+// from.
+type PricePoint struct {
+	ISIN     string    `json:"isin"`
+	Date     time.Time `json:"date"`
+	Open     *Decimal  `json:"open,omitempty"`
+	High     *Decimal  `json:"high,omitempty"`
+	Low      *Decimal  `json:"low,omitempty"`
+	Close    Decimal   `json:"close"`
+	Volume   *Decimal  `json:"volume,omitempty"`
+	Currency string    `json:"currency"`
+	Source   string    `json:"source"`
+}
+
+// NewPricePoint truncates date to its calendar day, as only one close is
+// kept per instrument, day and source.
+func NewPricePoint(isin string, date time.Time, closePrice Decimal, currency, source string) PricePoint {
+	y, m, d := date.Date()
+	return PricePoint{
+		ISIN:     isin,
+		Date:     time.Date(y, m, d, 0, 0, 0, 0, time.UTC),
+		Close:    closePrice,
+		Currency: currency,
+		Source:   source,
+	}
+}
+
+// PriceHistory holds stored closes by ISIN in date order.
+type PriceHistory map[string][]PricePoint
+
+// NewPriceHistory groups points by ISIN and sorts them by date. When
+// several sources report the same day, the first one is kept.
+func NewPriceHistory(points []PricePoint) PriceHistory {
+	history := make(PriceHistory)
+	for _, point := range points {
+		history[point.ISIN] = append(history[point.ISIN], point)
+	}
+	for isin, series := range history {
+		sort.SliceStable(series, func(i, j int) bool {
+			return series[i].Date.Before(series[j].Date)
+		})
+		unique := series[:0]
+		for _, point := range series {
+			if len(unique) > 0 && sameDate(unique[len(unique)-1].Date, point.Date) {
+				continue
+			}
+			unique = append(unique, point)
+		}
+		history[isin] = unique
+	}
+	return history
+}
+
+// CloseOn returns the last close of isin on or before date.
+func (h PriceHistory) CloseOn(isin string, date time.Time) (PricePoint, bool) {
+	series := h[isin]
+	i := sort.Search(len(series), func(i int) bool {
+		return series[i].Date.After(date) && !sameDate(series[i].Date, date)
+	})
+	if i == 0 {
+		return PricePoint{}, false
+	}
+	return series[i-1], true
+}
+
+// Dates returns the days isin has a close on, in order.
+func (h PriceHistory) Dates(isin string) []time.Time {
+	dates := make([]time.Time, len(h[isin]))
+	for i, point := range h[isin] {
+		dates[i] = point.Date
+	}
+	return dates
+}
+
+// TradingDates returns the days any of isins has a close on, in order.
+func (h PriceHistory) TradingDates(isins ...string) []time.Time {
+	seen := make(map[string]bool)
+	var dates []time.Time
+	for _, isin := range isins {
+		for _, point := range h[isin] {
+			if day := point.Date.Format(time.DateOnly); !seen[day] {
+				seen[day] = true
+				dates = append(dates, point.Date)
+			}
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		return dates[i].Before(dates[j])
+	})
+	return dates
+}
@@ -0,0 +1,45 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPriceHistory_CloseOn(t *testing.T) {
+	friday := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	history := NewPriceHistory([]PricePoint{
+		NewPricePoint("IE00B4L5Y983", friday.Add(17*time.Hour), NewDecimalFromInt(90), "EUR", "quote"),
+		NewPricePoint("IE00B4L5Y983", friday.AddDate(0, 0, -1), NewDecimalFromInt(80), "EUR", "quote"),
+		// A second source for the same day is ignored
+		NewPricePoint("IE00B4L5Y983", friday, NewDecimalFromInt(91), "EUR", "backfill"),
+	})
+
+	if dates := history.Dates("IE00B4L5Y983"); len(dates) != 2 || !dates[1].Equal(friday) {
+		t.Fatalf("expected two days ending on Friday, got %v", dates)
+	}
+	// A Sunday lookup returns Friday's close
+	if price, ok := history.CloseOn("IE00B4L5Y983", friday.AddDate(0, 0, 2)); !ok || !price.Close.Equal(NewDecimalFromInt(90)) {
+		t.Errorf("expected Friday's close of 90, got %+v", price)
+	}
+	if price, ok := history.CloseOn("IE00B4L5Y983", friday.Add(-time.Hour)); !ok || !price.Close.Equal(NewDecimalFromInt(80)) {
+		t.Errorf("expected Thursday's close of 80, got %+v", price)
+	}
+	if _, ok := history.CloseOn("IE00B4L5Y983", friday.AddDate(0, 0, -2)); ok {
+		t.Error("expected no close before the history starts")
+	}
+}
+
+func TestPriceHistory_TradingDates(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	history := NewPriceHistory([]PricePoint{
+		NewPricePoint("IE00B4L5Y983", day(5), NewDecimalFromInt(90), "EUR", "quote"),
+		NewPricePoint("IE00B4L5Y983", day(4), NewDecimalFromInt(89), "EUR", "quote"),
+		NewPricePoint("US0378331005", day(5), NewDecimalFromInt(170), "USD", "quote"),
+		NewPricePoint("US0378331005", day(6), NewDecimalFromInt(171), "USD", "quote"),
+	})
+
+	dates := history.TradingDates("IE00B4L5Y983", "US0378331005")
+	if len(dates) != 3 || !dates[0].Equal(day(4)) || !dates[2].Equal(day(6)) {
+		t.Errorf("expected Mar 4 to Mar 6 once each, got %v", dates)
+	}
+}
@@ -1,6 +1,9 @@
 package domain
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // PortfolioRepository defines the interface for portfolio persistence.
 // It follows the Domain-Driven Design repository pattern.
@@ -12,3 +15,91 @@ type PortfolioRepository interface {
 	FindAll(ctx context.Context) ([]*Portfolio, error)
 	Delete(ctx context.Context, id string) error
 }
+
+// FXRateRepository stores published exchange rates so that valuations
+// can be reproduced for past dates.
+type FXRateRepository interface {
+	SaveFXRates(ctx context.Context, rates []FXRate) error
+	// FindFXRate returns the latest stored rate published on or before date,
+	// or ErrFXRateNotFound.
+	FindFXRate(ctx context.Context, from, to string, date time.Time) (*FXRate, error)
+	// FindFXRates returns the stored rates published between start and end,
+	// oldest first.
+	FindFXRates(ctx context.Context, from, to string, start, end time.Time) ([]FXRate, error)
+}
+
+// CorporateActionRepository stores corporate actions atomically with the
+// positions and lots they adjusted.
+type CorporateActionRepository interface {
+	ApplyCorporateAction(ctx context.Context, portfolio *Portfolio, action *CorporateAction) error
+}
+
+// InstrumentRepository updates stored instruments, which are otherwise
+// only inserted the first time a position references them.
+type InstrumentRepository interface {
+	UpdateInstrument(ctx context.Context, instrument *Instrument) error
+}
+
+// PriceHistoryRepository stores daily closes so that valuations and
+// comparisons can be replayed without asking a provider again.
+type PriceHistoryRepository interface {
+	SavePrices(ctx context.Context, prices []PricePoint) error
+	// FindPrices returns the stored closes of isin between from and to,
+	// inclusive, in date order.
+	FindPrices(ctx context.Context, isin string, from, to time.Time) ([]PricePoint, error)
+}
+
+// ETFHoldingsRepository stores the constituents of ETFs so that exposure
+// can look through them.
+type ETFHoldingsRepository interface {
+	// SaveETFHoldings replaces the stored constituents of the ETF.
+	SaveETFHoldings(ctx context.Context, composition *ETFComposition) error
+	// FindETFHoldings returns the stored constituents of the ETF, or
+	// ErrHoldingsNotFound.
+	FindETFHoldings(ctx context.Context, etfISIN string) (*ETFComposition, error)
+	// FindETFISINs returns the ISINs of the ETFs with stored constituents.
+	FindETFISINs(ctx context.Context) ([]string, error)
+}
+
+// WatchlistRepository stores watchlists with their items and the
+// instruments they reference.
+type WatchlistRepository interface {
+	SaveWatchlist(ctx context.Context, watchlist *Watchlist) error
+	// FindWatchlist returns the watchlist with the given ID, or
+	// ErrWatchlistNotFound.
+	FindWatchlist(ctx context.Context, id string) (*Watchlist, error)
+	// FindWatchlists returns every watchlist, ordered by name.
+	FindWatchlists(ctx context.Context) ([]*Watchlist, error)
+	// DeleteWatchlist removes the watchlist with the given ID, or returns
+	// ErrWatchlistNotFound.
+	DeleteWatchlist(ctx context.Context, id string) error
+}
+
+// AlertRepository stores alert rules with the state they were last
+// evaluated in, and the alerts they raised.
+type AlertRepository interface {
+	SaveAlertRule(ctx context.Context, rule *AlertRule) error
+	// FindAlertRules returns every rule in creation order.
+	FindAlertRules(ctx context.Context) ([]*AlertRule, error)
+	// DeleteAlertRule removes the rule with the given ID and its alerts, or
+	// returns ErrAlertRuleNotFound.
+	DeleteAlertRule(ctx context.Context, id string) error
+	// RecordAlert stores an alert together with the state of the rule that
+	// raised it, so that a rule cannot fire twice for the same crossing.
+	RecordAlert(ctx context.Context, rule *AlertRule, alert *Alert) error
+	// SaveAlertDelivery stores the delivery outcome of an alert.
+	SaveAlertDelivery(ctx context.Context, alert *Alert) error
+	// FindAlerts returns the latest alerts, newest first, at most limit.
+	FindAlerts(ctx context.Context, limit int) ([]Alert, error)
+}
+
+// SnapshotRepository stores the end-of-day valuations of portfolios, which
+// are otherwise lost when prices are refreshed in place.
+type SnapshotRepository interface {
+	// SaveSnapshot stores the snapshot with its positions, replacing the one
+	// stored for the same portfolio and day.
+	SaveSnapshot(ctx context.Context, snapshot *PortfolioSnapshot) error
+	// FindSnapshots returns the snapshots of the portfolio between from and
+	// to, inclusive, in date order.
+	FindSnapshots(ctx context.Context, portfolioID string, from, to time.Time) ([]PortfolioSnapshot, error)
+}
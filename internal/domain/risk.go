@@ -0,0 +1,411 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// TradingDaysPerYear scales daily statistics to yearly ones.
+const TradingDaysPerYear = 252
+
+// Drawdown is the largest fall of the time-weighted growth from a running
+// peak, as a negative percentage, with the day of the peak and of the
+// trough. Recovery is the first day the peak was regained, nil while it
+// has not been.
+type Drawdown struct {
+	Depth    Decimal    `json:"depth"`
+	Peak     *time.Time `json:"peak,omitempty"`
+	Trough   *time.Time `json:"trough,omitempty"`
+	Recovery *time.Time `json:"recovery,omitempty"`
+}
+
+// RiskMetrics describe the daily returns of a valuation series.
+// Volatility is their annualized standard deviation, as a percentage. The
+// Sharpe and Sortino ratios are annualized and measured against the
+// risk-free rate; they are nil when returns do not vary or never fall
+// below that rate. Beta is measured against the price returns of the
+// benchmark on the days both have a value, and is nil without one.
+type RiskMetrics struct {
+	Observations int      `json:"observations"`
+	Volatility   Decimal  `json:"volatility"`
+	MaxDrawdown  Drawdown `json:"max_drawdown"`
+	SharpeRatio  *Decimal `json:"sharpe_ratio,omitempty"`
+	SortinoRatio *Decimal `json:"sortino_ratio,omitempty"`
+	Beta         *Decimal `json:"beta,omitempty"`
+}
+
+// PositionRisk holds the risk metrics of a single position. Positions
+// without enough history carry an error instead.
+type PositionRisk struct {
+	PositionID string `json:"position_id"`
+	ISIN       string `json:"isin"`
+	Symbol     string `json:"symbol"`
+	*RiskMetrics
+	Error string `json:"error,omitempty"`
+}
+
+// RiskReport holds the risk metrics of the portfolio and its positions
+// over a period. RiskFreeRate is the yearly rate used, in percent.
+type RiskReport struct {
+	Period        PerformancePeriod `json:"period"`
+	From          time.Time         `json:"from"`
+	To            time.Time         `json:"to"`
+	Currency      string            `json:"currency"`
+	RiskFreeRate  Decimal           `json:"risk_free_rate"`
+	BenchmarkISIN string            `json:"benchmark_isin,omitempty"`
+	RiskMetrics
+	Positions []PositionRisk `json:"positions"`
+}
+
+// Risk measures the portfolio and every position held during period,
+// ending at asOf, in the target currency of rates. The portfolio is valued
+// on every day one of its instruments has a stored close in history, at the
+// stored snapshot of the day where there is one, and each position on the
+// days its own instrument has one. riskFreeRate is a yearly rate in
+// percent.
+func (p *Portfolio) Risk(period PerformancePeriod, asOf time.Time, rates *ExchangeRates, history PriceHistory, snapshots []PortfolioSnapshot, riskFreeRate Decimal) (*RiskReport, error) {
+	isins := make([]string, 0, len(p.Positions))
+	for i := range p.Positions {
+		isins = append(isins, p.Positions[i].Instrument.ISIN)
+	}
+	series, err := p.ValuationSeriesAt("", history.TradingDates(isins...), asOf, rates, history)
+	if err != nil {
+		return nil, err
+	}
+	series = withSnapshots(series, snapshots, rates.Target, false)
+	if len(series) == 0 {
+		return nil, fmt.Errorf("%w: the ledger is empty", ErrInsufficientHistory)
+	}
+
+	start := period.Start(asOf)
+	window, from := pointsSince(series, start)
+	metrics, err := MeasureRisk(window, riskFreeRate, history, p.BenchmarkISIN)
+	if err != nil {
+		return nil, err
+	}
+	report := &RiskReport{
+		Period:        period,
+		From:          from,
+		To:            asOf,
+		Currency:      rates.Target,
+		RiskFreeRate:  riskFreeRate,
+		BenchmarkISIN: p.BenchmarkISIN,
+		RiskMetrics:   *metrics,
+		Positions:     make([]PositionRisk, 0, len(p.Positions)),
+	}
+
+	for i := range p.Positions {
+		pos := &p.Positions[i]
+		if pos.ClosedAt != nil && pos.ClosedAt.Before(start) {
+			continue
+		}
+		series, err := p.ValuationSeriesAt(pos.ID, history.Dates(pos.Instrument.ISIN), asOf, rates, history)
+		if err != nil {
+			return nil, fmt.Errorf("failed to value position %s: %w", pos.ID, err)
+		}
+		if len(series) == 0 {
+			continue
+		}
+		result := PositionRisk{
+			PositionID: pos.ID,
+			ISIN:       pos.Instrument.ISIN,
+			Symbol:     pos.Instrument.Symbol,
+		}
+		window, _ := pointsSince(series, start)
+		metrics, err := MeasureRisk(window, riskFreeRate, history, p.BenchmarkISIN)
+		switch {
+		case errors.Is(err, ErrInsufficientHistory):
+			result.Error = err.Error()
+		case err != nil:
+			return nil, err
+		default:
+			result.RiskMetrics = metrics
+		}
+		report.Positions = append(report.Positions, result)
+	}
+	return report, nil
+}
+
+// periodReturn is the return between two consecutive valuation points, as
+// a fraction.
+type periodReturn struct {
+	start time.Time
+	end   time.Time
+	value Decimal
+}
+
+// MeasureRisk calculates the risk metrics of the returns between
+// consecutive points, which are expected to be a trading day apart.
+// Returns are time-weighted, so cash flows do not count as gains or
+// losses. riskFreeRate is a yearly rate in percent; benchmarkISIN may be
+// empty. At least two returns are needed.
+func MeasureRisk(points []ValuationPoint, riskFreeRate Decimal, history PriceHistory, benchmarkISIN string) (*RiskMetrics, error) {
+	returns, err := periodReturns(points)
+	if err != nil {
+		return nil, err
+	}
+	if len(returns) < 2 {
+		return nil, fmt.Errorf("%w: %d daily returns", ErrInsufficientHistory, len(returns))
+	}
+
+	values := make([]Decimal, len(returns))
+	for i, r := range returns {
+		values[i] = r.value
+	}
+	variance, err := covariance(values, values)
+	if err != nil {
+		return nil, err
+	}
+	deviation, err := variance.Sqrt()
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate standard deviation: %w", err)
+	}
+	yearRoot, err := NewDecimalFromInt(TradingDaysPerYear).Sqrt()
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate annualization factor: %w", err)
+	}
+
+	metrics := &RiskMetrics{Observations: len(returns)}
+	volatility, err := deviation.Mul(yearRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to annualize volatility: %w", err)
+	}
+	if metrics.Volatility, err = asPercent(volatility); err != nil {
+		return nil, err
+	}
+	if metrics.MaxDrawdown, err = maxDrawdown(returns); err != nil {
+		return nil, err
+	}
+
+	dailyRate, err := riskFreeRate.Div(NewDecimalFromInt(100 * TradingDaysPerYear))
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate daily risk-free rate: %w", err)
+	}
+	excess := make([]Decimal, len(values))
+	shortfall := Zero
+	for i, value := range values {
+		if excess[i], err = value.Sub(dailyRate); err != nil {
+			return nil, fmt.Errorf("failed to calculate excess return: %w", err)
+		}
+		if excess[i].Cmp(Zero) < 0 {
+			square, err := excess[i].Mul(excess[i])
+			if err != nil {
+				return nil, fmt.Errorf("failed to calculate downside deviation: %w", err)
+			}
+			if shortfall, err = shortfall.Add(square); err != nil {
+				return nil, fmt.Errorf("failed to calculate downside deviation: %w", err)
+			}
+		}
+	}
+	meanExcess, err := mean(excess)
+	if err != nil {
+		return nil, err
+	}
+	if metrics.SharpeRatio, err = annualizedRatio(meanExcess, deviation, yearRoot); err != nil {
+		return nil, err
+	}
+	downside, err := shortfall.Div(NewDecimalFromInt(int64(len(values))))
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate downside deviation: %w", err)
+	}
+	if downside, err = downside.Sqrt(); err != nil {
+		return nil, fmt.Errorf("failed to calculate downside deviation: %w", err)
+	}
+	if metrics.SortinoRatio, err = annualizedRatio(meanExcess, downside, yearRoot); err != nil {
+		return nil, err
+	}
+
+	if benchmarkISIN != "" {
+		if metrics.Beta, err = beta(returns, history, benchmarkISIN); err != nil {
+			return nil, err
+		}
+	}
+	return metrics, nil
+}
+
+// periodReturns returns the time-weighted return of every sub-period of
+// points, skipping those that start with nothing held.
+func periodReturns(points []ValuationPoint) ([]periodReturn, error) {
+	one := NewDecimalFromInt(1)
+	var returns []periodReturn
+	for i := 1; i < len(points); i++ {
+		previous := points[i-1].Value
+		if previous.Cmp(Zero) <= 0 {
+			continue
+		}
+		before, err := points[i].Value.Sub(points[i].Flow)
+		if err != nil {
+			return nil, fmt.Errorf("failed to remove cash flow: %w", err)
+		}
+		factor, err := before.Div(previous)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate sub-period return: %w", err)
+		}
+		value, err := factor.Sub(one)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate sub-period return: %w", err)
+		}
+		returns = append(returns, periodReturn{start: points[i-1].Date, end: points[i].Date, value: value})
+	}
+	return returns, nil
+}
+
+// maxDrawdown chains the returns into a growth index and finds its largest
+// fall from a running peak.
+func maxDrawdown(returns []periodReturn) (Drawdown, error) {
+	one := NewDecimalFromInt(1)
+	growth, peak := one, one
+	peakDate := returns[0].start
+	drawdown := Drawdown{Depth: Zero}
+	for _, r := range returns {
+		factor, err := one.Add(r.value)
+		if err != nil {
+			return Drawdown{}, fmt.Errorf("failed to calculate growth: %w", err)
+		}
+		if growth, err = growth.Mul(factor); err != nil {
+			return Drawdown{}, fmt.Errorf("failed to chain growth: %w", err)
+		}
+		if growth.Cmp(peak) >= 0 {
+			peak, peakDate = growth, r.end
+			if drawdown.Trough != nil && drawdown.Recovery == nil {
+				recovery := r.end
+				drawdown.Recovery = &recovery
+			}
+			continue
+		}
+
+		ratio, err := growth.Div(peak)
+		if err != nil {
+			return Drawdown{}, fmt.Errorf("failed to calculate drawdown: %w", err)
+		}
+		depth, err := ratio.Sub(one)
+		if err != nil {
+			return Drawdown{}, fmt.Errorf("failed to calculate drawdown: %w", err)
+		}
+		if depth, err = asPercent(depth); err != nil {
+			return Drawdown{}, err
+		}
+		if depth.Cmp(drawdown.Depth) < 0 {
+			peakDay, trough := peakDate, r.end
+			drawdown = Drawdown{Depth: depth, Peak: &peakDay, Trough: &trough}
+		}
+	}
+	return drawdown, nil
+}
+
+// beta regresses the returns on the price returns of the benchmark over
+// the same sub-periods, using only those ending on a day the benchmark has
+// a close. It is nil when fewer than two such sub-periods remain or the
+// benchmark did not move.
+func beta(returns []periodReturn, history PriceHistory, isin string) (*Decimal, error) {
+	one := NewDecimalFromInt(1)
+	var own, market []Decimal
+	for _, r := range returns {
+		end, ok := history.CloseOn(isin, r.end)
+		if !ok || !sameDate(end.Date, r.end) {
+			continue
+		}
+		start, ok := history.CloseOn(isin, r.start)
+		if !ok || !start.Date.Before(end.Date) || start.Close.IsZero() {
+			continue
+		}
+		ratio, err := end.Close.Div(start.Close)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate benchmark return: %w", err)
+		}
+		benchmarkReturn, err := ratio.Sub(one)
+		if err != nil {
+			return nil, fmt.Errorf("failed to calculate benchmark return: %w", err)
+		}
+		own = append(own, r.value)
+		market = append(market, benchmarkReturn)
+	}
+	if len(market) < 2 {
+		return nil, nil
+	}
+
+	variance, err := covariance(market, market)
+	if err != nil {
+		return nil, err
+	}
+	if variance.IsZero() {
+		return nil, nil
+	}
+	cov, err := covariance(own, market)
+	if err != nil {
+		return nil, err
+	}
+	result, err := cov.Div(variance)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate beta: %w", err)
+	}
+	return &result, nil
+}
+
+// annualizedRatio divides the mean daily excess return by a daily
+// deviation and scales it to a year. It is nil when the deviation is zero.
+func annualizedRatio(meanExcess, deviation, yearRoot Decimal) (*Decimal, error) {
+	if deviation.IsZero() {
+		return nil, nil
+	}
+	ratio, err := meanExcess.Div(deviation)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate ratio: %w", err)
+	}
+	if ratio, err = ratio.Mul(yearRoot); err != nil {
+		return nil, fmt.Errorf("failed to annualize ratio: %w", err)
+	}
+	return &ratio, nil
+}
+
+func mean(values []Decimal) (Decimal, error) {
+	sum := Zero
+	var err error
+	for _, v := range values {
+		if sum, err = sum.Add(v); err != nil {
+			return Zero, fmt.Errorf("failed to sum values: %w", err)
+		}
+	}
+	result, err := sum.Div(NewDecimalFromInt(int64(len(values))))
+	if err != nil {
+		return Zero, fmt.Errorf("failed to calculate mean: %w", err)
+	}
+	return result, nil
+}
+
+// covariance returns the sample covariance of two series of equal length,
+// which is the sample variance when both are the same.
+func covariance(xs, ys []Decimal) (Decimal, error) {
+	meanX, err := mean(xs)
+	if err != nil {
+		return Zero, err
+	}
+	meanY, err := mean(ys)
+	if err != nil {
+		return Zero, err
+	}
+	sum := Zero
+	for i := range xs {
+		dx, err := xs[i].Sub(meanX)
+		if err != nil {
+			return Zero, fmt.Errorf("failed to calculate deviation: %w", err)
+		}
+		dy, err := ys[i].Sub(meanY)
+		if err != nil {
+			return Zero, fmt.Errorf("failed to calculate deviation: %w", err)
+		}
+		product, err := dx.Mul(dy)
+		if err != nil {
+			return Zero, fmt.Errorf("failed to calculate covariance: %w", err)
+		}
+		if sum, err = sum.Add(product); err != nil {
+			return Zero, fmt.Errorf("failed to calculate covariance: %w", err)
+		}
+	}
+	result, err := sum.Div(NewDecimalFromInt(int64(len(xs) - 1)))
+	if err != nil {
+		return Zero, fmt.Errorf("failed to calculate covariance: %w", err)
+	}
+	return result, nil
+}
@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMeasureRisk(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	points := []ValuationPoint{
+		{Date: day(4), Value: NewDecimalFromInt(100), Flow: NewDecimalFromInt(100)},
+		{Date: day(5), Value: NewDecimalFromInt(110), Flow: Zero},
+		{Date: day(6), Value: NewDecimalFromInt(99), Flow: Zero},
+		// A deposit is not a gain
+		{Date: day(7), Value: NewDecimalFromInt(198), Flow: NewDecimalFromInt(99)},
+		{Date: day(8), Value: NewDecimalFromInt(231), Flow: Zero},
+	}
+	history := NewPriceHistory([]PricePoint{
+		NewPricePoint("IE00B4L5Y983", day(4), NewDecimalFromInt(50), "USD", "quote"),
+		NewPricePoint("IE00B4L5Y983", day(5), NewDecimalFromInt(55), "USD", "quote"),
+		NewPricePoint("IE00B4L5Y983", day(6), mustDecimalFromString("49.5"), "USD", "quote"),
+		NewPricePoint("IE00B4L5Y983", day(7), mustDecimalFromString("49.5"), "USD", "quote"),
+		NewPricePoint("IE00B4L5Y983", day(8), mustDecimalFromString("57.75"), "USD", "quote"),
+	})
+
+	metrics, err := MeasureRisk(points, Zero, history, "IE00B4L5Y983")
+	if err != nil {
+		t.Fatalf("MeasureRisk failed: %v", err)
+	}
+	if metrics.Observations != 4 {
+		t.Errorf("expected 4 daily returns, got %d", metrics.Observations)
+	}
+	expectRounded(t, "volatility", metrics.Volatility, "185.20")
+	if metrics.SharpeRatio == nil || metrics.SortinoRatio == nil || metrics.Beta == nil {
+		t.Fatalf("expected Sharpe, Sortino and beta, got %+v", metrics)
+	}
+	expectRounded(t, "sharpe ratio", *metrics.SharpeRatio, "5.67")
+	expectRounded(t, "sortino ratio", *metrics.SortinoRatio, "13.23")
+	expectRounded(t, "beta", *metrics.Beta, "1.00")
+
+	drawdown := metrics.MaxDrawdown
+	if !drawdown.Depth.Equal(NewDecimalFromInt(-10)) {
+		t.Errorf("expected a 10%% drawdown, got %s", drawdown.Depth)
+	}
+	if drawdown.Peak == nil || !drawdown.Peak.Equal(day(5)) || drawdown.Trough == nil || !drawdown.Trough.Equal(day(6)) {
+		t.Errorf("expected the drawdown from Mar 5 to Mar 6, got %v to %v", drawdown.Peak, drawdown.Trough)
+	}
+	if drawdown.Recovery == nil || !drawdown.Recovery.Equal(day(8)) {
+		t.Errorf("expected the peak to be regained on Mar 8, got %v", drawdown.Recovery)
+	}
+
+	// The risk-free rate lowers the Sharpe ratio
+	metrics, err = MeasureRisk(points, NewDecimalFromInt(5), nil, "")
+	if err != nil {
+		t.Fatalf("MeasureRisk failed: %v", err)
+	}
+	expectRounded(t, "sharpe ratio", *metrics.SharpeRatio, "5.64")
+	if metrics.Beta != nil {
+		t.Errorf("expected no beta without a benchmark, got %s", metrics.Beta)
+	}
+
+	if _, err := MeasureRisk(points[:2], Zero, nil, ""); !errors.Is(err, ErrInsufficientHistory) {
+		t.Errorf("expected ErrInsufficientHistory for a single return, got %v", err)
+	}
+}
+
+func TestMeasureRisk_NoVariation(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 3, d, 0, 0, 0, 0, time.UTC) }
+	points := []ValuationPoint{
+		{Date: day(4), Value: NewDecimalFromInt(100), Flow: NewDecimalFromInt(100)},
+		{Date: day(5), Value: NewDecimalFromInt(100), Flow: Zero},
+		{Date: day(6), Value: NewDecimalFromInt(100), Flow: Zero},
+	}
+	metrics, err := MeasureRisk(points, Zero, nil, "")
+	if err != nil {
+		t.Fatalf("MeasureRisk failed: %v", err)
+	}
+	if !metrics.Volatility.IsZero() || !metrics.MaxDrawdown.Depth.IsZero() || metrics.MaxDrawdown.Peak != nil {
+		t.Errorf("expected no volatility or drawdown, got %+v", metrics)
+	}
+	if metrics.SharpeRatio != nil || metrics.SortinoRatio != nil {
+		t.Errorf("expected no ratios without variation, got %+v", metrics)
+	}
+}
+
+func TestPortfolio_Risk(t *testing.T) {
+	p := newPerformancePortfolio(t)
+	asOf := time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC)
+	day := func(month time.Month, d int) time.Time { return time.Date(2024, month, d, 0, 0, 0, 0, time.UTC) }
+	history := NewPriceHistory([]PricePoint{
+		NewPricePoint("US001", day(time.January, 31), NewDecimalFromInt(120), "USD", "quote"),
+		NewPricePoint("US001", day(time.February, 29), NewDecimalFromInt(100), "USD", "quote"),
+	})
+
+	report, err := p.Risk(PeriodSinceInception, asOf, NewExchangeRates("USD"), history, nil, NewDecimalFromInt(2))
+	if err != nil {
+		t.Fatalf("Risk failed: %v", err)
+	}
+	if !report.From.Equal(day(time.January, 10)) || report.Currency != "USD" || !report.RiskFreeRate.Equal(NewDecimalFromInt(2)) {
+		t.Errorf("unexpected report header: %+v", report)
+	}
+	// Jan 10, Jan 31, Feb 10, Feb 29 and Mar 15 give four returns
+	if report.Observations != 4 {
+		t.Errorf("expected 4 daily returns, got %d", report.Observations)
+	}
+	// Growth peaks at 1.2 on Jan 31 and ends at 0.99
+	expectRounded(t, "max drawdown", report.MaxDrawdown.Depth, "-17.50")
+	if report.MaxDrawdown.Trough == nil || !report.MaxDrawdown.Trough.Equal(asOf) || report.MaxDrawdown.Recovery != nil {
+		t.Errorf("expected an unrecovered drawdown to Mar 15, got %+v", report.MaxDrawdown)
+	}
+
+	if len(report.Positions) != 1 || report.Positions[0].RiskMetrics == nil {
+		t.Fatalf("expected metrics for one position, got %+v", report.Positions)
+	}
+	if !report.Positions[0].Volatility.Equal(report.Volatility) {
+		t.Errorf("expected the only position to be as volatile as the portfolio, got %s and %s", report.Positions[0].Volatility, report.Volatility)
+	}
+
+	// The one month window starts from the value of Feb 10 carried to Feb 15
+	report, err = p.Risk(PeriodOneMonth, asOf, NewExchangeRates("USD"), history, nil, Zero)
+	if err != nil {
+		t.Fatalf("Risk failed: %v", err)
+	}
+	if !report.From.Equal(day(time.February, 15)) || report.Observations != 2 {
+		t.Errorf("expected 2 returns from Feb 15, got %d from %s", report.Observations, report.From)
+	}
+}
+
+func expectRounded(t *testing.T, name string, actual Decimal, expected string) {
+	t.Helper()
+	rounded, err := actual.Round(2)
+	if err != nil {
+		t.Fatalf("failed to round %s: %v", name, err)
+	}
+	if !rounded.Equal(mustDecimalFromString(expected)) {
+		t.Errorf("expected %s of %s, got %s", name, expected, actual)
+	}
+}
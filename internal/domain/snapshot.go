@@ -0,0 +1,262 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+var ErrInvalidSnapshotInterval = errors.New("invalid snapshot interval")
+
+// SnapshotInterval is the spacing of the points in a portfolio history.
+type SnapshotInterval string
+
+const (
+	IntervalDay   SnapshotInterval = "day"
+	IntervalWeek  SnapshotInterval = "week"
+	IntervalMonth SnapshotInterval = "month"
+)
+
+// ParseSnapshotInterval accepts an interval name in any case. An empty
+// name means daily.
+func ParseSnapshotInterval(name string) (SnapshotInterval, error) {
+	if name == "" {
+		return IntervalDay, nil
+	}
+	interval := SnapshotInterval(strings.ToLower(name))
+	switch interval {
+	case IntervalDay, IntervalWeek, IntervalMonth:
+		return interval, nil
+	}
+	return "", fmt.Errorf("%w: %s", ErrInvalidSnapshotInterval, name)
+}
+
+// bucket returns a key shared by the dates in the same interval.
+func (i SnapshotInterval) bucket(date time.Time) string {
+	switch i {
+	case IntervalWeek:
+		year, week := date.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case IntervalMonth:
+		return date.Format("2006-01")
+	default:
+		return date.Format(time.DateOnly)
+	}
+}
+
+// PositionSnapshot is the valuation of an open position at the end of a
+// day. Price is in the currency the instrument is valued in; Value and
+// Invested are in the base currency of the portfolio.
+type PositionSnapshot struct {
+	PositionID    string  `json:"position_id"`
+	ISIN          string  `json:"isin"`
+	Quantity      Decimal `json:"quantity"`
+	Price         Decimal `json:"price"`
+	PriceCurrency string  `json:"price_currency"`
+	Value         Decimal `json:"value"`
+	Invested      Decimal `json:"invested"`
+}
+
+// PortfolioSnapshot is the valuation of a portfolio at the end of a day in
+// its base currency. NetFlow holds the external cash flows of the day, as
+// in ValuationPoint, so that returns can be chained from snapshots alone.
+type PortfolioSnapshot struct {
+	PortfolioID     string             `json:"portfolio_id"`
+	Date            time.Time          `json:"date"`
+	Currency        string             `json:"currency"`
+	TotalValue      Decimal            `json:"total_value"`
+	TotalCash       Decimal            `json:"total_cash"`
+	TotalInvested   Decimal            `json:"total_invested"`
+	TotalProfitLoss Decimal            `json:"total_profit_loss"`
+	NetFlow         Decimal            `json:"net_flow"`
+	Positions       []PositionSnapshot `json:"positions"`
+	TakenAt         time.Time          `json:"taken_at"`
+}
+
+// Snapshot values the portfolio at asOf in the target currency of rates.
+// Only one snapshot is kept per portfolio and day, so a later snapshot of
+// the same day replaces an earlier one.
+func (p *Portfolio) Snapshot(asOf time.Time, rates *ExchangeRates) (*PortfolioSnapshot, error) {
+	valuation, err := p.Valuate(rates)
+	if err != nil {
+		return nil, err
+	}
+
+	series, err := p.ValuationSeries("", asOf, rates)
+	if err != nil {
+		return nil, err
+	}
+	flow := Zero
+	for _, point := range series {
+		if !sameDate(point.Date, asOf) {
+			continue
+		}
+		if flow, err = flow.Add(point.Flow); err != nil {
+			return nil, fmt.Errorf("failed to add cash flow: %w", err)
+		}
+	}
+
+	y, m, d := asOf.Date()
+	snapshot := &PortfolioSnapshot{
+		PortfolioID:     p.ID,
+		Date:            time.Date(y, m, d, 0, 0, 0, 0, time.UTC),
+		Currency:        rates.Target,
+		TotalValue:      valuation.TotalValue,
+		TotalCash:       valuation.TotalCash,
+		TotalInvested:   valuation.TotalInvested,
+		TotalProfitLoss: valuation.TotalProfitLoss,
+		NetFlow:         flow,
+		Positions:       []PositionSnapshot{},
+		TakenAt:         asOf,
+	}
+	for i := range p.Positions {
+		pos := &p.Positions[i]
+		if pos.IsClosed() {
+			continue
+		}
+		value, err := pos.CurrentValue()
+		if err != nil {
+			return nil, err
+		}
+		if value, err = rates.ConvertMoney(value); err != nil {
+			return nil, fmt.Errorf("failed to convert value of %s: %w", pos.Instrument.ISIN, err)
+		}
+		invested, err := rates.ConvertMoney(pos.InvestedAmount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert invested amount of %s: %w", pos.Instrument.ISIN, err)
+		}
+		snapshot.Positions = append(snapshot.Positions, PositionSnapshot{
+			PositionID:    pos.ID,
+			ISIN:          pos.Instrument.ISIN,
+			Quantity:      pos.Quantity,
+			Price:         pos.CurrentPrice,
+			PriceCurrency: pos.ValueCurrency(),
+			Value:         value.Amount,
+			Invested:      invested.Amount,
+		})
+	}
+	return snapshot, nil
+}
+
+// SampleSnapshots keeps the last snapshot of each interval, in date order.
+// The cash flows of the snapshots it drops are added to the one kept, as if
+// they were made at the end of the interval.
+func SampleSnapshots(snapshots []PortfolioSnapshot, interval SnapshotInterval) ([]PortfolioSnapshot, error) {
+	sampled := make([]PortfolioSnapshot, 0, len(snapshots))
+	flow := Zero
+	for i := range snapshots {
+		var err error
+		if flow, err = flow.Add(snapshots[i].NetFlow); err != nil {
+			return nil, fmt.Errorf("failed to add cash flow: %w", err)
+		}
+		if i+1 < len(snapshots) && interval.bucket(snapshots[i+1].Date) == interval.bucket(snapshots[i].Date) {
+			continue
+		}
+		snapshot := snapshots[i]
+		snapshot.NetFlow = flow
+		sampled = append(sampled, snapshot)
+		flow = Zero
+	}
+	return sampled, nil
+}
+
+// PortfolioHistory is the valuation of a portfolio over time. Return is
+// the time-weighted return from the first snapshot to the last, as a
+// percentage.
+type PortfolioHistory struct {
+	Currency  string              `json:"currency"`
+	Interval  SnapshotInterval    `json:"interval"`
+	From      time.Time           `json:"from"`
+	To        time.Time           `json:"to"`
+	Return    Decimal             `json:"return"`
+	Snapshots []PortfolioSnapshot `json:"snapshots"`
+}
+
+// NewPortfolioHistory samples snapshots, in date order, at interval and
+// measures their return, which is zero with fewer than two snapshots.
+func NewPortfolioHistory(currency string, interval SnapshotInterval, from, to time.Time, snapshots []PortfolioSnapshot) (*PortfolioHistory, error) {
+	sampled, err := SampleSnapshots(snapshots, interval)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &PortfolioHistory{
+		Currency:  currency,
+		Interval:  interval,
+		From:      from,
+		To:        to,
+		Return:    Zero,
+		Snapshots: sampled,
+	}
+	if len(sampled) < 2 {
+		return history, nil
+	}
+
+	points := make([]ValuationPoint, len(sampled))
+	for i := range sampled {
+		points[i] = ValuationPoint{Date: sampled[i].Date, Value: sampled[i].TotalValue, Flow: sampled[i].NetFlow}
+	}
+	twr, err := TimeWeightedReturn(points)
+	if err != nil {
+		return nil, err
+	}
+	if history.Return, err = asPercent(twr); err != nil {
+		return nil, err
+	}
+	return history, nil
+}
+
+// withSnapshots values series at the stored snapshots in currency: a
+// snapshot replaces the value and cash flow of the point on its day and,
+// when add is set, becomes a point of its own on days series has none.
+// The last point, valued at the current prices, is kept as it is.
+func withSnapshots(series []ValuationPoint, snapshots []PortfolioSnapshot, currency string, add bool) []ValuationPoint {
+	if len(series) == 0 || len(snapshots) == 0 {
+		return series
+	}
+	last := series[len(series)-1]
+	byDay := make(map[string]*PortfolioSnapshot, len(snapshots))
+	for i := range snapshots {
+		if snapshots[i].Currency == currency && snapshots[i].Date.Before(dateOnly(last.Date)) {
+			byDay[snapshots[i].Date.Format(time.DateOnly)] = &snapshots[i]
+		}
+	}
+	if len(byDay) == 0 {
+		return series
+	}
+
+	// A snapshot holds the flows of its whole day, so it stands in for
+	// every point of that day
+	result := make([]ValuationPoint, 0, len(series)+len(byDay))
+	used := make(map[string]bool, len(byDay))
+	for _, point := range series[:len(series)-1] {
+		day := point.Date.Format(time.DateOnly)
+		if snapshot, ok := byDay[day]; ok {
+			if !used[day] {
+				used[day] = true
+				result = append(result, ValuationPoint{Date: point.Date, Value: snapshot.TotalValue, Flow: snapshot.NetFlow})
+			}
+			continue
+		}
+		result = append(result, point)
+	}
+	if add {
+		for day, snapshot := range byDay {
+			if !used[day] && !snapshot.Date.Before(dateOnly(series[0].Date)) {
+				result = append(result, ValuationPoint{Date: snapshot.Date, Value: snapshot.TotalValue, Flow: snapshot.NetFlow})
+			}
+		}
+		sort.SliceStable(result, func(i, j int) bool {
+			return result[i].Date.Before(result[j].Date)
+		})
+	}
+	return append(result, last)
+}
+
+// dateOnly returns the UTC midnight of t's calendar day.
+func dateOnly(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
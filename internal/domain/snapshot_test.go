@@ -0,0 +1,114 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseSnapshotInterval(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected SnapshotInterval
+		err      error
+	}{
+		{"", IntervalDay, nil},
+		{"Week", IntervalWeek, nil},
+		{"month", IntervalMonth, nil},
+		{"year", "", ErrInvalidSnapshotInterval},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			interval, err := ParseSnapshotInterval(tc.input)
+			if !errors.Is(err, tc.err) || interval != tc.expected {
+				t.Errorf("expected %q, %v; got %q, %v", tc.expected, tc.err, interval, err)
+			}
+		})
+	}
+}
+
+func TestPortfolio_Snapshot(t *testing.T) {
+	p := newPerformancePortfolio(t)
+
+	// The second buy of 1100 is the cash flow of its day
+	snapshot, err := p.Snapshot(time.Date(2024, 2, 10, 18, 30, 0, 0, time.UTC), NewExchangeRates("USD"))
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if !snapshot.Date.Equal(time.Date(2024, 2, 10, 0, 0, 0, 0, time.UTC)) || snapshot.PortfolioID != p.ID || snapshot.Currency != "USD" {
+		t.Errorf("unexpected snapshot header %+v", snapshot)
+	}
+	if !snapshot.TotalValue.Equal(NewDecimalFromInt(1980)) || !snapshot.TotalInvested.Equal(NewDecimalFromInt(2100)) ||
+		!snapshot.TotalProfitLoss.Equal(NewDecimalFromInt(-120)) || !snapshot.NetFlow.Equal(NewDecimalFromInt(1100)) {
+		t.Errorf("unexpected snapshot totals %+v", snapshot)
+	}
+	if len(snapshot.Positions) != 1 {
+		t.Fatalf("expected 1 position, got %+v", snapshot.Positions)
+	}
+	pos := snapshot.Positions[0]
+	if pos.ISIN != "US001" || !pos.Quantity.Equal(NewDecimalFromInt(20)) || !pos.Price.Equal(NewDecimalFromInt(99)) ||
+		pos.PriceCurrency != "USD" || !pos.Value.Equal(NewDecimalFromInt(1980)) || !pos.Invested.Equal(NewDecimalFromInt(2100)) {
+		t.Errorf("unexpected position snapshot %+v", pos)
+	}
+
+	later, err := p.Snapshot(time.Date(2024, 3, 15, 0, 0, 0, 0, time.UTC), NewExchangeRates("USD"))
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if !later.NetFlow.IsZero() {
+		t.Errorf("expected no cash flow without trades, got %s", later.NetFlow)
+	}
+}
+
+func TestNewPortfolioHistory(t *testing.T) {
+	snapshot := func(date time.Time, value, flow int64) PortfolioSnapshot {
+		return PortfolioSnapshot{Date: date, Currency: "USD", TotalValue: NewDecimalFromInt(value), NetFlow: NewDecimalFromInt(flow)}
+	}
+	snapshots := []PortfolioSnapshot{
+		snapshot(day(2024, time.January, 30), 1000, 1000),
+		snapshot(day(2024, time.January, 31), 1100, 0),
+		// Grew to 1100, then 1100 more was invested
+		snapshot(day(2024, time.February, 1), 2200, 1100),
+		snapshot(day(2024, time.February, 2), 1980, 0),
+	}
+	from, to := day(2024, time.January, 1), day(2024, time.February, 29)
+
+	testCases := []struct {
+		interval SnapshotInterval
+		dates    []time.Time
+		expected int64
+	}{
+		{IntervalDay, []time.Time{day(2024, time.January, 30), day(2024, time.January, 31), day(2024, time.February, 1), day(2024, time.February, 2)}, -1},
+		// 2024-01-29 to 2024-02-04 is one ISO week
+		{IntervalWeek, []time.Time{day(2024, time.February, 2)}, 0},
+		// The February flow is taken at the end of the month
+		{IntervalMonth, []time.Time{day(2024, time.January, 31), day(2024, time.February, 2)}, -20},
+	}
+	for _, tc := range testCases {
+		t.Run(string(tc.interval), func(t *testing.T) {
+			history, err := NewPortfolioHistory("USD", tc.interval, from, to, snapshots)
+			if err != nil {
+				t.Fatalf("NewPortfolioHistory failed: %v", err)
+			}
+			if len(history.Snapshots) != len(tc.dates) {
+				t.Fatalf("expected %d snapshots, got %+v", len(tc.dates), history.Snapshots)
+			}
+			for i, date := range tc.dates {
+				if !history.Snapshots[i].Date.Equal(date) {
+					t.Errorf("expected snapshot %d on %s, got %s", i, date, history.Snapshots[i].Date)
+				}
+			}
+			if !history.Return.Equal(NewDecimalFromInt(tc.expected)) {
+				t.Errorf("expected %d%%, got %s", tc.expected, history.Return)
+			}
+		})
+	}
+
+	sampled, err := SampleSnapshots(snapshots, IntervalMonth)
+	if err != nil {
+		t.Fatalf("SampleSnapshots failed: %v", err)
+	}
+	if !sampled[0].NetFlow.Equal(NewDecimalFromInt(1000)) || !sampled[1].NetFlow.Equal(NewDecimalFromInt(1100)) {
+		t.Errorf("expected the flows of each month added up, got %+v", sampled)
+	}
+}
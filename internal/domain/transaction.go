@@ -0,0 +1,125 @@
+package domain
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidTransaction   = errors.New("invalid transaction")
+	ErrInsufficientQuantity = errors.New("insufficient quantity")
+)
+
+type TransactionType string
+
+const (
+	TransactionTypeBuy         TransactionType = "buy"
+	TransactionTypeSell        TransactionType = "sell"
+	TransactionTypeDividend    TransactionType = "dividend"
+	TransactionTypeFee         TransactionType = "fee"
+	TransactionTypeTransferIn  TransactionType = "transfer_in"
+	TransactionTypeTransferOut TransactionType = "transfer_out"
+	TransactionTypeDeposit     TransactionType = "deposit"
+	TransactionTypeWithdrawal  TransactionType = "withdrawal"
+)
+
+// IsValid reports whether the type is one of the supported ledger entry types.
+func (t TransactionType) IsValid() bool {
+	switch t {
+	case TransactionTypeBuy, TransactionTypeSell, TransactionTypeDividend,
+		TransactionTypeFee, TransactionTypeTransferIn, TransactionTypeTransferOut,
+		TransactionTypeDeposit, TransactionTypeWithdrawal:
+		return true
+	}
+	return false
+}
+
+// IncreasesQuantity reports whether the entry adds units to a position.
+func (t TransactionType) IncreasesQuantity() bool {
+	return t == TransactionTypeBuy || t == TransactionTypeTransferIn
+}
+
+// DecreasesQuantity reports whether the entry removes units from a position.
+func (t TransactionType) DecreasesQuantity() bool {
+	return t == TransactionTypeSell || t == TransactionTypeTransferOut
+}
+
+// IsTrade reports whether entries of this type change the quantity held.
+func (t TransactionType) IsTrade() bool {
+	return t.IncreasesQuantity() || t.DecreasesQuantity()
+}
+
+// IsCashMovement reports whether entries of this type move money into or
+// out of the portfolio without involving an instrument.
+func (t TransactionType) IsCashMovement() bool {
+	return t == TransactionTypeDeposit || t == TransactionTypeWithdrawal
+}
+
+// Transaction is a single entry in the portfolio ledger.
+// Positions are derived by replaying the ledger in trade date order.
+// Amount is the gross cash value of the entry in Currency: quantity * price
+// for trades, or the cash amount for dividends, fees, deposits and
+// withdrawals. Deposits and withdrawals have no instrument.
+// Fee and Tax are the charges paid on a trade in the currency they were
+// charged in, and Costs is their total in Currency.
+// LotID selects the lot a sale consumes under specific identification.
+// CostBasisMethod is the policy a sale or outbound transfer was booked
+// under, so that replaying the ledger consumes the same lots.
+type Transaction struct {
+	ID              string          `json:"id"`
+	PortfolioID     string          `json:"-"`
+	PositionID      string          `json:"position_id,omitempty"`
+	InstrumentISIN  string          `json:"isin"`
+	Type            TransactionType `json:"type"`
+	TradeDate       time.Time       `json:"trade_date"`
+	Quantity        Decimal         `json:"quantity"`
+	Price           Decimal         `json:"price"`
+	Amount          Decimal         `json:"amount"`
+	Currency        string          `json:"currency"`
+	Fee             Money           `json:"fee"`
+	Tax             Money           `json:"tax"`
+	Costs           Decimal         `json:"costs"`
+	LotID           string          `json:"lot_id,omitempty"`
+	CostBasisMethod CostBasisMethod `json:"cost_basis_method,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+func NewTransaction(txType TransactionType, isin string, tradeDate time.Time, quantity, price, amount Decimal, currency string) Transaction {
+	return Transaction{
+		ID:             uuid.New().String(),
+		InstrumentISIN: isin,
+		Type:           txType,
+		TradeDate:      tradeDate,
+		Quantity:       quantity,
+		Price:          price,
+		Amount:         amount,
+		Currency:       currency,
+		Fee:            ZeroMoney(currency),
+		Tax:            ZeroMoney(currency),
+		Costs:          Zero,
+		CreatedAt:      time.Now(),
+	}
+}
+
+// IsTrade reports whether the entry changes the quantity held.
+func (t *Transaction) IsTrade() bool {
+	return t.Type.IsTrade()
+}
+
+func (t *Transaction) IsValid() bool {
+	if t.ID == "" || t.Currency == "" || !t.Type.IsValid() || t.TradeDate.IsZero() {
+		return false
+	}
+	if (t.InstrumentISIN == "") != t.Type.IsCashMovement() {
+		return false
+	}
+	if t.Costs.Cmp(Zero) < 0 || t.Fee.Amount.Cmp(Zero) < 0 || t.Tax.Amount.Cmp(Zero) < 0 {
+		return false
+	}
+	if t.IsTrade() {
+		return t.Quantity.Cmp(Zero) > 0 && t.Price.Cmp(Zero) >= 0
+	}
+	return t.Amount.Cmp(Zero) > 0
+}
@@ -0,0 +1,267 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func newBuy(isin string, quantity, price int64, date time.Time) Transaction {
+	amount := NewDecimalFromInt(quantity * price)
+	return NewTransaction(TransactionTypeBuy, isin, date, NewDecimalFromInt(quantity), NewDecimalFromInt(price), amount, "USD")
+}
+
+// --- Transaction Tests ---
+
+func TestTransaction_IsValid(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name string
+		tx   Transaction
+		want bool
+	}{
+		{"valid buy", newBuy("US001", 10, 100, now), true},
+		{"zero quantity buy", NewTransaction(TransactionTypeBuy, "US001", now, Zero, NewDecimalFromInt(100), Zero, "USD"), false},
+		{"valid dividend", NewTransaction(TransactionTypeDividend, "US001", now, Zero, Zero, NewDecimalFromInt(5), "USD"), true},
+		{"zero amount fee", NewTransaction(TransactionTypeFee, "US001", now, Zero, Zero, Zero, "USD"), false},
+		{"unknown type", NewTransaction("swap", "US001", now, NewDecimalFromInt(1), NewDecimalFromInt(1), NewDecimalFromInt(1), "USD"), false},
+		{"missing currency", NewTransaction(TransactionTypeBuy, "US001", now, NewDecimalFromInt(1), NewDecimalFromInt(1), NewDecimalFromInt(1), ""), false},
+		{"valid deposit", NewTransaction(TransactionTypeDeposit, "", now, Zero, Zero, NewDecimalFromInt(500), "EUR"), true},
+		{"withdrawal with instrument", NewTransaction(TransactionTypeWithdrawal, "US001", now, Zero, Zero, NewDecimalFromInt(500), "EUR"), false},
+		{"buy without instrument", NewTransaction(TransactionTypeBuy, "", now, NewDecimalFromInt(1), NewDecimalFromInt(1), NewDecimalFromInt(1), "USD"), false},
+		{"missing trade date", NewTransaction(TransactionTypeBuy, "US001", time.Time{}, NewDecimalFromInt(1), NewDecimalFromInt(1), NewDecimalFromInt(1), "USD"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.tx.IsValid(); got != tt.want {
+				t.Errorf("IsValid() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// --- RecordTransaction Tests ---
+
+func TestRecordTransaction_BuyOpensPosition(t *testing.T) {
+	p := NewPortfolio("Test")
+	inst := NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+
+	pos, err := p.RecordTransaction(inst, newBuy("US001", 10, 100, time.Now()))
+	if err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+
+	if len(p.Positions) != 1 || len(p.Transactions) != 1 {
+		t.Fatalf("expected 1 position and 1 transaction, got %d and %d", len(p.Positions), len(p.Transactions))
+	}
+	if !pos.Quantity.Equal(NewDecimalFromInt(10)) {
+		t.Errorf("expected quantity 10, got %s", pos.Quantity)
+	}
+	if !pos.InvestedAmount.Amount.Equal(NewDecimalFromInt(1000)) {
+		t.Errorf("expected invested 1000, got %s", pos.InvestedAmount)
+	}
+	if p.Transactions[0].PositionID != pos.ID || p.Transactions[0].PortfolioID != p.ID {
+		t.Error("expected transaction to reference its position and portfolio")
+	}
+}
+
+func TestRecordTransaction_QuantityDoesNotDriftWithPrice(t *testing.T) {
+	p := NewPortfolio("Test")
+	inst := NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+
+	pos, err := p.RecordTransaction(inst, newBuy("US001", 10, 100, time.Now()))
+	if err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+
+	if err := pos.UpdatePrice(NewDecimalFromInt(120)); err != nil {
+		t.Fatalf("UpdatePrice failed: %v", err)
+	}
+
+	if !pos.Quantity.Equal(NewDecimalFromInt(10)) {
+		t.Errorf("expected quantity to stay 10, got %s", pos.Quantity)
+	}
+
+	profitLoss, err := pos.ProfitLoss()
+	if err != nil {
+		t.Fatalf("ProfitLoss failed: %v", err)
+	}
+	if !profitLoss.Amount.Equal(NewDecimalFromInt(200)) {
+		t.Errorf("expected P/L 200, got %s", profitLoss)
+	}
+
+	total, err := p.TotalValue()
+	if err != nil {
+		t.Fatalf("TotalValue failed: %v", err)
+	}
+	if !total.Amount.Equal(NewDecimalFromInt(1200)) {
+		t.Errorf("expected total value 1200, got %s", total)
+	}
+}
+
+func TestRecordTransaction_SellReducesAtAverageCost(t *testing.T) {
+	p := NewPortfolio("Test")
+	inst := NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+	now := time.Now()
+
+	_, _ = p.RecordTransaction(inst, newBuy("US001", 10, 100, now))
+	_, _ = p.RecordTransaction(inst, newBuy("US001", 10, 200, now))
+
+	sell := NewTransaction(TransactionTypeSell, "US001", now, NewDecimalFromInt(5), NewDecimalFromInt(250), NewDecimalFromInt(1250), "USD")
+	pos, err := p.RecordTransaction(inst, sell)
+	if err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+
+	// Average cost is 150, so 15 units remain at a cost of 2250
+	if !pos.Quantity.Equal(NewDecimalFromInt(15)) {
+		t.Errorf("expected quantity 15, got %s", pos.Quantity)
+	}
+	if !pos.InvestedAmount.Amount.Equal(NewDecimalFromInt(2250)) {
+		t.Errorf("expected invested 2250, got %s", pos.InvestedAmount)
+	}
+}
+
+func TestRecordTransaction_SellMoreThanHeld(t *testing.T) {
+	p := NewPortfolio("Test")
+	inst := NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+
+	_, _ = p.RecordTransaction(inst, newBuy("US001", 10, 100, time.Now()))
+
+	sell := NewTransaction(TransactionTypeSell, "US001", time.Now(), NewDecimalFromInt(11), NewDecimalFromInt(100), NewDecimalFromInt(1100), "USD")
+	_, err := p.RecordTransaction(inst, sell)
+	if !errors.Is(err, ErrInsufficientQuantity) {
+		t.Errorf("expected ErrInsufficientQuantity, got %v", err)
+	}
+	if len(p.Transactions) != 1 {
+		t.Errorf("expected rejected sell not to be recorded, got %d transactions", len(p.Transactions))
+	}
+}
+
+func TestRecordTransaction_SellWithoutPosition(t *testing.T) {
+	p := NewPortfolio("Test")
+	inst := NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+
+	sell := NewTransaction(TransactionTypeSell, "US001", time.Now(), NewDecimalFromInt(1), NewDecimalFromInt(100), NewDecimalFromInt(100), "USD")
+	_, err := p.RecordTransaction(inst, sell)
+	if !errors.Is(err, ErrPositionNotFound) {
+		t.Errorf("expected ErrPositionNotFound, got %v", err)
+	}
+}
+
+func TestRecordTransaction_DividendKeepsQuantity(t *testing.T) {
+	p := NewPortfolio("Test")
+	inst := NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+
+	_, _ = p.RecordTransaction(inst, newBuy("US001", 10, 100, time.Now()))
+
+	dividend := NewTransaction(TransactionTypeDividend, "US001", time.Now(), Zero, Zero, NewDecimalFromInt(12), "USD")
+	pos, err := p.RecordTransaction(inst, dividend)
+	if err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+
+	if !pos.Quantity.Equal(NewDecimalFromInt(10)) {
+		t.Errorf("expected quantity 10, got %s", pos.Quantity)
+	}
+	if len(p.Transactions) != 2 {
+		t.Errorf("expected 2 transactions, got %d", len(p.Transactions))
+	}
+}
+
+func TestRecordTransaction_Invalid(t *testing.T) {
+	p := NewPortfolio("Test")
+	inst := NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+
+	_, err := p.RecordTransaction(inst, newBuy("US002", 10, 100, time.Now()))
+	if !errors.Is(err, ErrInvalidTransaction) {
+		t.Errorf("expected ErrInvalidTransaction for ISIN mismatch, got %v", err)
+	}
+}
+
+func TestRecordTransaction_OutOfOrderMatchesReplay(t *testing.T) {
+	p := NewPortfolio("Test")
+	inst := NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+
+	_, _ = p.RecordTransaction(inst, newBuy("US001", 10, 100, time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+	sell := NewTransaction(TransactionTypeSell, "US001", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		NewDecimalFromInt(5), NewDecimalFromInt(150), NewDecimalFromInt(750), "USD")
+	_, _ = p.RecordTransaction(inst, sell)
+
+	// A buy entered late, dated before the sell
+	pos, err := p.RecordTransaction(inst, newBuy("US001", 5, 50, time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("RecordTransaction failed: %v", err)
+	}
+	live := *pos
+
+	// Sold 5 of 15 units bought for 1250
+	realized, _ := live.RealizedProfitLoss.Amount.Round(2)
+	invested, _ := live.InvestedAmount.Amount.Round(2)
+	if realized.String() != "333.33" || invested.String() != "833.33" || !live.Quantity.Equal(NewDecimalFromInt(10)) {
+		t.Errorf("expected realized 333.33, invested 833.33 and 10 units, got %s, %s and %s", realized, invested, live.Quantity)
+	}
+
+	if err := p.RebuildPositions(); err != nil {
+		t.Fatalf("RebuildPositions failed: %v", err)
+	}
+	replayed := p.Positions[0]
+	if !replayed.Quantity.Equal(live.Quantity) || !replayed.InvestedAmount.Amount.Equal(live.InvestedAmount.Amount) ||
+		!replayed.RealizedProfitLoss.Amount.Equal(live.RealizedProfitLoss.Amount) {
+		t.Errorf("expected the replay to match the recorded state, got %+v and %+v", replayed, live)
+	}
+
+	// A backdated sell that the replay cannot cover is rejected and not recorded
+	early := NewTransaction(TransactionTypeSell, "US001", time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
+		NewDecimalFromInt(1), NewDecimalFromInt(50), NewDecimalFromInt(50), "USD")
+	if _, err := p.RecordTransaction(inst, early); !errors.Is(err, ErrInsufficientQuantity) {
+		t.Errorf("expected ErrInsufficientQuantity, got %v", err)
+	}
+	if len(p.Transactions) != 3 || !p.Positions[0].Quantity.Equal(NewDecimalFromInt(10)) {
+		t.Errorf("expected the rejected sell to leave the ledger unchanged, got %d entries and %s units", len(p.Transactions), p.Positions[0].Quantity)
+	}
+}
+
+// --- RebuildPositions Tests ---
+
+func TestRebuildPositions_ReplaysLedgerInTradeDateOrder(t *testing.T) {
+	p := NewPortfolio("Test")
+	inst := NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	_, _ = p.RecordTransaction(inst, newBuy("US001", 10, 100, day))
+	_, _ = p.RecordTransaction(inst, newBuy("US001", 10, 100, day.AddDate(0, 0, 2)))
+	sell := NewTransaction(TransactionTypeSell, "US001", day.AddDate(0, 0, 1), NewDecimalFromInt(5), NewDecimalFromInt(100), NewDecimalFromInt(500), "USD")
+	_, _ = p.RecordTransaction(inst, sell)
+
+	// Corrupt the derived state
+	p.Positions[0].Quantity = NewDecimalFromInt(999)
+
+	if err := p.RebuildPositions(); err != nil {
+		t.Fatalf("RebuildPositions failed: %v", err)
+	}
+
+	if !p.Positions[0].Quantity.Equal(NewDecimalFromInt(15)) {
+		t.Errorf("expected quantity 15, got %s", p.Positions[0].Quantity)
+	}
+	if !p.Positions[0].InvestedAmount.Amount.Equal(NewDecimalFromInt(1500)) {
+		t.Errorf("expected invested 1500, got %s", p.Positions[0].InvestedAmount)
+	}
+}
+
+func TestRebuildPositions_LeavesPositionsWithoutLedger(t *testing.T) {
+	p := NewPortfolio("Test")
+	inst := NewInstrument("US001", "AAPL", "Apple", InstrumentTypeStock, "USD", "NASDAQ")
+	pos := NewPosition(inst, NewDecimalFromInt(1000), "USD")
+	_ = pos.UpdatePrice(NewDecimalFromInt(100))
+	_ = p.AddPosition(pos)
+
+	if err := p.RebuildPositions(); err != nil {
+		t.Fatalf("RebuildPositions failed: %v", err)
+	}
+
+	if !p.Positions[0].Quantity.Equal(NewDecimalFromInt(10)) {
+		t.Errorf("expected quantity 10, got %s", p.Positions[0].Quantity)
+	}
+}
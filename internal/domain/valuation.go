@@ -0,0 +1,159 @@
+package domain
+
+import "fmt"
+
+// Valuation holds the portfolio totals converted into a single currency,
+// together with the exchange rates used for the conversion.
+// TotalReturn adds dividend income net of withholding tax to the
+// realized and unrealized profit/loss. Fees summarizes the trade charges
+// already deducted from the profit/loss. TotalValue includes TotalCash.
+type Valuation struct {
+	Currency                  string     `json:"currency"`
+	TotalValue                Decimal    `json:"total_value"`
+	TotalCash                 Decimal    `json:"total_cash"`
+	TotalInvested             Decimal    `json:"total_invested"`
+	TotalProfitLoss           Decimal    `json:"total_profit_loss"`
+	TotalProfitLossPercent    Decimal    `json:"total_profit_loss_percent"`
+	TotalRealizedProfitLoss   Decimal    `json:"total_realized_profit_loss"`
+	TotalUnrealizedProfitLoss Decimal    `json:"total_unrealized_profit_loss"`
+	TotalIncome               Decimal    `json:"total_income"`
+	TotalReturn               Decimal    `json:"total_return"`
+	TotalReturnPercent        Decimal    `json:"total_return_percent"`
+	Fees                      FeeSummary `json:"fees"`
+	FXRates                   []FXRate   `json:"fx_rates"`
+}
+
+// Valuate converts every position and cash balance into the target
+// currency of rates.
+// Market values are converted from the instrument currency, while cost
+// basis and realized profit/loss are converted from the invested currency.
+func (p *Portfolio) Valuate(rates *ExchangeRates) (*Valuation, error) {
+	var values, invested, bases, realized []Money
+	for i := range p.Positions {
+		pos := &p.Positions[i]
+
+		value, err := pos.CurrentValue()
+		if err != nil {
+			return nil, err
+		}
+		if value, err = rates.ConvertMoney(value); err != nil {
+			return nil, fmt.Errorf("failed to convert value of %s: %w", pos.Instrument.ISIN, err)
+		}
+		cost, err := rates.ConvertMoney(pos.InvestedAmount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert invested amount of %s: %w", pos.Instrument.ISIN, err)
+		}
+		basis, err := pos.costBasis()
+		if err != nil {
+			return nil, err
+		}
+		if basis, err = rates.ConvertMoney(basis); err != nil {
+			return nil, fmt.Errorf("failed to convert cost basis of %s: %w", pos.Instrument.ISIN, err)
+		}
+		gain, err := rates.ConvertMoney(pos.RealizedProfitLoss)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert realized profit/loss of %s: %w", pos.Instrument.ISIN, err)
+		}
+		values = append(values, value)
+		invested = append(invested, cost)
+		bases = append(bases, basis)
+		realized = append(realized, gain)
+	}
+
+	income := make([]Money, 0, len(p.Dividends))
+	for i := range p.Dividends {
+		net, err := p.Dividends[i].NetAmount()
+		if err != nil {
+			return nil, err
+		}
+		if net, err = rates.ConvertMoney(net); err != nil {
+			return nil, fmt.Errorf("failed to convert dividend of %s: %w", p.Dividends[i].InstrumentISIN, err)
+		}
+		income = append(income, net)
+	}
+
+	holdings, err := sumMoney(rates.Target, values)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add to total: %w", err)
+	}
+	cash, err := p.Cash(rates)
+	if err != nil {
+		return nil, err
+	}
+	totalValue, err := holdings.Add(NewMoney(cash.Total, rates.Target))
+	if err != nil {
+		return nil, fmt.Errorf("failed to add cash to total: %w", err)
+	}
+	totalInvested, err := sumMoney(rates.Target, invested)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add invested amount: %w", err)
+	}
+	totalBasis, err := sumMoney(rates.Target, bases)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add cost basis: %w", err)
+	}
+	totalRealized, err := sumMoney(rates.Target, realized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add realized profit/loss: %w", err)
+	}
+	unrealized, err := holdings.Sub(totalInvested)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate unrealized profit/loss: %w", err)
+	}
+	profitLoss, err := unrealized.Add(totalRealized)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate profit/loss: %w", err)
+	}
+	totalIncome, err := sumMoney(rates.Target, income)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add income: %w", err)
+	}
+	totalReturn, err := profitLoss.Add(totalIncome)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate total return: %w", err)
+	}
+
+	fees, err := p.Fees(rates)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Valuation{
+		Currency:                  rates.Target,
+		TotalValue:                totalValue.Amount,
+		TotalCash:                 cash.Total,
+		TotalInvested:             totalInvested.Amount,
+		TotalProfitLoss:           profitLoss.Amount,
+		TotalProfitLossPercent:    Zero,
+		TotalRealizedProfitLoss:   totalRealized.Amount,
+		TotalUnrealizedProfitLoss: unrealized.Amount,
+		TotalIncome:               totalIncome.Amount,
+		TotalReturn:               totalReturn.Amount,
+		TotalReturnPercent:        Zero,
+		Fees:                      *fees,
+		FXRates:                   rates.Rates(),
+	}
+	// Percentages are measured against the cost of the units held and sold
+	if !totalBasis.IsZero() {
+		if v.TotalProfitLossPercent, err = percentOf(profitLoss, totalBasis); err != nil {
+			return nil, err
+		}
+		if v.TotalReturnPercent, err = percentOf(totalReturn, totalBasis); err != nil {
+			return nil, err
+		}
+	}
+	return v, nil
+}
+
+// percentOf expresses part as a percentage of whole.
+func percentOf(part, whole Money) (Decimal, error) {
+	ratio, err := part.Ratio(whole)
+	if err != nil {
+		return Zero, fmt.Errorf("failed to divide: %w", err)
+	}
+	result, err := ratio.Mul(NewDecimalFromInt(100))
+	if err != nil {
+		return Zero, fmt.Errorf("failed to multiply by 100: %w", err)
+	}
+	return result, nil
+}
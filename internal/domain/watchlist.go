@@ -0,0 +1,241 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrInvalidWatchlist       = errors.New("invalid watchlist")
+	ErrWatchlistNotFound      = errors.New("watchlist not found")
+	ErrWatchlistItemNotFound  = errors.New("watchlist item not found")
+	ErrDuplicateWatchlistItem = errors.New("instrument already on watchlist")
+)
+
+// Watchlist is a named list of instruments followed without holding them.
+type Watchlist struct {
+	ID        string          `json:"id"`
+	Name      string          `json:"name"`
+	Items     []WatchlistItem `json:"items"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+// WatchlistItem is an instrument on a watchlist with the latest quoted
+// price. PreviousClose is the last price quoted on an earlier day, zero
+// until the item has been quoted on two days. LastUpdated is nil until the
+// first quote.
+type WatchlistItem struct {
+	Instrument    Instrument `json:"instrument"`
+	Notes         string     `json:"notes,omitempty"`
+	TargetPrice   *Decimal   `json:"target_price,omitempty"`
+	LastPrice     Decimal    `json:"last_price"`
+	PreviousClose Decimal    `json:"previous_close"`
+	LastUpdated   *time.Time `json:"last_updated,omitempty"`
+	AddedAt       time.Time  `json:"added_at"`
+}
+
+// WatchlistItemDetails holds the fields of a watchlist item set by hand.
+// Nil fields are left unchanged; an empty note and a zero target price
+// clear them.
+type WatchlistItemDetails struct {
+	Notes       *string  `json:"notes"`
+	TargetPrice *Decimal `json:"target_price"`
+}
+
+func NewWatchlist(name string) (*Watchlist, error) {
+	now := time.Now()
+	w := &Watchlist{
+		ID:        uuid.New().String(),
+		Items:     make([]WatchlistItem, 0),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	if err := w.Rename(name); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Rename sets the name of the watchlist, which must not be blank.
+func (w *Watchlist) Rename(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidWatchlist)
+	}
+	w.Name = name
+	w.UpdatedAt = time.Now()
+	return nil
+}
+
+// AddItem puts an instrument on the watchlist, once.
+func (w *Watchlist) AddItem(instrument Instrument, details WatchlistItemDetails) (*WatchlistItem, error) {
+	if _, err := w.FindItem(instrument.ISIN); err == nil {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateWatchlistItem, instrument.ISIN)
+	}
+
+	item := WatchlistItem{Instrument: instrument, LastPrice: Zero, PreviousClose: Zero, AddedAt: time.Now()}
+	if err := item.Apply(details); err != nil {
+		return nil, err
+	}
+	w.Items = append(w.Items, item)
+	w.UpdatedAt = time.Now()
+	return &w.Items[len(w.Items)-1], nil
+}
+
+// UpdateItem changes the notes and target price of an item.
+func (w *Watchlist) UpdateItem(isin string, details WatchlistItemDetails) (*WatchlistItem, error) {
+	item, err := w.FindItem(isin)
+	if err != nil {
+		return nil, err
+	}
+	if err := item.Apply(details); err != nil {
+		return nil, err
+	}
+	w.UpdatedAt = time.Now()
+	return item, nil
+}
+
+// RemoveItem takes an instrument off the watchlist.
+func (w *Watchlist) RemoveItem(isin string) error {
+	for i := range w.Items {
+		if w.Items[i].Instrument.ISIN == isin {
+			w.Items = append(w.Items[:i], w.Items[i+1:]...)
+			w.UpdatedAt = time.Now()
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrWatchlistItemNotFound, isin)
+}
+
+func (w *Watchlist) FindItem(isin string) (*WatchlistItem, error) {
+	for i := range w.Items {
+		if w.Items[i].Instrument.ISIN == isin {
+			return &w.Items[i], nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrWatchlistItemNotFound, isin)
+}
+
+// Apply sets the notes and target price of the item. Target prices must
+// be positive.
+func (i *WatchlistItem) Apply(details WatchlistItemDetails) error {
+	if details.TargetPrice != nil {
+		switch c := details.TargetPrice.Cmp(Zero); {
+		case c < 0:
+			return fmt.Errorf("%w: target price must be positive, got %s", ErrInvalidWatchlist, details.TargetPrice)
+		case c == 0:
+			i.TargetPrice = nil
+		default:
+			target := *details.TargetPrice
+			i.TargetPrice = &target
+		}
+	}
+	if details.Notes != nil {
+		i.Notes = strings.TrimSpace(*details.Notes)
+	}
+	return nil
+}
+
+// UpdatePrice records a quote taken at now. The last price quoted on an
+// earlier day becomes the previous close.
+func (i *WatchlistItem) UpdatePrice(price Decimal, now time.Time) error {
+	if price.Cmp(Zero) <= 0 {
+		return fmt.Errorf("%w: price of %s must be positive, got %s", ErrInvalidWatchlist, i.Instrument.ISIN, price)
+	}
+	if i.LastUpdated != nil && !i.LastPrice.IsZero() &&
+		i.LastUpdated.In(now.Location()).Format(time.DateOnly) < now.Format(time.DateOnly) {
+		i.PreviousClose = i.LastPrice
+	}
+	i.LastPrice = price
+	i.LastUpdated = &now
+	return nil
+}
+
+// WatchlistQuote is a watchlist item with its price movements.
+// DailyChange is the last price minus the previous close and
+// DailyChangePercent the same as a percentage of the previous close; both
+// are nil until a previous close is known. DistanceToTarget is how far the
+// price has to move to reach the target price, in percent of the last
+// price, nil without a quote or target price.
+type WatchlistQuote struct {
+	WatchlistItem
+	DailyChange        *Decimal `json:"daily_change,omitempty"`
+	DailyChangePercent *Decimal `json:"daily_change_percent,omitempty"`
+	DistanceToTarget   *Decimal `json:"distance_to_target,omitempty"`
+}
+
+// WatchlistReport is a watchlist with the price movements of its items.
+type WatchlistReport struct {
+	ID        string           `json:"id"`
+	Name      string           `json:"name"`
+	Items     []WatchlistQuote `json:"items"`
+	CreatedAt time.Time        `json:"created_at"`
+	UpdatedAt time.Time        `json:"updated_at"`
+}
+
+// Report calculates the price movements of the items.
+func (w *Watchlist) Report() (*WatchlistReport, error) {
+	report := &WatchlistReport{
+		ID:        w.ID,
+		Name:      w.Name,
+		Items:     make([]WatchlistQuote, 0, len(w.Items)),
+		CreatedAt: w.CreatedAt,
+		UpdatedAt: w.UpdatedAt,
+	}
+	for _, item := range w.Items {
+		quote, err := item.Quote()
+		if err != nil {
+			return nil, err
+		}
+		report.Items = append(report.Items, quote)
+	}
+	return report, nil
+}
+
+// Quote calculates the price movements of the item.
+func (i WatchlistItem) Quote() (WatchlistQuote, error) {
+	quote := WatchlistQuote{WatchlistItem: i}
+	if i.LastPrice.IsZero() {
+		return quote, nil
+	}
+
+	if !i.PreviousClose.IsZero() {
+		change, percent, err := relativeChange(i.PreviousClose, i.LastPrice)
+		if err != nil {
+			return WatchlistQuote{}, fmt.Errorf("failed to calculate daily change of %s: %w", i.Instrument.ISIN, err)
+		}
+		quote.DailyChange = &change
+		quote.DailyChangePercent = &percent
+	}
+	if i.TargetPrice != nil {
+		_, distance, err := relativeChange(i.LastPrice, *i.TargetPrice)
+		if err != nil {
+			return WatchlistQuote{}, fmt.Errorf("failed to calculate distance to target of %s: %w", i.Instrument.ISIN, err)
+		}
+		quote.DistanceToTarget = &distance
+	}
+	return quote, nil
+}
+
+// relativeChange returns to minus from, and the same as a percentage of
+// from.
+func relativeChange(from, to Decimal) (Decimal, Decimal, error) {
+	change, err := to.Sub(from)
+	if err != nil {
+		return Zero, Zero, err
+	}
+	fraction, err := change.Div(from)
+	if err != nil {
+		return Zero, Zero, err
+	}
+	percent, err := asPercent(fraction)
+	if err != nil {
+		return Zero, Zero, err
+	}
+	return change, percent, nil
+}
@@ -0,0 +1,105 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestWatchlist_Items(t *testing.T) {
+	w, err := NewWatchlist("  Candidates ")
+	if err != nil {
+		t.Fatalf("NewWatchlist failed: %v", err)
+	}
+	if w.Name != "Candidates" || w.ID == "" {
+		t.Fatalf("expected a named watchlist with an ID, got %+v", w)
+	}
+
+	apple := NewInstrument("US0378331005", "AAPL", "Apple Inc.", InstrumentTypeStock, "USD", "NASDAQ")
+	notes := " wait for earnings "
+	target := NewDecimalFromInt(150)
+	item, err := w.AddItem(apple, WatchlistItemDetails{Notes: &notes, TargetPrice: &target})
+	if err != nil {
+		t.Fatalf("AddItem failed: %v", err)
+	}
+	if item.Notes != "wait for earnings" || !item.TargetPrice.Equal(target) {
+		t.Errorf("expected trimmed notes and a target of 150, got %+v", item)
+	}
+	if _, err := w.AddItem(apple, WatchlistItemDetails{}); !errors.Is(err, ErrDuplicateWatchlistItem) {
+		t.Errorf("expected ErrDuplicateWatchlistItem, got %v", err)
+	}
+
+	// A zero target price clears it and omitted notes are kept
+	zero := Zero
+	if item, err = w.UpdateItem(apple.ISIN, WatchlistItemDetails{TargetPrice: &zero}); err != nil {
+		t.Fatalf("UpdateItem failed: %v", err)
+	}
+	if item.TargetPrice != nil || item.Notes != "wait for earnings" {
+		t.Errorf("expected the target cleared and the notes kept, got %+v", item)
+	}
+	negative := NewDecimalFromInt(-1)
+	if _, err := w.UpdateItem(apple.ISIN, WatchlistItemDetails{TargetPrice: &negative}); !errors.Is(err, ErrInvalidWatchlist) {
+		t.Errorf("expected ErrInvalidWatchlist, got %v", err)
+	}
+
+	if err := w.RemoveItem(apple.ISIN); err != nil {
+		t.Fatalf("RemoveItem failed: %v", err)
+	}
+	if err := w.RemoveItem(apple.ISIN); !errors.Is(err, ErrWatchlistItemNotFound) {
+		t.Errorf("expected ErrWatchlistItemNotFound, got %v", err)
+	}
+}
+
+func TestNewWatchlist_Invalid(t *testing.T) {
+	if _, err := NewWatchlist("  "); !errors.Is(err, ErrInvalidWatchlist) {
+		t.Errorf("expected ErrInvalidWatchlist, got %v", err)
+	}
+}
+
+func TestWatchlistItem_Quote(t *testing.T) {
+	item := WatchlistItem{
+		Instrument:    NewInstrument("US0378331005", "AAPL", "Apple Inc.", InstrumentTypeStock, "USD", "NASDAQ"),
+		LastPrice:     Zero,
+		PreviousClose: Zero,
+	}
+	quote, err := item.Quote()
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	if quote.DailyChange != nil || quote.DistanceToTarget != nil {
+		t.Errorf("expected no movements before the first quote, got %+v", quote)
+	}
+
+	monday := time.Date(2024, 3, 4, 16, 0, 0, 0, time.UTC)
+	if err := item.UpdatePrice(NewDecimalFromInt(200), monday); err != nil {
+		t.Fatalf("UpdatePrice failed: %v", err)
+	}
+	// A later quote on the same day does not move the previous close
+	if err := item.UpdatePrice(NewDecimalFromInt(190), monday.Add(time.Hour)); err != nil {
+		t.Fatalf("UpdatePrice failed: %v", err)
+	}
+	if !item.PreviousClose.IsZero() {
+		t.Errorf("expected no previous close on the first day, got %s", item.PreviousClose)
+	}
+	if err := item.UpdatePrice(NewDecimalFromInt(171), monday.AddDate(0, 0, 1)); err != nil {
+		t.Fatalf("UpdatePrice failed: %v", err)
+	}
+	target := NewDecimalFromInt(180)
+	item.TargetPrice = &target
+
+	quote, err = item.Quote()
+	if err != nil {
+		t.Fatalf("Quote failed: %v", err)
+	}
+	if !item.PreviousClose.Equal(NewDecimalFromInt(190)) {
+		t.Errorf("expected a previous close of 190, got %s", item.PreviousClose)
+	}
+	if !quote.DailyChange.Equal(NewDecimalFromInt(-19)) || !quote.DailyChangePercent.Equal(NewDecimalFromInt(-10)) {
+		t.Errorf("expected a daily change of -19 (-10%%), got %s (%s%%)", quote.DailyChange, quote.DailyChangePercent)
+	}
+	expectRounded(t, "distance to target", *quote.DistanceToTarget, "5.26")
+
+	if err := item.UpdatePrice(Zero, monday.AddDate(0, 0, 2)); !errors.Is(err, ErrInvalidWatchlist) {
+		t.Errorf("expected ErrInvalidWatchlist for a zero price, got %v", err)
+	}
+}
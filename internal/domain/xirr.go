@@ -0,0 +1,308 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/cockroachdb/apd/v3"
+)
+
+var (
+	ErrInvalidCashFlows = errors.New("invalid cash flows")
+	ErrNoConvergence    = errors.New("calculation did not converge")
+)
+
+const (
+	xirrNewtonIterations    = 50
+	xirrBisectionIterations = 200
+)
+
+var (
+	// xirrTolerance is the rate change, as a fraction, below which the
+	// solver stops.
+	xirrTolerance = Decimal{*apd.New(1, -12)}
+	// xirrLowerBound keeps 1 + rate positive, where discounting is defined.
+	xirrLowerBound = Decimal{*apd.New(-999999, -6)}
+	// xirrUpperBound is the highest yearly rate searched, 1,000,000%.
+	xirrUpperBound = NewDecimalFromInt(10000)
+)
+
+// CashFlow is money paid (negative) or received (positive) by the investor
+// on a date.
+type CashFlow struct {
+	Date   time.Time `json:"date"`
+	Amount Decimal   `json:"amount"`
+}
+
+// XIRR returns the yearly rate, as a fraction, at which the net present
+// value of the flows is zero, discounting each flow by the days elapsed
+// since the first one over a 365-day year. Newton's method is tried first;
+// when it fails to settle, the rate is bracketed and bisected. Flows need
+// at least one payment and one receipt.
+func XIRR(flows []CashFlow) (Decimal, error) {
+	sorted := make([]CashFlow, 0, len(flows))
+	var paid, received bool
+	for _, f := range flows {
+		switch f.Amount.Cmp(Zero) {
+		case -1:
+			paid = true
+		case 1:
+			received = true
+		default:
+			continue
+		}
+		sorted = append(sorted, f)
+	}
+	if !paid || !received {
+		return Zero, fmt.Errorf("%w: need at least one payment and one receipt", ErrInvalidCashFlows)
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Date.Before(sorted[j].Date)
+	})
+	if sameDate(sorted[0].Date, sorted[len(sorted)-1].Date) {
+		return Zero, fmt.Errorf("%w: all flows fall on %s", ErrInvalidCashFlows, sorted[0].Date.Format(time.DateOnly))
+	}
+
+	years := make([]Decimal, len(sorted))
+	for i, f := range sorted {
+		days := int64(math.Round(f.Date.Sub(sorted[0].Date).Hours() / 24))
+		y, err := NewDecimalFromInt(days).Div(NewDecimalFromInt(365))
+		if err != nil {
+			return Zero, fmt.Errorf("failed to calculate flow period: %w", err)
+		}
+		years[i] = y
+	}
+
+	if rate, ok := xirrNewton(sorted, years); ok {
+		return rate, nil
+	}
+	return xirrBisect(sorted, years)
+}
+
+// xirrNewton runs Newton's method from a 10% guess. It reports false when
+// the iteration leaves the valid range, stalls or runs out of steps.
+func xirrNewton(flows []CashFlow, years []Decimal) (Decimal, bool) {
+	rate, _ := NewDecimalFromString("0.1")
+	for i := 0; i < xirrNewtonIterations; i++ {
+		value, derivative, err := xirrNPV(flows, years, rate, true)
+		if err != nil || derivative.IsZero() {
+			return Zero, false
+		}
+		step, err := value.Div(derivative)
+		if err != nil {
+			return Zero, false
+		}
+		if rate, err = rate.Sub(step); err != nil || rate.Cmp(xirrLowerBound) <= 0 {
+			return Zero, false
+		}
+		if step.Abs().Cmp(xirrTolerance) < 0 {
+			return rate, true
+		}
+	}
+	return Zero, false
+}
+
+// xirrBisect looks for a sign change of the net present value between the
+// lower bound and increasing upper rates, then halves the bracket until it
+// is narrower than the tolerance.
+func xirrBisect(flows []CashFlow, years []Decimal) (Decimal, error) {
+	two := NewDecimalFromInt(2)
+	low, high := xirrLowerBound, NewDecimalFromInt(1)
+
+	lowValue, _, err := xirrNPV(flows, years, low, false)
+	if err != nil {
+		return Zero, err
+	}
+	for {
+		highValue, _, err := xirrNPV(flows, years, high, false)
+		if err != nil {
+			return Zero, err
+		}
+		if lowValue.Cmp(Zero)*highValue.Cmp(Zero) <= 0 {
+			break
+		}
+		if high.Cmp(xirrUpperBound) >= 0 {
+			return Zero, fmt.Errorf("%w: no rate between -99.9999%% and 1,000,000%% gives a net present value of zero", ErrNoConvergence)
+		}
+		if high, err = high.Mul(two); err != nil {
+			return Zero, fmt.Errorf("failed to widen bracket: %w", err)
+		}
+	}
+
+	for i := 0; i < xirrBisectionIterations; i++ {
+		sum, err := low.Add(high)
+		if err != nil {
+			return Zero, fmt.Errorf("failed to bisect: %w", err)
+		}
+		mid, err := sum.Div(two)
+		if err != nil {
+			return Zero, fmt.Errorf("failed to bisect: %w", err)
+		}
+		width, err := high.Sub(low)
+		if err != nil {
+			return Zero, fmt.Errorf("failed to bisect: %w", err)
+		}
+		if width.Cmp(xirrTolerance) < 0 {
+			return mid, nil
+		}
+
+		midValue, _, err := xirrNPV(flows, years, mid, false)
+		if err != nil {
+			return Zero, err
+		}
+		if midValue.IsZero() {
+			return mid, nil
+		}
+		if midValue.Cmp(Zero) == lowValue.Cmp(Zero) {
+			low, lowValue = mid, midValue
+		} else {
+			high = mid
+		}
+	}
+	return Zero, fmt.Errorf("%w: bracket still wider than the tolerance after %d bisections", ErrNoConvergence, xirrBisectionIterations)
+}
+
+// xirrNPV returns the net present value of the flows at rate and, when
+// requested, its derivative with respect to the rate.
+func xirrNPV(flows []CashFlow, years []Decimal, rate Decimal, withDerivative bool) (Decimal, Decimal, error) {
+	base, err := rate.Add(NewDecimalFromInt(1))
+	if err != nil {
+		return Zero, Zero, fmt.Errorf("failed to calculate discount base: %w", err)
+	}
+
+	value, derivative := Zero, Zero
+	for i, f := range flows {
+		discount, err := base.Pow(years[i])
+		if err != nil {
+			return Zero, Zero, fmt.Errorf("failed to discount flow: %w", err)
+		}
+		term, err := f.Amount.Div(discount)
+		if err != nil {
+			return Zero, Zero, fmt.Errorf("failed to discount flow: %w", err)
+		}
+		if value, err = value.Add(term); err != nil {
+			return Zero, Zero, fmt.Errorf("failed to add discounted flow: %w", err)
+		}
+		if !withDerivative {
+			continue
+		}
+
+		// d/dr of A / (1+r)^t is -t * A / (1+r)^(t+1)
+		slope, err := term.Mul(years[i])
+		if err == nil {
+			slope, err = slope.Div(base)
+		}
+		if err == nil {
+			derivative, err = derivative.Sub(slope)
+		}
+		if err != nil {
+			return Zero, Zero, fmt.Errorf("failed to calculate derivative: %w", err)
+		}
+	}
+	return value, derivative, nil
+}
+
+// CashFlows turns a valuation series into the investor's cash flows: money
+// put in is paid, money taken out is received, and the last value is
+// received as if the holdings were sold on that date.
+func CashFlows(points []ValuationPoint) ([]CashFlow, error) {
+	flows := make([]CashFlow, 0, len(points))
+	for i, point := range points {
+		if !point.Flow.IsZero() {
+			amount, err := Zero.Sub(point.Flow)
+			if err != nil {
+				return nil, fmt.Errorf("failed to invert cash flow: %w", err)
+			}
+			flows = append(flows, CashFlow{Date: point.Date, Amount: amount})
+		}
+		if i == len(points)-1 && !point.Value.IsZero() {
+			flows = append(flows, CashFlow{Date: point.Date, Amount: point.Value})
+		}
+	}
+	return flows, nil
+}
+
+// PositionMoneyWeightedReturn is the XIRR of a single position. Error
+// explains why it could not be calculated, for example when the position
+// was opened today.
+type PositionMoneyWeightedReturn struct {
+	PositionID string   `json:"position_id"`
+	ISIN       string   `json:"isin"`
+	Symbol     string   `json:"symbol"`
+	XIRR       *Decimal `json:"xirr,omitempty"`
+	Error      string   `json:"error,omitempty"`
+}
+
+// MoneyWeightedReport holds yearly money-weighted returns as percentages,
+// since inception up to AsOf.
+type MoneyWeightedReport struct {
+	Currency  string                        `json:"currency"`
+	AsOf      time.Time                     `json:"as_of"`
+	XIRR      Decimal                       `json:"xirr"`
+	CashFlows []CashFlow                    `json:"cash_flows"`
+	Positions []PositionMoneyWeightedReturn `json:"positions"`
+}
+
+// MoneyWeightedReturn calculates the XIRR of the portfolio and of every
+// position from the cash flows in the ledger, valuing the holdings at asOf
+// in the target currency of rates. Unlike the time-weighted return it
+// rewards or penalizes the timing of contributions.
+func (p *Portfolio) MoneyWeightedReturn(asOf time.Time, rates *ExchangeRates) (*MoneyWeightedReport, error) {
+	series, err := p.ValuationSeries("", asOf, rates)
+	if err != nil {
+		return nil, err
+	}
+	if len(series) == 0 {
+		return nil, fmt.Errorf("%w: the ledger is empty", ErrInsufficientHistory)
+	}
+	flows, err := CashFlows(series)
+	if err != nil {
+		return nil, err
+	}
+	rate, err := XIRR(flows)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &MoneyWeightedReport{
+		Currency:  rates.Target,
+		AsOf:      asOf,
+		CashFlows: flows,
+		Positions: make([]PositionMoneyWeightedReturn, 0, len(p.Positions)),
+	}
+	if report.XIRR, err = asPercent(rate); err != nil {
+		return nil, err
+	}
+
+	for i := range p.Positions {
+		pos := &p.Positions[i]
+		series, err := p.ValuationSeries(pos.ID, asOf, rates)
+		if err != nil {
+			return nil, fmt.Errorf("failed to value position %s: %w", pos.ID, err)
+		}
+		if len(series) == 0 {
+			continue
+		}
+		result := PositionMoneyWeightedReturn{
+			PositionID: pos.ID,
+			ISIN:       pos.Instrument.ISIN,
+			Symbol:     pos.Instrument.Symbol,
+		}
+		flows, err := CashFlows(series)
+		if err != nil {
+			return nil, err
+		}
+		if rate, err := XIRR(flows); err != nil {
+			result.Error = err.Error()
+		} else if rate, err = asPercent(rate); err != nil {
+			return nil, err
+		} else {
+			result.XIRR = &rate
+		}
+		report.Positions = append(report.Positions, result)
+	}
+	return report, nil
+}
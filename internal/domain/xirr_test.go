@@ -0,0 +1,121 @@
+package domain
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func day(year int, month time.Month, d int) time.Time {
+	return time.Date(year, month, d, 0, 0, 0, 0, time.UTC)
+}
+
+func assertRate(t *testing.T, expected string, actual Decimal) {
+	t.Helper()
+	want, _ := NewDecimalFromString(expected)
+	diff, _ := actual.Sub(want)
+	tolerance, _ := NewDecimalFromString("0.000001")
+	if diff.Abs().Cmp(tolerance) > 0 {
+		t.Errorf("expected rate %s, got %s", expected, actual)
+	}
+}
+
+func TestXIRR(t *testing.T) {
+	testCases := []struct {
+		name     string
+		flows    []CashFlow
+		expected string
+	}{
+		{"one year", []CashFlow{
+			{day(2023, 1, 1), NewDecimalFromInt(-1000)},
+			{day(2024, 1, 1), NewDecimalFromInt(1100)},
+		}, "0.1"},
+		{"spreadsheet example", []CashFlow{
+			{day(2008, 1, 1), NewDecimalFromInt(-10000)},
+			{day(2008, 3, 1), NewDecimalFromInt(2750)},
+			{day(2008, 10, 30), NewDecimalFromInt(4250)},
+			{day(2009, 2, 15), NewDecimalFromInt(3250)},
+			{day(2009, 4, 1), NewDecimalFromInt(2750)},
+		}, "0.373362535"},
+		{"loss", []CashFlow{
+			{day(2023, 1, 1), NewDecimalFromInt(-1000)},
+			{day(2023, 7, 2), NewDecimalFromInt(-1000)},
+			{day(2024, 1, 1), NewDecimalFromInt(1500)},
+		}, "-0.322603"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rate, err := XIRR(tc.flows)
+			if err != nil {
+				t.Fatalf("XIRR failed: %v", err)
+			}
+			assertRate(t, tc.expected, rate)
+		})
+	}
+}
+
+func TestXIRR_Bisection(t *testing.T) {
+	flows := []CashFlow{
+		{day(2023, 1, 1), NewDecimalFromInt(-1000)},
+		{day(2024, 1, 1), NewDecimalFromInt(1100)},
+	}
+	years := []Decimal{Zero, NewDecimalFromInt(1)}
+
+	rate, err := xirrBisect(flows, years)
+	if err != nil {
+		t.Fatalf("xirrBisect failed: %v", err)
+	}
+	assertRate(t, "0.1", rate)
+}
+
+func TestXIRR_Errors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		flows []CashFlow
+		err   error
+	}{
+		{"only payments", []CashFlow{
+			{day(2023, 1, 1), NewDecimalFromInt(-1000)},
+			{day(2024, 1, 1), NewDecimalFromInt(-100)},
+		}, ErrInvalidCashFlows},
+		{"single day", []CashFlow{
+			{day(2023, 1, 1), NewDecimalFromInt(-1000)},
+			{day(2023, 1, 1), NewDecimalFromInt(1100)},
+		}, ErrInvalidCashFlows},
+		// The net present value stays negative at every rate
+		{"no root", []CashFlow{
+			{day(2023, 1, 1), NewDecimalFromInt(-100)},
+			{day(2023, 7, 2), NewDecimalFromInt(300)},
+			{day(2024, 1, 1), NewDecimalFromInt(-300)},
+		}, ErrNoConvergence},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := XIRR(tc.flows); !errors.Is(err, tc.err) {
+				t.Errorf("expected %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestPortfolio_MoneyWeightedReturn(t *testing.T) {
+	p := newPerformancePortfolio(t)
+
+	report, err := p.MoneyWeightedReturn(day(2025, 2, 10), NewExchangeRates("USD"))
+	if err != nil {
+		t.Fatalf("MoneyWeightedReturn failed: %v", err)
+	}
+	if len(report.CashFlows) != 3 {
+		t.Fatalf("expected 2 buys and the final value as cash flows, got %+v", report.CashFlows)
+	}
+	if !report.CashFlows[2].Amount.Equal(NewDecimalFromInt(1980)) {
+		t.Errorf("expected final value of 1980, got %s", report.CashFlows[2].Amount)
+	}
+	// Most of the money went in at the higher price, so the loss is close to 10%
+	if report.XIRR.Cmp(NewDecimalFromInt(-10)) <= 0 || report.XIRR.Cmp(NewDecimalFromInt(-5)) >= 0 {
+		t.Errorf("expected a yearly loss between 5%% and 10%%, got %s", report.XIRR)
+	}
+	if len(report.Positions) != 1 || report.Positions[0].XIRR == nil || !report.Positions[0].XIRR.Equal(report.XIRR) {
+		t.Errorf("expected the single position to match the portfolio, got %+v", report.Positions)
+	}
+}
@@ -3,7 +3,10 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
 )
 
 // MarketDataProviderTwelveData is the constant for TwelveData provider.
@@ -15,6 +18,15 @@ const MarketDataProviderFinnhub = "finnhub"
 // MarketDataProviderYFinance is the constant for the yfinance-based Market Data Service.
 const MarketDataProviderYFinance = "yfinance"
 
+// FXProviderECB is the constant for the ECB reference-rate feed.
+const FXProviderECB = "ecb"
+
+// FXProviderStatic is the constant for fixed rates from FX_STATIC_RATES.
+const FXProviderStatic = "static"
+
+// FXProviderNone disables currency conversion.
+const FXProviderNone = "none"
+
 type Config struct {
 	TwelveDataAPIKey     string
 	FinnhubAPIKey        string
@@ -26,6 +38,13 @@ type Config struct {
 	LogLevel             string
 	DBDriver             string
 	DBDSN                string
+	FXProvider           string
+	FXStaticRates        string
+	ECBBaseURL           string
+	RiskFreeRate         domain.Decimal
+	AlertWebhookURLs     string
+	AlertWebhookRetries  int
+	AlertWebhookBackoff  time.Duration
 }
 
 func Load() (*Config, error) {
@@ -69,6 +88,39 @@ func Load() (*Config, error) {
 			marketDataProvider, MarketDataProviderTwelveData, MarketDataProviderFinnhub, MarketDataProviderYFinance)
 	}
 
+	// Optional fixed exchange rates, as units of each currency per 1 EUR
+	fxStaticRates := os.Getenv("FX_STATIC_RATES")
+	ecbBaseURL := getEnvOrDefault("ECB_BASE_URL", "https://www.ecb.europa.eu/stats/eurofxref")
+
+	fxProvider := getEnvOrDefault("FX_PROVIDER", FXProviderECB)
+	switch fxProvider {
+	case FXProviderECB, FXProviderNone:
+	case FXProviderStatic:
+		if fxStaticRates == "" {
+			return nil, fmt.Errorf("FX_STATIC_RATES environment variable is required when using static fx provider")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported FX_PROVIDER: %s (supported: %s, %s, %s)",
+			fxProvider, FXProviderECB, FXProviderStatic, FXProviderNone)
+	}
+
+	// Yearly rate in percent for Sharpe and Sortino ratios
+	riskFreeRate, err := domain.NewDecimalFromString(getEnvOrDefault("RISK_FREE_RATE", "0"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid RISK_FREE_RATE: %w", err)
+	}
+
+	// Comma-separated URLs triggered alerts are posted to
+	alertWebhookURLs := os.Getenv("ALERT_WEBHOOK_URLS")
+	alertWebhookRetries, err := strconv.Atoi(getEnvOrDefault("ALERT_WEBHOOK_RETRIES", "3"))
+	if err != nil || alertWebhookRetries < 0 {
+		return nil, fmt.Errorf("invalid ALERT_WEBHOOK_RETRIES: must be a non-negative integer")
+	}
+	alertWebhookBackoff, err := time.ParseDuration(getEnvOrDefault("ALERT_WEBHOOK_BACKOFF", "1s"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid ALERT_WEBHOOK_BACKOFF: %w", err)
+	}
+
 	return &Config{
 		TwelveDataAPIKey:     twelveDataAPIKey,
 		FinnhubAPIKey:        finnhubAPIKey,
@@ -80,6 +132,13 @@ func Load() (*Config, error) {
 		LogLevel:             logLevel,
 		DBDriver:             dbDriver,
 		DBDSN:                dbDSN,
+		FXProvider:           fxProvider,
+		FXStaticRates:        fxStaticRates,
+		ECBBaseURL:           ecbBaseURL,
+		RiskFreeRate:         riskFreeRate,
+		AlertWebhookURLs:     alertWebhookURLs,
+		AlertWebhookRetries:  alertWebhookRetries,
+		AlertWebhookBackoff:  alertWebhookBackoff,
 	}, nil
 }
 
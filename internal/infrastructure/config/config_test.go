@@ -128,6 +128,76 @@ func TestLoad_Defaults(t *testing.T) {
 	assert.Equal(t, "info", cfg.LogLevel)
 	assert.Equal(t, "twelvedata", cfg.MarketDataProvider) // Default provider
 	assert.Equal(t, 60*time.Second, cfg.PriceRefreshInterval)
+	assert.Equal(t, "", cfg.FXStaticRates)
+	assert.Equal(t, FXProviderECB, cfg.FXProvider)
+	assert.Equal(t, "https://www.ecb.europa.eu/stats/eurofxref", cfg.ECBBaseURL)
+	assert.True(t, cfg.RiskFreeRate.IsZero())
+}
+
+func TestLoad_RiskFreeRate(t *testing.T) {
+	t.Setenv("TWELVE_DATA_API_KEY", "key")
+	t.Setenv("DB_DSN", "dsn")
+	t.Setenv("RISK_FREE_RATE", "3.25")
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "3.25", cfg.RiskFreeRate.String())
+
+	t.Setenv("RISK_FREE_RATE", "three")
+	_, err = Load()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid RISK_FREE_RATE")
+}
+
+func TestLoad_AlertWebhooks(t *testing.T) {
+	t.Setenv("TWELVE_DATA_API_KEY", "key")
+	t.Setenv("DB_DSN", "dsn")
+
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Empty(t, cfg.AlertWebhookURLs)
+	assert.Equal(t, 3, cfg.AlertWebhookRetries)
+	assert.Equal(t, time.Second, cfg.AlertWebhookBackoff)
+
+	t.Setenv("ALERT_WEBHOOK_URLS", "https://hooks.example.com/alerts")
+	t.Setenv("ALERT_WEBHOOK_RETRIES", "5")
+	t.Setenv("ALERT_WEBHOOK_BACKOFF", "250ms")
+	cfg, err = Load()
+	assert.NoError(t, err)
+	assert.Equal(t, "https://hooks.example.com/alerts", cfg.AlertWebhookURLs)
+	assert.Equal(t, 5, cfg.AlertWebhookRetries)
+	assert.Equal(t, 250*time.Millisecond, cfg.AlertWebhookBackoff)
+
+	t.Setenv("ALERT_WEBHOOK_RETRIES", "-1")
+	_, err = Load()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid ALERT_WEBHOOK_RETRIES")
+}
+
+func TestLoad_StaticFXProvider(t *testing.T) {
+	t.Setenv("TWELVE_DATA_API_KEY", "key")
+	t.Setenv("DB_DSN", "dsn")
+	t.Setenv("FX_PROVIDER", "static")
+
+	_, err := Load()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "FX_STATIC_RATES")
+
+	t.Setenv("FX_STATIC_RATES", "USD=1.08")
+	cfg, err := Load()
+	assert.NoError(t, err)
+	assert.Equal(t, FXProviderStatic, cfg.FXProvider)
+	assert.Equal(t, "USD=1.08", cfg.FXStaticRates)
+}
+
+func TestLoad_UnsupportedFXProvider(t *testing.T) {
+	t.Setenv("TWELVE_DATA_API_KEY", "key")
+	t.Setenv("DB_DSN", "dsn")
+	t.Setenv("FX_PROVIDER", "oanda")
+
+	_, err := Load()
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported FX_PROVIDER")
 }
 
 func TestGetEnvOrDefault(t *testing.T) {
@@ -0,0 +1,300 @@
+// Package holdings reads the constituent files ETF issuers publish for
+// their funds.
+package holdings
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// Format identifies the layout of an issuer file.
+type Format string
+
+const (
+	// FormatIShares is the holdings CSV offered on iShares fund pages.
+	FormatIShares Format = "ishares"
+	// FormatVanguard is the holdings CSV exported from Vanguard fund pages.
+	FormatVanguard Format = "vanguard"
+)
+
+// File is the content of an issuer file. AsOf is zero when the file does
+// not state the date of its holdings.
+type File struct {
+	Format   Format
+	AsOf     time.Time
+	Holdings []domain.ETFHolding
+}
+
+// layout lists, for each field, the headers an issuer uses for it, in
+// order of preference. Headers are compared in lower case.
+type layout struct {
+	ticker     []string
+	name       []string
+	isin       []string
+	sector     []string
+	country    []string
+	currency   []string
+	assetClass []string
+	weight     []string
+}
+
+var layouts = map[Format]layout{
+	FormatIShares: {
+		ticker:     []string{"ticker", "issuer ticker"},
+		name:       []string{"name"},
+		isin:       []string{"isin"},
+		sector:     []string{"sector"},
+		country:    []string{"location", "location of risk"},
+		currency:   []string{"market currency", "currency"},
+		assetClass: []string{"asset class"},
+		weight:     []string{"weight (%)"},
+	},
+	FormatVanguard: {
+		ticker:     []string{"ticker", "symbol"},
+		name:       []string{"holding name", "holdings", "name"},
+		isin:       []string{"isin"},
+		sector:     []string{"sector"},
+		country:    []string{"country", "market"},
+		currency:   []string{"currency", "local currency"},
+		assetClass: []string{"asset class"},
+		weight:     []string{"% of fund", "% of net assets", "% of market value", "weight"},
+	},
+}
+
+// detectOrder is the order in which layouts are tried when the format is
+// not given. iShares headers are the more specific.
+var detectOrder = []Format{FormatIShares, FormatVanguard}
+
+// dateLayouts are the date formats issuers use for the date of their
+// holdings. Slashed numeric dates are read month first, as in the US files.
+var dateLayouts = []string{
+	"Jan 02, 2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"02/Jan/2006",
+	"2 Jan 2006",
+	"2 January 2006",
+	"01/02/2006",
+	"1/2/2006",
+	"2006-01-02",
+}
+
+// ParseFormat validates the name of a format. An empty name selects
+// detection from the headers.
+func ParseFormat(name string) (Format, error) {
+	format := Format(strings.ToLower(strings.TrimSpace(name)))
+	if format == "" {
+		return "", nil
+	}
+	if _, ok := layouts[format]; !ok {
+		return "", fmt.Errorf("%w: unsupported format %q", domain.ErrInvalidHoldings, name)
+	}
+	return format, nil
+}
+
+// Parse reads an issuer file in the given format, or in the first format
+// whose headers match when format is empty. Rows before the header are
+// searched for the date of the holdings; the holdings end at the first
+// blank row, where issuers start their disclaimers.
+func Parse(r io.Reader, format Format) (*File, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	candidates := detectOrder
+	if format != "" {
+		if _, ok := layouts[format]; !ok {
+			return nil, fmt.Errorf("%w: unsupported format %q", domain.ErrInvalidHoldings, format)
+		}
+		candidates = []Format{format}
+	}
+
+	file := &File{}
+	var header *columns
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: failed to read line %d: %w", domain.ErrInvalidHoldings, line, err)
+		}
+		if line == 1 && len(record) > 0 {
+			record[0] = strings.TrimPrefix(record[0], "\ufeff")
+		}
+
+		if header == nil {
+			for _, candidate := range candidates {
+				if header = matchHeader(layouts[candidate], record); header != nil {
+					file.Format = candidate
+					break
+				}
+			}
+			if header == nil && file.AsOf.IsZero() {
+				file.AsOf = findDate(record)
+			}
+			continue
+		}
+
+		if isBlank(record) {
+			break
+		}
+		holding, ok, err := header.holding(record)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", domain.ErrInvalidHoldings, line, err)
+		}
+		if ok {
+			file.Holdings = append(file.Holdings, holding)
+		}
+	}
+
+	if header == nil {
+		return nil, fmt.Errorf("%w: no holdings header found", domain.ErrInvalidHoldings)
+	}
+	return file, nil
+}
+
+// columns holds the index of each field in a record, or -1 when the file
+// does not have it.
+type columns struct {
+	ticker, name, isin, sector, country, currency, assetClass, weight int
+}
+
+// matchHeader maps the fields of l to the columns of record, or returns
+// nil when record lacks a name or weight column and so is not the header.
+func matchHeader(l layout, record []string) *columns {
+	headers := make(map[string]int, len(record))
+	for i, cell := range record {
+		header := strings.ToLower(strings.Join(strings.Fields(cell), " "))
+		if _, ok := headers[header]; !ok {
+			headers[header] = i
+		}
+	}
+	find := func(aliases []string) int {
+		for _, alias := range aliases {
+			if i, ok := headers[alias]; ok {
+				return i
+			}
+		}
+		return -1
+	}
+
+	c := &columns{
+		ticker:     find(l.ticker),
+		name:       find(l.name),
+		isin:       find(l.isin),
+		sector:     find(l.sector),
+		country:    find(l.country),
+		currency:   find(l.currency),
+		assetClass: find(l.assetClass),
+		weight:     find(l.weight),
+	}
+	if c.name < 0 || c.weight < 0 {
+		return nil
+	}
+	return c
+}
+
+// holding reads a row. Rows without a weight, such as subtotals, are
+// skipped.
+func (c *columns) holding(record []string) (domain.ETFHolding, bool, error) {
+	rawWeight := cell(record, c.weight)
+	if rawWeight == "" {
+		return domain.ETFHolding{}, false, nil
+	}
+	weight, err := parseNumber(rawWeight)
+	if err != nil {
+		return domain.ETFHolding{}, false, fmt.Errorf("invalid weight %q: %w", rawWeight, err)
+	}
+	return domain.ETFHolding{
+		ISIN:       cell(record, c.isin),
+		Ticker:     cell(record, c.ticker),
+		Name:       cell(record, c.name),
+		Sector:     cell(record, c.sector),
+		Country:    cell(record, c.country),
+		Currency:   cell(record, c.currency),
+		AssetClass: cell(record, c.assetClass),
+		Weight:     weight,
+	}, true, nil
+}
+
+// cell returns the trimmed value at index i. Issuers write "-" for missing
+// values, which is returned as empty.
+func cell(record []string, i int) string {
+	if i < 0 || i >= len(record) {
+		return ""
+	}
+	value := strings.TrimSpace(record[i])
+	if value == "-" || value == "--" {
+		return ""
+	}
+	return value
+}
+
+// parseNumber reads a number written with thousands separators, a decimal
+// comma or a percent sign.
+func parseNumber(raw string) (domain.Decimal, error) {
+	value := strings.TrimSuffix(strings.ReplaceAll(raw, " ", ""), "%")
+	comma := strings.LastIndex(value, ",")
+	dot := strings.LastIndex(value, ".")
+	switch {
+	case comma >= 0 && dot >= 0 && comma > dot:
+		value = strings.ReplaceAll(strings.ReplaceAll(value, ".", ""), ",", ".")
+	case comma >= 0 && dot < 0 && strings.Count(value, ",") == 1 && len(value)-comma-1 != 3:
+		value = strings.Replace(value, ",", ".", 1)
+	default:
+		value = strings.ReplaceAll(value, ",", "")
+	}
+	return domain.NewDecimalFromString(value)
+}
+
+// findDate returns the date stated in a preamble row such as
+// "Fund Holdings as of,Mar 01, 2024", or the zero time.
+func findDate(record []string) time.Time {
+	for i, value := range record {
+		lower := strings.ToLower(value)
+		for _, marker := range []string{"as of", "as at"} {
+			at := strings.Index(lower, marker)
+			if at < 0 {
+				continue
+			}
+			candidates := []string{value[at+len(marker):]}
+			if i+1 < len(record) {
+				candidates = append(candidates, record[i+1])
+			}
+			for _, candidate := range candidates {
+				if date, ok := parseDate(candidate); ok {
+					return date
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func parseDate(value string) (time.Time, bool) {
+	value = strings.Trim(strings.TrimSpace(value), ":")
+	value = strings.TrimSpace(value)
+	for _, l := range dateLayouts {
+		if date, err := time.Parse(l, value); err == nil {
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func isBlank(record []string) bool {
+	for _, value := range record {
+		if strings.TrimSpace(value) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+package holdings
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+const iSharesFile = "\ufeffiShares Core MSCI World UCITS ETF\n" +
+	`Fund Holdings as of,"Mar 01, 2024"
+Inception Date,"Sep 25, 2009"
+Shares Outstanding,"1,015,400,000.00"
+
+Ticker,Name,Sector,Asset Class,Market Value,Weight (%),Notional Value,Shares,Price,Location,Exchange,Currency,FX Rate,Market Currency
+"AAPL","APPLE INC","Information Technology","Equity","3,512,345,678.90","4.65","3,512,345,678.90","19,876,543.00","176.71","United States","NASDAQ","USD","1.00","USD"
+"NESN","NESTLE SA","Consumer Staples","Equity","512,345,678.90","0.68","512,345,678.90","5,123,456.00","93.17","Switzerland","SIX Swiss Exchange","USD","1.00","CHF"
+"EUR","EUR CASH","Cash and/or Derivatives","Cash","12,345,678.90","0.02","12,345,678.90","12,345,678.90","100.00","European Union","-","USD","1.08","EUR"
+"ESH4","S&P500 EMINI MAR 24","Cash and/or Derivatives","Futures","-","-0.01","45,678,901.23","95.00","5,137.25","United States","Chicago Mercantile Exchange","USD","1.00","USD"
+
+"The content contained herein is owned or licensed by BlackRock and/or its third-party information providers."
+`
+
+const vanguardFile = `Vanguard FTSE All-World UCITS ETF
+"As at 29 Feb 2024"
+
+Ticker,Holding name,ISIN,% of market value,Sector,Country,Market value
+MSFT,Microsoft Corp.,US5949181045,"3,85%",Technology,United States,"£1.234.567.890"
+ASML,ASML Holding NV,NL0010273215,"0,91%",Technology,Netherlands,"£291.234.567"
+
+Data as at 29 Feb 2024. Holdings are subject to change.
+`
+
+func TestParse_IShares(t *testing.T) {
+	file, err := Parse(strings.NewReader(iSharesFile), "")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if file.Format != FormatIShares {
+		t.Errorf("expected ishares format, got %q", file.Format)
+	}
+	if want := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC); !file.AsOf.Equal(want) {
+		t.Errorf("expected holdings as of %s, got %s", want, file.AsOf)
+	}
+	if len(file.Holdings) != 4 {
+		t.Fatalf("expected 4 holdings, got %d: %+v", len(file.Holdings), file.Holdings)
+	}
+
+	apple := file.Holdings[0]
+	if apple.Ticker != "AAPL" || apple.Name != "APPLE INC" || apple.ISIN != "" || apple.Sector != "Information Technology" ||
+		apple.Country != "United States" || apple.Currency != "USD" || apple.AssetClass != "Equity" || !apple.Weight.Equal(mustDecimal(t, "4.65")) {
+		t.Errorf("unexpected holding %+v", apple)
+	}
+	if nestle := file.Holdings[1]; nestle.Currency != "CHF" {
+		t.Errorf("expected the market currency CHF, got %q", nestle.Currency)
+	}
+	if future := file.Holdings[3]; !future.Weight.Equal(mustDecimal(t, "-0.01")) {
+		t.Errorf("expected a weight of -0.01, got %s", future.Weight)
+	}
+}
+
+func TestParse_Vanguard(t *testing.T) {
+	file, err := Parse(strings.NewReader(vanguardFile), FormatVanguard)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if want := time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC); !file.AsOf.Equal(want) {
+		t.Errorf("expected holdings as of %s, got %s", want, file.AsOf)
+	}
+	if len(file.Holdings) != 2 {
+		t.Fatalf("expected 2 holdings, got %d: %+v", len(file.Holdings), file.Holdings)
+	}
+	asml := file.Holdings[1]
+	if asml.ISIN != "NL0010273215" || asml.Ticker != "ASML" || asml.Country != "Netherlands" || !asml.Weight.Equal(mustDecimal(t, "0.91")) {
+		t.Errorf("unexpected holding %+v", asml)
+	}
+}
+
+func TestParse_DetectsVanguard(t *testing.T) {
+	file, err := Parse(strings.NewReader(vanguardFile), "")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if file.Format != FormatVanguard {
+		t.Errorf("expected vanguard format, got %q", file.Format)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		format Format
+	}{
+		{"no header", "Ticker,Name,Price\nAAPL,APPLE INC,176.71\n", ""},
+		{"header of another format", iSharesFile, FormatVanguard},
+		{"invalid weight", "Ticker,Name,Weight (%)\nAAPL,APPLE INC,four\n", FormatIShares},
+		{"unsupported format", iSharesFile, "spdr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input), tt.format)
+			if !errors.Is(err, domain.ErrInvalidHoldings) {
+				t.Errorf("expected ErrInvalidHoldings, got %v", err)
+			}
+		})
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if format, err := ParseFormat(" iShares "); err != nil || format != FormatIShares {
+		t.Errorf("expected ishares, got %q, %v", format, err)
+	}
+	if format, err := ParseFormat(""); err != nil || format != "" {
+		t.Errorf("expected detection, got %q, %v", format, err)
+	}
+	if _, err := ParseFormat("spdr"); !errors.Is(err, domain.ErrInvalidHoldings) {
+		t.Errorf("expected ErrInvalidHoldings, got %v", err)
+	}
+}
+
+func TestParseNumber(t *testing.T) {
+	tests := map[string]string{
+		"4.65":         "4.65",
+		"0,91%":        "0.91",
+		"1,234.5":      "1234.5",
+		"1.234,5":      "1234.5",
+		"3,512,345.90": "3512345.90",
+		"-0.01":        "-0.01",
+		"12 %":         "12",
+	}
+	for raw, want := range tests {
+		got, err := parseNumber(raw)
+		if err != nil {
+			t.Errorf("parseNumber(%q) failed: %v", raw, err)
+			continue
+		}
+		if !got.Equal(mustDecimal(t, want)) {
+			t.Errorf("parseNumber(%q) = %s, want %s", raw, got, want)
+		}
+	}
+}
+
+func mustDecimal(t *testing.T, value string) domain.Decimal {
+	t.Helper()
+	d, err := domain.NewDecimalFromString(value)
+	if err != nil {
+		t.Fatalf("NewDecimalFromString(%q) failed: %v", value, err)
+	}
+	return d
+}
@@ -0,0 +1,281 @@
+package ecb
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata"
+)
+
+const (
+	defaultBaseURL = "https://www.ecb.europa.eu/stats/eurofxref"
+	dailyPath      = "/eurofxref-daily.xml"
+	historyPath    = "/eurofxref-hist.xml"
+
+	// Source identifies rates published by the European Central Bank.
+	Source = "ecb"
+
+	// The ECB publishes once per business day around 16:00 CET.
+	dailyCacheTTL   = time.Hour
+	historyCacheTTL = 12 * time.Hour
+)
+
+// Client implements the FXRateProvider interface using the euro foreign
+// exchange reference rates published by the European Central Bank.
+// All rates are quoted against EUR; other pairs are derived as cross rates.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	daily   *rateTable
+	history []rateTable
+	loaded  time.Time
+}
+
+var _ marketdata.HistoricalFXRateProvider = (*Client)(nil)
+
+// NewClient creates a new ECB client with default settings.
+func NewClient() *Client {
+	return NewClientWithBaseURL(defaultBaseURL)
+}
+
+// NewClientWithBaseURL creates a new client with a custom base URL (useful for testing and mirrors).
+func NewClientWithBaseURL(baseURL string) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+		},
+	}
+}
+
+// envelope mirrors the gesmes envelope of the reference-rate feeds:
+// one outer Cube holding a Cube per day, each holding a Cube per currency.
+type envelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// rateTable holds the units of each currency per 1 EUR on one day.
+type rateTable struct {
+	date    time.Time
+	perEUR  map[string]domain.Decimal
+	fetched time.Time
+}
+
+// DailyRates returns the latest published EUR reference rates.
+func (c *Client) DailyRates(ctx context.Context) ([]domain.FXRate, error) {
+	table, err := c.latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return table.rates(), nil
+}
+
+// HistoricalRates returns every EUR reference rate published since 1999,
+// ordered by date.
+func (c *Client) HistoricalRates(ctx context.Context) ([]domain.FXRate, error) {
+	tables, err := c.historical(ctx)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]domain.FXRate, 0)
+	for i := range tables {
+		result = append(result, tables[i].rates()...)
+	}
+	return result, nil
+}
+
+// GetRate returns the latest reference rate between two currencies.
+func (c *Client) GetRate(ctx context.Context, from, to string) (*domain.FXRate, error) {
+	table, err := c.latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return table.cross(from, to)
+}
+
+// GetHistoricalRate returns the rate published on date, or on the last
+// business day before it for weekends and TARGET holidays.
+func (c *Client) GetHistoricalRate(ctx context.Context, from, to string, date time.Time) (*domain.FXRate, error) {
+	tables, err := c.historical(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	day := truncateToDay(date)
+	// Tables are sorted by date, find the last one on or before day
+	i := sort.Search(len(tables), func(i int) bool {
+		return tables[i].date.After(day)
+	})
+	if i == 0 {
+		return nil, fmt.Errorf("%w: no ECB rates published on or before %s", domain.ErrFXRateNotFound, day.Format(time.DateOnly))
+	}
+	return tables[i-1].cross(from, to)
+}
+
+func (c *Client) latest(ctx context.Context) (*rateTable, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.daily != nil && time.Since(c.daily.fetched) < dailyCacheTTL {
+		return c.daily, nil
+	}
+
+	tables, err := c.fetch(ctx, dailyPath)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 {
+		return nil, fmt.Errorf("%w: ECB daily feed contains no rates", domain.ErrFXRateNotFound)
+	}
+	c.daily = &tables[len(tables)-1]
+	return c.daily, nil
+}
+
+func (c *Client) historical(ctx context.Context) ([]rateTable, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.history != nil && time.Since(c.loaded) < historyCacheTTL {
+		return c.history, nil
+	}
+
+	tables, err := c.fetch(ctx, historyPath)
+	if err != nil {
+		return nil, err
+	}
+	c.history = tables
+	c.loaded = time.Now()
+	return c.history, nil
+}
+
+// fetch downloads and parses a reference-rate feed, returning one table
+// per published day in ascending date order.
+func (c *Client) fetch(ctx context.Context, path string) ([]rateTable, error) {
+	reqURL := c.baseURL + path
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			slog.Warn("failed to close response body", "error", closeErr, "url", reqURL)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("ECB returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var env envelope
+	if err := xml.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return nil, fmt.Errorf("failed to decode ECB feed: %w", err)
+	}
+
+	fetched := time.Now()
+	tables := make([]rateTable, 0, len(env.Days))
+	for _, day := range env.Days {
+		date, err := time.Parse(time.DateOnly, day.Time)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ECB rate date %q: %w", day.Time, err)
+		}
+		table := rateTable{
+			date:    date,
+			perEUR:  make(map[string]domain.Decimal, len(day.Rates)),
+			fetched: fetched,
+		}
+		for _, r := range day.Rates {
+			rate, err := domain.NewDecimalFromString(r.Rate)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ECB rate for %s on %s: %w", r.Currency, day.Time, err)
+			}
+			table.perEUR[r.Currency] = rate
+		}
+		tables = append(tables, table)
+	}
+
+	sort.Slice(tables, func(i, j int) bool {
+		return tables[i].date.Before(tables[j].date)
+	})
+
+	slog.Debug("fetched ECB reference rates", "path", path, "days", len(tables))
+	return tables, nil
+}
+
+// rates lists the table as EUR-based rates ordered by currency.
+func (t *rateTable) rates() []domain.FXRate {
+	result := make([]domain.FXRate, 0, len(t.perEUR))
+	for currency, rate := range t.perEUR {
+		result = append(result, domain.FXRate{From: "EUR", To: currency, Rate: rate, AsOf: t.date, Source: Source})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].To < result[j].To
+	})
+	return result
+}
+
+// cross derives the rate between two currencies through EUR.
+func (t *rateTable) cross(from, to string) (*domain.FXRate, error) {
+	fromRate, err := t.unitsPerEUR(from)
+	if err != nil {
+		return nil, err
+	}
+	toRate, err := t.unitsPerEUR(to)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := toRate.Div(fromRate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate cross rate: %w", err)
+	}
+
+	return &domain.FXRate{
+		From:   strings.ToUpper(from),
+		To:     strings.ToUpper(to),
+		Rate:   rate,
+		AsOf:   t.date,
+		Source: Source,
+	}, nil
+}
+
+func (t *rateTable) unitsPerEUR(currency string) (domain.Decimal, error) {
+	code := strings.ToUpper(currency)
+	if code == "EUR" {
+		return domain.NewDecimalFromInt(1), nil
+	}
+	rate, ok := t.perEUR[code]
+	if !ok {
+		return domain.Zero, fmt.Errorf("%w: ECB publishes no %s rate for %s", domain.ErrFXRateNotFound, code, t.date.Format(time.DateOnly))
+	}
+	return rate, nil
+}
+
+func truncateToDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,205 @@
+package ecb
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+const dailyFixture = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time="2024-03-01">
+			<Cube currency="USD" rate="1.0830"/>
+			<Cube currency="GBP" rate="0.80"/>
+			<Cube currency="JPY" rate="162.45"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+const historyFixture = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<Cube>
+		<Cube time="2024-03-01">
+			<Cube currency="USD" rate="1.0830"/>
+			<Cube currency="GBP" rate="0.85635"/>
+		</Cube>
+		<Cube time="2024-02-29">
+			<Cube currency="USD" rate="1.0800"/>
+			<Cube currency="GBP" rate="0.8500"/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func newFixtureServer(t *testing.T, requests *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests != nil {
+			*requests++
+		}
+		switch r.URL.Path {
+		case dailyPath:
+			_, _ = w.Write([]byte(dailyFixture))
+		case historyPath:
+			_, _ = w.Write([]byte(historyFixture))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func mustDecimal(t *testing.T, s string) domain.Decimal {
+	t.Helper()
+	d, err := domain.NewDecimalFromString(s)
+	if err != nil {
+		t.Fatalf("invalid decimal %s: %v", s, err)
+	}
+	return d
+}
+
+func TestGetRate(t *testing.T) {
+	server := newFixtureServer(t, nil)
+	client := NewClientWithBaseURL(server.URL)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{"EUR to USD", "EUR", "USD", "1.0830"},
+		{"GBP to EUR", "GBP", "EUR", "1.25"},
+		{"Cross USD to JPY", "usd", "jpy", "150"},
+		{"Same currency", "EUR", "EUR", "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := client.GetRate(ctx, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("GetRate failed: %v", err)
+			}
+			if rate.Rate.Cmp(mustDecimal(t, tt.want)) != 0 {
+				t.Errorf("expected %s, got %s", tt.want, rate.Rate)
+			}
+			if rate.Source != Source || !rate.AsOf.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("unexpected source or date: %s %v", rate.Source, rate.AsOf)
+			}
+		})
+	}
+
+	if _, err := client.GetRate(ctx, "EUR", "XYZ"); !errors.Is(err, domain.ErrFXRateNotFound) {
+		t.Errorf("expected ErrFXRateNotFound, got %v", err)
+	}
+}
+
+func TestGetRate_CachesDailyFeed(t *testing.T) {
+	requests := 0
+	server := newFixtureServer(t, &requests)
+	client := NewClientWithBaseURL(server.URL)
+	ctx := context.Background()
+
+	_, _ = client.GetRate(ctx, "EUR", "USD")
+	_, _ = client.GetRate(ctx, "EUR", "GBP")
+
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestGetHistoricalRate(t *testing.T) {
+	server := newFixtureServer(t, nil)
+	client := NewClientWithBaseURL(server.URL)
+	ctx := context.Background()
+
+	tests := []struct {
+		name     string
+		date     time.Time
+		want     string
+		wantDate time.Time
+	}{
+		{"Exact date", time.Date(2024, 2, 29, 15, 0, 0, 0, time.UTC), "1.0800", time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+		// Saturday falls back to Friday's rates
+		{"Weekend", time.Date(2024, 3, 2, 0, 0, 0, 0, time.UTC), "1.0830", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := client.GetHistoricalRate(ctx, "EUR", "USD", tt.date)
+			if err != nil {
+				t.Fatalf("GetHistoricalRate failed: %v", err)
+			}
+			if rate.Rate.Cmp(mustDecimal(t, tt.want)) != 0 {
+				t.Errorf("expected %s, got %s", tt.want, rate.Rate)
+			}
+			if !rate.AsOf.Equal(tt.wantDate) {
+				t.Errorf("expected rate of %v, got %v", tt.wantDate, rate.AsOf)
+			}
+		})
+	}
+
+	_, err := client.GetHistoricalRate(ctx, "EUR", "USD", time.Date(1998, 1, 1, 0, 0, 0, 0, time.UTC))
+	if !errors.Is(err, domain.ErrFXRateNotFound) {
+		t.Errorf("expected ErrFXRateNotFound before first publication, got %v", err)
+	}
+}
+
+func TestHistoricalRates(t *testing.T) {
+	server := newFixtureServer(t, nil)
+	client := NewClientWithBaseURL(server.URL)
+
+	rates, err := client.HistoricalRates(context.Background())
+	if err != nil {
+		t.Fatalf("HistoricalRates failed: %v", err)
+	}
+	if len(rates) != 4 {
+		t.Fatalf("expected 4 rates, got %d", len(rates))
+	}
+	if !rates[0].AsOf.Before(rates[3].AsOf) {
+		t.Error("expected rates in ascending date order")
+	}
+	if rates[0].From != "EUR" {
+		t.Errorf("expected EUR based rates, got %s", rates[0].From)
+	}
+}
+
+func TestFetch_Errors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{"HTTP 500", http.StatusInternalServerError, "unavailable"},
+		{"Malformed XML", http.StatusOK, "<gesmes:Envelope"},
+		{"Invalid rate", http.StatusOK, `<Envelope><Cube><Cube time="2024-03-01"><Cube currency="USD" rate="abc"/></Cube></Cube></Envelope>`},
+		{"Invalid date", http.StatusOK, `<Envelope><Cube><Cube time="01/03/2024"><Cube currency="USD" rate="1.1"/></Cube></Cube></Envelope>`},
+		{"Empty feed", http.StatusOK, `<Envelope><Cube></Cube></Envelope>`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClientWithBaseURL(server.URL)
+			if _, err := client.GetRate(context.Background(), "EUR", "USD"); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
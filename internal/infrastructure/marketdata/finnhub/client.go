@@ -8,6 +8,8 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/jmanzanog/stock-tracker/internal/domain"
@@ -19,6 +21,8 @@ const (
 	searchPath     = "/search"
 	quotePath      = "/quote"
 	profilePath    = "/stock/profile2"
+	dividendPath   = "/stock/dividend"
+	candlePath     = "/stock/candle"
 )
 
 // Client implements the MDataProvider interface using Finnhub API.
@@ -95,6 +99,18 @@ type profileResponse struct {
 	Weburl               string  `json:"weburl"`
 }
 
+// dividendResponse represents a single entry of the Finnhub dividend response.
+type dividendResponse struct {
+	Symbol          string  `json:"symbol"`
+	Date            string  `json:"date"` // Ex-dividend date
+	Amount          float64 `json:"amount"`
+	AdjustedAmount  float64 `json:"adjustedAmount"`
+	PayDate         string  `json:"payDate"`
+	RecordDate      string  `json:"recordDate"`
+	DeclarationDate string  `json:"declarationDate"`
+	Currency        string  `json:"currency"`
+}
+
 // SearchByISIN searches for an instrument by its ISIN.
 func (c *Client) SearchByISIN(ctx context.Context, isin string) (*domain.Instrument, error) {
 	params := url.Values{}
@@ -139,7 +155,7 @@ func (c *Client) SearchByISIN(ctx context.Context, isin string) (*domain.Instrum
 	// Get company profile to obtain currency and exchange
 	profile, err := c.getProfile(ctx, result.Symbol)
 
-	var currency, exchange string
+	var currency, exchange, industry, country string
 	if err != nil {
 		// Log warning but continue with extracted exchange (best effort)
 		slog.Warn("failed to get company profile, using fallback values",
@@ -149,6 +165,8 @@ func (c *Client) SearchByISIN(ctx context.Context, isin string) (*domain.Instrum
 	} else {
 		currency = profile.Currency
 		exchange = profile.Exchange
+		industry = profile.FinnhubIndustry
+		country = profile.Country
 		// Use profile name if available (usually more complete)
 		if profile.Name != "" {
 			result.Description = profile.Name
@@ -163,6 +181,10 @@ func (c *Client) SearchByISIN(ctx context.Context, isin string) (*domain.Instrum
 		currency,
 		exchange,
 	)
+	// Finnhub has a single level of classification, used for both
+	instrument.Sector = industry
+	instrument.Industry = industry
+	instrument.Country = country
 
 	return &instrument, nil
 }
@@ -209,6 +231,18 @@ func (c *Client) getProfile(ctx context.Context, symbol string) (*profileRespons
 	return &profileResp, nil
 }
 
+// candleResponse represents the Finnhub candle response, one array per
+// field with an entry for each bar.
+type candleResponse struct {
+	Close     []float64 `json:"c"`
+	High      []float64 `json:"h"`
+	Low       []float64 `json:"l"`
+	Open      []float64 `json:"o"`
+	Timestamp []int64   `json:"t"`
+	Volume    []float64 `json:"v"`
+	Status    string    `json:"s"` // "ok" or "no_data"
+}
+
 // GetQuote retrieves the current quote for a symbol.
 func (c *Client) GetQuote(ctx context.Context, symbol string) (*marketdata.QuoteResult, error) {
 	params := url.Values{}
@@ -258,16 +292,193 @@ func (c *Client) GetQuote(ctx context.Context, symbol string) (*marketdata.Quote
 		Price:    price,
 		Currency: "", // Finnhub quote endpoint doesn't return currency
 		Time:     time.Unix(quoteResp.Timestamp, 0).Format(time.RFC3339),
+		Open:     optionalPrice(quoteResp.Open),
+		High:     optionalPrice(quoteResp.High),
+		Low:      optionalPrice(quoteResp.Low),
 	}, nil
 }
 
+// optionalPrice converts a price Finnhub reports as 0 when it is unknown.
+func optionalPrice(value float64) *domain.Decimal {
+	if value == 0 {
+		return nil
+	}
+	price, err := domain.NewDecimalFromString(fmt.Sprintf("%.4f", value))
+	if err != nil {
+		return nil
+	}
+	return &price
+}
+
+// candleResolutions maps history intervals to Finnhub candle resolutions.
+var candleResolutions = map[marketdata.HistoryInterval]string{
+	marketdata.HistoryDaily:   "D",
+	marketdata.HistoryWeekly:  "W",
+	marketdata.HistoryMonthly: "M",
+}
+
+// GetHistory retrieves the candles between from and to. Finnhub does not
+// return currencies with candles, so the bars have none.
+func (c *Client) GetHistory(ctx context.Context, symbol string, from, to time.Time, interval marketdata.HistoryInterval) ([]marketdata.Bar, error) {
+	resolution, ok := candleResolutions[interval]
+	if !ok {
+		return nil, fmt.Errorf("unsupported history interval: %s", interval)
+	}
+
+	// to is a date, so include the whole day
+	end := to.AddDate(0, 0, 1).Add(-time.Second)
+
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("resolution", resolution)
+	params.Add("from", strconv.FormatInt(from.Unix(), 10))
+	params.Add("to", strconv.FormatInt(end.Unix(), 10))
+	params.Add("token", c.apiKey)
+
+	reqURL := fmt.Sprintf("%s%s?%s", c.baseURL, candlePath, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			slog.Warn("failed to close response body", "error", closeErr, "url", reqURL)
+		}
+	}()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("candle request failed for symbol %s: %w", symbol, marketdata.ErrRateLimited)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var candles candleResponse
+	if err := json.NewDecoder(resp.Body).Decode(&candles); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if candles.Status == "no_data" {
+		return []marketdata.Bar{}, nil
+	}
+	if candles.Status != "ok" {
+		return nil, fmt.Errorf("candle request failed for symbol %s: status %q", symbol, candles.Status)
+	}
+	count := len(candles.Timestamp)
+	if len(candles.Close) != count || len(candles.Open) != count || len(candles.High) != count ||
+		len(candles.Low) != count || len(candles.Volume) != count {
+		return nil, fmt.Errorf("candle request returned mismatched arrays for symbol: %s", symbol)
+	}
+
+	bars := make([]marketdata.Bar, 0, count)
+	for i, timestamp := range candles.Timestamp {
+		closePrice, err := domain.NewDecimalFromString(fmt.Sprintf("%.4f", candles.Close[i]))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse close: %w", err)
+		}
+		var volume *domain.Decimal
+		if v, err := domain.NewDecimalFromString(strconv.FormatFloat(candles.Volume[i], 'f', -1, 64)); err == nil {
+			volume = &v
+		}
+		y, m, d := time.Unix(timestamp, 0).UTC().Date()
+		bars = append(bars, marketdata.Bar{
+			Date:   time.Date(y, m, d, 0, 0, 0, 0, time.UTC),
+			Open:   optionalPrice(candles.Open[i]),
+			High:   optionalPrice(candles.High[i]),
+			Low:    optionalPrice(candles.Low[i]),
+			Close:  closePrice,
+			Volume: volume,
+		})
+	}
+	return bars, nil
+}
+
+// GetDividends retrieves the dividends with an ex-date between from and to.
+func (c *Client) GetDividends(ctx context.Context, symbol string, from, to time.Time) ([]marketdata.DividendEvent, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("from", from.Format(time.DateOnly))
+	params.Add("to", to.Format(time.DateOnly))
+	params.Add("token", c.apiKey)
+
+	reqURL := fmt.Sprintf("%s%s?%s", c.baseURL, dividendPath, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			slog.Warn("failed to close response body", "error", closeErr, "url", reqURL)
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var dividends []dividendResponse
+	if err := json.NewDecoder(resp.Body).Decode(&dividends); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	events := make([]marketdata.DividendEvent, 0, len(dividends))
+	for _, d := range dividends {
+		exDate, err := time.Parse(time.DateOnly, d.Date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse ex-date %q: %w", d.Date, err)
+		}
+		// Pay dates are not always announced yet
+		payDate := exDate
+		if d.PayDate != "" {
+			if payDate, err = time.Parse(time.DateOnly, d.PayDate); err != nil {
+				return nil, fmt.Errorf("failed to parse pay date %q: %w", d.PayDate, err)
+			}
+		}
+		amount, err := domain.NewDecimalFromString(strconv.FormatFloat(d.Amount, 'f', -1, 64))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse amount: %w", err)
+		}
+		events = append(events, marketdata.DividendEvent{
+			Symbol:   symbol,
+			ExDate:   exDate,
+			PayDate:  payDate,
+			Amount:   amount,
+			Currency: d.Currency,
+		})
+	}
+	return events, nil
+}
+
 // mapInstrumentType maps Finnhub security types to domain instrument types.
+// Finnhub reports ETFs and ETNs as "ETP" and funds by structure.
 func mapInstrumentType(finnhubType string) domain.InstrumentType {
-	switch finnhubType {
-	case "ETP", "ETF":
+	switch strings.ToLower(finnhubType) {
+	case "etp", "etf":
 		return domain.InstrumentTypeETF
-	case "Common Stock", "Equity":
-		return domain.InstrumentTypeStock
+	case "open-end fund", "closed-end fund", "mutual fund", "fund":
+		return domain.InstrumentTypeMutualFund
+	case "bond", "corporate bond", "government bond", "municipal bond":
+		return domain.InstrumentTypeBond
+	case "crypto", "cryptocurrency", "digital currency":
+		return domain.InstrumentTypeCrypto
+	case "money market fund", "money market":
+		return domain.InstrumentTypeCashEquivalent
 	default:
 		return domain.InstrumentTypeStock
 	}
@@ -283,3 +494,6 @@ func extractExchange(symbol string) string {
 	}
 	return ""
 }
+
+// Compile-time check that Client implements DividendProvider.
+var _ marketdata.DividendProvider = (*Client)(nil)
@@ -2,6 +2,7 @@ package finnhub
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -73,6 +74,7 @@ func TestClient_SearchByISIN_Success(t *testing.T) {
 				"country": "GB",
 				"currency": "GBP",
 				"exchange": "LONDON STOCK EXCHANGE",
+				"finnhubIndustry": "Aerospace & Defense",
 				"name": "Rolls-Royce Holdings plc",
 				"ticker": "RR.L"
 			}`))
@@ -94,6 +96,9 @@ func TestClient_SearchByISIN_Success(t *testing.T) {
 	assert.Equal(t, "GBP", instrument.Currency)                   // From profile
 	assert.Equal(t, "LONDON STOCK EXCHANGE", instrument.Exchange) // From profile
 	assert.Equal(t, 2, requestCount)                              // Both search and profile called
+	assert.Equal(t, "Aerospace & Defense", instrument.Sector)
+	assert.Equal(t, "Aerospace & Defense", instrument.Industry)
+	assert.Equal(t, "GB", instrument.Country)
 }
 
 func TestClient_SearchByISIN_ETF(t *testing.T) {
@@ -377,6 +382,14 @@ func TestClient_GetQuote_Success(t *testing.T) {
 	assert.Equal(t, "RR.L", quote.Symbol)
 	assert.Equal(t, "5.2300", quote.Price.String())
 	assert.NotEmpty(t, quote.Time)
+	require.NotNil(t, quote.Open)
+	assert.Equal(t, "5.1800", quote.Open.String())
+	require.NotNil(t, quote.High)
+	assert.Equal(t, "5.3000", quote.High.String())
+	require.NotNil(t, quote.Low)
+	assert.Equal(t, "5.1500", quote.Low.String())
+	// The quote endpoint does not report volume
+	assert.Nil(t, quote.Volume)
 }
 
 func TestClient_GetQuote_NoData(t *testing.T) {
@@ -527,6 +540,10 @@ func TestMapInstrumentType(t *testing.T) {
 		{"ETP type", "ETP", domain.InstrumentTypeETF},
 		{"Common Stock type", "Common Stock", domain.InstrumentTypeStock},
 		{"Equity type", "Equity", domain.InstrumentTypeStock},
+		{"Open-End Fund type", "Open-End Fund", domain.InstrumentTypeMutualFund},
+		{"Bond type", "Bond", domain.InstrumentTypeBond},
+		{"Crypto type", "Crypto", domain.InstrumentTypeCrypto},
+		{"Money market type", "Money Market Fund", domain.InstrumentTypeCashEquivalent},
 		{"Unknown type defaults to Stock", "SomethingElse", domain.InstrumentTypeStock},
 		{"Empty type defaults to Stock", "", domain.InstrumentTypeStock},
 	}
@@ -785,3 +802,136 @@ func TestClient_GetProfile_EmptyResponse(t *testing.T) {
 	require.Error(t, err)
 	assert.Contains(t, err.Error(), "no profile data found for symbol")
 }
+
+func TestClient_GetDividends_Success(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/stock/dividend", r.URL.Path)
+		assert.Equal(t, "AAPL", r.URL.Query().Get("symbol"))
+		assert.Equal(t, "2024-01-01", r.URL.Query().Get("from"))
+		assert.Equal(t, "2024-12-31", r.URL.Query().Get("to"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[
+			{"symbol": "AAPL", "date": "2024-02-09", "amount": 0.24, "adjustedAmount": 0.24, "payDate": "2024-02-15", "currency": "USD"},
+			{"symbol": "AAPL", "date": "2024-11-08", "amount": 0.25, "adjustedAmount": 0.25, "payDate": "", "currency": "USD"}
+		]`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.SetBaseURL(server.URL)
+
+	events, err := client.GetDividends(context.Background(), "AAPL",
+		time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC))
+
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "0.24", events[0].Amount.String())
+	assert.Equal(t, "USD", events[0].Currency)
+	assert.Equal(t, time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC), events[0].ExDate)
+	assert.Equal(t, time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC), events[0].PayDate)
+	assert.Equal(t, events[1].ExDate, events[1].PayDate, "missing pay date falls back to the ex-date")
+}
+
+func TestClient_GetDividends_APIError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"error": "You don't have access to this resource."}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.SetBaseURL(server.URL)
+
+	_, err := client.GetDividends(context.Background(), "AAPL", time.Now().AddDate(-1, 0, 0), time.Now())
+
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "API returned status 403")
+}
+
+func TestClient_GetHistory_Success(t *testing.T) {
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/stock/candle", r.URL.Path)
+		assert.Equal(t, "AAPL", r.URL.Query().Get("symbol"))
+		assert.Equal(t, "W", r.URL.Query().Get("resolution"))
+		assert.Equal(t, "1704153600", r.URL.Query().Get("from"))
+		assert.Equal(t, "1704326399", r.URL.Query().Get("to"))
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"c": [185.64, 184.25], "h": [188.44, 185.88], "l": [183.89, 183.43], "o": [187.15, 184.22],
+			"t": [1704153600, 1704240000], "v": [82488700, 58414500], "s": "ok"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.SetBaseURL(server.URL)
+
+	bars, err := client.GetHistory(context.Background(), "AAPL", from, to, marketdata.HistoryWeekly)
+
+	require.NoError(t, err)
+	require.Len(t, bars, 2)
+	assert.Equal(t, from, bars[0].Date)
+	assert.Equal(t, "185.6400", bars[0].Close.String())
+	require.NotNil(t, bars[0].Open)
+	assert.Equal(t, "187.1500", bars[0].Open.String())
+	require.NotNil(t, bars[0].Volume)
+	assert.Equal(t, "82488700", bars[0].Volume.String())
+	assert.Empty(t, bars[0].Currency)
+	assert.Equal(t, to, bars[1].Date)
+}
+
+func TestClient_GetHistory_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		statusCode  int
+		body        string
+		expectedErr string
+		rateLimited bool
+	}{
+		{"Rate limited", http.StatusTooManyRequests, `{"error": "API limit reached."}`, "", true},
+		{"API error", http.StatusForbidden, `{"error": "You don't have access to this resource."}`, "API returned status 403", false},
+		{"Mismatched arrays", http.StatusOK, `{"c": [185.64], "h": [], "l": [], "o": [], "t": [1704153600], "v": [], "s": "ok"}`, "mismatched arrays", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient("test-api-key")
+			client.SetBaseURL(server.URL)
+
+			_, err := client.GetHistory(context.Background(), "AAPL", time.Now().AddDate(-1, 0, 0), time.Now(), marketdata.HistoryDaily)
+
+			require.Error(t, err)
+			assert.Equal(t, tt.rateLimited, errors.Is(err, marketdata.ErrRateLimited))
+			if tt.expectedErr != "" {
+				assert.Contains(t, err.Error(), tt.expectedErr)
+			}
+		})
+	}
+}
+
+func TestClient_GetHistory_NoData(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`{"s": "no_data"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-api-key")
+	client.SetBaseURL(server.URL)
+
+	bars, err := client.GetHistory(context.Background(), "AAPL", time.Now().AddDate(0, 0, -7), time.Now(), marketdata.HistoryDaily)
+
+	require.NoError(t, err)
+	assert.Empty(t, bars)
+}
@@ -2,16 +2,28 @@ package marketdata
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/jmanzanog/stock-tracker/internal/domain"
 )
 
+// ErrRateLimited is wrapped by providers when a request is rejected for
+// exceeding the plan's request rate, so that callers can wait and retry.
+var ErrRateLimited = errors.New("rate limited by market data provider")
+
 // QuoteResult represents a single quote result from a market data provider.
+// Open, High, Low and Volume describe the trading day so far and are nil
+// when the provider does not report them.
 type QuoteResult struct {
 	Symbol   string
 	Price    domain.Decimal
 	Currency string
 	Time     string
+	Open     *domain.Decimal
+	High     *domain.Decimal
+	Low      *domain.Decimal
+	Volume   *domain.Decimal
 }
 
 // SearchResult represents a single search result in a batch operation.
@@ -28,6 +40,16 @@ type QuoteBatchResult struct {
 	Error  error
 }
 
+// DividendEvent is a distribution announced for a symbol.
+// Amount is paid per unit held before ExDate.
+type DividendEvent struct {
+	Symbol   string
+	ExDate   time.Time
+	PayDate  time.Time
+	Amount   domain.Decimal
+	Currency string
+}
+
 // MDataProvider defines the interface for market data providers.
 type MDataProvider interface {
 	SearchByISIN(ctx context.Context, isin string) (*domain.Instrument, error)
@@ -42,3 +64,68 @@ type BatchProvider interface {
 	SearchByISINBatch(ctx context.Context, isins []string) []SearchResult
 	GetQuoteBatch(ctx context.Context, symbols []string) []QuoteBatchResult
 }
+
+// IdentifierProvider defines optional lookup of instruments by identifiers
+// that cannot be mapped to an ISIN offline, such as FIGIs and tickers.
+// The returned instrument must carry its ISIN.
+// YFinance implements this interface for tickers.
+type IdentifierProvider interface {
+	SearchByIdentifier(ctx context.Context, id domain.Identifier) (*domain.Instrument, error)
+}
+
+// FXRateProvider defines the interface for exchange rate sources.
+// GetRate returns how many units of to one unit of from buys.
+type FXRateProvider interface {
+	GetRate(ctx context.Context, from, to string) (*domain.FXRate, error)
+}
+
+// HistoricalFXRateProvider defines optional access to past exchange rates,
+// used to reproduce valuations as of an earlier date.
+type HistoricalFXRateProvider interface {
+	FXRateProvider
+	GetHistoricalRate(ctx context.Context, from, to string, date time.Time) (*domain.FXRate, error)
+}
+
+// HistoricalFXRateBatchProvider defines optional lookup of the rates in
+// effect on many days at once. Days without a rate are left out of the
+// result rather than failing the whole batch.
+type HistoricalFXRateBatchProvider interface {
+	HistoricalFXRateProvider
+	GetHistoricalRates(ctx context.Context, from, to string, dates []time.Time) ([]domain.FXRate, error)
+}
+
+// DividendProvider defines optional access to dividend history.
+// Finnhub implements this interface.
+type DividendProvider interface {
+	GetDividends(ctx context.Context, symbol string, from, to time.Time) ([]DividendEvent, error)
+}
+
+// HistoryInterval is the period each bar of a price history covers.
+type HistoryInterval string
+
+const (
+	HistoryDaily   HistoryInterval = "1day"
+	HistoryWeekly  HistoryInterval = "1week"
+	HistoryMonthly HistoryInterval = "1month"
+)
+
+// Bar is the price range of a symbol over the interval starting at Date.
+// Open, High, Low and Volume are nil when the provider does not report
+// them, and Currency is empty when the provider does not state it.
+type Bar struct {
+	Date     time.Time
+	Open     *domain.Decimal
+	High     *domain.Decimal
+	Low      *domain.Decimal
+	Close    domain.Decimal
+	Volume   *domain.Decimal
+	Currency string
+}
+
+// HistoryProvider defines optional access to past prices, used to backfill
+// the stored price history. GetHistory returns the bars between from and
+// to, inclusive, in date order.
+// TwelveData, Finnhub and YFinance implement this interface.
+type HistoryProvider interface {
+	GetHistory(ctx context.Context, symbol string, from, to time.Time, interval HistoryInterval) ([]Bar, error)
+}
@@ -0,0 +1,96 @@
+package staticfx
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata"
+)
+
+// Provider implements the FXRateProvider interface from a fixed table of
+// rates, each expressed as units of currency per one unit of the base.
+// It is meant for deployments without access to a rate feed and for tests.
+type Provider struct {
+	base  string
+	rates map[string]domain.Decimal
+	asOf  time.Time
+}
+
+var _ marketdata.FXRateProvider = (*Provider)(nil)
+
+// NewProvider creates a provider for rates quoted against base.
+func NewProvider(base string, rates map[string]domain.Decimal) *Provider {
+	normalized := make(map[string]domain.Decimal, len(rates))
+	for code, rate := range rates {
+		normalized[strings.ToUpper(code)] = rate
+	}
+	return &Provider{
+		base:  strings.ToUpper(base),
+		rates: normalized,
+		asOf:  time.Now(),
+	}
+}
+
+// ParseRates parses a list such as "USD=1.0850,GBP=0.8560".
+func ParseRates(spec string) (map[string]domain.Decimal, error) {
+	rates := make(map[string]domain.Decimal)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		code, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid fx rate entry %q: expected CODE=RATE", entry)
+		}
+		rate, err := domain.NewDecimalFromString(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid fx rate for %s: %w", code, err)
+		}
+		if rate.Cmp(domain.Zero) <= 0 {
+			return nil, fmt.Errorf("invalid fx rate for %s: must be positive", code)
+		}
+		rates[strings.ToUpper(strings.TrimSpace(code))] = rate
+	}
+	return rates, nil
+}
+
+// GetRate returns the cross rate between two currencies of the table.
+func (p *Provider) GetRate(_ context.Context, from, to string) (*domain.FXRate, error) {
+	fromRate, err := p.unitsPerBase(from)
+	if err != nil {
+		return nil, err
+	}
+	toRate, err := p.unitsPerBase(to)
+	if err != nil {
+		return nil, err
+	}
+
+	rate, err := toRate.Div(fromRate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate cross rate: %w", err)
+	}
+
+	return &domain.FXRate{
+		From:   strings.ToUpper(from),
+		To:     strings.ToUpper(to),
+		Rate:   rate,
+		AsOf:   p.asOf,
+		Source: "static",
+	}, nil
+}
+
+func (p *Provider) unitsPerBase(currency string) (domain.Decimal, error) {
+	code := strings.ToUpper(currency)
+	if code == p.base {
+		return domain.NewDecimalFromInt(1), nil
+	}
+	rate, ok := p.rates[code]
+	if !ok {
+		return domain.Zero, fmt.Errorf("%w: %s/%s", domain.ErrFXRateNotFound, code, p.base)
+	}
+	return rate, nil
+}
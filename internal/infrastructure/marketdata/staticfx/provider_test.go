@@ -0,0 +1,62 @@
+package staticfx
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+func TestParseRates(t *testing.T) {
+	rates, err := ParseRates("usd=1.25, GBP=0.5,")
+	if err != nil {
+		t.Fatalf("ParseRates failed: %v", err)
+	}
+	if len(rates) != 2 {
+		t.Fatalf("expected 2 rates, got %d", len(rates))
+	}
+	want, _ := domain.NewDecimalFromString("1.25")
+	if !rates["USD"].Equal(want) {
+		t.Errorf("expected USD 1.25, got %s", rates["USD"])
+	}
+
+	for _, spec := range []string{"USD", "USD=abc", "USD=0"} {
+		if _, err := ParseRates(spec); err == nil {
+			t.Errorf("expected error for %q", spec)
+		}
+	}
+}
+
+func TestProvider_GetRate(t *testing.T) {
+	rates, _ := ParseRates("USD=1.25,GBP=0.5")
+	provider := NewProvider("EUR", rates)
+	ctx := context.Background()
+
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{"EUR", "USD", "1.25"},
+		{"USD", "EUR", "0.8"},
+		{"GBP", "USD", "2.5"},
+		{"EUR", "EUR", "1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+tt.to, func(t *testing.T) {
+			rate, err := provider.GetRate(ctx, tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("GetRate failed: %v", err)
+			}
+			want, _ := domain.NewDecimalFromString(tt.want)
+			if !rate.Rate.Equal(want) {
+				t.Errorf("expected %s, got %s", tt.want, rate.Rate)
+			}
+		})
+	}
+
+	if _, err := provider.GetRate(ctx, "JPY", "EUR"); !errors.Is(err, domain.ErrFXRateNotFound) {
+		t.Errorf("expected ErrFXRateNotFound, got %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strings"
 	"time"
 
 	"github.com/jmanzanog/stock-tracker/internal/domain"
@@ -18,6 +19,10 @@ const (
 	defaultBaseURL   = "https://api.twelvedata.com"
 	symbolSearchPath = "/symbol_search"
 	quotePath        = "/quote"
+	timeSeriesPath   = "/time_series"
+
+	// maxOutputSize is the most bars Twelve Data returns for one request.
+	maxOutputSize = 5000
 )
 
 type Client struct {
@@ -43,6 +48,7 @@ type symbolSearchResponse struct {
 		Exchange       string `json:"exchange"`
 		Currency       string `json:"currency"`
 		InstrumentType string `json:"instrument_type"`
+		Country        string `json:"country"`
 	} `json:"data"`
 	Status string `json:"status"`
 }
@@ -53,7 +59,11 @@ type quoteResponse struct {
 	Exchange string `json:"exchange"`
 	Currency string `json:"currency"`
 	Datetime string `json:"datetime"`
+	Open     string `json:"open"`
+	High     string `json:"high"`
+	Low      string `json:"low"`
 	Close    string `json:"close"`
+	Volume   string `json:"volume"`
 	Status   string `json:"status"`
 	Message  string `json:"message"`
 }
@@ -96,19 +106,16 @@ func (c *Client) SearchByISIN(ctx context.Context, isin string) (*domain.Instrum
 	}
 
 	data := searchResp.Data[0]
-	instrumentType := domain.InstrumentTypeStock
-	if data.InstrumentType == "ETF" {
-		instrumentType = domain.InstrumentTypeETF
-	}
-
 	instrument := domain.NewInstrument(
 		isin,
 		data.Symbol,
 		data.InstrumentName,
-		instrumentType,
+		mapInstrumentType(data.InstrumentType),
 		data.Currency,
 		data.Exchange,
 	)
+	// Symbol search reports the country but not the sector or industry
+	instrument.Country = data.Country
 
 	return &instrument, nil
 }
@@ -163,5 +170,140 @@ func (c *Client) GetQuote(ctx context.Context, symbol string) (*marketdata.Quote
 		Price:    price,
 		Currency: quoteResp.Currency,
 		Time:     quoteResp.Datetime,
+		Open:     optionalDecimal(quoteResp.Open),
+		High:     optionalDecimal(quoteResp.High),
+		Low:      optionalDecimal(quoteResp.Low),
+		Volume:   optionalDecimal(quoteResp.Volume),
 	}, nil
 }
+
+type timeSeriesResponse struct {
+	Meta struct {
+		Currency string `json:"currency"`
+	} `json:"meta"`
+	Values []struct {
+		Datetime string `json:"datetime"`
+		Open     string `json:"open"`
+		High     string `json:"high"`
+		Low      string `json:"low"`
+		Close    string `json:"close"`
+		Volume   string `json:"volume"`
+	} `json:"values"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+}
+
+// GetHistory fetches bars from the time_series endpoint, which returns at
+// most 5000 bars per request. An interval without any bars is not an error.
+func (c *Client) GetHistory(ctx context.Context, symbol string, from, to time.Time, interval marketdata.HistoryInterval) ([]marketdata.Bar, error) {
+	params := url.Values{}
+	params.Add("symbol", symbol)
+	params.Add("interval", string(interval))
+	params.Add("start_date", from.Format(time.DateOnly))
+	// end_date is exclusive
+	params.Add("end_date", to.AddDate(0, 0, 1).Format(time.DateOnly))
+	params.Add("order", "ASC")
+	params.Add("outputsize", fmt.Sprint(maxOutputSize))
+	params.Add("apikey", c.apiKey)
+
+	reqURL := fmt.Sprintf("%s%s?%s", c.baseURL, timeSeriesPath, params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			slog.Warn("failed to close response body", "error", closeErr, "url", reqURL)
+		}
+	}()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("time series request failed for symbol %s: %w", symbol, marketdata.ErrRateLimited)
+	}
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var seriesResp timeSeriesResponse
+	if err := json.NewDecoder(resp.Body).Decode(&seriesResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	// Errors are reported in the body with a 200 status
+	if seriesResp.Status == "error" {
+		switch seriesResp.Code {
+		case http.StatusTooManyRequests:
+			return nil, fmt.Errorf("time series request failed for symbol %s: %w: %s", symbol, marketdata.ErrRateLimited, seriesResp.Message)
+		case http.StatusBadRequest:
+			if strings.Contains(strings.ToLower(seriesResp.Message), "no data") {
+				return []marketdata.Bar{}, nil
+			}
+		}
+		return nil, fmt.Errorf("time series request failed for symbol %s: %s", symbol, seriesResp.Message)
+	}
+
+	bars := make([]marketdata.Bar, 0, len(seriesResp.Values))
+	for _, value := range seriesResp.Values {
+		date, err := time.Parse(time.DateOnly, value.Datetime)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse date %q: %w", value.Datetime, err)
+		}
+		if date.After(to) {
+			continue
+		}
+		closePrice, err := domain.NewDecimalFromString(value.Close)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse close of %s: %w", value.Datetime, err)
+		}
+		bars = append(bars, marketdata.Bar{
+			Date:     date,
+			Open:     optionalDecimal(value.Open),
+			High:     optionalDecimal(value.High),
+			Low:      optionalDecimal(value.Low),
+			Close:    closePrice,
+			Volume:   optionalDecimal(value.Volume),
+			Currency: seriesResp.Meta.Currency,
+		})
+	}
+	return bars, nil
+}
+
+// optionalDecimal parses a field the API may leave empty, returning nil
+// when it is missing or not a number.
+func optionalDecimal(raw string) *domain.Decimal {
+	if raw == "" {
+		return nil
+	}
+	value, err := domain.NewDecimalFromString(raw)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
+// mapInstrumentType maps Twelve Data instrument types to domain instrument
+// types.
+func mapInstrumentType(twelveDataType string) domain.InstrumentType {
+	switch strings.ToLower(twelveDataType) {
+	case "etf":
+		return domain.InstrumentTypeETF
+	case "bond":
+		return domain.InstrumentTypeBond
+	case "mutual fund":
+		return domain.InstrumentTypeMutualFund
+	case "digital currency":
+		return domain.InstrumentTypeCrypto
+	case "money market fund":
+		return domain.InstrumentTypeCashEquivalent
+	default:
+		return domain.InstrumentTypeStock
+	}
+}
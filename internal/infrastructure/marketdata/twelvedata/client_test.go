@@ -2,12 +2,15 @@ package twelvedata
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata"
 )
 
 func TestSearchByISIN(t *testing.T) {
@@ -31,7 +34,8 @@ func TestSearchByISIN(t *testing.T) {
 						"instrument_name": "Apple Inc",
 						"exchange": "NASDAQ",
 						"currency": "USD",
-						"instrument_type": "Common Stock"
+						"instrument_type": "Common Stock",
+						"country": "United States"
 					}
 				],
 				"status": "ok"
@@ -143,6 +147,10 @@ func TestSearchByISIN(t *testing.T) {
 			if tt.name == "Success - ETF Found" && result.Type != domain.InstrumentTypeETF {
 				t.Errorf("Expected instrument type ETF, got %v", result.Type)
 			}
+
+			if tt.name == "Success - Stock Found" && result.Country != "United States" {
+				t.Errorf("Expected country United States, got %q", result.Country)
+			}
 		})
 	}
 }
@@ -281,6 +289,148 @@ func TestGetQuote(t *testing.T) {
 	}
 }
 
+func TestGetQuote_DailyBar(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"symbol": "AAPL", "currency": "USD", "datetime": "2023-10-27", "open": "166.91", "high": "168.96", "low": "166.83", "close": "168.22", "volume": "58499129", "status": "ok"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient("test-key")
+	client.baseURL = server.URL
+
+	result, err := client.GetQuote(context.Background(), "AAPL")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if result.Open == nil || result.Open.String() != "166.91" || result.High == nil || result.High.String() != "168.96" ||
+		result.Low == nil || result.Low.String() != "166.83" || result.Volume == nil || result.Volume.String() != "58499129" {
+		t.Errorf("expected the daily bar, got %+v", result)
+	}
+}
+
+func TestGetHistory(t *testing.T) {
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		statusCode    int
+		responseBody  string
+		expectedBars  int
+		expectedError error
+		expectError   bool
+	}{
+		{
+			name:       "Success",
+			statusCode: http.StatusOK,
+			responseBody: `{"meta": {"symbol": "AAPL", "currency": "USD"}, "values": [
+				{"datetime": "2024-01-02", "open": "187.15", "high": "188.44", "low": "183.89", "close": "185.64", "volume": "82488700"},
+				{"datetime": "2024-01-03", "open": "184.22", "high": "185.88", "low": "183.43", "close": "184.25", "volume": "58414500"},
+				{"datetime": "2024-01-04", "open": "182.15", "high": "183.09", "low": "180.88", "close": "181.91", "volume": "71983600"}
+			], "status": "ok"}`,
+			expectedBars: 2,
+		},
+		{
+			name:         "No data",
+			statusCode:   http.StatusOK,
+			responseBody: `{"code": 400, "message": "No data is available on the specified dates. Try setting different start/end dates.", "status": "error"}`,
+			expectedBars: 0,
+		},
+		{
+			name:          "Rate limited in body",
+			statusCode:    http.StatusOK,
+			responseBody:  `{"code": 429, "message": "You have run out of API credits for the current minute.", "status": "error"}`,
+			expectedError: marketdata.ErrRateLimited,
+			expectError:   true,
+		},
+		{
+			name:          "Rate limited status",
+			statusCode:    http.StatusTooManyRequests,
+			responseBody:  `{}`,
+			expectedError: marketdata.ErrRateLimited,
+			expectError:   true,
+		},
+		{
+			name:         "API error",
+			statusCode:   http.StatusOK,
+			responseBody: `{"code": 404, "message": "symbol not found", "status": "error"}`,
+			expectError:  true,
+		},
+		{
+			name:         "Invalid close",
+			statusCode:   http.StatusOK,
+			responseBody: `{"meta": {"currency": "USD"}, "values": [{"datetime": "2024-01-02", "close": "n/a"}], "status": "ok"}`,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				if r.URL.Path != "/time_series" || query.Get("symbol") != "AAPL" || query.Get("interval") != "1day" ||
+					query.Get("start_date") != "2024-01-02" || query.Get("end_date") != "2024-01-04" {
+					t.Errorf("unexpected request %s", r.URL)
+				}
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.responseBody))
+			}))
+			defer server.Close()
+
+			client := NewClient("test-key")
+			client.baseURL = server.URL
+
+			bars, err := client.GetHistory(context.Background(), "AAPL", from, to, marketdata.HistoryDaily)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				if tt.expectedError != nil && !errors.Is(err, tt.expectedError) {
+					t.Errorf("Expected %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if len(bars) != tt.expectedBars {
+				t.Fatalf("Expected %d bars, got %+v", tt.expectedBars, bars)
+			}
+			if tt.expectedBars == 0 {
+				return
+			}
+			first := bars[0]
+			if !first.Date.Equal(from) || first.Close.String() != "185.64" || first.Currency != "USD" ||
+				first.Open == nil || first.Open.String() != "187.15" || first.Volume == nil || first.Volume.String() != "82488700" {
+				t.Errorf("unexpected first bar %+v", first)
+			}
+		})
+	}
+}
+
+func TestMapInstrumentType(t *testing.T) {
+	tests := []struct {
+		apiType  string
+		expected domain.InstrumentType
+	}{
+		{"Common Stock", domain.InstrumentTypeStock},
+		{"ETF", domain.InstrumentTypeETF},
+		{"Bond", domain.InstrumentTypeBond},
+		{"Mutual Fund", domain.InstrumentTypeMutualFund},
+		{"Digital Currency", domain.InstrumentTypeCrypto},
+		{"Money Market Fund", domain.InstrumentTypeCashEquivalent},
+		{"", domain.InstrumentTypeStock},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.apiType, func(t *testing.T) {
+			if result := mapInstrumentType(tt.apiType); result != tt.expected {
+				t.Errorf("mapInstrumentType(%s) = %v, want %v", tt.apiType, result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestNewClient(t *testing.T) {
 	apiKey := "test-key"
 	client := NewClient(apiKey)
@@ -8,6 +8,8 @@ import (
 	"io"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/jmanzanog/stock-tracker/internal/domain"
@@ -20,6 +22,7 @@ const (
 	quotePath       = "/api/v1/quote"
 	searchBatchPath = "/api/v1/search/batch"
 	quoteBatchPath  = "/api/v1/quote/batch"
+	historyPath     = "/api/v1/history"
 )
 
 // Client implements the MDataProvider interface using the yfinance-based Market Data Service.
@@ -70,6 +73,25 @@ type searchResponse struct {
 	Type     string `json:"type"`
 	Currency string `json:"currency"`
 	Exchange string `json:"exchange"`
+	Sector   string `json:"sector"`
+	Industry string `json:"industry"`
+	Country  string `json:"country"`
+}
+
+// instrument converts the search result into a domain instrument.
+func (r searchResponse) instrument() domain.Instrument {
+	instrument := domain.NewInstrument(
+		r.ISIN,
+		r.Symbol,
+		r.Name,
+		mapInstrumentType(r.Type),
+		r.Currency,
+		r.Exchange,
+	)
+	instrument.Sector = r.Sector
+	instrument.Industry = r.Industry
+	instrument.Country = r.Country
+	return instrument
 }
 
 // quoteResponse represents the response from the quote endpoint.
@@ -80,6 +102,20 @@ type quoteResponse struct {
 	Time     string `json:"time"`
 }
 
+// historyResponse represents the response from the history endpoint.
+type historyResponse struct {
+	Symbol   string `json:"symbol"`
+	Currency string `json:"currency"`
+	Bars     []struct {
+		Date   string `json:"date"`
+		Open   string `json:"open"`
+		High   string `json:"high"`
+		Low    string `json:"low"`
+		Close  string `json:"close"`
+		Volume string `json:"volume"`
+	} `json:"bars"`
+}
+
 // errorResponse represents an error response from the API.
 type errorResponse struct {
 	Detail string `json:"detail"`
@@ -87,7 +123,30 @@ type errorResponse struct {
 
 // SearchByISIN searches for an instrument by its ISIN using the Market Data Service.
 func (c *Client) SearchByISIN(ctx context.Context, isin string) (*domain.Instrument, error) {
-	reqURL := fmt.Sprintf("%s%s/%s", c.baseURL, searchPath, isin)
+	return c.search(ctx, isin, "ISIN")
+}
+
+// SearchByIdentifier looks up a ticker through the search endpoint, which
+// accepts Yahoo symbols as well as ISINs. The exchange prefix is not sent,
+// as Yahoo encodes listings as symbol suffixes. FIGIs are not supported.
+func (c *Client) SearchByIdentifier(ctx context.Context, id domain.Identifier) (*domain.Instrument, error) {
+	if id.Type != domain.IdentifierTicker {
+		return nil, fmt.Errorf("lookup by %s is not supported", id.Type)
+	}
+
+	instrument, err := c.search(ctx, id.Value, "symbol")
+	if err != nil {
+		return nil, err
+	}
+	if instrument.ISIN == "" {
+		return nil, fmt.Errorf("no ISIN reported for symbol: %s", id.Value)
+	}
+	return instrument, nil
+}
+
+// search queries the search endpoint; kind names the query in errors.
+func (c *Client) search(ctx context.Context, query, kind string) (*domain.Instrument, error) {
+	reqURL := fmt.Sprintf("%s%s/%s", c.baseURL, searchPath, url.PathEscape(query))
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
 	if err != nil {
@@ -106,7 +165,7 @@ func (c *Client) SearchByISIN(ctx context.Context, isin string) (*domain.Instrum
 	}()
 
 	if resp.StatusCode == http.StatusNotFound {
-		return nil, fmt.Errorf("no instrument found for ISIN: %s", isin)
+		return nil, fmt.Errorf("no instrument found for %s: %s", kind, query)
 	}
 
 	if resp.StatusCode != http.StatusOK {
@@ -123,17 +182,7 @@ func (c *Client) SearchByISIN(ctx context.Context, isin string) (*domain.Instrum
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	instrumentType := mapInstrumentType(searchResp.Type)
-
-	instrument := domain.NewInstrument(
-		searchResp.ISIN,
-		searchResp.Symbol,
-		searchResp.Name,
-		instrumentType,
-		searchResp.Currency,
-		searchResp.Exchange,
-	)
-
+	instrument := searchResp.instrument()
 	return &instrument, nil
 }
 
@@ -192,11 +241,118 @@ func (c *Client) GetQuote(ctx context.Context, symbol string) (*marketdata.Quote
 	}, nil
 }
 
+// historyIntervals maps history intervals to yfinance intervals.
+var historyIntervals = map[marketdata.HistoryInterval]string{
+	marketdata.HistoryDaily:   "1d",
+	marketdata.HistoryWeekly:  "1wk",
+	marketdata.HistoryMonthly: "1mo",
+}
+
+// GetHistory retrieves the bars between from and to from the history
+// endpoint, which passes Yahoo's exclusive end date through.
+func (c *Client) GetHistory(ctx context.Context, symbol string, from, to time.Time, interval marketdata.HistoryInterval) ([]marketdata.Bar, error) {
+	yfInterval, ok := historyIntervals[interval]
+	if !ok {
+		return nil, fmt.Errorf("unsupported history interval: %s", interval)
+	}
+
+	params := url.Values{}
+	params.Add("start", from.Format(time.DateOnly))
+	params.Add("end", to.AddDate(0, 0, 1).Format(time.DateOnly))
+	params.Add("interval", yfInterval)
+
+	reqURL := fmt.Sprintf("%s%s/%s?%s", c.baseURL, historyPath, url.PathEscape(symbol), params.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			slog.Warn("failed to close response body", "error", closeErr, "url", reqURL)
+		}
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("no history found for symbol: %s", symbol)
+	}
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("history request failed for symbol %s: %w", symbol, marketdata.ErrRateLimited)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		var errResp errorResponse
+		if json.Unmarshal(body, &errResp) == nil && errResp.Detail != "" {
+			return nil, fmt.Errorf("API error: %s", errResp.Detail)
+		}
+		return nil, fmt.Errorf("API returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var historyResp historyResponse
+	if err := json.NewDecoder(resp.Body).Decode(&historyResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	bars := make([]marketdata.Bar, 0, len(historyResp.Bars))
+	for _, b := range historyResp.Bars {
+		date, err := time.Parse(time.DateOnly, b.Date)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse date %q: %w", b.Date, err)
+		}
+		if date.After(to) {
+			continue
+		}
+		closePrice, err := domain.NewDecimalFromString(b.Close)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse close of %s: %w", b.Date, err)
+		}
+		bars = append(bars, marketdata.Bar{
+			Date:     date,
+			Open:     optionalDecimal(b.Open),
+			High:     optionalDecimal(b.High),
+			Low:      optionalDecimal(b.Low),
+			Close:    closePrice,
+			Volume:   optionalDecimal(b.Volume),
+			Currency: historyResp.Currency,
+		})
+	}
+	return bars, nil
+}
+
+// optionalDecimal parses a field the service may leave empty, returning
+// nil when it is missing or not a number.
+func optionalDecimal(raw string) *domain.Decimal {
+	if raw == "" {
+		return nil
+	}
+	value, err := domain.NewDecimalFromString(raw)
+	if err != nil {
+		return nil
+	}
+	return &value
+}
+
 // mapInstrumentType maps the API type string to domain InstrumentType.
+// Both the service's own names and Yahoo quote types are accepted.
 func mapInstrumentType(apiType string) domain.InstrumentType {
-	switch apiType {
-	case "etf", "ETF":
+	switch strings.ToLower(apiType) {
+	case "etf":
 		return domain.InstrumentTypeETF
+	case "bond":
+		return domain.InstrumentTypeBond
+	case "mutualfund", "mutual_fund", "fund":
+		return domain.InstrumentTypeMutualFund
+	case "cryptocurrency", "crypto":
+		return domain.InstrumentTypeCrypto
+	case "moneymarket", "money_market", "cash_equivalent":
+		return domain.InstrumentTypeCashEquivalent
 	default:
 		return domain.InstrumentTypeStock
 	}
@@ -314,15 +470,7 @@ func (c *Client) SearchByISINBatch(ctx context.Context, isins []string) []market
 
 	// Process successful results
 	for _, sr := range batchResp.Results {
-		instrumentType := mapInstrumentType(sr.Type)
-		instrument := domain.NewInstrument(
-			sr.ISIN,
-			sr.Symbol,
-			sr.Name,
-			instrumentType,
-			sr.Currency,
-			sr.Exchange,
-		)
+		instrument := sr.instrument()
 		results = append(results, marketdata.SearchResult{
 			ISIN:       sr.ISIN,
 			Instrument: &instrument,
@@ -448,3 +596,6 @@ func (c *Client) GetQuoteBatch(ctx context.Context, symbols []string) []marketda
 
 // Compile-time check that Client implements BatchProvider.
 var _ marketdata.BatchProvider = (*Client)(nil)
+
+// Compile-time check that Client implements IdentifierProvider.
+var _ marketdata.IdentifierProvider = (*Client)(nil)
@@ -2,13 +2,16 @@ package yfinance
 
 import (
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/jmanzanog/stock-tracker/internal/infrastructure/marketdata"
 )
 
 func TestSearchByISIN(t *testing.T) {
@@ -31,7 +34,10 @@ func TestSearchByISIN(t *testing.T) {
 				"name": "Apple Inc.",
 				"type": "stock",
 				"currency": "USD",
-				"exchange": "NASDAQ"
+				"exchange": "NASDAQ",
+				"sector": "Technology",
+				"industry": "Consumer Electronics",
+				"country": "United States"
 			}`,
 			expectedSymbol: "AAPL",
 			expectError:    false,
@@ -140,10 +146,47 @@ func TestSearchByISIN(t *testing.T) {
 			if tt.name == "Success - Stock Found" && result.Type != domain.InstrumentTypeStock {
 				t.Errorf("Expected instrument type Stock, got %v", result.Type)
 			}
+
+			if tt.name == "Success - Stock Found" && (result.Sector != "Technology" || result.Industry != "Consumer Electronics" || result.Country != "United States") {
+				t.Errorf("Expected Technology, Consumer Electronics, United States, got %q, %q, %q", result.Sector, result.Industry, result.Country)
+			}
 		})
 	}
 }
 
+func TestSearchByIdentifier(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/search/AAPL":
+			_, _ = w.Write([]byte(`{"isin": "US0378331005", "symbol": "AAPL", "name": "Apple Inc.", "type": "stock", "currency": "USD", "exchange": "NASDAQ"}`))
+		case "/api/v1/search/NOISIN":
+			_, _ = w.Write([]byte(`{"symbol": "NOISIN", "type": "stock"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	client := NewClientWithBaseURL(server.URL)
+
+	instrument, err := client.SearchByIdentifier(context.Background(), domain.Identifier{Type: domain.IdentifierTicker, Value: "AAPL", Exchange: "NASDAQ"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if instrument.ISIN != "US0378331005" {
+		t.Errorf("expected ISIN US0378331005, got %s", instrument.ISIN)
+	}
+
+	if _, err := client.SearchByIdentifier(context.Background(), domain.Identifier{Type: domain.IdentifierTicker, Value: "NOISIN"}); err == nil {
+		t.Error("expected an error when no ISIN is reported")
+	}
+	if _, err := client.SearchByIdentifier(context.Background(), domain.Identifier{Type: domain.IdentifierTicker, Value: "MISSING"}); err == nil {
+		t.Error("expected an error for an unknown symbol")
+	}
+	if _, err := client.SearchByIdentifier(context.Background(), domain.Identifier{Type: domain.IdentifierFIGI, Value: "BBG000B9XRY4"}); err == nil {
+		t.Error("expected FIGI lookups to be unsupported")
+	}
+}
+
 func TestGetQuote(t *testing.T) {
 	tests := []struct {
 		name           string
@@ -279,6 +322,101 @@ func TestGetQuote(t *testing.T) {
 	}
 }
 
+func TestGetHistory(t *testing.T) {
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name          string
+		statusCode    int
+		mockResponse  string
+		expectedBars  int
+		expectedError error
+		expectError   bool
+	}{
+		{
+			name:       "Success",
+			statusCode: http.StatusOK,
+			mockResponse: `{"symbol": "SAP.DE", "currency": "EUR", "bars": [
+				{"date": "2024-01-02", "open": "139.8000", "high": "141.2000", "low": "139.1000", "close": "140.9000", "volume": "1204532"},
+				{"date": "2024-01-03", "open": "140.5000", "high": "140.9000", "low": "138.2000", "close": "138.6000", "volume": ""},
+				{"date": "2024-01-04", "open": "138.6000", "high": "139.5000", "low": "138.0000", "close": "139.3000", "volume": "998310"}
+			]}`,
+			expectedBars: 2,
+		},
+		{
+			name:         "Empty",
+			statusCode:   http.StatusOK,
+			mockResponse: `{"symbol": "SAP.DE", "currency": "EUR", "bars": []}`,
+			expectedBars: 0,
+		},
+		{
+			name:         "Not Found - 404",
+			statusCode:   http.StatusNotFound,
+			mockResponse: `{"detail": "Symbol not found"}`,
+			expectError:  true,
+		},
+		{
+			name:          "Rate limited",
+			statusCode:    http.StatusTooManyRequests,
+			mockResponse:  `{"detail": "Too Many Requests"}`,
+			expectedError: marketdata.ErrRateLimited,
+			expectError:   true,
+		},
+		{
+			name:         "Server error",
+			statusCode:   http.StatusInternalServerError,
+			mockResponse: `{"detail": "upstream failure"}`,
+			expectError:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				query := r.URL.Query()
+				if r.URL.Path != "/api/v1/history/SAP.DE" || query.Get("start") != "2024-01-02" ||
+					query.Get("end") != "2024-01-04" || query.Get("interval") != "1mo" {
+					t.Errorf("unexpected request %s", r.URL)
+				}
+				w.WriteHeader(tt.statusCode)
+				_, _ = w.Write([]byte(tt.mockResponse))
+			}))
+			defer server.Close()
+
+			client := NewClient()
+			client.SetBaseURL(server.URL)
+
+			bars, err := client.GetHistory(context.Background(), "SAP.DE", from, to, marketdata.HistoryMonthly)
+			if tt.expectError {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				if tt.expectedError != nil && !errors.Is(err, tt.expectedError) {
+					t.Errorf("expected %v, got %v", tt.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(bars) != tt.expectedBars {
+				t.Fatalf("expected %d bars, got %+v", tt.expectedBars, bars)
+			}
+			if tt.expectedBars == 0 {
+				return
+			}
+			if !bars[0].Date.Equal(from) || bars[0].Close.String() != "140.9000" || bars[0].Currency != "EUR" ||
+				bars[0].High == nil || bars[0].High.String() != "141.2000" || bars[0].Volume == nil {
+				t.Errorf("unexpected first bar %+v", bars[0])
+			}
+			if bars[1].Volume != nil {
+				t.Errorf("expected no volume, got %s", bars[1].Volume)
+			}
+		})
+	}
+}
+
 func TestNewClient(t *testing.T) {
 	client := NewClient()
 
@@ -351,6 +489,10 @@ func TestMapInstrumentType(t *testing.T) {
 		{"STOCK", domain.InstrumentTypeStock},
 		{"etf", domain.InstrumentTypeETF},
 		{"ETF", domain.InstrumentTypeETF},
+		{"MUTUALFUND", domain.InstrumentTypeMutualFund},
+		{"bond", domain.InstrumentTypeBond},
+		{"CRYPTOCURRENCY", domain.InstrumentTypeCrypto},
+		{"MONEYMARKET", domain.InstrumentTypeCashEquivalent},
 		{"", domain.InstrumentTypeStock},
 		{"unknown", domain.InstrumentTypeStock},
 	}
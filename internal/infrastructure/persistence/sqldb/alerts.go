@@ -0,0 +1,160 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// SaveAlertRule stores a rule with the state it was last evaluated in.
+func (r *Repository) SaveAlertRule(ctx context.Context, rule *domain.AlertRule) error {
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		if err := r.db.Dialect.UpsertAlertRule(ctx, tx, rule); err != nil {
+			slog.Error("Failed to save alert rule", "rule_id", rule.ID, "error", err)
+			return fmt.Errorf("upsert alert rule: %w", err)
+		}
+		return nil
+	})
+}
+
+// FindAlertRules returns every rule in creation order.
+func (r *Repository) FindAlertRules(ctx context.Context) ([]*domain.AlertRule, error) {
+	query := `
+        SELECT id, kind, isin, threshold, cooldown_minutes, active, last_triggered_at, created_at
+        FROM alert_rules
+        ORDER BY created_at, id
+    `
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("querying alert rules: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	rules := []*domain.AlertRule{}
+	for rows.Next() {
+		var rule domain.AlertRule
+		var kind string
+		var isin sql.NullString
+		var active int
+		var lastTriggered sql.NullTime
+		if err := rows.Scan(&rule.ID, &kind, &isin, &rule.Threshold, &rule.CooldownMinutes, &active, &lastTriggered, &rule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("scanning alert rule: %w", err)
+		}
+		rule.Kind = domain.AlertKind(kind)
+		rule.ISIN = isin.String
+		rule.Active = active != 0
+		if lastTriggered.Valid {
+			triggered := lastTriggered.Time
+			rule.LastTriggeredAt = &triggered
+		}
+		rules = append(rules, &rule)
+	}
+	return rules, rows.Err()
+}
+
+// DeleteAlertRule removes a rule and its alerts, or returns
+// domain.ErrAlertRuleNotFound.
+func (r *Repository) DeleteAlertRule(ctx context.Context, id string) error {
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		query := r.rebind("DELETE FROM alerts WHERE rule_id = $1")
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return fmt.Errorf("failed to delete alerts: %w", err)
+		}
+
+		query = r.rebind("DELETE FROM alert_rules WHERE id = $1")
+		result, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete alert rule: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check deleted alert rule: %w", err)
+		}
+		if affected == 0 {
+			return fmt.Errorf("%w: %s", domain.ErrAlertRuleNotFound, id)
+		}
+		return nil
+	})
+}
+
+// RecordAlert stores an alert and the state of its rule in one
+// transaction. The unique (rule_id, triggered_at) key rejects the same
+// firing stored twice.
+func (r *Repository) RecordAlert(ctx context.Context, rule *domain.AlertRule, alert *domain.Alert) error {
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		if err := r.db.Dialect.UpsertAlertRule(ctx, tx, rule); err != nil {
+			slog.Error("Failed to save alert rule", "rule_id", rule.ID, "error", err)
+			return fmt.Errorf("upsert alert rule: %w", err)
+		}
+
+		query := r.rebind(`
+            INSERT INTO alerts (id, rule_id, kind, isin, observed_value, threshold, message, triggered_at, delivered_at, delivery_error)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        `)
+		if _, err := tx.ExecContext(ctx, query, alert.ID, alert.RuleID, string(alert.Kind), nullString(alert.ISIN), alert.Value,
+			alert.Threshold, alert.Message, alert.TriggeredAt, nullTime(alert.DeliveredAt), nullString(alert.DeliveryError)); err != nil {
+			return fmt.Errorf("failed to insert alert: %w", err)
+		}
+		return nil
+	})
+}
+
+// SaveAlertDelivery stores the delivery outcome of an alert.
+func (r *Repository) SaveAlertDelivery(ctx context.Context, alert *domain.Alert) error {
+	query := r.rebind("UPDATE alerts SET delivered_at = $1, delivery_error = $2 WHERE id = $3")
+	if _, err := r.db.ExecContext(ctx, query, nullTime(alert.DeliveredAt), nullString(alert.DeliveryError), alert.ID); err != nil {
+		return fmt.Errorf("failed to update alert delivery: %w", err)
+	}
+	return nil
+}
+
+// FindAlerts returns the latest alerts, newest first.
+func (r *Repository) FindAlerts(ctx context.Context, limit int) ([]domain.Alert, error) {
+	query := r.rebind(`
+        SELECT id, rule_id, kind, isin, observed_value, threshold, message, triggered_at, delivered_at, delivery_error
+        FROM alerts
+        ORDER BY triggered_at DESC, id
+        FETCH FIRST $1 ROWS ONLY
+    `)
+	rows, err := r.db.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("querying alerts: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	alerts := []domain.Alert{}
+	for rows.Next() {
+		var alert domain.Alert
+		var kind string
+		var isin, deliveryError sql.NullString
+		var delivered sql.NullTime
+		if err := rows.Scan(&alert.ID, &alert.RuleID, &kind, &isin, &alert.Value, &alert.Threshold, &alert.Message,
+			&alert.TriggeredAt, &delivered, &deliveryError); err != nil {
+			return nil, fmt.Errorf("scanning alert: %w", err)
+		}
+		alert.Kind = domain.AlertKind(kind)
+		alert.ISIN = isin.String
+		alert.DeliveryError = deliveryError.String
+		if delivered.Valid {
+			at := delivered.Time
+			alert.DeliveredAt = &at
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, rows.Err()
+}
+
+var _ domain.AlertRepository = (*Repository)(nil)
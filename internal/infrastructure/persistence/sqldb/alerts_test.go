@@ -0,0 +1,62 @@
+package sqldb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_SaveAndFind_Alerts(t *testing.T) {
+	runWithBackends(t, func(t *testing.T, db *DB) {
+		repo := NewRepository(db)
+		ctx := context.Background()
+
+		rule, err := domain.NewAlertRule(domain.AlertPriceAbove, "US0378331005", domain.NewDecimalFromInt(150), 60)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.SaveAlertRule(ctx, rule))
+		portfolioRule, err := domain.NewAlertRule(domain.AlertPortfolioValueBelow, "", domain.NewDecimalFromInt(10000), 0)
+		assert.NoError(t, err)
+		portfolioRule.CreatedAt = rule.CreatedAt.Add(time.Second)
+		assert.NoError(t, repo.SaveAlertRule(ctx, portfolioRule))
+
+		now := time.Now().Truncate(time.Second)
+		alert, changed := rule.Evaluate(domain.NewDecimalFromInt(155), now)
+		assert.True(t, changed)
+		assert.NotNil(t, alert)
+		assert.NoError(t, repo.RecordAlert(ctx, rule, alert))
+		// The same firing is rejected
+		assert.Error(t, repo.RecordAlert(ctx, rule, &domain.Alert{ID: "duplicate", RuleID: rule.ID, Kind: alert.Kind,
+			Value: alert.Value, Threshold: alert.Threshold, Message: alert.Message, TriggeredAt: alert.TriggeredAt}))
+
+		delivered := now.Add(time.Second)
+		alert.DeliveredAt = &delivered
+		assert.NoError(t, repo.SaveAlertDelivery(ctx, alert))
+
+		rules, err := repo.FindAlertRules(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(rules))
+		assert.Equal(t, rule.ID, rules[0].ID)
+		assert.True(t, rules[0].Active)
+		assert.Equal(t, 60, rules[0].CooldownMinutes)
+		assert.NotNil(t, rules[0].LastTriggeredAt)
+		assert.Equal(t, domain.AlertPortfolioValueBelow, rules[1].Kind)
+		assert.Empty(t, rules[1].ISIN)
+		assert.False(t, rules[1].Active)
+
+		alerts, err := repo.FindAlerts(ctx, 10)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(alerts))
+		assert.Equal(t, rule.ID, alerts[0].RuleID)
+		assert.True(t, alerts[0].Value.Equal(domain.NewDecimalFromInt(155)))
+		assert.NotNil(t, alerts[0].DeliveredAt)
+
+		assert.NoError(t, repo.DeleteAlertRule(ctx, rule.ID))
+		alerts, err = repo.FindAlerts(ctx, 10)
+		assert.NoError(t, err)
+		assert.Empty(t, alerts)
+		assert.ErrorIs(t, repo.DeleteAlertRule(ctx, rule.ID), domain.ErrAlertRuleNotFound)
+	})
+}
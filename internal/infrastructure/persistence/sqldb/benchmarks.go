@@ -0,0 +1,43 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// saveBenchmark replaces the stored benchmark of a portfolio, removing it
+// when none is set.
+func (r *Repository) saveBenchmark(ctx context.Context, tx *sql.Tx, p *domain.Portfolio) error {
+	query := r.rebind("DELETE FROM portfolio_benchmarks WHERE portfolio_id = $1")
+	if _, err := tx.ExecContext(ctx, query, p.ID); err != nil {
+		return fmt.Errorf("failed to delete benchmark: %w", err)
+	}
+	if p.BenchmarkISIN == "" {
+		return nil
+	}
+
+	insert := r.rebind("INSERT INTO portfolio_benchmarks (portfolio_id, isin) VALUES ($1, $2)")
+	if _, err := tx.ExecContext(ctx, insert, p.ID, p.BenchmarkISIN); err != nil {
+		return fmt.Errorf("failed to insert benchmark %s: %w", p.BenchmarkISIN, err)
+	}
+	return nil
+}
+
+// loadBenchmark attaches the benchmark of a portfolio, if any.
+func (r *Repository) loadBenchmark(ctx context.Context, p *domain.Portfolio) error {
+	query := r.rebind("SELECT isin FROM portfolio_benchmarks WHERE portfolio_id = $1")
+
+	err := r.db.QueryRowContext(ctx, query, p.ID).Scan(&p.BenchmarkISIN)
+	if errors.Is(err, sql.ErrNoRows) {
+		p.BenchmarkISIN = ""
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("querying benchmark: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// ApplyCorporateAction stores an action applied to the portfolio together
+// with the instrument, position and lots it changed, so that a holding is
+// never left half adjusted.
+func (r *Repository) ApplyCorporateAction(ctx context.Context, p *domain.Portfolio, action *domain.CorporateAction) error {
+	pos, err := p.GetPosition(action.PositionID)
+	if err != nil {
+		return fmt.Errorf("corporate action %s: %w", action.ID, err)
+	}
+
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		// A new ISIN needs its instrument before the position can point to it
+		if err := r.db.Dialect.UpsertInstrument(ctx, tx, &pos.Instrument); err != nil {
+			slog.Error("Failed to save instrument", "isin", pos.Instrument.ISIN, "error", err)
+			return fmt.Errorf("upsert instrument: %w", err)
+		}
+		if action.NewSymbol != "" {
+			query := r.rebind("UPDATE instruments SET symbol = $1 WHERE isin = $2")
+			if _, err := tx.ExecContext(ctx, query, pos.Instrument.Symbol, pos.Instrument.ISIN); err != nil {
+				return fmt.Errorf("failed to rename instrument: %w", err)
+			}
+		}
+
+		pos.PortfolioID = p.ID
+		if err := r.db.Dialect.UpsertPosition(ctx, tx, pos); err != nil {
+			slog.Error("Failed to save position", "position_id", pos.ID, "error", err)
+			return fmt.Errorf("upsert position: %w", err)
+		}
+		for i := range pos.Lots {
+			pos.Lots[i].PositionID = pos.ID
+
+			if err := r.db.Dialect.UpsertLot(ctx, tx, &pos.Lots[i]); err != nil {
+				slog.Error("Failed to save lot", "lot_id", pos.Lots[i].ID, "error", err)
+				return fmt.Errorf("upsert lot: %w", err)
+			}
+		}
+
+		action.PortfolioID = p.ID
+		if err := r.db.Dialect.UpsertCorporateAction(ctx, tx, action); err != nil {
+			slog.Error("Failed to save corporate action", "corporate_action_id", action.ID, "error", err)
+			return fmt.Errorf("upsert corporate action: %w", err)
+		}
+		return nil
+	})
+}
+
+// loadCorporateActions attaches the corporate action history of a portfolio
+// in the order the actions took effect.
+func (r *Repository) loadCorporateActions(ctx context.Context, p *domain.Portfolio) error {
+	query := r.rebind(`
+        SELECT id, portfolio_id, position_id, type, isin, effective_date, ratio_from, ratio_to, old_symbol, new_symbol, new_isin, note, created_at
+        FROM corporate_actions
+        WHERE portfolio_id = $1
+        ORDER BY effective_date, created_at
+    `)
+
+	rows, err := r.db.QueryContext(ctx, query, p.ID)
+	if err != nil {
+		return fmt.Errorf("querying corporate actions: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	p.CorporateActions = []domain.CorporateAction{}
+	for rows.Next() {
+		var a domain.CorporateAction
+		var actionType string
+		var positionID, oldSymbol, newSymbol, newISIN, note sql.NullString
+		var createdAt sql.NullTime
+
+		err := rows.Scan(
+			&a.ID, &a.PortfolioID, &positionID, &actionType, &a.ISIN, &a.EffectiveDate, &a.RatioFrom, &a.RatioTo,
+			&oldSymbol, &newSymbol, &newISIN, &note, &createdAt,
+		)
+		if err != nil {
+			return fmt.Errorf("scanning corporate action: %w", err)
+		}
+		a.Type = domain.CorporateActionType(actionType)
+		a.PositionID = positionID.String
+		a.OldSymbol = oldSymbol.String
+		a.NewSymbol = newSymbol.String
+		a.NewISIN = newISIN.String
+		a.Note = note.String
+		a.CreatedAt = createdAt.Time
+		p.CorporateActions = append(p.CorporateActions, a)
+	}
+
+	return rows.Err()
+}
+
+var _ domain.CorporateActionRepository = (*Repository)(nil)
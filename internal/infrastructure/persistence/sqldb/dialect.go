@@ -3,6 +3,7 @@ package sqldb
 import (
 	"context"
 	"database/sql"
+	"time"
 
 	"github.com/jmanzanog/stock-tracker/internal/domain"
 )
@@ -13,4 +14,69 @@ type Dialect interface {
 	UpsertPortfolio(ctx context.Context, tx *sql.Tx, p *domain.Portfolio) error
 	UpsertInstrument(ctx context.Context, tx *sql.Tx, i *domain.Instrument) error
 	UpsertPosition(ctx context.Context, tx *sql.Tx, p *domain.Position) error
+	UpsertTransaction(ctx context.Context, tx *sql.Tx, t *domain.Transaction) error
+	UpsertLot(ctx context.Context, tx *sql.Tx, l *domain.Lot) error
+	UpsertFXRate(ctx context.Context, tx *sql.Tx, r *domain.FXRate) error
+	UpsertDividend(ctx context.Context, tx *sql.Tx, d *domain.Dividend) error
+	UpsertCorporateAction(ctx context.Context, tx *sql.Tx, a *domain.CorporateAction) error
+	UpsertInstrumentPrice(ctx context.Context, tx *sql.Tx, p *domain.PricePoint) error
+	UpsertWatchlist(ctx context.Context, tx *sql.Tx, w *domain.Watchlist) error
+	UpsertAlertRule(ctx context.Context, tx *sql.Tx, r *domain.AlertRule) error
+	UpsertPortfolioSnapshot(ctx context.Context, tx *sql.Tx, s *domain.PortfolioSnapshot) error
+}
+
+// flag maps a boolean to the 0/1 integer column used by both databases.
+func flag(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// nullString maps an empty optional reference to SQL NULL.
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+// nullTime maps an optional timestamp to SQL NULL.
+func nullTime(t *time.Time) sql.NullTime {
+	if t == nil {
+		return sql.NullTime{}
+	}
+	return sql.NullTime{Time: *t, Valid: true}
+}
+
+// nullDecimal maps an optional decimal to SQL NULL.
+func nullDecimal(d *domain.Decimal) sql.NullString {
+	if d == nil {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: d.String(), Valid: true}
+}
+
+// parseNullDecimal maps an optional decimal column back to nil or a value.
+func parseNullDecimal(s sql.NullString) (*domain.Decimal, error) {
+	if !s.Valid {
+		return nil, nil
+	}
+	d, err := domain.NewDecimalFromString(s.String)
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+// costBasisMethod returns the stored policy of a portfolio, defaulting
+// portfolios created before lot tracking existed.
+func costBasisMethod(p *domain.Portfolio) domain.CostBasisMethod {
+	if p.CostBasisMethod == "" {
+		return domain.DefaultCostBasisMethod
+	}
+	return p.CostBasisMethod
+}
+
+// rateDate truncates the publication time of a rate to its calendar day.
+func rateDate(r *domain.FXRate) time.Time {
+	y, m, d := r.AsOf.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
 }
@@ -0,0 +1,114 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// SaveETFHoldings replaces the stored constituents of an ETF. Issuers
+// publish the full list each time, so there is nothing to merge with the
+// previous rows.
+func (r *Repository) SaveETFHoldings(ctx context.Context, composition *domain.ETFComposition) error {
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		query := r.rebind("DELETE FROM etf_holdings WHERE etf_isin = $1")
+		if _, err := tx.ExecContext(ctx, query, composition.ETFISIN); err != nil {
+			return fmt.Errorf("failed to delete etf holdings: %w", err)
+		}
+
+		insert := r.rebind(`
+            INSERT INTO etf_holdings (etf_isin, line_no, isin, ticker, name, sector, country, currency, asset_class, weight, as_of)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        `)
+		for i := range composition.Holdings {
+			h := &composition.Holdings[i]
+			if _, err := tx.ExecContext(ctx, insert, composition.ETFISIN, i+1, nullString(h.ISIN), nullString(h.Ticker), h.Name,
+				nullString(h.Sector), nullString(h.Country), nullString(h.Currency), nullString(h.AssetClass), h.Weight, composition.AsOf); err != nil {
+				slog.Error("Failed to save etf holding", "etf_isin", composition.ETFISIN, "name", h.Name, "error", err)
+				return fmt.Errorf("failed to insert etf holding %s: %w", h.Name, err)
+			}
+		}
+		return nil
+	})
+}
+
+// FindETFHoldings returns the stored constituents of an ETF in the order
+// of the issuer file, or domain.ErrHoldingsNotFound.
+func (r *Repository) FindETFHoldings(ctx context.Context, etfISIN string) (*domain.ETFComposition, error) {
+	query := r.rebind(`
+        SELECT isin, ticker, name, sector, country, currency, asset_class, weight, as_of
+        FROM etf_holdings
+        WHERE etf_isin = $1
+        ORDER BY line_no
+    `)
+
+	rows, err := r.db.QueryContext(ctx, query, etfISIN)
+	if err != nil {
+		return nil, fmt.Errorf("querying etf holdings: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	composition := &domain.ETFComposition{ETFISIN: etfISIN, TotalWeight: domain.Zero, Holdings: []domain.ETFHolding{}}
+	for rows.Next() {
+		var h domain.ETFHolding
+		var isin, ticker, sector, country, currency, assetClass sql.NullString
+
+		if err := rows.Scan(&isin, &ticker, &h.Name, &sector, &country, &currency, &assetClass, &h.Weight, &composition.AsOf); err != nil {
+			return nil, fmt.Errorf("scanning etf holding: %w", err)
+		}
+		h.ISIN = isin.String
+		h.Ticker = ticker.String
+		h.Sector = sector.String
+		h.Country = country.String
+		h.Currency = currency.String
+		h.AssetClass = assetClass.String
+		if composition.TotalWeight, err = composition.TotalWeight.Add(h.Weight); err != nil {
+			return nil, fmt.Errorf("failed to add etf holding weight: %w", err)
+		}
+		composition.Holdings = append(composition.Holdings, h)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(composition.Holdings) == 0 {
+		return nil, domain.ErrHoldingsNotFound
+	}
+	return composition, nil
+}
+
+// FindETFISINs returns the ISINs of the ETFs with stored constituents, in
+// order.
+func (r *Repository) FindETFISINs(ctx context.Context) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, "SELECT DISTINCT etf_isin FROM etf_holdings ORDER BY etf_isin")
+	if err != nil {
+		return nil, fmt.Errorf("querying etf isins: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	isins := []string{}
+	for rows.Next() {
+		var isin string
+		if err := rows.Scan(&isin); err != nil {
+			return nil, fmt.Errorf("scanning etf isin: %w", err)
+		}
+		isins = append(isins, isin)
+	}
+
+	return isins, rows.Err()
+}
+
+var _ domain.ETFHoldingsRepository = (*Repository)(nil)
@@ -0,0 +1,54 @@
+package sqldb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_SaveAndFind_ETFHoldings(t *testing.T) {
+	runWithBackends(t, func(t *testing.T, db *DB) {
+		repo := NewRepository(db)
+		ctx := context.Background()
+
+		_, err := repo.FindETFHoldings(ctx, "IE00B4L5Y983")
+		assert.ErrorIs(t, err, domain.ErrHoldingsNotFound)
+
+		asOf := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		composition, err := domain.NewETFComposition("IE00B4L5Y983", asOf, []domain.ETFHolding{
+			{ISIN: "US0378331005", Ticker: "AAPL", Name: "APPLE INC", Sector: "Information Technology", Country: "United States", Currency: "USD", AssetClass: "Equity", Weight: domain.NewDecimalFromInt(60)},
+			{Name: "EUR CASH", Currency: "EUR", AssetClass: "Cash", Weight: domain.NewDecimalFromInt(40)},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, repo.SaveETFHoldings(ctx, composition))
+
+		isins, err := repo.FindETFISINs(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"IE00B4L5Y983"}, isins)
+
+		found, err := repo.FindETFHoldings(ctx, "IE00B4L5Y983")
+		assert.NoError(t, err)
+		assert.True(t, found.AsOf.Equal(asOf))
+		assert.True(t, found.TotalWeight.Equal(domain.NewDecimalFromInt(100)))
+		assert.Equal(t, 2, len(found.Holdings))
+		assert.Equal(t, "AAPL", found.Holdings[0].Ticker)
+		assert.Equal(t, "United States", found.Holdings[0].Country)
+		assert.Empty(t, found.Holdings[1].ISIN)
+
+		// Saving again replaces the previous constituents
+		replacement, err := domain.NewETFComposition("IE00B4L5Y983", asOf.AddDate(0, 1, 0), []domain.ETFHolding{
+			{ISIN: "US5949181045", Name: "MICROSOFT CORP", Weight: domain.NewDecimalFromInt(100)},
+		})
+		assert.NoError(t, err)
+		assert.NoError(t, repo.SaveETFHoldings(ctx, replacement))
+
+		found, err = repo.FindETFHoldings(ctx, "IE00B4L5Y983")
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(found.Holdings))
+		assert.Equal(t, "US5949181045", found.Holdings[0].ISIN)
+		assert.True(t, found.AsOf.Equal(asOf.AddDate(0, 1, 0)))
+	})
+}
@@ -0,0 +1,79 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// SaveFXRates stores published exchange rates, replacing any rate already
+// stored for the same currency pair and day.
+func (r *Repository) SaveFXRates(ctx context.Context, rates []domain.FXRate) error {
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		for i := range rates {
+			if err := r.db.Dialect.UpsertFXRate(ctx, tx, &rates[i]); err != nil {
+				slog.Error("Failed to save fx rate", "from", rates[i].From, "to", rates[i].To, "error", err)
+				return fmt.Errorf("upsert fx rate: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// FindFXRate returns the latest stored rate published on or before date.
+func (r *Repository) FindFXRate(ctx context.Context, from, to string, date time.Time) (*domain.FXRate, error) {
+	query := r.rebind(`
+        SELECT from_currency, to_currency, rate_date, rate, source
+        FROM fx_rates
+        WHERE from_currency = $1 AND to_currency = $2 AND rate_date <= $3
+        ORDER BY rate_date DESC
+        FETCH FIRST 1 ROWS ONLY
+    `)
+
+	var rate domain.FXRate
+	err := r.db.QueryRowContext(ctx, query, from, to, date).Scan(&rate.From, &rate.To, &rate.AsOf, &rate.Rate, &rate.Source)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: %s/%s on %s", domain.ErrFXRateNotFound, from, to, date.Format(time.DateOnly))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("querying fx rate: %w", err)
+	}
+	return &rate, nil
+}
+
+// FindFXRates returns the stored rates published between start and end,
+// oldest first.
+func (r *Repository) FindFXRates(ctx context.Context, from, to string, start, end time.Time) ([]domain.FXRate, error) {
+	query := r.rebind(`
+        SELECT from_currency, to_currency, rate_date, rate, source
+        FROM fx_rates
+        WHERE from_currency = $1 AND to_currency = $2 AND rate_date >= $3 AND rate_date <= $4
+        ORDER BY rate_date
+    `)
+
+	rows, err := r.db.QueryContext(ctx, query, from, to, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("querying fx rates: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	var rates []domain.FXRate
+	for rows.Next() {
+		var rate domain.FXRate
+		if err := rows.Scan(&rate.From, &rate.To, &rate.AsOf, &rate.Rate, &rate.Source); err != nil {
+			return nil, fmt.Errorf("scanning fx rate: %w", err)
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
@@ -0,0 +1,50 @@
+package sqldb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_SaveAndFind_FXRates(t *testing.T) {
+	runWithBackends(t, func(t *testing.T, db *DB) {
+		repo := NewRepository(db)
+		ctx := context.Background()
+
+		friday := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		rates := []domain.FXRate{
+			{From: "EUR", To: "USD", Rate: domain.NewDecimalFromInt(2), AsOf: friday.AddDate(0, 0, -1), Source: "ecb"},
+			{From: "EUR", To: "USD", Rate: domain.NewDecimalFromInt(3), AsOf: friday, Source: "ecb"},
+		}
+		assert.NoError(t, repo.SaveFXRates(ctx, rates))
+
+		// Saving the same day again replaces the rate
+		rates[1].Rate = domain.NewDecimalFromInt(4)
+		assert.NoError(t, repo.SaveFXRates(ctx, rates[1:]))
+
+		// A Sunday lookup returns Friday's rate
+		found, err := repo.FindFXRate(ctx, "EUR", "USD", friday.AddDate(0, 0, 2))
+		assert.NoError(t, err)
+		assert.True(t, found.Rate.Equal(domain.NewDecimalFromInt(4)))
+		assert.Equal(t, "ecb", found.Source)
+		assert.True(t, found.AsOf.Equal(friday))
+
+		found, err = repo.FindFXRate(ctx, "EUR", "USD", friday.AddDate(0, 0, -1))
+		assert.NoError(t, err)
+		assert.True(t, found.Rate.Equal(domain.NewDecimalFromInt(2)))
+
+		_, err = repo.FindFXRate(ctx, "EUR", "USD", friday.AddDate(0, 0, -7))
+		assert.ErrorIs(t, err, domain.ErrFXRateNotFound)
+
+		// A range returns every stored day in it, oldest first
+		all, err := repo.FindFXRates(ctx, "EUR", "USD", friday.AddDate(0, 0, -7), friday.AddDate(0, 0, 2))
+		assert.NoError(t, err)
+		if assert.Len(t, all, 2) {
+			assert.True(t, all[0].AsOf.Equal(friday.AddDate(0, 0, -1)))
+			assert.True(t, all[1].Rate.Equal(domain.NewDecimalFromInt(4)))
+		}
+	})
+}
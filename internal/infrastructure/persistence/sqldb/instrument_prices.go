@@ -0,0 +1,72 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// SavePrices stores daily bars, replacing any bar already stored for the
+// same instrument, day and source.
+func (r *Repository) SavePrices(ctx context.Context, prices []domain.PricePoint) error {
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		for i := range prices {
+			if err := r.db.Dialect.UpsertInstrumentPrice(ctx, tx, &prices[i]); err != nil {
+				slog.Error("Failed to save instrument price", "isin", prices[i].ISIN, "date", prices[i].Date, "error", err)
+				return fmt.Errorf("upsert instrument price: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// FindPrices returns the stored bars of an instrument between from and
+// to, inclusive, in date order.
+func (r *Repository) FindPrices(ctx context.Context, isin string, from, to time.Time) ([]domain.PricePoint, error) {
+	query := r.rebind(`
+        SELECT isin, price_date, open_price, high_price, low_price, close_price, volume, currency, source
+        FROM instrument_prices
+        WHERE isin = $1 AND price_date >= $2 AND price_date <= $3
+        ORDER BY price_date, source
+    `)
+
+	rows, err := r.db.QueryContext(ctx, query, isin, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("querying instrument prices: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	prices := []domain.PricePoint{}
+	for rows.Next() {
+		var p domain.PricePoint
+		var open, high, low, volume sql.NullString
+		if err := rows.Scan(&p.ISIN, &p.Date, &open, &high, &low, &p.Close, &volume, &p.Currency, &p.Source); err != nil {
+			return nil, fmt.Errorf("scanning instrument price: %w", err)
+		}
+		var err error
+		if p.Open, err = parseNullDecimal(open); err != nil {
+			return nil, fmt.Errorf("parsing open price of %s: %w", p.ISIN, err)
+		}
+		if p.High, err = parseNullDecimal(high); err != nil {
+			return nil, fmt.Errorf("parsing high price of %s: %w", p.ISIN, err)
+		}
+		if p.Low, err = parseNullDecimal(low); err != nil {
+			return nil, fmt.Errorf("parsing low price of %s: %w", p.ISIN, err)
+		}
+		if p.Volume, err = parseNullDecimal(volume); err != nil {
+			return nil, fmt.Errorf("parsing volume of %s: %w", p.ISIN, err)
+		}
+		prices = append(prices, p)
+	}
+
+	return prices, rows.Err()
+}
@@ -0,0 +1,73 @@
+package sqldb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_SaveAndFind_Prices(t *testing.T) {
+	runWithBackends(t, func(t *testing.T, db *DB) {
+		repo := NewRepository(db)
+		ctx := context.Background()
+
+		friday := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+		prices := []domain.PricePoint{
+			domain.NewPricePoint("IE00B4L5Y983", friday.AddDate(0, 0, -1), domain.NewDecimalFromInt(80), "EUR", "quote"),
+			domain.NewPricePoint("IE00B4L5Y983", friday, domain.NewDecimalFromInt(90), "EUR", "quote"),
+			domain.NewPricePoint("US0378331005", friday, domain.NewDecimalFromInt(180), "USD", "quote"),
+		}
+		high, volume := domain.NewDecimalFromInt(92), domain.NewDecimalFromInt(125000)
+		prices[1].High = &high
+		prices[1].Volume = &volume
+		assert.NoError(t, repo.SavePrices(ctx, prices))
+
+		// Saving the same day and source again replaces the bar
+		prices[1].Close = domain.NewDecimalFromInt(91)
+		assert.NoError(t, repo.SavePrices(ctx, prices[1:2]))
+
+		found, err := repo.FindPrices(ctx, "IE00B4L5Y983", friday.AddDate(0, 0, -7), friday)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(found))
+		assert.True(t, found[0].Date.Equal(friday.AddDate(0, 0, -1)))
+		assert.True(t, found[1].Close.Equal(domain.NewDecimalFromInt(91)))
+		assert.Equal(t, "quote", found[1].Source)
+		assert.NotNil(t, found[1].High)
+		assert.True(t, found[1].High.Equal(high))
+		assert.NotNil(t, found[1].Volume)
+		assert.True(t, found[1].Volume.Equal(volume))
+		assert.Nil(t, found[1].Open)
+		assert.Nil(t, found[0].High)
+
+		found, err = repo.FindPrices(ctx, "IE00B4L5Y983", friday.AddDate(0, 0, 1), friday.AddDate(0, 0, 7))
+		assert.NoError(t, err)
+		assert.Empty(t, found)
+	})
+}
+
+func TestRepository_SaveAndFind_Benchmark(t *testing.T) {
+	runWithBackends(t, func(t *testing.T, db *DB) {
+		repo := NewRepository(db)
+		ctx := context.Background()
+
+		p := domain.NewPortfolio("Benchmark")
+		assert.NoError(t, p.SetBenchmark("IE00B4L5Y983"))
+		assert.NoError(t, repo.Save(ctx, &p))
+
+		found, err := repo.FindByID(ctx, p.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "IE00B4L5Y983", found.BenchmarkISIN)
+
+		// Removing the benchmark deletes the stored row
+		assert.NoError(t, p.SetBenchmark(""))
+		assert.NoError(t, repo.Save(ctx, &p))
+		found, err = repo.FindByID(ctx, p.ID)
+		assert.NoError(t, err)
+		assert.Empty(t, found.BenchmarkISIN)
+
+		assert.NoError(t, repo.Delete(ctx, p.ID))
+	})
+}
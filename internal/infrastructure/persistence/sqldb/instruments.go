@@ -0,0 +1,31 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// UpdateInstrument overwrites the type, type-specific attributes and
+// classification of a stored instrument. Identifiers and names keep the values first reported
+// by the market data provider.
+func (r *Repository) UpdateInstrument(ctx context.Context, instrument *domain.Instrument) error {
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		if err := r.db.Dialect.UpsertInstrument(ctx, tx, instrument); err != nil {
+			return fmt.Errorf("upsert instrument: %w", err)
+		}
+
+		query := r.rebind("UPDATE instruments SET type = $1, coupon_rate = $2, maturity_date = $3, unit_precision = $4, sector = $5, industry = $6, country = $7 WHERE isin = $8")
+		_, err := tx.ExecContext(ctx, query, string(instrument.Type), nullDecimal(instrument.CouponRate),
+			nullTime(instrument.MaturityDate), instrument.UnitPrecision, nullString(instrument.Sector),
+			nullString(instrument.Industry), nullString(instrument.Country), instrument.ISIN)
+		if err != nil {
+			return fmt.Errorf("failed to update instrument %s: %w", instrument.ISIN, err)
+		}
+		return nil
+	})
+}
+
+var _ domain.InstrumentRepository = (*Repository)(nil)
@@ -0,0 +1,18 @@
+CREATE TABLE transactions (
+    id VARCHAR2(36) PRIMARY KEY,
+    portfolio_id VARCHAR2(36) NOT NULL,
+    position_id VARCHAR2(36),
+    instrument_isin VARCHAR2(50) NOT NULL,
+    type VARCHAR2(20) NOT NULL,
+    trade_date TIMESTAMP WITH TIME ZONE NOT NULL,
+    quantity NUMBER NOT NULL,
+    price NUMBER NOT NULL,
+    amount NUMBER NOT NULL,
+    currency VARCHAR2(10) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_tx_port FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE,
+    CONSTRAINT fk_tx_pos FOREIGN KEY (position_id) REFERENCES positions(id) ON DELETE SET NULL
+)
+/
+CREATE INDEX idx_tx_port_date ON transactions (portfolio_id, trade_date)
+/
@@ -0,0 +1,19 @@
+ALTER TABLE portfolios ADD (cost_basis_method VARCHAR2(20) DEFAULT 'average' NOT NULL)
+/
+ALTER TABLE positions ADD (realized_profit_loss NUMBER DEFAULT 0 NOT NULL)
+/
+ALTER TABLE transactions ADD (lot_id VARCHAR2(36))
+/
+CREATE TABLE position_lots (
+    id VARCHAR2(36) PRIMARY KEY,
+    position_id VARCHAR2(36) NOT NULL,
+    transaction_id VARCHAR2(36),
+    acquired_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    quantity NUMBER NOT NULL,
+    remaining_quantity NUMBER NOT NULL,
+    unit_cost NUMBER NOT NULL,
+    CONSTRAINT fk_lot_pos FOREIGN KEY (position_id) REFERENCES positions(id) ON DELETE CASCADE
+)
+/
+CREATE INDEX idx_lot_pos ON position_lots (position_id)
+/
@@ -0,0 +1,2 @@
+ALTER TABLE positions ADD (closed_at TIMESTAMP WITH TIME ZONE)
+/
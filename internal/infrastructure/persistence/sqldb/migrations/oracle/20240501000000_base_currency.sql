@@ -0,0 +1,2 @@
+ALTER TABLE portfolios ADD (base_currency VARCHAR2(3) DEFAULT 'EUR' NOT NULL)
+/
@@ -0,0 +1,9 @@
+CREATE TABLE fx_rates (
+    from_currency VARCHAR2(3) NOT NULL,
+    to_currency VARCHAR2(3) NOT NULL,
+    rate_date DATE NOT NULL,
+    rate NUMBER NOT NULL,
+    source VARCHAR2(20) NOT NULL,
+    CONSTRAINT pk_fx_rates PRIMARY KEY (from_currency, to_currency, rate_date)
+)
+/
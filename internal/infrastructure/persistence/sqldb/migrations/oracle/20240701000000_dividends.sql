@@ -0,0 +1,17 @@
+CREATE TABLE dividends (
+    id VARCHAR2(36) PRIMARY KEY,
+    portfolio_id VARCHAR2(36) NOT NULL,
+    instrument_isin VARCHAR2(50) NOT NULL,
+    ex_date DATE NOT NULL,
+    pay_date DATE NOT NULL,
+    gross_amount NUMBER NOT NULL,
+    withholding_tax NUMBER DEFAULT 0 NOT NULL,
+    currency VARCHAR2(10) NOT NULL,
+    source VARCHAR2(20) NOT NULL,
+    transaction_id VARCHAR2(36),
+    created_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_div_port FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE,
+    CONSTRAINT fk_div_tx FOREIGN KEY (transaction_id) REFERENCES transactions(id) ON DELETE SET NULL,
+    CONSTRAINT uq_div_ex_date UNIQUE (portfolio_id, instrument_isin, ex_date)
+)
+/
@@ -0,0 +1,20 @@
+CREATE TABLE corporate_actions (
+    id VARCHAR2(36) PRIMARY KEY,
+    portfolio_id VARCHAR2(36) NOT NULL,
+    position_id VARCHAR2(36),
+    type VARCHAR2(20) NOT NULL,
+    isin VARCHAR2(50) NOT NULL,
+    effective_date DATE NOT NULL,
+    ratio_from NUMBER DEFAULT 0 NOT NULL,
+    ratio_to NUMBER DEFAULT 0 NOT NULL,
+    old_symbol VARCHAR2(50),
+    new_symbol VARCHAR2(50),
+    new_isin VARCHAR2(50),
+    note VARCHAR2(500),
+    created_at TIMESTAMP WITH TIME ZONE,
+    CONSTRAINT fk_ca_port FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE,
+    CONSTRAINT fk_ca_pos FOREIGN KEY (position_id) REFERENCES positions(id) ON DELETE SET NULL
+)
+/
+CREATE INDEX idx_ca_port ON corporate_actions (portfolio_id)
+/
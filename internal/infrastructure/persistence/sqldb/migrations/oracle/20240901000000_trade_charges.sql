@@ -0,0 +1,10 @@
+ALTER TABLE transactions ADD (fee_amount NUMBER DEFAULT 0 NOT NULL)
+/
+ALTER TABLE transactions ADD (fee_currency VARCHAR2(10))
+/
+ALTER TABLE transactions ADD (tax_amount NUMBER DEFAULT 0 NOT NULL)
+/
+ALTER TABLE transactions ADD (tax_currency VARCHAR2(10))
+/
+ALTER TABLE transactions ADD (costs NUMBER DEFAULT 0 NOT NULL)
+/
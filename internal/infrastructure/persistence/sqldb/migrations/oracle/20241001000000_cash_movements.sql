@@ -0,0 +1,2 @@
+ALTER TABLE transactions MODIFY (instrument_isin NULL)
+/
@@ -0,0 +1,9 @@
+CREATE TABLE target_allocations (
+    portfolio_id VARCHAR2(36) NOT NULL,
+    isin VARCHAR2(50) NOT NULL,
+    asset_class VARCHAR2(50),
+    weight NUMBER NOT NULL,
+    CONSTRAINT pk_target_alloc PRIMARY KEY (portfolio_id, isin),
+    CONSTRAINT fk_ta_port FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+)
+/
@@ -0,0 +1,6 @@
+ALTER TABLE instruments ADD (coupon_rate NUMBER)
+/
+ALTER TABLE instruments ADD (maturity_date DATE)
+/
+ALTER TABLE instruments ADD (unit_precision NUMBER(2) DEFAULT 0 NOT NULL)
+/
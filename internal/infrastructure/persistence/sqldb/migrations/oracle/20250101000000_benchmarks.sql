@@ -0,0 +1,16 @@
+CREATE TABLE instrument_prices (
+    isin VARCHAR2(50) NOT NULL,
+    price_date DATE NOT NULL,
+    close_price NUMBER NOT NULL,
+    currency VARCHAR2(3) NOT NULL,
+    source VARCHAR2(20) NOT NULL,
+    CONSTRAINT pk_instrument_prices PRIMARY KEY (isin, price_date, source)
+)
+/
+CREATE TABLE portfolio_benchmarks (
+    portfolio_id VARCHAR2(36) NOT NULL,
+    isin VARCHAR2(50) NOT NULL,
+    CONSTRAINT pk_portfolio_benchmarks PRIMARY KEY (portfolio_id),
+    CONSTRAINT fk_pb_port FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+)
+/
@@ -0,0 +1,6 @@
+ALTER TABLE instruments ADD (sector VARCHAR2(100))
+/
+ALTER TABLE instruments ADD (industry VARCHAR2(100))
+/
+ALTER TABLE instruments ADD (country VARCHAR2(100))
+/
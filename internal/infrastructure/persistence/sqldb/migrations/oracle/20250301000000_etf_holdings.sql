@@ -0,0 +1,15 @@
+CREATE TABLE etf_holdings (
+    etf_isin VARCHAR2(50) NOT NULL,
+    line_no NUMBER(10) NOT NULL,
+    isin VARCHAR2(50),
+    ticker VARCHAR2(50),
+    name VARCHAR2(255) NOT NULL,
+    sector VARCHAR2(100),
+    country VARCHAR2(100),
+    currency VARCHAR2(3),
+    asset_class VARCHAR2(50),
+    weight NUMBER NOT NULL,
+    as_of DATE NOT NULL,
+    CONSTRAINT pk_etf_holdings PRIMARY KEY (etf_isin, line_no)
+)
+/
@@ -0,0 +1,22 @@
+CREATE TABLE watchlists (
+    id VARCHAR2(36) NOT NULL,
+    name VARCHAR2(255) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT pk_watchlists PRIMARY KEY (id)
+)
+/
+CREATE TABLE watchlist_items (
+    watchlist_id VARCHAR2(36) NOT NULL,
+    instrument_isin VARCHAR2(50) NOT NULL,
+    notes VARCHAR2(2000),
+    target_price NUMBER,
+    last_price NUMBER NOT NULL,
+    previous_close NUMBER NOT NULL,
+    last_updated TIMESTAMP WITH TIME ZONE,
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT pk_watchlist_items PRIMARY KEY (watchlist_id, instrument_isin),
+    CONSTRAINT fk_wi_watchlist FOREIGN KEY (watchlist_id) REFERENCES watchlists(id) ON DELETE CASCADE,
+    CONSTRAINT fk_wi_inst FOREIGN KEY (instrument_isin) REFERENCES instruments(isin)
+)
+/
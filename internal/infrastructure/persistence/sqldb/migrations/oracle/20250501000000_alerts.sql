@@ -0,0 +1,30 @@
+CREATE TABLE alert_rules (
+    id VARCHAR2(36) NOT NULL,
+    kind VARCHAR2(50) NOT NULL,
+    isin VARCHAR2(50),
+    threshold NUMBER NOT NULL,
+    cooldown_minutes NUMBER(10) DEFAULT 0 NOT NULL,
+    active NUMBER(1) DEFAULT 0 NOT NULL,
+    last_triggered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT pk_alert_rules PRIMARY KEY (id)
+)
+/
+CREATE TABLE alerts (
+    id VARCHAR2(36) NOT NULL,
+    rule_id VARCHAR2(36) NOT NULL,
+    kind VARCHAR2(50) NOT NULL,
+    isin VARCHAR2(50),
+    observed_value NUMBER NOT NULL,
+    threshold NUMBER NOT NULL,
+    message VARCHAR2(1000) NOT NULL,
+    triggered_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    delivery_error VARCHAR2(2000),
+    CONSTRAINT pk_alerts PRIMARY KEY (id),
+    CONSTRAINT uq_alerts_rule_time UNIQUE (rule_id, triggered_at),
+    CONSTRAINT fk_alert_rule FOREIGN KEY (rule_id) REFERENCES alert_rules(id) ON DELETE CASCADE
+)
+/
+CREATE INDEX idx_alerts_triggered ON alerts (triggered_at)
+/
@@ -0,0 +1,28 @@
+CREATE TABLE portfolio_snapshots (
+    portfolio_id VARCHAR2(36) NOT NULL,
+    snapshot_date DATE NOT NULL,
+    currency VARCHAR2(3) NOT NULL,
+    total_value NUMBER NOT NULL,
+    total_cash NUMBER NOT NULL,
+    total_invested NUMBER NOT NULL,
+    total_profit_loss NUMBER NOT NULL,
+    net_flow NUMBER NOT NULL,
+    taken_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT pk_portfolio_snapshots PRIMARY KEY (portfolio_id, snapshot_date),
+    CONSTRAINT fk_ps_port FOREIGN KEY (portfolio_id) REFERENCES portfolios(id) ON DELETE CASCADE
+)
+/
+CREATE TABLE position_snapshots (
+    portfolio_id VARCHAR2(36) NOT NULL,
+    snapshot_date DATE NOT NULL,
+    position_id VARCHAR2(36) NOT NULL,
+    isin VARCHAR2(50) NOT NULL,
+    quantity NUMBER NOT NULL,
+    price NUMBER NOT NULL,
+    price_currency VARCHAR2(3) NOT NULL,
+    market_value NUMBER NOT NULL,
+    invested NUMBER NOT NULL,
+    CONSTRAINT pk_position_snapshots PRIMARY KEY (portfolio_id, snapshot_date, position_id),
+    CONSTRAINT fk_pos_snap FOREIGN KEY (portfolio_id, snapshot_date) REFERENCES portfolio_snapshots(portfolio_id, snapshot_date) ON DELETE CASCADE
+)
+/
@@ -0,0 +1,8 @@
+ALTER TABLE instrument_prices ADD (open_price NUMBER)
+/
+ALTER TABLE instrument_prices ADD (high_price NUMBER)
+/
+ALTER TABLE instrument_prices ADD (low_price NUMBER)
+/
+ALTER TABLE instrument_prices ADD (volume NUMBER)
+/
@@ -0,0 +1,2 @@
+ALTER TABLE positions ADD (opened_at TIMESTAMP WITH TIME ZONE)
+/
@@ -0,0 +1,2 @@
+ALTER TABLE transactions ADD (cost_basis_method VARCHAR2(20))
+/
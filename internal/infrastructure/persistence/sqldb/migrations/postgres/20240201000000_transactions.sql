@@ -0,0 +1,20 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS transactions (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    position_id TEXT REFERENCES positions(id) ON DELETE SET NULL,
+    instrument_isin TEXT NOT NULL,
+    type TEXT NOT NULL,
+    trade_date TIMESTAMPTZ NOT NULL,
+    quantity NUMERIC NOT NULL,
+    price NUMERIC NOT NULL,
+    amount NUMERIC NOT NULL,
+    currency TEXT NOT NULL,
+    created_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_transactions_portfolio_trade_date ON transactions (portfolio_id, trade_date);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_portfolio_trade_date;
+DROP TABLE IF EXISTS transactions;
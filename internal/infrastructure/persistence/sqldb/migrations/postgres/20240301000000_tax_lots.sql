@@ -0,0 +1,23 @@
+-- +goose Up
+ALTER TABLE portfolios ADD COLUMN IF NOT EXISTS cost_basis_method TEXT NOT NULL DEFAULT 'average';
+ALTER TABLE positions ADD COLUMN IF NOT EXISTS realized_profit_loss NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS lot_id TEXT;
+
+CREATE TABLE IF NOT EXISTS position_lots (
+    id TEXT PRIMARY KEY,
+    position_id TEXT NOT NULL REFERENCES positions(id) ON DELETE CASCADE,
+    transaction_id TEXT,
+    acquired_at TIMESTAMPTZ NOT NULL,
+    quantity NUMERIC NOT NULL,
+    remaining_quantity NUMERIC NOT NULL,
+    unit_cost NUMERIC NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_position_lots_position ON position_lots (position_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_position_lots_position;
+DROP TABLE IF EXISTS position_lots;
+ALTER TABLE transactions DROP COLUMN IF EXISTS lot_id;
+ALTER TABLE positions DROP COLUMN IF EXISTS realized_profit_loss;
+ALTER TABLE portfolios DROP COLUMN IF EXISTS cost_basis_method;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE positions ADD COLUMN IF NOT EXISTS closed_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE positions DROP COLUMN IF EXISTS closed_at;
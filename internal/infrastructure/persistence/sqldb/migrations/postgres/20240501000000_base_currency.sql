@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE portfolios ADD COLUMN IF NOT EXISTS base_currency TEXT NOT NULL DEFAULT 'EUR';
+
+-- +goose Down
+ALTER TABLE portfolios DROP COLUMN IF EXISTS base_currency;
@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS fx_rates (
+    from_currency TEXT NOT NULL,
+    to_currency TEXT NOT NULL,
+    rate_date DATE NOT NULL,
+    rate NUMERIC NOT NULL,
+    source TEXT NOT NULL,
+    PRIMARY KEY (from_currency, to_currency, rate_date)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS fx_rates;
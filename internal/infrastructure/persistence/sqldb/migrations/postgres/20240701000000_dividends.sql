@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS dividends (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    instrument_isin TEXT NOT NULL,
+    ex_date DATE NOT NULL,
+    pay_date DATE NOT NULL,
+    gross_amount NUMERIC NOT NULL,
+    withholding_tax NUMERIC NOT NULL DEFAULT 0,
+    currency TEXT NOT NULL,
+    source TEXT NOT NULL,
+    transaction_id TEXT REFERENCES transactions(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ,
+    UNIQUE (portfolio_id, instrument_isin, ex_date)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS dividends;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS corporate_actions (
+    id TEXT PRIMARY KEY,
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    position_id TEXT REFERENCES positions(id) ON DELETE SET NULL,
+    type TEXT NOT NULL,
+    isin TEXT NOT NULL,
+    effective_date DATE NOT NULL,
+    ratio_from NUMERIC NOT NULL DEFAULT 0,
+    ratio_to NUMERIC NOT NULL DEFAULT 0,
+    old_symbol TEXT,
+    new_symbol TEXT,
+    new_isin TEXT,
+    note TEXT,
+    created_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_corporate_actions_portfolio ON corporate_actions (portfolio_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_corporate_actions_portfolio;
+DROP TABLE IF EXISTS corporate_actions;
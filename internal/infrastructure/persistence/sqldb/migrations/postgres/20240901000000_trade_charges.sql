@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee_amount NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS fee_currency TEXT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_amount NUMERIC NOT NULL DEFAULT 0;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS tax_currency TEXT;
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS costs NUMERIC NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE transactions DROP COLUMN IF EXISTS costs;
+ALTER TABLE transactions DROP COLUMN IF EXISTS tax_currency;
+ALTER TABLE transactions DROP COLUMN IF EXISTS tax_amount;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fee_currency;
+ALTER TABLE transactions DROP COLUMN IF EXISTS fee_amount;
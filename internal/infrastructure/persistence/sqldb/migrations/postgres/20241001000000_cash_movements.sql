@@ -0,0 +1,7 @@
+-- +goose Up
+-- Deposits and withdrawals are ledger entries without an instrument
+ALTER TABLE transactions ALTER COLUMN instrument_isin DROP NOT NULL;
+
+-- +goose Down
+DELETE FROM transactions WHERE instrument_isin IS NULL;
+ALTER TABLE transactions ALTER COLUMN instrument_isin SET NOT NULL;
@@ -0,0 +1,11 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS target_allocations (
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    isin TEXT NOT NULL,
+    asset_class TEXT,
+    weight NUMERIC NOT NULL,
+    PRIMARY KEY (portfolio_id, isin)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS target_allocations;
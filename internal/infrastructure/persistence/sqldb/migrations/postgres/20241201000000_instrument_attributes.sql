@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE instruments ADD COLUMN IF NOT EXISTS coupon_rate NUMERIC;
+ALTER TABLE instruments ADD COLUMN IF NOT EXISTS maturity_date DATE;
+ALTER TABLE instruments ADD COLUMN IF NOT EXISTS unit_precision INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE instruments DROP COLUMN IF EXISTS unit_precision;
+ALTER TABLE instruments DROP COLUMN IF EXISTS maturity_date;
+ALTER TABLE instruments DROP COLUMN IF EXISTS coupon_rate;
@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS instrument_prices (
+    isin TEXT NOT NULL,
+    price_date DATE NOT NULL,
+    close_price NUMERIC NOT NULL,
+    currency TEXT NOT NULL,
+    source TEXT NOT NULL,
+    PRIMARY KEY (isin, price_date, source)
+);
+
+CREATE TABLE IF NOT EXISTS portfolio_benchmarks (
+    portfolio_id TEXT PRIMARY KEY REFERENCES portfolios(id) ON DELETE CASCADE,
+    isin TEXT NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS portfolio_benchmarks;
+DROP TABLE IF EXISTS instrument_prices;
@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE instruments ADD COLUMN IF NOT EXISTS sector VARCHAR(100);
+ALTER TABLE instruments ADD COLUMN IF NOT EXISTS industry VARCHAR(100);
+ALTER TABLE instruments ADD COLUMN IF NOT EXISTS country VARCHAR(100);
+
+-- +goose Down
+ALTER TABLE instruments DROP COLUMN IF EXISTS country;
+ALTER TABLE instruments DROP COLUMN IF EXISTS industry;
+ALTER TABLE instruments DROP COLUMN IF EXISTS sector;
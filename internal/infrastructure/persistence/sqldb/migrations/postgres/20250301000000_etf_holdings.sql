@@ -0,0 +1,18 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS etf_holdings (
+    etf_isin TEXT NOT NULL,
+    line_no INTEGER NOT NULL,
+    isin TEXT,
+    ticker TEXT,
+    name TEXT NOT NULL,
+    sector TEXT,
+    country TEXT,
+    currency TEXT,
+    asset_class TEXT,
+    weight NUMERIC NOT NULL,
+    as_of DATE NOT NULL,
+    PRIMARY KEY (etf_isin, line_no)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS etf_holdings;
@@ -0,0 +1,23 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS watchlists (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS watchlist_items (
+    watchlist_id TEXT NOT NULL REFERENCES watchlists(id) ON DELETE CASCADE,
+    instrument_isin TEXT NOT NULL REFERENCES instruments(isin) ON DELETE RESTRICT,
+    notes TEXT,
+    target_price NUMERIC,
+    last_price NUMERIC NOT NULL,
+    previous_close NUMERIC NOT NULL,
+    last_updated TIMESTAMPTZ,
+    added_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (watchlist_id, instrument_isin)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS watchlist_items;
+DROP TABLE IF EXISTS watchlists;
@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS alert_rules (
+    id TEXT PRIMARY KEY,
+    kind TEXT NOT NULL,
+    isin TEXT,
+    threshold NUMERIC NOT NULL,
+    cooldown_minutes INTEGER NOT NULL DEFAULT 0,
+    active INTEGER NOT NULL DEFAULT 0,
+    last_triggered_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id TEXT PRIMARY KEY,
+    rule_id TEXT NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    isin TEXT,
+    observed_value NUMERIC NOT NULL,
+    threshold NUMERIC NOT NULL,
+    message TEXT NOT NULL,
+    triggered_at TIMESTAMPTZ NOT NULL,
+    delivered_at TIMESTAMPTZ,
+    delivery_error TEXT,
+    UNIQUE (rule_id, triggered_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerts_triggered_at ON alerts(triggered_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS alerts;
+DROP TABLE IF EXISTS alert_rules;
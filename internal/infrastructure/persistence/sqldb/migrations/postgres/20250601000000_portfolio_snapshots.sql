@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS portfolio_snapshots (
+    portfolio_id TEXT NOT NULL REFERENCES portfolios(id) ON DELETE CASCADE,
+    snapshot_date DATE NOT NULL,
+    currency TEXT NOT NULL,
+    total_value NUMERIC NOT NULL,
+    total_cash NUMERIC NOT NULL,
+    total_invested NUMERIC NOT NULL,
+    total_profit_loss NUMERIC NOT NULL,
+    net_flow NUMERIC NOT NULL,
+    taken_at TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (portfolio_id, snapshot_date)
+);
+
+CREATE TABLE IF NOT EXISTS position_snapshots (
+    portfolio_id TEXT NOT NULL,
+    snapshot_date DATE NOT NULL,
+    position_id TEXT NOT NULL,
+    isin TEXT NOT NULL,
+    quantity NUMERIC NOT NULL,
+    price NUMERIC NOT NULL,
+    price_currency TEXT NOT NULL,
+    market_value NUMERIC NOT NULL,
+    invested NUMERIC NOT NULL,
+    PRIMARY KEY (portfolio_id, snapshot_date, position_id),
+    FOREIGN KEY (portfolio_id, snapshot_date) REFERENCES portfolio_snapshots(portfolio_id, snapshot_date) ON DELETE CASCADE
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS position_snapshots;
+DROP TABLE IF EXISTS portfolio_snapshots;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE instrument_prices ADD COLUMN IF NOT EXISTS open_price NUMERIC;
+ALTER TABLE instrument_prices ADD COLUMN IF NOT EXISTS high_price NUMERIC;
+ALTER TABLE instrument_prices ADD COLUMN IF NOT EXISTS low_price NUMERIC;
+ALTER TABLE instrument_prices ADD COLUMN IF NOT EXISTS volume NUMERIC;
+
+-- +goose Down
+ALTER TABLE instrument_prices DROP COLUMN IF EXISTS volume;
+ALTER TABLE instrument_prices DROP COLUMN IF EXISTS low_price;
+ALTER TABLE instrument_prices DROP COLUMN IF EXISTS high_price;
+ALTER TABLE instrument_prices DROP COLUMN IF EXISTS open_price;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE positions ADD COLUMN IF NOT EXISTS opened_at TIMESTAMPTZ;
+
+-- +goose Down
+ALTER TABLE positions DROP COLUMN IF EXISTS opened_at;
@@ -0,0 +1,5 @@
+-- +goose Up
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS cost_basis_method TEXT;
+
+-- +goose Down
+ALTER TABLE transactions DROP COLUMN IF EXISTS cost_basis_method;
@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"io/fs"
+	"path"
 	"strings"
 
 	"github.com/jmanzanog/stock-tracker/internal/domain"
@@ -16,25 +18,35 @@ func (d *OracleDialect) Name() string { return "oracle" }
 
 func (d *OracleDialect) Migrate(ctx context.Context, db *sql.DB) error {
 	// Goose does not support Oracle natively in a way that is easy to cross-compile with go-ora.
-	// We use the same pattern: read the SQL file and execute it.
-	content, err := migrations.OracleFS.ReadFile("oracle/20240101000000_init.sql")
+	// We use the same pattern: read the SQL files in order and execute them.
+	entries, err := fs.ReadDir(migrations.OracleFS, "oracle")
 	if err != nil {
-		return fmt.Errorf("reading migration file: %w", err)
+		return fmt.Errorf("listing migration files: %w", err)
 	}
 
-	// Split statements by '/' which is standard in Oracle scripts
-	statements := strings.Split(string(content), "/")
-
-	for _, stmt := range statements {
-		stmt = strings.TrimSpace(stmt)
-		if stmt == "" {
-			continue
+	for _, entry := range entries {
+		content, err := migrations.OracleFS.ReadFile(path.Join("oracle", entry.Name()))
+		if err != nil {
+			return fmt.Errorf("reading migration file: %w", err)
 		}
 
-		if _, err := db.ExecContext(ctx, stmt); err != nil {
-			// ORA-00955: name is already used by an existing object
-			if !strings.Contains(err.Error(), "ORA-00955") {
-				return fmt.Errorf("migrating: %s: %w", stmt, err)
+		// Split statements by '/' which is standard in Oracle scripts
+		statements := strings.Split(string(content), "/")
+
+		for _, stmt := range statements {
+			stmt = strings.TrimSpace(stmt)
+			if stmt == "" {
+				continue
+			}
+
+			if _, err := db.ExecContext(ctx, stmt); err != nil {
+				// ORA-00955: name is already used by an existing object
+				// ORA-01430: column being added already exists in table
+				// ORA-01451: column to be modified to NULL cannot be modified to NULL
+				if !strings.Contains(err.Error(), "ORA-00955") && !strings.Contains(err.Error(), "ORA-01430") &&
+					!strings.Contains(err.Error(), "ORA-01451") {
+					return fmt.Errorf("migrating %s: %s: %w", entry.Name(), stmt, err)
+				}
 			}
 		}
 	}
@@ -52,8 +64,8 @@ func (d *OracleDialect) UpsertPortfolio(ctx context.Context, tx *sql.Tx, p *doma
 	if count > 0 {
 		// UPDATE existing
 		_, err = tx.ExecContext(ctx,
-			"UPDATE portfolios SET name = :1, last_updated = :2 WHERE id = :3",
-			p.Name, p.LastUpdated, p.ID,
+			"UPDATE portfolios SET name = :1, cost_basis_method = :2, base_currency = :3, last_updated = :4 WHERE id = :5",
+			p.Name, string(costBasisMethod(p)), p.Currency(), p.LastUpdated, p.ID,
 		)
 		if err != nil {
 			return fmt.Errorf("updating portfolio: %w", err)
@@ -61,8 +73,8 @@ func (d *OracleDialect) UpsertPortfolio(ctx context.Context, tx *sql.Tx, p *doma
 	} else {
 		// INSERT new
 		_, err = tx.ExecContext(ctx,
-			"INSERT INTO portfolios (id, name, last_updated, created_at) VALUES (:1, :2, :3, :4)",
-			p.ID, p.Name, p.LastUpdated, p.CreatedAt,
+			"INSERT INTO portfolios (id, name, cost_basis_method, base_currency, last_updated, created_at) VALUES (:1, :2, :3, :4, :5, :6)",
+			p.ID, p.Name, string(costBasisMethod(p)), p.Currency(), p.LastUpdated, p.CreatedAt,
 		)
 		if err != nil {
 			return fmt.Errorf("inserting portfolio: %w", err)
@@ -82,8 +94,10 @@ func (d *OracleDialect) UpsertInstrument(ctx context.Context, tx *sql.Tx, i *dom
 	// Only insert if not exists (instruments are immutable by ISIN)
 	if count == 0 {
 		_, err = tx.ExecContext(ctx,
-			"INSERT INTO instruments (isin, symbol, name, type, currency, exchange) VALUES (:1, :2, :3, :4, :5, :6)",
+			"INSERT INTO instruments (isin, symbol, name, type, currency, exchange, coupon_rate, maturity_date, unit_precision, sector, industry, country) VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12)",
 			i.ISIN, i.Symbol, i.Name, string(i.Type), i.Currency, i.Exchange,
+			nullDecimal(i.CouponRate), nullTime(i.MaturityDate), i.UnitPrecision,
+			nullString(i.Sector), nullString(i.Industry), nullString(i.Country),
 		)
 		if err != nil {
 			// ORA-00001: unique constraint violation - another transaction inserted the same row
@@ -109,11 +123,11 @@ func (d *OracleDialect) UpsertPosition(ctx context.Context, tx *sql.Tx, p *domai
 		// UPDATE existing
 		_, err = tx.ExecContext(ctx,
 			`UPDATE positions SET 
-				invested_amount = :1, quantity = :2, current_price = :3, 
-				last_updated = :4, portfolio_id = :5 
-			WHERE id = :6`,
-			p.InvestedAmount, p.Quantity, p.CurrentPrice,
-			p.LastUpdated, p.PortfolioID, p.ID,
+				invested_amount = :1, quantity = :2, current_price = :3, realized_profit_loss = :4,
+				opened_at = :5, closed_at = :6, last_updated = :7, portfolio_id = :8, instrument_isin = :9 
+			WHERE id = :10`,
+			p.InvestedAmount, p.Quantity, p.CurrentPrice, p.RealizedProfitLoss,
+			nullTime(p.OpenedAt), nullTime(p.ClosedAt), p.LastUpdated, p.PortfolioID, p.Instrument.ISIN, p.ID,
 		)
 		if err != nil {
 			return fmt.Errorf("updating position: %w", err)
@@ -122,10 +136,10 @@ func (d *OracleDialect) UpsertPosition(ctx context.Context, tx *sql.Tx, p *domai
 		// INSERT new
 		_, err = tx.ExecContext(ctx,
 			`INSERT INTO positions 
-				(id, portfolio_id, instrument_isin, invested_amount, invested_currency, quantity, current_price, last_updated) 
-			VALUES (:1, :2, :3, :4, :5, :6, :7, :8)`,
+				(id, portfolio_id, instrument_isin, invested_amount, invested_currency, quantity, current_price, realized_profit_loss, opened_at, closed_at, last_updated) 
+			VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11)`,
 			p.ID, p.PortfolioID, p.Instrument.ISIN,
-			p.InvestedAmount, p.InvestedCurrency, p.Quantity, p.CurrentPrice, p.LastUpdated,
+			p.InvestedAmount, p.InvestedAmount.Currency, p.Quantity, p.CurrentPrice, p.RealizedProfitLoss, nullTime(p.OpenedAt), nullTime(p.ClosedAt), p.LastUpdated,
 		)
 		if err != nil {
 			return fmt.Errorf("inserting position: %w", err)
@@ -133,3 +147,268 @@ func (d *OracleDialect) UpsertPosition(ctx context.Context, tx *sql.Tx, p *domai
 	}
 	return nil
 }
+
+func (d *OracleDialect) UpsertTransaction(ctx context.Context, tx *sql.Tx, t *domain.Transaction) error {
+	// Check if transaction exists
+	var count int
+	err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM transactions WHERE id = :1", t.ID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("checking transaction existence: %w", err)
+	}
+
+	// Ledger entries are immutable, only insert if not exists
+	if count == 0 {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO transactions
+				(id, portfolio_id, position_id, instrument_isin, type, trade_date, quantity, price, amount, currency, lot_id, created_at,
+				fee_amount, fee_currency, tax_amount, tax_currency, costs, cost_basis_method)
+			VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12, :13, :14, :15, :16, :17, :18)`,
+			t.ID, t.PortfolioID, nullString(t.PositionID), nullString(t.InstrumentISIN), string(t.Type),
+			t.TradeDate, t.Quantity, t.Price, t.Amount, t.Currency, nullString(t.LotID), t.CreatedAt,
+			t.Fee, nullString(t.Fee.Currency), t.Tax, nullString(t.Tax.Currency), t.Costs, nullString(string(t.CostBasisMethod)),
+		)
+		if err != nil {
+			return fmt.Errorf("inserting transaction: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *OracleDialect) UpsertLot(ctx context.Context, tx *sql.Tx, l *domain.Lot) error {
+	// Check if lot exists
+	var count int
+	err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM position_lots WHERE id = :1", l.ID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("checking lot existence: %w", err)
+	}
+
+	if count > 0 {
+		// Sales reduce the remaining quantity; splits also rescale units and unit cost
+		_, err = tx.ExecContext(ctx,
+			"UPDATE position_lots SET quantity = :1, remaining_quantity = :2, unit_cost = :3 WHERE id = :4",
+			l.Quantity, l.RemainingQuantity, l.UnitCost, l.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("updating lot: %w", err)
+		}
+	} else {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO position_lots
+				(id, position_id, transaction_id, acquired_at, quantity, remaining_quantity, unit_cost)
+			VALUES (:1, :2, :3, :4, :5, :6, :7)`,
+			l.ID, l.PositionID, nullString(l.TransactionID), l.AcquiredAt,
+			l.Quantity, l.RemainingQuantity, l.UnitCost,
+		)
+		if err != nil {
+			return fmt.Errorf("inserting lot: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *OracleDialect) UpsertFXRate(ctx context.Context, tx *sql.Tx, r *domain.FXRate) error {
+	day := rateDate(r)
+
+	// Check if a rate was already stored for that day
+	var count int
+	err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM fx_rates WHERE from_currency = :1 AND to_currency = :2 AND rate_date = :3",
+		r.From, r.To, day,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("checking fx rate existence: %w", err)
+	}
+
+	if count > 0 {
+		_, err = tx.ExecContext(ctx,
+			"UPDATE fx_rates SET rate = :1, source = :2 WHERE from_currency = :3 AND to_currency = :4 AND rate_date = :5",
+			r.Rate, r.Source, r.From, r.To, day,
+		)
+		if err != nil {
+			return fmt.Errorf("updating fx rate: %w", err)
+		}
+	} else {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO fx_rates (from_currency, to_currency, rate_date, rate, source) VALUES (:1, :2, :3, :4, :5)",
+			r.From, r.To, day, r.Rate, r.Source,
+		)
+		if err != nil {
+			return fmt.Errorf("inserting fx rate: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *OracleDialect) UpsertInstrumentPrice(ctx context.Context, tx *sql.Tx, p *domain.PricePoint) error {
+	// Check if a close was already stored for that day and source
+	var count int
+	err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM instrument_prices WHERE isin = :1 AND price_date = :2 AND source = :3",
+		p.ISIN, p.Date, p.Source,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("checking instrument price existence: %w", err)
+	}
+
+	if count > 0 {
+		_, err = tx.ExecContext(ctx,
+			"UPDATE instrument_prices SET open_price = :1, high_price = :2, low_price = :3, close_price = :4, volume = :5, currency = :6 WHERE isin = :7 AND price_date = :8 AND source = :9",
+			nullDecimal(p.Open), nullDecimal(p.High), nullDecimal(p.Low), p.Close, nullDecimal(p.Volume), p.Currency, p.ISIN, p.Date, p.Source,
+		)
+		if err != nil {
+			return fmt.Errorf("updating instrument price: %w", err)
+		}
+	} else {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO instrument_prices (isin, price_date, open_price, high_price, low_price, close_price, volume, currency, source) VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9)",
+			p.ISIN, p.Date, nullDecimal(p.Open), nullDecimal(p.High), nullDecimal(p.Low), p.Close, nullDecimal(p.Volume), p.Currency, p.Source,
+		)
+		if err != nil {
+			return fmt.Errorf("inserting instrument price: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *OracleDialect) UpsertDividend(ctx context.Context, tx *sql.Tx, div *domain.Dividend) error {
+	// Check if dividend exists
+	var count int
+	err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM dividends WHERE id = :1", div.ID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("checking dividend existence: %w", err)
+	}
+
+	if count > 0 {
+		// Only the amounts and pay date can be corrected once recorded
+		_, err = tx.ExecContext(ctx,
+			"UPDATE dividends SET pay_date = :1, gross_amount = :2, withholding_tax = :3 WHERE id = :4",
+			div.PayDate, div.GrossAmount, div.WithholdingTax, div.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("updating dividend: %w", err)
+		}
+	} else {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO dividends
+				(id, portfolio_id, instrument_isin, ex_date, pay_date, gross_amount, withholding_tax, currency, source, transaction_id, created_at)
+			VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11)`,
+			div.ID, div.PortfolioID, div.InstrumentISIN, div.ExDate, div.PayDate,
+			div.GrossAmount, div.WithholdingTax, div.Currency(), div.Source, nullString(div.TransactionID), div.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("inserting dividend: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *OracleDialect) UpsertCorporateAction(ctx context.Context, tx *sql.Tx, a *domain.CorporateAction) error {
+	// Check if corporate action exists
+	var count int
+	err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM corporate_actions WHERE id = :1", a.ID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("checking corporate action existence: %w", err)
+	}
+
+	// Applied actions are kept unchanged for audit
+	if count == 0 {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO corporate_actions
+				(id, portfolio_id, position_id, type, isin, effective_date, ratio_from, ratio_to, old_symbol, new_symbol, new_isin, note, created_at)
+			VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9, :10, :11, :12, :13)`,
+			a.ID, a.PortfolioID, nullString(a.PositionID), string(a.Type), a.ISIN, a.EffectiveDate, a.RatioFrom, a.RatioTo,
+			nullString(a.OldSymbol), nullString(a.NewSymbol), nullString(a.NewISIN), nullString(a.Note), a.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("inserting corporate action: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *OracleDialect) UpsertWatchlist(ctx context.Context, tx *sql.Tx, w *domain.Watchlist) error {
+	// Check if watchlist exists
+	var count int
+	err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM watchlists WHERE id = :1", w.ID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("checking watchlist existence: %w", err)
+	}
+
+	if count > 0 {
+		_, err = tx.ExecContext(ctx,
+			"UPDATE watchlists SET name = :1, updated_at = :2 WHERE id = :3",
+			w.Name, w.UpdatedAt, w.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("updating watchlist: %w", err)
+		}
+	} else {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO watchlists (id, name, created_at, updated_at) VALUES (:1, :2, :3, :4)",
+			w.ID, w.Name, w.CreatedAt, w.UpdatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("inserting watchlist: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *OracleDialect) UpsertAlertRule(ctx context.Context, tx *sql.Tx, r *domain.AlertRule) error {
+	// Check if alert rule exists
+	var count int
+	err := tx.QueryRowContext(ctx, "SELECT COUNT(*) FROM alert_rules WHERE id = :1", r.ID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("checking alert rule existence: %w", err)
+	}
+
+	if count > 0 {
+		_, err = tx.ExecContext(ctx,
+			"UPDATE alert_rules SET threshold = :1, cooldown_minutes = :2, active = :3, last_triggered_at = :4 WHERE id = :5",
+			r.Threshold, r.CooldownMinutes, flag(r.Active), nullTime(r.LastTriggeredAt), r.ID,
+		)
+		if err != nil {
+			return fmt.Errorf("updating alert rule: %w", err)
+		}
+	} else {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO alert_rules (id, kind, isin, threshold, cooldown_minutes, active, last_triggered_at, created_at) VALUES (:1, :2, :3, :4, :5, :6, :7, :8)",
+			r.ID, string(r.Kind), nullString(r.ISIN), r.Threshold, r.CooldownMinutes, flag(r.Active), nullTime(r.LastTriggeredAt), r.CreatedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("inserting alert rule: %w", err)
+		}
+	}
+	return nil
+}
+
+func (d *OracleDialect) UpsertPortfolioSnapshot(ctx context.Context, tx *sql.Tx, s *domain.PortfolioSnapshot) error {
+	// Check if a snapshot was already taken that day
+	var count int
+	err := tx.QueryRowContext(ctx,
+		"SELECT COUNT(*) FROM portfolio_snapshots WHERE portfolio_id = :1 AND snapshot_date = :2",
+		s.PortfolioID, s.Date,
+	).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("checking portfolio snapshot existence: %w", err)
+	}
+
+	if count > 0 {
+		_, err = tx.ExecContext(ctx,
+			"UPDATE portfolio_snapshots SET currency = :1, total_value = :2, total_cash = :3, total_invested = :4, total_profit_loss = :5, net_flow = :6, taken_at = :7 WHERE portfolio_id = :8 AND snapshot_date = :9",
+			s.Currency, s.TotalValue, s.TotalCash, s.TotalInvested, s.TotalProfitLoss, s.NetFlow, s.TakenAt, s.PortfolioID, s.Date,
+		)
+		if err != nil {
+			return fmt.Errorf("updating portfolio snapshot: %w", err)
+		}
+	} else {
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO portfolio_snapshots (portfolio_id, snapshot_date, currency, total_value, total_cash, total_invested, total_profit_loss, net_flow, taken_at) VALUES (:1, :2, :3, :4, :5, :6, :7, :8, :9)",
+			s.PortfolioID, s.Date, s.Currency, s.TotalValue, s.TotalCash, s.TotalInvested, s.TotalProfitLoss, s.NetFlow, s.TakenAt,
+		)
+		if err != nil {
+			return fmt.Errorf("inserting portfolio snapshot: %w", err)
+		}
+	}
+	return nil
+}
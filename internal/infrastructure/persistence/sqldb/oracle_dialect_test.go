@@ -2,6 +2,7 @@ package sqldb
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -32,7 +33,7 @@ func TestOracleDialect_UpsertPortfolio_Insert(t *testing.T) {
 
 	// 2. INSERT
 	mock.ExpectExec(`INSERT INTO portfolios`).
-		WithArgs(p.ID, p.Name, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WithArgs(p.ID, p.Name, string(domain.CostBasisAverage), domain.DefaultBaseCurrency, sqlmock.AnyArg(), sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	ctx := context.Background()
@@ -64,7 +65,7 @@ func TestOracleDialect_UpsertPortfolio_Update(t *testing.T) {
 
 	// 2. UPDATE
 	mock.ExpectExec(`UPDATE portfolios SET`).
-		WithArgs(p.Name, sqlmock.AnyArg(), p.ID).
+		WithArgs(p.Name, string(domain.CostBasisAverage), domain.DefaultBaseCurrency, sqlmock.AnyArg(), p.ID).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	ctx := context.Background()
@@ -93,7 +94,9 @@ func TestOracleDialect_UpsertInstrument_Insert(t *testing.T) {
 
 	// 2. INSERT
 	mock.ExpectExec(`INSERT INTO instruments`).
-		WithArgs(inst.ISIN, inst.Symbol, inst.Name, string(inst.Type), inst.Currency, inst.Exchange).
+		WithArgs(inst.ISIN, inst.Symbol, inst.Name, string(inst.Type), inst.Currency, inst.Exchange,
+			nullDecimal(inst.CouponRate), nullTime(inst.MaturityDate), inst.UnitPrecision,
+			nullString(inst.Sector), nullString(inst.Industry), nullString(inst.Country)).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
 	ctx := context.Background()
@@ -153,7 +156,7 @@ func TestOracleDialect_UpsertPosition_Insert(t *testing.T) {
 	mock.ExpectExec(`INSERT INTO positions`).
 		WithArgs(
 			pos.ID, pos.PortfolioID, pos.Instrument.ISIN,
-			pos.InvestedAmount, pos.InvestedCurrency, pos.Quantity, pos.CurrentPrice, sqlmock.AnyArg(),
+			pos.InvestedAmount, pos.InvestedAmount.Currency, pos.Quantity, pos.CurrentPrice, pos.RealizedProfitLoss, sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(),
 		).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
@@ -187,8 +190,8 @@ func TestOracleDialect_UpsertPosition_Update(t *testing.T) {
 	// 2. UPDATE
 	mock.ExpectExec(`UPDATE positions SET`).
 		WithArgs(
-			pos.InvestedAmount, pos.Quantity, pos.CurrentPrice,
-			sqlmock.AnyArg(), pos.PortfolioID, pos.ID,
+			pos.InvestedAmount, pos.Quantity, pos.CurrentPrice, pos.RealizedProfitLoss,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), pos.PortfolioID, pos.Instrument.ISIN, pos.ID,
 		).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
@@ -198,3 +201,580 @@ func TestOracleDialect_UpsertPosition_Update(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestOracleDialect_UpsertTransaction_Insert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	trade := domain.NewTransaction(domain.TransactionTypeBuy, "US123", time.Now(),
+		domain.NewDecimalFromInt(10), domain.NewDecimalFromInt(100), domain.NewDecimalFromInt(1000), "USD")
+	trade.PortfolioID = "port-1"
+	trade.PositionID = "pos-1"
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 0 (not exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM transactions WHERE id = :1`).
+		WithArgs(trade.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// 2. INSERT
+	mock.ExpectExec(`INSERT INTO transactions`).
+		WithArgs(
+			trade.ID, trade.PortfolioID, sqlmock.AnyArg(), trade.InstrumentISIN, string(trade.Type),
+			sqlmock.AnyArg(), trade.Quantity, trade.Price, trade.Amount, trade.Currency, sqlmock.AnyArg(), sqlmock.AnyArg(),
+			trade.Fee, sqlmock.AnyArg(), trade.Tax, sqlmock.AnyArg(), trade.Costs, sqlmock.AnyArg(),
+		).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertTransaction(ctx, tx, &trade)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertTransaction_Skip(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	trade := domain.NewTransaction(domain.TransactionTypeBuy, "US123", time.Now(),
+		domain.NewDecimalFromInt(10), domain.NewDecimalFromInt(100), domain.NewDecimalFromInt(1000), "USD")
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 1 (ledger entries are immutable, skip insert)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM transactions WHERE id = :1`).
+		WithArgs(trade.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	ctx := context.Background()
+	err = dialect.UpsertTransaction(ctx, tx, &trade)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertLot_Insert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	lot, err := domain.NewLot("tx-1", time.Now(), domain.NewDecimalFromInt(10), domain.NewDecimalFromInt(1000))
+	assert.NoError(t, err)
+	lot.PositionID = "pos-1"
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 0 (not exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM position_lots WHERE id = :1`).
+		WithArgs(lot.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// 2. INSERT
+	mock.ExpectExec(`INSERT INTO position_lots`).
+		WithArgs(lot.ID, lot.PositionID, sqlmock.AnyArg(), sqlmock.AnyArg(), lot.Quantity, lot.RemainingQuantity, lot.UnitCost).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertLot(ctx, tx, &lot)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertLot_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	lot, err := domain.NewLot("tx-1", time.Now(), domain.NewDecimalFromInt(10), domain.NewDecimalFromInt(1000))
+	assert.NoError(t, err)
+	lot.RemainingQuantity = domain.NewDecimalFromInt(4)
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 1 (exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM position_lots WHERE id = :1`).
+		WithArgs(lot.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	// 2. UPDATE
+	mock.ExpectExec(`UPDATE position_lots SET quantity = :1, remaining_quantity = :2, unit_cost = :3 WHERE id = :4`).
+		WithArgs(lot.Quantity, lot.RemainingQuantity, lot.UnitCost, lot.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertLot(ctx, tx, &lot)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertFXRate_Insert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	rate := domain.FXRate{From: "EUR", To: "USD", Rate: domain.NewDecimalFromInt(2), AsOf: time.Date(2024, 3, 1, 16, 0, 0, 0, time.UTC), Source: "ecb"}
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 0 (not exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM fx_rates WHERE from_currency = :1 AND to_currency = :2 AND rate_date = :3`).
+		WithArgs("EUR", "USD", day).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// 2. INSERT
+	mock.ExpectExec(`INSERT INTO fx_rates`).
+		WithArgs("EUR", "USD", day, rate.Rate, "ecb").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertFXRate(ctx, tx, &rate)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertFXRate_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	rate := domain.FXRate{From: "EUR", To: "USD", Rate: domain.NewDecimalFromInt(2), AsOf: time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), Source: "ecb"}
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 1 (exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM fx_rates`).
+		WithArgs("EUR", "USD", rate.AsOf).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	// 2. UPDATE
+	mock.ExpectExec(`UPDATE fx_rates SET rate = :1, source = :2`).
+		WithArgs(rate.Rate, "ecb", "EUR", "USD", rate.AsOf).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertFXRate(ctx, tx, &rate)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertInstrumentPrice_Insert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	price := domain.NewPricePoint("IE00B4L5Y983", time.Date(2024, 3, 1, 17, 0, 0, 0, time.UTC), domain.NewDecimalFromInt(90), "EUR", "quote")
+	open, volume := domain.NewDecimalFromInt(88), domain.NewDecimalFromInt(125000)
+	price.Open = &open
+	price.Volume = &volume
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 0 (not exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM instrument_prices WHERE isin = :1 AND price_date = :2 AND source = :3`).
+		WithArgs("IE00B4L5Y983", price.Date, "quote").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// 2. INSERT
+	mock.ExpectExec(`INSERT INTO instrument_prices`).
+		WithArgs("IE00B4L5Y983", price.Date, sql.NullString{String: "88", Valid: true}, sql.NullString{}, sql.NullString{},
+			price.Close, sql.NullString{String: "125000", Valid: true}, "EUR", "quote").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertInstrumentPrice(ctx, tx, &price)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertInstrumentPrice_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	price := domain.NewPricePoint("IE00B4L5Y983", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC), domain.NewDecimalFromInt(91), "EUR", "quote")
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 1 (exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM instrument_prices`).
+		WithArgs("IE00B4L5Y983", price.Date, "quote").
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	// 2. UPDATE
+	mock.ExpectExec(`UPDATE instrument_prices SET open_price = :1, high_price = :2, low_price = :3, close_price = :4, volume = :5, currency = :6`).
+		WithArgs(sql.NullString{}, sql.NullString{}, sql.NullString{}, price.Close, sql.NullString{}, "EUR", "IE00B4L5Y983", price.Date, "quote").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertInstrumentPrice(ctx, tx, &price)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertDividend_Insert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	exDate := time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC)
+	div := domain.NewDividend("US0378331005", exDate, exDate.AddDate(0, 0, 6), domain.NewDecimalFromInt(24), domain.NewDecimalFromInt(4), "USD", domain.DividendSourceManual)
+	div.PortfolioID = "port-1"
+	div.TransactionID = "tx-1"
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 0 (not exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM dividends WHERE id = :1`).
+		WithArgs(div.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// 2. INSERT
+	mock.ExpectExec(`INSERT INTO dividends`).
+		WithArgs(div.ID, "port-1", "US0378331005", div.ExDate, div.PayDate,
+			div.GrossAmount, div.WithholdingTax, "USD", domain.DividendSourceManual, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertDividend(ctx, tx, &div)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertDividend_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	exDate := time.Date(2024, 2, 9, 0, 0, 0, 0, time.UTC)
+	div := domain.NewDividend("US0378331005", exDate, exDate, domain.NewDecimalFromInt(24), domain.Zero, "USD", domain.DividendSourceProvider)
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 1 (exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM dividends WHERE id = :1`).
+		WithArgs(div.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	// 2. UPDATE
+	mock.ExpectExec(`UPDATE dividends SET pay_date = :1, gross_amount = :2, withholding_tax = :3 WHERE id = :4`).
+		WithArgs(div.PayDate, div.GrossAmount, div.WithholdingTax, div.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertDividend(ctx, tx, &div)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertCorporateAction_Insert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	action := domain.NewCorporateAction(domain.CorporateActionSplit, "US0378331005", time.Date(2020, 8, 31, 0, 0, 0, 0, time.UTC))
+	action.RatioFrom = domain.NewDecimalFromInt(1)
+	action.RatioTo = domain.NewDecimalFromInt(4)
+	action.PortfolioID = "port-1"
+	action.PositionID = "pos-1"
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 0 (not exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM corporate_actions WHERE id = :1`).
+		WithArgs(action.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// 2. INSERT
+	mock.ExpectExec(`INSERT INTO corporate_actions`).
+		WithArgs(action.ID, "port-1", "pos-1", "split", "US0378331005", action.EffectiveDate, action.RatioFrom, action.RatioTo,
+			sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertCorporateAction(ctx, tx, &action)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertCorporateAction_Skip(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	action := domain.NewCorporateAction(domain.CorporateActionSymbolChange, "US30303M1027", time.Now())
+	action.NewSymbol = "META"
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 1 (applied actions are immutable, skip insert)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM corporate_actions WHERE id = :1`).
+		WithArgs(action.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	ctx := context.Background()
+	err = dialect.UpsertCorporateAction(ctx, tx, &action)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertWatchlist_Insert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	watchlist, err := domain.NewWatchlist("Candidates")
+	assert.NoError(t, err)
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 0 (not exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM watchlists WHERE id = :1`).
+		WithArgs(watchlist.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// 2. INSERT
+	mock.ExpectExec(`INSERT INTO watchlists`).
+		WithArgs(watchlist.ID, "Candidates", watchlist.CreatedAt, watchlist.UpdatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertWatchlist(ctx, tx, watchlist)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertWatchlist_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	watchlist, err := domain.NewWatchlist("Candidates")
+	assert.NoError(t, err)
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 1 (exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM watchlists`).
+		WithArgs(watchlist.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	// 2. UPDATE
+	mock.ExpectExec(`UPDATE watchlists SET name = :1, updated_at = :2 WHERE id = :3`).
+		WithArgs("Candidates", watchlist.UpdatedAt, watchlist.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertWatchlist(ctx, tx, watchlist)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertAlertRule_Insert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	rule, err := domain.NewAlertRule(domain.AlertPriceAbove, "US0378331005", domain.NewDecimalFromInt(150), 60)
+	assert.NoError(t, err)
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 0 (not exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM alert_rules WHERE id = :1`).
+		WithArgs(rule.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// 2. INSERT
+	mock.ExpectExec(`INSERT INTO alert_rules`).
+		WithArgs(rule.ID, "price_above", sql.NullString{String: "US0378331005", Valid: true}, rule.Threshold, 60, 0, sql.NullTime{}, rule.CreatedAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertAlertRule(ctx, tx, rule)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertAlertRule_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+
+	rule, err := domain.NewAlertRule(domain.AlertPortfolioValueBelow, "", domain.NewDecimalFromInt(10000), 0)
+	assert.NoError(t, err)
+	triggered := time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC)
+	rule.Active = true
+	rule.LastTriggeredAt = &triggered
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 1 (exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM alert_rules`).
+		WithArgs(rule.ID).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	// 2. UPDATE
+	mock.ExpectExec(`UPDATE alert_rules SET threshold = :1, cooldown_minutes = :2, active = :3, last_triggered_at = :4 WHERE id = :5`).
+		WithArgs(rule.Threshold, 0, 1, sql.NullTime{Time: triggered, Valid: true}, rule.ID).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertAlertRule(ctx, tx, rule)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func testPortfolioSnapshot() *domain.PortfolioSnapshot {
+	return &domain.PortfolioSnapshot{
+		PortfolioID:     "portfolio-1",
+		Date:            time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
+		Currency:        "EUR",
+		TotalValue:      domain.NewDecimalFromInt(1500),
+		TotalCash:       domain.NewDecimalFromInt(100),
+		TotalInvested:   domain.NewDecimalFromInt(1200),
+		TotalProfitLoss: domain.NewDecimalFromInt(200),
+		NetFlow:         domain.Zero,
+		TakenAt:         time.Date(2025, 3, 14, 21, 30, 0, 0, time.UTC),
+	}
+}
+
+func TestOracleDialect_UpsertPortfolioSnapshot_Insert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+	s := testPortfolioSnapshot()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 0 (not exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM portfolio_snapshots WHERE portfolio_id = :1 AND snapshot_date = :2`).
+		WithArgs(s.PortfolioID, s.Date).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+
+	// 2. INSERT
+	mock.ExpectExec(`INSERT INTO portfolio_snapshots`).
+		WithArgs(s.PortfolioID, s.Date, "EUR", s.TotalValue, s.TotalCash, s.TotalInvested, s.TotalProfitLoss, s.NetFlow, s.TakenAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertPortfolioSnapshot(ctx, tx, s)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestOracleDialect_UpsertPortfolioSnapshot_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer func() { _ = db.Close() }()
+
+	dialect := &OracleDialect{}
+	s := testPortfolioSnapshot()
+
+	mock.ExpectBegin()
+	tx, err := db.Begin()
+	assert.NoError(t, err)
+
+	// 1. SELECT COUNT(*) - returns 1 (exists)
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM portfolio_snapshots`).
+		WithArgs(s.PortfolioID, s.Date).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	// 2. UPDATE
+	mock.ExpectExec(`UPDATE portfolio_snapshots SET currency = :1, total_value = :2, total_cash = :3, total_invested = :4, total_profit_loss = :5, net_flow = :6, taken_at = :7 WHERE portfolio_id = :8 AND snapshot_date = :9`).
+		WithArgs("EUR", s.TotalValue, s.TotalCash, s.TotalInvested, s.TotalProfitLoss, s.NetFlow, s.TakenAt, s.PortfolioID, s.Date).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	ctx := context.Background()
+	err = dialect.UpsertPortfolioSnapshot(ctx, tx, s)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
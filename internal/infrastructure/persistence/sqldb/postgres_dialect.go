@@ -30,37 +30,171 @@ func (d *PostgresDialect) Migrate(ctx context.Context, db *sql.DB) error {
 
 func (d *PostgresDialect) UpsertPortfolio(ctx context.Context, tx *sql.Tx, p *domain.Portfolio) error {
 	query := `
-		INSERT INTO portfolios (id, name, last_updated, created_at)
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO portfolios (id, name, cost_basis_method, base_currency, last_updated, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		ON CONFLICT (id) DO UPDATE SET
 			name = EXCLUDED.name,
+			cost_basis_method = EXCLUDED.cost_basis_method,
+			base_currency = EXCLUDED.base_currency,
 			last_updated = EXCLUDED.last_updated
 	`
-	_, err := tx.ExecContext(ctx, query, p.ID, p.Name, p.LastUpdated, p.CreatedAt)
+	_, err := tx.ExecContext(ctx, query, p.ID, p.Name, costBasisMethod(p), p.Currency(), p.LastUpdated, p.CreatedAt)
 	return err
 }
 
 func (d *PostgresDialect) UpsertInstrument(ctx context.Context, tx *sql.Tx, i *domain.Instrument) error {
 	query := `
-		INSERT INTO instruments (isin, symbol, name, type, currency, exchange)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO instruments (isin, symbol, name, type, currency, exchange, coupon_rate, maturity_date, unit_precision, sector, industry, country)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 		ON CONFLICT (isin) DO NOTHING
 	`
-	_, err := tx.ExecContext(ctx, query, i.ISIN, i.Symbol, i.Name, i.Type, i.Currency, i.Exchange)
+	_, err := tx.ExecContext(ctx, query, i.ISIN, i.Symbol, i.Name, i.Type, i.Currency, i.Exchange,
+		nullDecimal(i.CouponRate), nullTime(i.MaturityDate), i.UnitPrecision,
+		nullString(i.Sector), nullString(i.Industry), nullString(i.Country))
 	return err
 }
 
 func (d *PostgresDialect) UpsertPosition(ctx context.Context, tx *sql.Tx, p *domain.Position) error {
 	query := `
-		INSERT INTO positions (id, portfolio_id, instrument_isin, invested_amount, invested_currency, quantity, current_price, last_updated)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		INSERT INTO positions (id, portfolio_id, instrument_isin, invested_amount, invested_currency, quantity, current_price, realized_profit_loss, opened_at, closed_at, last_updated)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		ON CONFLICT (id) DO UPDATE SET
+			instrument_isin = EXCLUDED.instrument_isin,
 			invested_amount = EXCLUDED.invested_amount,
 			quantity = EXCLUDED.quantity,
 			current_price = EXCLUDED.current_price,
+			realized_profit_loss = EXCLUDED.realized_profit_loss,
+			opened_at = EXCLUDED.opened_at,
+			closed_at = EXCLUDED.closed_at,
 			last_updated = EXCLUDED.last_updated,
             portfolio_id = EXCLUDED.portfolio_id
 	`
-	_, err := tx.ExecContext(ctx, query, p.ID, p.PortfolioID, p.Instrument.ISIN, p.InvestedAmount, p.InvestedCurrency, p.Quantity, p.CurrentPrice, p.LastUpdated)
+	_, err := tx.ExecContext(ctx, query, p.ID, p.PortfolioID, p.Instrument.ISIN, p.InvestedAmount, p.InvestedAmount.Currency, p.Quantity, p.CurrentPrice, p.RealizedProfitLoss, nullTime(p.OpenedAt), nullTime(p.ClosedAt), p.LastUpdated)
+	return err
+}
+
+func (d *PostgresDialect) UpsertTransaction(ctx context.Context, tx *sql.Tx, t *domain.Transaction) error {
+	query := `
+		INSERT INTO transactions (id, portfolio_id, position_id, instrument_isin, type, trade_date, quantity, price, amount, currency, lot_id, created_at,
+			fee_amount, fee_currency, tax_amount, tax_currency, costs, cost_basis_method)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		ON CONFLICT (id) DO NOTHING
+	`
+	_, err := tx.ExecContext(ctx, query, t.ID, t.PortfolioID, nullString(t.PositionID), nullString(t.InstrumentISIN), t.Type,
+		t.TradeDate, t.Quantity, t.Price, t.Amount, t.Currency, nullString(t.LotID), t.CreatedAt,
+		t.Fee, nullString(t.Fee.Currency), t.Tax, nullString(t.Tax.Currency), t.Costs, nullString(string(t.CostBasisMethod)))
+	return err
+}
+
+func (d *PostgresDialect) UpsertLot(ctx context.Context, tx *sql.Tx, l *domain.Lot) error {
+	query := `
+		INSERT INTO position_lots (id, position_id, transaction_id, acquired_at, quantity, remaining_quantity, unit_cost)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO UPDATE SET
+			quantity = EXCLUDED.quantity,
+			remaining_quantity = EXCLUDED.remaining_quantity,
+			unit_cost = EXCLUDED.unit_cost
+	`
+	_, err := tx.ExecContext(ctx, query, l.ID, l.PositionID, nullString(l.TransactionID), l.AcquiredAt,
+		l.Quantity, l.RemainingQuantity, l.UnitCost)
+	return err
+}
+
+func (d *PostgresDialect) UpsertFXRate(ctx context.Context, tx *sql.Tx, r *domain.FXRate) error {
+	query := `
+		INSERT INTO fx_rates (from_currency, to_currency, rate_date, rate, source)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (from_currency, to_currency, rate_date) DO UPDATE SET
+			rate = EXCLUDED.rate,
+			source = EXCLUDED.source
+	`
+	_, err := tx.ExecContext(ctx, query, r.From, r.To, rateDate(r), r.Rate, r.Source)
+	return err
+}
+
+func (d *PostgresDialect) UpsertInstrumentPrice(ctx context.Context, tx *sql.Tx, p *domain.PricePoint) error {
+	query := `
+		INSERT INTO instrument_prices (isin, price_date, open_price, high_price, low_price, close_price, volume, currency, source)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (isin, price_date, source) DO UPDATE SET
+			open_price = EXCLUDED.open_price,
+			high_price = EXCLUDED.high_price,
+			low_price = EXCLUDED.low_price,
+			close_price = EXCLUDED.close_price,
+			volume = EXCLUDED.volume,
+			currency = EXCLUDED.currency
+	`
+	_, err := tx.ExecContext(ctx, query, p.ISIN, p.Date, nullDecimal(p.Open), nullDecimal(p.High), nullDecimal(p.Low), p.Close,
+		nullDecimal(p.Volume), p.Currency, p.Source)
+	return err
+}
+
+func (d *PostgresDialect) UpsertDividend(ctx context.Context, tx *sql.Tx, div *domain.Dividend) error {
+	query := `
+		INSERT INTO dividends (id, portfolio_id, instrument_isin, ex_date, pay_date, gross_amount, withholding_tax, currency, source, transaction_id, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (id) DO UPDATE SET
+			pay_date = EXCLUDED.pay_date,
+			gross_amount = EXCLUDED.gross_amount,
+			withholding_tax = EXCLUDED.withholding_tax
+	`
+	_, err := tx.ExecContext(ctx, query, div.ID, div.PortfolioID, div.InstrumentISIN, div.ExDate, div.PayDate,
+		div.GrossAmount, div.WithholdingTax, div.Currency(), div.Source, nullString(div.TransactionID), div.CreatedAt)
+	return err
+}
+
+func (d *PostgresDialect) UpsertCorporateAction(ctx context.Context, tx *sql.Tx, a *domain.CorporateAction) error {
+	query := `
+		INSERT INTO corporate_actions (id, portfolio_id, position_id, type, isin, effective_date, ratio_from, ratio_to, old_symbol, new_symbol, new_isin, note, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		ON CONFLICT (id) DO NOTHING
+	`
+	_, err := tx.ExecContext(ctx, query, a.ID, a.PortfolioID, nullString(a.PositionID), a.Type, a.ISIN, a.EffectiveDate, a.RatioFrom, a.RatioTo,
+		nullString(a.OldSymbol), nullString(a.NewSymbol), nullString(a.NewISIN), nullString(a.Note), a.CreatedAt)
+	return err
+}
+
+func (d *PostgresDialect) UpsertWatchlist(ctx context.Context, tx *sql.Tx, w *domain.Watchlist) error {
+	query := `
+		INSERT INTO watchlists (id, name, created_at, updated_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (id) DO UPDATE SET
+			name = EXCLUDED.name,
+			updated_at = EXCLUDED.updated_at
+	`
+	_, err := tx.ExecContext(ctx, query, w.ID, w.Name, w.CreatedAt, w.UpdatedAt)
+	return err
+}
+
+func (d *PostgresDialect) UpsertAlertRule(ctx context.Context, tx *sql.Tx, r *domain.AlertRule) error {
+	query := `
+		INSERT INTO alert_rules (id, kind, isin, threshold, cooldown_minutes, active, last_triggered_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (id) DO UPDATE SET
+			threshold = EXCLUDED.threshold,
+			cooldown_minutes = EXCLUDED.cooldown_minutes,
+			active = EXCLUDED.active,
+			last_triggered_at = EXCLUDED.last_triggered_at
+	`
+	_, err := tx.ExecContext(ctx, query, r.ID, string(r.Kind), nullString(r.ISIN), r.Threshold, r.CooldownMinutes,
+		flag(r.Active), nullTime(r.LastTriggeredAt), r.CreatedAt)
+	return err
+}
+
+func (d *PostgresDialect) UpsertPortfolioSnapshot(ctx context.Context, tx *sql.Tx, s *domain.PortfolioSnapshot) error {
+	query := `
+		INSERT INTO portfolio_snapshots (portfolio_id, snapshot_date, currency, total_value, total_cash, total_invested, total_profit_loss, net_flow, taken_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (portfolio_id, snapshot_date) DO UPDATE SET
+			currency = EXCLUDED.currency,
+			total_value = EXCLUDED.total_value,
+			total_cash = EXCLUDED.total_cash,
+			total_invested = EXCLUDED.total_invested,
+			total_profit_loss = EXCLUDED.total_profit_loss,
+			net_flow = EXCLUDED.net_flow,
+			taken_at = EXCLUDED.taken_at
+	`
+	_, err := tx.ExecContext(ctx, query, s.PortfolioID, s.Date, s.Currency, s.TotalValue, s.TotalCash, s.TotalInvested,
+		s.TotalProfitLoss, s.NetFlow, s.TakenAt)
 	return err
 }
@@ -51,6 +51,58 @@ func (r *Repository) Save(ctx context.Context, p *domain.Portfolio) error {
 				slog.Error("Failed to save position", "position_id", p.Positions[i].ID, "error", err)
 				return fmt.Errorf("upsert position: %w", err)
 			}
+
+			// Upsert Lots
+			for j := range p.Positions[i].Lots {
+				p.Positions[i].Lots[j].PositionID = p.Positions[i].ID
+
+				if err := r.db.Dialect.UpsertLot(ctx, tx, &p.Positions[i].Lots[j]); err != nil {
+					slog.Error("Failed to save lot", "lot_id", p.Positions[i].Lots[j].ID, "error", err)
+					return fmt.Errorf("upsert lot: %w", err)
+				}
+			}
+		}
+
+		// 3. Append new ledger entries
+		for i := range p.Transactions {
+			p.Transactions[i].PortfolioID = p.ID
+
+			if err := r.db.Dialect.UpsertTransaction(ctx, tx, &p.Transactions[i]); err != nil {
+				slog.Error("Failed to save transaction", "transaction_id", p.Transactions[i].ID, "error", err)
+				return fmt.Errorf("upsert transaction: %w", err)
+			}
+		}
+
+		// 4. Upsert dividends, after the ledger entries they link to
+		for i := range p.Dividends {
+			p.Dividends[i].PortfolioID = p.ID
+
+			if err := r.db.Dialect.UpsertDividend(ctx, tx, &p.Dividends[i]); err != nil {
+				slog.Error("Failed to save dividend", "dividend_id", p.Dividends[i].ID, "error", err)
+				return fmt.Errorf("upsert dividend: %w", err)
+			}
+		}
+
+		// 5. Append corporate actions
+		for i := range p.CorporateActions {
+			p.CorporateActions[i].PortfolioID = p.ID
+
+			if err := r.db.Dialect.UpsertCorporateAction(ctx, tx, &p.CorporateActions[i]); err != nil {
+				slog.Error("Failed to save corporate action", "corporate_action_id", p.CorporateActions[i].ID, "error", err)
+				return fmt.Errorf("upsert corporate action: %w", err)
+			}
+		}
+
+		// 6. Replace target allocations
+		if err := r.saveTargetAllocations(ctx, tx, p); err != nil {
+			slog.Error("Failed to save target allocations", "portfolio_id", p.ID, "error", err)
+			return err
+		}
+
+		// 7. Replace the benchmark
+		if err := r.saveBenchmark(ctx, tx, p); err != nil {
+			slog.Error("Failed to save benchmark", "portfolio_id", p.ID, "error", err)
+			return err
 		}
 		return nil
 	})
@@ -59,9 +111,9 @@ func (r *Repository) Save(ctx context.Context, p *domain.Portfolio) error {
 func (r *Repository) FindByID(ctx context.Context, id string) (*domain.Portfolio, error) {
 	query := `
         SELECT
-            p.id, p.name, p.last_updated, p.created_at,
-            pos.id, pos.portfolio_id, pos.instrument_isin, pos.invested_amount, pos.invested_currency, pos.quantity, pos.current_price, pos.last_updated,
-            i.isin, i.symbol, i.name, i.type, i.currency, i.exchange
+            p.id, p.name, p.cost_basis_method, p.base_currency, p.last_updated, p.created_at,
+            pos.id, pos.portfolio_id, pos.instrument_isin, pos.invested_amount, pos.invested_currency, pos.quantity, pos.current_price, pos.realized_profit_loss, pos.opened_at, pos.closed_at, pos.last_updated,
+            i.isin, i.symbol, i.name, i.type, i.currency, i.exchange, i.coupon_rate, i.maturity_date, i.unit_precision, i.sector, i.industry, i.country
         FROM portfolios p
         LEFT JOIN positions pos ON p.id = pos.portfolio_id
         LEFT JOIN instruments i ON pos.instrument_isin = i.isin
@@ -84,18 +136,21 @@ func (r *Repository) FindByID(ctx context.Context, id string) (*domain.Portfolio
 	var portfolio *domain.Portfolio
 
 	for rows.Next() {
-		var pID, pName string
+		var pID, pName, pMethod, pCurrency string
 		var pLastTime, pCreateTime time.Time
 		var posID, posPortID, posInstISIN sql.NullString
-		var posInvAmt, posQty, posPrice domain.Decimal
+		var posInvAmt, posQty, posPrice, posRealized domain.Decimal
 		var posInvCurr sql.NullString
-		var posLast sql.NullTime
-		var iISIN, iSym, iName, iType, iCurr, iExch sql.NullString
+		var posOpened, posClosed, posLast sql.NullTime
+		var iISIN, iSym, iName, iType, iCurr, iExch, iCoupon sql.NullString
+		var iMaturity sql.NullTime
+		var iPrecision sql.NullInt32
+		var iSector, iIndustry, iCountry sql.NullString
 
 		err := rows.Scan(
-			&pID, &pName, &pLastTime, &pCreateTime,
-			&posID, &posPortID, &posInstISIN, &posInvAmt, &posInvCurr, &posQty, &posPrice, &posLast,
-			&iISIN, &iSym, &iName, &iType, &iCurr, &iExch,
+			&pID, &pName, &pMethod, &pCurrency, &pLastTime, &pCreateTime,
+			&posID, &posPortID, &posInstISIN, &posInvAmt, &posInvCurr, &posQty, &posPrice, &posRealized, &posOpened, &posClosed, &posLast,
+			&iISIN, &iSym, &iName, &iType, &iCurr, &iExch, &iCoupon, &iMaturity, &iPrecision, &iSector, &iIndustry, &iCountry,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning row: %w", err)
@@ -103,11 +158,13 @@ func (r *Repository) FindByID(ctx context.Context, id string) (*domain.Portfolio
 
 		if portfolio == nil {
 			portfolio = &domain.Portfolio{
-				ID:          pID,
-				Name:        pName,
-				LastUpdated: pLastTime,
-				CreatedAt:   pCreateTime,
-				Positions:   []domain.Position{},
+				ID:              pID,
+				Name:            pName,
+				CostBasisMethod: domain.CostBasisMethod(pMethod),
+				BaseCurrency:    pCurrency,
+				LastUpdated:     pLastTime,
+				CreatedAt:       pCreateTime,
+				Positions:       []domain.Position{},
 			}
 		}
 
@@ -119,18 +176,32 @@ func (r *Repository) FindByID(ctx context.Context, id string) (*domain.Portfolio
 				Type:     domain.InstrumentType(iType.String),
 				Currency: iCurr.String,
 				Exchange: iExch.String,
+				Sector:   iSector.String,
+				Industry: iIndustry.String,
+				Country:  iCountry.String,
+			}
+			if err := scanInstrumentAttributes(&inst, iCoupon, iMaturity, iPrecision); err != nil {
+				return nil, err
 			}
 
 			pos := domain.Position{
-				ID:               posID.String,
-				PortfolioID:      posPortID.String,
-				InstrumentISIN:   posInstISIN.String,
-				Instrument:       inst,
-				InvestedAmount:   posInvAmt,
-				InvestedCurrency: posInvCurr.String,
-				Quantity:         posQty,
-				CurrentPrice:     posPrice,
-				LastUpdated:      posLast.Time,
+				ID:                 posID.String,
+				PortfolioID:        posPortID.String,
+				InstrumentISIN:     posInstISIN.String,
+				Instrument:         inst,
+				InvestedAmount:     domain.NewMoney(posInvAmt, posInvCurr.String),
+				Quantity:           posQty,
+				CurrentPrice:       posPrice,
+				RealizedProfitLoss: domain.NewMoney(posRealized, posInvCurr.String),
+				LastUpdated:        posLast.Time,
+			}
+			if posOpened.Valid {
+				openedAt := posOpened.Time
+				pos.OpenedAt = &openedAt
+			}
+			if posClosed.Valid {
+				closedAt := posClosed.Time
+				pos.ClosedAt = &closedAt
 			}
 			portfolio.Positions = append(portfolio.Positions, pos)
 		}
@@ -145,15 +216,34 @@ func (r *Repository) FindByID(ctx context.Context, id string) (*domain.Portfolio
 		return nil, fmt.Errorf("portfolio not found: %s", id)
 	}
 
+	if err := r.loadTransactions(ctx, portfolio); err != nil {
+		return nil, err
+	}
+	if err := r.loadLots(ctx, portfolio); err != nil {
+		return nil, err
+	}
+	if err := r.loadDividends(ctx, portfolio); err != nil {
+		return nil, err
+	}
+	if err := r.loadCorporateActions(ctx, portfolio); err != nil {
+		return nil, err
+	}
+	if err := r.loadTargetAllocations(ctx, portfolio); err != nil {
+		return nil, err
+	}
+	if err := r.loadBenchmark(ctx, portfolio); err != nil {
+		return nil, err
+	}
+
 	return portfolio, nil
 }
 
 func (r *Repository) FindAll(ctx context.Context) ([]*domain.Portfolio, error) {
 	query := `
         SELECT
-            p.id, p.name, p.last_updated, p.created_at,
-            pos.id, pos.portfolio_id, pos.instrument_isin, pos.invested_amount, pos.invested_currency, pos.quantity, pos.current_price, pos.last_updated,
-            i.isin, i.symbol, i.name, i.type, i.currency, i.exchange
+            p.id, p.name, p.cost_basis_method, p.base_currency, p.last_updated, p.created_at,
+            pos.id, pos.portfolio_id, pos.instrument_isin, pos.invested_amount, pos.invested_currency, pos.quantity, pos.current_price, pos.realized_profit_loss, pos.opened_at, pos.closed_at, pos.last_updated,
+            i.isin, i.symbol, i.name, i.type, i.currency, i.exchange, i.coupon_rate, i.maturity_date, i.unit_precision, i.sector, i.industry, i.country
         FROM portfolios p
         LEFT JOIN positions pos ON p.id = pos.portfolio_id
         LEFT JOIN instruments i ON pos.instrument_isin = i.isin
@@ -179,18 +269,21 @@ func (r *Repository) FindAll(ctx context.Context) ([]*domain.Portfolio, error) {
 	var ids []string
 
 	for rows.Next() {
-		var pID, pName string
+		var pID, pName, pMethod, pCurrency string
 		var pLastTime, pCreateTime time.Time
 		var posID, posPortID, posInstISIN sql.NullString
-		var posInvAmt, posQty, posPrice domain.Decimal
+		var posInvAmt, posQty, posPrice, posRealized domain.Decimal
 		var posInvCurr sql.NullString
-		var posLast sql.NullTime
-		var iISIN, iSym, iName, iType, iCurr, iExch sql.NullString
+		var posOpened, posClosed, posLast sql.NullTime
+		var iISIN, iSym, iName, iType, iCurr, iExch, iCoupon sql.NullString
+		var iMaturity sql.NullTime
+		var iPrecision sql.NullInt32
+		var iSector, iIndustry, iCountry sql.NullString
 
 		err := rows.Scan(
-			&pID, &pName, &pLastTime, &pCreateTime,
-			&posID, &posPortID, &posInstISIN, &posInvAmt, &posInvCurr, &posQty, &posPrice, &posLast,
-			&iISIN, &iSym, &iName, &iType, &iCurr, &iExch,
+			&pID, &pName, &pMethod, &pCurrency, &pLastTime, &pCreateTime,
+			&posID, &posPortID, &posInstISIN, &posInvAmt, &posInvCurr, &posQty, &posPrice, &posRealized, &posOpened, &posClosed, &posLast,
+			&iISIN, &iSym, &iName, &iType, &iCurr, &iExch, &iCoupon, &iMaturity, &iPrecision, &iSector, &iIndustry, &iCountry,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("scanning row: %w", err)
@@ -199,11 +292,13 @@ func (r *Repository) FindAll(ctx context.Context) ([]*domain.Portfolio, error) {
 		p, exists := portfolioMap[pID]
 		if !exists {
 			p = &domain.Portfolio{
-				ID:          pID,
-				Name:        pName,
-				LastUpdated: pLastTime,
-				CreatedAt:   pCreateTime,
-				Positions:   []domain.Position{},
+				ID:              pID,
+				Name:            pName,
+				CostBasisMethod: domain.CostBasisMethod(pMethod),
+				BaseCurrency:    pCurrency,
+				LastUpdated:     pLastTime,
+				CreatedAt:       pCreateTime,
+				Positions:       []domain.Position{},
 			}
 			portfolioMap[pID] = p
 			ids = append(ids, pID)
@@ -217,18 +312,32 @@ func (r *Repository) FindAll(ctx context.Context) ([]*domain.Portfolio, error) {
 				Type:     domain.InstrumentType(iType.String),
 				Currency: iCurr.String,
 				Exchange: iExch.String,
+				Sector:   iSector.String,
+				Industry: iIndustry.String,
+				Country:  iCountry.String,
+			}
+			if err := scanInstrumentAttributes(&inst, iCoupon, iMaturity, iPrecision); err != nil {
+				return nil, err
 			}
 
 			pos := domain.Position{
-				ID:               posID.String,
-				PortfolioID:      posPortID.String,
-				InstrumentISIN:   posInstISIN.String,
-				Instrument:       inst,
-				InvestedAmount:   posInvAmt,
-				InvestedCurrency: posInvCurr.String,
-				Quantity:         posQty,
-				CurrentPrice:     posPrice,
-				LastUpdated:      posLast.Time,
+				ID:                 posID.String,
+				PortfolioID:        posPortID.String,
+				InstrumentISIN:     posInstISIN.String,
+				Instrument:         inst,
+				InvestedAmount:     domain.NewMoney(posInvAmt, posInvCurr.String),
+				Quantity:           posQty,
+				CurrentPrice:       posPrice,
+				RealizedProfitLoss: domain.NewMoney(posRealized, posInvCurr.String),
+				LastUpdated:        posLast.Time,
+			}
+			if posOpened.Valid {
+				openedAt := posOpened.Time
+				pos.OpenedAt = &openedAt
+			}
+			if posClosed.Valid {
+				closedAt := posClosed.Time
+				pos.ClosedAt = &closedAt
 			}
 			p.Positions = append(p.Positions, pos)
 		}
@@ -239,6 +348,24 @@ func (r *Repository) FindAll(ctx context.Context) ([]*domain.Portfolio, error) {
 	}
 
 	for _, id := range ids {
+		if err := r.loadTransactions(ctx, portfolioMap[id]); err != nil {
+			return nil, err
+		}
+		if err := r.loadLots(ctx, portfolioMap[id]); err != nil {
+			return nil, err
+		}
+		if err := r.loadDividends(ctx, portfolioMap[id]); err != nil {
+			return nil, err
+		}
+		if err := r.loadCorporateActions(ctx, portfolioMap[id]); err != nil {
+			return nil, err
+		}
+		if err := r.loadTargetAllocations(ctx, portfolioMap[id]); err != nil {
+			return nil, err
+		}
+		if err := r.loadBenchmark(ctx, portfolioMap[id]); err != nil {
+			return nil, err
+		}
 		portfolios = append(portfolios, portfolioMap[id])
 	}
 
@@ -247,7 +374,42 @@ func (r *Repository) FindAll(ctx context.Context) ([]*domain.Portfolio, error) {
 
 func (r *Repository) Delete(ctx context.Context, id string) error {
 	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
-		// 1. Delete Positions
+		// 1. Delete Snapshots, Benchmark, Target Allocations, Corporate Actions, Dividends, Transactions and Positions
+		qs := r.rebind("DELETE FROM position_snapshots WHERE portfolio_id = $1")
+		if _, err := tx.ExecContext(ctx, qs, id); err != nil {
+			return fmt.Errorf("failed to delete position snapshots: %w", err)
+		}
+
+		qs = r.rebind("DELETE FROM portfolio_snapshots WHERE portfolio_id = $1")
+		if _, err := tx.ExecContext(ctx, qs, id); err != nil {
+			return fmt.Errorf("failed to delete portfolio snapshots: %w", err)
+		}
+
+		qb := r.rebind("DELETE FROM portfolio_benchmarks WHERE portfolio_id = $1")
+		if _, err := tx.ExecContext(ctx, qb, id); err != nil {
+			return fmt.Errorf("failed to delete benchmark: %w", err)
+		}
+
+		qt := r.rebind("DELETE FROM target_allocations WHERE portfolio_id = $1")
+		if _, err := tx.ExecContext(ctx, qt, id); err != nil {
+			return fmt.Errorf("failed to delete target allocations: %w", err)
+		}
+
+		qc := r.rebind("DELETE FROM corporate_actions WHERE portfolio_id = $1")
+		if _, err := tx.ExecContext(ctx, qc, id); err != nil {
+			return fmt.Errorf("failed to delete corporate actions: %w", err)
+		}
+
+		qd := r.rebind("DELETE FROM dividends WHERE portfolio_id = $1")
+		if _, err := tx.ExecContext(ctx, qd, id); err != nil {
+			return fmt.Errorf("failed to delete dividends: %w", err)
+		}
+
+		q0 := r.rebind("DELETE FROM transactions WHERE portfolio_id = $1")
+		if _, err := tx.ExecContext(ctx, q0, id); err != nil {
+			return fmt.Errorf("failed to delete transactions: %w", err)
+		}
+
 		q1 := r.rebind("DELETE FROM positions WHERE portfolio_id = $1")
 		if _, err := tx.ExecContext(ctx, q1, id); err != nil {
 			return fmt.Errorf("failed to delete positions: %w", err)
@@ -263,6 +425,170 @@ func (r *Repository) Delete(ctx context.Context, id string) error {
 	})
 }
 
+// loadTransactions attaches the ledger of a portfolio in trade date order.
+func (r *Repository) loadTransactions(ctx context.Context, p *domain.Portfolio) error {
+	query := r.rebind(`
+        SELECT id, portfolio_id, position_id, instrument_isin, type, trade_date, quantity, price, amount, currency, lot_id, created_at,
+            fee_amount, fee_currency, tax_amount, tax_currency, costs, cost_basis_method
+        FROM transactions
+        WHERE portfolio_id = $1
+        ORDER BY trade_date, created_at
+    `)
+
+	rows, err := r.db.QueryContext(ctx, query, p.ID)
+	if err != nil {
+		return fmt.Errorf("querying transactions: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	p.Transactions = []domain.Transaction{}
+	for rows.Next() {
+		var t domain.Transaction
+		var positionID, isin, lotID, feeCurrency, taxCurrency, method sql.NullString
+		var txType string
+		var createdAt sql.NullTime
+		var fee, tax domain.Decimal
+
+		err := rows.Scan(
+			&t.ID, &t.PortfolioID, &positionID, &isin, &txType, &t.TradeDate,
+			&t.Quantity, &t.Price, &t.Amount, &t.Currency, &lotID, &createdAt,
+			&fee, &feeCurrency, &tax, &taxCurrency, &t.Costs, &method,
+		)
+		if err != nil {
+			return fmt.Errorf("scanning transaction: %w", err)
+		}
+		// Entries recorded before charges were tracked have no charge currency
+		if !feeCurrency.Valid {
+			feeCurrency.String = t.Currency
+		}
+		if !taxCurrency.Valid {
+			taxCurrency.String = t.Currency
+		}
+		t.Fee = domain.NewMoney(fee, feeCurrency.String)
+		t.Tax = domain.NewMoney(tax, taxCurrency.String)
+		t.PositionID = positionID.String
+		t.InstrumentISIN = isin.String
+		t.LotID = lotID.String
+		t.CostBasisMethod = domain.CostBasisMethod(method.String)
+		t.Type = domain.TransactionType(txType)
+		t.CreatedAt = createdAt.Time
+		p.Transactions = append(p.Transactions, t)
+	}
+
+	return rows.Err()
+}
+
+// loadDividends attaches the distributions of a portfolio in pay date order.
+func (r *Repository) loadDividends(ctx context.Context, p *domain.Portfolio) error {
+	query := r.rebind(`
+        SELECT id, portfolio_id, instrument_isin, ex_date, pay_date, gross_amount, withholding_tax, currency, source, transaction_id, created_at
+        FROM dividends
+        WHERE portfolio_id = $1
+        ORDER BY pay_date, ex_date
+    `)
+
+	rows, err := r.db.QueryContext(ctx, query, p.ID)
+	if err != nil {
+		return fmt.Errorf("querying dividends: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	p.Dividends = []domain.Dividend{}
+	for rows.Next() {
+		var d domain.Dividend
+		var gross, withholding domain.Decimal
+		var currency string
+		var transactionID sql.NullString
+		var createdAt sql.NullTime
+
+		err := rows.Scan(
+			&d.ID, &d.PortfolioID, &d.InstrumentISIN, &d.ExDate, &d.PayDate,
+			&gross, &withholding, &currency, &d.Source, &transactionID, &createdAt,
+		)
+		if err != nil {
+			return fmt.Errorf("scanning dividend: %w", err)
+		}
+		d.GrossAmount = domain.NewMoney(gross, currency)
+		d.WithholdingTax = domain.NewMoney(withholding, currency)
+		d.TransactionID = transactionID.String
+		d.CreatedAt = createdAt.Time
+		p.Dividends = append(p.Dividends, d)
+	}
+
+	return rows.Err()
+}
+
+// loadLots attaches the tax lots of every position in acquisition order.
+func (r *Repository) loadLots(ctx context.Context, p *domain.Portfolio) error {
+	query := r.rebind(`
+        SELECT l.id, l.position_id, l.transaction_id, l.acquired_at, l.quantity, l.remaining_quantity, l.unit_cost
+        FROM position_lots l
+        JOIN positions pos ON l.position_id = pos.id
+        WHERE pos.portfolio_id = $1
+        ORDER BY l.acquired_at
+    `)
+
+	rows, err := r.db.QueryContext(ctx, query, p.ID)
+	if err != nil {
+		return fmt.Errorf("querying lots: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	lotsByPosition := make(map[string][]domain.Lot)
+	for rows.Next() {
+		var l domain.Lot
+		var transactionID sql.NullString
+
+		err := rows.Scan(&l.ID, &l.PositionID, &transactionID, &l.AcquiredAt, &l.Quantity, &l.RemainingQuantity, &l.UnitCost)
+		if err != nil {
+			return fmt.Errorf("scanning lot: %w", err)
+		}
+		l.TransactionID = transactionID.String
+		lotsByPosition[l.PositionID] = append(lotsByPosition[l.PositionID], l)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range p.Positions {
+		p.Positions[i].Lots = lotsByPosition[p.Positions[i].ID]
+	}
+	return nil
+}
+
+// scanInstrumentAttributes sets the optional type-specific attributes of an
+// instrument from nullable columns.
+func scanInstrumentAttributes(inst *domain.Instrument, coupon sql.NullString, maturity sql.NullTime, precision sql.NullInt32) error {
+	if coupon.Valid {
+		rate, err := domain.NewDecimalFromString(coupon.String)
+		if err != nil {
+			return fmt.Errorf("parsing coupon rate of %s: %w", inst.ISIN, err)
+		}
+		inst.CouponRate = &rate
+	}
+	if maturity.Valid {
+		date := maturity.Time
+		inst.MaturityDate = &date
+	}
+	inst.UnitPrecision = precision.Int32
+	return nil
+}
+
 func (r *Repository) rebind(query string) string {
 	if r.db.Dialect.Name() == "oracle" {
 		for i := 1; i <= 10; i++ {
@@ -134,6 +134,10 @@ func setupOracle(t *testing.T) *DB {
 	if err := db.Dialect.Migrate(ctx, rawDB); err != nil {
 		t.Fatalf("failed to migrate: %s", err)
 	}
+	// Every start runs all the scripts again
+	if err := db.Dialect.Migrate(ctx, rawDB); err != nil {
+		t.Fatalf("failed to migrate again: %s", err)
+	}
 
 	return db
 }
@@ -169,6 +173,234 @@ func TestRepository_SaveAndFind(t *testing.T) {
 	})
 }
 
+func TestRepository_SaveAndFind_Transactions(t *testing.T) {
+	runWithBackends(t, func(t *testing.T, db *DB) {
+		repo := NewRepository(db)
+		ctx := context.Background()
+
+		p := domain.NewPortfolio("Ledger")
+		inst := domain.NewInstrument("US123", "TEST", "Test Corp", domain.InstrumentTypeStock, "USD", "NYSE")
+		tradeDate := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+		buy := domain.NewTransaction(domain.TransactionTypeBuy, "US123", tradeDate,
+			domain.NewDecimalFromInt(10), domain.NewDecimalFromInt(100), domain.NewDecimalFromInt(1000), "USD")
+		_, err := p.RecordTransaction(inst, buy)
+		assert.NoError(t, err)
+
+		sell := domain.NewTransaction(domain.TransactionTypeSell, "US123", tradeDate.AddDate(0, 1, 0),
+			domain.NewDecimalFromInt(4), domain.NewDecimalFromInt(120), domain.NewDecimalFromInt(480), "USD")
+		assert.NoError(t, sell.SetCharges(domain.TradeCharges{
+			Fee: domain.NewMoney(domain.NewDecimalFromInt(5), "USD"),
+		}, domain.NewExchangeRates("USD")))
+		_, err = p.RecordTransaction(inst, sell)
+		assert.NoError(t, err)
+
+		deposit := domain.NewTransaction(domain.TransactionTypeDeposit, "", tradeDate.AddDate(0, 2, 0),
+			domain.Zero, domain.Zero, domain.NewDecimalFromInt(500), "USD")
+		assert.NoError(t, p.RecordCashMovement(deposit))
+
+		err = repo.Save(ctx, &p)
+		assert.NoError(t, err)
+
+		// Saving again must not duplicate ledger entries
+		err = repo.Save(ctx, &p)
+		assert.NoError(t, err)
+
+		found, err := repo.FindByID(ctx, p.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 3, len(found.Transactions))
+		assert.Equal(t, domain.TransactionTypeBuy, found.Transactions[0].Type)
+		assert.Equal(t, domain.TransactionTypeDeposit, found.Transactions[2].Type)
+		assert.Equal(t, "", found.Transactions[2].InstrumentISIN)
+		assert.Equal(t, domain.TransactionTypeSell, found.Transactions[1].Type)
+		assert.Equal(t, found.Positions[0].ID, found.Transactions[0].PositionID)
+		assert.True(t, found.Transactions[1].Quantity.Equal(domain.NewDecimalFromInt(4)))
+		assert.True(t, found.Transactions[1].Fee.Equal(domain.NewMoney(domain.NewDecimalFromInt(5), "USD")))
+		assert.True(t, found.Transactions[1].Costs.Equal(domain.NewDecimalFromInt(5)))
+		assert.Equal(t, "USD", found.Transactions[0].Tax.Currency)
+
+		assert.NoError(t, found.RebuildPositions())
+		assert.True(t, found.Positions[0].Quantity.Equal(domain.NewDecimalFromInt(6)))
+	})
+}
+
+func TestRepository_SaveAndFind_LotsAndCostBasis(t *testing.T) {
+	runWithBackends(t, func(t *testing.T, db *DB) {
+		repo := NewRepository(db)
+		ctx := context.Background()
+
+		p := domain.NewPortfolio("Lots")
+		assert.NoError(t, p.SetCostBasisMethod(domain.CostBasisFIFO))
+		assert.NoError(t, p.SetBaseCurrency("USD"))
+		inst := domain.NewInstrument("US123", "TEST", "Test Corp", domain.InstrumentTypeStock, "USD", "NYSE")
+		day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+		for i, price := range []int64{100, 200} {
+			buy := domain.NewTransaction(domain.TransactionTypeBuy, "US123", day.AddDate(0, i, 0),
+				domain.NewDecimalFromInt(10), domain.NewDecimalFromInt(price), domain.NewDecimalFromInt(10*price), "USD")
+			_, err := p.RecordTransaction(inst, buy)
+			assert.NoError(t, err)
+		}
+		sell := domain.NewTransaction(domain.TransactionTypeSell, "US123", day.AddDate(0, 3, 0),
+			domain.NewDecimalFromInt(15), domain.NewDecimalFromInt(250), domain.NewDecimalFromInt(3750), "USD")
+		_, err := p.RecordTransaction(inst, sell)
+		assert.NoError(t, err)
+
+		assert.NoError(t, repo.Save(ctx, &p))
+
+		found, err := repo.FindByID(ctx, p.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.CostBasisFIFO, found.CostBasisMethod)
+		assert.Equal(t, "USD", found.BaseCurrency)
+		assert.Equal(t, 2, len(found.Positions[0].Lots))
+		assert.True(t, found.Positions[0].Lots[0].RemainingQuantity.IsZero())
+		assert.True(t, found.Positions[0].Lots[1].RemainingQuantity.Equal(domain.NewDecimalFromInt(5)))
+		assert.True(t, found.Positions[0].RealizedProfitLoss.Amount.Equal(domain.NewDecimalFromInt(1750)))
+	})
+}
+
+func TestRepository_SaveAndFind_Dividends(t *testing.T) {
+	runWithBackends(t, func(t *testing.T, db *DB) {
+		repo := NewRepository(db)
+		ctx := context.Background()
+
+		p := domain.NewPortfolio("Income")
+		inst := domain.NewInstrument("US123", "TEST", "Test Corp", domain.InstrumentTypeStock, "USD", "NYSE")
+		day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+		buy := domain.NewTransaction(domain.TransactionTypeBuy, "US123", day,
+			domain.NewDecimalFromInt(10), domain.NewDecimalFromInt(100), domain.NewDecimalFromInt(1000), "USD")
+		_, err := p.RecordTransaction(inst, buy)
+		assert.NoError(t, err)
+
+		dividend := domain.NewDividend("US123", day.AddDate(0, 1, 0), day.AddDate(0, 1, 7),
+			domain.NewDecimalFromInt(24), domain.NewDecimalFromInt(4), "USD", domain.DividendSourceManual)
+		_, err = p.RecordDividend(dividend)
+		assert.NoError(t, err)
+
+		assert.NoError(t, repo.Save(ctx, &p))
+		// Saving again must not duplicate dividends
+		assert.NoError(t, repo.Save(ctx, &p))
+
+		found, err := repo.FindByID(ctx, p.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(found.Dividends))
+		assert.True(t, found.Dividends[0].GrossAmount.Equal(domain.NewMoney(domain.NewDecimalFromInt(24), "USD")))
+		assert.True(t, found.Dividends[0].WithholdingTax.Amount.Equal(domain.NewDecimalFromInt(4)))
+		assert.Equal(t, found.Transactions[1].ID, found.Dividends[0].TransactionID)
+		assert.True(t, found.Dividends[0].ExDate.Equal(day.AddDate(0, 1, 0)))
+	})
+}
+
+func TestRepository_ApplyCorporateAction(t *testing.T) {
+	runWithBackends(t, func(t *testing.T, db *DB) {
+		repo := NewRepository(db)
+		ctx := context.Background()
+
+		p := domain.NewPortfolio("Actions")
+		inst := domain.NewInstrument("US123", "TEST", "Test Corp", domain.InstrumentTypeStock, "USD", "NYSE")
+		buy := domain.NewTransaction(domain.TransactionTypeBuy, "US123", time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			domain.NewDecimalFromInt(10), domain.NewDecimalFromInt(100), domain.NewDecimalFromInt(1000), "USD")
+		_, err := p.RecordTransaction(inst, buy)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Save(ctx, &p))
+
+		split := domain.NewCorporateAction(domain.CorporateActionSplit, "US123", time.Date(2024, 6, 10, 0, 0, 0, 0, time.UTC))
+		split.RatioFrom = domain.NewDecimalFromInt(1)
+		split.RatioTo = domain.NewDecimalFromInt(2)
+		applied, err := p.ApplyCorporateAction(split)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.ApplyCorporateAction(ctx, &p, applied))
+
+		remap := domain.NewCorporateAction(domain.CorporateActionISINChange, "US123", time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC))
+		remap.NewISIN = "US456"
+		remap.NewSymbol = "NEWT"
+		applied, err = p.ApplyCorporateAction(remap)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.ApplyCorporateAction(ctx, &p, applied))
+
+		found, err := repo.FindByID(ctx, p.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(found.CorporateActions))
+		assert.Equal(t, domain.CorporateActionSplit, found.CorporateActions[0].Type)
+		assert.Equal(t, "TEST", found.CorporateActions[1].OldSymbol)
+
+		pos := found.Positions[0]
+		assert.Equal(t, "US456", pos.InstrumentISIN)
+		assert.Equal(t, "NEWT", pos.Instrument.Symbol)
+		assert.True(t, pos.Quantity.Equal(domain.NewDecimalFromInt(20)))
+		assert.True(t, pos.Lots[0].UnitCost.Equal(domain.NewDecimalFromInt(50)))
+		// The ledger keeps the identifier the trade was recorded under
+		assert.Equal(t, "US123", found.Transactions[0].InstrumentISIN)
+	})
+}
+
+func TestRepository_SaveAndFind_TargetAllocations(t *testing.T) {
+	runWithBackends(t, func(t *testing.T, db *DB) {
+		repo := NewRepository(db)
+		ctx := context.Background()
+
+		p := domain.NewPortfolio("Targets")
+		assert.NoError(t, p.SetTargetAllocations([]domain.TargetAllocation{
+			{ISIN: "US0378331005", AssetClass: "equity", Weight: domain.NewDecimalFromInt(70)},
+			{ISIN: "IE00B4L5Y983", Weight: domain.NewDecimalFromInt(30)},
+		}))
+		assert.NoError(t, repo.Save(ctx, &p))
+
+		// Replacing the targets drops the ones no longer listed
+		assert.NoError(t, p.SetTargetAllocations([]domain.TargetAllocation{
+			{ISIN: "US0378331005", AssetClass: "equity", Weight: domain.NewDecimalFromInt(100)},
+		}))
+		assert.NoError(t, repo.Save(ctx, &p))
+
+		found, err := repo.FindByID(ctx, p.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(found.TargetAllocations))
+		assert.Equal(t, "equity", found.TargetAllocations[0].AssetClass)
+		assert.True(t, found.TargetAllocations[0].Weight.Equal(domain.NewDecimalFromInt(100)))
+
+		assert.NoError(t, repo.Delete(ctx, p.ID))
+	})
+}
+
+func TestRepository_UpdateInstrument(t *testing.T) {
+	runWithBackends(t, func(t *testing.T, db *DB) {
+		repo := NewRepository(db)
+		ctx := context.Background()
+
+		p := domain.NewPortfolio("Bonds")
+		inst := domain.NewInstrument("DE0001102580", "DBR", "Bund 2030", domain.InstrumentTypeStock, "EUR", "XETRA")
+		inst.Country = "DE"
+		buy := domain.NewTransaction(domain.TransactionTypeBuy, inst.ISIN, time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			domain.NewDecimalFromInt(10), domain.NewDecimalFromInt(98), domain.NewDecimalFromInt(980), "EUR")
+		_, err := p.RecordTransaction(inst, buy)
+		assert.NoError(t, err)
+		assert.NoError(t, repo.Save(ctx, &p))
+
+		bond := domain.InstrumentTypeBond
+		coupon, _ := domain.NewDecimalFromString("4.25")
+		maturity := time.Date(2030, 6, 15, 0, 0, 0, 0, time.UTC)
+		sector := "Government"
+		updated, err := p.UpdateInstrument(inst.ISIN, domain.InstrumentDetails{Type: &bond, CouponRate: &coupon, MaturityDate: &maturity, Sector: &sector})
+		assert.NoError(t, err)
+		assert.NoError(t, repo.UpdateInstrument(ctx, updated))
+
+		found, err := repo.FindByID(ctx, p.ID)
+		assert.NoError(t, err)
+		got := found.Positions[0].Instrument
+		assert.Equal(t, domain.InstrumentTypeBond, got.Type)
+		if assert.NotNil(t, got.CouponRate) {
+			assert.True(t, got.CouponRate.Equal(coupon))
+		}
+		if assert.NotNil(t, got.MaturityDate) {
+			assert.Equal(t, maturity.Format(time.DateOnly), got.MaturityDate.Format(time.DateOnly))
+		}
+		assert.Equal(t, "Government", got.Sector)
+		assert.Equal(t, "", got.Industry)
+		assert.Equal(t, "DE", got.Country)
+	})
+}
+
 func TestRepository_Save_Update(t *testing.T) {
 	runWithBackends(t, func(t *testing.T, db *DB) {
 		repo := NewRepository(db)
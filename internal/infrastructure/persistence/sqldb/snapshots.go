@@ -0,0 +1,107 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// SaveSnapshot stores a portfolio snapshot and replaces its position rows,
+// so that positions closed since an earlier snapshot of the same day do
+// not linger.
+func (r *Repository) SaveSnapshot(ctx context.Context, s *domain.PortfolioSnapshot) error {
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		if err := r.db.Dialect.UpsertPortfolioSnapshot(ctx, tx, s); err != nil {
+			slog.Error("Failed to save portfolio snapshot", "portfolio_id", s.PortfolioID, "date", s.Date, "error", err)
+			return fmt.Errorf("upsert portfolio snapshot: %w", err)
+		}
+
+		query := r.rebind("DELETE FROM position_snapshots WHERE portfolio_id = $1 AND snapshot_date = $2")
+		if _, err := tx.ExecContext(ctx, query, s.PortfolioID, s.Date); err != nil {
+			return fmt.Errorf("failed to delete position snapshots: %w", err)
+		}
+
+		insert := r.rebind(`
+            INSERT INTO position_snapshots (portfolio_id, snapshot_date, position_id, isin, quantity, price, price_currency, market_value, invested)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        `)
+		for i := range s.Positions {
+			pos := &s.Positions[i]
+			if _, err := tx.ExecContext(ctx, insert, s.PortfolioID, s.Date, pos.PositionID, pos.ISIN, pos.Quantity, pos.Price,
+				pos.PriceCurrency, pos.Value, pos.Invested); err != nil {
+				return fmt.Errorf("failed to insert position snapshot %s: %w", pos.ISIN, err)
+			}
+		}
+		return nil
+	})
+}
+
+// FindSnapshots returns the snapshots of a portfolio between from and to,
+// inclusive, with their positions, in date order.
+func (r *Repository) FindSnapshots(ctx context.Context, portfolioID string, from, to time.Time) ([]domain.PortfolioSnapshot, error) {
+	query := r.rebind(`
+        SELECT
+            s.snapshot_date, s.currency, s.total_value, s.total_cash, s.total_invested, s.total_profit_loss, s.net_flow, s.taken_at,
+            p.position_id, p.isin, p.quantity, p.price, p.price_currency, p.market_value, p.invested
+        FROM portfolio_snapshots s
+        LEFT JOIN position_snapshots p ON s.portfolio_id = p.portfolio_id AND s.snapshot_date = p.snapshot_date
+        WHERE s.portfolio_id = $1 AND s.snapshot_date >= $2 AND s.snapshot_date <= $3
+        ORDER BY s.snapshot_date, p.isin, p.position_id
+    `)
+
+	rows, err := r.db.QueryContext(ctx, query, portfolioID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("querying portfolio snapshots: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	snapshots := []domain.PortfolioSnapshot{}
+	for rows.Next() {
+		var s domain.PortfolioSnapshot
+		var positionID, isin, quantity, price, priceCurrency, value, invested sql.NullString
+		if err := rows.Scan(
+			&s.Date, &s.Currency, &s.TotalValue, &s.TotalCash, &s.TotalInvested, &s.TotalProfitLoss, &s.NetFlow, &s.TakenAt,
+			&positionID, &isin, &quantity, &price, &priceCurrency, &value, &invested,
+		); err != nil {
+			return nil, fmt.Errorf("scanning portfolio snapshot: %w", err)
+		}
+
+		if n := len(snapshots); n == 0 || !snapshots[n-1].Date.Equal(s.Date) {
+			s.PortfolioID = portfolioID
+			s.Positions = []domain.PositionSnapshot{}
+			snapshots = append(snapshots, s)
+		}
+		if !positionID.Valid {
+			continue
+		}
+
+		pos := domain.PositionSnapshot{PositionID: positionID.String, ISIN: isin.String, PriceCurrency: priceCurrency.String}
+		if pos.Quantity, err = domain.NewDecimalFromString(quantity.String); err != nil {
+			return nil, fmt.Errorf("parsing quantity of %s: %w", pos.ISIN, err)
+		}
+		if pos.Price, err = domain.NewDecimalFromString(price.String); err != nil {
+			return nil, fmt.Errorf("parsing price of %s: %w", pos.ISIN, err)
+		}
+		if pos.Value, err = domain.NewDecimalFromString(value.String); err != nil {
+			return nil, fmt.Errorf("parsing value of %s: %w", pos.ISIN, err)
+		}
+		if pos.Invested, err = domain.NewDecimalFromString(invested.String); err != nil {
+			return nil, fmt.Errorf("parsing invested amount of %s: %w", pos.ISIN, err)
+		}
+		last := &snapshots[len(snapshots)-1]
+		last.Positions = append(last.Positions, pos)
+	}
+
+	return snapshots, rows.Err()
+}
+
+var _ domain.SnapshotRepository = (*Repository)(nil)
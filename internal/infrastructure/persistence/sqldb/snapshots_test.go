@@ -0,0 +1,67 @@
+package sqldb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_SaveAndFind_Snapshots(t *testing.T) {
+	runWithBackends(t, func(t *testing.T, db *DB) {
+		repo := NewRepository(db)
+		ctx := context.Background()
+
+		p := domain.NewPortfolio("Snapshots")
+		assert.NoError(t, repo.Save(ctx, &p))
+
+		day := time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC)
+		snapshot := &domain.PortfolioSnapshot{
+			PortfolioID:     p.ID,
+			Date:            day,
+			Currency:        "EUR",
+			TotalValue:      domain.NewDecimalFromInt(1500),
+			TotalCash:       domain.NewDecimalFromInt(100),
+			TotalInvested:   domain.NewDecimalFromInt(1200),
+			TotalProfitLoss: domain.NewDecimalFromInt(200),
+			NetFlow:         domain.NewDecimalFromInt(1200),
+			TakenAt:         day.Add(10 * time.Hour),
+			Positions: []domain.PositionSnapshot{
+				{PositionID: "pos-1", ISIN: "US0378331005", Quantity: domain.NewDecimalFromInt(10), Price: domain.NewDecimalFromInt(150),
+					PriceCurrency: "USD", Value: domain.NewDecimalFromInt(1000), Invested: domain.NewDecimalFromInt(800)},
+				{PositionID: "pos-2", ISIN: "IE00B4L5Y983", Quantity: domain.NewDecimalFromInt(5), Price: domain.NewDecimalFromInt(80),
+					PriceCurrency: "EUR", Value: domain.NewDecimalFromInt(400), Invested: domain.NewDecimalFromInt(400)},
+			},
+		}
+		assert.NoError(t, repo.SaveSnapshot(ctx, snapshot))
+
+		// A later snapshot of the same day replaces the earlier one
+		snapshot.TotalValue = domain.NewDecimalFromInt(1600)
+		snapshot.TakenAt = day.Add(22 * time.Hour)
+		snapshot.Positions = snapshot.Positions[:1]
+		assert.NoError(t, repo.SaveSnapshot(ctx, snapshot))
+
+		next := *snapshot
+		next.Date = day.AddDate(0, 0, 1)
+		next.NetFlow = domain.Zero
+		next.Positions = nil
+		assert.NoError(t, repo.SaveSnapshot(ctx, &next))
+
+		snapshots, err := repo.FindSnapshots(ctx, p.ID, day, day.AddDate(0, 0, 1))
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(snapshots))
+		assert.True(t, snapshots[0].Date.Equal(day))
+		assert.True(t, snapshots[0].TotalValue.Equal(domain.NewDecimalFromInt(1600)))
+		assert.True(t, snapshots[0].NetFlow.Equal(domain.NewDecimalFromInt(1200)))
+		assert.Equal(t, 1, len(snapshots[0].Positions))
+		assert.Equal(t, "US0378331005", snapshots[0].Positions[0].ISIN)
+		assert.True(t, snapshots[0].Positions[0].Value.Equal(domain.NewDecimalFromInt(1000)))
+		assert.Empty(t, snapshots[1].Positions)
+
+		snapshots, err = repo.FindSnapshots(ctx, p.ID, day.AddDate(0, 0, 1), day.AddDate(0, 0, 30))
+		assert.NoError(t, err)
+		assert.Equal(t, 1, len(snapshots))
+	})
+}
@@ -0,0 +1,65 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// saveTargetAllocations replaces the stored target allocations of a
+// portfolio. Targets are always set as a whole, so there is nothing to
+// merge with the previous rows.
+func (r *Repository) saveTargetAllocations(ctx context.Context, tx *sql.Tx, p *domain.Portfolio) error {
+	query := r.rebind("DELETE FROM target_allocations WHERE portfolio_id = $1")
+	if _, err := tx.ExecContext(ctx, query, p.ID); err != nil {
+		return fmt.Errorf("failed to delete target allocations: %w", err)
+	}
+
+	insert := r.rebind("INSERT INTO target_allocations (portfolio_id, isin, asset_class, weight) VALUES ($1, $2, $3, $4)")
+	for i := range p.TargetAllocations {
+		t := &p.TargetAllocations[i]
+		if _, err := tx.ExecContext(ctx, insert, p.ID, t.ISIN, nullString(t.AssetClass), t.Weight); err != nil {
+			return fmt.Errorf("failed to insert target allocation %s: %w", t.ISIN, err)
+		}
+	}
+	return nil
+}
+
+// loadTargetAllocations attaches the target allocations of a portfolio,
+// ordered by ISIN.
+func (r *Repository) loadTargetAllocations(ctx context.Context, p *domain.Portfolio) error {
+	query := r.rebind(`
+        SELECT isin, asset_class, weight
+        FROM target_allocations
+        WHERE portfolio_id = $1
+        ORDER BY isin
+    `)
+
+	rows, err := r.db.QueryContext(ctx, query, p.ID)
+	if err != nil {
+		return fmt.Errorf("querying target allocations: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	p.TargetAllocations = []domain.TargetAllocation{}
+	for rows.Next() {
+		var t domain.TargetAllocation
+		var assetClass sql.NullString
+
+		if err := rows.Scan(&t.ISIN, &assetClass, &t.Weight); err != nil {
+			return fmt.Errorf("scanning target allocation: %w", err)
+		}
+		t.AssetClass = assetClass.String
+		p.TargetAllocations = append(p.TargetAllocations, t)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,183 @@
+package sqldb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// SaveWatchlist stores a watchlist and replaces its items. Items are few
+// and always saved with their watchlist, so there is nothing to merge with
+// the previous rows.
+func (r *Repository) SaveWatchlist(ctx context.Context, w *domain.Watchlist) error {
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		if err := r.db.Dialect.UpsertWatchlist(ctx, tx, w); err != nil {
+			slog.Error("Failed to save watchlist", "watchlist_id", w.ID, "error", err)
+			return fmt.Errorf("upsert watchlist: %w", err)
+		}
+
+		query := r.rebind("DELETE FROM watchlist_items WHERE watchlist_id = $1")
+		if _, err := tx.ExecContext(ctx, query, w.ID); err != nil {
+			return fmt.Errorf("failed to delete watchlist items: %w", err)
+		}
+
+		insert := r.rebind(`
+            INSERT INTO watchlist_items (watchlist_id, instrument_isin, notes, target_price, last_price, previous_close, last_updated, added_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        `)
+		for i := range w.Items {
+			item := &w.Items[i]
+			if err := r.db.Dialect.UpsertInstrument(ctx, tx, &item.Instrument); err != nil {
+				slog.Error("Failed to save instrument", "isin", item.Instrument.ISIN, "error", err)
+				return fmt.Errorf("upsert instrument: %w", err)
+			}
+			if _, err := tx.ExecContext(ctx, insert, w.ID, item.Instrument.ISIN, nullString(item.Notes), nullDecimal(item.TargetPrice),
+				item.LastPrice, item.PreviousClose, nullTime(item.LastUpdated), item.AddedAt); err != nil {
+				return fmt.Errorf("failed to insert watchlist item %s: %w", item.Instrument.ISIN, err)
+			}
+		}
+		return nil
+	})
+}
+
+// FindWatchlist returns a watchlist with its items, or
+// domain.ErrWatchlistNotFound.
+func (r *Repository) FindWatchlist(ctx context.Context, id string) (*domain.Watchlist, error) {
+	watchlists, err := r.findWatchlists(ctx, "WHERE w.id = $1", id)
+	if err != nil {
+		return nil, err
+	}
+	if len(watchlists) == 0 {
+		return nil, fmt.Errorf("%w: %s", domain.ErrWatchlistNotFound, id)
+	}
+	return watchlists[0], nil
+}
+
+// FindWatchlists returns every watchlist with its items, ordered by name.
+func (r *Repository) FindWatchlists(ctx context.Context) ([]*domain.Watchlist, error) {
+	return r.findWatchlists(ctx, "")
+}
+
+// DeleteWatchlist removes a watchlist and its items, or returns
+// domain.ErrWatchlistNotFound.
+func (r *Repository) DeleteWatchlist(ctx context.Context, id string) error {
+	return r.db.WithTx(ctx, func(tx *sql.Tx) error {
+		// Items are removed explicitly rather than relying on the cascade
+		query := r.rebind("DELETE FROM watchlist_items WHERE watchlist_id = $1")
+		if _, err := tx.ExecContext(ctx, query, id); err != nil {
+			return fmt.Errorf("failed to delete watchlist items: %w", err)
+		}
+
+		query = r.rebind("DELETE FROM watchlists WHERE id = $1")
+		result, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete watchlist: %w", err)
+		}
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to check deleted watchlist: %w", err)
+		}
+		if affected == 0 {
+			return fmt.Errorf("%w: %s", domain.ErrWatchlistNotFound, id)
+		}
+		return nil
+	})
+}
+
+// findWatchlists loads the watchlists matching the where clause with their
+// items, in the order the items were added.
+func (r *Repository) findWatchlists(ctx context.Context, where string, args ...any) ([]*domain.Watchlist, error) {
+	query := r.rebind(`
+        SELECT
+            w.id, w.name, w.created_at, w.updated_at,
+            wi.instrument_isin, wi.notes, wi.target_price, wi.last_price, wi.previous_close, wi.last_updated, wi.added_at,
+            i.symbol, i.name, i.type, i.currency, i.exchange, i.coupon_rate, i.maturity_date, i.unit_precision, i.sector, i.industry, i.country
+        FROM watchlists w
+        LEFT JOIN watchlist_items wi ON w.id = wi.watchlist_id
+        LEFT JOIN instruments i ON wi.instrument_isin = i.isin
+        ` + where + `
+        ORDER BY w.name, w.id, wi.added_at, wi.instrument_isin
+    `)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("querying watchlists: %w", err)
+	}
+	defer func(rows *sql.Rows) {
+		err := rows.Close()
+		if err != nil {
+			slog.Error("Failed to close rows", "error", err)
+		}
+	}(rows)
+
+	watchlists := []*domain.Watchlist{}
+	var current *domain.Watchlist
+	for rows.Next() {
+		var w domain.Watchlist
+		var isin, notes, targetPrice sql.NullString
+		var lastPrice, previousClose sql.NullString
+		var lastUpdated, addedAt sql.NullTime
+		var iSym, iName, iType, iCurr, iExch, iCoupon sql.NullString
+		var iMaturity sql.NullTime
+		var iPrecision sql.NullInt32
+		var iSector, iIndustry, iCountry sql.NullString
+
+		if err := rows.Scan(
+			&w.ID, &w.Name, &w.CreatedAt, &w.UpdatedAt,
+			&isin, &notes, &targetPrice, &lastPrice, &previousClose, &lastUpdated, &addedAt,
+			&iSym, &iName, &iType, &iCurr, &iExch, &iCoupon, &iMaturity, &iPrecision, &iSector, &iIndustry, &iCountry,
+		); err != nil {
+			return nil, fmt.Errorf("scanning watchlist: %w", err)
+		}
+
+		if current == nil || current.ID != w.ID {
+			w.Items = []domain.WatchlistItem{}
+			current = &w
+			watchlists = append(watchlists, current)
+		}
+		if !isin.Valid {
+			continue
+		}
+
+		inst := domain.Instrument{
+			ISIN:     isin.String,
+			Symbol:   iSym.String,
+			Name:     iName.String,
+			Type:     domain.InstrumentType(iType.String),
+			Currency: iCurr.String,
+			Exchange: iExch.String,
+			Sector:   iSector.String,
+			Industry: iIndustry.String,
+			Country:  iCountry.String,
+		}
+		if err := scanInstrumentAttributes(&inst, iCoupon, iMaturity, iPrecision); err != nil {
+			return nil, err
+		}
+		item := domain.WatchlistItem{Instrument: inst, Notes: notes.String, AddedAt: addedAt.Time}
+		if item.LastPrice, err = domain.NewDecimalFromString(lastPrice.String); err != nil {
+			return nil, fmt.Errorf("parsing last price of %s: %w", inst.ISIN, err)
+		}
+		if item.PreviousClose, err = domain.NewDecimalFromString(previousClose.String); err != nil {
+			return nil, fmt.Errorf("parsing previous close of %s: %w", inst.ISIN, err)
+		}
+		if targetPrice.Valid {
+			target, err := domain.NewDecimalFromString(targetPrice.String)
+			if err != nil {
+				return nil, fmt.Errorf("parsing target price of %s: %w", inst.ISIN, err)
+			}
+			item.TargetPrice = &target
+		}
+		if lastUpdated.Valid {
+			updated := lastUpdated.Time
+			item.LastUpdated = &updated
+		}
+		current.Items = append(current.Items, item)
+	}
+
+	return watchlists, rows.Err()
+}
+
+var _ domain.WatchlistRepository = (*Repository)(nil)
@@ -0,0 +1,64 @@
+package sqldb
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_SaveAndFind_Watchlists(t *testing.T) {
+	runWithBackends(t, func(t *testing.T, db *DB) {
+		repo := NewRepository(db)
+		ctx := context.Background()
+
+		watchlist, err := domain.NewWatchlist("Candidates")
+		assert.NoError(t, err)
+		notes := "wait for earnings"
+		target := domain.NewDecimalFromInt(150)
+		apple := domain.NewInstrument("US0378331005", "AAPL", "Apple Inc.", domain.InstrumentTypeStock, "USD", "NASDAQ")
+		item, err := watchlist.AddItem(apple, domain.WatchlistItemDetails{Notes: &notes, TargetPrice: &target})
+		assert.NoError(t, err)
+		assert.NoError(t, item.UpdatePrice(domain.NewDecimalFromInt(180), time.Now()))
+		world := domain.NewInstrument("IE00B4L5Y983", "IWDA", "iShares Core MSCI World", domain.InstrumentTypeETF, "EUR", "XAMS")
+		_, err = watchlist.AddItem(world, domain.WatchlistItemDetails{})
+		assert.NoError(t, err)
+		assert.NoError(t, repo.SaveWatchlist(ctx, watchlist))
+
+		empty, err := domain.NewWatchlist("Bonds")
+		assert.NoError(t, err)
+		assert.NoError(t, repo.SaveWatchlist(ctx, empty))
+
+		found, err := repo.FindWatchlist(ctx, watchlist.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Candidates", found.Name)
+		assert.Equal(t, 2, len(found.Items))
+		assert.Equal(t, "Apple Inc.", found.Items[0].Instrument.Name)
+		assert.Equal(t, "wait for earnings", found.Items[0].Notes)
+		assert.True(t, found.Items[0].TargetPrice.Equal(target))
+		assert.True(t, found.Items[0].LastPrice.Equal(domain.NewDecimalFromInt(180)))
+		assert.NotNil(t, found.Items[0].LastUpdated)
+		assert.Nil(t, found.Items[1].TargetPrice)
+		assert.Nil(t, found.Items[1].LastUpdated)
+
+		// Saving again replaces the items
+		assert.NoError(t, found.RemoveItem(apple.ISIN))
+		assert.NoError(t, found.Rename("ETFs"))
+		assert.NoError(t, repo.SaveWatchlist(ctx, found))
+
+		all, err := repo.FindWatchlists(ctx)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, len(all))
+		assert.Equal(t, "Bonds", all[0].Name)
+		assert.Empty(t, all[0].Items)
+		assert.Equal(t, "ETFs", all[1].Name)
+		assert.Equal(t, 1, len(all[1].Items))
+
+		assert.NoError(t, repo.DeleteWatchlist(ctx, watchlist.ID))
+		_, err = repo.FindWatchlist(ctx, watchlist.ID)
+		assert.ErrorIs(t, err, domain.ErrWatchlistNotFound)
+		assert.ErrorIs(t, repo.DeleteWatchlist(ctx, watchlist.ID), domain.ErrWatchlistNotFound)
+	})
+}
@@ -0,0 +1,138 @@
+// Package webhook delivers triggered alerts as JSON POST requests.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+const (
+	// DefaultRetries is the number of attempts after the first one.
+	DefaultRetries = 3
+	// DefaultBackoff is the wait before the first retry, doubled for each
+	// retry after it.
+	DefaultBackoff = time.Second
+
+	userAgent = "stock-tracker"
+)
+
+// Notifier posts every alert to each of its URLs. Requests that fail to
+// connect, time out or get a 429 or 5xx response are retried with
+// exponential backoff; other 4xx responses are not, as repeating the same
+// payload would not change them.
+type Notifier struct {
+	urls       []string
+	retries    int
+	backoff    time.Duration
+	httpClient *http.Client
+}
+
+// NewNotifier creates a notifier for the given URLs.
+func NewNotifier(urls []string, retries int, backoff time.Duration) *Notifier {
+	return &Notifier{
+		urls:    urls,
+		retries: retries,
+		backoff: backoff,
+		httpClient: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+	}
+}
+
+// ParseURLs reads a comma-separated list of http or https URLs.
+func ParseURLs(raw string) ([]string, error) {
+	var urls []string
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		parsed, err := url.Parse(part)
+		if err != nil {
+			return nil, fmt.Errorf("invalid webhook url %q: %w", part, err)
+		}
+		if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid webhook url %q: must be an absolute http or https url", part)
+		}
+		urls = append(urls, part)
+	}
+	return urls, nil
+}
+
+// Notify delivers the alert to every URL, returning the failures of those
+// that did not accept it after all retries.
+func (n *Notifier) Notify(ctx context.Context, alert domain.Alert) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("failed to encode alert: %w", err)
+	}
+
+	var errs []error
+	for _, target := range n.urls {
+		if err := n.deliver(ctx, target, payload); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", target, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deliver posts payload to target until it is accepted, a permanent error
+// occurs or the retries run out.
+func (n *Notifier) deliver(ctx context.Context, target string, payload []byte) error {
+	wait := n.backoff
+	for attempt := 0; ; attempt++ {
+		retry, err := n.post(ctx, target, payload)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= n.retries {
+			return err
+		}
+
+		slog.WarnContext(ctx, "webhook delivery failed, retrying", "url", target, "attempt", attempt+1, "wait", wait, "error", err)
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		wait *= 2
+	}
+}
+
+// post sends one request and reports whether a failure is worth retrying.
+func (n *Notifier) post(ctx context.Context, target string, payload []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(payload))
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, fmt.Errorf("failed to execute request: %w", err)
+	}
+	defer func() {
+		if closeErr := resp.Body.Close(); closeErr != nil {
+			slog.Warn("failed to close response body", "error", closeErr, "url", target)
+		}
+	}()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("webhook returned status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jmanzanog/stock-tracker/internal/domain"
+)
+
+// stub answers with the given statuses in turn, repeating the last one,
+// and records the alerts it received.
+type stub struct {
+	mu       sync.Mutex
+	statuses []int
+	received []domain.Alert
+}
+
+func newStubServer(t *testing.T, s *stub) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var alert domain.Alert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		s.received = append(s.received, alert)
+
+		status := s.statuses[len(s.statuses)-1]
+		if len(s.received) <= len(s.statuses) {
+			status = s.statuses[len(s.received)-1]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func testAlert() domain.Alert {
+	return domain.Alert{
+		ID:          "alert-1",
+		RuleID:      "rule-1",
+		Kind:        domain.AlertPriceAbove,
+		ISIN:        "US0378331005",
+		Value:       domain.NewDecimalFromInt(155),
+		Threshold:   domain.NewDecimalFromInt(150),
+		Message:     "US0378331005 price 155 is at or above 150",
+		TriggeredAt: time.Date(2025, 3, 14, 15, 0, 0, 0, time.UTC),
+	}
+}
+
+func TestNotifier_Notify(t *testing.T) {
+	first := &stub{statuses: []int{http.StatusOK}}
+	second := &stub{statuses: []int{http.StatusNoContent}}
+	notifier := NewNotifier([]string{newStubServer(t, first).URL, newStubServer(t, second).URL}, 2, time.Millisecond)
+
+	if err := notifier.Notify(context.Background(), testAlert()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	for i, s := range []*stub{first, second} {
+		if len(s.received) != 1 || s.received[0].ID != "alert-1" || !s.received[0].Value.Equal(domain.NewDecimalFromInt(155)) {
+			t.Errorf("webhook %d: expected the alert once, got %+v", i, s.received)
+		}
+	}
+}
+
+func TestNotifier_RetriesTransientFailures(t *testing.T) {
+	s := &stub{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK}}
+	notifier := NewNotifier([]string{newStubServer(t, s).URL}, 2, time.Millisecond)
+
+	if err := notifier.Notify(context.Background(), testAlert()); err != nil {
+		t.Fatalf("Notify failed: %v", err)
+	}
+	if len(s.received) != 3 {
+		t.Errorf("expected 3 attempts, got %d", len(s.received))
+	}
+}
+
+func TestNotifier_GivesUp(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		attempts int
+	}{
+		{"after the retries", http.StatusBadGateway, 3},
+		{"on a client error", http.StatusNotFound, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &stub{statuses: []int{tt.status}}
+			notifier := NewNotifier([]string{newStubServer(t, s).URL}, 2, time.Millisecond)
+
+			err := notifier.Notify(context.Background(), testAlert())
+			if err == nil || !strings.Contains(err.Error(), "status") {
+				t.Errorf("expected a status error, got %v", err)
+			}
+			if len(s.received) != tt.attempts {
+				t.Errorf("expected %d attempts, got %d", tt.attempts, len(s.received))
+			}
+		})
+	}
+}
+
+func TestParseURLs(t *testing.T) {
+	urls, err := ParseURLs(" https://hooks.example.com/a , http://localhost:9000/alerts,")
+	if err != nil {
+		t.Fatalf("ParseURLs failed: %v", err)
+	}
+	if len(urls) != 2 || urls[0] != "https://hooks.example.com/a" || urls[1] != "http://localhost:9000/alerts" {
+		t.Errorf("unexpected urls %v", urls)
+	}
+	for _, raw := range []string{"ftp://example.com", "/alerts", "https://"} {
+		if _, err := ParseURLs(raw); err == nil {
+			t.Errorf("expected an error for %q", raw)
+		}
+	}
+}
@@ -2,23 +2,73 @@ package http
 
 import (
 	"context"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmanzanog/stock-tracker/internal/application"
 	"github.com/jmanzanog/stock-tracker/internal/domain"
 )
 
+// maxHoldingsFileSize bounds the size of the issuer files accepted by
+// ImportETFHoldings.
+const maxHoldingsFileSize = 10 << 20
+
 // PortfolioService defines the interface for portfolio operations
 type PortfolioService interface {
-	AddPosition(ctx context.Context, isin string, amount domain.Decimal, currency string) (*domain.Position, error)
+	AddPosition(ctx context.Context, identifier string, amount domain.Decimal, currency string, charges domain.TradeCharges, trade application.TradeDetails) (*domain.Position, error)
 	AddPositionsBatch(ctx context.Context, requests []application.AddPositionBatchRequest) *application.AddPositionsBatchResult
 	RemovePosition(ctx context.Context, id string) error
 	GetPosition(ctx context.Context, id string) (*domain.Position, error)
 	ListPositions(ctx context.Context) ([]domain.Position, error)
 	GetPortfolioSummary(ctx context.Context) (*domain.Portfolio, error)
 	RefreshPrices(ctx context.Context) error
+	RecordTransaction(ctx context.Context, req application.RecordTransactionRequest) (*domain.Transaction, error)
+	ListTransactions(ctx context.Context) ([]domain.Transaction, error)
+	SetCostBasisMethod(ctx context.Context, method domain.CostBasisMethod) error
+	SellPosition(ctx context.Context, id string, req application.SellPositionRequest) (*domain.Position, error)
+	GetPortfolioValuation(ctx context.Context) (*domain.Valuation, error)
+	SetBaseCurrency(ctx context.Context, currency string) error
+	RecordDividend(ctx context.Context, req application.RecordDividendRequest) (*domain.Dividend, error)
+	ListDividends(ctx context.Context) ([]domain.Dividend, error)
+	SyncDividends(ctx context.Context) (int, error)
+	GetIncomeReport(ctx context.Context) (*domain.IncomeReport, error)
+	ApplyCorporateAction(ctx context.Context, req application.ApplyCorporateActionRequest) (*domain.CorporateAction, error)
+	ListCorporateActions(ctx context.Context) ([]domain.CorporateAction, error)
+	GetPerformance(ctx context.Context, period string) (*domain.PerformanceReport, error)
+	GetMoneyWeightedReturn(ctx context.Context) (*domain.MoneyWeightedReport, error)
+	RecordCashMovement(ctx context.Context, req application.RecordCashMovementRequest) (*domain.Transaction, error)
+	GetCashBalances(ctx context.Context) (*domain.CashSummary, error)
+	ListCashMovements(ctx context.Context, currency string) ([]domain.CashMovement, error)
+	SetTargetAllocations(ctx context.Context, targets []domain.TargetAllocation) ([]domain.TargetAllocation, error)
+	ListTargetAllocations(ctx context.Context) ([]domain.TargetAllocation, error)
+	Rebalance(ctx context.Context, req application.RebalanceRequest) (*application.RebalancePlan, error)
+	UpdateInstrument(ctx context.Context, isin string, details domain.InstrumentDetails) (*domain.Instrument, error)
+	ResolveInstrument(ctx context.Context, identifier string) (*domain.Instrument, error)
+	SetBenchmark(ctx context.Context, identifier string) (*domain.Instrument, error)
+	CompareBenchmark(ctx context.Context, period string) (*domain.BenchmarkComparison, error)
+	GetRisk(ctx context.Context, period string, riskFreeRate *domain.Decimal) (*domain.RiskReport, error)
+	GetPortfolioHistory(ctx context.Context, from, to time.Time, interval string) (*domain.PortfolioHistory, error)
+	GetInstrumentPrices(ctx context.Context, isin string, from, to time.Time, source string) ([]domain.PricePoint, error)
+	GetExposure(ctx context.Context, lookThrough bool) (*domain.Exposure, error)
+	ImportETFHoldings(ctx context.Context, etfISIN, format string, r io.Reader) (*domain.ETFComposition, error)
+	GetETFHoldings(ctx context.Context, etfISIN string) (*domain.ETFComposition, error)
+	CreateWatchlist(ctx context.Context, name string) (*domain.WatchlistReport, error)
+	ListWatchlists(ctx context.Context) ([]domain.WatchlistReport, error)
+	GetWatchlist(ctx context.Context, id string) (*domain.WatchlistReport, error)
+	RenameWatchlist(ctx context.Context, id, name string) (*domain.WatchlistReport, error)
+	DeleteWatchlist(ctx context.Context, id string) error
+	AddWatchlistItem(ctx context.Context, id string, req application.AddWatchlistItemRequest) (*domain.WatchlistReport, error)
+	UpdateWatchlistItem(ctx context.Context, id, isin string, details domain.WatchlistItemDetails) (*domain.WatchlistReport, error)
+	RemoveWatchlistItem(ctx context.Context, id, isin string) (*domain.WatchlistReport, error)
+	CreateAlertRule(ctx context.Context, req application.CreateAlertRuleRequest) (*domain.AlertRule, error)
+	ListAlertRules(ctx context.Context) ([]*domain.AlertRule, error)
+	DeleteAlertRule(ctx context.Context, id string) error
+	ListAlerts(ctx context.Context, limit int) ([]domain.Alert, error)
 }
 
 type Handler struct {
@@ -31,10 +81,20 @@ func NewHandler(portfolioService PortfolioService) *Handler {
 	}
 }
 
+// AddPositionRequest opens or adds to a position. Identifier may replace
+// ISIN with a CUSIP, SEDOL, FIGI or exchange:ticker. Fee and Tax are
+// optional and may be given in a currency other than the invested amount.
+// TradeDate and Price are optional and record a past purchase, priced at
+// the close of its trade date unless Price is given.
 type AddPositionRequest struct {
-	ISIN           string         `json:"isin" binding:"required"`
+	ISIN           string         `json:"isin" binding:"required_without=Identifier"`
+	Identifier     string         `json:"identifier"`
 	InvestedAmount domain.Decimal `json:"invested_amount" binding:"required"`
 	Currency       string         `json:"currency" binding:"required"`
+	Fee            domain.Money   `json:"fee"`
+	Tax            domain.Money   `json:"tax"`
+	TradeDate      time.Time      `json:"trade_date"`
+	Price          domain.Decimal `json:"price"`
 }
 
 type ErrorResponse struct {
@@ -49,10 +109,16 @@ func (h *Handler) AddPosition(c *gin.Context) {
 		return
 	}
 
-	position, err := h.portfolioService.AddPosition(c.Request.Context(), req.ISIN, req.InvestedAmount, req.Currency)
+	identifier := req.ISIN
+	if req.Identifier != "" {
+		identifier = req.Identifier
+	}
+
+	trade := application.TradeDetails{TradeDate: req.TradeDate, Price: req.Price}
+	position, err := h.portfolioService.AddPosition(c.Request.Context(), identifier, req.InvestedAmount, req.Currency, domain.TradeCharges{Fee: req.Fee, Tax: req.Tax}, trade)
 	if err != nil {
-		slog.ErrorContext(c.Request.Context(), "Failed to add position", "isin", req.ISIN, "error", err)
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		slog.ErrorContext(c.Request.Context(), "Failed to add position", "identifier", identifier, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
 		return
 	}
 
@@ -95,46 +161,62 @@ func (h *Handler) DeletePosition(c *gin.Context) {
 	c.JSON(http.StatusNoContent, nil)
 }
 
-func (h *Handler) GetPortfolio(c *gin.Context) {
-	portfolio, err := h.portfolioService.GetPortfolioSummary(c.Request.Context())
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
-		return
-	}
+// SellPosition sells part or all of a position, keeping closed positions for history.
+func (h *Handler) SellPosition(c *gin.Context) {
+	positionID := c.Param("id")
 
-	totalValue, err := portfolio.TotalValue()
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	var req application.SellPositionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Invalid sell request body", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	totalInvested, err := portfolio.TotalInvested()
+	position, err := h.portfolioService.SellPosition(c.Request.Context(), positionID, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		slog.ErrorContext(c.Request.Context(), "Failed to sell position", "position_id", positionID, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	totalProfitLoss, err := portfolio.TotalProfitLoss()
+	c.JSON(http.StatusOK, position)
+}
+
+// GetPortfolio returns the portfolio with every total converted into its
+// base currency and the exchange rates used for the conversion.
+func (h *Handler) GetPortfolio(c *gin.Context) {
+	portfolio, err := h.portfolioService.GetPortfolioSummary(c.Request.Context())
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
 		return
 	}
 
-	totalProfitLossPercent, err := portfolio.TotalProfitLossPercent()
+	valuation, err := h.portfolioService.GetPortfolioValuation(c.Request.Context())
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		slog.ErrorContext(c.Request.Context(), "Failed to value portfolio", "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
 		return
 	}
 
 	summary := map[string]interface{}{
-		"id":                        portfolio.ID,
-		"name":                      portfolio.Name,
-		"positions":                 portfolio.Positions,
-		"total_value":               totalValue,
-		"total_invested":            totalInvested,
-		"total_profit_loss":         totalProfitLoss,
-		"total_profit_loss_percent": totalProfitLossPercent,
-		"created_at":                portfolio.CreatedAt,
+		"id":                           portfolio.ID,
+		"name":                         portfolio.Name,
+		"positions":                    portfolio.Positions,
+		"base_currency":                valuation.Currency,
+		"total_value":                  valuation.TotalValue,
+		"total_cash":                   valuation.TotalCash,
+		"total_invested":               valuation.TotalInvested,
+		"total_profit_loss":            valuation.TotalProfitLoss,
+		"total_profit_loss_percent":    valuation.TotalProfitLossPercent,
+		"total_realized_profit_loss":   valuation.TotalRealizedProfitLoss,
+		"total_unrealized_profit_loss": valuation.TotalUnrealizedProfitLoss,
+		"total_income":                 valuation.TotalIncome,
+		"total_return":                 valuation.TotalReturn,
+		"total_return_percent":         valuation.TotalReturnPercent,
+		"fees":                         valuation.Fees,
+		"fx_rates":                     valuation.FXRates,
+		"cost_basis_method":            portfolio.CostBasisMethod,
+		"created_at":                   portfolio.CreatedAt,
 	}
 
 	c.JSON(http.StatusOK, summary)
@@ -179,3 +261,803 @@ func (h *Handler) AddPositionsBatch(c *gin.Context) {
 
 	c.JSON(statusCode, result)
 }
+
+// ListTransactions returns the portfolio ledger in trade date order.
+func (h *Handler) ListTransactions(c *gin.Context) {
+	transactions, err := h.portfolioService.ListTransactions(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to list transactions", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, transactions)
+}
+
+// RecordTransaction books a buy, sell, dividend, fee or transfer entry.
+func (h *Handler) RecordTransaction(c *gin.Context) {
+	var req application.RecordTransactionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Invalid transaction request body", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if !req.Type.IsValid() {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "unsupported transaction type: " + string(req.Type)})
+		return
+	}
+
+	transaction, err := h.portfolioService.RecordTransaction(c.Request.Context(), req)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to record transaction", "isin", req.ISIN, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, transaction)
+}
+
+type SetCostBasisMethodRequest struct {
+	Method domain.CostBasisMethod `json:"method" binding:"required"`
+}
+
+// SetCostBasisMethod selects FIFO, LIFO, average cost or specific lot identification.
+func (h *Handler) SetCostBasisMethod(c *gin.Context) {
+	var req SetCostBasisMethodRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Invalid cost basis request body", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.portfolioService.SetCostBasisMethod(c.Request.Context(), req.Method); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to set cost basis method", "method", req.Method, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cost_basis_method": req.Method})
+}
+
+type SetBaseCurrencyRequest struct {
+	Currency string `json:"currency" binding:"required"`
+}
+
+// SetBaseCurrency selects the ISO-4217 currency portfolio totals are reported in.
+func (h *Handler) SetBaseCurrency(c *gin.Context) {
+	var req SetBaseCurrencyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Invalid base currency request body", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := h.portfolioService.SetBaseCurrency(c.Request.Context(), req.Currency); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to set base currency", "currency", req.Currency, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"base_currency": req.Currency})
+}
+
+// ListDividends returns the recorded distributions in pay date order.
+func (h *Handler) ListDividends(c *gin.Context) {
+	dividends, err := h.portfolioService.ListDividends(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to list dividends", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, dividends)
+}
+
+// RecordDividend books a manually entered distribution.
+func (h *Handler) RecordDividend(c *gin.Context) {
+	var req application.RecordDividendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Invalid dividend request body", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	dividend, err := h.portfolioService.RecordDividend(c.Request.Context(), req)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to record dividend", "isin", req.ISIN, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dividend)
+}
+
+// SyncDividends fetches dividend history from the market data provider.
+func (h *Handler) SyncDividends(c *gin.Context) {
+	added, err := h.portfolioService.SyncDividends(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to sync dividends", "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"added": added})
+}
+
+// GetIncomeReport returns dividend income aggregated by month and year.
+func (h *Handler) GetIncomeReport(c *gin.Context) {
+	report, err := h.portfolioService.GetIncomeReport(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to build income report", "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// ListCorporateActions returns the applied corporate actions in effective
+// date order.
+func (h *Handler) ListCorporateActions(c *gin.Context) {
+	actions, err := h.portfolioService.ListCorporateActions(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to list corporate actions", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, actions)
+}
+
+// ApplyCorporateAction adjusts a position for a split, reverse split,
+// symbol change or ISIN change.
+func (h *Handler) ApplyCorporateAction(c *gin.Context) {
+	var req application.ApplyCorporateActionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Invalid corporate action request body", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	action, err := h.portfolioService.ApplyCorporateAction(c.Request.Context(), req)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to apply corporate action", "type", req.Type, "isin", req.ISIN, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, action)
+}
+
+// GetPerformance returns the time-weighted return over the period given in
+// the query (1M, 3M, YTD, 1Y or ALL, default ALL).
+func (h *Handler) GetPerformance(c *gin.Context) {
+	report, err := h.portfolioService.GetPerformance(c.Request.Context(), c.Query("period"))
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to measure performance", "period", c.Query("period"), "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetMoneyWeightedReturn returns the XIRR of the portfolio and its
+// positions since inception.
+func (h *Handler) GetMoneyWeightedReturn(c *gin.Context) {
+	report, err := h.portfolioService.GetMoneyWeightedReturn(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to calculate money-weighted return", "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetCashBalances returns the balance of every cash account and their
+// total in the base currency.
+func (h *Handler) GetCashBalances(c *gin.Context) {
+	summary, err := h.portfolioService.GetCashBalances(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to get cash balances", "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, summary)
+}
+
+// ListCashMovements returns the entries that moved cash, optionally
+// filtered by the currency given in the query.
+func (h *Handler) ListCashMovements(c *gin.Context) {
+	movements, err := h.portfolioService.ListCashMovements(c.Request.Context(), c.Query("currency"))
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to list cash movements", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, movements)
+}
+
+// RecordCashMovement books a deposit or withdrawal.
+func (h *Handler) RecordCashMovement(c *gin.Context) {
+	var req application.RecordCashMovementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Invalid cash movement request body", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	tx, err := h.portfolioService.RecordCashMovement(c.Request.Context(), req)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to record cash movement", "type", req.Type, "currency", req.Currency, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, tx)
+}
+
+// ListTargetAllocations returns the target weights of the portfolio.
+func (h *Handler) ListTargetAllocations(c *gin.Context) {
+	targets, err := h.portfolioService.ListTargetAllocations(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to list target allocations", "error", err)
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, targets)
+}
+
+type SetTargetAllocationsRequest struct {
+	Targets []domain.TargetAllocation `json:"targets"`
+}
+
+// SetTargetAllocations replaces the target weights, which must add up to
+// 100. An empty list clears them.
+func (h *Handler) SetTargetAllocations(c *gin.Context) {
+	var req SetTargetAllocationsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Invalid target allocations request body", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	targets, err := h.portfolioService.SetTargetAllocations(c.Request.Context(), req.Targets)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to set target allocations", "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, targets)
+}
+
+// Rebalance proposes the trades that restore the target weights. The query
+// may add a contribution in the base currency and set buy_only to avoid
+// sales.
+func (h *Handler) Rebalance(c *gin.Context) {
+	var req application.RebalanceRequest
+	if contribution := c.Query("contribution"); contribution != "" {
+		amount, err := domain.NewDecimalFromString(contribution)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid contribution: " + contribution})
+			return
+		}
+		req.Contribution = amount
+	}
+	if buyOnly := c.Query("buy_only"); buyOnly != "" {
+		value, err := strconv.ParseBool(buyOnly)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid buy_only: " + buyOnly})
+			return
+		}
+		req.BuyOnly = value
+	}
+
+	plan, err := h.portfolioService.Rebalance(c.Request.Context(), req)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to plan rebalance", "contribution", req.Contribution, "buy_only", req.BuyOnly, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, plan)
+}
+
+// SetBenchmarkRequest names the benchmark by any identifier the resolve
+// endpoint accepts. An empty identifier removes the benchmark.
+type SetBenchmarkRequest struct {
+	Identifier string `json:"identifier"`
+}
+
+// SetBenchmark selects the index fund or ETF the portfolio is compared
+// against.
+func (h *Handler) SetBenchmark(c *gin.Context) {
+	var req SetBenchmarkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Invalid benchmark request body", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	instrument, err := h.portfolioService.SetBenchmark(c.Request.Context(), req.Identifier)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to set benchmark", "identifier", req.Identifier, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+	if instrument == nil {
+		c.Status(http.StatusNoContent)
+		return
+	}
+
+	c.JSON(http.StatusOK, instrument)
+}
+
+// CompareBenchmark returns the excess return and tracking difference of
+// the portfolio against its benchmark for the period query parameter.
+func (h *Handler) CompareBenchmark(c *gin.Context) {
+	comparison, err := h.portfolioService.CompareBenchmark(c.Request.Context(), c.Query("period"))
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to compare with benchmark", "period", c.Query("period"), "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, comparison)
+}
+
+// GetRisk returns the volatility, drawdown, Sharpe and Sortino ratios and
+// beta of the portfolio and its positions for the period query parameter.
+// A risk_free_rate query parameter, yearly in percent, overrides the
+// configured rate.
+func (h *Handler) GetRisk(c *gin.Context) {
+	var riskFreeRate *domain.Decimal
+	if rate := c.Query("risk_free_rate"); rate != "" {
+		value, err := domain.NewDecimalFromString(rate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid risk_free_rate: " + rate})
+			return
+		}
+		riskFreeRate = &value
+	}
+
+	report, err := h.portfolioService.GetRisk(c.Request.Context(), c.Query("period"), riskFreeRate)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to measure risk", "period", c.Query("period"), "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, report)
+}
+
+// GetPortfolioHistory returns the end-of-day valuations of the portfolio
+// for charts. The from and to query parameters are dates (YYYY-MM-DD),
+// both optional, and interval is day, week or month (default day).
+func (h *Handler) GetPortfolioHistory(c *gin.Context) {
+	from, err := parseDateQuery(c, "from")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	to, err := parseDateQuery(c, "to")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "from must not be after to"})
+		return
+	}
+
+	history, err := h.portfolioService.GetPortfolioHistory(c.Request.Context(), from, to, c.Query("interval"))
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to get portfolio history", "from", c.Query("from"), "to", c.Query("to"), "interval", c.Query("interval"), "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, history)
+}
+
+// GetInstrumentPrices returns the stored daily bars of an instrument. The
+// from and to query parameters are optional dates (YYYY-MM-DD) and source
+// keeps the bars of one source only.
+func (h *Handler) GetInstrumentPrices(c *gin.Context) {
+	isin := c.Param("isin")
+	from, err := parseDateQuery(c, "from")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	to, err := parseDateQuery(c, "to")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if !from.IsZero() && !to.IsZero() && from.After(to) {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "from must not be after to"})
+		return
+	}
+
+	prices, err := h.portfolioService.GetInstrumentPrices(c.Request.Context(), isin, from, to, c.Query("source"))
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to get instrument prices", "isin", isin, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, prices)
+}
+
+// parseDateQuery reads an optional YYYY-MM-DD query parameter as UTC
+// midnight, returning the zero time when it is absent.
+func parseDateQuery(c *gin.Context, name string) (time.Time, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return time.Time{}, errors.New("invalid " + name + ": " + raw)
+	}
+	return date, nil
+}
+
+// GetExposure returns the weights of the portfolio by sector, industry,
+// country, currency and asset type. ETFs with imported holdings are split
+// across their constituents unless look_through is false.
+func (h *Handler) GetExposure(c *gin.Context) {
+	lookThrough := true
+	if raw := c.Query("look_through"); raw != "" {
+		value, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid look_through: " + raw})
+			return
+		}
+		lookThrough = value
+	}
+
+	exposure, err := h.portfolioService.GetExposure(c.Request.Context(), lookThrough)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to calculate exposure", "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, exposure)
+}
+
+// ImportETFHoldings replaces the constituents of an ETF with those of the
+// issuer CSV in the request body. The format query parameter selects the
+// issuer layout, which is otherwise detected from the headers.
+func (h *Handler) ImportETFHoldings(c *gin.Context) {
+	isin := c.Param("isin")
+	body := http.MaxBytesReader(c.Writer, c.Request.Body, maxHoldingsFileSize)
+
+	composition, err := h.portfolioService.ImportETFHoldings(c.Request.Context(), isin, c.Query("format"), body)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to import etf holdings", "isin", isin, "format", c.Query("format"), "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, composition)
+}
+
+// GetETFHoldings returns the imported constituents of an ETF.
+func (h *Handler) GetETFHoldings(c *gin.Context) {
+	isin := c.Param("isin")
+	composition, err := h.portfolioService.GetETFHoldings(c.Request.Context(), isin)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to get etf holdings", "isin", isin, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, composition)
+}
+
+// WatchlistRequest names a watchlist.
+type WatchlistRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// CreateWatchlist stores a new, empty watchlist.
+func (h *Handler) CreateWatchlist(c *gin.Context) {
+	var req WatchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Invalid watchlist request body", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	watchlist, err := h.portfolioService.CreateWatchlist(c.Request.Context(), req.Name)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to create watchlist", "name", req.Name, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, watchlist)
+}
+
+// ListWatchlists returns every watchlist with the quotes, daily changes and
+// distances to target of its items.
+func (h *Handler) ListWatchlists(c *gin.Context) {
+	watchlists, err := h.portfolioService.ListWatchlists(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to list watchlists", "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlists)
+}
+
+// GetWatchlist returns a watchlist with the quotes, daily changes and
+// distances to target of its items.
+func (h *Handler) GetWatchlist(c *gin.Context) {
+	id := c.Param("id")
+	watchlist, err := h.portfolioService.GetWatchlist(c.Request.Context(), id)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to get watchlist", "watchlist_id", id, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlist)
+}
+
+// RenameWatchlist changes the name of a watchlist.
+func (h *Handler) RenameWatchlist(c *gin.Context) {
+	id := c.Param("id")
+	var req WatchlistRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Invalid watchlist request body", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	watchlist, err := h.portfolioService.RenameWatchlist(c.Request.Context(), id, req.Name)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to rename watchlist", "watchlist_id", id, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlist)
+}
+
+// DeleteWatchlist removes a watchlist and its items.
+func (h *Handler) DeleteWatchlist(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.portfolioService.DeleteWatchlist(c.Request.Context(), id); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to delete watchlist", "watchlist_id", id, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddWatchlistItem puts an instrument on a watchlist.
+func (h *Handler) AddWatchlistItem(c *gin.Context) {
+	id := c.Param("id")
+	var req application.AddWatchlistItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Invalid watchlist item request body", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	watchlist, err := h.portfolioService.AddWatchlistItem(c.Request.Context(), id, req)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to add watchlist item", "watchlist_id", id, "isin", req.ISIN, "identifier", req.Identifier, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, watchlist)
+}
+
+// UpdateWatchlistItem changes the notes and target price of an item.
+// Omitted fields are left unchanged.
+func (h *Handler) UpdateWatchlistItem(c *gin.Context) {
+	id, isin := c.Param("id"), c.Param("isin")
+	var details domain.WatchlistItemDetails
+	if err := c.ShouldBindJSON(&details); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Invalid watchlist item request body", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	watchlist, err := h.portfolioService.UpdateWatchlistItem(c.Request.Context(), id, isin, details)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to update watchlist item", "watchlist_id", id, "isin", isin, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlist)
+}
+
+// RemoveWatchlistItem takes an instrument off a watchlist.
+func (h *Handler) RemoveWatchlistItem(c *gin.Context) {
+	id, isin := c.Param("id"), c.Param("isin")
+	watchlist, err := h.portfolioService.RemoveWatchlistItem(c.Request.Context(), id, isin)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to remove watchlist item", "watchlist_id", id, "isin", isin, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, watchlist)
+}
+
+// CreateAlertRule stores an alert rule, checked after every price refresh.
+func (h *Handler) CreateAlertRule(c *gin.Context) {
+	var req application.CreateAlertRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Invalid alert rule request body", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	rule, err := h.portfolioService.CreateAlertRule(c.Request.Context(), req)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to create alert rule", "kind", req.Kind, "isin", req.ISIN, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+// ListAlertRules returns every alert rule with the state of its last
+// check.
+func (h *Handler) ListAlertRules(c *gin.Context) {
+	rules, err := h.portfolioService.ListAlertRules(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to list alert rules", "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, rules)
+}
+
+// DeleteAlertRule removes an alert rule and the alerts it raised.
+func (h *Handler) DeleteAlertRule(c *gin.Context) {
+	id := c.Param("id")
+	if err := h.portfolioService.DeleteAlertRule(c.Request.Context(), id); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to delete alert rule", "rule_id", id, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// ListAlerts returns the latest triggered alerts with their delivery
+// status, newest first. The optional limit query parameter caps how many.
+func (h *Handler) ListAlerts(c *gin.Context) {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		value, err := strconv.Atoi(raw)
+		if err != nil || value <= 0 {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid limit: " + raw})
+			return
+		}
+		limit = value
+	}
+
+	alerts, err := h.portfolioService.ListAlerts(c.Request.Context(), limit)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to list alerts", "limit", limit, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, alerts)
+}
+
+// UpdateInstrument corrects the type of a held instrument, its
+// type-specific attributes and its classification. Omitted fields are left
+// unchanged.
+func (h *Handler) UpdateInstrument(c *gin.Context) {
+	isin := c.Param("isin")
+	var details domain.InstrumentDetails
+	if err := c.ShouldBindJSON(&details); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Invalid instrument request body", "error", err)
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	instrument, err := h.portfolioService.UpdateInstrument(c.Request.Context(), isin, details)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to update instrument", "isin", isin, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, instrument)
+}
+
+// ResolveInstrument validates an ISIN, CUSIP, SEDOL, FIGI or
+// exchange:ticker and returns the instrument it identifies.
+func (h *Handler) ResolveInstrument(c *gin.Context) {
+	identifier := c.Query("identifier")
+	if identifier == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Error: "identifier query parameter is required"})
+		return
+	}
+
+	instrument, err := h.portfolioService.ResolveInstrument(c.Request.Context(), identifier)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Failed to resolve instrument", "identifier", identifier, "error", err)
+		c.JSON(statusForDomainError(err), ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, instrument)
+}
+
+// statusForDomainError maps domain validation errors to client errors.
+func statusForDomainError(err error) int {
+	switch {
+	case errors.Is(err, domain.ErrInvalidTransaction),
+		errors.Is(err, domain.ErrInsufficientQuantity),
+		errors.Is(err, domain.ErrInvalidPosition),
+		errors.Is(err, domain.ErrInvalidCostBasisMethod),
+		errors.Is(err, domain.ErrLotSelectionUnsupported),
+		errors.Is(err, domain.ErrInvalidCurrency),
+		errors.Is(err, domain.ErrCurrencyMismatch),
+		errors.Is(err, domain.ErrInvalidDividend),
+		errors.Is(err, domain.ErrInvalidCorporateAction),
+		errors.Is(err, domain.ErrInvalidPeriod),
+		errors.Is(err, domain.ErrInvalidAllocation),
+		errors.Is(err, domain.ErrInvalidInstrument),
+		errors.Is(err, domain.ErrInvalidIdentifier),
+		errors.Is(err, domain.ErrInvalidHoldings),
+		errors.Is(err, domain.ErrInvalidWatchlist),
+		errors.Is(err, domain.ErrInvalidAlertRule),
+		errors.Is(err, domain.ErrInvalidSnapshotInterval):
+		return http.StatusBadRequest
+	case errors.Is(err, domain.ErrPositionNotFound),
+		errors.Is(err, domain.ErrLotNotFound),
+		errors.Is(err, domain.ErrHoldingsNotFound),
+		errors.Is(err, domain.ErrWatchlistNotFound),
+		errors.Is(err, domain.ErrWatchlistItemNotFound),
+		errors.Is(err, domain.ErrAlertRuleNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, domain.ErrDuplicateDividend),
+		errors.Is(err, domain.ErrDuplicateCorporateAction),
+		errors.Is(err, domain.ErrDuplicateWatchlistItem):
+		return http.StatusConflict
+	case errors.Is(err, domain.ErrInsufficientHistory),
+		errors.Is(err, domain.ErrInvalidCashFlows),
+		errors.Is(err, domain.ErrNoConvergence),
+		errors.Is(err, domain.ErrNoBenchmark),
+		errors.Is(err, application.ErrUnresolvedIdentifier),
+		errors.Is(err, application.ErrHistoricalPriceNotFound):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, domain.ErrFXRateNotFound):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, application.ErrDividendsUnsupported),
+		errors.Is(err, application.ErrPriceHistoryUnsupported),
+		errors.Is(err, application.ErrHoldingsUnsupported),
+		errors.Is(err, application.ErrWatchlistsUnsupported),
+		errors.Is(err, application.ErrAlertsUnsupported),
+		errors.Is(err, application.ErrSnapshotsUnsupported):
+		return http.StatusNotImplemented
+	default:
+		return http.StatusInternalServerError
+	}
+}
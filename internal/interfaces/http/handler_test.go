@@ -5,10 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jmanzanog/stock-tracker/internal/application"
@@ -18,18 +20,60 @@ import (
 // --- Mock Service ---
 
 type MockPortfolioService struct {
-	addPositionFunc         func(ctx context.Context, isin string, amount domain.Decimal, currency string) (*domain.Position, error)
-	addPositionsBatchFunc   func(ctx context.Context, requests []application.AddPositionBatchRequest) *application.AddPositionsBatchResult
-	removePositionFunc      func(ctx context.Context, id string) error
-	getPositionFunc         func(ctx context.Context, id string) (*domain.Position, error)
-	listPositionsFunc       func(ctx context.Context) ([]domain.Position, error)
-	getPortfolioSummaryFunc func(ctx context.Context) (*domain.Portfolio, error)
-	refreshPricesFunc       func(ctx context.Context) error
+	addPositionFunc            func(ctx context.Context, isin string, amount domain.Decimal, currency string, charges domain.TradeCharges, trade application.TradeDetails) (*domain.Position, error)
+	addPositionsBatchFunc      func(ctx context.Context, requests []application.AddPositionBatchRequest) *application.AddPositionsBatchResult
+	removePositionFunc         func(ctx context.Context, id string) error
+	getPositionFunc            func(ctx context.Context, id string) (*domain.Position, error)
+	listPositionsFunc          func(ctx context.Context) ([]domain.Position, error)
+	getPortfolioSummaryFunc    func(ctx context.Context) (*domain.Portfolio, error)
+	refreshPricesFunc          func(ctx context.Context) error
+	recordTransactionFunc      func(ctx context.Context, req application.RecordTransactionRequest) (*domain.Transaction, error)
+	listTransactionsFunc       func(ctx context.Context) ([]domain.Transaction, error)
+	setCostBasisMethodFunc     func(ctx context.Context, method domain.CostBasisMethod) error
+	sellPositionFunc           func(ctx context.Context, id string, req application.SellPositionRequest) (*domain.Position, error)
+	getPortfolioValuationFunc  func(ctx context.Context) (*domain.Valuation, error)
+	setBaseCurrencyFunc        func(ctx context.Context, currency string) error
+	recordDividendFunc         func(ctx context.Context, req application.RecordDividendRequest) (*domain.Dividend, error)
+	listDividendsFunc          func(ctx context.Context) ([]domain.Dividend, error)
+	syncDividendsFunc          func(ctx context.Context) (int, error)
+	getIncomeReportFunc        func(ctx context.Context) (*domain.IncomeReport, error)
+	applyCorporateActionFunc   func(ctx context.Context, req application.ApplyCorporateActionRequest) (*domain.CorporateAction, error)
+	listCorporateActionsFunc   func(ctx context.Context) ([]domain.CorporateAction, error)
+	getPerformanceFunc         func(ctx context.Context, period string) (*domain.PerformanceReport, error)
+	getMoneyWeightedReturnFunc func(ctx context.Context) (*domain.MoneyWeightedReport, error)
+	recordCashMovementFunc     func(ctx context.Context, req application.RecordCashMovementRequest) (*domain.Transaction, error)
+	getCashBalancesFunc        func(ctx context.Context) (*domain.CashSummary, error)
+	listCashMovementsFunc      func(ctx context.Context, currency string) ([]domain.CashMovement, error)
+	setTargetAllocationsFunc   func(ctx context.Context, targets []domain.TargetAllocation) ([]domain.TargetAllocation, error)
+	listTargetAllocationsFunc  func(ctx context.Context) ([]domain.TargetAllocation, error)
+	rebalanceFunc              func(ctx context.Context, req application.RebalanceRequest) (*application.RebalancePlan, error)
+	updateInstrumentFunc       func(ctx context.Context, isin string, details domain.InstrumentDetails) (*domain.Instrument, error)
+	resolveInstrumentFunc      func(ctx context.Context, identifier string) (*domain.Instrument, error)
+	setBenchmarkFunc           func(ctx context.Context, identifier string) (*domain.Instrument, error)
+	compareBenchmarkFunc       func(ctx context.Context, period string) (*domain.BenchmarkComparison, error)
+	getRiskFunc                func(ctx context.Context, period string, riskFreeRate *domain.Decimal) (*domain.RiskReport, error)
+	getExposureFunc            func(ctx context.Context, lookThrough bool) (*domain.Exposure, error)
+	importETFHoldingsFunc      func(ctx context.Context, etfISIN, format string, r io.Reader) (*domain.ETFComposition, error)
+	getETFHoldingsFunc         func(ctx context.Context, etfISIN string) (*domain.ETFComposition, error)
+	createWatchlistFunc        func(ctx context.Context, name string) (*domain.WatchlistReport, error)
+	listWatchlistsFunc         func(ctx context.Context) ([]domain.WatchlistReport, error)
+	getWatchlistFunc           func(ctx context.Context, id string) (*domain.WatchlistReport, error)
+	renameWatchlistFunc        func(ctx context.Context, id, name string) (*domain.WatchlistReport, error)
+	deleteWatchlistFunc        func(ctx context.Context, id string) error
+	addWatchlistItemFunc       func(ctx context.Context, id string, req application.AddWatchlistItemRequest) (*domain.WatchlistReport, error)
+	updateWatchlistItemFunc    func(ctx context.Context, id, isin string, details domain.WatchlistItemDetails) (*domain.WatchlistReport, error)
+	removeWatchlistItemFunc    func(ctx context.Context, id, isin string) (*domain.WatchlistReport, error)
+	createAlertRuleFunc        func(ctx context.Context, req application.CreateAlertRuleRequest) (*domain.AlertRule, error)
+	listAlertRulesFunc         func(ctx context.Context) ([]*domain.AlertRule, error)
+	deleteAlertRuleFunc        func(ctx context.Context, id string) error
+	listAlertsFunc             func(ctx context.Context, limit int) ([]domain.Alert, error)
+	getPortfolioHistoryFunc    func(ctx context.Context, from, to time.Time, interval string) (*domain.PortfolioHistory, error)
+	getInstrumentPricesFunc    func(ctx context.Context, isin string, from, to time.Time, source string) ([]domain.PricePoint, error)
 }
 
-func (m *MockPortfolioService) AddPosition(ctx context.Context, isin string, amount domain.Decimal, currency string) (*domain.Position, error) {
+func (m *MockPortfolioService) AddPosition(ctx context.Context, isin string, amount domain.Decimal, currency string, charges domain.TradeCharges, trade application.TradeDetails) (*domain.Position, error) {
 	if m.addPositionFunc != nil {
-		return m.addPositionFunc(ctx, isin, amount, currency)
+		return m.addPositionFunc(ctx, isin, amount, currency, charges, trade)
 	}
 	return nil, fmt.Errorf("not implemented")
 }
@@ -79,6 +123,300 @@ func (m *MockPortfolioService) RefreshPrices(ctx context.Context) error {
 	return fmt.Errorf("not implemented")
 }
 
+func (m *MockPortfolioService) RecordTransaction(ctx context.Context, req application.RecordTransactionRequest) (*domain.Transaction, error) {
+	if m.recordTransactionFunc != nil {
+		return m.recordTransactionFunc(ctx, req)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) ListTransactions(ctx context.Context) ([]domain.Transaction, error) {
+	if m.listTransactionsFunc != nil {
+		return m.listTransactionsFunc(ctx)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) SetCostBasisMethod(ctx context.Context, method domain.CostBasisMethod) error {
+	if m.setCostBasisMethodFunc != nil {
+		return m.setCostBasisMethodFunc(ctx, method)
+	}
+	return fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) SellPosition(ctx context.Context, id string, req application.SellPositionRequest) (*domain.Position, error) {
+	if m.sellPositionFunc != nil {
+		return m.sellPositionFunc(ctx, id, req)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) GetPortfolioValuation(ctx context.Context) (*domain.Valuation, error) {
+	if m.getPortfolioValuationFunc != nil {
+		return m.getPortfolioValuationFunc(ctx)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) SetBaseCurrency(ctx context.Context, currency string) error {
+	if m.setBaseCurrencyFunc != nil {
+		return m.setBaseCurrencyFunc(ctx, currency)
+	}
+	return fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) RecordDividend(ctx context.Context, req application.RecordDividendRequest) (*domain.Dividend, error) {
+	if m.recordDividendFunc != nil {
+		return m.recordDividendFunc(ctx, req)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) ListDividends(ctx context.Context) ([]domain.Dividend, error) {
+	if m.listDividendsFunc != nil {
+		return m.listDividendsFunc(ctx)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) SyncDividends(ctx context.Context) (int, error) {
+	if m.syncDividendsFunc != nil {
+		return m.syncDividendsFunc(ctx)
+	}
+	return 0, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) GetIncomeReport(ctx context.Context) (*domain.IncomeReport, error) {
+	if m.getIncomeReportFunc != nil {
+		return m.getIncomeReportFunc(ctx)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) ApplyCorporateAction(ctx context.Context, req application.ApplyCorporateActionRequest) (*domain.CorporateAction, error) {
+	if m.applyCorporateActionFunc != nil {
+		return m.applyCorporateActionFunc(ctx, req)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) ListCorporateActions(ctx context.Context) ([]domain.CorporateAction, error) {
+	if m.listCorporateActionsFunc != nil {
+		return m.listCorporateActionsFunc(ctx)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) GetPerformance(ctx context.Context, period string) (*domain.PerformanceReport, error) {
+	if m.getPerformanceFunc != nil {
+		return m.getPerformanceFunc(ctx, period)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) GetMoneyWeightedReturn(ctx context.Context) (*domain.MoneyWeightedReport, error) {
+	if m.getMoneyWeightedReturnFunc != nil {
+		return m.getMoneyWeightedReturnFunc(ctx)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) RecordCashMovement(ctx context.Context, req application.RecordCashMovementRequest) (*domain.Transaction, error) {
+	if m.recordCashMovementFunc != nil {
+		return m.recordCashMovementFunc(ctx, req)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) GetCashBalances(ctx context.Context) (*domain.CashSummary, error) {
+	if m.getCashBalancesFunc != nil {
+		return m.getCashBalancesFunc(ctx)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) ListCashMovements(ctx context.Context, currency string) ([]domain.CashMovement, error) {
+	if m.listCashMovementsFunc != nil {
+		return m.listCashMovementsFunc(ctx, currency)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) SetTargetAllocations(ctx context.Context, targets []domain.TargetAllocation) ([]domain.TargetAllocation, error) {
+	if m.setTargetAllocationsFunc != nil {
+		return m.setTargetAllocationsFunc(ctx, targets)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) ListTargetAllocations(ctx context.Context) ([]domain.TargetAllocation, error) {
+	if m.listTargetAllocationsFunc != nil {
+		return m.listTargetAllocationsFunc(ctx)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) Rebalance(ctx context.Context, req application.RebalanceRequest) (*application.RebalancePlan, error) {
+	if m.rebalanceFunc != nil {
+		return m.rebalanceFunc(ctx, req)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) UpdateInstrument(ctx context.Context, isin string, details domain.InstrumentDetails) (*domain.Instrument, error) {
+	if m.updateInstrumentFunc != nil {
+		return m.updateInstrumentFunc(ctx, isin, details)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) ResolveInstrument(ctx context.Context, identifier string) (*domain.Instrument, error) {
+	if m.resolveInstrumentFunc != nil {
+		return m.resolveInstrumentFunc(ctx, identifier)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) SetBenchmark(ctx context.Context, identifier string) (*domain.Instrument, error) {
+	if m.setBenchmarkFunc != nil {
+		return m.setBenchmarkFunc(ctx, identifier)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) CompareBenchmark(ctx context.Context, period string) (*domain.BenchmarkComparison, error) {
+	if m.compareBenchmarkFunc != nil {
+		return m.compareBenchmarkFunc(ctx, period)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) GetRisk(ctx context.Context, period string, riskFreeRate *domain.Decimal) (*domain.RiskReport, error) {
+	if m.getRiskFunc != nil {
+		return m.getRiskFunc(ctx, period, riskFreeRate)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) GetExposure(ctx context.Context, lookThrough bool) (*domain.Exposure, error) {
+	if m.getExposureFunc != nil {
+		return m.getExposureFunc(ctx, lookThrough)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) ImportETFHoldings(ctx context.Context, etfISIN, format string, r io.Reader) (*domain.ETFComposition, error) {
+	if m.importETFHoldingsFunc != nil {
+		return m.importETFHoldingsFunc(ctx, etfISIN, format, r)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) GetETFHoldings(ctx context.Context, etfISIN string) (*domain.ETFComposition, error) {
+	if m.getETFHoldingsFunc != nil {
+		return m.getETFHoldingsFunc(ctx, etfISIN)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) CreateWatchlist(ctx context.Context, name string) (*domain.WatchlistReport, error) {
+	if m.createWatchlistFunc != nil {
+		return m.createWatchlistFunc(ctx, name)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) ListWatchlists(ctx context.Context) ([]domain.WatchlistReport, error) {
+	if m.listWatchlistsFunc != nil {
+		return m.listWatchlistsFunc(ctx)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) GetWatchlist(ctx context.Context, id string) (*domain.WatchlistReport, error) {
+	if m.getWatchlistFunc != nil {
+		return m.getWatchlistFunc(ctx, id)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) RenameWatchlist(ctx context.Context, id, name string) (*domain.WatchlistReport, error) {
+	if m.renameWatchlistFunc != nil {
+		return m.renameWatchlistFunc(ctx, id, name)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) DeleteWatchlist(ctx context.Context, id string) error {
+	if m.deleteWatchlistFunc != nil {
+		return m.deleteWatchlistFunc(ctx, id)
+	}
+	return fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) AddWatchlistItem(ctx context.Context, id string, req application.AddWatchlistItemRequest) (*domain.WatchlistReport, error) {
+	if m.addWatchlistItemFunc != nil {
+		return m.addWatchlistItemFunc(ctx, id, req)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) UpdateWatchlistItem(ctx context.Context, id, isin string, details domain.WatchlistItemDetails) (*domain.WatchlistReport, error) {
+	if m.updateWatchlistItemFunc != nil {
+		return m.updateWatchlistItemFunc(ctx, id, isin, details)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) RemoveWatchlistItem(ctx context.Context, id, isin string) (*domain.WatchlistReport, error) {
+	if m.removeWatchlistItemFunc != nil {
+		return m.removeWatchlistItemFunc(ctx, id, isin)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) CreateAlertRule(ctx context.Context, req application.CreateAlertRuleRequest) (*domain.AlertRule, error) {
+	if m.createAlertRuleFunc != nil {
+		return m.createAlertRuleFunc(ctx, req)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) ListAlertRules(ctx context.Context) ([]*domain.AlertRule, error) {
+	if m.listAlertRulesFunc != nil {
+		return m.listAlertRulesFunc(ctx)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) DeleteAlertRule(ctx context.Context, id string) error {
+	if m.deleteAlertRuleFunc != nil {
+		return m.deleteAlertRuleFunc(ctx, id)
+	}
+	return fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) ListAlerts(ctx context.Context, limit int) ([]domain.Alert, error) {
+	if m.listAlertsFunc != nil {
+		return m.listAlertsFunc(ctx, limit)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) GetPortfolioHistory(ctx context.Context, from, to time.Time, interval string) (*domain.PortfolioHistory, error) {
+	if m.getPortfolioHistoryFunc != nil {
+		return m.getPortfolioHistoryFunc(ctx, from, to, interval)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
+func (m *MockPortfolioService) GetInstrumentPrices(ctx context.Context, isin string, from, to time.Time, source string) ([]domain.PricePoint, error) {
+	if m.getInstrumentPricesFunc != nil {
+		return m.getInstrumentPricesFunc(ctx, isin, from, to, source)
+	}
+	return nil, fmt.Errorf("not implemented")
+}
+
 // --- Test Setup ---
 
 func setupRouter(handler *Handler) *gin.Engine {
@@ -97,7 +435,7 @@ func TestMain(m *testing.M) {
 
 func TestHandler_AddPosition_Success(t *testing.T) {
 	mockService := &MockPortfolioService{
-		addPositionFunc: func(ctx context.Context, isin string, amount domain.Decimal, currency string) (*domain.Position, error) {
+		addPositionFunc: func(ctx context.Context, isin string, amount domain.Decimal, currency string, charges domain.TradeCharges, trade application.TradeDetails) (*domain.Position, error) {
 			instrument := domain.NewInstrument(isin, "AAPL", "Apple Inc.", domain.InstrumentTypeStock, "USD", "NASDAQ")
 			position := domain.NewPosition(instrument, amount, currency)
 			price := domain.NewDecimalFromInt(150)
@@ -136,6 +474,105 @@ func TestHandler_AddPosition_Success(t *testing.T) {
 	}
 }
 
+func TestHandler_AddPosition_TradeDate(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{"historical close", `{"isin": "US0378331005", "invested_amount": "1000", "currency": "USD", "trade_date": "2021-03-15T00:00:00Z"}`, nil, http.StatusCreated},
+		{"explicit price", `{"isin": "US0378331005", "invested_amount": "1000", "currency": "USD", "trade_date": "2021-03-15T00:00:00Z", "price": "123.5"}`, nil, http.StatusCreated},
+		{"no close", `{"isin": "US0378331005", "invested_amount": "1000", "currency": "USD", "trade_date": "2021-03-15T00:00:00Z"}`, application.ErrHistoricalPriceNotFound, http.StatusUnprocessableEntity},
+		{"invalid date", `{"isin": "US0378331005", "invested_amount": "1000", "currency": "USD", "trade_date": "15/03/2021"}`, nil, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var received application.TradeDetails
+			mockService := &MockPortfolioService{
+				addPositionFunc: func(ctx context.Context, isin string, amount domain.Decimal, currency string, charges domain.TradeCharges, trade application.TradeDetails) (*domain.Position, error) {
+					received = trade
+					if tt.err != nil {
+						return nil, tt.err
+					}
+					position := domain.NewPosition(domain.NewInstrument(isin, "AAPL", "Apple Inc.", domain.InstrumentTypeStock, "USD", "NASDAQ"), amount, currency)
+					return &position, nil
+				},
+			}
+			router := setupRouter(NewHandler(mockService))
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/positions", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.expectedStatus == http.StatusBadRequest {
+				return
+			}
+			if !received.TradeDate.Equal(time.Date(2021, 3, 15, 0, 0, 0, 0, time.UTC)) {
+				t.Errorf("expected the trade date to be passed on, got %v", received.TradeDate)
+			}
+			if tt.name == "explicit price" && received.Price.String() != "123.5" {
+				t.Errorf("expected the price to be passed on, got %s", received.Price)
+			}
+		})
+	}
+}
+
+func TestHandler_AddPosition_CurrencyMismatch(t *testing.T) {
+	mockService := &MockPortfolioService{
+		addPositionFunc: func(ctx context.Context, isin string, amount domain.Decimal, currency string, charges domain.TradeCharges, trade application.TradeDetails) (*domain.Position, error) {
+			return nil, fmt.Errorf("failed to apply transaction: %w", domain.ErrCurrencyMismatch)
+		},
+	}
+	router := setupRouter(NewHandler(mockService))
+
+	body, _ := json.Marshal(AddPositionRequest{ISIN: "US0378331005", InvestedAmount: domain.NewDecimalFromInt(1000), Currency: "GBP"})
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/positions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandler_AddPosition_WithCharges(t *testing.T) {
+	var received domain.TradeCharges
+	mockService := &MockPortfolioService{
+		addPositionFunc: func(ctx context.Context, isin string, amount domain.Decimal, currency string, charges domain.TradeCharges, trade application.TradeDetails) (*domain.Position, error) {
+			received = charges
+			instrument := domain.NewInstrument(isin, "VOD", "Vodafone", domain.InstrumentTypeStock, "GBP", "LSE")
+			position := domain.NewPosition(instrument, amount, currency)
+			return &position, nil
+		},
+	}
+	router := setupRouter(NewHandler(mockService))
+
+	body := `{"isin":"GB00BH4HKS39","invested_amount":"1000","currency":"GBP","fee":{"amount":"9.95","currency":"EUR"},"tax":5}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/positions", bytes.NewReader([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d: %s", http.StatusCreated, w.Code, w.Body.String())
+	}
+	fee, _ := domain.NewDecimalFromString("9.95")
+	if !received.Fee.Equal(domain.NewMoney(fee, "EUR")) {
+		t.Errorf("expected 9.95 EUR fee, got %s", received.Fee)
+	}
+	// A bare number is charged in the trade currency
+	if !received.Tax.Amount.Equal(domain.NewDecimalFromInt(5)) || received.Tax.Currency != "" {
+		t.Errorf("expected 5 tax without currency, got %s", received.Tax)
+	}
+}
+
 func TestHandler_AddPosition_InvalidJSON(t *testing.T) {
 	mockService := &MockPortfolioService{}
 	handler := NewHandler(mockService)
@@ -162,7 +599,7 @@ func TestHandler_AddPosition_MissingFields(t *testing.T) {
 		body map[string]interface{}
 	}{
 		{
-			name: "missing ISIN",
+			name: "missing ISIN and identifier",
 			body: map[string]interface{}{
 				"invested_amount": 1000,
 				"currency":        "USD",
@@ -197,9 +634,32 @@ func TestHandler_AddPosition_MissingFields(t *testing.T) {
 	}
 }
 
+func TestHandler_AddPosition_Identifier(t *testing.T) {
+	mockService := &MockPortfolioService{
+		addPositionFunc: func(ctx context.Context, identifier string, amount domain.Decimal, currency string, charges domain.TradeCharges, trade application.TradeDetails) (*domain.Position, error) {
+			if identifier != "NASDAQ:AAPL" {
+				t.Errorf("expected the identifier to be passed on, got %s", identifier)
+			}
+			return nil, application.ErrUnresolvedIdentifier
+		},
+	}
+	router := setupRouter(NewHandler(mockService))
+
+	body := `{"identifier":"NASDAQ:AAPL","invested_amount":"1000","currency":"USD"}`
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/positions", bytes.NewBufferString(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("expected status %d, got %d: %s", http.StatusUnprocessableEntity, w.Code, w.Body.String())
+	}
+}
+
 func TestHandler_AddPosition_ServiceError(t *testing.T) {
 	mockService := &MockPortfolioService{
-		addPositionFunc: func(ctx context.Context, isin string, amount domain.Decimal, currency string) (*domain.Position, error) {
+		addPositionFunc: func(ctx context.Context, isin string, amount domain.Decimal, currency string, charges domain.TradeCharges, trade application.TradeDetails) (*domain.Position, error) {
 			return nil, fmt.Errorf("service error: instrument not found")
 		},
 	}
@@ -575,53 +1035,203 @@ func TestHandler_DeletePosition_NotFound(t *testing.T) {
 	}
 }
 
-// --- GetPortfolio Tests ---
+// --- SellPosition Tests ---
 
-func TestHandler_GetPortfolio_Success(t *testing.T) {
+func TestHandler_SellPosition_Success(t *testing.T) {
 	mockService := &MockPortfolioService{
-		getPortfolioSummaryFunc: func(ctx context.Context) (*domain.Portfolio, error) {
-			portfolio := domain.NewPortfolio("test-portfolio")
+		sellPositionFunc: func(ctx context.Context, id string, req application.SellPositionRequest) (*domain.Position, error) {
 			instrument := domain.NewInstrument("US0378331005", "AAPL", "Apple Inc.", domain.InstrumentTypeStock, "USD", "NASDAQ")
-			position := domain.NewPosition(instrument, domain.NewDecimalFromInt(1000), "USD")
-			price := domain.NewDecimalFromInt(150)
-			_ = position.UpdatePrice(price)
-			_ = portfolio.AddPosition(position)
-			return &portfolio, nil
+			position := domain.NewPosition(instrument, domain.NewDecimalFromInt(600), "USD")
+			position.ID = id
+			position.Quantity = domain.NewDecimalFromInt(6)
+			position.RealizedProfitLoss = domain.NewMoney(domain.NewDecimalFromInt(200), "USD")
+			return &position, nil
 		},
 	}
 
 	handler := NewHandler(mockService)
 	router := setupRouter(handler)
 
-	req := httptest.NewRequest(http.MethodGet, "/api/v1/portfolio", nil)
+	body := []byte(`{"quantity":"4","price":"200"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/positions/pos-1/sell", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
 	w := httptest.NewRecorder()
 
 	router.ServeHTTP(w, req)
 
 	if w.Code != http.StatusOK {
 		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
-		t.Logf("Response body: %s", w.Body.String())
 	}
 
-	var summary map[string]interface{}
-	if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+	var position domain.Position
+	if err := json.Unmarshal(w.Body.Bytes(), &position); err != nil {
 		t.Fatalf("failed to unmarshal response: %v", err)
 	}
-
-	// Verify all expected fields are present
-	expectedFields := []string{"id", "name", "positions", "total_value", "total_invested", "total_profit_loss", "total_profit_loss_percent", "created_at"}
-	for _, field := range expectedFields {
-		if _, ok := summary[field]; !ok {
-			t.Errorf("expected field %s in response", field)
-		}
+	if position.ID != "pos-1" {
+		t.Errorf("expected position pos-1, got %s", position.ID)
 	}
 }
 
-func TestHandler_GetPortfolio_ServiceError(t *testing.T) {
-	mockService := &MockPortfolioService{
-		getPortfolioSummaryFunc: func(ctx context.Context) (*domain.Portfolio, error) {
-			return nil, fmt.Errorf("database error")
-		},
+func TestHandler_SellPosition_Errors(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+	}{
+		{"not found", domain.ErrPositionNotFound, http.StatusNotFound},
+		{"insufficient quantity", domain.ErrInsufficientQuantity, http.StatusBadRequest},
+		{"invalid request", domain.ErrInvalidTransaction, http.StatusBadRequest},
+		{"storage failure", fmt.Errorf("database error"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				sellPositionFunc: func(ctx context.Context, id string, req application.SellPositionRequest) (*domain.Position, error) {
+					return nil, fmt.Errorf("failed to sell position: %w", tt.err)
+				},
+			}
+
+			handler := NewHandler(mockService)
+			router := setupRouter(handler)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/positions/pos-1/sell", bytes.NewReader([]byte(`{"quantity":"1"}`)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+// --- GetPortfolio Tests ---
+
+func TestHandler_GetPortfolio_Success(t *testing.T) {
+	mockService := &MockPortfolioService{
+		getPortfolioSummaryFunc: func(ctx context.Context) (*domain.Portfolio, error) {
+			portfolio := domain.NewPortfolio("test-portfolio")
+			instrument := domain.NewInstrument("US0378331005", "AAPL", "Apple Inc.", domain.InstrumentTypeStock, "USD", "NASDAQ")
+			position := domain.NewPosition(instrument, domain.NewDecimalFromInt(1000), "USD")
+			price := domain.NewDecimalFromInt(150)
+			_ = position.UpdatePrice(price)
+			_ = portfolio.AddPosition(position)
+			return &portfolio, nil
+		},
+		getPortfolioValuationFunc: func(ctx context.Context) (*domain.Valuation, error) {
+			return &domain.Valuation{
+				Currency:   "EUR",
+				TotalValue: domain.NewDecimalFromInt(900),
+				FXRates:    []domain.FXRate{{From: "USD", To: "EUR", Rate: domain.NewDecimalFromInt(1)}},
+			}, nil
+		},
+	}
+
+	handler := NewHandler(mockService)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/portfolio", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+		t.Logf("Response body: %s", w.Body.String())
+	}
+
+	var summary map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	// Verify all expected fields are present
+	expectedFields := []string{"id", "name", "positions", "total_value", "total_invested", "total_profit_loss", "total_profit_loss_percent",
+		"total_realized_profit_loss", "total_unrealized_profit_loss", "total_income", "total_return", "total_return_percent",
+		"fees", "total_cash", "base_currency", "fx_rates", "cost_basis_method", "created_at"}
+	for _, field := range expectedFields {
+		if _, ok := summary[field]; !ok {
+			t.Errorf("expected field %s in response", field)
+		}
+	}
+	if summary["base_currency"] != "EUR" || fmt.Sprint(summary["total_value"]) != "900" {
+		t.Errorf("expected totals in EUR, got %v %v", summary["base_currency"], summary["total_value"])
+	}
+}
+
+func TestHandler_GetPortfolio_MissingFXRate(t *testing.T) {
+	mockService := &MockPortfolioService{
+		getPortfolioSummaryFunc: func(ctx context.Context) (*domain.Portfolio, error) {
+			portfolio := domain.NewPortfolio("test-portfolio")
+			return &portfolio, nil
+		},
+		getPortfolioValuationFunc: func(ctx context.Context) (*domain.Valuation, error) {
+			return nil, fmt.Errorf("failed to get fx rate: %w", domain.ErrFXRateNotFound)
+		},
+	}
+
+	handler := NewHandler(mockService)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/portfolio", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, w.Code)
+	}
+}
+
+// --- SetBaseCurrency Tests ---
+
+func TestHandler_SetBaseCurrency(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantStatus int
+	}{
+		{"success", `{"currency":"USD"}`, nil, http.StatusOK},
+		{"missing currency", `{}`, nil, http.StatusBadRequest},
+		{"invalid currency", `{"currency":"usd"}`, domain.ErrInvalidCurrency, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				setBaseCurrencyFunc: func(ctx context.Context, currency string) error {
+					if tt.err != nil {
+						return fmt.Errorf("failed to set base currency: %w", tt.err)
+					}
+					return nil
+				},
+			}
+
+			handler := NewHandler(mockService)
+			router := setupRouter(handler)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/portfolio/base-currency", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, w.Code)
+			}
+		})
+	}
+}
+
+func TestHandler_GetPortfolio_ServiceError(t *testing.T) {
+	mockService := &MockPortfolioService{
+		getPortfolioSummaryFunc: func(ctx context.Context) (*domain.Portfolio, error) {
+			return nil, fmt.Errorf("database error")
+		},
 	}
 
 	handler := NewHandler(mockService)
@@ -688,6 +1298,1273 @@ func TestHandler_RefreshPrices_ServiceError(t *testing.T) {
 	}
 }
 
+// --- Transaction Tests ---
+
+func TestHandler_RecordTransaction_Success(t *testing.T) {
+	mockService := &MockPortfolioService{
+		recordTransactionFunc: func(ctx context.Context, req application.RecordTransactionRequest) (*domain.Transaction, error) {
+			tx := domain.NewTransaction(req.Type, req.ISIN, req.TradeDate, req.Quantity, req.Price, req.Amount, req.Currency)
+			return &tx, nil
+		},
+	}
+
+	handler := NewHandler(mockService)
+	router := setupRouter(handler)
+
+	body := []byte(`{"isin":"US0378331005","type":"buy","trade_date":"2024-03-01T00:00:00Z","quantity":"10","price":"150","currency":"USD"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/portfolio/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+
+	var tx domain.Transaction
+	if err := json.Unmarshal(w.Body.Bytes(), &tx); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if tx.Type != domain.TransactionTypeBuy {
+		t.Errorf("expected type buy, got %s", tx.Type)
+	}
+}
+
+func TestHandler_RecordTransaction_InvalidType(t *testing.T) {
+	handler := NewHandler(&MockPortfolioService{})
+	router := setupRouter(handler)
+
+	body := []byte(`{"isin":"US0378331005","type":"swap","quantity":"10","price":"150","currency":"USD"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/portfolio/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandler_RecordTransaction_InsufficientQuantity(t *testing.T) {
+	mockService := &MockPortfolioService{
+		recordTransactionFunc: func(ctx context.Context, req application.RecordTransactionRequest) (*domain.Transaction, error) {
+			return nil, fmt.Errorf("failed to record transaction: %w", domain.ErrInsufficientQuantity)
+		},
+	}
+
+	handler := NewHandler(mockService)
+	router := setupRouter(handler)
+
+	body := []byte(`{"isin":"US0378331005","type":"sell","quantity":"10","price":"150","currency":"USD"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/portfolio/transactions", bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestHandler_ListTransactions_Success(t *testing.T) {
+	mockService := &MockPortfolioService{
+		listTransactionsFunc: func(ctx context.Context) ([]domain.Transaction, error) {
+			tx := domain.NewTransaction(domain.TransactionTypeBuy, "US0378331005", time.Now(),
+				domain.NewDecimalFromInt(10), domain.NewDecimalFromInt(150), domain.NewDecimalFromInt(1500), "USD")
+			return []domain.Transaction{tx}, nil
+		},
+	}
+
+	handler := NewHandler(mockService)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/portfolio/transactions", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var transactions []domain.Transaction
+	if err := json.Unmarshal(w.Body.Bytes(), &transactions); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+
+	if len(transactions) != 1 {
+		t.Errorf("expected 1 transaction, got %d", len(transactions))
+	}
+}
+
+// --- Cost Basis Tests ---
+
+func TestHandler_SetCostBasisMethod_Success(t *testing.T) {
+	var received domain.CostBasisMethod
+	mockService := &MockPortfolioService{
+		setCostBasisMethodFunc: func(ctx context.Context, method domain.CostBasisMethod) error {
+			received = method
+			return nil
+		},
+	}
+
+	handler := NewHandler(mockService)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/portfolio/cost-basis", bytes.NewReader([]byte(`{"method":"fifo"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	if received != domain.CostBasisFIFO {
+		t.Errorf("expected method fifo, got %s", received)
+	}
+}
+
+func TestHandler_SetCostBasisMethod_Invalid(t *testing.T) {
+	mockService := &MockPortfolioService{
+		setCostBasisMethodFunc: func(ctx context.Context, method domain.CostBasisMethod) error {
+			return fmt.Errorf("failed to set cost basis method: %w", domain.ErrInvalidCostBasisMethod)
+		},
+	}
+
+	handler := NewHandler(mockService)
+	router := setupRouter(handler)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/v1/portfolio/cost-basis", bytes.NewReader([]byte(`{"method":"hifo"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+// --- Dividend Tests ---
+
+func TestHandler_RecordDividend(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{"success", `{"isin":"US0378331005","ex_date":"2024-02-09T00:00:00Z","gross_amount":"24","withholding_tax":"3.6"}`, nil, http.StatusCreated},
+		{"missing ex-date", `{"isin":"US0378331005","gross_amount":"24"}`, nil, http.StatusBadRequest},
+		{"invalid", `{"isin":"US0378331005","ex_date":"2024-02-09T00:00:00Z","gross_amount":"24","withholding_tax":"30"}`, domain.ErrInvalidDividend, http.StatusBadRequest},
+		{"duplicate", `{"isin":"US0378331005","ex_date":"2024-02-09T00:00:00Z","gross_amount":"24"}`, domain.ErrDuplicateDividend, http.StatusConflict},
+		{"not held", `{"isin":"US5949181045","ex_date":"2024-02-09T00:00:00Z","gross_amount":"24"}`, domain.ErrPositionNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				recordDividendFunc: func(ctx context.Context, req application.RecordDividendRequest) (*domain.Dividend, error) {
+					if tt.err != nil {
+						return nil, fmt.Errorf("failed to record dividend: %w", tt.err)
+					}
+					d := domain.NewDividend(req.ISIN, req.ExDate, req.PayDate, req.GrossAmount, req.WithholdingTax, "USD", domain.DividendSourceManual)
+					return &d, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/portfolio/dividends", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_SyncDividends_Unsupported(t *testing.T) {
+	mockService := &MockPortfolioService{
+		syncDividendsFunc: func(ctx context.Context) (int, error) {
+			return 0, application.ErrDividendsUnsupported
+		},
+	}
+
+	router := setupRouter(NewHandler(mockService))
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/portfolio/dividends/sync", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("expected status %d, got %d", http.StatusNotImplemented, w.Code)
+	}
+}
+
+func TestHandler_GetIncomeReport(t *testing.T) {
+	mockService := &MockPortfolioService{
+		getIncomeReportFunc: func(ctx context.Context) (*domain.IncomeReport, error) {
+			return &domain.IncomeReport{
+				Currency: "EUR",
+				Total:    domain.IncomePeriod{Period: "total", NetAmount: domain.NewDecimalFromInt(42), Count: 2},
+				Monthly:  []domain.IncomePeriod{{Period: "2024-02", NetAmount: domain.NewDecimalFromInt(42), Count: 2}},
+				Yearly:   []domain.IncomePeriod{{Period: "2024", NetAmount: domain.NewDecimalFromInt(42), Count: 2}},
+			}, nil
+		},
+	}
+
+	router := setupRouter(NewHandler(mockService))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/portfolio/income", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var report map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	for _, field := range []string{"currency", "total", "monthly", "yearly"} {
+		if _, ok := report[field]; !ok {
+			t.Errorf("expected field %s in response", field)
+		}
+	}
+}
+
+func TestHandler_ApplyCorporateAction(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		err            error
+		expectedStatus int
+	}{
+		{"split", `{"type":"split","isin":"US0378331005","effective_date":"2020-08-31T00:00:00Z","ratio_from":"1","ratio_to":"4"}`, nil, http.StatusCreated},
+		{"missing effective date", `{"type":"split","isin":"US0378331005","ratio_from":"1","ratio_to":"4"}`, nil, http.StatusBadRequest},
+		{"invalid", `{"type":"reverse_split","isin":"US0378331005","effective_date":"2020-08-31T00:00:00Z","ratio_from":"1","ratio_to":"4"}`, domain.ErrInvalidCorporateAction, http.StatusBadRequest},
+		{"duplicate", `{"type":"split","isin":"US0378331005","effective_date":"2020-08-31T00:00:00Z","ratio_from":"1","ratio_to":"4"}`, domain.ErrDuplicateCorporateAction, http.StatusConflict},
+		{"not held", `{"type":"symbol_change","isin":"US5949181045","effective_date":"2020-08-31T00:00:00Z","new_symbol":"MSFT"}`, domain.ErrPositionNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				applyCorporateActionFunc: func(ctx context.Context, req application.ApplyCorporateActionRequest) (*domain.CorporateAction, error) {
+					if tt.err != nil {
+						return nil, fmt.Errorf("failed to apply corporate action: %w", tt.err)
+					}
+					a := domain.NewCorporateAction(req.Type, req.ISIN, req.EffectiveDate)
+					return &a, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/portfolio/corporate-actions", bytes.NewReader([]byte(tt.body)))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_ListCorporateActions(t *testing.T) {
+	mockService := &MockPortfolioService{
+		listCorporateActionsFunc: func(ctx context.Context) ([]domain.CorporateAction, error) {
+			return []domain.CorporateAction{
+				domain.NewCorporateAction(domain.CorporateActionSplit, "US0378331005", time.Date(2020, 8, 31, 0, 0, 0, 0, time.UTC)),
+			}, nil
+		},
+	}
+
+	router := setupRouter(NewHandler(mockService))
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/portfolio/corporate-actions", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var actions []domain.CorporateAction
+	if err := json.Unmarshal(w.Body.Bytes(), &actions); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(actions) != 1 || actions[0].Type != domain.CorporateActionSplit {
+		t.Errorf("expected 1 split, got %+v", actions)
+	}
+}
+
+func TestHandler_GetPerformance(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "?period=ytd", nil, http.StatusOK},
+		{"invalid period", "?period=10Y", domain.ErrInvalidPeriod, http.StatusBadRequest},
+		{"empty ledger", "", domain.ErrInsufficientHistory, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requested string
+			mockService := &MockPortfolioService{
+				getPerformanceFunc: func(ctx context.Context, period string) (*domain.PerformanceReport, error) {
+					requested = period
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					return &domain.PerformanceReport{
+						Period:             domain.PeriodYearToDate,
+						Currency:           "EUR",
+						TimeWeightedReturn: domain.NewDecimalFromInt(7),
+					}, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/portfolio/performance"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.name == "success" {
+				if requested != "ytd" {
+					t.Errorf("expected period ytd to be passed through, got %q", requested)
+				}
+				var report domain.PerformanceReport
+				if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil {
+					t.Fatalf("failed to unmarshal response: %v", err)
+				}
+				if !report.TimeWeightedReturn.Equal(domain.NewDecimalFromInt(7)) {
+					t.Errorf("expected 7%% return, got %s", report.TimeWeightedReturn)
+				}
+			}
+		})
+	}
+}
+
+func TestHandler_GetMoneyWeightedReturn(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", nil, http.StatusOK},
+		{"no convergence", fmt.Errorf("failed to calculate money-weighted return: %w", domain.ErrNoConvergence), http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				getMoneyWeightedReturnFunc: func(ctx context.Context) (*domain.MoneyWeightedReport, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					return &domain.MoneyWeightedReport{Currency: "EUR", XIRR: domain.NewDecimalFromInt(8)}, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/portfolio/xirr", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_RecordCashMovement(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"deposit", `{"type": "deposit", "amount": "1000", "currency": "EUR"}`, nil, http.StatusCreated},
+		{"missing currency", `{"type": "deposit", "amount": "1000"}`, nil, http.StatusBadRequest},
+		{"not a cash movement", `{"type": "buy", "amount": "1000", "currency": "EUR"}`, domain.ErrInvalidTransaction, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				recordCashMovementFunc: func(ctx context.Context, req application.RecordCashMovementRequest) (*domain.Transaction, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					tx := domain.NewTransaction(req.Type, "", time.Now(), domain.Zero, domain.Zero, req.Amount, req.Currency)
+					return &tx, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/portfolio/cash/movements", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_GetCashBalances(t *testing.T) {
+	mockService := &MockPortfolioService{
+		getCashBalancesFunc: func(ctx context.Context) (*domain.CashSummary, error) {
+			return &domain.CashSummary{
+				Currency: "EUR",
+				Total:    domain.NewDecimalFromInt(750),
+				Balances: []domain.Money{domain.NewMoney(domain.NewDecimalFromInt(500), "EUR"), domain.NewMoney(domain.NewDecimalFromInt(500), "USD")},
+			}, nil
+		},
+		listCashMovementsFunc: func(ctx context.Context, currency string) ([]domain.CashMovement, error) {
+			if currency != "USD" {
+				t.Errorf("expected currency filter USD, got %q", currency)
+			}
+			return []domain.CashMovement{{Type: domain.TransactionTypeDeposit, Amount: domain.NewMoney(domain.NewDecimalFromInt(500), "USD")}}, nil
+		},
+	}
+	router := setupRouter(NewHandler(mockService))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/portfolio/cash", nil)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	var summary domain.CashSummary
+	if err := json.Unmarshal(w.Body.Bytes(), &summary); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(summary.Balances) != 2 || summary.Balances[1].Currency != "USD" {
+		t.Errorf("expected EUR and USD balances, got %+v", summary.Balances)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/portfolio/cash/movements?currency=USD", nil)
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+}
+
+func TestHandler_SetTargetAllocations(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"valid", `{"targets":[{"isin":"US0378331005","asset_class":"equity","weight":60},{"isin":"IE00B4L5Y983","weight":"40"}]}`, nil, http.StatusOK},
+		{"malformed", `{"targets":`, nil, http.StatusBadRequest},
+		{"invalid weights", `{"targets":[{"isin":"US0378331005","weight":60}]}`, domain.ErrInvalidAllocation, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				setTargetAllocationsFunc: func(ctx context.Context, targets []domain.TargetAllocation) ([]domain.TargetAllocation, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					return targets, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/portfolio/targets", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_Rebalance(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"full", "", nil, http.StatusOK},
+		{"buy only with contribution", "?contribution=1000.50&buy_only=true", nil, http.StatusOK},
+		{"invalid contribution", "?contribution=lots", nil, http.StatusBadRequest},
+		{"invalid buy_only", "?buy_only=maybe", nil, http.StatusBadRequest},
+		{"no targets", "", domain.ErrInvalidAllocation, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				rebalanceFunc: func(ctx context.Context, req application.RebalanceRequest) (*application.RebalancePlan, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					if tt.query != "" {
+						expected, _ := domain.NewDecimalFromString("1000.50")
+						if !req.BuyOnly || !req.Contribution.Equal(expected) {
+							t.Errorf("expected a buy-only contribution of 1000.50, got %+v", req)
+						}
+					}
+					return &application.RebalancePlan{Currency: "EUR", TargetValue: req.Contribution}, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/portfolio/rebalance"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_UpdateInstrument(t *testing.T) {
+	tests := []struct {
+		name           string
+		isin           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"bond attributes", "DE0001102580", `{"type":"bond","coupon_rate":"4.25","maturity_date":"2030-06-15T00:00:00Z"}`, nil, http.StatusOK},
+		{"crypto precision", "XC000A2YY636", `{"type":"crypto","unit_precision":6}`, nil, http.StatusOK},
+		{"classification", "US0378331005", `{"type":"stock","sector":"Technology","industry":"Consumer Electronics","country":"US"}`, nil, http.StatusOK},
+		{"malformed", "DE0001102580", `{"type":`, nil, http.StatusBadRequest},
+		{"invalid attributes", "DE0001102580", `{"type":"warrant"}`, domain.ErrInvalidInstrument, http.StatusBadRequest},
+		{"not held", "DE0001102580", `{}`, domain.ErrPositionNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				updateInstrumentFunc: func(ctx context.Context, isin string, details domain.InstrumentDetails) (*domain.Instrument, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					if isin != tt.isin || details.Type == nil {
+						t.Errorf("expected a type for %s, got %s %+v", tt.isin, isin, details)
+					}
+					inst := domain.NewInstrument(isin, "SYM", "Name", *details.Type, "EUR", "XETRA")
+					return &inst, details.Apply(&inst)
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/instruments/"+tt.isin, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_ResolveInstrument(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"cusip", "?identifier=037833100", nil, http.StatusOK},
+		{"missing", "", nil, http.StatusBadRequest},
+		{"bad check digit", "?identifier=US0378331006", domain.ErrInvalidIdentifier, http.StatusBadRequest},
+		{"unresolved", "?identifier=BBG000B9XRY4", application.ErrUnresolvedIdentifier, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				resolveInstrumentFunc: func(ctx context.Context, identifier string) (*domain.Instrument, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					inst := domain.NewInstrument("US0378331005", "AAPL", "Apple Inc.", domain.InstrumentTypeStock, "USD", "NASDAQ")
+					return &inst, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/instruments/resolve"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_SetBenchmark(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"set", `{"identifier":"IE00B4L5Y983"}`, nil, http.StatusOK},
+		{"removed", `{"identifier":""}`, nil, http.StatusNoContent},
+		{"invalid", `{"identifier":"IE00B4L5Y984"}`, domain.ErrInvalidIdentifier, http.StatusBadRequest},
+		{"malformed", `{"identifier":`, nil, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				setBenchmarkFunc: func(ctx context.Context, identifier string) (*domain.Instrument, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					if identifier == "" {
+						return nil, nil
+					}
+					inst := domain.NewInstrument(identifier, "IWDA", "iShares Core MSCI World", domain.InstrumentTypeETF, "EUR", "XAMS")
+					return &inst, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodPut, "/api/v1/portfolio/benchmark", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_CompareBenchmark(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", nil, http.StatusOK},
+		{"no benchmark", domain.ErrNoBenchmark, http.StatusUnprocessableEntity},
+		{"not enough prices", domain.ErrInsufficientHistory, http.StatusUnprocessableEntity},
+		{"no price history", application.ErrPriceHistoryUnsupported, http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				compareBenchmarkFunc: func(ctx context.Context, period string) (*domain.BenchmarkComparison, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					if period != "1Y" {
+						t.Errorf("expected period 1Y, got %q", period)
+					}
+					return &domain.BenchmarkComparison{BenchmarkISIN: "IE00B4L5Y983", Period: domain.PeriodOneYear, ExcessReturn: domain.NewDecimalFromInt(-2)}, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/portfolio/benchmark?period=1Y", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_GetRisk(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		serviceErr     error
+		expectedRate   string
+		expectedStatus int
+	}{
+		{"success", "period=1Y", nil, "", http.StatusOK},
+		{"risk-free rate", "period=1Y&risk_free_rate=3.5", nil, "3.5", http.StatusOK},
+		{"invalid risk-free rate", "period=1Y&risk_free_rate=abc", nil, "", http.StatusBadRequest},
+		{"invalid period", "period=2W", domain.ErrInvalidPeriod, "", http.StatusBadRequest},
+		{"not enough prices", "period=1Y", domain.ErrInsufficientHistory, "", http.StatusUnprocessableEntity},
+		{"no price history", "period=1Y", application.ErrPriceHistoryUnsupported, "", http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				getRiskFunc: func(ctx context.Context, period string, riskFreeRate *domain.Decimal) (*domain.RiskReport, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					if tt.expectedRate == "" && riskFreeRate != nil {
+						t.Errorf("expected the configured rate, got %s", riskFreeRate)
+					}
+					if tt.expectedRate != "" && (riskFreeRate == nil || riskFreeRate.String() != tt.expectedRate) {
+						t.Errorf("expected risk-free rate %s, got %v", tt.expectedRate, riskFreeRate)
+					}
+					return &domain.RiskReport{Period: domain.PeriodOneYear, RiskMetrics: domain.RiskMetrics{Observations: 250}}, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/portfolio/risk?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_GetPortfolioHistory(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		serviceErr     error
+		expectedFrom   time.Time
+		expectedTo     time.Time
+		expectedStatus int
+	}{
+		{"success", "", nil, time.Time{}, time.Time{}, http.StatusOK},
+		{"date range", "from=2025-01-01&to=2025-03-31&interval=week", nil,
+			time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC), http.StatusOK},
+		{"invalid from", "from=01/01/2025", nil, time.Time{}, time.Time{}, http.StatusBadRequest},
+		{"invalid to", "to=yesterday", nil, time.Time{}, time.Time{}, http.StatusBadRequest},
+		{"from after to", "from=2025-04-01&to=2025-03-31", nil, time.Time{}, time.Time{}, http.StatusBadRequest},
+		{"invalid interval", "interval=hour", domain.ErrInvalidSnapshotInterval, time.Time{}, time.Time{}, http.StatusBadRequest},
+		{"no snapshots", "", application.ErrSnapshotsUnsupported, time.Time{}, time.Time{}, http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				getPortfolioHistoryFunc: func(ctx context.Context, from, to time.Time, interval string) (*domain.PortfolioHistory, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					if !from.Equal(tt.expectedFrom) || !to.Equal(tt.expectedTo) {
+						t.Errorf("expected %s to %s, got %s to %s", tt.expectedFrom, tt.expectedTo, from, to)
+					}
+					return &domain.PortfolioHistory{Currency: "EUR", Interval: domain.IntervalDay, Snapshots: []domain.PortfolioSnapshot{}}, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/portfolio/history?"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_GetInstrumentPrices(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		serviceErr     error
+		expectedSource string
+		expectedStatus int
+	}{
+		{"success", "", nil, "", http.StatusOK},
+		{"date range and source", "?from=2025-01-01&to=2025-03-31&source=quote", nil, "quote", http.StatusOK},
+		{"invalid from", "?from=2025-13-01", nil, "", http.StatusBadRequest},
+		{"from after to", "?from=2025-04-01&to=2025-03-31", nil, "", http.StatusBadRequest},
+		{"invalid isin", "", domain.ErrInvalidIdentifier, "", http.StatusBadRequest},
+		{"no price history", "", application.ErrPriceHistoryUnsupported, "", http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				getInstrumentPricesFunc: func(ctx context.Context, isin string, from, to time.Time, source string) ([]domain.PricePoint, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					if isin != "US0378331005" || source != tt.expectedSource {
+						t.Errorf("unexpected isin %s or source %q", isin, source)
+					}
+					return []domain.PricePoint{domain.NewPricePoint(isin, time.Now(), domain.NewDecimalFromInt(150), "USD", "quote")}, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/instruments/US0378331005/prices"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_GetExposure(t *testing.T) {
+	tests := []struct {
+		name                string
+		query               string
+		serviceErr          error
+		expectedStatus      int
+		expectedLookThrough bool
+	}{
+		{"success", "", nil, http.StatusOK, true},
+		{"without look-through", "?look_through=false", nil, http.StatusOK, false},
+		{"invalid look-through", "?look_through=maybe", nil, http.StatusBadRequest, false},
+		{"missing rate", "", domain.ErrFXRateNotFound, http.StatusServiceUnavailable, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				getExposureFunc: func(ctx context.Context, lookThrough bool) (*domain.Exposure, error) {
+					if lookThrough != tt.expectedLookThrough {
+						t.Errorf("expected look-through %v, got %v", tt.expectedLookThrough, lookThrough)
+					}
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					return &domain.Exposure{
+						Currency:   "EUR",
+						TotalValue: domain.NewDecimalFromInt(1000),
+						Sectors:    []domain.ExposureWeight{{Key: "Technology", Value: domain.NewDecimalFromInt(1000), Weight: domain.NewDecimalFromInt(100)}},
+					}, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/portfolio/exposure"+tt.query, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusOK && !bytes.Contains(w.Body.Bytes(), []byte(`"key":"Technology"`)) {
+				t.Errorf("expected the sector breakdown, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_ImportETFHoldings(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", "?format=ishares", nil, http.StatusCreated},
+		{"invalid file", "", domain.ErrInvalidHoldings, http.StatusBadRequest},
+		{"unsupported repository", "", application.ErrHoldingsUnsupported, http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				importETFHoldingsFunc: func(ctx context.Context, etfISIN, format string, r io.Reader) (*domain.ETFComposition, error) {
+					body, err := io.ReadAll(r)
+					if err != nil {
+						t.Fatalf("failed to read body: %v", err)
+					}
+					if etfISIN != "IE00B4L5Y983" || string(body) != "Ticker,Name,Weight (%)" {
+						t.Errorf("unexpected import of %s: %q", etfISIN, body)
+					}
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					if format != "ishares" {
+						t.Errorf("expected the ishares format, got %q", format)
+					}
+					return &domain.ETFComposition{ETFISIN: etfISIN, TotalWeight: domain.NewDecimalFromInt(100)}, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/etfs/IE00B4L5Y983/holdings"+tt.query, bytes.NewBufferString("Ticker,Name,Weight (%)"))
+			req.Header.Set("Content-Type", "text/csv")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_GetETFHoldings(t *testing.T) {
+	tests := []struct {
+		name           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", nil, http.StatusOK},
+		{"not imported", domain.ErrHoldingsNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				getETFHoldingsFunc: func(ctx context.Context, etfISIN string) (*domain.ETFComposition, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					return &domain.ETFComposition{
+						ETFISIN:     etfISIN,
+						TotalWeight: domain.NewDecimalFromInt(100),
+						Holdings:    []domain.ETFHolding{{ISIN: "US0378331005", Name: "APPLE INC", Weight: domain.NewDecimalFromInt(100)}},
+					}, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/etfs/IE00B4L5Y983/holdings", nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if tt.serviceErr == nil && !bytes.Contains(w.Body.Bytes(), []byte(`"name":"APPLE INC"`)) {
+				t.Errorf("expected the holdings, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_CreateWatchlist(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", `{"name":"Candidates"}`, nil, http.StatusCreated},
+		{"missing name", `{}`, nil, http.StatusBadRequest},
+		{"blank name", `{"name":" "}`, domain.ErrInvalidWatchlist, http.StatusBadRequest},
+		{"unsupported repository", `{"name":"Candidates"}`, application.ErrWatchlistsUnsupported, http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				createWatchlistFunc: func(ctx context.Context, name string) (*domain.WatchlistReport, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					return &domain.WatchlistReport{ID: "wl-1", Name: name, Items: []domain.WatchlistQuote{}}, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/watchlists", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_GetWatchlists(t *testing.T) {
+	target := domain.NewDecimalFromInt(-20)
+	report := domain.WatchlistReport{
+		ID:   "wl-1",
+		Name: "Candidates",
+		Items: []domain.WatchlistQuote{{
+			WatchlistItem:    domain.WatchlistItem{Instrument: domain.Instrument{ISIN: "US0378331005"}, LastPrice: domain.NewDecimalFromInt(150)},
+			DistanceToTarget: &target,
+		}},
+	}
+	mockService := &MockPortfolioService{
+		listWatchlistsFunc: func(ctx context.Context) ([]domain.WatchlistReport, error) {
+			return []domain.WatchlistReport{report}, nil
+		},
+		getWatchlistFunc: func(ctx context.Context, id string) (*domain.WatchlistReport, error) {
+			if id != report.ID {
+				return nil, domain.ErrWatchlistNotFound
+			}
+			return &report, nil
+		},
+	}
+	router := setupRouter(NewHandler(mockService))
+
+	tests := []struct {
+		name           string
+		path           string
+		expectedStatus int
+	}{
+		{"list", "/api/v1/watchlists", http.StatusOK},
+		{"get", "/api/v1/watchlists/wl-1", http.StatusOK},
+		{"not found", "/api/v1/watchlists/wl-2", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+			if w.Code == http.StatusOK && !bytes.Contains(w.Body.Bytes(), []byte(`"distance_to_target":-20`)) {
+				t.Errorf("expected the distance to target, got %s", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_RenameAndDeleteWatchlist(t *testing.T) {
+	mockService := &MockPortfolioService{
+		renameWatchlistFunc: func(ctx context.Context, id, name string) (*domain.WatchlistReport, error) {
+			return &domain.WatchlistReport{ID: id, Name: name, Items: []domain.WatchlistQuote{}}, nil
+		},
+		deleteWatchlistFunc: func(ctx context.Context, id string) error {
+			if id != "wl-1" {
+				return domain.ErrWatchlistNotFound
+			}
+			return nil
+		},
+	}
+	router := setupRouter(NewHandler(mockService))
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"rename", http.MethodPut, "/api/v1/watchlists/wl-1", `{"name":"ETFs"}`, http.StatusOK},
+		{"rename without name", http.MethodPut, "/api/v1/watchlists/wl-1", `{}`, http.StatusBadRequest},
+		{"delete", http.MethodDelete, "/api/v1/watchlists/wl-1", "", http.StatusNoContent},
+		{"delete unknown", http.MethodDelete, "/api/v1/watchlists/wl-2", "", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_WatchlistItems(t *testing.T) {
+	report := &domain.WatchlistReport{ID: "wl-1", Name: "Candidates", Items: []domain.WatchlistQuote{}}
+	mockService := &MockPortfolioService{
+		addWatchlistItemFunc: func(ctx context.Context, id string, req application.AddWatchlistItemRequest) (*domain.WatchlistReport, error) {
+			if req.ISIN == "US0378331005" {
+				return nil, domain.ErrDuplicateWatchlistItem
+			}
+			if req.TargetPrice == nil || !req.TargetPrice.Equal(domain.NewDecimalFromInt(120)) {
+				t.Errorf("expected a target price of 120, got %v", req.TargetPrice)
+			}
+			return report, nil
+		},
+		updateWatchlistItemFunc: func(ctx context.Context, id, isin string, details domain.WatchlistItemDetails) (*domain.WatchlistReport, error) {
+			if isin != "IE00B4L5Y983" {
+				return nil, domain.ErrWatchlistItemNotFound
+			}
+			if details.TargetPrice != nil && details.TargetPrice.Cmp(domain.Zero) < 0 {
+				return nil, domain.ErrInvalidWatchlist
+			}
+			return report, nil
+		},
+		removeWatchlistItemFunc: func(ctx context.Context, id, isin string) (*domain.WatchlistReport, error) {
+			return report, nil
+		},
+	}
+	router := setupRouter(NewHandler(mockService))
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{"add", http.MethodPost, "/api/v1/watchlists/wl-1/items", `{"isin":"IE00B4L5Y983","notes":"core","target_price":"120"}`, http.StatusCreated},
+		{"add without identifier", http.MethodPost, "/api/v1/watchlists/wl-1/items", `{"notes":"core"}`, http.StatusBadRequest},
+		{"add duplicate", http.MethodPost, "/api/v1/watchlists/wl-1/items", `{"isin":"US0378331005"}`, http.StatusConflict},
+		{"update", http.MethodPut, "/api/v1/watchlists/wl-1/items/IE00B4L5Y983", `{"notes":"satellite"}`, http.StatusOK},
+		{"update negative target", http.MethodPut, "/api/v1/watchlists/wl-1/items/IE00B4L5Y983", `{"target_price":"-1"}`, http.StatusBadRequest},
+		{"update unknown item", http.MethodPut, "/api/v1/watchlists/wl-1/items/US0378331005", `{}`, http.StatusNotFound},
+		{"remove", http.MethodDelete, "/api/v1/watchlists/wl-1/items/IE00B4L5Y983", "", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_CreateAlertRule(t *testing.T) {
+	tests := []struct {
+		name           string
+		body           string
+		serviceErr     error
+		expectedStatus int
+	}{
+		{"success", `{"kind":"price_above","isin":"US0378331005","threshold":"150","cooldown_minutes":30}`, nil, http.StatusCreated},
+		{"missing kind", `{"isin":"US0378331005","threshold":"150"}`, nil, http.StatusBadRequest},
+		{"invalid rule", `{"kind":"daily_move","threshold":"5"}`, domain.ErrInvalidAlertRule, http.StatusBadRequest},
+		{"unsupported repository", `{"kind":"portfolio_value_below","threshold":"1000"}`, application.ErrAlertsUnsupported, http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockPortfolioService{
+				createAlertRuleFunc: func(ctx context.Context, req application.CreateAlertRuleRequest) (*domain.AlertRule, error) {
+					if tt.serviceErr != nil {
+						return nil, tt.serviceErr
+					}
+					if req.CooldownMinutes == nil || *req.CooldownMinutes != 30 || !req.Threshold.Equal(domain.NewDecimalFromInt(150)) {
+						t.Errorf("unexpected request %+v", req)
+					}
+					return &domain.AlertRule{ID: "rule-1", Kind: req.Kind, ISIN: req.ISIN, Threshold: req.Threshold}, nil
+				},
+			}
+
+			router := setupRouter(NewHandler(mockService))
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/alerts/rules", bytes.NewBufferString(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandler_AlertRulesAndAlerts(t *testing.T) {
+	mockService := &MockPortfolioService{
+		listAlertRulesFunc: func(ctx context.Context) ([]*domain.AlertRule, error) {
+			return []*domain.AlertRule{{ID: "rule-1", Kind: domain.AlertPriceAbove, ISIN: "US0378331005", Threshold: domain.NewDecimalFromInt(150)}}, nil
+		},
+		deleteAlertRuleFunc: func(ctx context.Context, id string) error {
+			if id != "rule-1" {
+				return domain.ErrAlertRuleNotFound
+			}
+			return nil
+		},
+		listAlertsFunc: func(ctx context.Context, limit int) ([]domain.Alert, error) {
+			if limit != 0 && limit != 10 {
+				t.Errorf("unexpected limit %d", limit)
+			}
+			return []domain.Alert{{ID: "alert-1", RuleID: "rule-1", Kind: domain.AlertPriceAbove, Value: domain.NewDecimalFromInt(155)}}, nil
+		},
+	}
+	router := setupRouter(NewHandler(mockService))
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		expectedStatus int
+	}{
+		{"list rules", http.MethodGet, "/api/v1/alerts/rules", http.StatusOK},
+		{"delete rule", http.MethodDelete, "/api/v1/alerts/rules/rule-1", http.StatusNoContent},
+		{"delete unknown rule", http.MethodDelete, "/api/v1/alerts/rules/rule-2", http.StatusNotFound},
+		{"list alerts", http.MethodGet, "/api/v1/alerts", http.StatusOK},
+		{"list alerts with limit", http.MethodGet, "/api/v1/alerts?limit=10", http.StatusOK},
+		{"invalid limit", http.MethodGet, "/api/v1/alerts?limit=-1", http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.expectedStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
 // --- NewHandler Tests ---
 
 func TestNewHandler(t *testing.T) {
@@ -12,9 +12,53 @@ func SetupRoutes(router *gin.Engine, handler *Handler) {
 		api.GET("/positions", handler.ListPositions)
 		api.GET("/positions/:id", handler.GetPosition)
 		api.DELETE("/positions/:id", handler.DeletePosition)
+		api.POST("/positions/:id/sell", handler.SellPosition)
+
+		api.GET("/instruments/resolve", handler.ResolveInstrument)
+		api.PUT("/instruments/:isin", handler.UpdateInstrument)
+		api.GET("/instruments/:isin/prices", handler.GetInstrumentPrices)
+
+		api.GET("/etfs/:isin/holdings", handler.GetETFHoldings)
+		api.POST("/etfs/:isin/holdings", handler.ImportETFHoldings)
+
+		api.GET("/watchlists", handler.ListWatchlists)
+		api.POST("/watchlists", handler.CreateWatchlist)
+		api.GET("/watchlists/:id", handler.GetWatchlist)
+		api.PUT("/watchlists/:id", handler.RenameWatchlist)
+		api.DELETE("/watchlists/:id", handler.DeleteWatchlist)
+		api.POST("/watchlists/:id/items", handler.AddWatchlistItem)
+		api.PUT("/watchlists/:id/items/:isin", handler.UpdateWatchlistItem)
+		api.DELETE("/watchlists/:id/items/:isin", handler.RemoveWatchlistItem)
+		api.GET("/alerts", handler.ListAlerts)
+		api.GET("/alerts/rules", handler.ListAlertRules)
+		api.POST("/alerts/rules", handler.CreateAlertRule)
+		api.DELETE("/alerts/rules/:id", handler.DeleteAlertRule)
 
 		api.GET("/portfolio", handler.GetPortfolio)
 		api.POST("/portfolio/refresh", handler.RefreshPrices)
+		api.GET("/portfolio/transactions", handler.ListTransactions)
+		api.POST("/portfolio/transactions", handler.RecordTransaction)
+		api.PUT("/portfolio/cost-basis", handler.SetCostBasisMethod)
+		api.PUT("/portfolio/base-currency", handler.SetBaseCurrency)
+		api.GET("/portfolio/dividends", handler.ListDividends)
+		api.POST("/portfolio/dividends", handler.RecordDividend)
+		api.POST("/portfolio/dividends/sync", handler.SyncDividends)
+		api.GET("/portfolio/income", handler.GetIncomeReport)
+		api.GET("/portfolio/corporate-actions", handler.ListCorporateActions)
+		api.POST("/portfolio/corporate-actions", handler.ApplyCorporateAction)
+		api.GET("/portfolio/performance", handler.GetPerformance)
+		api.GET("/portfolio/xirr", handler.GetMoneyWeightedReturn)
+		api.GET("/portfolio/cash", handler.GetCashBalances)
+		api.GET("/portfolio/cash/movements", handler.ListCashMovements)
+		api.POST("/portfolio/cash/movements", handler.RecordCashMovement)
+		api.GET("/portfolio/targets", handler.ListTargetAllocations)
+		api.PUT("/portfolio/targets", handler.SetTargetAllocations)
+		api.GET("/portfolio/rebalance", handler.Rebalance)
+		api.GET("/portfolio/benchmark", handler.CompareBenchmark)
+		api.PUT("/portfolio/benchmark", handler.SetBenchmark)
+		api.GET("/portfolio/risk", handler.GetRisk)
+		api.GET("/portfolio/history", handler.GetPortfolioHistory)
+		api.GET("/portfolio/exposure", handler.GetExposure)
 	}
 
 	router.GET("/health", func(c *gin.Context) {